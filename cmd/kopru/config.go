@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var schemaFormat string
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect Kopru configuration",
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the configuration schema",
+	Long: `Schema prints every configuration option with its type, default value, required
+condition, allowed values and description. Use --format to select markdown, json or env output.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return config.RenderSchema(os.Stdout, schemaFormat)
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration and print the effective values",
+	Long: `Validate checks the configuration file and environment without contacting a cloud:
+OCID formats, region names, allowed values, mutually exclusive options, the SSH and LUKS
+key files and the external configurators with their hook scripts. Only secret:// values are
+read from their secret stores; a compartment path in OCI_COMPARTMENT_ID is not resolved. It then prints the effective configuration, with secrets masked
+and secret references shown as such.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfigOffline()
+		if err != nil {
+			return err
+		}
+		if err := cfg.WriteEffective(os.Stdout); err != nil {
+			return err
+		}
+		if err := workflow.ValidateConfig(cfg); err != nil {
+			return fmt.Errorf("configuration is invalid:\n%w", err)
+		}
+		fmt.Fprintln(os.Stderr, "Configuration is valid")
+		return nil
+	},
+}
+
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "List the configuration profiles",
+	Long: `Profiles lists the profiles of the configuration file or directory given with --config,
+./kopru-config.env, or ~/.kopru/profiles. Select a profile with --profile or KOPRU_PROFILE.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := config.ListProfiles(configPath())
+		if err != nil {
+			return fmt.Errorf("failed to list profiles: %w", err)
+		}
+		for _, p := range profiles {
+			fmt.Println(p)
+		}
+		return nil
+	},
+}
+
+func init() {
+	configSchemaCmd.Flags().StringVar(&schemaFormat, "format", config.SchemaFormatMarkdown, "Output format (markdown, json, env)")
+	configCmd.AddCommand(configSchemaCmd, configValidateCmd, configProfilesCmd)
+	rootCmd.AddCommand(configCmd)
+}
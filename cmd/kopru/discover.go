@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	discoverTags     []string
+	discoverManifest string
+)
+
+var discoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "List the Azure VMs to migrate by their tags",
+	Long: `Discover lists the VMs of AZURE_RESOURCE_GROUP, or of AZURE_SUBSCRIPTION_ID when no resource group
+is set, that have all the tags of --azure-tag, with their size, OS and disks. A filter is
+<key>=<value>, or <key> to match any value. Pass --manifest to write the VMs to a batch manifest,
+which BATCH_MANIFEST_FILE migrates one after another.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfigOffline()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		opts := workflow.DiscoverOptions{Tags: discoverTags, Manifest: discoverManifest}
+		return workflow.Discover(context.Background(), cfg, logger.New(cfg.Debug), os.Stdout, opts)
+	},
+}
+
+func init() {
+	discoverCmd.Flags().StringArrayVar(&discoverTags, "azure-tag", nil, "Tag the VMs must have, as <key>=<value> or <key> (repeatable)")
+	discoverCmd.Flags().StringVar(&discoverManifest, "manifest", "", "Write the resource IDs of the VMs to this batch manifest")
+	rootCmd.AddCommand(discoverCmd)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcRetentionDays int
+	gcMinAgeHours   int
+	gcDelete        bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Clean up resources left by earlier runs",
+}
+
+var gcImagesCmd = &cobra.Command{
+	Use:   "images",
+	Short: "List and delete stale custom images created by Kopru",
+	Long: `Images lists the custom images tagged created-by=kopru in OCI_COMPARTMENT_ID with their state
+and age. Images that never became AVAILABLE, and available images older than --retention-days,
+are selected for deletion. Pass --delete to delete them after a typed confirmation (or --yes).`,
+	RunE: runGCImages,
+}
+
+var gcSnapshotsCmd = &cobra.Command{
+	Use:   "snapshots",
+	Short: "List and delete export snapshots left in Azure by crashed runs",
+	Long: `Snapshots lists the export snapshots in AZURE_RESOURCE_GROUP: those tagged created-by=kopru, and
+untagged ss-* snapshots created by earlier versions, with their migration ID, creator and age.
+Snapshots older than --min-age-hours are selected for deletion. Pass --delete to revoke their
+download access and delete them after a typed confirmation (or --yes).`,
+	RunE: runGCSnapshots,
+}
+
+func init() {
+	gcImagesCmd.Flags().IntVar(&gcRetentionDays, "retention-days", 0, "Delete available images older than this many days (0 keeps available images)")
+	gcImagesCmd.Flags().BoolVar(&gcDelete, "delete", false, "Delete the selected images instead of only listing them")
+	gcSnapshotsCmd.Flags().IntVar(&gcMinAgeHours, "min-age-hours", 24, "Delete snapshots older than this many hours (younger snapshots may belong to a running migration)")
+	gcSnapshotsCmd.Flags().BoolVar(&gcDelete, "delete", false, "Delete the selected snapshots instead of only listing them")
+	gcCmd.AddCommand(gcImagesCmd, gcSnapshotsCmd)
+	rootCmd.AddCommand(gcCmd)
+}
+
+func runGCImages(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	log := logger.New(cfg.Debug)
+	opts := workflow.ImageGCOptions{
+		Retention: time.Duration(gcRetentionDays) * 24 * time.Hour,
+		Delete:    gcDelete,
+	}
+	return workflow.GarbageCollectImages(context.Background(), cfg, log, os.Stdout, opts)
+}
+
+func runGCSnapshots(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	log := logger.New(cfg.Debug)
+	opts := workflow.SnapshotGCOptions{
+		MinAge: time.Duration(gcMinAgeHours) * time.Hour,
+		Delete: gcDelete,
+	}
+	return workflow.GarbageCollectSnapshots(context.Background(), cfg, log, os.Stdout, opts)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	imageEditExpr        string
+	imageConfigureFile   string
+	imageConfigureSource string
+	imageConfigureOS     string
+	imageConfigureOSVer  string
+)
+
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Read and write files in a disk image without running the full configuration",
+	Long: `Image reads and writes single files in the guest filesystems of a QCOW2, VHD or RAW image with
+libguestfs, for quick fixes to an exported or configured image. LUKS containers are opened with
+LUKS_PASSPHRASE or LUKS_KEY_FILE. The image must not be in use by a running workflow or VM.`,
+}
+
+var imageCatCmd = &cobra.Command{
+	Use:   "cat <image> <path>",
+	Short: "Print a file of the image",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		return workflow.CatGuestFile(cfg, args[0], args[1], os.Stdout)
+	},
+}
+
+var imageCpCmd = &cobra.Command{
+	Use:   "cp <image> <source> <destination>",
+	Short: "Copy files between the image and the local filesystem",
+	Long: `Cp copies a file or directory between the image and the local filesystem. Prefix the path in
+the image with ':'. A path in the image is copied into a local directory, and a local path is
+copied into a directory in the image:
+
+  kopru image cp disk.qcow2 :/etc/fstab ./
+  kopru image cp disk.qcow2 ./sshd_config :/etc/ssh/`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		return workflow.CopyGuestFile(cfg, args[0], args[1], args[2])
+	},
+}
+
+var imageEditCmd = &cobra.Command{
+	Use:   "edit <image> <path>",
+	Short: "Edit a file of the image in place",
+	Long: `Edit opens a file of the image in $EDITOR and writes it back when the editor exits. Use --expr
+to apply a Perl expression to each line instead, e.g.:
+
+  kopru image edit disk.qcow2 /etc/selinux/config --expr 's/^SELINUX=.*/SELINUX=permissive/'`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		return workflow.EditGuestFile(cfg, args[0], args[1], imageEditExpr)
+	},
+}
+
+var imageConfigureCmd = &cobra.Command{
+	Use:   "configure",
+	Short: "Apply the OS configuration to a local image without the cloud steps",
+	Long: `Configure applies the configuration stack of a migration to a local QCOW2 image in place: the
+built-in OS scripts or virt-v2v (CONFIGURE_ENGINE), the external configurators, and scrubbing and
+pre-boot validation when enabled. Nothing is exported, uploaded or deployed, so guest fixups can
+be validated repeatedly. Work on a copy of the image, since it is modified in place:
+
+  cp vm.qcow2 test.qcow2
+  kopru image configure --qcow2 test.qcow2 --source azure --os Ubuntu --preboot-validation`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if imageConfigureOS != "" {
+			cfg.OCIImageOS = imageConfigureOS
+		}
+		if imageConfigureOSVer != "" {
+			cfg.OCIImageOSVersion = imageConfigureOSVer
+		}
+		if cfg.OCIImageOS == "" {
+			return fmt.Errorf("--os (or OCI_IMAGE_OS) is required")
+		}
+		if imageConfigureSource != "azure" && imageConfigureSource != "linux_image" {
+			return fmt.Errorf("unsupported source platform '%s' (supported: azure, linux_image)", imageConfigureSource)
+		}
+		log := logger.New(cfg.Debug)
+		return workflow.ConfigureImage(context.Background(), cfg, log, imageConfigureFile, imageConfigureSource)
+	},
+}
+
+func init() {
+	imageConfigureCmd.Flags().StringVar(&imageConfigureFile, "qcow2", "", "QCOW2 image to configure in place")
+	imageConfigureCmd.Flags().StringVar(&imageConfigureSource, "source", "azure", "Source platform the image comes from (azure, linux_image)")
+	imageConfigureCmd.Flags().StringVar(&imageConfigureOS, "os", "", "Operating system of the image, as in OCI_IMAGE_OS (e.g. Ubuntu)")
+	imageConfigureCmd.Flags().StringVar(&imageConfigureOSVer, "os-version", "", "Operating system version, as in OCI_IMAGE_OS_VERSION (e.g. 22.04)")
+	_ = imageConfigureCmd.MarkFlagRequired("qcow2")
+	imageEditCmd.Flags().StringVar(&imageEditExpr, "expr", "", "Perl expression applied to each line instead of opening an editor")
+	imageCmd.AddCommand(imageCatCmd, imageCpCmd, imageEditCmd, imageConfigureCmd)
+	rootCmd.AddCommand(imageCmd)
+}
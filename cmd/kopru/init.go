@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/prompt"
+	"github.com/codebypatrickleung/kopru-cli/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	initOutput string
+	initForce  bool
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a configuration file interactively",
+	Long: `Init walks through the source and target of a migration, listing the Azure subscriptions,
+resource groups and VMs, and the OCI compartments, subnets and availability domains the
+current credentials can access, and writes a validated kopru-config.env. Values already set
+in the environment or with flags are kept without asking.`,
+	RunE: runInit,
+}
+
+func init() {
+	initCmd.Flags().StringVarP(&initOutput, "output", "o", "kopru-config.env", "Configuration file to write")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing configuration file")
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	if !prompt.IsInteractive() {
+		return errors.New("kopru init requires an interactive terminal")
+	}
+	if _, err := os.Stat(initOutput); err == nil && !initForce {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", initOutput)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	log := logger.New(cfg.Debug)
+	ctx := context.Background()
+	p := prompt.New(os.Stdin, os.Stderr)
+
+	if cfg.SourcePlatform, err = p.Select("Source platform", []prompt.Option{
+		{Label: "Azure VM", Value: "azure"},
+		{Label: "Linux QCOW2 image", Value: "linux_image"},
+	}); err != nil {
+		return err
+	}
+	switch cfg.SourcePlatform {
+	case "azure":
+		if err := initAzure(ctx, p, cfg, log); err != nil {
+			return err
+		}
+	case "linux_image":
+		if cfg.OSImageURL == "" {
+			if cfg.OSImageURL, err = p.Input("URL of the QCOW2 image"); err != nil {
+				return err
+			}
+		}
+	}
+	if err := promptOCI(ctx, p, cfg, log); err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+	var b bytes.Buffer
+	b.WriteString("# Kopru configuration generated by kopru init\n# See kopru-config.env.template for all options\n\n")
+	if err := cfg.WriteEnv(&b); err != nil {
+		return err
+	}
+	if err := os.WriteFile(initOutput, b.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", initOutput, err)
+	}
+	log.Successf("Configuration written to %s", initOutput)
+	log.Info("Review it with 'kopru plan', then run 'kopru' to start the migration")
+	return nil
+}
+
+// initAzure selects the subscription and resource group from the lists Azure returns
+// for the current credentials, then the VM.
+func initAzure(ctx context.Context, p *prompt.Prompter, cfg *config.Config, log *logger.Logger) error {
+	provider, err := azure.NewProvider(cfg.AzureSubscriptionID, workflow.AzureAuth(cfg), log)
+	if err != nil {
+		return err
+	}
+	if cfg.AzureSubscriptionID == "" {
+		subscriptions, err := provider.ListSubscriptions(ctx)
+		if err != nil {
+			return err
+		}
+		options := make([]prompt.Option, len(subscriptions))
+		for i, s := range subscriptions {
+			options[i] = prompt.Option{Label: fmt.Sprintf("%s (%s)", s.DisplayName, s.ID), Value: s.ID}
+		}
+		if cfg.AzureSubscriptionID, err = p.Select("Azure subscription", options); err != nil {
+			return err
+		}
+		if provider, err = azure.NewProvider(cfg.AzureSubscriptionID, workflow.AzureAuth(cfg), log); err != nil {
+			return err
+		}
+	}
+	if cfg.AzureResourceGroup == "" {
+		groups, err := provider.ListResourceGroups(ctx)
+		if err != nil {
+			return err
+		}
+		options := make([]prompt.Option, len(groups))
+		for i, name := range groups {
+			options[i] = prompt.Option{Label: name, Value: name}
+		}
+		if cfg.AzureResourceGroup, err = p.Select("Azure resource group", options); err != nil {
+			return err
+		}
+	}
+	return promptAzure(ctx, p, cfg, log)
+}
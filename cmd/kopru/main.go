@@ -5,17 +5,31 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
 	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/prompt"
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
 	"github.com/codebypatrickleung/kopru-cli/internal/workflow"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// telemetryShutdownTimeout bounds how long exiting waits for telemetry to be flushed.
+const telemetryShutdownTimeout = 10 * time.Second
+
+// defaultConfigFile is read when --config is not set.
+const defaultConfigFile = "kopru-config.env"
+
 var (
-	cfgFile string
-	version = "0.2.3"
+	cfgFile  string
+	profile  string
+	noPrompt bool
+	version  = "0.2.3"
 )
 
 func main() {
@@ -35,15 +49,26 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	workflow.RegisterSecretBackends()
+	workflow.RegisterCompartmentResolver()
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./kopru-config.env)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file or directory of <profile>.env files (default is ./kopru-config.env)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "configuration profile to use (default is $KOPRU_PROFILE or default)")
+	rootCmd.Flags().BoolVar(&noPrompt, "no-prompt", false, "Fail instead of prompting for missing values in interactive sessions")
 
 	flags := []struct {
 		name, shorthand, usage, defaultValue string
 	}{
 		{"azure-subscription-id", "", "Azure subscription ID", ""},
+		{"azure-managed-identity-client-id", "", "Client ID of the user-assigned managed identity to use on the migration VM", ""},
+		{"snapshot-consistency", "", "Consistency of the export snapshots of a running Azure VM (crash, fsfreeze)", ""},
+		{"live-sync-stop-timeout-minutes", "", "Minutes the live sync waits for the Azure VM to be stopped after the first pass", "60"},
+		{"azure-power-timeout-minutes", "", "Minutes to wait for the Azure VM to be deallocated or running again", "15"},
+		{"azure-auth", "", "Azure authentication method (auto, client-secret, client-certificate, managed-identity, device-code, azure-cli)", ""},
 		{"azure-resource-group", "", "Azure resource group name", ""},
-		{"azure-compute-name", "", "Azure compute instance name", ""},
+		{"azure-compute-name", "", "Azure compute instance name, or a pattern such as \"web-*\" to migrate each matching VM", ""},
+		{"azure-compute-group", "", "Availability set or VM scale set whose VMs are migrated (availabilitySets/<name>, virtualMachineScaleSets/<name> or resource ID)", ""},
+		{"batch-manifest-file", "", "File listing the Azure VMs to migrate as a batch, one VM name or resource ID per line (see kopru discover)", ""},
 		{"oci-region", "", "OCI region", ""},
 		{"oci-compartment-id", "", "OCI compartment OCID", ""},
 		{"oci-subnet-id", "", "OCI subnet OCID", ""},
@@ -52,16 +77,64 @@ func init() {
 		{"oci-image-os", "", "OS type for OCI (Ubuntu, Windows, Debian, Oracle Linux, AlmaLinux, CentOS, RHEL, Rocky Linux, SUSE, Generic Linux)", ""},
 		{"oci-image-os-version", "", "OS version for OCI (e.g., 20.04, 22.04, 2019, 2022)", ""},
 		{"oci-image-enable-uefi", "", "Enable UEFI for OCI image (true or false)", "false"},
+		{"oci-image-launch-mode", "", "Launch mode of the imported image (NATIVE, EMULATED, PARAVIRTUALIZED)", "PARAVIRTUALIZED"},
+		{"oci-boot-volume-vpus", "", "Performance of the boot volume in VPUs per GB (10-120)", "10"},
+		{"oci-data-volume-vpus", "", "Performance of restored data volumes in VPUs per GB (0-120)", "10"},
+		{"image-conflict-policy", "", "Action when an image with the same name exists (reuse, fail, suffix)", "suffix"},
 		{"oci-instance-name", "", "OCI instance name", ""},
 		{"oci-availability-domain", "", "OCI availability domain", ""},
+		{"oci-data-volume-availability-domain", "", "Availability domain number of the data volumes (default: that of the Kopru host)", ""},
+		{"oci-shapes", "", "Comma-separated shapes of the instance in order of preference", ""},
+		{"oci-burstable-baseline", "", "Baseline OCPU utilization of instances migrated from B-series VMs (BASELINE_1_8, BASELINE_1_2, BASELINE_1_1)", ""},
+		{"oci-fault-domain", "", "Fault domain of the instance (FAULT-DOMAIN-1, FAULT-DOMAIN-2, FAULT-DOMAIN-3)", ""},
+		{"oci-nsg-ids", "", "Comma-separated OCIDs of network security groups of the instance VNIC", ""},
+		{"oci-hostname-label", "", "Hostname label of the instance VNIC", ""},
+		{"oci-subnet-map-file", "", "File mapping Azure subnets to OCI subnets, to reconstruct secondary network interfaces as VNICs", ""},
+		{"oci-target-instance-id", "", "OCID of an existing instance to attach the migrated data disks to, instead of creating an instance", ""},
+		{"oci-defined-tags", "", "Comma-separated defined tags of the instance (namespace.key=value)", ""},
+		{"source-tag-map-file", "", "File mapping Azure tags to OCI defined tags, with --preserve-source-tags", ""},
+		{"instance-state", "", "State of the instance after deployment (RUNNING, STOPPED)", "RUNNING"},
 		{"os-image-url", "", "URL to OS image in QCOW2 format for linux_image source platform", ""},
 		{"template-output-dir", "", "Directory for template files", "./template-output"},
+		{"template-environments", "", "Comma-separated environments to generate <env>.tfvars for (e.g. dev,prod)", ""},
+		{"template-dir", "", "Directory of templates that override or add to the generated template files", ""},
+		{"template-backend", "", "State backend of the template (local, oci, or a backend type configured with --template-backend-config)", "local"},
+		{"template-backend-bucket", "", "OCI Object Storage bucket of the state with --template-backend oci", ""},
+		{"template-backend-config", "", "Comma-separated key=value settings of the backend block", ""},
+		{"iac-engine", "", "Infrastructure as code engine that deploys the template (tofu, terraform)", "tofu"},
 		{"ssh-key-file", "", "Path to SSH public key file for instance access", ""},
+		{"ssh-public-key", "", "SSH public key injected into the image and instance metadata", ""},
+		{"cloud-init-file", "", "Path to a cloud-init file passed to the instance as user_data", ""},
+		{"oci-agent-plugins", "", "Comma-separated Oracle Cloud Agent plugins enabled on the instance", "monitoring"},
+		{"breakglass-user", "", "Temporary sudo user created in the image for emergency SSH access", ""},
+		{"luks-key-file", "", "Path to a key file of the LUKS containers in the image", ""},
+		{"configure-engine", "", "Engine that configures the image for OCI (builtin, virt-v2v)", "builtin"},
+		{"data-disk-copy", "", "How data disks are copied to OCI block volumes (dd, sparse, nbd)", "dd"},
+		{"configurators-dir", "", "Directory of YAML OS configurators (default ~/.kopru/configurators)", ""},
+		{"configure-chain", "", "Ordered image configuration steps (builtin, configurators, configurator:<name>, script:<path>)", "builtin,configurators"},
+		{"package-cache-dir", "", "Local package repository used instead of the image's repositories while it is configured", ""},
+		{"arch-mismatch", "", "Handling of commands inside images of another architecture than the host (firstboot, emulate, fail)", "firstboot"},
+		{"finishing-script", "", "Script run on the deployed instance through the OCI Run Command plugin after first boot", ""},
+		{"step-timeouts", "", "Hard timeouts of workflow steps as <step>=<duration> (e.g. export=4h,upload=6h,deploy=30m)", ""},
+		{"heartbeat-minutes", "", "Minutes between heartbeat log lines of a running step (0 disables heartbeats)", "5"},
+		{"existing-migration", "", "Action when the source was migrated by an earlier run (prompt, resume, replace, abort)", "prompt"},
+		{"migration-history-file", "", "File recording the last migration run per source (default ~/.kopru/migrations.json)", ""},
 		{"source-platform", "", "Source cloud platform (azure, linux_image)", "azure"},
 		{"target-platform", "", "Target cloud platform (oci)", "oci"},
+		{"lang", "", "Language for user-facing messages (en, es)", "en"},
+		{"log-format", "", "Log output format (text, json)", "text"},
+		{"otlp-endpoint", "", "OTLP/HTTP endpoint for OpenTelemetry traces and metrics (e.g. http://localhost:4318)", ""},
+		{"cmdb-format", "", "Format of the CMDB record written after each run (json, csv)", "json"},
+		{"cmdb-endpoint", "", "HTTP endpoint that receives the CMDB record after a successful run", ""},
+		{"change-ticket-system", "", "Change ticket system updated at workflow start and end (jira, servicenow)", ""},
+		{"change-ticket-url", "", "Base URL of the Jira or ServiceNow instance", ""},
+		{"change-ticket-id", "", "Existing Jira issue key or ServiceNow change number", ""},
+		{"change-ticket-project", "", "Jira project key used when creating an issue", ""},
+		{"notify-webhook-format", "", "Payload format of the notification webhook (json, slack, teams)", "json"},
+		{"notify-events", "", "Events posted to the notification webhook (all, failures)", "all"},
 	}
 	for _, f := range flags {
-		rootCmd.Flags().String(f.name, f.defaultValue, f.usage)
+		rootCmd.PersistentFlags().String(f.name, f.defaultValue, f.usage)
 	}
 
 	boolFlags := []struct {
@@ -69,54 +142,159 @@ func init() {
 	}{
 		{"skip-os-export", "Skip OS disk export"},
 		{"skip-template-deploy", "Skip template deployment"},
+		{"oci-instance-pool", "Add the instances migrated from AZURE_COMPUTE_GROUP to an instance pool"},
 		{"debug", "Enable debug logging"},
+		{"scrub-image", "Remove host-specific data and secrets from the configured image"},
+		{"verify-checksums", "Verify exported, converted, uploaded and copied disks with checksums"},
+		{"parallel-steps", "Run each step as soon as the artifacts it consumes are available"},
+		{"live-sync", "Export the disks of the running Azure VM, then only the changes once it is stopped"},
+		{"stop-vm", "Deallocate the running Azure VM before its disks are exported, after confirmation"},
+		{"force-stop", "Deallocate the running Azure VM before its disks are exported, without confirmation"},
+		{"restart-vm", "Start the Azure VM again once its disks are exported, if Kopru deallocated it"},
+		{"preboot-validation", "Boot the configured image under QEMU/KVM before upload"},
+		{"preserve-source-tags", "Carry the tags of the source VM and its disks over to the instance, image and volumes"},
+		{"boot-beacon", "Install a one-shot service that reports the first boot of the instance in OCI"},
+		{"subscription-reregister", "Install a one-shot service that registers RHEL and SLES instances again on their first boot in OCI"},
+		{"accept-custom-script", "Acknowledge that custom configurator scripts run as root in the image with sudo"},
+		{"yes", "Skip typed confirmations before large uploads and template deployment"},
 	}
 	for _, f := range boolFlags {
-		rootCmd.Flags().Bool(f.name, false, f.usage)
+		rootCmd.PersistentFlags().Bool(f.name, false, f.usage)
 	}
+	rootCmd.PersistentFlags().Bool("parallel-disk-pipelines", false, "Former name of --parallel-steps")
+	_ = rootCmd.PersistentFlags().MarkDeprecated("parallel-disk-pipelines", "use --parallel-steps instead")
 
 	bindings := map[string]string{
-		"AZURE_SUBSCRIPTION_ID":   "azure-subscription-id",
-		"AZURE_RESOURCE_GROUP":    "azure-resource-group",
-		"AZURE_COMPUTE_NAME":      "azure-compute-name",
-		"OCI_REGION":              "oci-region",
-		"OCI_COMPARTMENT_ID":      "oci-compartment-id",
-		"OCI_SUBNET_ID":           "oci-subnet-id",
-		"OCI_BUCKET_NAME":         "oci-bucket-name",
-		"OCI_IMAGE_NAME":          "oci-image-name",
-		"OCI_IMAGE_OS":            "oci-image-os",
-		"OCI_IMAGE_OS_VERSION":    "oci-image-os-version",
-		"OCI_IMAGE_ENABLE_UEFI":   "oci-image-enable-uefi",
-		"OCI_INSTANCE_NAME":       "oci-instance-name",
-		"OCI_AVAILABILITY_DOMAIN": "oci-availability-domain",
-		"OS_IMAGE_URL":            "os-image-url",
-		"SKIP_OS_EXPORT":          "skip-os-export",
-		"SKIP_TEMPLATE_DEPLOY":    "skip-template-deploy",
-		"TEMPLATE_OUTPUT_DIR":     "template-output-dir",
-		"SSH_KEY_FILE":            "ssh-key-file",
-		"SOURCE_PLATFORM":         "source-platform",
-		"TARGET_PLATFORM":         "target-platform",
-		"DEBUG":                   "debug",
+		"AZURE_SUBSCRIPTION_ID":               "azure-subscription-id",
+		"AZURE_MANAGED_IDENTITY_CLIENT_ID":    "azure-managed-identity-client-id",
+		"AZURE_AUTH":                          "azure-auth",
+		"AZURE_SNAPSHOT_CONSISTENCY":          "snapshot-consistency",
+		"LIVE_SYNC":                           "live-sync",
+		"LIVE_SYNC_STOP_TIMEOUT_MINUTES":      "live-sync-stop-timeout-minutes",
+		"AZURE_STOP_VM":                       "stop-vm",
+		"AZURE_FORCE_STOP":                    "force-stop",
+		"AZURE_RESTART_VM":                    "restart-vm",
+		"AZURE_POWER_TIMEOUT_MINUTES":         "azure-power-timeout-minutes",
+		"AZURE_RESOURCE_GROUP":                "azure-resource-group",
+		"AZURE_COMPUTE_NAME":                  "azure-compute-name",
+		"AZURE_COMPUTE_GROUP":                 "azure-compute-group",
+		"BATCH_MANIFEST_FILE":                 "batch-manifest-file",
+		"OCI_REGION":                          "oci-region",
+		"OCI_COMPARTMENT_ID":                  "oci-compartment-id",
+		"OCI_SUBNET_ID":                       "oci-subnet-id",
+		"OCI_BUCKET_NAME":                     "oci-bucket-name",
+		"OCI_IMAGE_NAME":                      "oci-image-name",
+		"OCI_IMAGE_OS":                        "oci-image-os",
+		"OCI_IMAGE_OS_VERSION":                "oci-image-os-version",
+		"OCI_IMAGE_ENABLE_UEFI":               "oci-image-enable-uefi",
+		"OCI_IMAGE_LAUNCH_MODE":               "oci-image-launch-mode",
+		"OCI_BOOT_VOLUME_VPUS_PER_GB":         "oci-boot-volume-vpus",
+		"OCI_DATA_VOLUME_VPUS_PER_GB":         "oci-data-volume-vpus",
+		"IMAGE_CONFLICT_POLICY":               "image-conflict-policy",
+		"OCI_INSTANCE_NAME":                   "oci-instance-name",
+		"OCI_AVAILABILITY_DOMAIN":             "oci-availability-domain",
+		"OCI_DATA_VOLUME_AVAILABILITY_DOMAIN": "oci-data-volume-availability-domain",
+		"OCI_SHAPES":                          "oci-shapes",
+		"OCI_BURSTABLE_BASELINE":              "oci-burstable-baseline",
+		"OCI_FAULT_DOMAIN":                    "oci-fault-domain",
+		"OCI_NSG_IDS":                         "oci-nsg-ids",
+		"OCI_HOSTNAME_LABEL":                  "oci-hostname-label",
+		"OCI_DEFINED_TAGS":                    "oci-defined-tags",
+		"PRESERVE_SOURCE_TAGS":                "preserve-source-tags",
+		"SOURCE_TAG_MAP_FILE":                 "source-tag-map-file",
+		"OCI_TARGET_INSTANCE_ID":              "oci-target-instance-id",
+		"OCI_SUBNET_MAP_FILE":                 "oci-subnet-map-file",
+		"OCI_INSTANCE_STATE":                  "instance-state",
+		"OS_IMAGE_URL":                        "os-image-url",
+		"SKIP_OS_EXPORT":                      "skip-os-export",
+		"SKIP_TEMPLATE_DEPLOY":                "skip-template-deploy",
+		"OCI_INSTANCE_POOL":                   "oci-instance-pool",
+		"TEMPLATE_OUTPUT_DIR":                 "template-output-dir",
+		"TEMPLATE_ENVIRONMENTS":               "template-environments",
+		"TEMPLATE_DIR":                        "template-dir",
+		"TEMPLATE_BACKEND":                    "template-backend",
+		"TEMPLATE_BACKEND_BUCKET":             "template-backend-bucket",
+		"TEMPLATE_BACKEND_CONFIG":             "template-backend-config",
+		"IAC_ENGINE":                          "iac-engine",
+		"SSH_KEY_FILE":                        "ssh-key-file",
+		"OCI_SSH_PUBLIC_KEY":                  "ssh-public-key",
+		"CLOUD_INIT_FILE":                     "cloud-init-file",
+		"OCI_AGENT_PLUGINS":                   "oci-agent-plugins",
+		"KOPRU_BREAKGLASS_USER":               "breakglass-user",
+		"LUKS_KEY_FILE":                       "luks-key-file",
+		"CONFIGURE_ENGINE":                    "configure-engine",
+		"DATA_DISK_COPY_STRATEGY":             "data-disk-copy",
+		"CONFIGURATORS_DIR":                   "configurators-dir",
+		"CONFIGURE_CHAIN":                     "configure-chain",
+		"ACCEPT_CUSTOM_SCRIPT":                "accept-custom-script",
+		"PACKAGE_CACHE_DIR":                   "package-cache-dir",
+		"ARCH_MISMATCH_ACTION":                "arch-mismatch",
+		"SCRUB_IMAGE":                         "scrub-image",
+		"PARALLEL_STEPS":                      "parallel-steps",
+		"PARALLEL_DISK_PIPELINES":             "parallel-disk-pipelines",
+		"STEP_TIMEOUTS":                       "step-timeouts",
+		"HEARTBEAT_MINUTES":                   "heartbeat-minutes",
+		"VERIFY_CHECKSUMS":                    "verify-checksums",
+		"PREBOOT_VALIDATION":                  "preboot-validation",
+		"FINISHING_SCRIPT":                    "finishing-script",
+		"BOOT_BEACON":                         "boot-beacon",
+		"SUBSCRIPTION_REREGISTER":             "subscription-reregister",
+		"EXISTING_MIGRATION_ACTION":           "existing-migration",
+		"MIGRATION_HISTORY_FILE":              "migration-history-file",
+		"SOURCE_PLATFORM":                     "source-platform",
+		"TARGET_PLATFORM":                     "target-platform",
+		"KOPRU_LANG":                          "lang",
+		"LOG_FORMAT":                          "log-format",
+		"OTEL_EXPORTER_OTLP_ENDPOINT":         "otlp-endpoint",
+		"CMDB_FORMAT":                         "cmdb-format",
+		"CMDB_ENDPOINT":                       "cmdb-endpoint",
+		"CHANGE_TICKET_SYSTEM":                "change-ticket-system",
+		"CHANGE_TICKET_URL":                   "change-ticket-url",
+		"CHANGE_TICKET_ID":                    "change-ticket-id",
+		"CHANGE_TICKET_PROJECT":               "change-ticket-project",
+		"NOTIFY_WEBHOOK_FORMAT":               "notify-webhook-format",
+		"NOTIFY_EVENTS":                       "notify-events",
+		"DEBUG":                               "debug",
+		"ASSUME_YES":                          "yes",
 	}
 	for env, flag := range bindings {
-		if err := viper.BindPFlag(env, rootCmd.Flags().Lookup(flag)); err != nil {
+		if err := viper.BindPFlag(env, rootCmd.PersistentFlags().Lookup(flag)); err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to bind flag %s to env %s: %v\n", flag, env, err)
 		}
 	}
 }
 
 func initConfig() {
+	viper.AutomaticEnv()
+	if profile == "" {
+		profile = os.Getenv("KOPRU_PROFILE")
+	}
+	path := configPath()
+	if cfgFile == "" && path != defaultConfigFile && profile == "" {
+		return
+	}
+	used, err := config.ReadConfigFile(path, profile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if profile != "" {
+		fmt.Fprintf(os.Stderr, "Using config file: %s (profile %s)\n", used, profile)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Using config file:", used)
+}
+
+// configPath returns the configuration file or profiles directory to read: --config,
+// ./kopru-config.env, or ~/.kopru/profiles when the file does not exist.
+func configPath() string {
 	if cfgFile != "" {
-		viper.SetConfigFile(cfgFile)
-	} else {
-		viper.AddConfigPath(".")
-		viper.SetConfigName("kopru-config")
-		viper.SetConfigType("env")
+		return cfgFile
 	}
-	viper.AutomaticEnv()
-	if err := viper.ReadInConfig(); err == nil {
-		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	if _, err := os.Stat(defaultConfigFile); err == nil {
+		return defaultConfigFile
 	}
+	return config.DefaultProfilesDir()
 }
 
 func run(cmd *cobra.Command, args []string) error {
@@ -133,22 +311,66 @@ func run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to initialize logger: %w", err)
 	}
 	defer log.Close()
+	if err := log.SetFormat(cfg.LogFormat); err != nil {
+		return err
+	}
 
 	log.Infof("Kopru version %s", version)
 	log.Infof("Log file: %s", logFileName)
+	if err := i18n.SetLanguage(cfg.Language); err != nil {
+		log.Warningf("%v, using English", err)
+	}
+
+	ctx := context.Background()
+	shutdownTelemetry, err := telemetry.Setup(ctx, cfg.OTLPEndpoint, version)
+	if err != nil {
+		return fmt.Errorf("failed to initialize telemetry: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), telemetryShutdownTimeout)
+		defer cancel()
+		if err := shutdownTelemetry(ctx); err != nil {
+			log.Warningf("Failed to flush telemetry: %v", err)
+		}
+	}()
+
+	if !noPrompt && prompt.IsInteractive() && cfg.Validate() != nil {
+		if err := promptMissing(ctx, cfg, log); err != nil {
+			return fmt.Errorf("failed to prompt for missing configuration: %w", err)
+		}
+	}
 
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("configuration validation failed: %w", err)
 	}
 
-	ctx := context.Background()
+	// An interrupt cancels the run, so that the steps stop and their temporary resources
+	// are cleaned up; a second interrupt exits immediately.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	if cfg.SourcePlatform == "azure" && config.IsComputeNamePattern(cfg.AzureComputeName) {
+		vms, err := workflow.ExpandComputeNames(ctx, cfg, log)
+		if err != nil {
+			return fmt.Errorf("failed to select VMs: %w", err)
+		}
+		if err := workflow.RunBatch(ctx, cfg, log, version, vms); err != nil {
+			log.Error(i18n.T("workflow.failed", err))
+			return err
+		}
+		return nil
+	}
+
 	mgr, err := workflow.NewManager(cfg, log, version)
 	if err != nil {
 		return fmt.Errorf("failed to create workflow manager: %w", err)
 	}
-
 	if err := mgr.Run(ctx); err != nil {
-		log.Errorf("Workflow failed: %v", err)
+		log.Error(i18n.T("workflow.failed", err))
 		return err
 	}
 
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	planGraph       bool
+	planGraphFormat string
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show the steps of the selected workflow without executing them",
+	Long: `Plan resolves the workflow for the configured source and target platforms and prints its
+steps, skip states and artifact dependencies. Use --graph to render the plan as a DOT or
+Mermaid graph for documentation and review before execution.`,
+	RunE: runPlan,
+}
+
+func init() {
+	planCmd.Flags().BoolVar(&planGraph, "graph", false, "Render the plan as a graph")
+	planCmd.Flags().StringVar(&planGraphFormat, "format", workflow.GraphFormatMermaid, "Graph format (dot, mermaid)")
+	rootCmd.AddCommand(planCmd)
+}
+
+func runPlan(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadConfigOffline()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	log := logger.New(cfg.Debug)
+	mgr, err := workflow.NewManagerOffline(cfg, log, version)
+	if err != nil {
+		return fmt.Errorf("failed to create workflow manager: %w", err)
+	}
+
+	if planGraph {
+		return workflow.RenderGraph(os.Stdout, mgr.WorkflowName(), mgr.Steps(), planGraphFormat)
+	}
+	return workflow.RenderPlan(os.Stdout, mgr.WorkflowName(), mgr.Steps())
+}
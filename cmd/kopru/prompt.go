@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/prompt"
+	"github.com/codebypatrickleung/kopru-cli/internal/workflow"
+)
+
+// promptMissing asks for required values that are not configured, offering lists
+// fetched live from Azure and OCI where possible.
+func promptMissing(ctx context.Context, cfg *config.Config, log *logger.Logger) error {
+	p := prompt.New(os.Stdin, os.Stderr)
+
+	if cfg.SourcePlatform == "azure" {
+		if err := promptAzure(ctx, p, cfg, log); err != nil {
+			return err
+		}
+	}
+	if cfg.TargetPlatform == "oci" {
+		if err := promptOCI(ctx, p, cfg, log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func promptAzure(ctx context.Context, p *prompt.Prompter, cfg *config.Config, log *logger.Logger) error {
+	var err error
+	if cfg.AzureResourceGroup == "" {
+		if cfg.AzureResourceGroup, err = p.Input("Azure resource group"); err != nil {
+			return err
+		}
+	}
+	if cfg.AzureComputeName != "" {
+		return nil
+	}
+	provider, err := azure.NewProvider(cfg.AzureSubscriptionID, workflow.AzureAuth(cfg), log)
+	if err != nil {
+		return err
+	}
+	names, err := provider.ListComputeNames(ctx, cfg.AzureResourceGroup)
+	if err != nil {
+		return err
+	}
+	options := make([]prompt.Option, len(names))
+	for i, name := range names {
+		options[i] = prompt.Option{Label: name, Value: name}
+	}
+	if cfg.AzureComputeName, err = p.Select(fmt.Sprintf("Azure VM to migrate (resource group %s)", cfg.AzureResourceGroup), options); err != nil {
+		return err
+	}
+	cfg.DeriveNames()
+	return nil
+}
+
+func promptOCI(ctx context.Context, p *prompt.Prompter, cfg *config.Config, log *logger.Logger) error {
+	var err error
+	if cfg.OCIRegion == "" {
+		region, err := p.Input("OCI region (e.g. us-ashburn-1)")
+		if err != nil {
+			return err
+		}
+		cfg.OCIRegion = config.CanonicalRegion(region)
+	}
+	if cfg.OCICompartmentID != "" && cfg.OCISubnetID != "" {
+		return nil
+	}
+	provider, err := oci.NewProvider(cfg.OCIRegion, log)
+	if err != nil {
+		return err
+	}
+
+	if cfg.OCICompartmentID == "" {
+		compartments, err := provider.ListCompartments(ctx)
+		if err != nil {
+			return err
+		}
+		options := make([]prompt.Option, 0, len(compartments))
+		for _, c := range compartments {
+			if c.Id != nil && c.Name != nil {
+				options = append(options, prompt.Option{Label: *c.Name, Value: *c.Id})
+			}
+		}
+		if cfg.OCICompartmentID, err = p.Select("OCI compartment", options); err != nil {
+			return err
+		}
+	}
+
+	if cfg.OCISubnetID == "" {
+		subnets, err := provider.ListSubnets(ctx, cfg.OCICompartmentID)
+		if err != nil {
+			return err
+		}
+		options := make([]prompt.Option, 0, len(subnets))
+		for _, s := range subnets {
+			if s.Id != nil && s.DisplayName != nil {
+				label := *s.DisplayName
+				if s.CidrBlock != nil {
+					label = fmt.Sprintf("%s (%s)", label, *s.CidrBlock)
+				}
+				options = append(options, prompt.Option{Label: label, Value: *s.Id})
+			}
+		}
+		if cfg.OCISubnetID, err = p.Select("OCI subnet", options); err != nil {
+			return err
+		}
+
+		if cfg.OCIAvailabilityDomain == "" {
+			ads, err := provider.ListAvailabilityDomains(ctx, cfg.OCICompartmentID)
+			if err != nil {
+				return err
+			}
+			options := make([]prompt.Option, 0, len(ads))
+			for i, ad := range ads {
+				if ad.Name != nil {
+					options = append(options, prompt.Option{Label: *ad.Name, Value: strconv.Itoa(i + 1)})
+				}
+			}
+			if len(options) > 1 {
+				if cfg.OCIAvailabilityDomain, err = p.Select("OCI availability domain", options); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
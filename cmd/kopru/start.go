@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var startCmd = &cobra.Command{
+	Use:   "start [instance-id]",
+	Short: "Start an instance deployed with OCI_INSTANCE_STATE=STOPPED",
+	Long: `Start starts an instance that was deployed stopped, once network and DNS work is done, and
+waits for it to run. Without an instance OCID, the instance recorded in kopru-summary.json in
+the current directory is started.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		var instanceID string
+		if len(args) == 1 {
+			instanceID = args[0]
+		}
+		return workflow.StartInstance(context.Background(), cfg, logger.New(cfg.Debug), instanceID)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(startCmd)
+}
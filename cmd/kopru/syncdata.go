@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+var syncDataOpts workflow.DataSyncOptions
+
+var syncDataCmd = &cobra.Command{
+	Use:   "sync-data [disk...]",
+	Short: "Sync changes on Azure data disks to the migrated instance",
+	Long: `Sync-data re-exports the given Azure data disks of AZURE_COMPUTE_NAME (all data disks when none
+are given) and rsyncs the changes made since the migration onto the volumes attached to the
+migrated instance over SSH, for a catch-up pass after the initial bulk migration. Each
+filesystem is synced to the directory where the instance mounts the filesystem with the same
+UUID; files deleted on the source are deleted on the instance.
+
+Without --host, the private IP of the instance recorded in kopru-summary.json is used. The SSH
+user needs passwordless sudo and rsync on the instance.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		syncDataOpts.Disks = args
+		return workflow.SyncData(context.Background(), cfg, logger.New(cfg.Debug), syncDataOpts)
+	},
+}
+
+func init() {
+	syncDataCmd.Flags().StringVar(&syncDataOpts.Host, "host", "", "Address of the migrated instance (default: its private IP from kopru-summary.json)")
+	syncDataCmd.Flags().StringVar(&syncDataOpts.User, "user", "", "SSH user with passwordless sudo on the instance (default: KOPRU_BREAKGLASS_USER)")
+	syncDataCmd.Flags().StringVar(&syncDataOpts.IdentityFile, "identity", "", "SSH private key (default: SSH_KEY_FILE without .pub)")
+	syncDataCmd.Flags().BoolVar(&syncDataOpts.DryRun, "dry-run", false, "List the changes without transferring them")
+	rootCmd.AddCommand(syncDataCmd)
+}
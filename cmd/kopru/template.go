@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/template"
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage the templates of the generated OpenTofu or Terraform files",
+}
+
+var templateExportCmd = &cobra.Command{
+	Use:   "export [dir]",
+	Short: "Write the built-in templates to a directory for customization",
+	Long: `Export writes the built-in templates of the generated files (provider.tf.tmpl, main.tf.tmpl,
+...) to a directory, ./kopru-templates by default. Edit or delete them, add templates such as
+backend.tf.tmpl, and set TEMPLATE_DIR to the directory: templates found there replace the
+built-in ones, and the others are rendered as additional files. Existing files are not
+overwritten.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "kopru-templates"
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		written, err := template.ExportTemplates(dir)
+		for _, path := range written {
+			fmt.Println(path)
+		}
+		return err
+	},
+}
+
+func init() {
+	templateCmd.AddCommand(templateExportCmd)
+	rootCmd.AddCommand(templateCmd)
+}
@@ -0,0 +1,106 @@
+// Package changeticket records migration runs on Jira issues and ServiceNow change requests.
+package changeticket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Supported ticket systems.
+const (
+	SystemJira       = "jira"
+	SystemServiceNow = "servicenow"
+)
+
+const requestTimeout = 30 * time.Second
+
+// Client creates, comments on and attaches files to change tickets.
+type Client interface {
+	// Create opens a new ticket and returns its human-readable ID.
+	Create(ctx context.Context, summary, description string) (string, error)
+	// Comment adds a note to the ticket.
+	Comment(ctx context.Context, id, text string) error
+	// Attach uploads a file to the ticket.
+	Attach(ctx context.Context, id, fileName string, data []byte) error
+}
+
+// Options configures a ticket system client.
+type Options struct {
+	System        string // jira or servicenow
+	BaseURL       string // e.g. https://example.atlassian.net or https://example.service-now.com
+	Authorization string // Authorization header value, e.g. "Basic <base64>" or "Bearer <token>"
+	Project       string // Jira project key used when creating issues
+}
+
+// New returns the client for opts.System.
+func New(opts Options) (Client, error) {
+	h := &httpClient{
+		baseURL:       strings.TrimRight(opts.BaseURL, "/"),
+		authorization: opts.Authorization,
+		client:        &http.Client{Timeout: requestTimeout},
+	}
+	switch opts.System {
+	case SystemJira:
+		return &jiraClient{http: h, project: opts.Project}, nil
+	case SystemServiceNow:
+		return &serviceNowClient{http: h, sysIDs: make(map[string]string)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported change ticket system %q", opts.System)
+	}
+}
+
+// httpClient sends authenticated requests to a ticket system's REST API.
+type httpClient struct {
+	baseURL       string
+	authorization string
+	client        *http.Client
+}
+
+// do sends a request and decodes a JSON response into out when out is not nil.
+func (c *httpClient) do(ctx context.Context, method, path, contentType string, body io.Reader, header http.Header, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.authorization != "" {
+		req.Header.Set("Authorization", c.authorization)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s returned %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// doJSON sends in as a JSON body.
+func (c *httpClient) doJSON(ctx context.Context, method, path string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	return c.do(ctx, method, path, "application/json", bytes.NewReader(body), nil, out)
+}
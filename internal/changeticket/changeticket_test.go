@@ -0,0 +1,137 @@
+package changeticket
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJiraClient(t *testing.T) {
+	var comment, attachment, token, auth string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /rest/api/2/issue", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Fields struct {
+				Project struct{ Key string } `json:"project"`
+			} `json:"fields"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Fields.Project.Key != "OPS" {
+			t.Errorf("Unexpected project: %q", req.Fields.Project.Key)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"id":"10001","key":"OPS-42"}`)
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/OPS-42/comment", func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		var req struct{ Body string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		comment = req.Body
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/OPS-42/attachments", func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("X-Atlassian-Token")
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("Failed to read attachment: %v", err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		attachment = header.Filename + ":" + string(data)
+		_, _ = io.WriteString(w, `[]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := New(Options{System: SystemJira, BaseURL: server.URL + "/", Authorization: "Basic abc", Project: "OPS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	id, err := client.Create(ctx, "Kopru migration", "started")
+	if err != nil || id != "OPS-42" {
+		t.Fatalf("Create() = %q, %v", id, err)
+	}
+	if err := client.Comment(ctx, id, "finished"); err != nil {
+		t.Fatalf("Comment() error = %v", err)
+	}
+	if err := client.Attach(ctx, id, "kopru-summary.json", []byte(`{}`)); err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+	if comment != "finished" || auth != "Basic abc" {
+		t.Errorf("Unexpected comment %q with authorization %q", comment, auth)
+	}
+	if attachment != "kopru-summary.json:{}" || token != "no-check" {
+		t.Errorf("Unexpected attachment %q with token %q", attachment, token)
+	}
+
+	if _, err := (&jiraClient{http: client.(*jiraClient).http}).Create(ctx, "x", "y"); err == nil {
+		t.Error("Expected error when creating an issue without a project")
+	}
+}
+
+func TestServiceNowClient(t *testing.T) {
+	var workNotes, attachment string
+	lookups := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/now/table/change_request", func(w http.ResponseWriter, r *http.Request) {
+		lookups++
+		if q := r.URL.Query().Get("sysparm_query"); q != "number=CHG0030001" {
+			_, _ = io.WriteString(w, `{"result":[]}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"result":[{"sys_id":"abc123","number":"CHG0030001"}]}`)
+	})
+	mux.HandleFunc("PATCH /api/now/table/change_request/abc123", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			WorkNotes string `json:"work_notes"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		workNotes = req.WorkNotes
+		_, _ = io.WriteString(w, `{"result":{}}`)
+	})
+	mux.HandleFunc("POST /api/now/attachment/file", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		data, _ := io.ReadAll(r.Body)
+		attachment = q.Get("table_name") + "/" + q.Get("table_sys_id") + "/" + q.Get("file_name") + ":" + string(data)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"result":{}}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := New(Options{System: SystemServiceNow, BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := client.Comment(ctx, "CHG0030001", "started"); err != nil {
+		t.Fatalf("Comment() error = %v", err)
+	}
+	if err := client.Attach(ctx, "CHG0030001", "kopru-summary.json", []byte(`{}`)); err != nil {
+		t.Fatalf("Attach() error = %v", err)
+	}
+	if workNotes != "started" {
+		t.Errorf("work_notes = %q", workNotes)
+	}
+	if attachment != "change_request/abc123/kopru-summary.json:{}" {
+		t.Errorf("Unexpected attachment: %q", attachment)
+	}
+	if lookups != 1 {
+		t.Errorf("Expected the sys_id to be looked up once, got %d lookups", lookups)
+	}
+
+	if err := client.Comment(ctx, "CHG0000000", "x"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestNewUnsupportedSystem(t *testing.T) {
+	if _, err := New(Options{System: "remedy"}); err == nil {
+		t.Error("Expected error for unsupported system")
+	}
+}
@@ -0,0 +1,68 @@
+package changeticket
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// jiraClient uses the Jira REST API v2, which accepts plain-text descriptions and
+// comments on both Jira Cloud and Data Center.
+type jiraClient struct {
+	http    *httpClient
+	project string
+}
+
+func (c *jiraClient) Create(ctx context.Context, summary, description string) (string, error) {
+	if c.project == "" {
+		return "", fmt.Errorf("a Jira project key is required to create an issue")
+	}
+	req := map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": c.project},
+			"issuetype":   map[string]string{"name": "Task"},
+			"summary":     summary,
+			"description": description,
+		},
+	}
+	var resp struct {
+		Key string `json:"key"`
+	}
+	if err := c.http.doJSON(ctx, http.MethodPost, "/rest/api/2/issue", req, &resp); err != nil {
+		return "", fmt.Errorf("failed to create Jira issue: %w", err)
+	}
+	return resp.Key, nil
+}
+
+func (c *jiraClient) Comment(ctx context.Context, id, text string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(id) + "/comment"
+	if err := c.http.doJSON(ctx, http.MethodPost, path, map[string]string{"body": text}, nil); err != nil {
+		return fmt.Errorf("failed to comment on Jira issue %s: %w", id, err)
+	}
+	return nil
+}
+
+func (c *jiraClient) Attach(ctx context.Context, id, fileName string, data []byte) error {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", fileName)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+	// Jira rejects attachment uploads without this header as a CSRF protection.
+	header := http.Header{"X-Atlassian-Token": {"no-check"}}
+	path := "/rest/api/2/issue/" + url.PathEscape(id) + "/attachments"
+	if err := c.http.do(ctx, http.MethodPost, path, mw.FormDataContentType(), &body, header, nil); err != nil {
+		return fmt.Errorf("failed to attach %s to Jira issue %s: %w", fileName, id, err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package changeticket
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const serviceNowTable = "change_request"
+
+// serviceNowClient uses the ServiceNow Table and Attachment APIs. Change requests are
+// identified by their number (e.g. CHG0030001); the sys_id needed by the APIs is
+// looked up once and cached.
+type serviceNowClient struct {
+	http   *httpClient
+	sysIDs map[string]string
+}
+
+type serviceNowRecord struct {
+	SysID  string `json:"sys_id"`
+	Number string `json:"number"`
+}
+
+func (c *serviceNowClient) Create(ctx context.Context, summary, description string) (string, error) {
+	req := map[string]string{"short_description": summary, "description": description}
+	var resp struct {
+		Result serviceNowRecord `json:"result"`
+	}
+	if err := c.http.doJSON(ctx, http.MethodPost, "/api/now/table/"+serviceNowTable, req, &resp); err != nil {
+		return "", fmt.Errorf("failed to create ServiceNow change request: %w", err)
+	}
+	c.sysIDs[resp.Result.Number] = resp.Result.SysID
+	return resp.Result.Number, nil
+}
+
+func (c *serviceNowClient) Comment(ctx context.Context, id, text string) error {
+	sysID, err := c.sysID(ctx, id)
+	if err != nil {
+		return err
+	}
+	path := "/api/now/table/" + serviceNowTable + "/" + sysID
+	if err := c.http.doJSON(ctx, http.MethodPatch, path, map[string]string{"work_notes": text}, nil); err != nil {
+		return fmt.Errorf("failed to add work notes to ServiceNow change request %s: %w", id, err)
+	}
+	return nil
+}
+
+func (c *serviceNowClient) Attach(ctx context.Context, id, fileName string, data []byte) error {
+	sysID, err := c.sysID(ctx, id)
+	if err != nil {
+		return err
+	}
+	query := url.Values{
+		"table_name":   {serviceNowTable},
+		"table_sys_id": {sysID},
+		"file_name":    {fileName},
+	}
+	path := "/api/now/attachment/file?" + query.Encode()
+	if err := c.http.do(ctx, http.MethodPost, path, "application/json", bytes.NewReader(data), nil, nil); err != nil {
+		return fmt.Errorf("failed to attach %s to ServiceNow change request %s: %w", fileName, id, err)
+	}
+	return nil
+}
+
+// sysID returns the sys_id of the change request with the given number.
+func (c *serviceNowClient) sysID(ctx context.Context, number string) (string, error) {
+	if sysID, ok := c.sysIDs[number]; ok {
+		return sysID, nil
+	}
+	query := url.Values{
+		"sysparm_query":  {"number=" + number},
+		"sysparm_fields": {"sys_id,number"},
+		"sysparm_limit":  {"1"},
+	}
+	var resp struct {
+		Result []serviceNowRecord `json:"result"`
+	}
+	if err := c.http.do(ctx, http.MethodGet, "/api/now/table/"+serviceNowTable+"?"+query.Encode(), "", nil, nil, &resp); err != nil {
+		return "", fmt.Errorf("failed to look up ServiceNow change request %s: %w", number, err)
+	}
+	if len(resp.Result) == 0 {
+		return "", fmt.Errorf("ServiceNow change request %s not found", number)
+	}
+	c.sysIDs[number] = resp.Result[0].SysID
+	return resp.Result[0].SysID, nil
+}
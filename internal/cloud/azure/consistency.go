@@ -0,0 +1,177 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+const (
+	freezeDelay       = 10 * time.Second // Lets the VM agent report the freeze command before the filesystems freeze
+	minSnapshotWindow = 15 * time.Second // Time needed to start the snapshots of all disks
+)
+
+// Quiesce makes the export snapshots of a running VM application-consistent. The
+// pre-snapshot script runs first, then the filesystems of Linux VMs are frozen for
+// Freeze, the snapshots of all disks are started together, and the post-snapshot
+// script runs once the filesystems are thawed. Scripts run through Run Command.
+type Quiesce struct {
+	Windows    bool
+	PreScript  string
+	PostScript string
+	Freeze     time.Duration // fsfreeze window on Linux VMs; zero disables fsfreeze
+}
+
+// freezeScript returns the Linux script that freezes the local filesystems after
+// delay and thaws them after window. The freeze runs detached, because a frozen root
+// filesystem blocks the VM agent, so the filesystems are thawed on schedule even if
+// Kopru is interrupted. Nested mounts are frozen before their parents and thawed after.
+func freezeScript(delay, window time.Duration) string {
+	return fmt.Sprintf(`freeze=$(findmnt -rn -t ext3,ext4,xfs,btrfs -o TARGET | sort -ru | tr '\n' ' ')
+thaw=$(findmnt -rn -t ext3,ext4,xfs,btrfs -o TARGET | sort -u | tr '\n' ' ')
+[ -n "$freeze" ] || { echo "no filesystems to freeze"; exit 1; }
+sync
+setsid sh -c "sleep %d; for m in $freeze; do fsfreeze -f \$m; done; sleep %d; for m in $thaw; do fsfreeze -u \$m; done" >/dev/null 2>&1 </dev/null &
+echo "freezing $freeze"`, int(delay.Seconds()), int(window.Seconds()))
+}
+
+// thawScript thaws all local filesystems, in case the scheduled thaw did not run.
+const thawScript = `for m in $(findmnt -rn -t ext3,ext4,xfs,btrfs -o TARGET | sort -u); do fsfreeze -u "$m" 2>/dev/null; done; true`
+
+// consistentSnapshot is a snapshot taken by CreateConsistentSnapshots and the teardown
+// action that deletes it.
+type consistentSnapshot struct {
+	name   string
+	delete func()
+}
+
+// groupSnapshot returns the consistent snapshot of a disk, if there is one, and
+// forgets it so that it is exported once.
+func (p *Provider) groupSnapshot(diskName string) (consistentSnapshot, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	snapshot, ok := p.groupSnapshots[diskName]
+	delete(p.groupSnapshots, diskName)
+	return snapshot, ok
+}
+
+// CreateConsistentSnapshots snapshots the disks of a running VM at the same point in
+// time while it is quiesced as set by the Quiesce of ConfigureSnapshots, so that disks
+// of databases and other crash-sensitive workloads are consistent with each other and
+// with the applications. ExportAzureDisk then exports these snapshots instead of
+// taking its own.
+func (p *Provider) CreateConsistentSnapshots(ctx context.Context, resourceGroup, vmName string, diskNames []string) (err error) {
+	q := p.snapshotOptions.Quiesce
+	if q == nil {
+		return errors.New("no quiesce options configured")
+	}
+	if q.PreScript != "" {
+		p.logger.Info("Running the pre-snapshot script on the VM...")
+		output, err := p.RunCommand(ctx, resourceGroup, vmName, q.Windows, q.PreScript)
+		p.logOutput(output)
+		if err != nil {
+			return fmt.Errorf("pre-snapshot script failed: %w", err)
+		}
+	}
+	var thawAt time.Time
+	defer func() {
+		if !thawAt.IsZero() {
+			if wait := time.Until(thawAt); wait > 0 {
+				p.logger.Infof("Waiting %s for the filesystems to thaw...", wait.Round(time.Second))
+				time.Sleep(wait)
+			}
+			if _, thawErr := p.RunCommand(ctx, resourceGroup, vmName, false, thawScript); thawErr != nil {
+				p.logger.Warningf("Could not confirm that the filesystems of the VM are thawed: %v", thawErr)
+			} else {
+				p.logger.Success("✓ Filesystems thawed")
+			}
+		}
+		if q.PostScript != "" {
+			p.logger.Info("Running the post-snapshot script on the VM...")
+			output, postErr := p.RunCommand(ctx, resourceGroup, vmName, q.Windows, q.PostScript)
+			p.logOutput(output)
+			if postErr != nil {
+				err = errors.Join(err, fmt.Errorf("post-snapshot script failed: %w", postErr))
+			}
+		}
+	}()
+
+	var deadline time.Time
+	if q.Freeze > 0 {
+		start := time.Now()
+		p.logger.Infof("Scheduling a %s filesystem freeze on the VM...", q.Freeze)
+		output, err := p.RunCommand(ctx, resourceGroup, vmName, false, freezeScript(freezeDelay, q.Freeze))
+		p.logOutput(output)
+		if err != nil {
+			return fmt.Errorf("failed to freeze filesystems: %w", err)
+		}
+		// The script started between start and now, so the filesystems are frozen
+		// from now+freezeDelay at the latest until start+freezeDelay+Freeze at the earliest.
+		frozen := time.Now().Add(freezeDelay + time.Second)
+		deadline = start.Add(freezeDelay + q.Freeze)
+		thawAt = deadline.Add(time.Second)
+		if deadline.Sub(frozen) < minSnapshotWindow {
+			return fmt.Errorf("run command took %s, the filesystems thaw before the snapshots can be started; increase AZURE_FREEZE_SECONDS", frozen.Sub(start).Round(time.Second))
+		}
+		time.Sleep(time.Until(frozen))
+		p.logger.Success("✓ Filesystems frozen")
+	}
+
+	names := make(map[string]string, len(diskNames))
+	pollers := make([]*runtime.Poller[armcompute.SnapshotsClientCreateOrUpdateResponse], len(diskNames))
+	errs := make([]error, len(diskNames))
+	var wg sync.WaitGroup
+	for i, diskName := range diskNames {
+		names[diskName] = renderSnapshotName(p.snapshotOptions.NameTemplate, p.snapshotOptions.MigrationID, diskName, time.Now())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pollers[i], errs[i] = p.beginSnapshot(ctx, resourceGroup, names[diskName], diskName, false)
+		}()
+	}
+	wg.Wait()
+	snapshots := make(map[string]consistentSnapshot, len(diskNames))
+	for i, diskName := range diskNames {
+		if pollers[i] != nil {
+			name := names[diskName]
+			snapshots[diskName] = consistentSnapshot{name: name, delete: p.registerTeardown("delete snapshot "+name, p.snapshotDeleter(resourceGroup, name))}
+		}
+	}
+	if !deadline.IsZero() && time.Now().After(deadline) {
+		errs = append(errs, errors.New("the snapshots were not started before the filesystems thawed; increase AZURE_FREEZE_SECONDS"))
+	}
+	for i, poller := range pollers {
+		if poller == nil {
+			continue
+		}
+		if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+			errs[i] = fmt.Errorf("failed to create snapshot of %s: %w", diskNames[i], err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		for _, snapshot := range snapshots {
+			snapshot.delete()
+		}
+		return err
+	}
+	p.mu.Lock()
+	p.groupSnapshots = snapshots
+	p.mu.Unlock()
+	p.logger.Successf("✓ Consistent snapshots of %d disk(s) created", len(diskNames))
+	return nil
+}
+
+// logOutput logs the output of a run command line by line.
+func (p *Provider) logOutput(output string) {
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line != "" {
+			p.logger.Infof("  | %s", line)
+		}
+	}
+}
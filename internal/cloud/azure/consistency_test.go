@@ -0,0 +1,61 @@
+package azure
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+func TestFreezeScript(t *testing.T) {
+	script := freezeScript(10*time.Second, 90*time.Second)
+	for _, want := range []string{
+		"sort -ru", // nested mounts before their parents
+		"sleep 10; for m in $freeze; do fsfreeze -f \\$m; done; sleep 90; for m in $thaw; do fsfreeze -u \\$m; done",
+		"setsid sh -c",
+		">/dev/null 2>&1 </dev/null &",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("freezeScript() missing %q:\n%s", want, script)
+		}
+	}
+}
+
+func TestRunCommandOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []*armcompute.InstanceViewStatus
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "linux succeeded",
+			statuses: []*armcompute.InstanceViewStatus{{Code: to.Ptr("ProvisioningState/succeeded"), Level: to.Ptr(armcompute.StatusLevelTypesInfo), Message: to.Ptr("Enable succeeded: \n[stdout]\nfreezing / \n")}},
+			want:     "Enable succeeded: \n[stdout]\nfreezing / \n",
+		},
+		{
+			name: "windows stderr",
+			statuses: []*armcompute.InstanceViewStatus{
+				{Code: to.Ptr("ComponentStatus/StdOut/succeeded"), Level: to.Ptr(armcompute.StatusLevelTypesInfo), Message: to.Ptr("done")},
+				{Code: to.Ptr("ComponentStatus/StdErr/succeeded"), Level: to.Ptr(armcompute.StatusLevelTypesInfo), Message: to.Ptr("")},
+			},
+			want: "done",
+		},
+		{
+			name:     "failed",
+			statuses: []*armcompute.InstanceViewStatus{{Code: to.Ptr("ProvisioningState/failed"), Level: to.Ptr(armcompute.StatusLevelTypesError), Message: to.Ptr("Enable failed")}},
+			want:     "Enable failed",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runCommandOutput(tt.statuses)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("runCommandOutput() = %q, %v, want %q (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+// ComputeDependencies are the Azure resources and services a Compute instance depends on
+// outside its disks, which do not carry over to another cloud.
+type ComputeDependencies struct {
+	Extensions             []VMExtension
+	SystemAssignedIdentity bool
+	UserAssignedIdentities []string // Resource IDs of the user-assigned managed identities
+	KeyVaultCertificates   []string // Resource IDs of the key vaults whose certificates are installed on the VM
+	DiskEncryptionSets     []string // Resource IDs of the disk encryption sets of customer-managed keys
+}
+
+// VMExtension is an extension installed on a Compute instance.
+type VMExtension struct {
+	Name      string
+	Publisher string
+	Type      string
+	Version   string
+}
+
+// GetComputeDependencies retrieves the extensions, managed identities, Key Vault
+// certificates and disk encryption sets of a Compute instance.
+func (p *Provider) GetComputeDependencies(ctx context.Context, resourceGroup, computeName string) (*ComputeDependencies, error) {
+	vm, err := p.GetComputeInfo(ctx, resourceGroup, computeName)
+	if err != nil {
+		return nil, err
+	}
+	deps := computeDependencies(vm)
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute client factory: %w", err)
+	}
+	extensions, err := clientFactory.NewVirtualMachineExtensionsClient().List(ctx, resourceGroup, computeName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list VM extensions: %w", err)
+	}
+	deps.Extensions = vmExtensions(extensions.Value)
+	return deps, nil
+}
+
+// computeDependencies extracts the managed identities, Key Vault certificates and disk
+// encryption sets of vm.
+func computeDependencies(vm *armcompute.VirtualMachine) *ComputeDependencies {
+	deps := &ComputeDependencies{}
+	if id := vm.Identity; id != nil && id.Type != nil {
+		switch *id.Type {
+		case armcompute.ResourceIdentityTypeSystemAssigned, armcompute.ResourceIdentityTypeSystemAssignedUserAssigned:
+			deps.SystemAssignedIdentity = true
+		}
+		for resourceID := range id.UserAssignedIdentities {
+			deps.UserAssignedIdentities = append(deps.UserAssignedIdentities, resourceID)
+		}
+		sort.Strings(deps.UserAssignedIdentities)
+	}
+	if vm.Properties == nil {
+		return deps
+	}
+	if profile := vm.Properties.OSProfile; profile != nil {
+		for _, secret := range profile.Secrets {
+			if secret != nil && secret.SourceVault != nil && secret.SourceVault.ID != nil {
+				deps.KeyVaultCertificates = append(deps.KeyVaultCertificates, *secret.SourceVault.ID)
+			}
+		}
+	}
+	if sp := vm.Properties.StorageProfile; sp != nil {
+		seen := make(map[string]bool)
+		addSet := func(disk *armcompute.ManagedDiskParameters) {
+			if disk == nil || disk.DiskEncryptionSet == nil || disk.DiskEncryptionSet.ID == nil || seen[*disk.DiskEncryptionSet.ID] {
+				return
+			}
+			seen[*disk.DiskEncryptionSet.ID] = true
+			deps.DiskEncryptionSets = append(deps.DiskEncryptionSets, *disk.DiskEncryptionSet.ID)
+		}
+		if sp.OSDisk != nil {
+			addSet(sp.OSDisk.ManagedDisk)
+		}
+		for _, disk := range sp.DataDisks {
+			if disk != nil {
+				addSet(disk.ManagedDisk)
+			}
+		}
+	}
+	return deps
+}
+
+// vmExtensions converts the extensions of the VM extensions API, sorted by name.
+func vmExtensions(extensions []*armcompute.VirtualMachineExtension) []VMExtension {
+	var result []VMExtension
+	for _, e := range extensions {
+		if e == nil || e.Name == nil {
+			continue
+		}
+		ext := VMExtension{Name: *e.Name}
+		if props := e.Properties; props != nil {
+			if props.Publisher != nil {
+				ext.Publisher = *props.Publisher
+			}
+			if props.Type != nil {
+				ext.Type = *props.Type
+			}
+			if props.TypeHandlerVersion != nil {
+				ext.Version = *props.TypeHandlerVersion
+			}
+		}
+		result = append(result, ext)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
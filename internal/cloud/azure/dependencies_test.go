@@ -0,0 +1,64 @@
+package azure
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+func TestComputeDependencies(t *testing.T) {
+	const prefix = "/subscriptions/s/resourceGroups/rg/providers/"
+	identityType := armcompute.ResourceIdentityTypeSystemAssignedUserAssigned
+	des := &armcompute.DiskEncryptionSetParameters{ID: to.Ptr(prefix + "Microsoft.Compute/diskEncryptionSets/cmk")}
+	vm := &armcompute.VirtualMachine{
+		Identity: &armcompute.VirtualMachineIdentity{
+			Type: &identityType,
+			UserAssignedIdentities: map[string]*armcompute.UserAssignedIdentitiesValue{
+				prefix + "Microsoft.ManagedIdentity/userAssignedIdentities/web-id": {},
+				prefix + "Microsoft.ManagedIdentity/userAssignedIdentities/app-id": {},
+			},
+		},
+		Properties: &armcompute.VirtualMachineProperties{
+			OSProfile: &armcompute.OSProfile{Secrets: []*armcompute.VaultSecretGroup{
+				{SourceVault: &armcompute.SubResource{ID: to.Ptr(prefix + "Microsoft.KeyVault/vaults/web-kv")}},
+				nil,
+			}},
+			StorageProfile: &armcompute.StorageProfile{
+				OSDisk:    &armcompute.OSDisk{ManagedDisk: &armcompute.ManagedDiskParameters{DiskEncryptionSet: des}},
+				DataDisks: []*armcompute.DataDisk{{ManagedDisk: &armcompute.ManagedDiskParameters{DiskEncryptionSet: des}}, {ManagedDisk: &armcompute.ManagedDiskParameters{}}},
+			},
+		},
+	}
+	deps := computeDependencies(vm)
+	if !deps.SystemAssignedIdentity {
+		t.Error("Expected a system-assigned identity")
+	}
+	if want := []string{prefix + "Microsoft.ManagedIdentity/userAssignedIdentities/app-id", prefix + "Microsoft.ManagedIdentity/userAssignedIdentities/web-id"}; !slices.Equal(deps.UserAssignedIdentities, want) {
+		t.Errorf("UserAssignedIdentities = %v, want %v", deps.UserAssignedIdentities, want)
+	}
+	if len(deps.KeyVaultCertificates) != 1 || len(deps.DiskEncryptionSets) != 1 {
+		t.Errorf("KeyVaultCertificates = %v, DiskEncryptionSets = %v", deps.KeyVaultCertificates, deps.DiskEncryptionSets)
+	}
+
+	if deps := computeDependencies(&armcompute.VirtualMachine{}); deps.SystemAssignedIdentity || len(deps.UserAssignedIdentities) > 0 {
+		t.Errorf("computeDependencies() of a VM without identity = %+v", deps)
+	}
+}
+
+func TestVMExtensions(t *testing.T) {
+	extensions := vmExtensions([]*armcompute.VirtualMachineExtension{
+		{Name: to.Ptr("KeyVaultForLinux"), Properties: &armcompute.VirtualMachineExtensionProperties{
+			Publisher: to.Ptr("Microsoft.Azure.KeyVault"), Type: to.Ptr("KeyVaultForLinux"), TypeHandlerVersion: to.Ptr("2.0"),
+		}},
+		{Name: to.Ptr("AADSSHLogin")},
+		nil,
+	})
+	if len(extensions) != 2 || extensions[0].Name != "AADSSHLogin" {
+		t.Fatalf("vmExtensions() = %+v", extensions)
+	}
+	if e := extensions[1]; e.Publisher != "Microsoft.Azure.KeyVault" || e.Type != "KeyVaultForLinux" || e.Version != "2.0" {
+		t.Errorf("vmExtensions() = %+v", e)
+	}
+}
@@ -0,0 +1,114 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+// ComputeSummary describes a Compute instance listed by ListComputeSummaries.
+type ComputeSummary struct {
+	Name          string
+	ID            string
+	ResourceGroup string
+	Location      string
+	Size          string
+	OSType        string
+	Image         string // <publisher>:<offer>:<sku> of the marketplace image, if any
+	OSDiskGB      int32
+	DataDisks     int
+	DataDisksGB   int32
+	Tags          map[string]string
+}
+
+// ListComputeSummaries lists the Compute instances of a resource group, or of the
+// subscription when resourceGroup is empty, with their size, OS and disks.
+func (p *Provider) ListComputeSummaries(ctx context.Context, resourceGroup string) ([]ComputeSummary, error) {
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute client factory: %w", err)
+	}
+	vmClient := clientFactory.NewVirtualMachinesClient()
+	var vms []ComputeSummary
+	add := func(page []*armcompute.VirtualMachine) {
+		for _, vm := range page {
+			if vm != nil && vm.Name != nil {
+				vms = append(vms, computeSummary(vm))
+			}
+		}
+	}
+	if resourceGroup != "" {
+		pager := vmClient.NewListPager(resourceGroup, nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list Compute instances: %w", err)
+			}
+			add(page.Value)
+		}
+		return vms, nil
+	}
+	pager := vmClient.NewListAllPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Compute instances: %w", err)
+		}
+		add(page.Value)
+	}
+	return vms, nil
+}
+
+// computeSummary extracts the summary of vm, whose disk sizes are those recorded in its
+// storage profile.
+func computeSummary(vm *armcompute.VirtualMachine) ComputeSummary {
+	s := ComputeSummary{Name: *vm.Name, Tags: make(map[string]string, len(vm.Tags))}
+	if vm.ID != nil {
+		s.ID = *vm.ID
+		if id, err := arm.ParseResourceID(s.ID); err == nil {
+			s.ResourceGroup = id.ResourceGroupName
+		}
+	}
+	if vm.Location != nil {
+		s.Location = *vm.Location
+	}
+	for k, v := range vm.Tags {
+		if v != nil {
+			s.Tags[k] = *v
+		}
+	}
+	if vm.Properties == nil {
+		return s
+	}
+	if hw := vm.Properties.HardwareProfile; hw != nil && hw.VMSize != nil {
+		s.Size = string(*hw.VMSize)
+	}
+	sp := vm.Properties.StorageProfile
+	if sp == nil {
+		return s
+	}
+	if sp.OSDisk != nil {
+		if sp.OSDisk.OSType != nil {
+			s.OSType = string(*sp.OSDisk.OSType)
+		}
+		if sp.OSDisk.DiskSizeGB != nil {
+			s.OSDiskGB = *sp.OSDisk.DiskSizeGB
+		}
+	}
+	if ref := sp.ImageReference; ref != nil && ref.Publisher != nil && ref.Offer != nil && ref.SKU != nil {
+		s.Image = strings.Join([]string{*ref.Publisher, *ref.Offer, *ref.SKU}, ":")
+	}
+	for _, disk := range sp.DataDisks {
+		if disk == nil {
+			continue
+		}
+		s.DataDisks++
+		if disk.DiskSizeGB != nil {
+			s.DataDisksGB += *disk.DiskSizeGB
+		}
+	}
+	return s
+}
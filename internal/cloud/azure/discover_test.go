@@ -0,0 +1,44 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+func TestComputeSummary(t *testing.T) {
+	linux := armcompute.OperatingSystemTypesLinux
+	size := armcompute.VirtualMachineSizeTypesStandardD4SV3
+	vm := &armcompute.VirtualMachine{
+		Name:     to.Ptr("web-01"),
+		ID:       to.Ptr("/subscriptions/s/resourceGroups/web-rg/providers/Microsoft.Compute/virtualMachines/web-01"),
+		Location: to.Ptr("westeurope"),
+		Tags:     map[string]*string{"migrate": to.Ptr("true"), "empty": nil},
+		Properties: &armcompute.VirtualMachineProperties{
+			HardwareProfile: &armcompute.HardwareProfile{VMSize: &size},
+			StorageProfile: &armcompute.StorageProfile{
+				OSDisk:         &armcompute.OSDisk{OSType: &linux, DiskSizeGB: to.Ptr[int32](30)},
+				ImageReference: &armcompute.ImageReference{Publisher: to.Ptr("Canonical"), Offer: to.Ptr("ubuntu-24_04-lts"), SKU: to.Ptr("server")},
+				DataDisks:      []*armcompute.DataDisk{{DiskSizeGB: to.Ptr[int32](128)}, {DiskSizeGB: to.Ptr[int32](256)}, nil},
+			},
+		},
+	}
+	s := computeSummary(vm)
+	if s.Name != "web-01" || s.ResourceGroup != "web-rg" || s.Location != "westeurope" || s.Size != "Standard_D4s_v3" {
+		t.Errorf("computeSummary() = %+v", s)
+	}
+	if s.OSType != "Linux" || s.Image != "Canonical:ubuntu-24_04-lts:server" || s.OSDiskGB != 30 {
+		t.Errorf("computeSummary() OS = %q, %q, %d GB", s.OSType, s.Image, s.OSDiskGB)
+	}
+	if s.DataDisks != 2 || s.DataDisksGB != 384 {
+		t.Errorf("computeSummary() data disks = %d, %d GB, want 2, 384 GB", s.DataDisks, s.DataDisksGB)
+	}
+	if len(s.Tags) != 1 || s.Tags["migrate"] != "true" {
+		t.Errorf("computeSummary() tags = %v", s.Tags)
+	}
+
+	if s := computeSummary(&armcompute.VirtualMachine{Name: to.Ptr("bare")}); s.Name != "bare" || s.Size != "" {
+		t.Errorf("computeSummary() of a VM without properties = %+v", s)
+	}
+}
@@ -0,0 +1,381 @@
+// Package azure provides parallel, resumable blob downloads.
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
+	"github.com/codebypatrickleung/kopru-cli/internal/retry"
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultDownloadBlockSizeMB = 64
+	defaultDownloadWorkers     = 8
+	defaultDownloadMBPerSecond = 25
+	minSASDuration             = time.Hour
+	downloadBlockRetries       = 3
+	manifestSuffix             = ".manifest.json"
+)
+
+// downloadCheckpointInterval is how often the blocks written by a download are flushed
+// and recorded in its manifest. Tests shorten it.
+var downloadCheckpointInterval = 30 * time.Second
+
+// snapshotRef identifies the snapshot a disk is downloaded from.
+type snapshotRef struct {
+	Name string
+	ID   string // Unique ID of the snapshot resource, which changes when it is recreated
+}
+
+// downloadManifest records the progress of a ranged download so it can be resumed.
+// Source identifies the content of the blob, and Snapshot and SnapshotID the snapshot
+// it was exported from, so that the blocks of one snapshot are never resumed into the
+// download of another. ExportAzureDisk reuses the snapshot of the manifest in the next
+// run, since a new snapshot is a new blob.
+type downloadManifest struct {
+	Source     string `json:"source"`
+	Snapshot   string `json:"snapshot,omitempty"`
+	SnapshotID string `json:"snapshotId,omitempty"`
+	Size       int64  `json:"size"`
+	BlockSize  int64  `json:"blockSize"`
+	Completed  []bool `json:"completed"`
+}
+
+// ConfigureDownload sets the block size (in MB), the number of concurrent workers used
+// when downloading disks, and the expected download throughput (in MB/s) that the
+// validity of snapshot SAS URLs is derived from. Values below 1 keep the defaults.
+func (p *Provider) ConfigureDownload(blockSizeMB, workers, mbPerSecond int) {
+	if blockSizeMB > 0 {
+		p.downloadBlockSize = int64(blockSizeMB) * 1024 * 1024
+	}
+	if workers > 0 {
+		p.downloadWorkers = workers
+	}
+	if mbPerSecond > 0 {
+		p.downloadMBPerSecond = mbPerSecond
+	}
+}
+
+// sasDuration returns the validity of a SAS URL for downloading sizeBytes at mbPerSecond:
+// twice the expected download time, to allow for slower periods and retries, plus an
+// hour for the SAS grant and download start-up.
+func sasDuration(sizeBytes int64, mbPerSecond int) time.Duration {
+	expected := time.Duration(sizeBytes/(int64(mbPerSecond)*1024*1024)+1) * time.Second
+	return 2*expected + minSASDuration
+}
+
+// DownloadFromSASURL downloads a blob from a SAS URL using concurrent ranged GETs.
+// Progress is tracked in a sidecar manifest next to destFile, so an interrupted
+// download resumes from the blocks already written instead of starting over.
+func (p *Provider) DownloadFromSASURL(ctx context.Context, sasURL, destFile string) error {
+	return p.downloadSnapshot(ctx, sasURL, destFile, snapshotRef{})
+}
+
+// downloadSnapshot downloads the blob at sasURL, exported from snapshot, like
+// DownloadFromSASURL. The manifest records the snapshot, so that the download resumes
+// from a new SAS URL of the same snapshot.
+func (p *Provider) downloadSnapshot(ctx context.Context, sasURL, destFile string, snapshot snapshotRef) (err error) {
+	ctx, span := telemetry.StartSpan(ctx, "azure.DownloadFromSASURL", attribute.String("file", filepath.Base(destFile)))
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	blobClient, err := blob.NewClientWithNoCredential(sasURL, p.blobClientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create blob client: %w", err)
+	}
+	props, err := blobClient.GetProperties(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get blob properties: %w", err)
+	}
+	if props.ContentLength == nil {
+		return fmt.Errorf("blob content length not available")
+	}
+	size := *props.ContentLength
+	source := blobSource(props.ETag, props.LastModified)
+
+	blockSize, workers := p.downloadBlockSize, p.downloadWorkers
+	if blockSize <= 0 {
+		blockSize = defaultDownloadBlockSizeMB * 1024 * 1024
+	}
+	if workers <= 0 {
+		workers = defaultDownloadWorkers
+	}
+
+	manifestFile := destFile + manifestSuffix
+	manifest := loadDownloadManifest(manifestFile, source, snapshot, size, blockSize)
+	if manifest == nil {
+		if _, err := os.Stat(manifestFile); err == nil {
+			p.logger.Infof("Restarting download of %s: the partial download is from another snapshot", destFile)
+		}
+		blocks := (size + blockSize - 1) / blockSize
+		manifest = &downloadManifest{Source: source, Size: size, BlockSize: blockSize, Completed: make([]bool, blocks)}
+	} else if done := manifest.completedBlocks(); done > 0 {
+		p.logger.Infof("Resuming download of %s: %d of %d blocks already downloaded", destFile, done, len(manifest.Completed))
+	}
+	manifest.Source, manifest.Snapshot, manifest.SnapshotID = source, snapshot.Name, snapshot.ID
+
+	// #nosec G304 -- destFile is controlled by the application
+	out, err := os.OpenFile(destFile, os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer out.Close()
+	if err := out.Truncate(size); err != nil {
+		return fmt.Errorf("failed to allocate file: %w", err)
+	}
+	if err := manifest.save(manifestFile); err != nil {
+		return err
+	}
+
+	p.logger.Infof("Downloading %d GB in %d MB blocks using %d workers", size/(1024*1024*1024), blockSize/(1024*1024), workers)
+
+	rep := progress.New(p.logger, "Downloading "+filepath.Base(destFile), size).Record(progress.PhaseDownload, filepath.Base(destFile))
+	defer rep.Done()
+	var pending []int
+	for idx, done := range manifest.Completed {
+		if done {
+			rep.Skip(manifest.blockLength(idx))
+		} else {
+			pending = append(pending, idx)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex // Guards manifest.Completed
+	var saveMu sync.Mutex
+	// checkpoint flushes the blocks written so far and records them in the manifest. The
+	// completed blocks are taken before the flush, so that every block the manifest
+	// records is on disk.
+	checkpoint := func() error {
+		saveMu.Lock()
+		defer saveMu.Unlock()
+		mu.Lock()
+		saved := *manifest
+		saved.Completed = slices.Clone(manifest.Completed)
+		mu.Unlock()
+		if err := out.Sync(); err != nil {
+			return fmt.Errorf("failed to flush downloaded blocks: %w", err)
+		}
+		return saved.save(manifestFile)
+	}
+	stopCheckpoints := make(chan struct{})
+	checkpointsDone := make(chan struct{})
+	go func() {
+		defer close(checkpointsDone)
+		ticker := time.NewTicker(downloadCheckpointInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCheckpoints:
+				return
+			case <-ticker.C:
+				if err := checkpoint(); err != nil {
+					p.logger.Warningf("Failed to update download manifest: %v", err)
+				}
+			}
+		}
+	}()
+
+	blocks := make(chan int)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range blocks {
+				if err := downloadBlockWithRetry(ctx, blobClient, out, manifest, idx); err != nil {
+					errs[w] = err
+					cancel()
+					return
+				}
+				rep.Add(manifest.blockLength(idx))
+				telemetry.AddDownloadedBytes(ctx, manifest.blockLength(idx))
+				mu.Lock()
+				manifest.Completed[idx] = true
+				mu.Unlock()
+			}
+		}()
+	}
+dispatch:
+	for _, idx := range pending {
+		select {
+		case blocks <- idx:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(blocks)
+	wg.Wait()
+	close(stopCheckpoints)
+	<-checkpointsDone
+
+	if failed := errors.Join(errs...); failed != nil || ctx.Err() != nil {
+		if err := checkpoint(); err != nil {
+			p.logger.Warningf("Failed to update download manifest: %v", err)
+		}
+		if failed != nil {
+			return fmt.Errorf("failed to download blob (progress saved to %s): %w", manifestFile, failed)
+		}
+		return fmt.Errorf("download interrupted (progress saved to %s): %w", manifestFile, ctx.Err())
+	}
+	if err := out.Sync(); err != nil {
+		return fmt.Errorf("failed to flush downloaded file: %w", err)
+	}
+	if err := os.Remove(manifestFile); err != nil && !os.IsNotExist(err) {
+		p.logger.Warningf("Failed to remove download manifest %s: %v", manifestFile, err)
+	}
+	return nil
+}
+
+// downloadBlockWithRetry downloads a single block, retrying transient failures.
+func downloadBlockWithRetry(ctx context.Context, client *blob.Client, out *os.File, m *downloadManifest, idx int) error {
+	offset := int64(idx) * m.BlockSize
+	if err := downloadRangeWithRetry(ctx, client, out, offset, m.blockLength(idx)); err != nil {
+		return fmt.Errorf("block %d (offset %d) %w", idx, offset, err)
+	}
+	return nil
+}
+
+// downloadRangeWithRetry downloads count bytes at offset, retrying transient failures.
+func downloadRangeWithRetry(ctx context.Context, client *blob.Client, out *os.File, offset, count int64) error {
+	var lastErr error
+	for attempt := 1; attempt <= downloadBlockRetries; attempt++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt > 1 {
+			telemetry.AddRetries(ctx, "blob_download", 1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(retry.Default().Delay(attempt)):
+			}
+		}
+		if lastErr = downloadRange(ctx, client, out, offset, count); lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("failed after %d attempts: %w", downloadBlockRetries, lastErr)
+}
+
+func downloadRange(ctx context.Context, client *blob.Client, out *os.File, offset, count int64) error {
+	resp, err := client.DownloadStream(ctx, &blob.DownloadStreamOptions{
+		Range: blob.HTTPRange{Offset: offset, Count: count},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	n, err := io.Copy(io.NewOffsetWriter(out, offset), resp.Body)
+	if err != nil {
+		return err
+	}
+	if n != count {
+		return fmt.Errorf("short read: got %d of %d bytes", n, count)
+	}
+	return nil
+}
+
+// loadDownloadManifest returns the manifest at path if it was written for the same
+// snapshot, or else the same blob source, and the same size and block size. A blob
+// without a known snapshot or source is never resumed.
+func loadDownloadManifest(path, source string, snapshot snapshotRef, size, blockSize int64) *downloadManifest {
+	if source == "" && snapshot.ID == "" {
+		return nil
+	}
+	m, err := readDownloadManifest(path)
+	if err != nil {
+		return nil
+	}
+	sameSnapshot := snapshot.ID != "" && m.SnapshotID == snapshot.ID
+	sameSource := source != "" && m.Source == source
+	if !sameSnapshot && !sameSource || m.Size != size || m.BlockSize != blockSize || int64(len(m.Completed)) != (size+blockSize-1)/blockSize {
+		return nil
+	}
+	return m
+}
+
+// readDownloadManifest reads the manifest at path.
+func readDownloadManifest(path string) (*downloadManifest, error) {
+	// #nosec G304 -- path is controlled by the application
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m downloadManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode download manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// blobClientOptions returns the options of the blob clients that download snapshots,
+// which only differ from the defaults when tests replace the transport.
+func (p *Provider) blobClientOptions() *blob.ClientOptions {
+	if p.transport == nil {
+		return nil
+	}
+	return &blob.ClientOptions{ClientOptions: policy.ClientOptions{Transport: p.transport}}
+}
+
+// blobSource identifies the content of a blob by its ETag, or by its last modification
+// time when it has none. It is empty when neither is known.
+func blobSource(etag *azcore.ETag, lastModified *time.Time) string {
+	if etag != nil && *etag != "" {
+		return string(*etag)
+	}
+	if lastModified != nil {
+		return lastModified.UTC().Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// blockLength returns the number of bytes in block idx; the last block may be short.
+func (m *downloadManifest) blockLength(idx int) int64 {
+	offset := int64(idx) * m.BlockSize
+	if offset+m.BlockSize > m.Size {
+		return m.Size - offset
+	}
+	return m.BlockSize
+}
+
+func (m *downloadManifest) completedBlocks() int {
+	n := 0
+	for _, done := range m.Completed {
+		if done {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *downloadManifest) save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to encode download manifest: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write download manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write download manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/retry"
+)
+
+func TestSASDuration(t *testing.T) {
+	tests := []struct {
+		sizeGB      int64
+		mbPerSecond int
+		want        time.Duration
+	}{
+		{0, 25, time.Hour + 2*time.Second},
+		{30, 25, time.Hour + 2*(1228*time.Second+time.Second)},
+		{4096, 25, time.Hour + 2*(167772*time.Second+time.Second)},
+		{4096, 100, time.Hour + 2*(41943*time.Second+time.Second)},
+	}
+	for _, tt := range tests {
+		if got := sasDuration(tt.sizeGB<<30, tt.mbPerSecond); got != tt.want {
+			t.Errorf("sasDuration(%d GB, %d MB/s) = %s, want %s", tt.sizeGB, tt.mbPerSecond, got, tt.want)
+		}
+	}
+}
+
+func TestLoadDownloadManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disk.vhd"+manifestSuffix)
+	m := &downloadManifest{Source: `"0x8DC1"`, Snapshot: "snap-1", SnapshotID: "id-1", Size: 3 << 20, BlockSize: 1 << 20, Completed: []bool{true, false, true}}
+	if err := m.save(path); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		source    string
+		snapshot  snapshotRef
+		size      int64
+		blockSize int64
+		want      bool
+	}{
+		{"same blob", `"0x8DC1"`, snapshotRef{}, 3 << 20, 1 << 20, true},
+		{"same snapshot with a new SAS", `"0x8DC9"`, snapshotRef{Name: "snap-1", ID: "id-1"}, 3 << 20, 1 << 20, true},
+		{"recreated snapshot", `"0x8DC9"`, snapshotRef{Name: "snap-1", ID: "id-2"}, 3 << 20, 1 << 20, false},
+		{"other snapshot", `"0x8DC2"`, snapshotRef{}, 3 << 20, 1 << 20, false},
+		{"unknown source", "", snapshotRef{}, 3 << 20, 1 << 20, false},
+		{"other size", `"0x8DC1"`, snapshotRef{}, 4 << 20, 1 << 20, false},
+		{"other block size", `"0x8DC1"`, snapshotRef{}, 3 << 20, 2 << 20, false},
+	}
+	for _, tt := range tests {
+		if got := loadDownloadManifest(path, tt.source, tt.snapshot, tt.size, tt.blockSize); (got != nil) != tt.want {
+			t.Errorf("%s: loadDownloadManifest() = %v, want resumed %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBlobSource(t *testing.T) {
+	etag := azcore.ETag(`"0x8DC1"`)
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if got := blobSource(&etag, &modified); got != `"0x8DC1"` {
+		t.Errorf("blobSource() = %s, want the ETag", got)
+	}
+	if got := blobSource(nil, &modified); got != "2026-01-02T03:04:05Z" {
+		t.Errorf("blobSource() without ETag = %s, want the last modification time", got)
+	}
+	if got := blobSource(nil, nil); got != "" {
+		t.Errorf("blobSource() without ETag and time = %s, want empty", got)
+	}
+}
+
+// fakeCredential authenticates the requests of tests.
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// serverTransport sends the requests of the SDK clients, whatever their host, to server.
+type serverTransport struct{ server *httptest.Server }
+
+func (t serverTransport) Do(req *http.Request) (*http.Response, error) {
+	u, err := url.Parse(t.server.URL)
+	if err != nil {
+		return nil, err
+	}
+	req.URL.Scheme, req.URL.Host = u.Scheme, u.Host
+	return t.server.Client().Do(req)
+}
+
+// fakeSnapshotAPI serves the snapshot operations of ARM and the blob of the snapshots,
+// whose ETag changes with every SAS grant. Ranges from failFrom on fail with 403.
+type fakeSnapshotAPI struct {
+	mu        sync.Mutex
+	disk      []byte
+	snapshots map[string]bool
+	created   []string
+	deleted   []string
+	revoked   int
+	grants    int
+	ranges    []int64
+	failFrom  int64
+}
+
+func (f *fakeSnapshotAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_, resource, arm := strings.Cut(r.URL.Path, "/providers/Microsoft.Compute/")
+	switch {
+	case !arm && r.Method == http.MethodHead:
+		w.Header().Set("Content-Length", fmt.Sprint(len(f.disk)))
+		w.Header().Set("ETag", fmt.Sprintf(`"0x%d"`, f.grants))
+	case !arm:
+		var start, end int64
+		if _, err := fmt.Sscanf(r.Header.Get("x-ms-range"), "bytes=%d-%d", &start, &end); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.ranges = append(f.ranges, start)
+		if f.failFrom > 0 && start >= f.failFrom {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(f.disk)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(f.disk[start : end+1])
+	case strings.HasPrefix(resource, "disks/"):
+		_, _ = fmt.Fprint(w, `{"id": "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/disks/disk1", "location": "westeurope"}`)
+	case strings.HasSuffix(resource, "/beginGetAccess"):
+		f.grants++
+		_, _ = fmt.Fprint(w, `{"accessSAS": "https://md-1.blob.core.windows.net/disk?sig=1"}`)
+	case strings.HasSuffix(resource, "/endGetAccess"):
+		f.revoked++
+	default:
+		name := strings.TrimPrefix(resource, "snapshots/")
+		switch r.Method {
+		case http.MethodPut:
+			f.snapshots[name] = true
+			f.created = append(f.created, name)
+			_, _ = fmt.Fprintf(w, `{"name": %q, "properties": {"provisioningState": "Succeeded"}}`, name)
+		case http.MethodDelete:
+			delete(f.snapshots, name)
+			f.deleted = append(f.deleted, name)
+		default:
+			if !f.snapshots[name] {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = fmt.Fprint(w, `{"error": {"code": "ResourceNotFound", "message": "not found"}}`)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"name": %q, "properties": {"diskSizeBytes": %d, "uniqueId": "id-%s"}}`, name, len(f.disk), name)
+		}
+	}
+}
+
+func TestExportAzureDiskResumesDownload(t *testing.T) {
+	defer retry.Configure(retry.Default())
+	retry.Configure(retry.New(2, time.Millisecond, time.Millisecond, 0))
+
+	api := &fakeSnapshotAPI{disk: make([]byte, 4<<20), snapshots: map[string]bool{}, failFrom: 2 << 20}
+	for i := range api.disk {
+		api.disk[i] = byte(i / 4096)
+	}
+	server := httptest.NewTLSServer(api)
+	defer server.Close()
+	p := &Provider{
+		subscriptionID:      "sub",
+		credential:          fakeCredential{},
+		logger:              logger.New(false),
+		downloadBlockSize:   1 << 20,
+		downloadWorkers:     1,
+		downloadMBPerSecond: defaultDownloadMBPerSecond,
+		transport:           serverTransport{server},
+	}
+	dir := t.TempDir()
+
+	if _, err := p.ExportAzureDisk(context.Background(), "disk1", "rg", dir); err == nil {
+		t.Fatal("ExportAzureDisk() succeeded with failing blocks")
+	}
+	if len(api.created) != 1 || len(api.deleted) != 0 || api.revoked != 0 {
+		t.Fatalf("after the failed export: created %v, deleted %v, revoked %d; want the snapshot and its access kept", api.created, api.deleted, api.revoked)
+	}
+	m, err := readDownloadManifest(filepath.Join(dir, "disk1.vhd"+manifestSuffix))
+	if err != nil || m.Snapshot != api.created[0] || !reflect.DeepEqual(m.Completed, []bool{true, true, false, false}) {
+		t.Fatalf("manifest after the failed export = %+v, %v", m, err)
+	}
+
+	api.failFrom, api.ranges = 0, nil
+	vhdFile, err := p.ExportAzureDisk(context.Background(), "disk1", "rg", dir)
+	if err != nil {
+		t.Fatalf("ExportAzureDisk() error = %v", err)
+	}
+	if len(api.created) != 1 {
+		t.Errorf("created snapshots %v, want the snapshot of the first export reused", api.created)
+	}
+	if want := []int64{2 << 20, 3 << 20}; !reflect.DeepEqual(api.ranges, want) {
+		t.Errorf("downloaded ranges at %v, want only %v", api.ranges, want)
+	}
+	if !reflect.DeepEqual(api.deleted, api.created) || api.revoked != 1 {
+		t.Errorf("deleted %v and revoked %d after the export, want the snapshot cleaned up", api.deleted, api.revoked)
+	}
+	if data, err := os.ReadFile(vhdFile); err != nil || !bytes.Equal(data, api.disk) {
+		t.Errorf("downloaded disk differs from the snapshot: %v", err)
+	}
+	if _, err := os.Stat(vhdFile + manifestSuffix); !os.IsNotExist(err) {
+		t.Errorf("manifest left after the download: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+// ComputeFirmware describes the boot firmware and the security features of a Compute
+// instance.
+type ComputeFirmware struct {
+	HyperVGeneration string // V1 for BIOS, V2 for UEFI; empty when unknown
+	SecurityType     string // TrustedLaunch or ConfidentialVM; empty for standard security
+	SecureBoot       bool
+	VTPM             bool
+}
+
+// UEFI reports whether the instance boots with UEFI firmware, as Generation 2 VMs,
+// Trusted Launch VMs and confidential VMs do.
+func (f *ComputeFirmware) UEFI() bool {
+	return f.HyperVGeneration == "V2" || f.SecurityType != ""
+}
+
+// GetComputeFirmware retrieves the security profile of a Compute instance and the
+// Hyper-V generation of its OS disk.
+func (p *Provider) GetComputeFirmware(ctx context.Context, resourceGroup, computeName string) (*ComputeFirmware, error) {
+	vm, err := p.GetComputeInfo(ctx, resourceGroup, computeName)
+	if err != nil {
+		return nil, err
+	}
+	f := computeFirmware(vm)
+	if osDiskID := computeInventory(vm).OSDiskID; osDiskID != "" {
+		disk, err := p.getManagedDisk(ctx, osDiskID)
+		if err != nil {
+			return nil, err
+		}
+		f.HyperVGeneration = disk.Properties.HyperVGeneration
+	}
+	return f, nil
+}
+
+// computeFirmware extracts the security profile of vm.
+func computeFirmware(vm *armcompute.VirtualMachine) *ComputeFirmware {
+	f := &ComputeFirmware{}
+	if vm.Properties == nil || vm.Properties.SecurityProfile == nil {
+		return f
+	}
+	sp := vm.Properties.SecurityProfile
+	if sp.SecurityType != nil {
+		f.SecurityType = string(*sp.SecurityType)
+	}
+	if uefi := sp.UefiSettings; uefi != nil {
+		f.SecureBoot = uefi.SecureBootEnabled != nil && *uefi.SecureBootEnabled
+		f.VTPM = uefi.VTpmEnabled != nil && *uefi.VTpmEnabled
+	}
+	return f
+}
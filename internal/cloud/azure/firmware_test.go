@@ -0,0 +1,27 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+func TestComputeFirmware(t *testing.T) {
+	vm := &armcompute.VirtualMachine{Properties: &armcompute.VirtualMachineProperties{
+		SecurityProfile: &armcompute.SecurityProfile{
+			SecurityType: to.Ptr(armcompute.SecurityTypesTrustedLaunch),
+			UefiSettings: &armcompute.UefiSettings{SecureBootEnabled: to.Ptr(true), VTpmEnabled: to.Ptr(false)},
+		},
+	}}
+	f := computeFirmware(vm)
+	if f.SecurityType != "TrustedLaunch" || !f.SecureBoot || f.VTPM || !f.UEFI() {
+		t.Errorf("computeFirmware() = %+v", f)
+	}
+	if f := computeFirmware(&armcompute.VirtualMachine{}); f.UEFI() {
+		t.Errorf("Expected BIOS without a security profile or generation, got %+v", f)
+	}
+	if f := (&ComputeFirmware{HyperVGeneration: "V2"}); !f.UEFI() {
+		t.Error("Expected UEFI for a Generation 2 VM")
+	}
+}
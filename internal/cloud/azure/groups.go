@@ -0,0 +1,65 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+// ListComputeGroupMembers lists the names of the Compute instances of a resource group
+// that belong to the availability set or VM scale set groupName, of the resource type
+// groupType: availabilitySets or virtualMachineScaleSets. The instances of scale sets in
+// Flexible orchestration mode are Compute instances of their own; those of scale sets in
+// Uniform orchestration mode are listed by the scale set, as <scale set>_<instance ID>.
+func (p *Provider) ListComputeGroupMembers(ctx context.Context, resourceGroup, groupType, groupName string) ([]string, error) {
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute client factory: %w", err)
+	}
+	if strings.EqualFold(groupType, "virtualMachineScaleSets") {
+		names, uniform, err := listUniformInstances(ctx, clientFactory, resourceGroup, groupName)
+		if err != nil {
+			return nil, err
+		}
+		if uniform {
+			return names, nil
+		}
+	}
+	pager := clientFactory.NewVirtualMachinesClient().NewListPager(resourceGroup, nil)
+	var vms []*armcompute.VirtualMachine
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Compute instances: %w", err)
+		}
+		vms = append(vms, page.Value...)
+	}
+	return groupMembers(vms, groupType, groupName), nil
+}
+
+// groupMembers returns the names of the vms that belong to the group groupName of the
+// resource type groupType, sorted.
+func groupMembers(vms []*armcompute.VirtualMachine, groupType, groupName string) []string {
+	suffix := strings.ToLower("/" + groupType + "/" + groupName)
+	var names []string
+	for _, vm := range vms {
+		if vm == nil || vm.Name == nil || vm.Properties == nil {
+			continue
+		}
+		var group *armcompute.SubResource
+		switch strings.ToLower(groupType) {
+		case "availabilitysets":
+			group = vm.Properties.AvailabilitySet
+		case "virtualmachinescalesets":
+			group = vm.Properties.VirtualMachineScaleSet
+		}
+		if group != nil && group.ID != nil && strings.HasSuffix(strings.ToLower(*group.ID), suffix) {
+			names = append(names, *vm.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,40 @@
+package azure
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+func TestGroupMembers(t *testing.T) {
+	const prefix = "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/"
+	vm := func(name string, availabilitySet, scaleSet string) *armcompute.VirtualMachine {
+		props := &armcompute.VirtualMachineProperties{}
+		if availabilitySet != "" {
+			props.AvailabilitySet = &armcompute.SubResource{ID: to.Ptr(prefix + "availabilitySets/" + availabilitySet)}
+		}
+		if scaleSet != "" {
+			props.VirtualMachineScaleSet = &armcompute.SubResource{ID: to.Ptr(prefix + "virtualMachineScaleSets/" + scaleSet)}
+		}
+		return &armcompute.VirtualMachine{Name: to.Ptr(name), Properties: props}
+	}
+	vms := []*armcompute.VirtualMachine{
+		vm("web-2", "WEB-AVSET", ""),
+		vm("web-1", "web-avset", ""),
+		vm("web-avset-other", "web-avset-other", ""),
+		vm("app_1", "", "app-vmss"),
+		vm("db-1", "", ""),
+		nil,
+	}
+	if got, want := groupMembers(vms, "availabilitySets", "web-avset"), []string{"web-1", "web-2"}; !slices.Equal(got, want) {
+		t.Errorf("groupMembers(availabilitySets) = %v, want %v", got, want)
+	}
+	if got, want := groupMembers(vms, "virtualMachineScaleSets", "app-vmss"), []string{"app_1"}; !slices.Equal(got, want) {
+		t.Errorf("groupMembers(virtualMachineScaleSets) = %v, want %v", got, want)
+	}
+	if got := groupMembers(vms, "virtualMachineScaleSets", "web-avset"); len(got) != 0 {
+		t.Errorf("groupMembers() of another type = %v", got)
+	}
+}
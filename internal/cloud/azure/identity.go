@@ -0,0 +1,245 @@
+// Package azure provides credential selection and managed identity checks.
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+const (
+	imdsInstanceURL = "http://169.254.169.254/metadata/instance/compute?api-version=2021-02-01"
+	imdsTimeout     = 2 * time.Second
+	armScope        = "https://management.azure.com/.default"
+)
+
+// instanceMetadata holds the fields of the Azure Instance Metadata Service compute document used by Kopru.
+type instanceMetadata struct {
+	Name              string `json:"name"`
+	ResourceGroupName string `json:"resourceGroupName"`
+	SubscriptionID    string `json:"subscriptionId"`
+}
+
+// ManagedIdentity describes the managed identity Kopru authenticates with.
+type ManagedIdentity struct {
+	ClientID   string
+	ObjectID   string
+	ResourceID string
+}
+
+// queryInstanceMetadata returns the compute metadata of the Azure VM Kopru runs on,
+// or an error when not running on Azure.
+func queryInstanceMetadata(ctx context.Context) (*instanceMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, imdsTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imdsInstanceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("instance metadata service returned %s", resp.Status)
+	}
+	var md instanceMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&md); err != nil {
+		return nil, fmt.Errorf("failed to decode instance metadata: %w", err)
+	}
+	return &md, nil
+}
+
+// Azure authentication methods selected with AZURE_AUTH.
+const (
+	AuthAuto              = "auto"
+	AuthClientSecret      = "client-secret"
+	AuthClientCertificate = "client-certificate"
+	AuthManagedIdentity   = "managed-identity"
+	AuthDeviceCode        = "device-code"
+	AuthAzureCLI          = "azure-cli"
+)
+
+// Auth selects the credential Kopru authenticates to Azure with.
+type Auth struct {
+	Method                  string // One of the Auth* methods; empty selects AuthAuto
+	TenantID                string
+	ClientID                string
+	ClientSecret            string
+	CertificatePath         string // PEM or PKCS#12 file with the certificate and private key
+	CertificatePassword     string
+	ManagedIdentityClientID string // User-assigned managed identity; empty selects the system-assigned one
+}
+
+// authHints tells users how to fix a credential of each method that cannot get a token.
+var authHints = map[string]string{
+	AuthAuto:              "no credential of the default chain (environment, workload identity, managed identity, Azure CLI, Azure Developer CLI) could authenticate; set AZURE_AUTH to select a method explicitly",
+	AuthClientSecret:      "check AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET, and that the secret has not expired",
+	AuthClientCertificate: "check AZURE_TENANT_ID, AZURE_CLIENT_ID and that the certificate in AZURE_CLIENT_CERTIFICATE_PATH is registered with the application",
+	AuthManagedIdentity:   "check that Kopru runs on an Azure VM with a managed identity, and set AZURE_MANAGED_IDENTITY_CLIENT_ID for a user-assigned identity",
+	AuthDeviceCode:        "complete the sign-in at the URL shown with the code shown before it expires",
+	AuthAzureCLI:          "run 'az login' (with --tenant for AZURE_TENANT_ID) and check that 'az account get-access-token' works",
+}
+
+// newCredential creates the credential of an explicitly selected authentication method.
+func newCredential(auth Auth, log *logger.Logger) (azcore.TokenCredential, error) {
+	switch auth.Method {
+	case AuthClientSecret:
+		if auth.TenantID == "" || auth.ClientID == "" || auth.ClientSecret == "" {
+			return nil, errors.New("AZURE_AUTH=client-secret requires AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET")
+		}
+		return azidentity.NewClientSecretCredential(auth.TenantID, auth.ClientID, auth.ClientSecret, nil)
+	case AuthClientCertificate:
+		if auth.TenantID == "" || auth.ClientID == "" || auth.CertificatePath == "" {
+			return nil, errors.New("AZURE_AUTH=client-certificate requires AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_CERTIFICATE_PATH")
+		}
+		// #nosec G304 -- the certificate path is given by the user
+		data, err := os.ReadFile(auth.CertificatePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client certificate: %w", err)
+		}
+		var password []byte
+		if auth.CertificatePassword != "" {
+			password = []byte(auth.CertificatePassword)
+		}
+		certs, key, err := azidentity.ParseCertificates(data, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client certificate %s: %w", auth.CertificatePath, err)
+		}
+		return azidentity.NewClientCertificateCredential(auth.TenantID, auth.ClientID, certs, key, nil)
+	case AuthManagedIdentity:
+		opts := &azidentity.ManagedIdentityCredentialOptions{}
+		if auth.ManagedIdentityClientID != "" {
+			opts.ID = azidentity.ClientID(auth.ManagedIdentityClientID)
+		}
+		return azidentity.NewManagedIdentityCredential(opts)
+	case AuthDeviceCode:
+		return azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
+			TenantID: auth.TenantID,
+			ClientID: auth.ClientID,
+			UserPrompt: func(_ context.Context, msg azidentity.DeviceCodeMessage) error {
+				log.Info(msg.Message)
+				return nil
+			},
+		})
+	case AuthAzureCLI:
+		return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{TenantID: auth.TenantID})
+	}
+	return nil, fmt.Errorf("unsupported Azure authentication method '%s'", auth.Method)
+}
+
+// environmentCredentialsConfigured reports whether a service principal is configured
+// through the AZURE_* environment variables read by azidentity.
+func environmentCredentialsConfigured() bool {
+	if os.Getenv("AZURE_TENANT_ID") == "" || os.Getenv("AZURE_CLIENT_ID") == "" {
+		return false
+	}
+	return os.Getenv("AZURE_CLIENT_SECRET") != "" || os.Getenv("AZURE_CLIENT_CERTIFICATE_PATH") != "" || os.Getenv("AZURE_FEDERATED_TOKEN_FILE") != ""
+}
+
+// managedIdentityCredential returns a credential for the managed identity of the Azure VM
+// Kopru runs on, and the VM's metadata. It returns a nil credential when Kopru does not
+// run on an Azure VM.
+func managedIdentityCredential(ctx context.Context, clientID string) (azcore.TokenCredential, *instanceMetadata, error) {
+	md, err := queryInstanceMetadata(ctx)
+	if err != nil {
+		return nil, nil, nil
+	}
+	opts := &azidentity.ManagedIdentityCredentialOptions{}
+	if clientID != "" {
+		opts.ID = azidentity.ClientID(clientID)
+	}
+	cred, err := azidentity.NewManagedIdentityCredential(opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create managed identity credential: %w", err)
+	}
+	return cred, md, nil
+}
+
+// AuthMethod returns the authentication method in use.
+func (p *Provider) AuthMethod() string {
+	return p.authMethod
+}
+
+// UsesManagedIdentity reports whether the provider authenticates with the managed identity of the migration VM.
+func (p *Provider) UsesManagedIdentity() bool {
+	return p.managedIdentity
+}
+
+// CheckToken acquires an Azure Resource Manager access token and returns its expiry,
+// so that an expired Azure CLI login or invalid credential fails before the migration
+// starts. Tokens are refreshed automatically by the credential while the run continues.
+func (p *Provider) CheckToken(ctx context.Context) (time.Time, error) {
+	token, err := p.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{armScope}})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get Azure access token with %s authentication (%s): %w", p.authMethod, authHints[p.authMethod], err)
+	}
+	return token.ExpiresOn, nil
+}
+
+// DescribeIdentity returns the client, object and resource IDs of the identity in use,
+// read from the claims of an ARM access token.
+func (p *Provider) DescribeIdentity(ctx context.Context) (*ManagedIdentity, error) {
+	token, err := p.credential.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{armScope}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+	parts := strings.Split(token.Token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("unexpected access token format")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode access token: %w", err)
+	}
+	var claims struct {
+		AppID      string `json:"appid"`
+		ObjectID   string `json:"oid"`
+		ResourceID string `json:"xms_mirid"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to decode access token claims: %w", err)
+	}
+	return &ManagedIdentity{ClientID: claims.AppID, ObjectID: claims.ObjectID, ResourceID: claims.ResourceID}, nil
+}
+
+// armGet sends a GET request to the Azure Resource Manager API and decodes the JSON response into out.
+func (p *Provider) armGet(ctx context.Context, resourcePath, apiVersion string, query map[string]string, out interface{}) error {
+	client, err := arm.NewClient("kopru", "v1", p.credential, p.clientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create ARM client: %w", err)
+	}
+	req, err := runtime.NewRequest(ctx, http.MethodGet, runtime.JoinPaths(client.Endpoint(), resourcePath))
+	if err != nil {
+		return err
+	}
+	q := req.Raw().URL.Query()
+	q.Set("api-version", apiVersion)
+	for k, v := range query {
+		q.Set(k, v)
+	}
+	req.Raw().URL.RawQuery = q.Encode()
+	resp, err := client.Pipeline().Do(req)
+	if err != nil {
+		return err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return runtime.NewResponseError(resp)
+	}
+	return runtime.UnmarshalAsJSON(resp, out)
+}
@@ -0,0 +1,66 @@
+package azure
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestNewCredential(t *testing.T) {
+	dir := t.TempDir()
+	invalidCert := filepath.Join(dir, "cert.pem")
+	if err := os.WriteFile(invalidCert, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	const tenant, client = "00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"
+	tests := []struct {
+		name    string
+		auth    Auth
+		wantErr string
+	}{
+		{"client secret", Auth{Method: AuthClientSecret, TenantID: tenant, ClientID: client, ClientSecret: "secret"}, ""},
+		{"client secret without tenant", Auth{Method: AuthClientSecret, ClientID: client, ClientSecret: "secret"}, "requires AZURE_TENANT_ID"},
+		{"client certificate without path", Auth{Method: AuthClientCertificate, TenantID: tenant, ClientID: client}, "AZURE_CLIENT_CERTIFICATE_PATH"},
+		{"missing client certificate", Auth{Method: AuthClientCertificate, TenantID: tenant, ClientID: client, CertificatePath: filepath.Join(dir, "missing.pem")}, "failed to read client certificate"},
+		{"invalid client certificate", Auth{Method: AuthClientCertificate, TenantID: tenant, ClientID: client, CertificatePath: invalidCert}, "failed to parse client certificate"},
+		{"managed identity", Auth{Method: AuthManagedIdentity, ManagedIdentityClientID: client}, ""},
+		{"device code", Auth{Method: AuthDeviceCode, TenantID: tenant}, ""},
+		{"azure cli", Auth{Method: AuthAzureCLI}, ""},
+		{"unknown", Auth{Method: "password"}, "unsupported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cred, err := newCredential(tt.auth, logger.New(false))
+			if tt.wantErr == "" {
+				if err != nil || cred == nil {
+					t.Errorf("newCredential() = %v, %v", cred, err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("newCredential() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthHints(t *testing.T) {
+	for _, method := range []string{AuthAuto, AuthClientSecret, AuthClientCertificate, AuthManagedIdentity, AuthDeviceCode, AuthAzureCLI} {
+		if authHints[method] == "" {
+			t.Errorf("no hint for Azure authentication method %s", method)
+		}
+	}
+}
+
+func TestKeyVaultEndpoint(t *testing.T) {
+	tests := []struct{ vault, endpoint, scope string }{
+		{"kv-migration", "https://kv-migration.vault.azure.net", "https://vault.azure.net/.default"},
+		{"kv-gov.vault.usgovcloudapi.net", "https://kv-gov.vault.usgovcloudapi.net", "https://vault.usgovcloudapi.net/.default"},
+	}
+	for _, tt := range tests {
+		if endpoint, scope := keyVaultEndpoint(tt.vault); endpoint != tt.endpoint || scope != tt.scope {
+			t.Errorf("keyVaultEndpoint(%s) = %s, %s, want %s, %s", tt.vault, endpoint, scope, tt.endpoint, tt.scope)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// keyVaultAPIVersion is the Key Vault data plane API version used to read secrets.
+const keyVaultAPIVersion = "7.4"
+
+// keyVaultEndpoint returns the URL of a vault and the token scope of its cloud. vault is
+// a vault name in the public cloud, or the host name of a vault in another cloud, e.g.
+// myvault.vault.usgovcloudapi.net.
+func keyVaultEndpoint(vault string) (endpoint, scope string) {
+	host := vault
+	if !strings.Contains(host, ".") {
+		host += ".vault.azure.net"
+	}
+	_, suffix, _ := strings.Cut(host, ".")
+	return "https://" + host, "https://" + suffix + "/.default"
+}
+
+// GetKeyVaultSecret returns the value of secret name in Azure Key Vault vault. name may
+// end in /<version>; the current version is returned otherwise. The identity needs the
+// Key Vault Secrets User role or a get secret access policy.
+func (p *Provider) GetKeyVaultSecret(ctx context.Context, vault, name string) (string, error) {
+	endpoint, scope := keyVaultEndpoint(vault)
+	options := p.clientOptions().ClientOptions
+	pipeline := runtime.NewPipeline("kopru", "v1", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(p.credential, []string{scope}, nil)},
+	}, &options)
+	req, err := runtime.NewRequest(ctx, http.MethodGet, runtime.JoinPaths(endpoint, "secrets", name))
+	if err != nil {
+		return "", err
+	}
+	q := req.Raw().URL.Query()
+	q.Set("api-version", keyVaultAPIVersion)
+	req.Raw().URL.RawQuery = q.Encode()
+	resp, err := pipeline.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s from %s: %w", name, vault, err)
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return "", fmt.Errorf("failed to get secret %s from %s: %w", name, vault, runtime.NewResponseError(resp))
+	}
+	var secret struct {
+		Value *string `json:"value"`
+	}
+	if err := runtime.UnmarshalAsJSON(resp, &secret); err != nil {
+		return "", err
+	}
+	if secret.Value == nil {
+		return "", fmt.Errorf("secret %s in %s has no value", name, vault)
+	}
+	return *secret.Value, nil
+}
@@ -0,0 +1,49 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+// ComputeLicensing describes how the OS of a Compute instance is licensed in Azure.
+type ComputeLicensing struct {
+	LicenseType    string // Azure Hybrid Benefit, e.g. Windows_Server, RHEL_BYOS or SLES_BYOS
+	ImagePublisher string // Publisher of the marketplace image the VM was created from
+	ImageOffer     string
+	ImageSKU       string
+}
+
+// GetComputeLicensing retrieves the Azure Hybrid Benefit license type and the marketplace
+// image of a Compute instance.
+func (p *Provider) GetComputeLicensing(ctx context.Context, resourceGroup, computeName string) (*ComputeLicensing, error) {
+	vm, err := p.GetComputeInfo(ctx, resourceGroup, computeName)
+	if err != nil {
+		return nil, err
+	}
+	return computeLicensing(vm), nil
+}
+
+// computeLicensing extracts the licensing of vm.
+func computeLicensing(vm *armcompute.VirtualMachine) *ComputeLicensing {
+	l := &ComputeLicensing{}
+	if vm.Properties == nil {
+		return l
+	}
+	if vm.Properties.LicenseType != nil {
+		l.LicenseType = *vm.Properties.LicenseType
+	}
+	if sp := vm.Properties.StorageProfile; sp != nil && sp.ImageReference != nil {
+		ref := sp.ImageReference
+		if ref.Publisher != nil {
+			l.ImagePublisher = *ref.Publisher
+		}
+		if ref.Offer != nil {
+			l.ImageOffer = *ref.Offer
+		}
+		if ref.SKU != nil {
+			l.ImageSKU = *ref.SKU
+		}
+	}
+	return l
+}
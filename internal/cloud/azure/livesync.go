@@ -0,0 +1,299 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/pageblob"
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
+)
+
+// liveSyncBase is the incremental snapshot a disk was first exported from while its VM
+// was running, and the teardown action that deletes it.
+type liveSyncBase struct {
+	name   string
+	delete func()
+}
+
+// byteRange is a range of count bytes at offset of a disk.
+type byteRange struct {
+	offset int64
+	count  int64
+}
+
+// ExportAzureDiskBase exports a disk of a running VM from an incremental snapshot, as
+// the first pass of a live sync. The snapshot is kept until SyncAzureDiskDelta applies
+// the changes made since then to the exported VHD.
+func (p *Provider) ExportAzureDiskBase(ctx context.Context, diskName, resourceGroup, exportDir string) (string, error) {
+	vhdFile := filepath.Join(exportDir, fmt.Sprintf("%s.vhd", diskName))
+	base := liveSyncBase{name: renderSnapshotName(p.snapshotOptions.NameTemplate, p.snapshotOptions.MigrationID, diskName, time.Now())}
+	p.logger.Infof("Creating incremental snapshot: %s", base.name)
+	base.delete = p.registerTeardown("delete snapshot "+base.name, p.snapshotDeleter(resourceGroup, base.name))
+	if err := p.createIncrementalSnapshot(ctx, resourceGroup, base.name, diskName); err != nil {
+		base.delete()
+		return "", err
+	}
+	p.logger.Success("✓ Incremental snapshot created")
+	p.mu.Lock()
+	if p.liveSyncBases == nil {
+		p.liveSyncBases = make(map[string]liveSyncBase)
+	}
+	p.liveSyncBases[diskName] = base
+	p.mu.Unlock()
+
+	sasURL, revoke, err := p.grantExportAccess(ctx, resourceGroup, base.name)
+	if err != nil {
+		return "", err
+	}
+	defer revoke()
+	p.logger.Info("Downloading disk while the VM is running (this may take a while)...")
+	if err := p.DownloadFromSASURL(ctx, sasURL, vhdFile); err != nil {
+		return "", fmt.Errorf("failed to download disk: %w", err)
+	}
+	p.logger.Successf("✓ Disk downloaded: %s", vhdFile)
+	return vhdFile, nil
+}
+
+// SyncAzureDiskDelta takes a second incremental snapshot of a disk exported by
+// ExportAzureDiskBase, once its VM is stopped, and applies the pages that changed
+// between the two snapshots to the exported VHD. It returns the number of bytes
+// written, and deletes both snapshots.
+func (p *Provider) SyncAzureDiskDelta(ctx context.Context, diskName, resourceGroup, exportDir string) (written int64, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "azure.SyncAzureDiskDelta")
+	defer func() { telemetry.EndSpan(span, err) }()
+
+	p.mu.Lock()
+	base, ok := p.liveSyncBases[diskName]
+	delete(p.liveSyncBases, diskName)
+	p.mu.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("disk %s has no base snapshot to sync from", diskName)
+	}
+	defer base.delete()
+	vhdFile := filepath.Join(exportDir, fmt.Sprintf("%s.vhd", diskName))
+
+	deltaName := renderSnapshotName(p.snapshotOptions.NameTemplate, p.snapshotOptions.MigrationID, diskName, time.Now())
+	if deltaName == base.name {
+		deltaName = deltaName[:min(len(deltaName), maxSnapshotNameLen-len("-delta"))] + "-delta"
+	}
+	p.logger.Infof("Creating incremental snapshot: %s", deltaName)
+	deleteDelta := p.registerTeardown("delete snapshot "+deltaName, p.snapshotDeleter(resourceGroup, deltaName))
+	defer deleteDelta()
+	if err := p.createIncrementalSnapshot(ctx, resourceGroup, deltaName, diskName); err != nil {
+		return 0, err
+	}
+	p.logger.Success("✓ Incremental snapshot created")
+
+	baseURL, revokeBase, err := p.grantExportAccess(ctx, resourceGroup, base.name)
+	if err != nil {
+		return 0, err
+	}
+	defer revokeBase()
+	deltaURL, revokeDelta, err := p.grantExportAccess(ctx, resourceGroup, deltaName)
+	if err != nil {
+		return 0, err
+	}
+	defer revokeDelta()
+
+	changed, cleared, err := changedPageRanges(ctx, deltaURL, baseURL)
+	if err != nil {
+		return 0, err
+	}
+	changed, cleared = mergeByteRanges(changed, p.downloadBlockSize), mergeByteRanges(cleared, p.downloadBlockSize)
+	p.logger.Infof("%d MB changed and %d MB cleared since the first pass", rangesLength(changed)>>20, rangesLength(cleared)>>20)
+	if err := p.applyDelta(ctx, deltaURL, vhdFile, changed, cleared); err != nil {
+		return 0, err
+	}
+	p.logger.Successf("✓ Disk synced: %s", vhdFile)
+	return rangesLength(changed) + rangesLength(cleared), nil
+}
+
+// createIncrementalSnapshot creates an incremental snapshot of a disk, which records
+// the pages that differ from the previous incremental snapshot of the same disk.
+func (p *Provider) createIncrementalSnapshot(ctx context.Context, resourceGroup, snapshotName, diskName string) error {
+	poller, err := p.beginSnapshot(ctx, resourceGroup, snapshotName, diskName, true)
+	if err != nil {
+		return fmt.Errorf("failed to create incremental snapshot: %w", err)
+	}
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("failed to create incremental snapshot: %w", err)
+	}
+	return nil
+}
+
+// grantExportAccess grants read access to a snapshot for long enough to download it, and
+// returns its SAS URL and the function that revokes the access.
+func (p *Provider) grantExportAccess(ctx context.Context, resourceGroup, snapshotName string) (string, func(), error) {
+	sizeBytes, err := p.snapshotSizeBytes(ctx, resourceGroup, snapshotName)
+	if err != nil {
+		return "", nil, err
+	}
+	duration := sasDuration(sizeBytes, p.downloadMBPerSecond)
+	if duration.Seconds() > math.MaxInt32 {
+		return "", nil, fmt.Errorf("snapshot %s of %d GB cannot be downloaded at %d MB/s within the longest SAS validity", snapshotName, sizeBytes>>30, p.downloadMBPerSecond)
+	}
+	p.logger.Infof("Generating SAS URL for snapshot: %s (valid for %s)", snapshotName, duration)
+	revoke := p.registerTeardown("revoke access to snapshot "+snapshotName, func(ctx context.Context) error {
+		return p.RevokeSnapshotAccess(ctx, resourceGroup, snapshotName)
+	})
+	sasURL, err := p.GrantSnapshotAccess(ctx, resourceGroup, snapshotName, int32(duration.Seconds()))
+	if err != nil {
+		revoke()
+		return "", nil, fmt.Errorf("failed to generate SAS URL: %w", err)
+	}
+	p.logger.Success("✓ SAS URL generated")
+	return sasURL, revoke, nil
+}
+
+// changedPageRanges lists the pages of the snapshot at sasURL that were written or
+// cleared since the earlier incremental snapshot of the same disk at prevSASURL.
+func changedPageRanges(ctx context.Context, sasURL, prevSASURL string) (changed, cleared []byteRange, err error) {
+	client, err := pageblob.NewClientWithNoCredential(sasURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create page blob client: %w", err)
+	}
+	pager := client.NewGetPageRangesDiffPager(&pageblob.GetPageRangesDiffOptions{PrevSnapshotURL: &prevSASURL})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list changed pages: %w", err)
+		}
+		for _, r := range page.PageRange {
+			if r != nil && r.Start != nil && r.End != nil {
+				changed = append(changed, byteRange{offset: *r.Start, count: *r.End - *r.Start + 1})
+			}
+		}
+		for _, r := range page.ClearRange {
+			if r != nil && r.Start != nil && r.End != nil {
+				cleared = append(cleared, byteRange{offset: *r.Start, count: *r.End - *r.Start + 1})
+			}
+		}
+	}
+	return changed, cleared, nil
+}
+
+// mergeByteRanges sorts ranges and joins the adjacent and overlapping ones into ranges
+// of at most maxCount bytes, so that each is downloaded with one ranged GET.
+func mergeByteRanges(ranges []byteRange, maxCount int64) []byteRange {
+	if maxCount <= 0 {
+		maxCount = defaultDownloadBlockSizeMB * 1024 * 1024
+	}
+	sorted := append([]byteRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].offset < sorted[j].offset })
+	var joined []byteRange
+	for _, r := range sorted {
+		if r.count <= 0 {
+			continue
+		}
+		if n := len(joined); n > 0 && r.offset <= joined[n-1].offset+joined[n-1].count {
+			end := max(joined[n-1].offset+joined[n-1].count, r.offset+r.count)
+			joined[n-1].count = end - joined[n-1].offset
+			continue
+		}
+		joined = append(joined, r)
+	}
+	var merged []byteRange
+	for _, r := range joined {
+		for r.count > maxCount {
+			merged = append(merged, byteRange{offset: r.offset, count: maxCount})
+			r.offset, r.count = r.offset+maxCount, r.count-maxCount
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// rangesLength returns the total number of bytes of ranges.
+func rangesLength(ranges []byteRange) int64 {
+	var n int64
+	for _, r := range ranges {
+		n += r.count
+	}
+	return n
+}
+
+// applyDelta downloads the changed ranges of the snapshot at sasURL into vhdFile at the
+// same offsets, with the configured number of workers, and zeroes the cleared ranges.
+func (p *Provider) applyDelta(ctx context.Context, sasURL, vhdFile string, changed, cleared []byteRange) error {
+	blobClient, err := blob.NewClientWithNoCredential(sasURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create blob client: %w", err)
+	}
+	// #nosec G304 -- vhdFile is controlled by the application
+	out, err := os.OpenFile(vhdFile, os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open exported disk: %w", err)
+	}
+	defer out.Close()
+	for _, r := range cleared {
+		if err := zeroRange(out, r); err != nil {
+			return fmt.Errorf("failed to clear %d bytes at offset %d: %w", r.count, r.offset, err)
+		}
+	}
+
+	workers := max(p.downloadWorkers, 1)
+	rep := progress.New(p.logger, "Syncing "+filepath.Base(vhdFile), rangesLength(changed)).Record(progress.PhaseDownload, filepath.Base(vhdFile))
+	defer rep.Done()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	jobs := make(chan byteRange)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				if err := downloadRangeWithRetry(ctx, blobClient, out, r.offset, r.count); err != nil {
+					errs[w] = fmt.Errorf("range at offset %d: %w", r.offset, err)
+					cancel()
+					return
+				}
+				rep.Add(r.count)
+				telemetry.AddDownloadedBytes(ctx, r.count)
+			}
+		}()
+	}
+dispatch:
+	for _, r := range changed {
+		select {
+		case jobs <- r:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to download changed pages: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("sync interrupted: %w", err)
+	}
+	if err := out.Sync(); err != nil {
+		return fmt.Errorf("failed to flush synced disk: %w", err)
+	}
+	return nil
+}
+
+// zeroRange writes zeros over r of out.
+func zeroRange(out *os.File, r byteRange) error {
+	zeros := make([]byte, min(r.count, 1<<20))
+	for offset, end := r.offset, r.offset+r.count; offset < end; {
+		n := min(int64(len(zeros)), end-offset)
+		if _, err := out.WriteAt(zeros[:n], offset); err != nil {
+			return err
+		}
+		offset += n
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package azure
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMergeByteRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		ranges   []byteRange
+		maxCount int64
+		want     []byteRange
+	}{
+		{"empty", nil, 1024, nil},
+		{"adjacent", []byteRange{{0, 512}, {512, 512}}, 4096, []byteRange{{0, 1024}}},
+		{"unsorted and overlapping", []byteRange{{2048, 512}, {0, 1024}, {512, 1024}}, 4096, []byteRange{{0, 1536}, {2048, 512}}},
+		{"split at max count", []byteRange{{0, 2560}}, 1024, []byteRange{{0, 1024}, {1024, 1024}, {2048, 512}}},
+		{"empty range dropped", []byteRange{{0, 0}, {512, 512}}, 1024, []byteRange{{512, 512}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeByteRanges(tt.ranges, tt.maxCount); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeByteRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRangesLength(t *testing.T) {
+	if got := rangesLength([]byteRange{{0, 512}, {4096, 1024}}); got != 1536 {
+		t.Errorf("rangesLength() = %d, want 1536", got)
+	}
+}
+
+func TestZeroRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disk.vhd")
+	if err := os.WriteFile(path, bytes.Repeat([]byte{0xff}, 4096), 0600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := zeroRange(f, byteRange{offset: 1024, count: 2048}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(append(bytes.Repeat([]byte{0xff}, 1024), make([]byte, 2048)...), bytes.Repeat([]byte{0xff}, 1024)...)
+	if !bytes.Equal(data, want) {
+		t.Error("zeroRange() did not zero exactly the range")
+	}
+}
@@ -0,0 +1,118 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+const networkInterfacesAPI = "2023-11-01"
+
+// NetworkInterface is a network interface of a Compute instance.
+type NetworkInterface struct {
+	Name             string
+	ID               string
+	Primary          bool
+	IPConfigurations []IPConfiguration // The primary IP configuration first
+}
+
+// IPConfiguration is an IP configuration of a network interface.
+type IPConfiguration struct {
+	Name      string
+	PrivateIP string
+	SubnetID  string // Resource ID of the subnet, .../virtualNetworks/<vnet>/subnets/<subnet>
+	Primary   bool
+}
+
+// networkInterface is the network interface resource of the Azure Network API.
+type networkInterface struct {
+	Name       string `json:"name"`
+	ID         string `json:"id"`
+	Properties struct {
+		IPConfigurations []struct {
+			Name       string `json:"name"`
+			Properties struct {
+				PrivateIPAddress string `json:"privateIPAddress"`
+				Primary          bool   `json:"primary"`
+				Subnet           *struct {
+					ID string `json:"id"`
+				} `json:"subnet"`
+			} `json:"properties"`
+		} `json:"ipConfigurations"`
+	} `json:"properties"`
+}
+
+// GetComputeNetworkInterfaces retrieves the network interfaces of a Compute instance
+// and their IP configurations, the primary interface first.
+func (p *Provider) GetComputeNetworkInterfaces(ctx context.Context, resourceGroup, computeName string) ([]NetworkInterface, error) {
+	vm, err := p.GetComputeInfo(ctx, resourceGroup, computeName)
+	if err != nil {
+		return nil, err
+	}
+	refs := networkInterfaceRefs(vm)
+	nics := make([]NetworkInterface, 0, len(refs))
+	for _, ref := range refs {
+		var nic networkInterface
+		if err := p.armGet(ctx, ref.id, networkInterfacesAPI, nil, &nic); err != nil {
+			return nil, fmt.Errorf("failed to get network interface %s: %w", ref.id, err)
+		}
+		nics = append(nics, convertNetworkInterface(nic, ref.primary))
+	}
+	return primaryFirst(nics, func(n NetworkInterface) bool { return n.Primary }), nil
+}
+
+// networkInterfaceRef is a network interface referenced by the network profile of a VM.
+type networkInterfaceRef struct {
+	id      string
+	primary bool
+}
+
+// networkInterfaceRefs returns the network interfaces of vm. A VM with a single network
+// interface need not mark it as primary.
+func networkInterfaceRefs(vm *armcompute.VirtualMachine) []networkInterfaceRef {
+	if vm.Properties == nil || vm.Properties.NetworkProfile == nil {
+		return nil
+	}
+	var refs []networkInterfaceRef
+	for _, ref := range vm.Properties.NetworkProfile.NetworkInterfaces {
+		if ref.ID == nil {
+			continue
+		}
+		primary := ref.Properties != nil && ref.Properties.Primary != nil && *ref.Properties.Primary
+		refs = append(refs, networkInterfaceRef{id: *ref.ID, primary: primary})
+	}
+	if len(refs) == 1 {
+		refs[0].primary = true
+	}
+	return refs
+}
+
+// convertNetworkInterface converts a network interface of the Network API. A network
+// interface with a single IP configuration need not mark it as primary.
+func convertNetworkInterface(nic networkInterface, primary bool) NetworkInterface {
+	n := NetworkInterface{Name: nic.Name, ID: nic.ID, Primary: primary}
+	for _, c := range nic.Properties.IPConfigurations {
+		ipc := IPConfiguration{Name: c.Name, PrivateIP: c.Properties.PrivateIPAddress, Primary: c.Properties.Primary}
+		if c.Properties.Subnet != nil {
+			ipc.SubnetID = c.Properties.Subnet.ID
+		}
+		n.IPConfigurations = append(n.IPConfigurations, ipc)
+	}
+	if len(n.IPConfigurations) == 1 {
+		n.IPConfigurations[0].Primary = true
+	}
+	n.IPConfigurations = primaryFirst(n.IPConfigurations, func(c IPConfiguration) bool { return c.Primary })
+	return n
+}
+
+// primaryFirst moves the first item for which primary returns true to the front of
+// items, keeping the order of the others.
+func primaryFirst[T any](items []T, primary func(T) bool) []T {
+	for i, item := range items {
+		if primary(item) {
+			return append([]T{item}, append(items[:i:i], items[i+1:]...)...)
+		}
+	}
+	return items
+}
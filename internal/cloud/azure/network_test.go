@@ -0,0 +1,33 @@
+package azure
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConvertNetworkInterface(t *testing.T) {
+	const response = `{
+		"name": "app-nic",
+		"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/app-nic",
+		"properties": {"ipConfigurations": [
+			{"name": "secondary", "properties": {"privateIPAddress": "10.0.1.5", "primary": false,
+				"subnet": {"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/app"}}},
+			{"name": "ipconfig1", "properties": {"privateIPAddress": "10.0.1.4", "primary": true,
+				"subnet": {"id": "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/app"}}}
+		]}
+	}`
+	var nic networkInterface
+	if err := json.Unmarshal([]byte(response), &nic); err != nil {
+		t.Fatal(err)
+	}
+	n := convertNetworkInterface(nic, true)
+	if n.Name != "app-nic" || !n.Primary || len(n.IPConfigurations) != 2 {
+		t.Fatalf("convertNetworkInterface() = %+v", n)
+	}
+	if first := n.IPConfigurations[0]; first.Name != "ipconfig1" || first.PrivateIP != "10.0.1.4" || !first.Primary {
+		t.Errorf("Expected the primary IP configuration first, got %+v", first)
+	}
+	if n.IPConfigurations[1].SubnetID == "" {
+		t.Error("Expected the subnet of the secondary IP configuration")
+	}
+}
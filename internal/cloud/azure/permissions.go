@@ -0,0 +1,115 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const authorizationAPI = "2022-04-01"
+
+// requiredPermission is an Azure RBAC action Kopru performs on the resource group,
+// together with the built-in role that grants it.
+type requiredPermission struct {
+	action string
+	role   string
+}
+
+// requiredPermissions lists the actions used to read the VM and export its disks.
+var requiredPermissions = []requiredPermission{
+	{"Microsoft.Compute/virtualMachines/read", "Reader"},
+	{"Microsoft.Compute/virtualMachines/instanceView/read", "Reader"},
+	{"Microsoft.Compute/disks/read", "Reader"},
+	{"Microsoft.Compute/snapshots/read", "Disk Snapshot Contributor"},
+	{"Microsoft.Compute/snapshots/write", "Disk Snapshot Contributor"},
+	{"Microsoft.Compute/snapshots/beginGetAccess/action", "Disk Snapshot Contributor"},
+	{"Microsoft.Compute/snapshots/endGetAccess/action", "Disk Snapshot Contributor"},
+	{"Microsoft.Compute/snapshots/delete", "Disk Snapshot Contributor"},
+}
+
+// runCommandPermission is needed to quiesce a running VM before its disks are snapshotted.
+var runCommandPermission = requiredPermission{"Microsoft.Compute/virtualMachines/runCommand/action", "Virtual Machine Contributor"}
+
+// Permissions needed to deallocate the VM before its disks are exported and to start it again.
+var (
+	deallocatePermission = requiredPermission{"Microsoft.Compute/virtualMachines/deallocate/action", "Virtual Machine Contributor"}
+	startPermission      = requiredPermission{"Microsoft.Compute/virtualMachines/start/action", "Virtual Machine Contributor"}
+)
+
+// permission is an entry of the Azure Authorization permissions API response.
+type permission struct {
+	Actions    []string `json:"actions"`
+	NotActions []string `json:"notActions"`
+}
+
+// CheckPermissions verifies that the current principal may perform all actions
+// needed for the migration on the resource group, as evaluated by the Azure
+// Authorization API across all of its role assignments (including custom roles and
+// assignments inherited from the subscription). The error names the roles to assign
+// and the missing actions.
+func (p *Provider) CheckPermissions(ctx context.Context, resourceGroup string) error {
+	scope := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", p.subscriptionID, resourceGroup)
+	var result struct {
+		Value []permission `json:"value"`
+	}
+	if err := p.armGet(ctx, scope+"/providers/Microsoft.Authorization/permissions", authorizationAPI, nil, &result); err != nil {
+		return fmt.Errorf("failed to list permissions: %w", err)
+	}
+	required := requiredPermissions
+	if p.snapshotOptions.Quiesce != nil {
+		required = append(slices.Clip(required), runCommandPermission)
+	}
+	required = append(slices.Clip(required), p.powerPermissions...)
+	missing := missingPermissions(result.Value, required)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var roles []string
+	actionsByRole := make(map[string][]string)
+	for _, m := range missing {
+		if _, ok := actionsByRole[m.role]; !ok {
+			roles = append(roles, m.role)
+		}
+		actionsByRole[m.role] = append(actionsByRole[m.role], m.action)
+	}
+	details := make([]string, len(roles))
+	for i, role := range roles {
+		details[i] = fmt.Sprintf("%s (missing %s)", role, strings.Join(actionsByRole[role], ", "))
+	}
+	return fmt.Errorf("missing permissions on %s, assign the role(s): %s", scope, strings.Join(details, "; "))
+}
+
+// missingPermissions returns the required permissions not granted by perms. An action
+// is granted when an entry allows it through its actions and does not exclude it
+// through its notActions.
+func missingPermissions(perms []permission, required []requiredPermission) []requiredPermission {
+	var missing []requiredPermission
+	for _, req := range required {
+		granted := false
+		for _, perm := range perms {
+			if matchesAny(perm.Actions, req.action) && !matchesAny(perm.NotActions, req.action) {
+				granted = true
+				break
+			}
+		}
+		if !granted {
+			missing = append(missing, req)
+		}
+	}
+	return missing
+}
+
+// matchesAny reports whether action matches one of the RBAC action patterns,
+// which are case-insensitive and may contain * wildcards.
+func matchesAny(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		expr := "(?i)^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		if matched, err := regexp.MatchString(expr, action); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
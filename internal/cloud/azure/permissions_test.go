@@ -0,0 +1,61 @@
+package azure
+
+import "testing"
+
+func TestMissingPermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		perms   []permission
+		missing []string
+	}{
+		{
+			name:  "owner",
+			perms: []permission{{Actions: []string{"*"}}},
+		},
+		{
+			name: "contributor excludes authorization writes only",
+			perms: []permission{{
+				Actions:    []string{"*"},
+				NotActions: []string{"Microsoft.Authorization/*/Delete", "Microsoft.Authorization/*/Write"},
+			}},
+		},
+		{
+			name: "reader and disk snapshot contributor",
+			perms: []permission{
+				{Actions: []string{"*/read"}},
+				{Actions: []string{"Microsoft.Compute/snapshots/*", "Microsoft.Storage/storageAccounts/read"}},
+			},
+		},
+		{
+			name:  "reader only",
+			perms: []permission{{Actions: []string{"*/read"}}},
+			missing: []string{
+				"Microsoft.Compute/snapshots/write",
+				"Microsoft.Compute/snapshots/beginGetAccess/action",
+				"Microsoft.Compute/snapshots/endGetAccess/action",
+				"Microsoft.Compute/snapshots/delete",
+			},
+		},
+		{
+			name: "snapshot delete denied",
+			perms: []permission{
+				{Actions: []string{"*/read"}},
+				{Actions: []string{"microsoft.compute/snapshots/*"}, NotActions: []string{"Microsoft.Compute/snapshots/delete"}},
+			},
+			missing: []string{"Microsoft.Compute/snapshots/delete"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := missingPermissions(tt.perms, requiredPermissions)
+			if len(got) != len(tt.missing) {
+				t.Fatalf("missingPermissions() = %v, want %v", got, tt.missing)
+			}
+			for i, m := range got {
+				if m.action != tt.missing[i] {
+					t.Errorf("missingPermissions()[%d] = %s, want %s", i, m.action, tt.missing[i])
+				}
+			}
+		})
+	}
+}
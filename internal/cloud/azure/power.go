@@ -0,0 +1,159 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+// powerPollInterval is how often the power state of a VM is read while waiting for it
+// to change.
+const powerPollInterval = 15 * time.Second
+
+// Power states of a Compute instance, as reported by its instance view.
+const (
+	PowerStateRunning     = "running"
+	PowerStateStopped     = "stopped"
+	PowerStateDeallocated = "deallocated"
+)
+
+// ConfigurePowerControl makes CheckPermissions also require the permission to deallocate
+// the VM, and with restart the permission to start it again.
+func (p *Provider) ConfigurePowerControl(restart bool) {
+	p.powerPermissions = []requiredPermission{deallocatePermission}
+	if restart {
+		p.powerPermissions = append(p.powerPermissions, startPermission)
+	}
+}
+
+// GetComputePowerState returns the power state of a Compute instance, such as running,
+// stopping, stopped, deallocating or deallocated.
+func (p *Provider) GetComputePowerState(ctx context.Context, resourceGroup, computeName string) (string, error) {
+	statuses, err := p.instanceStatuses(ctx, resourceGroup, computeName)
+	if err != nil {
+		return "", err
+	}
+	state, ok := powerState(statuses)
+	if !ok {
+		return "", fmt.Errorf("compute instance view has no power state")
+	}
+	return state, nil
+}
+
+// powerState returns the power state of the PowerState/<state> status code of a VM.
+func powerState(statuses []*armcompute.InstanceViewStatus) (string, bool) {
+	for _, status := range statuses {
+		if status != nil && status.Code != nil {
+			if state, ok := strings.CutPrefix(*status.Code, "PowerState/"); ok {
+				return state, true
+			}
+		}
+	}
+	return "", false
+}
+
+// DeallocateCompute deallocates a Compute instance, or an instance of a Uniform scale
+// set, which stops it and releases its compute resources, and waits for the operation to
+// complete.
+func (p *Provider) DeallocateCompute(ctx context.Context, resourceGroup, computeName string) error {
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create compute client factory: %w", err)
+	}
+	scaleSet, instanceID, uniform, err := scaleSetInstance(ctx, clientFactory, resourceGroup, computeName)
+	if err != nil {
+		return err
+	}
+	if uniform {
+		poller, err := clientFactory.NewVirtualMachineScaleSetVMsClient().BeginDeallocate(ctx, resourceGroup, scaleSet, instanceID, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin Compute instance deallocation: %w", err)
+		}
+		if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+			return fmt.Errorf("failed to deallocate Compute instance: %w", err)
+		}
+		return nil
+	}
+	poller, err := clientFactory.NewVirtualMachinesClient().BeginDeallocate(ctx, resourceGroup, computeName, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin Compute instance deallocation: %w", err)
+	}
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("failed to deallocate Compute instance: %w", err)
+	}
+	return nil
+}
+
+// StartCompute starts a Compute instance, or an instance of a Uniform scale set, and
+// waits for the operation to complete.
+func (p *Provider) StartCompute(ctx context.Context, resourceGroup, computeName string) error {
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create compute client factory: %w", err)
+	}
+	scaleSet, instanceID, uniform, err := scaleSetInstance(ctx, clientFactory, resourceGroup, computeName)
+	if err != nil {
+		return err
+	}
+	if uniform {
+		poller, err := clientFactory.NewVirtualMachineScaleSetVMsClient().BeginStart(ctx, resourceGroup, scaleSet, instanceID, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin Compute instance start: %w", err)
+		}
+		if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+			return fmt.Errorf("failed to start Compute instance: %w", err)
+		}
+		return nil
+	}
+	poller, err := clientFactory.NewVirtualMachinesClient().BeginStart(ctx, resourceGroup, computeName, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin Compute instance start: %w", err)
+	}
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("failed to start Compute instance: %w", err)
+	}
+	return nil
+}
+
+// WaitForComputeStopped waits until the Compute instance is stopped or deallocated, for
+// at most timeout, logging every minute that it is still running.
+func (p *Provider) WaitForComputeStopped(ctx context.Context, resourceGroup, computeName string, timeout time.Duration) error {
+	return p.waitForPowerState(ctx, resourceGroup, computeName, timeout, PowerStateStopped, PowerStateDeallocated)
+}
+
+// WaitForComputeRunning waits until the Compute instance is running, for at most timeout.
+func (p *Provider) WaitForComputeRunning(ctx context.Context, resourceGroup, computeName string, timeout time.Duration) error {
+	return p.waitForPowerState(ctx, resourceGroup, computeName, timeout, PowerStateRunning)
+}
+
+// waitForPowerState waits until the power state of the Compute instance is one of
+// states, for at most timeout, logging every minute the state it is still in.
+func (p *Provider) waitForPowerState(ctx context.Context, resourceGroup, computeName string, timeout time.Duration, states ...string) error {
+	deadline := time.Now().Add(timeout)
+	lastLog := time.Now()
+	for {
+		state, err := p.GetComputePowerState(ctx, resourceGroup, computeName)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(states, state) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("compute instance %s is still %s after %s, expected %s", computeName, state, timeout, strings.Join(states, " or "))
+		}
+		if time.Since(lastLog) >= time.Minute {
+			p.logger.Infof("Waiting for Compute instance %s to be %s, currently %s (%s left)...", computeName, strings.Join(states, " or "), state, time.Until(deadline).Round(time.Second))
+			lastLog = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(powerPollInterval):
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+func TestPowerState(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []*armcompute.InstanceViewStatus
+		want     string
+		wantOK   bool
+	}{
+		{"no statuses", nil, "", false},
+		{"provisioning only", []*armcompute.InstanceViewStatus{{Code: to.Ptr("ProvisioningState/succeeded")}}, "", false},
+		{"running", []*armcompute.InstanceViewStatus{{Code: to.Ptr("ProvisioningState/succeeded")}, {Code: to.Ptr("PowerState/running")}}, PowerStateRunning, true},
+		{"deallocating", []*armcompute.InstanceViewStatus{nil, {}, {Code: to.Ptr("PowerState/deallocating")}}, "deallocating", true},
+	}
+	for _, tt := range tests {
+		got, ok := powerState(tt.statuses)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: powerState() = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestConfigurePowerControl(t *testing.T) {
+	var p Provider
+	p.ConfigurePowerControl(false)
+	if len(p.powerPermissions) != 1 || p.powerPermissions[0] != deallocatePermission {
+		t.Errorf("ConfigurePowerControl(false) requires %v, want deallocate only", p.powerPermissions)
+	}
+	p.ConfigurePowerControl(true)
+	if len(p.powerPermissions) != 2 || p.powerPermissions[1] != startPermission {
+		t.Errorf("ConfigurePowerControl(true) requires %v, want deallocate and start", p.powerPermissions)
+	}
+}
@@ -4,38 +4,126 @@ package azure
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
-	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
 	"github.com/codebypatrickleung/kopru-cli/internal/logger"
 )
 
 // Provider implements Azure cloud operations.
 type Provider struct {
-	subscriptionID string
-	credential     azcore.TokenCredential
-	logger         *logger.Logger
+	subscriptionID      string
+	credential          azcore.TokenCredential
+	logger              *logger.Logger
+	downloadBlockSize   int64
+	downloadWorkers     int
+	downloadMBPerSecond int
+	managedIdentity     bool
+	authMethod          string
+	snapshotOptions     SnapshotOptions
+	powerPermissions    []requiredPermission          // Permissions to deallocate and start the VM, if it is
+	groupSnapshots      map[string]consistentSnapshot // Consistent snapshot of each disk, by disk name
+	liveSyncBases       map[string]liveSyncBase       // Base snapshot of each disk exported by ExportAzureDiskBase
+	mu                  sync.Mutex                    // Guards groupSnapshots and liveSyncBases
+	teardown            Teardown
+	transport           policy.Transporter // HTTP transport of the SDK clients; tests replace it
 }
 
-// NewProvider creates a new Azure provider instance.
-func NewProvider(subscriptionID string, log *logger.Logger) (*Provider, error) {
+// Teardown registers fn, which undoes a change made by the provider such as creating a
+// snapshot, and returns a function that runs it once the change is no longer needed.
+// The workflow registers them with its cleanup manager, so that they also run when the
+// run fails or is interrupted first.
+type Teardown func(name string, fn func(context.Context) error) (run func())
+
+// SetTeardown makes the provider register its teardown actions with t. Without it, they
+// only run when the operation that made the change returns.
+func (p *Provider) SetTeardown(t Teardown) {
+	p.teardown = t
+}
+
+// registerTeardown registers fn with the Teardown set by SetTeardown, if any.
+func (p *Provider) registerTeardown(name string, fn func(context.Context) error) func() {
+	if p.teardown != nil {
+		return p.teardown(name, fn)
+	}
+	return func() {
+		if err := fn(context.Background()); err != nil {
+			p.logger.Warningf("Failed to %s: %v", name, err)
+		}
+	}
+}
+
+// NewProvider creates a new Azure provider instance that authenticates with the
+// method selected in auth.
+//
+// With AuthAuto, when Kopru runs on an Azure VM and no service principal is configured
+// in the environment, the managed identity of the VM is used explicitly (the
+// user-assigned identity with auth.ManagedIdentityClientID, if set). Otherwise
+// DefaultAzureCredential is used. An empty subscriptionID defaults to the subscription
+// of the migration VM when a managed identity is used.
+func NewProvider(subscriptionID string, auth Auth, log *logger.Logger) (*Provider, error) {
+	p := &Provider{
+		subscriptionID:      subscriptionID,
+		logger:              log,
+		downloadBlockSize:   defaultDownloadBlockSizeMB * 1024 * 1024,
+		downloadWorkers:     defaultDownloadWorkers,
+		downloadMBPerSecond: defaultDownloadMBPerSecond,
+		authMethod:          auth.Method,
+	}
+	if p.authMethod == "" {
+		p.authMethod = AuthAuto
+	}
+
+	if p.authMethod != AuthAuto {
+		cred, err := newCredential(auth, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure %s credential: %w", p.authMethod, err)
+		}
+		log.Infof("Authenticating to Azure with %s", p.authMethod)
+		p.credential = cred
+		if p.authMethod == AuthManagedIdentity {
+			p.managedIdentity = true
+			if md, err := queryInstanceMetadata(context.Background()); err == nil && p.subscriptionID == "" {
+				p.subscriptionID = md.SubscriptionID
+				log.Infof("Using subscription of the migration VM: %s", p.subscriptionID)
+			}
+		}
+		return p, nil
+	}
+
+	if !environmentCredentialsConfigured() {
+		cred, md, err := managedIdentityCredential(context.Background(), auth.ManagedIdentityClientID)
+		if err != nil {
+			return nil, err
+		}
+		if cred != nil {
+			log.Infof("Running on Azure VM '%s' - using its managed identity", md.Name)
+			p.credential, p.managedIdentity = cred, true
+			if p.subscriptionID == "" {
+				p.subscriptionID = md.SubscriptionID
+				log.Infof("Using subscription of the migration VM: %s", p.subscriptionID)
+			}
+			return p, nil
+		}
+	}
+
 	cred, err := azidentity.NewDefaultAzureCredential(nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Azure credential: %w", err)
+		return nil, fmt.Errorf("failed to create Azure credential (%s): %w", authHints[AuthAuto], err)
 	}
 	log.Debug("Successfully created DefaultAzureCredential")
-	return &Provider{
-		subscriptionID: subscriptionID,
-		credential:     cred,
-		logger:         log,
-	}, nil
+	p.credential = cred
+	return p, nil
 }
 
 // CheckComputeExists checks if a Compute instance exists and is accessible.
@@ -47,21 +135,93 @@ func (p *Provider) CheckComputeExists(ctx context.Context, resourceGroup, comput
 	return nil
 }
 
-// GetComputeInfo retrieves information about a Compute instance.
+// ListComputeNames lists the names of the Compute instances in a resource group.
+func (p *Provider) ListComputeNames(ctx context.Context, resourceGroup string) ([]string, error) {
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute client factory: %w", err)
+	}
+	pager := clientFactory.NewVirtualMachinesClient().NewListPager(resourceGroup, nil)
+	var names []string
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Compute instances: %w", err)
+		}
+		for _, vm := range page.Value {
+			if vm.Name != nil {
+				names = append(names, *vm.Name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// GetComputeInfo retrieves information about a Compute instance. An instance of a scale
+// set in Uniform orchestration mode, named <scale set>_<instance ID>, is returned as a
+// Compute instance.
 func (p *Provider) GetComputeInfo(ctx context.Context, resourceGroup, computeName string) (*armcompute.VirtualMachine, error) {
 	p.logger.Debugf("Getting Compute info for %s in resource group %s", computeName, resourceGroup)
-	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, nil)
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create compute client factory: %w", err)
 	}
 	vmClient := clientFactory.NewVirtualMachinesClient()
 	vm, err := vmClient.Get(ctx, resourceGroup, computeName, nil)
 	if err != nil {
+		if scaleSet, instanceID, ok := uniformInstanceName(computeName); ok && isNotFound(err) {
+			instance, instanceErr := clientFactory.NewVirtualMachineScaleSetVMsClient().Get(ctx, resourceGroup, scaleSet, instanceID, nil)
+			if instanceErr == nil {
+				return scaleSetVMAsVirtualMachine(&instance.VirtualMachineScaleSetVM), nil
+			}
+		}
 		return nil, fmt.Errorf("failed to get Compute instance: %w", err)
 	}
 	return &vm.VirtualMachine, nil
 }
 
+// ComputeInventory identifies a Compute instance and its disks for asset inventories.
+type ComputeInventory struct {
+	ResourceID  string
+	OSDiskID    string
+	DataDiskIDs []string
+	Tags        map[string]string
+}
+
+// GetComputeInventory retrieves the resource IDs and tags of a Compute instance and its managed disks.
+func (p *Provider) GetComputeInventory(ctx context.Context, resourceGroup, computeName string) (*ComputeInventory, error) {
+	vm, err := p.GetComputeInfo(ctx, resourceGroup, computeName)
+	if err != nil {
+		return nil, err
+	}
+	return computeInventory(vm), nil
+}
+
+// computeInventory extracts the resource IDs and tags of vm and its managed disks.
+func computeInventory(vm *armcompute.VirtualMachine) *ComputeInventory {
+	inv := &ComputeInventory{Tags: make(map[string]string, len(vm.Tags))}
+	if vm.ID != nil {
+		inv.ResourceID = *vm.ID
+	}
+	for k, v := range vm.Tags {
+		if v != nil {
+			inv.Tags[k] = *v
+		}
+	}
+	if vm.Properties == nil || vm.Properties.StorageProfile == nil {
+		return inv
+	}
+	if osDisk := vm.Properties.StorageProfile.OSDisk; osDisk != nil && osDisk.ManagedDisk != nil && osDisk.ManagedDisk.ID != nil {
+		inv.OSDiskID = *osDisk.ManagedDisk.ID
+	}
+	for _, disk := range vm.Properties.StorageProfile.DataDisks {
+		if disk.ManagedDisk != nil && disk.ManagedDisk.ID != nil {
+			inv.DataDiskIDs = append(inv.DataDiskIDs, *disk.ManagedDisk.ID)
+		}
+	}
+	return inv
+}
+
 // GetComputeOSType retrieves the OS type of a Compute instance.
 func (p *Provider) GetComputeOSType(ctx context.Context, resourceGroup, computeName string) (string, error) {
 	vm, err := p.GetComputeInfo(ctx, resourceGroup, computeName)
@@ -76,19 +236,14 @@ func (p *Provider) GetComputeOSType(ctx context.Context, resourceGroup, computeN
 
 // CheckComputeIsStopped checks if the Compute instance is stopped or deallocated.
 func (p *Provider) CheckComputeIsStopped(ctx context.Context, resourceGroup, computeName string) (bool, error) {
-	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, nil)
-	if err != nil {
-		return false, fmt.Errorf("failed to create compute client factory: %w", err)
-	}
-	vmClient := clientFactory.NewVirtualMachinesClient()
-	instanceView, err := vmClient.InstanceView(ctx, resourceGroup, computeName, nil)
+	statuses, err := p.instanceStatuses(ctx, resourceGroup, computeName)
 	if err != nil {
-		return false, fmt.Errorf("failed to get Compute instance view: %w", err)
+		return false, err
 	}
-	if instanceView.Statuses == nil {
+	if statuses == nil {
 		return false, fmt.Errorf("compute instance view has no statuses")
 	}
-	for _, status := range instanceView.Statuses {
+	for _, status := range statuses {
 		if status.Code == nil {
 			continue
 		}
@@ -135,154 +290,236 @@ func (p *Provider) GetComputeDataDiskNames(ctx context.Context, resourceGroup, c
 	return diskNames, nil
 }
 
-// GetComputeVMSize retrieves the VM size details for a Compute instance.
-func (p *Provider) GetComputeVMSize(ctx context.Context, resourceGroup, computeName string) (*armcompute.VirtualMachineSize, error) {
+// GetComputeDiskSizesGB retrieves the sizes of the OS disk and data disks of a Compute
+// instance, as reported by its storage profile. Sizes Azure does not report are zero.
+func (p *Provider) GetComputeDiskSizesGB(ctx context.Context, resourceGroup, computeName string) (osDiskGB int64, dataDisksGB []int64, err error) {
 	vm, err := p.GetComputeInfo(ctx, resourceGroup, computeName)
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
-	if vm.Properties == nil || vm.Properties.HardwareProfile == nil || vm.Properties.HardwareProfile.VMSize == nil {
-		return nil, fmt.Errorf("VM hardware profile not found")
+	if vm.Properties == nil || vm.Properties.StorageProfile == nil {
+		return 0, nil, fmt.Errorf("compute instance storage profile not found")
 	}
-	vmSizeName := string(*vm.Properties.HardwareProfile.VMSize)
-	location := *vm.Location
-
-	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create compute client factory: %w", err)
+	profile := vm.Properties.StorageProfile
+	if profile.OSDisk != nil && profile.OSDisk.DiskSizeGB != nil {
+		osDiskGB = int64(*profile.OSDisk.DiskSizeGB)
 	}
-	sizesClient := clientFactory.NewVirtualMachineSizesClient()
-	pager := sizesClient.NewListPager(location, nil)
-	for pager.More() {
-		page, err := pager.NextPage(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list VM sizes: %w", err)
-		}
-		for _, size := range page.Value {
-			if size.Name != nil && *size.Name == vmSizeName {
-				return size, nil
-			}
+	for _, disk := range profile.DataDisks {
+		var sizeGB int64
+		if disk != nil && disk.DiskSizeGB != nil {
+			sizeGB = int64(*disk.DiskSizeGB)
 		}
+		dataDisksGB = append(dataDisksGB, sizeGB)
 	}
-	return nil, fmt.Errorf("VM size %s not found in location %s", vmSizeName, location)
+	return osDiskGB, dataDisksGB, nil
 }
 
-// GetComputeCPUAndMemory retrieves the vCPU count and memory in GB for a Compute instance.
-// Azure VMs are based on vCPU count.
-func (p *Provider) GetComputeCPUAndMemory(ctx context.Context, resourceGroup, computeName string) (int32, int32, error) {
-	vmSize, err := p.GetComputeVMSize(ctx, resourceGroup, computeName)
+// GetComputeVMSizeName retrieves the VM size of a Compute instance, e.g. Standard_D4s_v5.
+func (p *Provider) GetComputeVMSizeName(ctx context.Context, resourceGroup, computeName string) (string, error) {
+	vm, err := p.GetComputeInfo(ctx, resourceGroup, computeName)
 	if err != nil {
-		return 0, 0, err
-	}
-	if vmSize.NumberOfCores == nil {
-		return 0, 0, fmt.Errorf("number of cores not available for VM size")
+		return "", err
 	}
-	if vmSize.MemoryInMB == nil {
-		return 0, 0, fmt.Errorf("memory size not available for VM size")
+	if vm.Properties == nil || vm.Properties.HardwareProfile == nil || vm.Properties.HardwareProfile.VMSize == nil {
+		return "", fmt.Errorf("VM hardware profile not found")
 	}
-	cpus := *vmSize.NumberOfCores
-	memoryMB := *vmSize.MemoryInMB
-	memoryGB := (memoryMB + 1023) / 1024
-	return cpus, memoryGB, nil
+	return string(*vm.Properties.HardwareProfile.VMSize), nil
 }
 
 // GetComputeArchitecture retrieves the CPU architecture of a Compute instance.
 // Returns "x86_64" or "ARM64" based on the VM size SKU.
 func (p *Provider) GetComputeArchitecture(ctx context.Context, resourceGroup, computeName string) (string, error) {
-	vm, err := p.GetComputeInfo(ctx, resourceGroup, computeName)
+	vmSizeName, err := p.GetComputeVMSizeName(ctx, resourceGroup, computeName)
 	if err != nil {
 		return "", err
 	}
-	if vm.Properties == nil || vm.Properties.HardwareProfile == nil || vm.Properties.HardwareProfile.VMSize == nil {
-		return "", fmt.Errorf("VM hardware profile not found")
-	}
-	vmSizeName := string(*vm.Properties.HardwareProfile.VMSize)
+	return sizeNameArchitecture(vmSizeName), nil
+}
+
+// sizeNameArchitecture returns the CPU architecture of a VM size from its name, in which
+// a "p" marks Arm-based processors, e.g. Standard_D4ps_v5.
+func sizeNameArchitecture(vmSizeName string) string {
 	if strings.Contains(vmSizeName, "p") {
-		return "ARM64", nil
+		return "ARM64"
 	}
-	return "x86_64", nil
+	return "x86_64"
 }
 
 // ExportAzureDisk exports an Azure disk by creating a snapshot, generating a SAS URL, and downloading the VHD.
+// The snapshot taken by CreateConsistentSnapshots is used instead, if there is one for the disk.
+//
+// When the download fails, the snapshot it created and its SAS grant are kept, and the
+// next export of the disk to the same directory resumes the download from that snapshot.
 func (p *Provider) ExportAzureDisk(ctx context.Context, diskName, resourceGroup, exportDir string) (string, error) {
-	timestamp := strconv.FormatInt(time.Now().Unix(), 36)
-	maxDiskNameLen := 80 - 4 - len(timestamp)
-	truncatedDiskName := diskName
-	if len(diskName) > maxDiskNameLen {
-		truncatedDiskName = diskName[:maxDiskNameLen]
-	}
-	snapshotName := fmt.Sprintf("ss-%s-%s", truncatedDiskName, timestamp)
 	vhdFile := filepath.Join(exportDir, fmt.Sprintf("%s.vhd", diskName))
-
-	p.logger.Infof("Creating snapshot: %s", snapshotName)
-	if err := p.CreateSnapshot(ctx, resourceGroup, snapshotName, diskName); err != nil {
-		return "", fmt.Errorf("failed to create snapshot: %w", err)
-	}
-	p.logger.Success("✓ Snapshot created")
-
-	defer func() {
-		p.logger.Info("Cleaning up snapshot...")
-		if err := p.RevokeSnapshotAccess(ctx, resourceGroup, snapshotName); err != nil {
-			p.logger.Warningf("Failed to revoke access to snapshot: %v", err)
-		}
-		if err := p.DeleteSnapshot(ctx, resourceGroup, snapshotName); err != nil {
-			p.logger.Warningf("Failed to delete snapshot %s - manual cleanup may be required", snapshotName)
+	manifestFile := vhdFile + manifestSuffix
+	snapshot, ok := p.groupSnapshot(diskName)
+	ref := snapshotRef{Name: snapshot.name}
+	// keep is set when the download fails after recording its progress, so that the
+	// snapshot outlives the run.
+	var keep atomic.Bool
+	if ok {
+		p.logger.Infof("Using consistent snapshot: %s", ref.Name)
+	} else {
+		resumed, found := p.resumableSnapshot(ctx, resourceGroup, manifestFile)
+		if found {
+			ref = resumed
+			p.logger.Infof("Resuming the download from snapshot %s of an earlier run", ref.Name)
 		} else {
-			p.logger.Successf("✓ Snapshot deleted: %s", snapshotName)
+			ref.Name = renderSnapshotName(p.snapshotOptions.NameTemplate, p.snapshotOptions.MigrationID, diskName, time.Now())
+			p.logger.Infof("Creating snapshot: %s", ref.Name)
 		}
-	}()
+		deleteSnapshot := p.snapshotDeleter(resourceGroup, ref.Name)
+		snapshot.delete = p.registerTeardown("delete snapshot "+ref.Name, func(ctx context.Context) error {
+			if keep.Load() {
+				return nil
+			}
+			return deleteSnapshot(ctx)
+		})
+		if !found {
+			if err := p.CreateSnapshot(ctx, resourceGroup, ref.Name, diskName); err != nil {
+				snapshot.delete()
+				return "", fmt.Errorf("failed to create snapshot: %w", err)
+			}
+			p.logger.Success("✓ Snapshot created")
+		}
+	}
+	defer snapshot.delete()
 
-	p.logger.Infof("Generating SAS URL for snapshot: %s", snapshotName)
-	sasURL, err := p.GrantSnapshotAccess(ctx, resourceGroup, snapshotName, 200000)
+	sizeBytes, snapshotID, err := p.snapshotInfo(ctx, resourceGroup, ref.Name)
+	if err != nil {
+		return "", err
+	}
+	ref.ID = snapshotID
+	duration := sasDuration(sizeBytes, p.downloadMBPerSecond)
+	if duration.Seconds() > math.MaxInt32 {
+		return "", fmt.Errorf("snapshot %s of %d GB cannot be downloaded at %d MB/s within the longest SAS validity", ref.Name, sizeBytes>>30, p.downloadMBPerSecond)
+	}
+	p.logger.Infof("Generating SAS URL for snapshot: %s (valid for %s)", ref.Name, duration)
+	revokeAccess := p.registerTeardown("revoke access to snapshot "+ref.Name, func(ctx context.Context) error {
+		if keep.Load() {
+			return nil
+		}
+		return p.RevokeSnapshotAccess(ctx, resourceGroup, ref.Name)
+	})
+	defer revokeAccess()
+	sasURL, err := p.GrantSnapshotAccess(ctx, resourceGroup, ref.Name, int32(duration.Seconds()))
 	if err != nil {
 		return "", fmt.Errorf("failed to generate SAS URL: %w", err)
 	}
 	p.logger.Success("✓ SAS URL generated")
 
 	p.logger.Info("Downloading disk (this may take a while)...")
-	if err := p.DownloadFromSASURL(ctx, sasURL, vhdFile); err != nil {
+	if err := p.downloadSnapshot(ctx, sasURL, vhdFile, ref); err != nil {
+		if _, statErr := os.Stat(manifestFile); !ok && statErr == nil {
+			keep.Store(true)
+			p.logger.Warningf("Keeping snapshot %s so that the next run resumes the download; delete it if the migration is abandoned", ref.Name)
+		}
 		return "", fmt.Errorf("failed to download disk: %w", err)
 	}
 	p.logger.Successf("✓ Disk downloaded: %s", vhdFile)
 	return vhdFile, nil
 }
 
-// CreateSnapshot creates a snapshot of a disk.
+// CreateSnapshot creates a snapshot of a disk, tagged with the creator and migration ID
+// set by ConfigureSnapshots.
 func (p *Provider) CreateSnapshot(ctx context.Context, resourceGroup, snapshotName, diskName string) error {
-	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, nil)
+	poller, err := p.beginSnapshot(ctx, resourceGroup, snapshotName, diskName, false)
 	if err != nil {
-		return fmt.Errorf("failed to create compute client factory: %w", err)
+		return err
+	}
+	_, err = poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	return nil
+}
+
+// beginSnapshot starts the creation of a full or incremental snapshot of a disk. The
+// snapshot captures the disk at the time the request is accepted; the returned poller
+// waits for the copy.
+func (p *Provider) beginSnapshot(ctx context.Context, resourceGroup, snapshotName, diskName string, incremental bool) (*runtime.Poller[armcompute.SnapshotsClientCreateOrUpdateResponse], error) {
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute client factory: %w", err)
 	}
 	snapshotsClient := clientFactory.NewSnapshotsClient()
 	disksClient := clientFactory.NewDisksClient()
 	disk, err := disksClient.Get(ctx, resourceGroup, diskName, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get disk: %w", err)
+		return nil, fmt.Errorf("failed to get disk: %w", err)
 	}
 	createOption := armcompute.DiskCreateOptionCopy
 	poller, err := snapshotsClient.BeginCreateOrUpdate(ctx, resourceGroup, snapshotName,
 		armcompute.Snapshot{
 			Location: disk.Location,
+			Tags:     snapshotTags(p.snapshotOptions),
 			Properties: &armcompute.SnapshotProperties{
 				CreationData: &armcompute.CreationData{
 					CreateOption:     &createOption,
 					SourceResourceID: disk.ID,
 				},
+				Incremental: &incremental,
 			},
 		}, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin snapshot creation: %w", err)
+		return nil, fmt.Errorf("failed to begin snapshot creation: %w", err)
 	}
-	_, err = poller.PollUntilDone(ctx, nil)
+	return poller, nil
+}
+
+// snapshotSizeBytes returns the size of a snapshot in bytes.
+func (p *Provider) snapshotSizeBytes(ctx context.Context, resourceGroup, snapshotName string) (int64, error) {
+	sizeBytes, _, err := p.snapshotInfo(ctx, resourceGroup, snapshotName)
+	return sizeBytes, err
+}
+
+// snapshotInfo returns the size of a snapshot in bytes and its unique ID.
+func (p *Provider) snapshotInfo(ctx context.Context, resourceGroup, snapshotName string) (int64, string, error) {
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
 	if err != nil {
-		return fmt.Errorf("failed to create snapshot: %w", err)
+		return 0, "", fmt.Errorf("failed to create compute client factory: %w", err)
 	}
-	return nil
+	snapshot, err := clientFactory.NewSnapshotsClient().Get(ctx, resourceGroup, snapshotName, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	props := snapshot.Properties
+	var uniqueID string
+	if props != nil && props.UniqueID != nil {
+		uniqueID = *props.UniqueID
+	}
+	switch {
+	case props != nil && props.DiskSizeBytes != nil:
+		return *props.DiskSizeBytes, uniqueID, nil
+	case props != nil && props.DiskSizeGB != nil:
+		return int64(*props.DiskSizeGB) << 30, uniqueID, nil
+	}
+	return 0, "", fmt.Errorf("size of snapshot %s is unknown", snapshotName)
+}
+
+// resumableSnapshot returns the snapshot recorded in the download manifest at
+// manifestFile by a failed export, if it still exists as the same resource.
+func (p *Provider) resumableSnapshot(ctx context.Context, resourceGroup, manifestFile string) (snapshotRef, bool) {
+	m, err := readDownloadManifest(manifestFile)
+	if err != nil || m.Snapshot == "" || m.SnapshotID == "" {
+		return snapshotRef{}, false
+	}
+	_, uniqueID, err := p.snapshotInfo(ctx, resourceGroup, m.Snapshot)
+	switch {
+	case err != nil && !isNotFound(err):
+		p.logger.Warningf("Failed to look up snapshot %s of the partial download: %v", m.Snapshot, err)
+		return snapshotRef{}, false
+	case err != nil || uniqueID != m.SnapshotID:
+		p.logger.Infof("Snapshot %s of the partial download no longer exists", m.Snapshot)
+		return snapshotRef{}, false
+	}
+	return snapshotRef{Name: m.Snapshot, ID: uniqueID}, true
 }
 
 // GrantSnapshotAccess grants read access to a snapshot and returns the SAS URL.
 func (p *Provider) GrantSnapshotAccess(ctx context.Context, resourceGroup, snapshotName string, durationInSeconds int32) (string, error) {
-	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, nil)
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
 	if err != nil {
 		return "", fmt.Errorf("failed to create compute client factory: %w", err)
 	}
@@ -306,28 +543,9 @@ func (p *Provider) GrantSnapshotAccess(ctx context.Context, resourceGroup, snaps
 	return *result.AccessSAS, nil
 }
 
-// DownloadFromSASURL downloads a file from an Azure blob using a SAS URL.
-func (p *Provider) DownloadFromSASURL(ctx context.Context, sasURL, destFile string) error {
-	blobClient, err := blob.NewClientWithNoCredential(sasURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create blob client: %w", err)
-	}
-	// #nosec G304 -- destFile is controlled by the application
-	out, err := os.Create(destFile)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer out.Close()
-	_, err = blobClient.DownloadFile(ctx, out, nil)
-	if err != nil {
-		return fmt.Errorf("failed to download blob: %w", err)
-	}
-	return nil
-}
-
 // RevokeSnapshotAccess revokes access to a snapshot.
 func (p *Provider) RevokeSnapshotAccess(ctx context.Context, resourceGroup, snapshotName string) error {
-	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, nil)
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
 	if err != nil {
 		return fmt.Errorf("failed to create compute client factory: %w", err)
 	}
@@ -343,9 +561,21 @@ func (p *Provider) RevokeSnapshotAccess(ctx context.Context, resourceGroup, snap
 	return nil
 }
 
+// snapshotDeleter returns a teardown action that deletes a snapshot created for an export.
+func (p *Provider) snapshotDeleter(resourceGroup, snapshotName string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		p.logger.Info("Cleaning up snapshot...")
+		if err := p.DeleteSnapshot(ctx, resourceGroup, snapshotName); err != nil {
+			return fmt.Errorf("%w - manual cleanup may be required", err)
+		}
+		p.logger.Successf("✓ Snapshot deleted: %s", snapshotName)
+		return nil
+	}
+}
+
 // DeleteSnapshot deletes a snapshot.
 func (p *Provider) DeleteSnapshot(ctx context.Context, resourceGroup, snapshotName string) error {
-	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, nil)
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
 	if err != nil {
 		return fmt.Errorf("failed to create compute client factory: %w", err)
 	}
@@ -0,0 +1,82 @@
+package azure
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/retry"
+)
+
+// nonRetriable is implemented by SDK errors that must not be retried, such as
+// credential failures.
+type nonRetriable interface {
+	error
+	NonRetriable()
+}
+
+// transient reports whether a failed attempt may succeed when retried.
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		var nre nonRetriable
+		return !errors.As(err, &nre)
+	}
+	return retry.RetryableStatus(resp.StatusCode)
+}
+
+// retryAfter returns the delay requested by a throttled response, or zero.
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	for _, header := range []string{"retry-after-ms", "x-ms-retry-after-ms"} {
+		if ms, err := strconv.Atoi(resp.Header.Get(header)); err == nil && ms > 0 {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	value := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// retryPolicy retries Azure calls with the shared retry policy, in place of the SDK's
+// own retries. It honours the Retry-After header of throttled responses up to the
+// maximum delay of the policy.
+type retryPolicy struct {
+	logger *logger.Logger
+}
+
+func (r retryPolicy) Do(req *policy.Request) (*http.Response, error) {
+	shared := retry.Default()
+	ctx := req.Raw().Context()
+	for attempt := 1; ; attempt++ {
+		if err := req.RewindBody(); err != nil {
+			return nil, err
+		}
+		resp, err := req.Clone(ctx).Next()
+		if ctx.Err() != nil || !transient(resp, err) || !shared.Allow(attempt) {
+			return resp, err
+		}
+		delay := max(shared.Delay(attempt+1), min(retryAfter(resp), shared.MaxDelay))
+		reason := "error"
+		if err == nil {
+			reason = resp.Status
+			runtime.Drain(resp)
+		}
+		r.logger.Debugf("Azure %s %s failed (%s), retrying in %s (attempt %d of %d)", req.Raw().Method, req.Raw().URL.Path, reason, delay.Round(time.Millisecond), attempt+1, shared.MaxAttempts)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
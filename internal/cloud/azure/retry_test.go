@@ -0,0 +1,81 @@
+package azure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/retry"
+)
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header, value string
+		want          time.Duration
+	}{
+		{"Retry-After", "17", 17 * time.Second},
+		{"retry-after-ms", "250", 250 * time.Millisecond},
+		{"x-ms-retry-after-ms", "1500", 1500 * time.Millisecond},
+		{"Retry-After", "soon", 0},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set(tt.header, tt.value)
+		if got := retryAfter(resp); got != tt.want {
+			t.Errorf("retryAfter(%s: %s) = %s, want %s", tt.header, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	defer retry.Configure(retry.Default())
+	retry.Configure(retry.New(3, time.Millisecond, 5*time.Millisecond, 3))
+
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/throttled":
+			if calls < 3 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	p := &Provider{logger: logger.New(false)}
+	options := p.clientOptions().ClientOptions
+	pipeline := runtime.NewPipeline("kopru", "test", runtime.PipelineOptions{}, &options)
+	get := func(path string) int {
+		calls = 0
+		req, err := runtime.NewRequest(context.Background(), http.MethodGet, server.URL+path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := pipeline.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		return resp.StatusCode
+	}
+
+	if status := get("/throttled"); status != http.StatusOK || calls != 3 {
+		t.Errorf("throttled call = %d after %d attempts, want 200 after 3", status, calls)
+	}
+	if status := get("/missing"); status != http.StatusNotFound || calls != 1 {
+		t.Errorf("missing resource = %d after %d attempts, want 404 after 1", status, calls)
+	}
+	// Two retries were taken from the budget of three, so only one is left.
+	if status := get("/throttled"); status != http.StatusTooManyRequests || calls != 2 {
+		t.Errorf("call with exhausted budget = %d after %d attempts, want 429 after 2", status, calls)
+	}
+}
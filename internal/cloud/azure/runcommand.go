@@ -0,0 +1,77 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+// RunCommand runs script as root (Linux) or SYSTEM (Windows) on a running VM through
+// the Run Command feature of the Azure VM agent and returns its output. Azure runs one
+// command at a time per VM and keeps only the last 4096 bytes of the output.
+func (p *Provider) RunCommand(ctx context.Context, resourceGroup, vmName string, windows bool, script string) (string, error) {
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
+	if err != nil {
+		return "", fmt.Errorf("failed to create compute client factory: %w", err)
+	}
+	commandID := "RunShellScript"
+	if windows {
+		commandID = "RunPowerShellScript"
+	}
+	var lines []*string
+	for _, line := range strings.Split(script, "\n") {
+		lines = append(lines, to.Ptr(line))
+	}
+	input := armcompute.RunCommandInput{CommandID: &commandID, Script: lines}
+	scaleSet, instanceID, uniform, err := scaleSetInstance(ctx, clientFactory, resourceGroup, vmName)
+	if err != nil {
+		return "", err
+	}
+	if uniform {
+		poller, err := clientFactory.NewVirtualMachineScaleSetVMsClient().BeginRunCommand(ctx, resourceGroup, scaleSet, instanceID, input, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to begin run command: %w", err)
+		}
+		resp, err := poller.PollUntilDone(ctx, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to run command: %w", err)
+		}
+		return runCommandOutput(resp.Value)
+	}
+	poller, err := clientFactory.NewVirtualMachinesClient().BeginRunCommand(ctx, resourceGroup, vmName, input, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin run command: %w", err)
+	}
+	resp, err := poller.PollUntilDone(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to run command: %w", err)
+	}
+	return runCommandOutput(resp.Value)
+}
+
+// runCommandOutput joins the messages of the statuses of a run command, and fails when
+// a status reports an error.
+func runCommandOutput(statuses []*armcompute.InstanceViewStatus) (string, error) {
+	var output []string
+	failed := false
+	for _, s := range statuses {
+		if s == nil {
+			continue
+		}
+		if s.Message != nil && *s.Message != "" {
+			output = append(output, *s.Message)
+		}
+		if (s.Level != nil && *s.Level == armcompute.StatusLevelTypesError) ||
+			(s.Code != nil && strings.HasSuffix(strings.ToLower(*s.Code), "/failed")) {
+			failed = true
+		}
+	}
+	out := strings.Join(output, "\n")
+	if failed {
+		return out, fmt.Errorf("run command failed: %s", out)
+	}
+	return out, nil
+}
@@ -0,0 +1,148 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+// uniformInstancePattern matches the names of the instances of scale sets in Uniform
+// orchestration mode, <scale set>_<instance ID>.
+var uniformInstancePattern = regexp.MustCompile(`^(.+)_(\d+)$`)
+
+// uniformInstanceName splits the name of an instance of a scale set in Uniform
+// orchestration mode into the name of its scale set and its instance ID.
+func uniformInstanceName(name string) (scaleSet, instanceID string, ok bool) {
+	m := uniformInstancePattern.FindStringSubmatch(name)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// isNotFound reports whether err is an Azure API response with status 404.
+func isNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
+
+// listUniformInstances lists the names of the instances of the scale set scaleSet when
+// it is in Uniform orchestration mode, whose instances are not Compute instances of
+// their own. ok is false for a scale set in Flexible orchestration mode.
+func listUniformInstances(ctx context.Context, clientFactory *armcompute.ClientFactory, resourceGroup, scaleSet string) (names []string, ok bool, err error) {
+	vmss, err := clientFactory.NewVirtualMachineScaleSetsClient().Get(ctx, resourceGroup, scaleSet, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get scale set %s: %w", scaleSet, err)
+	}
+	if vmss.Properties == nil || vmss.Properties.OrchestrationMode == nil || *vmss.Properties.OrchestrationMode != armcompute.OrchestrationModeUniform {
+		return nil, false, nil
+	}
+	pager := clientFactory.NewVirtualMachineScaleSetVMsClient().NewListPager(resourceGroup, scaleSet, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list the instances of scale set %s: %w", scaleSet, err)
+		}
+		for _, instance := range page.Value {
+			if instance != nil && instance.Name != nil {
+				names = append(names, *instance.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, true, nil
+}
+
+// scaleSetInstance reports whether computeName is not a Compute instance but an instance
+// of a scale set in Uniform orchestration mode, and returns its scale set and instance ID.
+func scaleSetInstance(ctx context.Context, clientFactory *armcompute.ClientFactory, resourceGroup, computeName string) (scaleSet, instanceID string, ok bool, err error) {
+	scaleSet, instanceID, ok = uniformInstanceName(computeName)
+	if !ok {
+		return "", "", false, nil
+	}
+	if _, err := clientFactory.NewVirtualMachinesClient().Get(ctx, resourceGroup, computeName, nil); err == nil {
+		return "", "", false, nil
+	} else if !isNotFound(err) {
+		return "", "", false, fmt.Errorf("failed to get Compute instance: %w", err)
+	}
+	if _, err := clientFactory.NewVirtualMachineScaleSetVMsClient().Get(ctx, resourceGroup, scaleSet, instanceID, nil); err != nil {
+		if isNotFound(err) {
+			return "", "", false, nil
+		}
+		return "", "", false, fmt.Errorf("failed to get instance %s of scale set %s: %w", instanceID, scaleSet, err)
+	}
+	return scaleSet, instanceID, true, nil
+}
+
+// scaleSetVMAsVirtualMachine returns an instance of a Uniform scale set as a Compute
+// instance, with the profiles that describe its disks, size, network and extensions.
+// Instances that take their size from the scale set model report it as their SKU.
+func scaleSetVMAsVirtualMachine(instance *armcompute.VirtualMachineScaleSetVM) *armcompute.VirtualMachine {
+	vm := &armcompute.VirtualMachine{
+		ID:        instance.ID,
+		Name:      instance.Name,
+		Type:      instance.Type,
+		Location:  instance.Location,
+		Tags:      instance.Tags,
+		Zones:     instance.Zones,
+		Plan:      instance.Plan,
+		Identity:  instance.Identity,
+		Resources: instance.Resources,
+	}
+	if props := instance.Properties; props != nil {
+		vm.Properties = &armcompute.VirtualMachineProperties{
+			AdditionalCapabilities: props.AdditionalCapabilities,
+			AvailabilitySet:        props.AvailabilitySet,
+			DiagnosticsProfile:     props.DiagnosticsProfile,
+			HardwareProfile:        props.HardwareProfile,
+			LicenseType:            props.LicenseType,
+			NetworkProfile:         props.NetworkProfile,
+			OSProfile:              props.OSProfile,
+			SecurityProfile:        props.SecurityProfile,
+			StorageProfile:         props.StorageProfile,
+			UserData:               props.UserData,
+			ProvisioningState:      props.ProvisioningState,
+			TimeCreated:            props.TimeCreated,
+			VMID:                   props.VMID,
+		}
+	}
+	if instance.SKU != nil && instance.SKU.Name != nil && (vm.Properties == nil || vm.Properties.HardwareProfile == nil || vm.Properties.HardwareProfile.VMSize == nil) {
+		if vm.Properties == nil {
+			vm.Properties = &armcompute.VirtualMachineProperties{}
+		}
+		size := armcompute.VirtualMachineSizeTypes(*instance.SKU.Name)
+		vm.Properties.HardwareProfile = &armcompute.HardwareProfile{VMSize: &size}
+	}
+	return vm
+}
+
+// instanceStatuses returns the statuses of the instance view of a Compute instance or of
+// an instance of a Uniform scale set, such as its power state.
+func (p *Provider) instanceStatuses(ctx context.Context, resourceGroup, computeName string) ([]*armcompute.InstanceViewStatus, error) {
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute client factory: %w", err)
+	}
+	scaleSet, instanceID, uniform, err := scaleSetInstance(ctx, clientFactory, resourceGroup, computeName)
+	if err != nil {
+		return nil, err
+	}
+	if uniform {
+		instanceView, err := clientFactory.NewVirtualMachineScaleSetVMsClient().GetInstanceView(ctx, resourceGroup, scaleSet, instanceID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Compute instance view: %w", err)
+		}
+		return instanceView.Statuses, nil
+	}
+	instanceView, err := clientFactory.NewVirtualMachinesClient().InstanceView(ctx, resourceGroup, computeName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Compute instance view: %w", err)
+	}
+	return instanceView.Statuses, nil
+}
@@ -0,0 +1,63 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+func TestUniformInstanceName(t *testing.T) {
+	tests := []struct {
+		name       string
+		scaleSet   string
+		instanceID string
+		ok         bool
+	}{
+		{name: "web-vmss_3", scaleSet: "web-vmss", instanceID: "3", ok: true},
+		{name: "app_vmss_12", scaleSet: "app_vmss", instanceID: "12", ok: true},
+		{name: "web-vm-01"},
+		{name: "web-vmss_"},
+		{name: "vmss_a1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scaleSet, instanceID, ok := uniformInstanceName(tt.name)
+			if scaleSet != tt.scaleSet || instanceID != tt.instanceID || ok != tt.ok {
+				t.Errorf("uniformInstanceName(%q) = %q, %q, %v, want %q, %q, %v", tt.name, scaleSet, instanceID, ok, tt.scaleSet, tt.instanceID, tt.ok)
+			}
+		})
+	}
+}
+
+func TestScaleSetVMAsVirtualMachine(t *testing.T) {
+	osDisk := &armcompute.OSDisk{Name: to.Ptr("web-vmss_3_OsDisk_1"), OSType: to.Ptr(armcompute.OperatingSystemTypesLinux)}
+	dataDisks := []*armcompute.DataDisk{{Lun: to.Ptr[int32](0), Name: to.Ptr("web-vmss_3_disk2")}}
+	instance := &armcompute.VirtualMachineScaleSetVM{
+		Name:     to.Ptr("web-vmss_3"),
+		Location: to.Ptr("westeurope"),
+		SKU:      &armcompute.SKU{Name: to.Ptr("Standard_D2s_v3")},
+		Properties: &armcompute.VirtualMachineScaleSetVMProperties{
+			StorageProfile: &armcompute.StorageProfile{OSDisk: osDisk, DataDisks: dataDisks},
+		},
+	}
+	vm := scaleSetVMAsVirtualMachine(instance)
+	if vm.Name == nil || *vm.Name != "web-vmss_3" || vm.Location == nil || *vm.Location != "westeurope" {
+		t.Errorf("scaleSetVMAsVirtualMachine() did not keep the name and location: %+v", vm)
+	}
+	if vm.Properties.StorageProfile.OSDisk != osDisk || len(vm.Properties.StorageProfile.DataDisks) != 1 {
+		t.Errorf("scaleSetVMAsVirtualMachine() did not keep the storage profile")
+	}
+	if size := vm.Properties.HardwareProfile.VMSize; size == nil || *size != "Standard_D2s_v3" {
+		t.Errorf("VM size = %v, want Standard_D2s_v3 from the SKU", size)
+	}
+
+	instance.Properties.HardwareProfile = &armcompute.HardwareProfile{VMSize: to.Ptr(armcompute.VirtualMachineSizeTypesStandardB2S)}
+	if size := scaleSetVMAsVirtualMachine(instance).Properties.HardwareProfile.VMSize; *size != armcompute.VirtualMachineSizeTypesStandardB2S {
+		t.Errorf("VM size = %s, want the size of the hardware profile", *size)
+	}
+
+	if vm := scaleSetVMAsVirtualMachine(&armcompute.VirtualMachineScaleSetVM{Name: to.Ptr("web-vmss_4")}); vm.Properties != nil {
+		t.Errorf("scaleSetVMAsVirtualMachine() made up properties: %+v", vm.Properties)
+	}
+}
@@ -0,0 +1,83 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+// ComputeSize is the VM size of a Compute instance, as described by the Resource SKUs
+// API.
+type ComputeSize struct {
+	Name         string // e.g. Standard_D4s_v5
+	VCPUs        int32
+	MemoryGB     int32  // Rounded up to whole GB
+	Architecture string // x86_64 or ARM64
+}
+
+// GetComputeSize resolves the VM size of the hardware profile of a Compute instance to
+// its vCPUs, memory and CPU architecture through the Resource SKUs API of the VM's
+// location.
+func (p *Provider) GetComputeSize(ctx context.Context, resourceGroup, computeName string) (ComputeSize, error) {
+	vm, err := p.GetComputeInfo(ctx, resourceGroup, computeName)
+	if err != nil {
+		return ComputeSize{}, err
+	}
+	if vm.Properties == nil || vm.Properties.HardwareProfile == nil || vm.Properties.HardwareProfile.VMSize == nil || vm.Location == nil {
+		return ComputeSize{}, fmt.Errorf("VM hardware profile not found")
+	}
+	vmSizeName := string(*vm.Properties.HardwareProfile.VMSize)
+	location := *vm.Location
+
+	client, err := armcompute.NewResourceSKUsClient(p.subscriptionID, p.credential, p.clientOptions())
+	if err != nil {
+		return ComputeSize{}, fmt.Errorf("failed to create resource SKUs client: %w", err)
+	}
+	pager := client.NewListPager(&armcompute.ResourceSKUsClientListOptions{Filter: to.Ptr(fmt.Sprintf("location eq '%s'", location))})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return ComputeSize{}, fmt.Errorf("failed to list resource SKUs: %w", err)
+		}
+		for _, sku := range page.Value {
+			if sku.ResourceType != nil && *sku.ResourceType == "virtualMachines" && sku.Name != nil && strings.EqualFold(*sku.Name, vmSizeName) {
+				return computeSizeFromSKU(sku)
+			}
+		}
+	}
+	return ComputeSize{}, fmt.Errorf("VM size %s not found in the resource SKUs of location %s", vmSizeName, location)
+}
+
+// computeSizeFromSKU reads the vCPUs, memory and CPU architecture capabilities of a VM
+// size SKU. Without a CpuArchitectureType capability, the architecture is derived from
+// the size name.
+func computeSizeFromSKU(sku *armcompute.ResourceSKU) (ComputeSize, error) {
+	size := ComputeSize{Name: *sku.Name, Architecture: sizeNameArchitecture(*sku.Name)}
+	capabilities := make(map[string]string)
+	for _, c := range sku.Capabilities {
+		if c != nil && c.Name != nil && c.Value != nil {
+			capabilities[*c.Name] = *c.Value
+		}
+	}
+	vcpus, err := strconv.ParseInt(capabilities["vCPUs"], 10, 32)
+	if err != nil || vcpus <= 0 {
+		return ComputeSize{}, fmt.Errorf("VM size %s has no vCPUs capability", size.Name)
+	}
+	memoryGB, err := strconv.ParseFloat(capabilities["MemoryGB"], 64)
+	if err != nil || memoryGB <= 0 {
+		return ComputeSize{}, fmt.Errorf("VM size %s has no MemoryGB capability", size.Name)
+	}
+	size.VCPUs, size.MemoryGB = int32(vcpus), int32(math.Ceil(memoryGB))
+	switch strings.ToLower(capabilities["CpuArchitectureType"]) {
+	case "arm64":
+		size.Architecture = "ARM64"
+	case "x64":
+		size.Architecture = "x86_64"
+	}
+	return size, nil
+}
@@ -0,0 +1,56 @@
+package azure
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+func TestComputeSizeFromSKU(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     ComputeSize
+		wantErr  bool
+	}{
+		{
+			name: "x64",
+			response: `{"resourceType": "virtualMachines", "name": "Standard_D4s_v5", "capabilities": [
+				{"name": "vCPUs", "value": "4"}, {"name": "MemoryGB", "value": "16"}, {"name": "CpuArchitectureType", "value": "x64"}]}`,
+			want: ComputeSize{Name: "Standard_D4s_v5", VCPUs: 4, MemoryGB: 16, Architecture: "x86_64"},
+		},
+		{
+			name: "Arm64 with fractional memory",
+			response: `{"resourceType": "virtualMachines", "name": "Standard_B2pts_v2", "capabilities": [
+				{"name": "vCPUs", "value": "2"}, {"name": "MemoryGB", "value": "0.5"}, {"name": "CpuArchitectureType", "value": "Arm64"}]}`,
+			want: ComputeSize{Name: "Standard_B2pts_v2", VCPUs: 2, MemoryGB: 1, Architecture: "ARM64"},
+		},
+		{
+			name: "architecture from the size name",
+			response: `{"resourceType": "virtualMachines", "name": "Standard_D8ps_v5", "capabilities": [
+				{"name": "vCPUs", "value": "8"}, {"name": "MemoryGB", "value": "32"}]}`,
+			want: ComputeSize{Name: "Standard_D8ps_v5", VCPUs: 8, MemoryGB: 32, Architecture: "ARM64"},
+		},
+		{
+			name:     "no vCPUs",
+			response: `{"resourceType": "virtualMachines", "name": "Standard_D4s_v5", "capabilities": [{"name": "MemoryGB", "value": "16"}]}`,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sku armcompute.ResourceSKU
+			if err := json.Unmarshal([]byte(tt.response), &sku); err != nil {
+				t.Fatal(err)
+			}
+			got, err := computeSizeFromSKU(&sku)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("computeSizeFromSKU() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("computeSizeFromSKU() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
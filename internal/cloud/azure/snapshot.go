@@ -0,0 +1,122 @@
+// Package azure provides the naming, tagging and cleanup of the snapshots used to export disks.
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+// DefaultSnapshotNameTemplate is the name of export snapshots unless configured otherwise.
+const DefaultSnapshotNameTemplate = "ss-{disk}-{timestamp}"
+
+// Tags set on the snapshots created by Kopru.
+const (
+	SnapshotCreatedByTag   = "created-by"
+	SnapshotCreatedByValue = "kopru"
+	SnapshotMigrationIDTag = "kopru-migration-id"
+	SnapshotCreatorTag     = "kopru-creator"
+)
+
+// maxSnapshotNameLen is the maximum length of an Azure snapshot name.
+const maxSnapshotNameLen = 80
+
+// SnapshotOptions sets the names and tags of the snapshots created to export disks.
+type SnapshotOptions struct {
+	NameTemplate string   // Name with {disk}, {timestamp} and {migration} placeholders
+	MigrationID  string   // Identifies the run that created the snapshot
+	Creator      string   // User or identity that started the run
+	Quiesce      *Quiesce // Quiesces running VMs for CreateConsistentSnapshots, if set
+}
+
+// Snapshot describes a snapshot in a resource group.
+type Snapshot struct {
+	Name        string
+	State       string // Disk state, ActiveSAS while a download URL is granted
+	TimeCreated time.Time
+	Tags        map[string]string
+}
+
+// AccessGranted reports whether a download URL is granted for the snapshot.
+func (s Snapshot) AccessGranted() bool {
+	return s.State == string(armcompute.DiskStateActiveSAS)
+}
+
+// ConfigureSnapshots sets the names and tags of the snapshots created by ExportAzureDisk.
+func (p *Provider) ConfigureSnapshots(opts SnapshotOptions) {
+	p.snapshotOptions = opts
+}
+
+// renderSnapshotName renders the snapshot name template for diskName. The disk name is
+// truncated so that the name fits the Azure limit of 80 characters.
+func renderSnapshotName(template, migrationID, diskName string, now time.Time) string {
+	if template == "" {
+		template = DefaultSnapshotNameTemplate
+	}
+	name := strings.NewReplacer(
+		"{timestamp}", strconv.FormatInt(now.Unix(), 36),
+		"{migration}", migrationID,
+	).Replace(template)
+	if maxDiskNameLen := maxSnapshotNameLen - len(name) + len("{disk}"); len(diskName) > maxDiskNameLen {
+		diskName = diskName[:max(maxDiskNameLen, 0)]
+	}
+	name = strings.ReplaceAll(name, "{disk}", diskName)
+	if len(name) > maxSnapshotNameLen {
+		name = name[:maxSnapshotNameLen]
+	}
+	return name
+}
+
+// snapshotTags returns the tags of the snapshots created with opts.
+func snapshotTags(opts SnapshotOptions) map[string]*string {
+	tags := map[string]*string{SnapshotCreatedByTag: to.Ptr(SnapshotCreatedByValue)}
+	if opts.MigrationID != "" {
+		tags[SnapshotMigrationIDTag] = to.Ptr(opts.MigrationID)
+	}
+	if opts.Creator != "" {
+		tags[SnapshotCreatorTag] = to.Ptr(opts.Creator)
+	}
+	return tags
+}
+
+// ListSnapshots lists the snapshots in a resource group.
+func (p *Provider) ListSnapshots(ctx context.Context, resourceGroup string) ([]Snapshot, error) {
+	clientFactory, err := armcompute.NewClientFactory(p.subscriptionID, p.credential, p.clientOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute client factory: %w", err)
+	}
+	pager := clientFactory.NewSnapshotsClient().NewListByResourceGroupPager(resourceGroup, nil)
+	var snapshots []Snapshot
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list snapshots: %w", err)
+		}
+		for _, s := range page.Value {
+			if s == nil || s.Name == nil {
+				continue
+			}
+			snapshot := Snapshot{Name: *s.Name, Tags: make(map[string]string, len(s.Tags))}
+			for k, v := range s.Tags {
+				if v != nil {
+					snapshot.Tags[k] = *v
+				}
+			}
+			if s.Properties != nil {
+				if s.Properties.DiskState != nil {
+					snapshot.State = string(*s.Properties.DiskState)
+				}
+				if s.Properties.TimeCreated != nil {
+					snapshot.TimeCreated = *s.Properties.TimeCreated
+				}
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,49 @@
+package azure
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderSnapshotName(t *testing.T) {
+	now := time.Unix(1767225600, 0)
+	longDisk := strings.Repeat("d", 100)
+	tests := []struct {
+		template, disk, want string
+	}{
+		{"", "osdisk", "ss-osdisk-t85s00"},
+		{DefaultSnapshotNameTemplate, "osdisk", "ss-osdisk-t85s00"},
+		{"kopru-{migration}-{disk}", "data1", "kopru-a1b2c3-data1"},
+		{DefaultSnapshotNameTemplate, longDisk, "ss-" + strings.Repeat("d", 70) + "-t85s00"},
+	}
+	for _, tt := range tests {
+		got := renderSnapshotName(tt.template, "a1b2c3", tt.disk, now)
+		if got != tt.want {
+			t.Errorf("renderSnapshotName(%q, %q) = %q, want %q", tt.template, tt.disk, got, tt.want)
+		}
+		if len(got) > maxSnapshotNameLen {
+			t.Errorf("renderSnapshotName(%q, %q) is %d characters long", tt.template, tt.disk, len(got))
+		}
+	}
+}
+
+func TestSnapshotTags(t *testing.T) {
+	tags := snapshotTags(SnapshotOptions{MigrationID: "a1b2c3", Creator: "azureuser@migrator"})
+	want := map[string]string{
+		SnapshotCreatedByTag:   SnapshotCreatedByValue,
+		SnapshotMigrationIDTag: "a1b2c3",
+		SnapshotCreatorTag:     "azureuser@migrator",
+	}
+	if len(tags) != len(want) {
+		t.Fatalf("snapshotTags() returned %d tags, want %d", len(tags), len(want))
+	}
+	for k, v := range want {
+		if tags[k] == nil || *tags[k] != v {
+			t.Errorf("tag %s = %v, want %q", k, tags[k], v)
+		}
+	}
+	if tags := snapshotTags(SnapshotOptions{}); len(tags) != 1 {
+		t.Errorf("snapshotTags() without migration ID and creator returned %d tags, want 1", len(tags))
+	}
+}
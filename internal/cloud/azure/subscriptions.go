@@ -0,0 +1,57 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+const (
+	subscriptionsAPI  = "2022-12-01"
+	resourceGroupsAPI = "2021-04-01"
+)
+
+// Subscription is an Azure subscription the current principal can access.
+type Subscription struct {
+	ID          string `json:"subscriptionId"`
+	DisplayName string `json:"displayName"`
+	State       string `json:"state"`
+}
+
+// ListSubscriptions lists the enabled subscriptions the current principal can access,
+// sorted by display name.
+func (p *Provider) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	var result struct {
+		Value []Subscription `json:"value"`
+	}
+	if err := p.armGet(ctx, "/subscriptions", subscriptionsAPI, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	var subscriptions []Subscription
+	for _, s := range result.Value {
+		if s.State == "Enabled" {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].DisplayName < subscriptions[j].DisplayName })
+	return subscriptions, nil
+}
+
+// ListResourceGroups lists the names of the resource groups in the provider's
+// subscription, sorted by name.
+func (p *Provider) ListResourceGroups(ctx context.Context) ([]string, error) {
+	var result struct {
+		Value []struct {
+			Name string `json:"name"`
+		} `json:"value"`
+	}
+	if err := p.armGet(ctx, fmt.Sprintf("/subscriptions/%s/resourcegroups", p.subscriptionID), resourceGroupsAPI, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to list resource groups: %w", err)
+	}
+	names := make([]string, 0, len(result.Value))
+	for _, rg := range result.Value {
+		names = append(names, rg.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
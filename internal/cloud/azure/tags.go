@@ -0,0 +1,57 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+)
+
+const disksAPI = "2023-04-02"
+
+// DiskTags are the tags of the managed disks of a Compute instance.
+type DiskTags struct {
+	OSDisk    map[string]string
+	DataDisks map[string]map[string]string // By disk name
+}
+
+// managedDisk is the managed disk resource of the Azure Compute API.
+type managedDisk struct {
+	Name       string            `json:"name"`
+	Tags       map[string]string `json:"tags"`
+	Properties struct {
+		HyperVGeneration string `json:"hyperVGeneration"`
+	} `json:"properties"`
+}
+
+// GetComputeDiskTags retrieves the tags of the managed OS and data disks of a Compute
+// instance.
+func (p *Provider) GetComputeDiskTags(ctx context.Context, resourceGroup, computeName string) (*DiskTags, error) {
+	inv, err := p.GetComputeInventory(ctx, resourceGroup, computeName)
+	if err != nil {
+		return nil, err
+	}
+	tags := &DiskTags{DataDisks: make(map[string]map[string]string)}
+	if inv.OSDiskID != "" {
+		disk, err := p.getManagedDisk(ctx, inv.OSDiskID)
+		if err != nil {
+			return nil, err
+		}
+		tags.OSDisk = disk.Tags
+	}
+	for _, id := range inv.DataDiskIDs {
+		disk, err := p.getManagedDisk(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		tags.DataDisks[disk.Name] = disk.Tags
+	}
+	return tags, nil
+}
+
+// getManagedDisk retrieves the managed disk with the given resource ID.
+func (p *Provider) getManagedDisk(ctx context.Context, id string) (*managedDisk, error) {
+	var disk managedDisk
+	if err := p.armGet(ctx, id, disksAPI, nil, &disk); err != nil {
+		return nil, fmt.Errorf("failed to get disk %s: %w", id, err)
+	}
+	return &disk, nil
+}
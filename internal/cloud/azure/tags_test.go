@@ -0,0 +1,17 @@
+package azure
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestManagedDiskTags(t *testing.T) {
+	const response = `{"name": "web-data-0", "location": "eastus", "tags": {"env": "prod", "backup": "daily"}, "properties": {"diskSizeGB": 128}}`
+	var disk managedDisk
+	if err := json.Unmarshal([]byte(response), &disk); err != nil {
+		t.Fatal(err)
+	}
+	if disk.Name != "web-data-0" || len(disk.Tags) != 2 || disk.Tags["backup"] != "daily" {
+		t.Errorf("managedDisk = %+v", disk)
+	}
+}
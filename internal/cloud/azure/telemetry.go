@@ -0,0 +1,115 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// attemptCounter counts the attempts of a single Azure API call across retries.
+type attemptCounter struct {
+	n int
+}
+
+// tracingPolicy traces each Azure Resource Manager call as a span and records
+// the retries the SDK made for it.
+type tracingPolicy struct{}
+
+func (tracingPolicy) Do(req *policy.Request) (*http.Response, error) {
+	raw := req.Raw()
+	ctx, span := telemetry.StartSpan(raw.Context(), fmt.Sprintf("azure %s", raw.Method),
+		attribute.String("http.request.method", raw.Method),
+		attribute.String("server.address", raw.URL.Host),
+		attribute.String("url.path", raw.URL.Path),
+	)
+	telemetry.RecordOperation(telemetry.CloudAzure, operationName(raw.Method, raw.URL.Host, raw.URL.Path))
+	attempts := &attemptCounter{}
+	req = req.WithContext(ctx)
+	req.SetOperationValue(attempts)
+	resp, err := req.Next()
+	spanErr := err
+	if resp != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if err == nil && resp.StatusCode >= http.StatusBadRequest {
+			spanErr = errors.New(resp.Status)
+		}
+	}
+	if attempts.n > 1 {
+		span.SetAttributes(attribute.Int("retries", attempts.n-1))
+		telemetry.AddRetries(ctx, "azure", int64(attempts.n-1))
+	}
+	telemetry.EndSpan(span, spanErr)
+	return resp, err
+}
+
+// operationName returns the Azure RBAC operation a request needs, as used in custom
+// role definitions, e.g. Microsoft.Compute/disks/beginGetAccess/action. Resource types
+// are the even segments of the path after the last providers segment; a POST to a
+// resource names an action. Key Vault secret reads are data actions.
+func operationName(method, host, path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if strings.Contains(host, ".vault.") && len(segments) > 0 && segments[0] == "secrets" {
+		return "Microsoft.KeyVault/vaults/secrets/getSecret/action"
+	}
+	namespace := "Microsoft.Resources"
+	for i := len(segments) - 2; i >= 0; i-- {
+		if strings.EqualFold(segments[i], "providers") {
+			namespace, segments = segments[i+1], segments[i+2:]
+			break
+		}
+	}
+	action := ""
+	if method == http.MethodPost && len(segments)%2 == 1 && len(segments) > 1 {
+		action, segments = segments[len(segments)-1], segments[:len(segments)-1]
+	}
+	parts := []string{namespace}
+	for i := 0; i < len(segments); i += 2 {
+		if strings.EqualFold(segments[i], "resourceGroups") {
+			segments[i] = "resourceGroups"
+		}
+		parts = append(parts, segments[i])
+	}
+	switch {
+	case action != "":
+		parts = append(parts, action, "action")
+	case method == http.MethodGet || method == http.MethodHead:
+		parts = append(parts, "read")
+	case method == http.MethodPut || method == http.MethodPatch:
+		parts = append(parts, "write")
+	case method == http.MethodDelete:
+		parts = append(parts, "delete")
+	default:
+		parts = append(parts, "action")
+	}
+	return strings.Join(parts, "/")
+}
+
+// attemptPolicy runs once per attempt and increments the counter set by tracingPolicy.
+type attemptPolicy struct{}
+
+func (attemptPolicy) Do(req *policy.Request) (*http.Response, error) {
+	var attempts *attemptCounter
+	if req.OperationValue(&attempts) && attempts != nil {
+		attempts.n++
+	}
+	return req.Next()
+}
+
+// clientOptions returns the ARM client options shared by all Azure clients of the provider.
+// The SDK's retries are replaced by retryPolicy, which applies the shared retry budget.
+func (p *Provider) clientOptions() *arm.ClientOptions {
+	return &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			PerCallPolicies:  []policy.Policy{tracingPolicy{}, retryPolicy{logger: p.logger}},
+			PerRetryPolicies: []policy.Policy{attemptPolicy{}},
+			Retry:            policy.RetryOptions{MaxRetries: -1},
+			Transport:        p.transport,
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package azure
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestOperationName(t *testing.T) {
+	const rg = "/subscriptions/sub/resourceGroups/rg"
+	tests := []struct {
+		method string
+		host   string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "management.azure.com", rg + "/providers/Microsoft.Compute/virtualMachines/vm", "Microsoft.Compute/virtualMachines/read"},
+		{http.MethodGet, "management.azure.com", rg + "/providers/Microsoft.Compute/virtualMachines/vm/instanceView", "Microsoft.Compute/virtualMachines/instanceView/read"},
+		{http.MethodPut, "management.azure.com", rg + "/providers/Microsoft.Compute/snapshots/snap", "Microsoft.Compute/snapshots/write"},
+		{http.MethodPost, "management.azure.com", rg + "/providers/Microsoft.Compute/snapshots/snap/beginGetAccess", "Microsoft.Compute/snapshots/beginGetAccess/action"},
+		{http.MethodPost, "management.azure.com", rg + "/providers/Microsoft.Compute/virtualMachines/vm/runCommand", "Microsoft.Compute/virtualMachines/runCommand/action"},
+		{http.MethodDelete, "management.azure.com", rg + "/providers/Microsoft.Compute/snapshots/snap", "Microsoft.Compute/snapshots/delete"},
+		{http.MethodGet, "management.azure.com", "/subscriptions/sub/providers/Microsoft.Compute/locations/eastus/operations/op", "Microsoft.Compute/locations/operations/read"},
+		{http.MethodGet, "management.azure.com", rg + "/providers/Microsoft.Authorization/permissions", "Microsoft.Authorization/permissions/read"},
+		{http.MethodGet, "management.azure.com", "/subscriptions/sub/resourcegroups/rg", "Microsoft.Resources/subscriptions/resourceGroups/read"},
+		{http.MethodGet, "myvault.vault.azure.net", "/secrets/db-password", "Microsoft.KeyVault/vaults/secrets/getSecret/action"},
+	}
+	for _, tt := range tests {
+		if got := operationName(tt.method, tt.host, tt.path); got != tt.want {
+			t.Errorf("operationName(%s, %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// CompartmentByPath returns the OCID of the compartment at path below the root
+// compartment rootID, e.g. prod/app-team for the app-team compartment of the prod
+// compartment. Names are compared without regard to case, as OCI does when it checks
+// that compartment names are unique within their parent. An empty path, or "/", is
+// the root compartment.
+func CompartmentByPath(compartments []identity.Compartment, rootID, path string) (string, error) {
+	current := rootID
+	var walked []string
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		if name == "" {
+			continue
+		}
+		var children []string
+		next := ""
+		for _, c := range compartments {
+			if c.CompartmentId == nil || *c.CompartmentId != current || c.Name == nil || c.Id == nil {
+				continue
+			}
+			children = append(children, *c.Name)
+			if strings.EqualFold(*c.Name, name) {
+				next = *c.Id
+			}
+		}
+		if next == "" {
+			parent := "the root compartment"
+			if len(walked) > 0 {
+				parent = "'" + strings.Join(walked, "/") + "'"
+			}
+			if len(children) == 0 {
+				return "", fmt.Errorf("no accessible compartment '%s' in %s, which has no accessible compartments", name, parent)
+			}
+			sort.Strings(children)
+			return "", fmt.Errorf("no accessible compartment '%s' in %s (compartments: %s)", name, parent, strings.Join(children, ", "))
+		}
+		current = next
+		walked = append(walked, name)
+	}
+	return current, nil
+}
+
+// ResolveCompartmentPath returns the OCID of the compartment at path below the root
+// compartment of the tenancy, e.g. prod/app-team.
+func (p *Provider) ResolveCompartmentPath(ctx context.Context, path string) (string, error) {
+	compartments, err := p.ListCompartments(ctx)
+	if err != nil {
+		return "", err
+	}
+	tenancyID, err := p.configProvider.TenancyOCID()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenancy OCID: %w", err)
+	}
+	return CompartmentByPath(compartments, tenancyID, path)
+}
@@ -0,0 +1,48 @@
+package oci
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+func TestCompartmentByPath(t *testing.T) {
+	const root = "ocid1.tenancy.oc1..root"
+	compartment := func(id, parent, name string) identity.Compartment {
+		return identity.Compartment{Id: common.String(id), CompartmentId: common.String(parent), Name: common.String(name)}
+	}
+	compartments := []identity.Compartment{
+		compartment(root, "", "acme"),
+		compartment("ocid1.compartment.oc1..prod", root, "prod"),
+		compartment("ocid1.compartment.oc1..dev", root, "dev"),
+		compartment("ocid1.compartment.oc1..prodapp", "ocid1.compartment.oc1..prod", "app-team"),
+		compartment("ocid1.compartment.oc1..devapp", "ocid1.compartment.oc1..dev", "app-team"),
+	}
+	tests := []struct {
+		path    string
+		want    string
+		wantErr string
+	}{
+		{"prod/app-team", "ocid1.compartment.oc1..prodapp", ""},
+		{"/Dev/App-Team/", "ocid1.compartment.oc1..devapp", ""},
+		{"prod", "ocid1.compartment.oc1..prod", ""},
+		{"/", root, ""},
+		{"prod/db-team", "", "no accessible compartment 'db-team' in 'prod' (compartments: app-team)"},
+		{"staging", "", "no accessible compartment 'staging' in the root compartment (compartments: dev, prod)"},
+		{"prod/app-team/web", "", "in 'prod/app-team', which has no accessible compartments"},
+	}
+	for _, tt := range tests {
+		got, err := CompartmentByPath(compartments, root, tt.path)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CompartmentByPath(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("CompartmentByPath(%q) = %q, %v, want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/limits"
+)
+
+// Service limit names Kopru consumes during a migration.
+const (
+	LimitServiceCompute       = "compute"
+	LimitServiceBlockStorage  = "block-storage"
+	LimitServiceObjectStorage = "object-storage"
+
+	LimitCustomImageCount = "custom-image-count"
+	LimitTotalStorageGB   = "total-storage-gb"
+	LimitStorageBytes     = "storage-bytes"
+)
+
+// LimitRequest describes an amount of a service limit a migration will consume.
+// AvailabilityDomain is the full name of the domain for AD-scoped limits.
+type LimitRequest struct {
+	Service            string
+	Limit              string
+	AvailabilityDomain string
+	Required           int64
+	Description        string
+}
+
+// LimitShortfall describes a service limit or compartment quota with too little
+// capacity left for a migration.
+type LimitShortfall struct {
+	LimitRequest
+	Used      int64
+	Available int64
+}
+
+func (s LimitShortfall) String() string {
+	msg := fmt.Sprintf("%s: %d required, %d available (%d used) for %s/%s", s.Description, s.Required, s.Available, s.Used, s.Service, s.Limit)
+	if s.AvailabilityDomain != "" {
+		msg += " in " + s.AvailabilityDomain
+	}
+	return msg
+}
+
+// CheckLimits queries the resource availability of each request in the compartment,
+// which accounts for both tenancy service limits and compartment quotas, and returns
+// those that cannot be satisfied. Limits the region does not define are skipped, as
+// are limits whose availability cannot be read; neither should block a migration.
+func (p *Provider) CheckLimits(ctx context.Context, compartmentID string, requests []LimitRequest) ([]LimitShortfall, error) {
+	client, err := limits.NewLimitsClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create limits client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	var shortfalls []LimitShortfall
+	for _, r := range requests {
+		req := limits.GetResourceAvailabilityRequest{
+			ServiceName:   common.String(r.Service),
+			LimitName:     common.String(r.Limit),
+			CompartmentId: &compartmentID,
+		}
+		if r.AvailabilityDomain != "" {
+			req.AvailabilityDomain = common.String(r.AvailabilityDomain)
+		}
+		resp, err := client.GetResourceAvailability(ctx, req)
+		if err != nil {
+			if serviceErr, ok := common.IsServiceError(err); ok && serviceErr.GetHTTPStatusCode() == http.StatusNotFound {
+				p.logger.Debugf("Limit %s/%s is not defined, skipping", r.Service, r.Limit)
+			} else {
+				p.logger.Warningf("Could not read availability of limit %s/%s: %v", r.Service, r.Limit, err)
+			}
+			continue
+		}
+		if resp.Available == nil || *resp.Available >= r.Required {
+			continue
+		}
+		var used int64
+		if resp.Used != nil {
+			used = *resp.Used
+		}
+		shortfalls = append(shortfalls, LimitShortfall{LimitRequest: r, Used: used, Available: *resp.Available})
+	}
+	return shortfalls, nil
+}
+
+// ShapeLimitPrefix returns the prefix of the compute limit names of a shape,
+// for example "standard-e5" for VM.Standard.E5.Flex, whose cores are counted
+// by the standard-e5-core-count limit.
+func ShapeLimitPrefix(shape string) string {
+	parts := strings.Split(strings.ToLower(shape), ".")
+	if len(parts) > 0 && (parts[0] == "vm" || parts[0] == "bm") {
+		parts = parts[1:]
+	}
+	if n := len(parts); n > 1 {
+		if last := parts[n-1]; last == "flex" || strings.Trim(last, "0123456789") == "" {
+			parts = parts[:n-1]
+		}
+	}
+	return strings.Join(parts, "-")
+}
@@ -0,0 +1,33 @@
+package oci
+
+import "testing"
+
+func TestShapeLimitPrefix(t *testing.T) {
+	tests := []struct {
+		shape string
+		want  string
+	}{
+		{"VM.Standard.E5.Flex", "standard-e5"},
+		{"VM.Standard.A1.Flex", "standard-a1"},
+		{"VM.Standard2.4", "standard2"},
+		{"BM.Standard3.64", "standard3"},
+		{"VM.DenseIO.E4.Flex", "denseio-e4"},
+	}
+	for _, tt := range tests {
+		if got := ShapeLimitPrefix(tt.shape); got != tt.want {
+			t.Errorf("ShapeLimitPrefix(%q) = %q, want %q", tt.shape, got, tt.want)
+		}
+	}
+}
+
+func TestLimitShortfallString(t *testing.T) {
+	s := LimitShortfall{
+		LimitRequest: LimitRequest{Service: "compute", Limit: "standard-e5-core-count", AvailabilityDomain: "Uocm:PHX-AD-1", Required: 4, Description: "VM.Standard.E5.Flex cores"},
+		Used:         10,
+		Available:    2,
+	}
+	want := "VM.Standard.E5.Flex cores: 4 required, 2 available (10 used) for compute/standard-e5-core-count in Uocm:PHX-AD-1"
+	if got := s.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
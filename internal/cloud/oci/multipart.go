@@ -0,0 +1,112 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" // #nosec G501 -- Object Storage verifies parts with Content-MD5
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+)
+
+// MultipartUpload is a multipart upload of an object whose parts are uploaded one by
+// one, in any order, and become the object when the upload is committed.
+type MultipartUpload struct {
+	p         *Provider
+	client    objectstorage.ObjectStorageClient
+	namespace string
+	bucket    string
+	object    string
+	id        string
+}
+
+// CreateMultipartUpload starts a multipart upload of objectName.
+func (p *Provider) CreateMultipartUpload(ctx context.Context, namespace, bucketName, objectName string) (*MultipartUpload, error) {
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object storage client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+
+	resp, err := client.CreateMultipartUpload(ctx, objectstorage.CreateMultipartUploadRequest{
+		NamespaceName:                &namespace,
+		BucketName:                   &bucketName,
+		CreateMultipartUploadDetails: objectstorage.CreateMultipartUploadDetails{Object: &objectName},
+		RequestMetadata:              common.RequestMetadata{RetryPolicy: p.retryPolicy()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create multipart upload of %s: %w", objectName, err)
+	}
+	return &MultipartUpload{
+		p: p, client: client, namespace: namespace, bucket: bucketName, object: objectName,
+		id: *resp.UploadId,
+	}, nil
+}
+
+// UploadPart uploads data as part number of the upload, replacing a part uploaded
+// before with the same number, and returns its ETag. Part numbers start at 1.
+func (u *MultipartUpload) UploadPart(ctx context.Context, number int, data []byte) (string, error) {
+	sum := md5.Sum(data) // #nosec G401
+	contentMD5 := base64.StdEncoding.EncodeToString(sum[:])
+	resp, err := u.client.UploadPart(ctx, objectstorage.UploadPartRequest{
+		NamespaceName:   &u.namespace,
+		BucketName:      &u.bucket,
+		ObjectName:      &u.object,
+		UploadId:        &u.id,
+		UploadPartNum:   &number,
+		ContentLength:   common.Int64(int64(len(data))),
+		UploadPartBody:  io.NopCloser(bytes.NewReader(data)),
+		ContentMD5:      &contentMD5,
+		RequestMetadata: common.RequestMetadata{RetryPolicy: u.p.retryPolicy()},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part %d of %s: %w", number, u.object, err)
+	}
+	telemetry.AddUploadedBytes(ctx, int64(len(data)))
+	return *resp.ETag, nil
+}
+
+// Commit assembles the object from the parts with the given ETags, in order from part
+// 1, discards the uploaded parts numbered in exclude, and returns the multipart MD5 of
+// the object followed by "-<parts>".
+func (u *MultipartUpload) Commit(ctx context.Context, etags []string, exclude []int) (string, error) {
+	parts := make([]objectstorage.CommitMultipartUploadPartDetails, len(etags))
+	for i := range etags {
+		parts[i] = objectstorage.CommitMultipartUploadPartDetails{PartNum: common.Int(i + 1), Etag: &etags[i]}
+	}
+	resp, err := u.client.CommitMultipartUpload(ctx, objectstorage.CommitMultipartUploadRequest{
+		NamespaceName:                &u.namespace,
+		BucketName:                   &u.bucket,
+		ObjectName:                   &u.object,
+		UploadId:                     &u.id,
+		CommitMultipartUploadDetails: objectstorage.CommitMultipartUploadDetails{PartsToCommit: parts, PartsToExclude: exclude},
+		RequestMetadata:              common.RequestMetadata{RetryPolicy: u.p.retryPolicy()},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to commit multipart upload of %s: %w", u.object, err)
+	}
+	u.p.logger.Successf("Uploaded %s to bucket %s", u.object, u.bucket)
+	if resp.OpcMultipartMd5 == nil {
+		return "", nil
+	}
+	return *resp.OpcMultipartMd5, nil
+}
+
+// Abort discards the upload and the parts uploaded so far.
+func (u *MultipartUpload) Abort(ctx context.Context) error {
+	_, err := u.client.AbortMultipartUpload(ctx, objectstorage.AbortMultipartUploadRequest{
+		NamespaceName:   &u.namespace,
+		BucketName:      &u.bucket,
+		ObjectName:      &u.object,
+		UploadId:        &u.id,
+		RequestMetadata: common.RequestMetadata{RetryPolicy: u.p.retryPolicy()},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to abort multipart upload of %s: %w", u.object, err)
+	}
+	return nil
+}
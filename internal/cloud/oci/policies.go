@@ -0,0 +1,108 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+)
+
+// IAM resource types Kopru needs to manage in the target compartment.
+const (
+	PolicyObjectFamily   = "object-family"
+	PolicyInstanceImages = "instance-images"
+	PolicyVolumeFamily   = "volume-family"
+	PolicyInstanceFamily = "instance-family"
+)
+
+// MissingPolicy describes an IAM permission the current principal lacks in the
+// target compartment, with a policy statement that grants it.
+type MissingPolicy struct {
+	Resource  string
+	Statement string
+	Err       error
+}
+
+// CheckPolicies probes whether the current principal is authorized for the given
+// IAM resource types in the compartment by issuing a read-only list request for each.
+// OCI answers unauthorized requests with 404 NotAuthorizedOrNotFound, which is only
+// conclusive once the compartment is known to exist; callers should therefore verify
+// the compartment first. Errors other than authorization failures are returned as is.
+func (p *Provider) CheckPolicies(ctx context.Context, compartmentID, namespace string, resources []string) ([]MissingPolicy, error) {
+	var missing []MissingPolicy
+	for _, resource := range resources {
+		err := p.probePolicy(ctx, compartmentID, namespace, resource)
+		if err == nil {
+			continue
+		}
+		if !isAuthorizationError(err) {
+			return nil, fmt.Errorf("failed to check %s policy: %w", resource, err)
+		}
+		missing = append(missing, MissingPolicy{
+			Resource:  resource,
+			Statement: PolicyStatement(resource, compartmentID),
+			Err:       err,
+		})
+	}
+	return missing, nil
+}
+
+// PolicyStatement returns a policy statement granting manage access to resource in the compartment.
+func PolicyStatement(resource, compartmentID string) string {
+	return fmt.Sprintf("Allow group <group-name> to manage %s in compartment id %s", resource, compartmentID)
+}
+
+func (p *Provider) probePolicy(ctx context.Context, compartmentID, namespace, resource string) error {
+	limit := 1
+	switch resource {
+	case PolicyObjectFamily:
+		client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(p.configProvider)
+		if err != nil {
+			return fmt.Errorf("failed to create object storage client: %w", err)
+		}
+		p.instrument(&client.BaseClient)
+		_, err = client.ListBuckets(ctx, objectstorage.ListBucketsRequest{NamespaceName: &namespace, CompartmentId: &compartmentID, Limit: &limit})
+		return err
+	case PolicyInstanceImages, PolicyInstanceFamily:
+		client, err := core.NewComputeClientWithConfigurationProvider(p.configProvider)
+		if err != nil {
+			return fmt.Errorf("failed to create compute client: %w", err)
+		}
+		p.instrument(&client.BaseClient)
+		if resource == PolicyInstanceImages {
+			_, err = client.ListImages(ctx, core.ListImagesRequest{CompartmentId: &compartmentID, Limit: &limit})
+		} else {
+			_, err = client.ListInstances(ctx, core.ListInstancesRequest{CompartmentId: &compartmentID, Limit: &limit})
+		}
+		return err
+	case PolicyVolumeFamily:
+		client, err := core.NewBlockstorageClientWithConfigurationProvider(p.configProvider)
+		if err != nil {
+			return fmt.Errorf("failed to create block storage client: %w", err)
+		}
+		p.instrument(&client.BaseClient)
+		_, err = client.ListVolumes(ctx, core.ListVolumesRequest{CompartmentId: &compartmentID, Limit: &limit})
+		return err
+	default:
+		return fmt.Errorf("unsupported policy resource type '%s'", resource)
+	}
+}
+
+// isAuthorizationError reports whether err is an OCI service error caused by missing
+// IAM policies (401, 403 or 404 NotAuthorizedOrNotFound).
+func isAuthorizationError(err error) bool {
+	serviceErr, ok := common.IsServiceError(err)
+	if !ok {
+		return false
+	}
+	switch serviceErr.GetHTTPStatusCode() {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	case http.StatusNotFound:
+		return serviceErr.GetCode() == "NotAuthorizedOrNotFound"
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package oci
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fakeServiceError struct {
+	status int
+	code   string
+}
+
+func (e fakeServiceError) Error() string           { return e.code }
+func (e fakeServiceError) GetHTTPStatusCode() int  { return e.status }
+func (e fakeServiceError) GetMessage() string      { return e.code }
+func (e fakeServiceError) GetCode() string         { return e.code }
+func (e fakeServiceError) GetOpcRequestID() string { return "" }
+
+func TestIsAuthorizationError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"not authorized or not found", fakeServiceError{404, "NotAuthorizedOrNotFound"}, true},
+		{"forbidden", fakeServiceError{403, "NotAuthorized"}, true},
+		{"unauthenticated", fakeServiceError{401, "NotAuthenticated"}, true},
+		{"bucket not found", fakeServiceError{404, "BucketNotFound"}, false},
+		{"server error", fakeServiceError{500, "InternalServerError"}, false},
+		{"client error", errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAuthorizationError(tt.err); got != tt.want {
+				t.Errorf("isAuthorizationError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyStatement(t *testing.T) {
+	got := PolicyStatement(PolicyVolumeFamily, "ocid1.compartment.oc1..example")
+	if !strings.HasPrefix(got, "Allow group <group-name> to manage volume-family in compartment id ocid1.compartment.oc1..example") {
+		t.Errorf("Unexpected policy statement: %s", got)
+	}
+}
+
+func TestParsePolicyStatement(t *testing.T) {
+	tests := []struct {
+		statement string
+		ok        bool
+		want      policyGrant
+	}{
+		{
+			"Allow group Migrators to manage instance-family in compartment Apps:Prod",
+			true,
+			policyGrant{groups: []string{"migrators"}, verb: "manage", resource: "instance-family", location: "apps:prod"},
+		},
+		{
+			"allow group 'Default'/'Administrators', id ocid1.group.oc1..aaa to MANAGE all-resources in tenancy",
+			true,
+			policyGrant{groups: []string{"administrators", "ocid1.group.oc1..aaa"}, verb: "manage", resource: "all-resources", location: "tenancy"},
+		},
+		{
+			"Allow any-user to read object-family in compartment id ocid1.compartment.oc1..bbb where request.user.id = 'x'",
+			true,
+			policyGrant{anyUser: true, verb: "read", resource: "object-family", location: "ocid1.compartment.oc1..bbb", conditional: true},
+		},
+		{"Allow dynamic-group Instances to manage object-family in tenancy", false, policyGrant{}},
+		{"Endorse group Migrators to manage objects in tenancy Other", false, policyGrant{}},
+	}
+	for _, tt := range tests {
+		got, ok := parsePolicyStatement(tt.statement)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePolicyStatement(%q) = %+v, %v, want %+v, %v", tt.statement, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPolicyGrantGrants(t *testing.T) {
+	chain := []compartmentRef{{"ocid1.compartment.oc1..prod", "Prod"}, {"ocid1.compartment.oc1..apps", "Apps"}, {"ocid1.tenancy.oc1..root", "root"}}
+	groups := map[string]bool{"migrators": true, "ocid1.group.oc1..migrators": true}
+	tests := []struct {
+		statement string
+		want      bool
+	}{
+		{"Allow group Migrators to manage instance-family in compartment Apps", true},
+		{"Allow group Migrators to manage instance-family in compartment Apps:Prod", true},
+		{"Allow group id ocid1.group.oc1..migrators to manage all-resources in compartment id ocid1.compartment.oc1..apps", true},
+		{"Allow group Migrators to manage instance-family in tenancy", true},
+		{"Allow group Migrators to use instance-family in compartment Prod", false},
+		{"Allow group Migrators to manage volume-family in compartment Prod", false},
+		{"Allow group Operators to manage instance-family in compartment Prod", false},
+		{"Allow group Migrators to manage instance-family in compartment Dev", false},
+	}
+	for _, tt := range tests {
+		g, ok := parsePolicyStatement(tt.statement)
+		if !ok {
+			t.Fatalf("parsePolicyStatement(%q) failed", tt.statement)
+		}
+		if got := g.grants(groups, "manage", PolicyInstanceFamily, chain); got != tt.want {
+			t.Errorf("%q grants manage instance-family = %v, want %v", tt.statement, got, tt.want)
+		}
+	}
+}
@@ -4,11 +4,16 @@ package oci
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
 	"github.com/oracle/oci-go-sdk/v65/common"
 	"github.com/oracle/oci-go-sdk/v65/core"
 	"github.com/oracle/oci-go-sdk/v65/identity"
@@ -21,16 +26,43 @@ type Provider struct {
 	configProvider common.ConfigurationProvider
 	region         string
 	logger         *logger.Logger
+	session        *sessionProfile
 }
 
 // NewProvider creates a new OCI provider instance.
+// When the OCI CLI profile in use authenticates with a session token
+// (`oci session authenticate`), the token is used and can be refreshed during the run.
+// Clients connect to the given region rather than the region of the OCI CLI profile;
+// endpoints are resolved by the SDK, including the realm domain of government and
+// dedicated regions.
 func NewProvider(region string, log *logger.Logger) (*Provider, error) {
-	configProvider := common.DefaultConfigProvider()
-	return &Provider{
-		configProvider: configProvider,
-		region:         region,
-		logger:         log,
-	}, nil
+	p := &Provider{region: region, logger: log}
+	configFile, profile := configFileAndProfile()
+	if p.session = detectSessionProfile(configFile, profile); p.session != nil {
+		log.Infof("Using OCI session token authentication (profile %s)", profile)
+		p.configProvider = withRegion(p.session.configProvider(), region)
+		return p, nil
+	}
+	p.configProvider = withRegion(common.DefaultConfigProvider(), region)
+	return p, nil
+}
+
+// regionConfigProvider overrides the region of a configuration provider.
+type regionConfigProvider struct {
+	common.ConfigurationProvider
+	region string
+}
+
+func (r regionConfigProvider) Region() (string, error) {
+	return r.region, nil
+}
+
+// withRegion returns provider with its region replaced by region, if set.
+func withRegion(provider common.ConfigurationProvider, region string) common.ConfigurationProvider {
+	if region == "" {
+		return provider
+	}
+	return regionConfigProvider{ConfigurationProvider: provider, region: region}
 }
 
 // GetNamespace retrieves the Object Storage namespace for the tenancy.
@@ -39,6 +71,7 @@ func (p *Provider) GetNamespace(ctx context.Context) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to create object storage client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	req := objectstorage.GetNamespaceRequest{}
 	resp, err := client.GetNamespace(ctx, req)
 	if err != nil {
@@ -53,6 +86,7 @@ func (p *Provider) CheckBucketExists(ctx context.Context, namespace, bucketName
 	if err != nil {
 		return false, fmt.Errorf("failed to create object storage client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	req := objectstorage.HeadBucketRequest{
 		NamespaceName: &namespace,
 		BucketName:    &bucketName,
@@ -73,6 +107,7 @@ func (p *Provider) CreateBucket(ctx context.Context, namespace, compartmentID, b
 	if err != nil {
 		return fmt.Errorf("failed to create object storage client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	req := objectstorage.CreateBucketRequest{
 		NamespaceName: &namespace,
 		CreateBucketDetails: objectstorage.CreateBucketDetails{
@@ -94,6 +129,7 @@ func (p *Provider) CheckCompartmentExists(ctx context.Context, compartmentID str
 	if err != nil {
 		return fmt.Errorf("failed to create identity client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	req := identity.GetCompartmentRequest{
 		CompartmentId: &compartmentID,
 	}
@@ -110,6 +146,7 @@ func (p *Provider) CheckSubnetExists(ctx context.Context, subnetID string) error
 	if err != nil {
 		return fmt.Errorf("failed to create virtual network client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	req := core.GetSubnetRequest{
 		SubnetId: &subnetID,
 	}
@@ -120,12 +157,87 @@ func (p *Provider) CheckSubnetExists(ctx context.Context, subnetID string) error
 	return nil
 }
 
+// ListCompartments lists the active compartments accessible in the tenancy, including the root compartment.
+func (p *Provider) ListCompartments(ctx context.Context) ([]identity.Compartment, error) {
+	client, err := identity.NewIdentityClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	tenancyID, err := p.configProvider.TenancyOCID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenancy OCID: %w", err)
+	}
+	root, err := client.GetCompartment(ctx, identity.GetCompartmentRequest{CompartmentId: &tenancyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get root compartment: %w", err)
+	}
+	compartments := []identity.Compartment{root.Compartment}
+	req := identity.ListCompartmentsRequest{
+		CompartmentId:          &tenancyID,
+		CompartmentIdInSubtree: common.Bool(true),
+		AccessLevel:            identity.ListCompartmentsAccessLevelAccessible,
+		LifecycleState:         identity.CompartmentLifecycleStateActive,
+	}
+	for {
+		resp, err := client.ListCompartments(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list compartments: %w", err)
+		}
+		compartments = append(compartments, resp.Items...)
+		if resp.OpcNextPage == nil {
+			return compartments, nil
+		}
+		req.Page = resp.OpcNextPage
+	}
+}
+
+// ListSubnets lists the available subnets in a compartment.
+func (p *Provider) ListSubnets(ctx context.Context, compartmentID string) ([]core.Subnet, error) {
+	client, err := core.NewVirtualNetworkClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create virtual network client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	req := core.ListSubnetsRequest{
+		CompartmentId:  &compartmentID,
+		LifecycleState: core.SubnetLifecycleStateAvailable,
+	}
+	var subnets []core.Subnet
+	for {
+		resp, err := client.ListSubnets(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list subnets: %w", err)
+		}
+		subnets = append(subnets, resp.Items...)
+		if resp.OpcNextPage == nil {
+			return subnets, nil
+		}
+		req.Page = resp.OpcNextPage
+	}
+}
+
+// ListAvailabilityDomains lists the availability domains of the region, in order.
+func (p *Provider) ListAvailabilityDomains(ctx context.Context, compartmentID string) ([]identity.AvailabilityDomain, error) {
+	client, err := identity.NewIdentityClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	resp, err := client.ListAvailabilityDomains(ctx, identity.ListAvailabilityDomainsRequest{CompartmentId: &compartmentID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list availability domains: %w", err)
+	}
+	return resp.Items, nil
+}
+
 // GetLocalAvailabilityDomain retrieves the availability domain of the local instance.
 func (p *Provider) GetLocalAvailabilityDomain(ctx context.Context, instanceID string) (string, error) {
 	client, err := core.NewComputeClientWithConfigurationProvider(p.configProvider)
 	if err != nil {
 		return "", fmt.Errorf("failed to create compute client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	req := core.GetInstanceRequest{
 		InstanceId: &instanceID,
 	}
@@ -139,12 +251,25 @@ func (p *Provider) GetLocalAvailabilityDomain(ctx context.Context, instanceID st
 	return *resp.AvailabilityDomain, nil
 }
 
-// UploadToObjectStorage uploads a file to OCI Object Storage.
-func (p *Provider) UploadToObjectStorage(ctx context.Context, namespace, bucketName, objectName, filePath string) error {
+// UploadPartSize is the part size of multipart uploads to Object Storage.
+const UploadPartSize = 128 * 1024 * 1024
+
+// UploadToObjectStorage uploads a file to OCI Object Storage in parts of UploadPartSize
+// and returns the MD5 of the object computed by Object Storage: the base64 MD5 of a
+// single-part object, or the multipart MD5 followed by "-<parts>".
+func (p *Provider) UploadToObjectStorage(ctx context.Context, namespace, bucketName, objectName, filePath string) (string, error) {
 	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(p.configProvider)
 	if err != nil {
-		return fmt.Errorf("failed to create object storage client: %w", err)
+		return "", fmt.Errorf("failed to create object storage client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+
+	var total int64
+	if info, err := os.Stat(filePath); err == nil {
+		total = info.Size()
 	}
+	rep := progress.New(p.logger, "Uploading "+objectName, total).Record(progress.PhaseUpload, objectName)
+	defer rep.Done()
 
 	uploadManager := transfer.NewUploadManager()
 
@@ -154,16 +279,72 @@ func (p *Provider) UploadToObjectStorage(ctx context.Context, namespace, bucketN
 			BucketName:          &bucketName,
 			ObjectName:          &objectName,
 			ObjectStorageClient: &client,
+			CallBack: func(part transfer.MultiPartUploadPart) {
+				if part.Err == nil {
+					rep.Add(part.Size)
+					telemetry.AddUploadedBytes(ctx, part.Size)
+				}
+			},
 		},
 		FilePath: filePath,
 	}
+	req.PartSize = common.Int64(UploadPartSize)
+	req.RequestMetadata = common.RequestMetadata{RetryPolicy: p.retryPolicy()}
 
-	_, err = uploadManager.UploadFile(ctx, req)
+	resp, err := uploadManager.UploadFile(ctx, req)
 	if err != nil {
-		return fmt.Errorf("failed to upload object: %w", err)
+		return "", fmt.Errorf("failed to upload object: %w", err)
 	}
 	p.logger.Successf("Uploaded %s to bucket %s", objectName, bucketName)
-	return nil
+	var md5 *string
+	switch {
+	case resp.SinglepartUploadResponse != nil:
+		md5 = resp.SinglepartUploadResponse.OpcContentMd5
+	case resp.MultipartUploadResponse != nil:
+		md5 = resp.MultipartUploadResponse.OpcMultipartMd5
+	}
+	if md5 == nil {
+		return "", nil
+	}
+	return *md5, nil
+}
+
+// GetInstanceIPs returns the private and public IP addresses of the VNICs attached to an instance.
+func (p *Provider) GetInstanceIPs(ctx context.Context, compartmentID, instanceID string) (privateIPs, publicIPs []string, err error) {
+	computeClient, err := core.NewComputeClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create compute client: %w", err)
+	}
+	p.instrument(&computeClient.BaseClient)
+	networkClient, err := core.NewVirtualNetworkClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create virtual network client: %w", err)
+	}
+	p.instrument(&networkClient.BaseClient)
+
+	resp, err := computeClient.ListVnicAttachments(ctx, core.ListVnicAttachmentsRequest{
+		CompartmentId: &compartmentID,
+		InstanceId:    &instanceID,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list VNIC attachments: %w", err)
+	}
+	for _, attachment := range resp.Items {
+		if attachment.VnicId == nil || attachment.LifecycleState != core.VnicAttachmentLifecycleStateAttached {
+			continue
+		}
+		vnic, err := networkClient.GetVnic(ctx, core.GetVnicRequest{VnicId: attachment.VnicId})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get VNIC: %w", err)
+		}
+		if vnic.PrivateIp != nil {
+			privateIPs = append(privateIPs, *vnic.PrivateIp)
+		}
+		if vnic.PublicIp != nil {
+			publicIPs = append(publicIPs, *vnic.PublicIp)
+		}
+	}
+	return privateIPs, publicIPs, nil
 }
 
 // GetLocalInstanceID retrieves the OCID of the local OCI instance.
@@ -180,30 +361,60 @@ func (p *Provider) GetLocalInstanceID(ctx context.Context) (string, error) {
 	return instanceID, nil
 }
 
-// CreateBlockVolume creates a new block volume with storage autoscaling enabled.
-func (p *Provider) CreateBlockVolume(ctx context.Context, compartmentID, availabilityDomain, displayName string, sizeInGBs int64) (string, error) {
+// Tags are the freeform and defined tags of a resource.
+type Tags struct {
+	Freeform map[string]string
+	Defined  map[string]map[string]interface{} // Values by namespace and key
+}
+
+// CreateBlockVolume creates a new block volume with the given performance in VPUs per GB
+// and tags. When autotuneMaxVPUsPerGB is positive, performance-based auto-tune may raise
+// the performance up to that limit.
+func (p *Provider) CreateBlockVolume(ctx context.Context, compartmentID, availabilityDomain, displayName string, sizeInGBs, vpusPerGB, autotuneMaxVPUsPerGB int64, tags Tags) (string, error) {
 	client, err := core.NewBlockstorageClientWithConfigurationProvider(p.configProvider)
 	if err != nil {
 		return "", fmt.Errorf("failed to create block storage client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
+	return p.createVolume(ctx, client, core.CreateVolumeDetails{
+		CompartmentId:      &compartmentID,
+		AvailabilityDomain: &availabilityDomain,
+		DisplayName:        &displayName,
+		SizeInGBs:          &sizeInGBs,
+		FreeformTags:       tags.Freeform,
+		DefinedTags:        tags.Defined,
+	}, vpusPerGB, autotuneMaxVPUsPerGB)
+}
 
-	maxVpusPerGB := int64(120)
-	autotunePolicies := []core.AutotunePolicy{
-		core.PerformanceBasedAutotunePolicy{
-			MaxVpusPerGB: &maxVpusPerGB,
-		},
+// CreateBlockVolumeFromBackup restores a volume backup to a new block volume in
+// availabilityDomain, which may differ from that of the backed up volume, with the given
+// performance and tags. The volume is usable while it is hydrated from the backup.
+func (p *Provider) CreateBlockVolumeFromBackup(ctx context.Context, compartmentID, availabilityDomain, displayName, backupID string, vpusPerGB, autotuneMaxVPUsPerGB int64, tags Tags) (string, error) {
+	client, err := core.NewBlockstorageClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return "", fmt.Errorf("failed to create block storage client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
+	return p.createVolume(ctx, client, core.CreateVolumeDetails{
+		CompartmentId:      &compartmentID,
+		AvailabilityDomain: &availabilityDomain,
+		DisplayName:        &displayName,
+		SourceDetails:      core.VolumeSourceFromVolumeBackupDetails{Id: &backupID},
+		FreeformTags:       tags.Freeform,
+		DefinedTags:        tags.Defined,
+	}, vpusPerGB, autotuneMaxVPUsPerGB)
+}
 
-	req := core.CreateVolumeRequest{
-		CreateVolumeDetails: core.CreateVolumeDetails{
-			CompartmentId:      &compartmentID,
-			AvailabilityDomain: &availabilityDomain,
-			DisplayName:        &displayName,
-			SizeInGBs:          &sizeInGBs,
-			AutotunePolicies:   autotunePolicies,
-		},
+// createVolume creates the volume of details with the given performance and waits for
+// it to become available.
+func (p *Provider) createVolume(ctx context.Context, client core.BlockstorageClient, details core.CreateVolumeDetails, vpusPerGB, autotuneMaxVPUsPerGB int64) (string, error) {
+	details.VpusPerGB = &vpusPerGB
+	if autotuneMaxVPUsPerGB > 0 {
+		details.AutotunePolicies = append(details.AutotunePolicies, core.PerformanceBasedAutotunePolicy{
+			MaxVpusPerGB: &autotuneMaxVPUsPerGB,
+		})
 	}
-	resp, err := client.CreateVolume(ctx, req)
+	resp, err := client.CreateVolume(ctx, core.CreateVolumeRequest{CreateVolumeDetails: details})
 	if err != nil {
 		return "", fmt.Errorf("failed to create volume: %w", err)
 	}
@@ -213,7 +424,11 @@ func (p *Provider) CreateBlockVolume(ctx context.Context, compartmentID, availab
 	if err != nil {
 		return "", fmt.Errorf("volume did not become available: %w", err)
 	}
-	p.logger.Successf("Volume created successfully with autoscaling enabled: %s", volumeID)
+	if autotuneMaxVPUsPerGB > 0 {
+		p.logger.Successf("Volume created with %d VPUs/GB and auto-tune up to %d VPUs/GB: %s", vpusPerGB, autotuneMaxVPUsPerGB, volumeID)
+	} else {
+		p.logger.Successf("Volume created with %d VPUs/GB: %s", vpusPerGB, volumeID)
+	}
 	return volumeID, nil
 }
 
@@ -223,6 +438,7 @@ func (p *Provider) WaitForVolumeState(ctx context.Context, volumeID string, targ
 	if err != nil {
 		return fmt.Errorf("failed to create block storage client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	maxAttempts := 60
 	for i := 0; i < maxAttempts; i++ {
 		req := core.GetVolumeRequest{
@@ -249,6 +465,7 @@ func (p *Provider) AttachVolume(ctx context.Context, instanceID, volumeID, devic
 	if err != nil {
 		return "", fmt.Errorf("failed to create compute client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	req := core.AttachVolumeRequest{
 		AttachVolumeDetails: core.AttachParavirtualizedVolumeDetails{
 			InstanceId: &instanceID,
@@ -275,6 +492,7 @@ func (p *Provider) WaitForVolumeAttachmentState(ctx context.Context, attachmentI
 	if err != nil {
 		return fmt.Errorf("failed to create compute client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	maxAttempts := 60
 	for i := 0; i < maxAttempts; i++ {
 		req := core.GetVolumeAttachmentRequest{
@@ -298,6 +516,7 @@ func (p *Provider) DetachVolume(ctx context.Context, attachmentID string) error
 	if err != nil {
 		return fmt.Errorf("failed to create compute client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	req := core.DetachVolumeRequest{
 		VolumeAttachmentId: &attachmentID,
 	}
@@ -319,6 +538,7 @@ func (p *Provider) CreateVolumeSnapshot(ctx context.Context, volumeID, displayNa
 	if err != nil {
 		return "", fmt.Errorf("failed to create block storage client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	backupType := core.CreateVolumeBackupDetailsTypeFull
 	req := core.CreateVolumeBackupRequest{
 		CreateVolumeBackupDetails: core.CreateVolumeBackupDetails{
@@ -346,6 +566,7 @@ func (p *Provider) WaitForSnapshotState(ctx context.Context, snapshotID string,
 	if err != nil {
 		return fmt.Errorf("failed to create block storage client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	maxAttempts := 120
 	for i := 0; i < maxAttempts; i++ {
 		req := core.GetVolumeBackupRequest{
@@ -372,6 +593,7 @@ func (p *Provider) DeleteVolume(ctx context.Context, volumeID string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create block storage client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 	req := core.DeleteVolumeRequest{
 		VolumeId: &volumeID,
 	}
@@ -386,30 +608,29 @@ func (p *Provider) DeleteVolume(ctx context.Context, volumeID string) error {
 	return nil
 }
 
-// ImportImage imports a custom image from Object Storage.
-func (p *Provider) ImportImage(ctx context.Context, compartmentID, namespace, bucketName, objectName, imageName, operatingSystem, operatingSystemVersion string) (string, error) {
+// ImageImportOptions describes the custom image created by ImportImage.
+type ImageImportOptions struct {
+	DisplayName            string
+	OperatingSystem        string
+	OperatingSystemVersion string
+	LaunchMode             core.CreateImageDetailsLaunchModeEnum // PARAVIRTUALIZED when empty
+	FreeformTags           map[string]string
+	DefinedTags            map[string]map[string]interface{} // Values by namespace and key
+}
+
+// ImportImage imports a QCOW2 custom image from Object Storage. The object is referenced
+// by namespace, bucket and object name rather than by URL, so that the import works in
+// every realm and for object names with special characters.
+func (p *Provider) ImportImage(ctx context.Context, compartmentID, namespace, bucketName, objectName string, opts ImageImportOptions) (string, error) {
 	client, err := core.NewComputeClientWithConfigurationProvider(p.configProvider)
 	if err != nil {
 		return "", fmt.Errorf("failed to create compute client: %w", err)
 	}
-
-	launchMode := core.CreateImageDetailsLaunchModeParavirtualized
+	p.instrument(&client.BaseClient)
 
 	req := core.CreateImageRequest{
-		CreateImageDetails: core.CreateImageDetails{
-			CompartmentId: &compartmentID,
-			DisplayName:   &imageName,
-			LaunchMode:    launchMode,
-			ImageSourceDetails: core.ImageSourceViaObjectStorageTupleDetails{
-				NamespaceName:          &namespace,
-				BucketName:             &bucketName,
-				ObjectName:             &objectName,
-				OperatingSystem:        &operatingSystem,
-				OperatingSystemVersion: &operatingSystemVersion,
-			},
-		},
+		CreateImageDetails: createImageDetails(compartmentID, namespace, bucketName, objectName, opts),
 	}
-
 	resp, err := client.CreateImage(ctx, req)
 	if err != nil {
 		return "", fmt.Errorf("failed to create image: %w", err)
@@ -420,6 +641,102 @@ func (p *Provider) ImportImage(ctx context.Context, compartmentID, namespace, bu
 	return imageID, nil
 }
 
+// createImageDetails returns the request details for importing a QCOW2 object as a custom image.
+func createImageDetails(compartmentID, namespace, bucketName, objectName string, opts ImageImportOptions) core.CreateImageDetails {
+	launchMode := opts.LaunchMode
+	if launchMode == "" {
+		launchMode = core.CreateImageDetailsLaunchModeParavirtualized
+	}
+	source := core.ImageSourceViaObjectStorageTupleDetails{
+		NamespaceName:   &namespace,
+		BucketName:      &bucketName,
+		ObjectName:      &objectName,
+		SourceImageType: core.ImageSourceDetailsSourceImageTypeQcow2,
+	}
+	if opts.OperatingSystem != "" {
+		source.OperatingSystem = &opts.OperatingSystem
+	}
+	if opts.OperatingSystemVersion != "" {
+		source.OperatingSystemVersion = &opts.OperatingSystemVersion
+	}
+	return core.CreateImageDetails{
+		CompartmentId:      &compartmentID,
+		DisplayName:        &opts.DisplayName,
+		LaunchMode:         launchMode,
+		ImageSourceDetails: source,
+		FreeformTags:       opts.FreeformTags,
+		DefinedTags:        opts.DefinedTags,
+	}
+}
+
+// FindImageByName returns the OCID of the newest image in the compartment with the given
+// display name that is available or still importing, or "" when there is none.
+func (p *Provider) FindImageByName(ctx context.Context, compartmentID, displayName string) (string, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return "", fmt.Errorf("failed to create compute client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+
+	resp, err := client.ListImages(ctx, core.ListImagesRequest{
+		CompartmentId: &compartmentID,
+		DisplayName:   &displayName,
+		SortBy:        core.ListImagesSortByTimecreated,
+		SortOrder:     core.ListImagesSortOrderDesc,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list images: %w", err)
+	}
+	for _, image := range resp.Items {
+		switch image.LifecycleState {
+		case core.ImageLifecycleStateAvailable, core.ImageLifecycleStateImporting, core.ImageLifecycleStateProvisioning:
+			return *image.Id, nil
+		}
+	}
+	return "", nil
+}
+
+// ListImagesByTag lists the images in the compartment with the given freeform tag.
+func (p *Provider) ListImagesByTag(ctx context.Context, compartmentID, key, value string) ([]core.Image, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+
+	var images []core.Image
+	req := core.ListImagesRequest{CompartmentId: &compartmentID}
+	for {
+		resp, err := client.ListImages(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list images: %w", err)
+		}
+		for _, image := range resp.Items {
+			if image.FreeformTags[key] == value {
+				images = append(images, image)
+			}
+		}
+		if resp.OpcNextPage == nil {
+			return images, nil
+		}
+		req.Page = resp.OpcNextPage
+	}
+}
+
+// DeleteImage deletes a custom image.
+func (p *Provider) DeleteImage(ctx context.Context, imageID string) error {
+	client, err := core.NewComputeClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return fmt.Errorf("failed to create compute client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+
+	if _, err := client.DeleteImage(ctx, core.DeleteImageRequest{ImageId: &imageID}); err != nil {
+		return fmt.Errorf("failed to delete image: %w", err)
+	}
+	return nil
+}
+
 // WaitForImageState waits for an image to reach the specified state.
 func (p *Provider) WaitForImageState(ctx context.Context, imageID string, targetState core.ImageLifecycleStateEnum) error {
 	const (
@@ -431,6 +748,7 @@ func (p *Provider) WaitForImageState(ctx context.Context, imageID string, target
 	if err != nil {
 		return fmt.Errorf("failed to create compute client: %w", err)
 	}
+	p.instrument(&client.BaseClient)
 
 	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
 		var cancel context.CancelFunc
@@ -471,3 +789,119 @@ func (p *Provider) WaitForImageState(ctx context.Context, imageID string, target
 		}
 	}
 }
+
+// GetInstance returns the details of an instance.
+func (p *Provider) GetInstance(ctx context.Context, instanceID string) (core.Instance, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return core.Instance{}, fmt.Errorf("failed to create compute client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	resp, err := client.GetInstance(ctx, core.GetInstanceRequest{InstanceId: &instanceID})
+	if err != nil {
+		return core.Instance{}, fmt.Errorf("failed to get instance: %w", err)
+	}
+	return resp.Instance, nil
+}
+
+// StartInstance starts a stopped instance and waits for it to be running.
+func (p *Provider) StartInstance(ctx context.Context, instanceID string) error {
+	const (
+		timeout  = 15 * time.Minute
+		interval = 10 * time.Second
+	)
+	client, err := core.NewComputeClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return fmt.Errorf("failed to create compute client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+
+	resp, err := client.GetInstance(ctx, core.GetInstanceRequest{InstanceId: &instanceID})
+	if err != nil {
+		return fmt.Errorf("failed to get instance: %w", err)
+	}
+	if resp.LifecycleState == core.InstanceLifecycleStateRunning {
+		p.logger.Infof("Instance %s is already running", instanceID)
+		return nil
+	}
+	if _, err := client.InstanceAction(ctx, core.InstanceActionRequest{InstanceId: &instanceID, Action: core.InstanceActionActionStart}); err != nil {
+		return fmt.Errorf("failed to start instance: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		resp, err := client.GetInstance(ctx, core.GetInstanceRequest{InstanceId: &instanceID})
+		if err != nil {
+			return fmt.Errorf("failed to get instance state: %w", err)
+		}
+		switch resp.LifecycleState {
+		case core.InstanceLifecycleStateRunning:
+			return nil
+		case core.InstanceLifecycleStateTerminating, core.InstanceLifecycleStateTerminated:
+			return fmt.Errorf("instance %s is %s", instanceID, resp.LifecycleState)
+		}
+		p.logger.Debugf("Instance %s is %s", instanceID, resp.LifecycleState)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timeout waiting up to %s for instance %s to start: %w", timeout, instanceID, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// CreateObjectWriteURL creates a pre-authenticated request that allows writing a single
+// object until expires, and returns its URL. Holders of the URL need no OCI credentials.
+func (p *Provider) CreateObjectWriteURL(ctx context.Context, namespace, bucketName, objectName string, expires time.Time) (string, error) {
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return "", fmt.Errorf("failed to create object storage client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	resp, err := client.CreatePreauthenticatedRequest(ctx, objectstorage.CreatePreauthenticatedRequestRequest{
+		NamespaceName: &namespace,
+		BucketName:    &bucketName,
+		CreatePreauthenticatedRequestDetails: objectstorage.CreatePreauthenticatedRequestDetails{
+			Name:        common.String("kopru-" + strings.ReplaceAll(objectName, "/", "-")),
+			AccessType:  objectstorage.CreatePreauthenticatedRequestDetailsAccessTypeObjectwrite,
+			ObjectName:  &objectName,
+			TimeExpires: &common.SDKTime{Time: expires},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create pre-authenticated request: %w", err)
+	}
+	if resp.FullPath != nil {
+		return *resp.FullPath, nil
+	}
+	return client.Host + *resp.AccessUri, nil
+}
+
+// GetObject reads an object from Object Storage. It returns found false, without error,
+// when the object does not exist.
+func (p *Provider) GetObject(ctx context.Context, namespace, bucketName, objectName string) (data []byte, found bool, err error) {
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create object storage client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	resp, err := client.GetObject(ctx, objectstorage.GetObjectRequest{
+		NamespaceName: &namespace,
+		BucketName:    &bucketName,
+		ObjectName:    &objectName,
+	})
+	if err != nil {
+		if serviceErr, ok := common.IsServiceError(err); ok && serviceErr.GetHTTPStatusCode() == http.StatusNotFound {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get object %s: %w", objectName, err)
+	}
+	defer resp.Content.Close()
+	data, err = io.ReadAll(resp.Content)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read object %s: %w", objectName, err)
+	}
+	return data, true, nil
+}
@@ -0,0 +1,53 @@
+package oci
+
+import (
+	"testing"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+func TestWithRegion(t *testing.T) {
+	base := common.NewRawConfigurationProvider("ocid1.tenancy.oc1..example", "ocid1.user.oc1..example", "us-ashburn-1", "aa:bb", "", nil)
+
+	region, err := withRegion(base, "uk-gov-london-1").Region()
+	if err != nil || region != "uk-gov-london-1" {
+		t.Errorf("Region() = %q, %v, want uk-gov-london-1", region, err)
+	}
+	tenancy, _ := withRegion(base, "uk-gov-london-1").TenancyOCID()
+	if tenancy != "ocid1.tenancy.oc1..example" {
+		t.Errorf("TenancyOCID() = %q", tenancy)
+	}
+	if region, _ := withRegion(base, "").Region(); region != "us-ashburn-1" {
+		t.Errorf("Expected profile region without override, got %q", region)
+	}
+
+}
+
+func TestCreateImageDetails(t *testing.T) {
+	details := createImageDetails("ocid1.compartment.oc1..example", "ns", "kopru-bucket", "disk #1.qcow2", ImageImportOptions{
+		DisplayName:     "vm-imported-image",
+		OperatingSystem: "Ubuntu",
+	})
+	if details.LaunchMode != core.CreateImageDetailsLaunchModeParavirtualized {
+		t.Errorf("LaunchMode = %s, want PARAVIRTUALIZED", details.LaunchMode)
+	}
+	source, ok := details.ImageSourceDetails.(core.ImageSourceViaObjectStorageTupleDetails)
+	if !ok {
+		t.Fatalf("ImageSourceDetails = %T, want object storage tuple", details.ImageSourceDetails)
+	}
+	if *source.NamespaceName != "ns" || *source.BucketName != "kopru-bucket" || *source.ObjectName != "disk #1.qcow2" {
+		t.Errorf("Unexpected image source: %+v", source)
+	}
+	if source.SourceImageType != core.ImageSourceDetailsSourceImageTypeQcow2 {
+		t.Errorf("SourceImageType = %s, want QCOW2", source.SourceImageType)
+	}
+	if *source.OperatingSystem != "Ubuntu" || source.OperatingSystemVersion != nil {
+		t.Errorf("Unexpected operating system: %v, %v", source.OperatingSystem, source.OperatingSystemVersion)
+	}
+
+	details = createImageDetails("ocid1.compartment.oc1..example", "ns", "b", "o", ImageImportOptions{LaunchMode: core.CreateImageDetailsLaunchModeEmulated})
+	if details.LaunchMode != core.CreateImageDetailsLaunchModeEmulated {
+		t.Errorf("LaunchMode = %s, want EMULATED", details.LaunchMode)
+	}
+}
@@ -0,0 +1,32 @@
+package oci
+
+import (
+	"context"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/retry"
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+// retryPolicy returns the OCI SDK retry policy for the shared retry policy. The SDK's
+// conditions are kept (409 IncorrectState, 429 and 5xx except 501, and timeouts), while
+// the number of attempts, the backoff and the retry budget come from the shared policy.
+func (p *Provider) retryPolicy() *common.RetryPolicy {
+	shared := retry.Default()
+	policy := common.NewRetryPolicyWithOptions(
+		common.WithMaximumNumberAttempts(uint(shared.MaxAttempts)),
+		common.WithShouldRetryOperation(func(r common.OCIOperationResponse) bool {
+			if !common.DefaultShouldRetryOperation(r) || !shared.Allow(int(r.AttemptNumber)) {
+				return false
+			}
+			p.logger.Debugf("OCI request failed, retrying (attempt %d of %d): %v", r.AttemptNumber+1, shared.MaxAttempts, r.Error)
+			telemetry.AddRetries(context.Background(), "oci", 1)
+			return true
+		}),
+		common.WithNextDuration(func(r common.OCIOperationResponse) time.Duration {
+			return shared.Delay(int(r.AttemptNumber) + 1)
+		}),
+	)
+	return &policy
+}
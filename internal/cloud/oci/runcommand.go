@@ -0,0 +1,92 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/computeinstanceagent"
+)
+
+// CommandResult is the outcome of a script run through the Run Command plugin.
+type CommandResult struct {
+	CommandID string
+	State     string
+	ExitCode  int
+	Output    string
+	Message   string
+}
+
+// RunCommand runs script on the instance through the Compute Instance Run Command
+// plugin of the Oracle Cloud Agent and waits up to timeout for it to finish. The
+// command is queued until the agent of the instance picks it up, so it may be issued
+// while the instance is still booting. The plugin must be enabled on the instance and
+// the instance must be allowed to use instance-agent-command-execution-family, e.g.
+// through a dynamic group policy.
+func (p *Provider) RunCommand(ctx context.Context, compartmentID, instanceID, displayName, script string, timeout time.Duration) (*CommandResult, error) {
+	client, err := computeinstanceagent.NewComputeInstanceAgentClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute instance agent client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	resp, err := client.CreateInstanceAgentCommand(ctx, computeinstanceagent.CreateInstanceAgentCommandRequest{
+		CreateInstanceAgentCommandDetails: computeinstanceagent.CreateInstanceAgentCommandDetails{
+			CompartmentId:             &compartmentID,
+			DisplayName:               &displayName,
+			ExecutionTimeOutInSeconds: common.Int(int(timeout.Seconds())),
+			Target:                    &computeinstanceagent.InstanceAgentCommandTarget{InstanceId: &instanceID},
+			Content: &computeinstanceagent.InstanceAgentCommandContent{
+				Source: computeinstanceagent.InstanceAgentCommandSourceViaTextDetails{Text: &script},
+				Output: computeinstanceagent.InstanceAgentCommandOutputViaTextDetails{},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create instance agent command: %w", err)
+	}
+	result := &CommandResult{CommandID: *resp.Id}
+	p.logger.Infof("Run command created: %s", result.CommandID)
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		exec, err := client.GetInstanceAgentCommandExecution(ctx, computeinstanceagent.GetInstanceAgentCommandExecutionRequest{
+			InstanceAgentCommandId: resp.Id,
+			InstanceId:             &instanceID,
+		})
+		if err != nil {
+			// The execution only exists once the agent has picked up the command
+			if serviceErr, ok := common.IsServiceError(err); !ok || serviceErr.GetHTTPStatusCode() != http.StatusNotFound {
+				return result, fmt.Errorf("failed to get instance agent command execution: %w", err)
+			}
+		} else {
+			result.State = string(exec.LifecycleState)
+			switch exec.LifecycleState {
+			case computeinstanceagent.InstanceAgentCommandExecutionLifecycleStateSucceeded,
+				computeinstanceagent.InstanceAgentCommandExecutionLifecycleStateFailed,
+				computeinstanceagent.InstanceAgentCommandExecutionLifecycleStateTimedOut,
+				computeinstanceagent.InstanceAgentCommandExecutionLifecycleStateCanceled:
+				if output, ok := exec.Content.(computeinstanceagent.InstanceAgentCommandExecutionOutputViaTextDetails); ok {
+					if output.ExitCode != nil {
+						result.ExitCode = *output.ExitCode
+					}
+					if output.Text != nil {
+						result.Output = *output.Text
+					}
+					if output.Message != nil {
+						result.Message = *output.Message
+					}
+				}
+				return result, nil
+			}
+			p.logger.Debugf("Run command %s: delivery %s, state %s", result.CommandID, exec.DeliveryState, exec.LifecycleState)
+		}
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(15 * time.Second):
+		}
+	}
+	return result, fmt.Errorf("timeout waiting for run command %s after %s", result.CommandID, timeout)
+}
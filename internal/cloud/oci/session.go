@@ -0,0 +1,240 @@
+package oci
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+)
+
+const (
+	defaultConfigProfile = "DEFAULT"
+	sessionRefreshMargin = 10 * time.Minute // Refresh session tokens this long before they expire
+	sessionRetryInterval = time.Minute      // Delay before retrying a failed refresh
+)
+
+// sessionProfile identifies a profile of the OCI CLI configuration that authenticates
+// with a security token created by `oci session authenticate`.
+type sessionProfile struct {
+	configFile string
+	profile    string
+	tokenFile  string
+}
+
+// configFileAndProfile returns the OCI CLI configuration file and profile in use,
+// honouring the OCI_CLI_CONFIG_FILE and OCI_CLI_PROFILE environment variables.
+func configFileAndProfile() (string, string) {
+	configFile := os.Getenv("OCI_CLI_CONFIG_FILE")
+	if configFile == "" {
+		home, _ := os.UserHomeDir()
+		configFile = filepath.Join(home, ".oci", "config")
+	}
+	profile := os.Getenv("OCI_CLI_PROFILE")
+	if profile == "" {
+		profile = defaultConfigProfile
+	}
+	return configFile, profile
+}
+
+// detectSessionProfile returns the session profile when the given profile of the
+// configuration file sets security_token_file, or nil otherwise.
+func detectSessionProfile(configFile, profile string) *sessionProfile {
+	// #nosec G304 -- configFile is the user's OCI CLI configuration
+	f, err := os.Open(configFile)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found || current != profile || strings.TrimSpace(key) != "security_token_file" {
+			continue
+		}
+		tokenFile := strings.TrimSpace(value)
+		if strings.HasPrefix(tokenFile, "~/") {
+			home, _ := os.UserHomeDir()
+			tokenFile = filepath.Join(home, tokenFile[2:])
+		}
+		return &sessionProfile{configFile: configFile, profile: profile, tokenFile: tokenFile}
+	}
+	return nil
+}
+
+// configProvider returns a configuration provider that re-reads the security token on
+// every request, so that refreshed tokens are picked up without restarting Kopru.
+func (s *sessionProfile) configProvider() common.ConfigurationProvider {
+	return common.CustomProfileSessionTokenConfigProvider(s.configFile, s.profile)
+}
+
+// expiry returns the expiry time of the security token.
+func (s *sessionProfile) expiry() (time.Time, error) {
+	// #nosec G304 -- tokenFile is read from the user's OCI CLI configuration
+	data, err := os.ReadFile(s.tokenFile)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read security token: %w", err)
+	}
+	return tokenExpiry(strings.TrimSpace(string(data)))
+}
+
+// refresh extends the session with `oci session refresh`. Sessions can be refreshed
+// until their maximum lifetime (24 hours by default) is reached.
+func (s *sessionProfile) refresh(ctx context.Context) error {
+	// #nosec G204 -- arguments are read from the user's OCI CLI configuration
+	cmd := exec.CommandContext(ctx, "oci", "session", "refresh", "--config-file", s.configFile, "--profile", s.profile)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("oci session refresh failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// tokenClaims are the claims of a security token Kopru reads.
+type tokenClaims struct {
+	Sub string `json:"sub"` // OCID of the user
+	Exp int64  `json:"exp"`
+}
+
+// parseToken decodes the claims of a JWT.
+func parseToken(token string) (tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return tokenClaims{}, fmt.Errorf("unexpected security token format")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return tokenClaims{}, fmt.Errorf("failed to decode security token: %w", err)
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return tokenClaims{}, fmt.Errorf("failed to decode security token: %w", err)
+	}
+	return claims, nil
+}
+
+// tokenExpiry decodes the exp claim of a JWT.
+func tokenExpiry(token string) (time.Time, error) {
+	claims, err := parseToken(token)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if claims.Exp == 0 {
+		return time.Time{}, fmt.Errorf("security token has no expiry")
+	}
+	return time.Unix(claims.Exp, 0), nil
+}
+
+// userOCID returns the OCID of the user the security token was issued to. Session
+// profiles have no user entry, so it is read from the sub claim of the token.
+func (s *sessionProfile) userOCID() (string, error) {
+	// #nosec G304 -- tokenFile is read from the user's OCI CLI configuration
+	data, err := os.ReadFile(s.tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read security token: %w", err)
+	}
+	claims, err := parseToken(strings.TrimSpace(string(data)))
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(claims.Sub, "ocid1.user.") {
+		return "", fmt.Errorf("security token is not issued to a user")
+	}
+	return claims.Sub, nil
+}
+
+// UsesSessionToken reports whether the provider authenticates with an OCI CLI session token.
+func (p *Provider) UsesSessionToken() bool {
+	return p.session != nil
+}
+
+// SessionProfile returns the OCI CLI profile that authenticates with a session token,
+// or an empty string when API keys or other credentials are used.
+func (p *Provider) SessionProfile() string {
+	if p.session == nil {
+		return ""
+	}
+	return p.session.profile
+}
+
+// userOCID returns the OCID of the authenticated user.
+func (p *Provider) userOCID() (string, error) {
+	if p.session != nil {
+		return p.session.userOCID()
+	}
+	userID, err := p.configProvider.UserOCID()
+	if err == nil && userID == "" {
+		err = fmt.Errorf("no user in the OCI configuration")
+	}
+	return userID, err
+}
+
+// CheckSession verifies that the session token is valid, refreshing it when it is
+// expired or about to expire, and returns its expiry. It fails with instructions to
+// re-authenticate when the session can no longer be refreshed.
+func (p *Provider) CheckSession(ctx context.Context) (time.Time, error) {
+	expiry, err := p.session.expiry()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if time.Until(expiry) > sessionRefreshMargin {
+		return expiry, nil
+	}
+	if err := p.session.refresh(ctx); err != nil {
+		return time.Time{}, fmt.Errorf("OCI session token expires at %s and could not be refreshed, run 'oci session authenticate --profile %s': %w",
+			expiry.Format(time.RFC3339), p.session.profile, err)
+	}
+	return p.session.expiry()
+}
+
+// MonitorSession refreshes the session token in the background shortly before it
+// expires, so that long transfers do not fail with authentication errors. It logs
+// an error with re-authentication instructions when a refresh fails. The returned
+// function stops the monitor. Without session token authentication it does nothing.
+func (p *Provider) MonitorSession(ctx context.Context) (stop func()) {
+	if p.session == nil {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		for {
+			wait := sessionRetryInterval
+			if expiry, err := p.session.expiry(); err == nil {
+				wait = max(time.Until(expiry)-sessionRefreshMargin, 0)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			expiry, err := p.CheckSession(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				p.logger.Errorf("%v", err)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(sessionRetryInterval):
+				}
+				continue
+			}
+			p.logger.Infof("OCI session token refreshed, valid until %s", expiry.Format(time.RFC3339))
+		}
+	}()
+	return cancel
+}
@@ -0,0 +1,88 @@
+package oci
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDetectSessionProfile(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	content := `[DEFAULT]
+user=ocid1.user.oc1..example
+key_file=~/.oci/key.pem
+
+[session]
+region=us-ashburn-1
+security_token_file = /tmp/token
+`
+	if err := os.WriteFile(configFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := detectSessionProfile(configFile, "DEFAULT"); s != nil {
+		t.Errorf("Expected API key profile not to be detected as session, got %+v", s)
+	}
+	s := detectSessionProfile(configFile, "session")
+	if s == nil || s.tokenFile != "/tmp/token" || s.profile != "session" {
+		t.Errorf("Unexpected session profile: %+v", s)
+	}
+	if s := detectSessionProfile(filepath.Join(dir, "missing"), "DEFAULT"); s != nil {
+		t.Errorf("Expected nil for missing config file, got %+v", s)
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"ocid1.user.oc1..example","exp":1767225600}`))
+	expiry, err := tokenExpiry("header." + payload + ".signature")
+	if err != nil {
+		t.Fatalf("tokenExpiry() error = %v", err)
+	}
+	if !expiry.Equal(time.Unix(1767225600, 0)) {
+		t.Errorf("tokenExpiry() = %v", expiry)
+	}
+
+	if _, err := tokenExpiry("not-a-token"); err == nil {
+		t.Error("Expected error for malformed token")
+	}
+}
+
+func TestSessionUserOCID(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	s := &sessionProfile{profile: "session", tokenFile: tokenFile}
+	tests := []struct {
+		claims  string
+		want    string
+		wantErr bool
+	}{
+		{`{"sub":"ocid1.user.oc1..example","exp":1767225600}`, "ocid1.user.oc1..example", false},
+		{`{"sub":"ocid1.instance.oc1..example","exp":1767225600}`, "", true},
+	}
+	for _, tt := range tests {
+		token := "header." + base64.RawURLEncoding.EncodeToString([]byte(tt.claims)) + ".signature\n"
+		if err := os.WriteFile(tokenFile, []byte(token), 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.userOCID()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("userOCID() with %s = %q, %v, want %q", tt.claims, got, err, tt.want)
+		}
+	}
+}
+
+func TestSecretRegion(t *testing.T) {
+	tests := map[string]string{
+		"ocid1.vaultsecret.oc1.iad.amaaaaaa":            "us-ashburn-1",
+		"ocid1.vaultsecret.oc1.eu-frankfurt-1.amaaaaaa": "eu-frankfurt-1",
+		"ocid1.vaultsecret.oc1..amaaaaaa":               "",
+	}
+	for secretID, want := range tests {
+		if got := secretRegion(secretID); got != want {
+			t.Errorf("secretRegion(%s) = %q, want %q", secretID, got, want)
+		}
+	}
+}
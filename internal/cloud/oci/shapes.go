@@ -0,0 +1,66 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// ListShapes lists the shapes that instances in the compartment can use in an
+// availability domain.
+func (p *Provider) ListShapes(ctx context.Context, compartmentID, availabilityDomain string) ([]core.Shape, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compute client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	req := core.ListShapesRequest{
+		CompartmentId:      &compartmentID,
+		AvailabilityDomain: &availabilityDomain,
+	}
+	var shapes []core.Shape
+	for {
+		resp, err := client.ListShapes(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list shapes: %w", err)
+		}
+		shapes = append(shapes, resp.Items...)
+		if resp.OpcNextPage == nil {
+			return shapes, nil
+		}
+		req.Page = resp.OpcNextPage
+	}
+}
+
+// CheckShapeCapacity reports whether the availability domain has the host capacity to
+// launch an instance of shape. ocpus and memoryGB configure flexible shapes and are
+// ignored when zero.
+func (p *Provider) CheckShapeCapacity(ctx context.Context, compartmentID, availabilityDomain, shape string, ocpus, memoryGB float32) (core.CapacityReportShapeAvailabilityAvailabilityStatusEnum, error) {
+	client, err := core.NewComputeClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return "", fmt.Errorf("failed to create compute client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	availability := core.CreateCapacityReportShapeAvailabilityDetails{InstanceShape: &shape}
+	if ocpus > 0 {
+		availability.InstanceShapeConfig = &core.CapacityReportInstanceShapeConfig{Ocpus: &ocpus}
+		if memoryGB > 0 {
+			availability.InstanceShapeConfig.MemoryInGBs = &memoryGB
+		}
+	}
+	resp, err := client.CreateComputeCapacityReport(ctx, core.CreateComputeCapacityReportRequest{
+		CreateComputeCapacityReportDetails: core.CreateComputeCapacityReportDetails{
+			CompartmentId:       &compartmentID,
+			AvailabilityDomain:  &availabilityDomain,
+			ShapeAvailabilities: []core.CreateCapacityReportShapeAvailabilityDetails{availability},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create compute capacity report: %w", err)
+	}
+	if len(resp.ShapeAvailabilities) == 0 {
+		return "", fmt.Errorf("compute capacity report has no availability of shape %s", shape)
+	}
+	return resp.ShapeAvailabilities[0].AvailabilityStatus, nil
+}
@@ -0,0 +1,198 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/identity"
+)
+
+// policyVerbs ranks the IAM verbs; each includes the permissions of the ones before it.
+var policyVerbs = map[string]int{"inspect": 1, "read": 2, "use": 3, "manage": 4}
+
+// policyPattern matches an allow statement, capturing the subject type, the subjects,
+// the verb, the resource type, the location and an optional condition.
+var policyPattern = regexp.MustCompile(`(?i)^allow\s+(any-user|group|dynamic-group)\s*(.*?)\s+to\s+(inspect|read|use|manage)\s+(\S+)\s+in\s+(tenancy|compartment\s+(?:id\s+)?\S+)(?:\s+where\s+(.+))?$`)
+
+// policyGrant is a parsed allow statement.
+type policyGrant struct {
+	anyUser     bool
+	groups      []string // lowercase group names or OCIDs
+	verb        string
+	resource    string
+	location    string // "tenancy", or a compartment OCID or name path
+	conditional bool
+}
+
+// parsePolicyStatement parses an allow statement. Statements for dynamic groups, which
+// never apply to users, and statements Kopru cannot interpret are ignored.
+func parsePolicyStatement(statement string) (policyGrant, bool) {
+	m := policyPattern.FindStringSubmatch(strings.TrimSpace(statement))
+	if m == nil || strings.EqualFold(m[1], "dynamic-group") {
+		return policyGrant{}, false
+	}
+	g := policyGrant{
+		anyUser:     strings.EqualFold(m[1], "any-user"),
+		verb:        strings.ToLower(m[3]),
+		resource:    strings.ToLower(m[4]),
+		conditional: m[6] != "",
+	}
+	if location := strings.Fields(m[5]); strings.EqualFold(location[0], "tenancy") {
+		g.location = "tenancy"
+	} else {
+		g.location = strings.ToLower(location[len(location)-1])
+	}
+	for _, subject := range strings.Split(m[2], ",") {
+		subject = strings.TrimSpace(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(subject)), "id "))
+		if i := strings.LastIndex(subject, "/"); i >= 0 {
+			subject = subject[i+1:] // identity domain prefix
+		}
+		if subject = strings.Trim(subject, `'"`); subject != "" {
+			g.groups = append(g.groups, subject)
+		}
+	}
+	return g, true
+}
+
+// compartmentRef identifies a compartment on the path from the target compartment to the tenancy.
+type compartmentRef struct {
+	id   string
+	name string
+}
+
+// grants reports whether g allows a member of groups to use verb on resource in the
+// compartment at the head of chain. A grant in a compartment applies to all of its
+// subcompartments, so it matches any compartment of the chain; name paths are matched
+// by their last component.
+func (g policyGrant) grants(groups map[string]bool, verb, resource string, chain []compartmentRef) bool {
+	if policyVerbs[g.verb] < policyVerbs[verb] || (g.resource != resource && g.resource != "all-resources") {
+		return false
+	}
+	member := g.anyUser
+	for _, group := range g.groups {
+		member = member || groups[group]
+	}
+	if !member {
+		return false
+	}
+	if g.location == "tenancy" {
+		return true
+	}
+	name := g.location[strings.LastIndex(g.location, ":")+1:]
+	for _, c := range chain {
+		if strings.EqualFold(c.id, g.location) || strings.EqualFold(c.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// SimulatePolicies evaluates the IAM policies of the target compartment and its parent
+// compartments for the groups of the current user, and reports the resource types the
+// user may not manage there. Unlike CheckPolicies, which only proves read access, this
+// finds missing manage permissions (creating images, launching instances) before the
+// migration relies on them. Conditional statements are assumed to grant access. It
+// fails when the user or the policies cannot be read, for example for users without
+// inspect access to groups and policies.
+func (p *Provider) SimulatePolicies(ctx context.Context, compartmentID string, resources []string) ([]MissingPolicy, error) {
+	client, err := identity.NewIdentityClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create identity client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	tenancyID, err := p.configProvider.TenancyOCID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenancy OCID: %w", err)
+	}
+	userID, err := p.userOCID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user OCID: %w", err)
+	}
+
+	groups := make(map[string]bool)
+	memberships := identity.ListUserGroupMembershipsRequest{CompartmentId: &tenancyID, UserId: &userID}
+	for {
+		resp, err := client.ListUserGroupMemberships(ctx, memberships)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list group memberships: %w", err)
+		}
+		for _, m := range resp.Items {
+			if m.GroupId == nil {
+				continue
+			}
+			groups[strings.ToLower(*m.GroupId)] = true
+			group, err := client.GetGroup(ctx, identity.GetGroupRequest{GroupId: m.GroupId})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get group %s: %w", *m.GroupId, err)
+			}
+			if group.Name != nil {
+				groups[strings.ToLower(*group.Name)] = true
+			}
+		}
+		if resp.OpcNextPage == nil {
+			break
+		}
+		memberships.Page = resp.OpcNextPage
+	}
+
+	var chain []compartmentRef
+	var grants []policyGrant
+	for id := compartmentID; id != ""; {
+		compartment, err := client.GetCompartment(ctx, identity.GetCompartmentRequest{CompartmentId: &id})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get compartment %s: %w", id, err)
+		}
+		ref := compartmentRef{id: id}
+		if compartment.Name != nil {
+			ref.name = *compartment.Name
+		}
+		chain = append(chain, ref)
+		policies := identity.ListPoliciesRequest{CompartmentId: common.String(id)}
+		for {
+			resp, err := client.ListPolicies(ctx, policies)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list policies in compartment %s: %w", id, err)
+			}
+			for _, policy := range resp.Items {
+				for _, statement := range policy.Statements {
+					if g, ok := parsePolicyStatement(statement); ok {
+						grants = append(grants, g)
+					}
+				}
+			}
+			if resp.OpcNextPage == nil {
+				break
+			}
+			policies.Page = resp.OpcNextPage
+		}
+		if id == tenancyID || compartment.CompartmentId == nil {
+			break
+		}
+		id = *compartment.CompartmentId
+	}
+
+	var missing []MissingPolicy
+	for _, resource := range resources {
+		granted := false
+		for _, g := range grants {
+			if g.grants(groups, "manage", resource, chain) {
+				granted = true
+				if g.conditional {
+					p.logger.Debugf("manage %s is granted by a conditional policy statement", resource)
+				}
+				break
+			}
+		}
+		if !granted {
+			missing = append(missing, MissingPolicy{
+				Resource:  resource,
+				Statement: PolicyStatement(resource, compartmentID),
+				Err:       fmt.Errorf("no policy statement allows your groups to manage %s in compartment %s", resource, chain[0].name),
+			})
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,92 @@
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"unicode"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// tracingDispatcher traces each OCI API request as a span, including the
+// opc-request-id needed when raising a service request with Oracle.
+type tracingDispatcher struct {
+	next common.HTTPRequestDispatcher
+}
+
+func (d tracingDispatcher) Do(req *http.Request) (*http.Response, error) {
+	service, _, _ := strings.Cut(req.URL.Host, ".")
+	telemetry.RecordOperation(telemetry.CloudOCI, callerOperation(service+":"+req.Method+" "+req.URL.Path))
+	ctx, span := telemetry.StartSpan(req.Context(), fmt.Sprintf("oci %s %s", service, req.Method),
+		attribute.String("http.request.method", req.Method),
+		attribute.String("server.address", req.URL.Host),
+		attribute.String("url.path", req.URL.Path),
+	)
+	resp, err := d.next.Do(req.WithContext(ctx))
+	spanErr := err
+	if resp != nil {
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", resp.StatusCode),
+			attribute.String("oci.opc_request_id", resp.Header.Get("opc-request-id")),
+		)
+		if err == nil && resp.StatusCode >= http.StatusBadRequest {
+			spanErr = errors.New(resp.Status)
+		}
+	}
+	telemetry.EndSpan(span, spanErr)
+	return resp, err
+}
+
+// sdkPackagePrefix prefixes the functions of the OCI SDK service packages.
+const sdkPackagePrefix = "github.com/oracle/oci-go-sdk/v65/"
+
+// callerOperation returns the OCI API operation being sent, e.g. core:ListImages, from
+// the method of the SDK service client on the call stack. The HTTP request does not name
+// its operation, but every generated client method sends exactly one. fallback is
+// returned for requests not sent by a service client.
+func callerOperation(fallback string) string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if operation, ok := operationName(frame.Function); ok {
+			return operation
+		}
+		if !more {
+			return fallback
+		}
+	}
+}
+
+// operationName parses the name of the unexported service client method that sends an
+// operation, such as github.com/oracle/oci-go-sdk/v65/core.ComputeClient.listImages-fm,
+// into core:ListImages. Exported methods, including those promoted from BaseClient, are
+// not operations.
+func operationName(function string) (string, bool) {
+	name, ok := strings.CutPrefix(function, sdkPackagePrefix)
+	if !ok || strings.Contains(name, "/") {
+		return "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(name, "-fm"), ".")
+	if len(parts) != 3 || parts[0] == "common" || !strings.HasSuffix(parts[1], "Client") || parts[2] == "" {
+		return "", false
+	}
+	method := []rune(parts[2])
+	if !unicode.IsLower(method[0]) {
+		return "", false
+	}
+	method[0] = unicode.ToUpper(method[0])
+	return parts[0] + ":" + string(method), true
+}
+
+// instrument enables tracing of the requests sent by an OCI client and retries them
+// with the shared retry policy.
+func (p *Provider) instrument(client *common.BaseClient) {
+	client.HTTPClient = tracingDispatcher{next: client.HTTPClient}
+	client.Configuration.RetryPolicy = p.retryPolicy()
+}
@@ -0,0 +1,66 @@
+package oci
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+)
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		function string
+		want     string
+	}{
+		{"github.com/oracle/oci-go-sdk/v65/core.ComputeClient.listImages-fm", "core:ListImages"},
+		{"github.com/oracle/oci-go-sdk/v65/objectstorage.ObjectStorageClient.putObject", "objectstorage:PutObject"},
+		{"github.com/oracle/oci-go-sdk/v65/core.ComputeClient.ListImages", ""},
+		{"github.com/oracle/oci-go-sdk/v65/common.BaseClient.httpDo", ""},
+		{"github.com/oracle/oci-go-sdk/v65/objectstorage/transfer.multipartUploader.uploadParts", ""},
+		{"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci.tracingDispatcher.Do", ""},
+	}
+	for _, tt := range tests {
+		got, ok := operationName(tt.function)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("operationName(%q) = %q, %v, want %q", tt.function, got, ok, tt.want)
+		}
+	}
+}
+
+func TestTracingDispatcherRecordsOperations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`"namespace"`))
+	}))
+	defer server.Close()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	configProvider := common.NewRawConfigurationProvider("ocid1.tenancy.oc1..example", "ocid1.user.oc1..example", "us-ashburn-1", "aa:bb", string(keyPEM), nil)
+	client, err := objectstorage.NewObjectStorageClientWithConfigurationProvider(configProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Host = server.URL
+	client.HTTPClient = tracingDispatcher{next: server.Client()}
+
+	telemetry.ResetOperations()
+	defer telemetry.ResetOperations()
+	if _, err := client.GetNamespace(context.Background(), objectstorage.GetNamespaceRequest{}); err != nil {
+		t.Fatalf("GetNamespace() error = %v", err)
+	}
+	if ops := telemetry.Operations()[telemetry.CloudOCI]; !slices.Contains(ops, "objectstorage:GetNamespace") {
+		t.Errorf("Recorded OCI operations = %v, want objectstorage:GetNamespace", ops)
+	}
+}
@@ -0,0 +1,47 @@
+package oci
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/secrets"
+)
+
+// secretRegion returns the region of a vault secret from the region key in its OCID,
+// e.g. us-ashburn-1 for ocid1.vaultsecret.oc1.iad.example, or "" when the OCID has none.
+func secretRegion(secretID string) string {
+	parts := strings.Split(secretID, ".")
+	if len(parts) < 5 || parts[3] == "" {
+		return ""
+	}
+	return string(common.StringToRegion(parts[3]))
+}
+
+// GetVaultSecret returns the current version of the OCI Vault secret secretID, read from
+// the region in its OCID.
+func (p *Provider) GetVaultSecret(ctx context.Context, secretID string) (string, error) {
+	client, err := secrets.NewSecretsClientWithConfigurationProvider(p.configProvider)
+	if err != nil {
+		return "", fmt.Errorf("failed to create secrets client: %w", err)
+	}
+	p.instrument(&client.BaseClient)
+	if region := secretRegion(secretID); region != "" {
+		client.SetRegion(region)
+	}
+	resp, err := client.GetSecretBundle(ctx, secrets.GetSecretBundleRequest{SecretId: &secretID})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret bundle: %w", err)
+	}
+	content, ok := resp.SecretBundleContent.(secrets.Base64SecretBundleContentDetails)
+	if !ok || content.Content == nil {
+		return "", fmt.Errorf("secret %s has no content", secretID)
+	}
+	data, err := base64.StdEncoding.DecodeString(*content.Content)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret content: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,83 @@
+// Package common provides the detection of image and migration host architectures.
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// binfmtDir is the binfmt_misc mount point of the host. Overridable in tests.
+var binfmtDir = "/proc/sys/fs/binfmt_misc"
+
+var inspectorArch = regexp.MustCompile(`<arch>([^<]+)</arch>`)
+
+// normalizeArch returns the libguestfs name of an architecture (x86_64 or aarch64).
+func normalizeArch(arch string) string {
+	switch strings.ToLower(strings.TrimSpace(arch)) {
+	case "amd64", "x86-64", "x86_64":
+		return "x86_64"
+	case "arm64", "aarch64":
+		return "aarch64"
+	}
+	return strings.ToLower(strings.TrimSpace(arch))
+}
+
+// HostArchitecture returns the architecture of the migration host.
+func HostArchitecture() string {
+	return normalizeArch(runtime.GOARCH)
+}
+
+// GuestArchitecture returns the architecture of the operating system in imageFile, as
+// detected by virt-inspector.
+func GuestArchitecture(imageFile, luksKey string) (string, error) {
+	output, err := RunCommand("sudo", guestfsToolArgs("virt-inspector", imageFile, luksKey)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image: %w\nOutput: %s", err, output)
+	}
+	m := inspectorArch.FindStringSubmatch(output)
+	if m == nil {
+		return "", fmt.Errorf("failed to detect the architecture of the image")
+	}
+	return normalizeArch(m[1]), nil
+}
+
+// BinfmtEmulation reports whether qemu-user-static is registered with binfmt_misc for
+// arch with the fix-binary (F) flag, which lets libguestfs run binaries of that
+// architecture inside the image.
+func BinfmtEmulation(arch string) bool {
+	data, err := os.ReadFile(filepath.Join(binfmtDir, "qemu-"+arch)) // #nosec G304 -- binfmt_misc entry
+	if err != nil || !strings.HasPrefix(string(data), "enabled") {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if flags, found := strings.CutPrefix(line, "flags:"); found {
+			return strings.Contains(flags, "F")
+		}
+	}
+	return false
+}
+
+// firstbootArgs rewrites the virt-customize operations that run commands inside the
+// guest to their first-boot equivalents, for images whose architecture the migration
+// host cannot execute.
+func firstbootArgs(args []string) []string {
+	deferred := map[string]string{
+		"--run-command": "--firstboot-command",
+		"--run":         "--firstboot",
+		"--install":     "--firstboot-install",
+	}
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if op, ok := deferred[args[i]]; ok && i+1 < len(args) {
+			out = append(out, op, args[i+1])
+			i++
+			continue
+		}
+		out = append(out, args[i])
+	}
+	return out
+}
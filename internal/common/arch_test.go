@@ -0,0 +1,54 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeArch(t *testing.T) {
+	tests := map[string]string{"amd64": "x86_64", "x86-64": "x86_64", "x86_64": "x86_64", "arm64": "aarch64", "AArch64": "aarch64", "ppc64le": "ppc64le"}
+	for arch, want := range tests {
+		if got := normalizeArch(arch); got != want {
+			t.Errorf("normalizeArch(%q) = %q, want %q", arch, got, want)
+		}
+	}
+}
+
+func TestBinfmtEmulation(t *testing.T) {
+	dir := t.TempDir()
+	orig := binfmtDir
+	binfmtDir = dir
+	t.Cleanup(func() { binfmtDir = orig })
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "qemu-aarch64"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if BinfmtEmulation("aarch64") {
+		t.Error("Expected no emulation without a binfmt_misc entry")
+	}
+	write("enabled\ninterpreter /usr/libexec/qemu-binfmt/aarch64-binfmt-P\nflags: POCF\noffset 0\n")
+	if !BinfmtEmulation("aarch64") {
+		t.Error("Expected emulation with an enabled entry with the F flag")
+	}
+	write("enabled\ninterpreter /usr/bin/qemu-aarch64\nflags: OC\n")
+	if BinfmtEmulation("aarch64") {
+		t.Error("Expected no emulation without the F flag")
+	}
+	write("disabled\nflags: F\n")
+	if BinfmtEmulation("aarch64") {
+		t.Error("Expected no emulation with a disabled entry")
+	}
+}
+
+func TestFirstbootArgs(t *testing.T) {
+	args := []string{"--edit", "/etc/motd:s/a/b/", "--install", "oci-utils", "--run-command", "systemctl enable x", "--run", "/tmp/fix.sh", "--firstboot-command", "echo"}
+	want := []string{"--edit", "/etc/motd:s/a/b/", "--firstboot-install", "oci-utils", "--firstboot-command", "systemctl enable x", "--firstboot", "/tmp/fix.sh", "--firstboot-command", "echo"}
+	if got := firstbootArgs(args); !reflect.DeepEqual(got, want) {
+		t.Errorf("firstbootArgs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+// Package common provides the boot beacon baked into images to report their first boot in OCI.
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// Guest paths of the boot beacon.
+const (
+	BootBeaconScriptPath = "/usr/local/sbin/kopru-beacon"
+	BootBeaconConfigPath = "/etc/kopru/beacon.conf"
+	BootBeaconUnitPath   = "/etc/systemd/system/kopru-beacon.service"
+)
+
+// bootBeaconScript reads the instance OCID from the OCI instance metadata service, waits
+// for cloud-init to finish and sends the beacon to the configured URL. It runs once: on
+// success it deletes its configuration, which holds the upload URL, and disables itself.
+const bootBeaconScript = `#!/bin/sh
+# Kopru boot beacon: reports the first boot of the migrated instance in OCI.
+. ` + BootBeaconConfigPath + ` || exit 0
+for i in $(seq 1 30); do
+    id=$(curl -sf -H "Authorization: Bearer Oracle" http://169.254.169.254/opc/v2/instance/id) && break
+    sleep 10
+done
+[ -n "$id" ] || { echo "kopru-beacon: instance metadata service not reachable"; exit 1; }
+status=none
+if command -v cloud-init >/dev/null 2>&1; then
+    timeout 900 cloud-init status --wait >/dev/null 2>&1
+    status=$(cloud-init status 2>/dev/null | sed -n 's/^status: *//p')
+fi
+boot=$(sed -n 's/^btime //p' /proc/stat)
+body=$(printf '{"instanceId":"%s","bootTime":%s,"cloudInitStatus":"%s","hostname":"%s","kernel":"%s"}' \
+    "$id" "${boot:-0}" "${status:-unknown}" "$(hostname)" "$(uname -r)")
+for i in $(seq 1 30); do
+    if curl -sf -X "$METHOD" -H "Content-Type: application/json" --data "$body" "$URL" >/dev/null; then
+        rm -f ` + BootBeaconConfigPath + `
+        systemctl disable kopru-beacon.service >/dev/null 2>&1
+        echo "kopru-beacon: boot reported for $id"
+        exit 0
+    fi
+    sleep 10
+done
+echo "kopru-beacon: failed to send the boot beacon"
+exit 1
+`
+
+const bootBeaconUnit = `[Unit]
+Description=Kopru boot beacon
+Wants=network-online.target
+After=network-online.target
+ConditionPathExists=` + BootBeaconConfigPath + `
+
+[Service]
+Type=simple
+ExecStart=` + BootBeaconScriptPath + `
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// bootBeaconConfig returns the beacon configuration sourced by the beacon script.
+func bootBeaconConfig(url, method string) string {
+	return fmt.Sprintf("URL='%s'\nMETHOD='%s'\n", strings.ReplaceAll(url, "'", `'\''`), method)
+}
+
+// bootBeaconArgs returns the virt-customize operations that install the boot beacon.
+// Files are only written and linked, so no command runs inside the guest.
+func bootBeaconArgs(url, method string) []string {
+	return []string{
+		"--mkdir", "/etc/kopru",
+		"--mkdir", "/etc/systemd/system/multi-user.target.wants",
+		"--write", BootBeaconScriptPath + ":" + bootBeaconScript,
+		"--chmod", "0755:" + BootBeaconScriptPath,
+		"--write", BootBeaconConfigPath + ":" + bootBeaconConfig(url, method),
+		"--chmod", "0600:" + BootBeaconConfigPath,
+		"--write", BootBeaconUnitPath + ":" + bootBeaconUnit,
+		"--link", BootBeaconUnitPath + ":/etc/systemd/system/multi-user.target.wants/kopru-beacon.service",
+	}
+}
+
+// InstallBootBeacon installs a one-shot systemd service into imageFile that, on the
+// first boot of the instance, sends its OCID, boot time and cloud-init status as JSON to
+// url with the HTTP method. It needs curl in the guest and no credentials; url is
+// typically an Object Storage pre-authenticated request. The service must be installed
+// before the OS configuration, which relabels SELinux guests.
+func InstallBootBeacon(imageFile, url, method, luksKey string, log *logger.Logger) error {
+	log.Info("Installing boot beacon ...")
+	args := append(guestfsToolArgs("virt-customize", imageFile, luksKey), bootBeaconArgs(url, method)...)
+	if output, err := RunCommand("sudo", args...); err != nil {
+		return fmt.Errorf("failed to install boot beacon: %w\nOutput: %s", err, output)
+	}
+	log.Success("Boot beacon installed")
+	return nil
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBootBeaconArgs(t *testing.T) {
+	args := bootBeaconArgs("https://objectstorage.example.com/p/abc/n/ns/b/kopru/o/kopru-beacons/web01.json", "PUT")
+	for _, op := range []string{"--run-command", "--firstboot-command", "--run"} {
+		if slices.Contains(args, op) {
+			t.Errorf("bootBeaconArgs() runs commands in the guest: %v", args)
+		}
+	}
+	joined := strings.Join(args, "\n")
+	for _, want := range []string{
+		BootBeaconScriptPath + ":#!/bin/sh",
+		BootBeaconConfigPath + ":URL='https://objectstorage.example.com/p/abc/n/ns/b/kopru/o/kopru-beacons/web01.json'\nMETHOD='PUT'",
+		"0600:" + BootBeaconConfigPath,
+		BootBeaconUnitPath + ":/etc/systemd/system/multi-user.target.wants/kopru-beacon.service",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("bootBeaconArgs() missing %q", want)
+		}
+	}
+}
+
+func TestBootBeaconConfigQuoting(t *testing.T) {
+	got := bootBeaconConfig("https://example.com/a'b", "POST")
+	if want := "URL='https://example.com/a'\\''b'\nMETHOD='POST'\n"; got != want {
+		t.Errorf("bootBeaconConfig() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,260 @@
+// Package common provides the pre-boot validation of configured images under QEMU/KVM.
+package common
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+const (
+	bootCheckMemoryMB     = 2048
+	bootCheckPollInterval = 2 * time.Second
+	bootCheckConsoleLines = 20 // Console lines included in boot check errors
+)
+
+var (
+	// userlandMarkers are serial console messages that show the guest reached userland.
+	userlandMarkers = []string{" login:", "Reached target Multi-User System", "Reached target multi-user.target"}
+	// bootFailureMarkers are serial console messages that show the guest cannot boot.
+	bootFailureMarkers = []string{"Kernel panic", "Entering emergency mode", "You are in emergency mode", "dracut-initqueue: Warning: Could not boot", "grub rescue>", "Boot failed"}
+
+	// uefiFirmwareFiles are the locations of the OVMF/AAVMF firmware of common distributions.
+	uefiFirmwareFiles = map[string][]string{
+		"amd64": {"/usr/share/OVMF/OVMF_CODE.fd", "/usr/share/ovmf/OVMF.fd", "/usr/share/edk2/ovmf/OVMF_CODE.fd", "/usr/share/qemu/ovmf-x86_64.bin"},
+		"arm64": {"/usr/share/AAVMF/AAVMF_CODE.fd", "/usr/share/qemu-efi-aarch64/QEMU_EFI.fd", "/usr/share/edk2/aarch64/QEMU_EFI-pflash.raw", "/usr/share/qemu/aavmf-aarch64-code.bin"},
+	}
+)
+
+// QEMUSystemCommand returns the QEMU system emulator for the architecture of the host.
+func QEMUSystemCommand() string {
+	if runtime.GOARCH == "arm64" {
+		return "qemu-system-aarch64"
+	}
+	return "qemu-system-x86_64"
+}
+
+// BootCheckImage boots imageFile headless under QEMU, using KVM when available, and
+// waits until the guest reaches userland on its serial console and acquires an address
+// from the QEMU DHCP server. Changes made by the guest are discarded. The serial console
+// log and network capture are kept in workDir for troubleshooting.
+func BootCheckImage(ctx context.Context, imageFile string, uefi bool, timeout time.Duration, workDir string, log *logger.Logger) error {
+	consoleFile := filepath.Join(workDir, "boot-check-console.log")
+	captureFile := filepath.Join(workDir, "boot-check-network.pcap")
+	for _, f := range []string{consoleFile, captureFile} {
+		if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove previous boot check output: %w", err)
+		}
+	}
+	args, err := bootCheckArgs(imageFile, consoleFile, captureFile, uefi)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// #nosec G204 -- the image file is produced by the workflow
+	cmd := exec.CommandContext(ctx, QEMUSystemCommand(), args...)
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	cmd.WaitDelay = 10 * time.Second
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", QEMUSystemCommand(), err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	stop := func() {
+		cancel()
+		<-exited
+	}
+
+	log.Infof("Booting image under QEMU (timeout %s, console log %s)...", timeout, consoleFile)
+	var userland, dhcp bool
+	ticker := time.NewTicker(bootCheckPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-exited:
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return bootCheckTimeoutError(timeout, userland, dhcp, consoleFile)
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("QEMU exited before the guest finished booting (%v): %s%s", err, strings.TrimSpace(stderr.String()), consoleTail(consoleFile))
+		case <-ticker.C:
+		}
+
+		console, _ := os.ReadFile(consoleFile) // #nosec G304 -- written by QEMU in workDir
+		if failure := bootFailure(string(console)); failure != "" {
+			stop()
+			return fmt.Errorf("guest failed to boot (%s)%s", failure, consoleTail(consoleFile))
+		}
+		if !userland && reachedUserland(string(console)) {
+			userland = true
+			log.Success("✓ Guest reached userland")
+		}
+		capture, _ := os.ReadFile(captureFile) // #nosec G304 -- written by QEMU in workDir
+		if !dhcp && dhcpAcknowledged(capture) {
+			dhcp = true
+			log.Success("✓ Guest acquired a DHCP lease")
+		}
+		if userland && dhcp {
+			stop()
+			return nil
+		}
+	}
+}
+
+// bootCheckArgs returns the QEMU arguments for a headless, throw-away boot of imageFile
+// with a virtio disk and NIC, as used by OCI paravirtualized instances.
+func bootCheckArgs(imageFile, consoleFile, captureFile string, uefi bool) ([]string, error) {
+	machine := "q35"
+	if runtime.GOARCH == "arm64" {
+		machine, uefi = "virt", true
+	}
+	args := []string{
+		"-machine", machine, "-accel", "kvm", "-accel", "tcg", "-cpu", "max",
+		"-smp", "2", "-m", fmt.Sprint(bootCheckMemoryMB),
+		"-display", "none", "-monitor", "none", "-no-reboot",
+		"-serial", "file:" + consoleFile,
+		"-drive", "file=" + imageFile + ",if=virtio,format=qcow2,snapshot=on",
+		"-netdev", "user,id=net0",
+		"-device", "virtio-net-pci,netdev=net0",
+		"-object", "filter-dump,id=dump0,netdev=net0,file=" + captureFile,
+	}
+	if uefi {
+		firmware, err := findUEFIFirmware()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "-drive", "if=pflash,format=raw,readonly=on,file="+firmware)
+	}
+	return args, nil
+}
+
+func findUEFIFirmware() (string, error) {
+	for _, f := range uefiFirmwareFiles[runtime.GOARCH] {
+		if _, err := os.Stat(f); err == nil {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("UEFI firmware not found; install the OVMF (x86_64) or AAVMF (aarch64) package")
+}
+
+func bootCheckTimeoutError(timeout time.Duration, userland, dhcp bool, consoleFile string) error {
+	var missing []string
+	if !userland {
+		missing = append(missing, "reach userland (is console=ttyS0 set on the kernel command line?)")
+	}
+	if !dhcp {
+		missing = append(missing, "acquire a DHCP lease")
+	}
+	return fmt.Errorf("guest did not %s within %s%s", strings.Join(missing, " or "), timeout, consoleTail(consoleFile))
+}
+
+// consoleTail returns the last lines of the serial console log, formatted for an error message.
+func consoleTail(consoleFile string) string {
+	data, err := os.ReadFile(consoleFile) // #nosec G304 -- written by QEMU in workDir
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) > bootCheckConsoleLines {
+		lines = lines[len(lines)-bootCheckConsoleLines:]
+	}
+	return "\nLast console output:\n" + strings.Join(lines, "\n")
+}
+
+func reachedUserland(console string) bool {
+	for _, marker := range userlandMarkers {
+		if strings.Contains(console, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// bootFailure returns the first boot failure marker found in the console output.
+func bootFailure(console string) string {
+	for _, marker := range bootFailureMarkers {
+		if strings.Contains(console, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// dhcpAcknowledged reports whether a pcap capture contains a DHCPACK sent to the guest.
+func dhcpAcknowledged(capture []byte) bool {
+	const (
+		pcapHeaderLen   = 24
+		recordHeaderLen = 16
+	)
+	if len(capture) < pcapHeaderLen {
+		return false
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if binary.BigEndian.Uint32(capture) == 0xa1b2c3d4 {
+		order = binary.BigEndian
+	}
+	for off := pcapHeaderLen; off+recordHeaderLen <= len(capture); {
+		n := int(order.Uint32(capture[off+8:]))
+		off += recordHeaderLen
+		if off+n > len(capture) {
+			return false // Record still being written
+		}
+		if isDHCPAck(capture[off : off+n]) {
+			return true
+		}
+		off += n
+	}
+	return false
+}
+
+// isDHCPAck reports whether an Ethernet frame carries a DHCPACK message.
+func isDHCPAck(frame []byte) bool {
+	const ethHeaderLen, udpHeaderLen, bootpHeaderLen = 14, 8, 236
+	if len(frame) < ethHeaderLen+20 || binary.BigEndian.Uint16(frame[12:]) != 0x0800 {
+		return false
+	}
+	ip := frame[ethHeaderLen:]
+	ihl := int(ip[0]&0x0f) * 4
+	if ip[9] != 17 || len(ip) < ihl+udpHeaderLen {
+		return false
+	}
+	udp := ip[ihl:]
+	if binary.BigEndian.Uint16(udp[0:]) != 67 || binary.BigEndian.Uint16(udp[2:]) != 68 {
+		return false
+	}
+	bootp := udp[udpHeaderLen:]
+	if len(bootp) < bootpHeaderLen+4 || binary.BigEndian.Uint32(bootp[bootpHeaderLen:]) != 0x63825363 {
+		return false
+	}
+	options := bootp[bootpHeaderLen+4:]
+	for i := 0; i < len(options) && options[i] != 0xff; {
+		if options[i] == 0 {
+			i++
+			continue
+		}
+		if i+1 >= len(options) || i+2+int(options[i+1]) > len(options) {
+			return false
+		}
+		if options[i] == 53 && options[i+1] == 1 {
+			return options[i+2] == 5 // DHCP message type ACK
+		}
+		i += 2 + int(options[i+1])
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// dhcpFrame returns an Ethernet frame carrying a DHCP message of the given type.
+func dhcpFrame(srcPort, dstPort uint16, messageType byte) []byte {
+	frame := make([]byte, 14+20+8+236)
+	binary.BigEndian.PutUint16(frame[12:], 0x0800)
+	ip := frame[14:]
+	ip[0] = 0x45
+	ip[9] = 17
+	binary.BigEndian.PutUint16(ip[20:], srcPort)
+	binary.BigEndian.PutUint16(ip[22:], dstPort)
+	frame = binary.BigEndian.AppendUint32(frame, 0x63825363)
+	return append(frame, 53, 1, messageType, 0xff)
+}
+
+func pcapCapture(frames ...[]byte) []byte {
+	capture := binary.LittleEndian.AppendUint32(nil, 0xa1b2c3d4)
+	capture = append(capture, make([]byte, 20)...)
+	for _, f := range frames {
+		capture = append(capture, make([]byte, 8)...)
+		capture = binary.LittleEndian.AppendUint32(capture, uint32(len(f)))
+		capture = binary.LittleEndian.AppendUint32(capture, uint32(len(f)))
+		capture = append(capture, f...)
+	}
+	return capture
+}
+
+func TestDHCPAcknowledged(t *testing.T) {
+	discover := dhcpFrame(68, 67, 1)
+	offer := dhcpFrame(67, 68, 2)
+	ack := dhcpFrame(67, 68, 5)
+	tests := []struct {
+		name    string
+		capture []byte
+		want    bool
+	}{
+		{"empty", nil, false},
+		{"no packets", pcapCapture(), false},
+		{"discover and offer", pcapCapture(discover, offer), false},
+		{"ack", pcapCapture(discover, offer, dhcpFrame(68, 67, 3), ack), true},
+		{"truncated record", pcapCapture(discover, ack)[:24+16+len(discover)+16+10], false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dhcpAcknowledged(tt.capture); got != tt.want {
+				t.Errorf("dhcpAcknowledged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBootConsoleMarkers(t *testing.T) {
+	booted := "[  OK  ] Reached target Multi-User System.\nUbuntu 24.04 LTS vm ttyS0\n\nvm login: "
+	if !reachedUserland(booted) || bootFailure(booted) != "" {
+		t.Errorf("Expected userland without failure for %q", booted)
+	}
+	panicked := "[    2.1] Kernel panic - not syncing: VFS: Unable to mount root fs on unknown-block(0,0)"
+	if reachedUserland(panicked) || bootFailure(panicked) != "Kernel panic" {
+		t.Errorf("Expected kernel panic for %q", panicked)
+	}
+}
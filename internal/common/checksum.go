@@ -0,0 +1,273 @@
+// Package common provides the checksums that verify the integrity of migrated disks.
+package common
+
+import (
+	"bytes"
+	"crypto/md5" // #nosec G501 -- Object Storage reports MD5 hashes of uploaded objects
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
+	"golang.org/x/sys/unix"
+)
+
+// Data disk verification modes.
+const (
+	VerifySample = "sample" // Compare sampled blocks of the disk
+	VerifyFull   = "full"   // Compare the SHA-256 of the whole disk
+	VerifyOff    = "off"    // Do not read back the disk
+)
+
+const (
+	verifySamples     = 64
+	verifySampleBytes = 1024 * 1024
+)
+
+// FileHashes are the hashes of a file computed in one pass.
+type FileHashes struct {
+	SHA256    string // Hex-encoded SHA-256
+	ObjectMD5 string // MD5 as reported by Object Storage for an upload in parts of the given size
+}
+
+// objectMD5 computes the MD5 that Object Storage reports for an object: the base64 MD5 of
+// the content for a single-part upload, or the base64 MD5 of the concatenated part MD5s
+// followed by "-<parts>" for a multipart upload.
+type objectMD5 struct {
+	partSize, size int64
+	whole, part    hash.Hash
+	partLen        int64
+	partSums       []byte
+	parts          int
+}
+
+func newObjectMD5(partSize, size int64) *objectMD5 {
+	return &objectMD5{partSize: partSize, size: size, whole: md5.New(), part: md5.New()} // #nosec G401
+}
+
+func (o *objectMD5) Write(p []byte) (int, error) {
+	n := len(p)
+	o.whole.Write(p)
+	for len(p) > 0 {
+		chunk := min(int64(len(p)), o.partSize-o.partLen)
+		o.part.Write(p[:chunk])
+		o.partLen += chunk
+		p = p[chunk:]
+		if o.partLen == o.partSize {
+			o.endPart()
+		}
+	}
+	return n, nil
+}
+
+func (o *objectMD5) endPart() {
+	o.partSums = o.part.Sum(o.partSums)
+	o.parts++
+	o.part.Reset()
+	o.partLen = 0
+}
+
+func (o *objectMD5) String() string {
+	// Files up to one part are uploaded with a single PutObject request.
+	if o.size <= o.partSize {
+		return base64.StdEncoding.EncodeToString(o.whole.Sum(nil))
+	}
+	if o.partLen > 0 {
+		o.endPart()
+	}
+	sum := md5.Sum(o.partSums) // #nosec G401
+	return fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(sum[:]), o.parts)
+}
+
+// HashFile computes the SHA-256 of a file and, when partSize is positive, the MD5 Object
+// Storage reports after uploading it in parts of partSize bytes.
+func HashFile(path string, partSize int64, log *logger.Logger) (FileHashes, error) {
+	f, err := os.Open(path) // #nosec G304 -- the file is produced by the workflow
+	if err != nil {
+		return FileHashes{}, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return FileHashes{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	rep := progress.New(log, "Hashing "+filepath.Base(path), info.Size())
+	defer rep.Done()
+	sha := sha256.New()
+	writers := []io.Writer{sha, rep}
+	var om *objectMD5
+	if partSize > 0 {
+		om = newObjectMD5(partSize, info.Size())
+		writers = append(writers, om)
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), f); err != nil {
+		return FileHashes{}, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	hashes := FileHashes{SHA256: hex.EncodeToString(sha.Sum(nil))}
+	if om != nil {
+		hashes.ObjectMD5 = om.String()
+	}
+	return hashes, nil
+}
+
+// SHA256File returns the hex-encoded SHA-256 of a file.
+func SHA256File(path string, log *logger.Logger) (string, error) {
+	hashes, err := HashFile(path, 0, log)
+	return hashes.SHA256, err
+}
+
+// CompareImages checks with qemu-img compare that two disk images have the same guest
+// visible content, e.g. a VHD and the QCOW2 it was converted to.
+func CompareImages(image1, format1, image2, format2 string) error {
+	if output, err := RunCommand("qemu-img", "compare", "-f", format1, "-F", format2, image1, image2); err != nil {
+		return fmt.Errorf("%s and %s differ: %w\nOutput: %s", filepath.Base(image1), filepath.Base(image2), err, output)
+	}
+	return nil
+}
+
+// VerifyDeviceCopy checks that the device holds the content of source, a RAW image or an
+// NBD device. The device is read with O_DIRECT, so that the data comes from the volume
+// rather than from pages cached when it was written. In VerifyFull mode the SHA-256 of
+// the whole image is compared with that of the same number of bytes read from the
+// device, and returned. In VerifySample mode the first, last and evenly spaced 1 MiB
+// blocks are compared.
+func VerifyDeviceCopy(source, device, mode string, log *logger.Logger) (string, error) {
+	src, err := os.Open(source) // #nosec G304 -- the file is produced by the workflow
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", source, err)
+	}
+	defer src.Close()
+	dst, err := openDirect(device)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", device, err)
+	}
+	defer dst.Close()
+	// Seek rather than stat, as the source may be a block device.
+	size, err := src.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the size of %s: %w", source, err)
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to rewind %s: %w", source, err)
+	}
+
+	if mode == VerifyFull {
+		rep := progress.New(log, "Verifying "+filepath.Base(device), 2*size)
+		defer rep.Done()
+		srcSum, dstSum := sha256.New(), sha256.New()
+		if _, err := io.Copy(io.MultiWriter(srcSum, rep), src); err != nil {
+			return "", fmt.Errorf("failed to hash %s: %w", source, err)
+		}
+		buf := make([]byte, directReadBytes)
+		if _, err := io.CopyBuffer(io.MultiWriter(dstSum, rep), io.NewSectionReader(dst, 0, size), buf); err != nil {
+			return "", fmt.Errorf("failed to hash %s: %w", device, err)
+		}
+		if !bytes.Equal(srcSum.Sum(nil), dstSum.Sum(nil)) {
+			return "", fmt.Errorf("SHA-256 of %s does not match %s", device, filepath.Base(source))
+		}
+		return hex.EncodeToString(srcSum.Sum(nil)), nil
+	}
+
+	srcBuf, dstBuf := make([]byte, verifySampleBytes), make([]byte, verifySampleBytes)
+	for _, off := range sampleOffsets(size, verifySampleBytes, verifySamples) {
+		n := min(int64(verifySampleBytes), size-off)
+		if _, err := src.ReadAt(srcBuf[:n], off); err != nil {
+			return "", fmt.Errorf("failed to read %s at offset %d: %w", source, off, err)
+		}
+		if _, err := dst.ReadAt(dstBuf[:n], off); err != nil {
+			return "", fmt.Errorf("failed to read %s at offset %d: %w", device, off, err)
+		}
+		if !bytes.Equal(srcBuf[:n], dstBuf[:n]) {
+			return "", fmt.Errorf("%s differs from %s at offset %d", device, filepath.Base(source), off)
+		}
+	}
+	return "", nil
+}
+
+// directAlignment is the alignment of the offsets, lengths and buffers of O_DIRECT reads,
+// a multiple of the logical block size of the devices.
+const directAlignment = 4096
+
+// directReadBytes is the size of the reads of a whole device with O_DIRECT, which is
+// not read ahead.
+const directReadBytes = 4 * 1024 * 1024
+
+// directReader reads a file with O_DIRECT, bypassing the page cache. ReadAt accepts any
+// offset and length: it reads the enclosing aligned range into an aligned buffer.
+type directReader struct {
+	f   *os.File
+	buf []byte
+}
+
+// openDirect opens path for reading with O_DIRECT.
+func openDirect(path string) (*directReader, error) {
+	f, err := os.OpenFile(path, os.O_RDONLY|unix.O_DIRECT, 0) // #nosec G304 -- the device is attached by the workflow
+	if err != nil {
+		return nil, err
+	}
+	return &directReader{f: f}, nil
+}
+
+// ReadAt implements io.ReaderAt. It is not safe for concurrent use.
+func (r *directReader) ReadAt(p []byte, off int64) (int, error) {
+	start := off &^ (directAlignment - 1)
+	end := (off + int64(len(p)) + directAlignment - 1) &^ (directAlignment - 1)
+	if int64(len(r.buf)) < end-start {
+		r.buf = alignedBuffer(int(end - start))
+	}
+	n, err := r.f.ReadAt(r.buf[:end-start], start)
+	if int64(n) <= off-start {
+		if err == nil {
+			err = io.EOF
+		}
+		return 0, err
+	}
+	copied := copy(p, r.buf[off-start:n])
+	if copied == len(p) {
+		return copied, nil
+	}
+	if err == nil {
+		err = io.EOF
+	}
+	return copied, err
+}
+
+// Close closes the file.
+func (r *directReader) Close() error {
+	return r.f.Close()
+}
+
+// alignedBuffer returns a buffer of size bytes that starts at a multiple of directAlignment.
+func alignedBuffer(size int) []byte {
+	buf := make([]byte, size+directAlignment)
+	shift := (directAlignment - int(uintptr(unsafe.Pointer(&buf[0]))%directAlignment)) % directAlignment // #nosec G103
+	return buf[shift : shift+size]
+}
+
+// sampleOffsets returns the offsets of count blocks of blockSize spread evenly over size
+// bytes, including the first and the last block.
+func sampleOffsets(size, blockSize int64, count int) []int64 {
+	if size <= 0 {
+		return nil
+	}
+	last := max(size-blockSize, 0)
+	if count < 2 || last == 0 {
+		return []int64{0}
+	}
+	offsets := make([]int64, 0, count)
+	for i := range int64(count) {
+		off := last * i / int64(count-1)
+		if len(offsets) > 0 && off == offsets[len(offsets)-1] {
+			continue
+		}
+		offsets = append(offsets, off)
+	}
+	return offsets
+}
@@ -0,0 +1,108 @@
+package common
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestHashFile(t *testing.T) {
+	data := []byte("0123456789abcdefghij") // 20 bytes: parts of 8, 8 and 4 bytes
+	path := filepath.Join(t.TempDir(), "image.qcow2")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	part := func(b []byte) []byte { sum := md5.Sum(b); return sum[:] }
+	var parts []byte
+	for _, p := range [][]byte{data[:8], data[8:16], data[16:]} {
+		parts = append(parts, part(p)...)
+	}
+	tests := []struct {
+		partSize int64
+		want     string
+	}{
+		{8, base64.StdEncoding.EncodeToString(part(parts)) + "-3"},
+		{20, base64.StdEncoding.EncodeToString(part(data))},
+		{64, base64.StdEncoding.EncodeToString(part(data))},
+		{0, ""},
+	}
+	for _, tt := range tests {
+		hashes, err := HashFile(path, tt.partSize, logger.New(false))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hashes.ObjectMD5 != tt.want {
+			t.Errorf("HashFile(partSize %d) object MD5 = %q, want %q", tt.partSize, hashes.ObjectMD5, tt.want)
+		}
+		if want := "6bc14bdc4517a7a682c6910de2e2946eb8e1ecd04090728fef6d092a7ceb62c5"; hashes.SHA256 != want {
+			t.Errorf("HashFile() SHA-256 = %q, want %q", hashes.SHA256, want)
+		}
+	}
+}
+
+func TestSampleOffsets(t *testing.T) {
+	tests := []struct {
+		size, block int64
+		count       int
+		want        []int64
+	}{
+		{0, 4, 3, nil},
+		{3, 4, 3, []int64{0}},
+		{12, 4, 3, []int64{0, 4, 8}},
+		{10, 4, 3, []int64{0, 3, 6}},
+		{6, 4, 8, []int64{0, 1, 2}},
+	}
+	for _, tt := range tests {
+		if got := sampleOffsets(tt.size, tt.block, tt.count); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sampleOffsets(%d, %d, %d) = %v, want %v", tt.size, tt.block, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestDirectReaderReadAt(t *testing.T) {
+	data := make([]byte, 3*directAlignment+100)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	path := filepath.Join(t.TempDir(), "volume.raw")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := openDirect(path)
+	if err != nil {
+		t.Skipf("O_DIRECT is not supported here: %v", err)
+	}
+	defer r.Close()
+
+	tests := []struct {
+		off, length int64
+		wantN       int64
+		wantEOF     bool
+	}{
+		{0, directAlignment, directAlignment, false},
+		{10, 100, 100, false},
+		{directAlignment - 5, 10, 10, false},
+		{int64(len(data)) - 50, 50, 50, false},
+		{int64(len(data)) - 50, 80, 50, true},
+		{int64(len(data)) + 10, 10, 0, true},
+	}
+	for _, tt := range tests {
+		p := make([]byte, tt.length)
+		n, err := r.ReadAt(p, tt.off)
+		if int64(n) != tt.wantN || (err == io.EOF) != tt.wantEOF || (err != nil && err != io.EOF) {
+			t.Errorf("ReadAt(%d bytes at %d) = %d, %v, want %d bytes, EOF %t", tt.length, tt.off, n, err, tt.wantN, tt.wantEOF)
+			continue
+		}
+		if want := data[min(tt.off, int64(len(data))):][:n]; !bytes.Equal(p[:n], want) {
+			t.Errorf("ReadAt(%d bytes at %d) read the wrong bytes", tt.length, tt.off)
+		}
+	}
+}
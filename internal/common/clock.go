@@ -0,0 +1,63 @@
+package common
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	ntpPacketSize = 48
+	ntpTimeout    = 5 * time.Second
+	ntpEpochDelta = 2208988800 // Seconds between the NTP epoch (1900) and the Unix epoch (1970)
+)
+
+// ClockOffset queries an NTP server with a single SNTP request and returns the offset
+// of the local clock from the server's clock; a positive offset means the local clock
+// is ahead. server is a host name or host:port (default port 123).
+func ClockOffset(ctx context.Context, server string) (time.Duration, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "123")
+	}
+	ctx, cancel := context.WithTimeout(ctx, ntpTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to NTP server %s: %w", server, err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	req := make([]byte, ntpPacketSize)
+	req[0] = 0x23 // LI = 0, version 4, mode 3 (client)
+	sent := time.Now()
+	if _, err := conn.Write(req); err != nil {
+		return 0, fmt.Errorf("failed to query NTP server %s: %w", server, err)
+	}
+	resp := make([]byte, ntpPacketSize)
+	n, err := conn.Read(resp)
+	received := time.Now()
+	if err != nil {
+		return 0, fmt.Errorf("no response from NTP server %s: %w", server, err)
+	}
+	if n < ntpPacketSize || resp[0]&0x07 != 4 {
+		return 0, fmt.Errorf("invalid response from NTP server %s", server)
+	}
+
+	// Offset = ((T2 - T1) + (T3 - T4)) / 2 with T2/T3 the server receive/transmit times.
+	serverReceive := ntpTime(resp[32:40])
+	serverTransmit := ntpTime(resp[40:48])
+	return (sent.Sub(serverReceive) + received.Sub(serverTransmit)) / 2, nil
+}
+
+// ntpTime decodes a 64-bit NTP timestamp.
+func ntpTime(b []byte) time.Time {
+	seconds := int64(binary.BigEndian.Uint32(b[0:4])) - ntpEpochDelta
+	fraction := int64(binary.BigEndian.Uint32(b[4:8]))
+	return time.Unix(seconds, fraction*int64(time.Second)>>32)
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// serveNTP answers a single SNTP request with the local time shifted by skew.
+func serveNTP(t *testing.T, skew time.Duration) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		req := make([]byte, ntpPacketSize)
+		_, addr, err := conn.ReadFrom(req)
+		if err != nil {
+			return
+		}
+		resp := make([]byte, ntpPacketSize)
+		resp[0] = 0x24 // version 4, mode 4 (server)
+		now := time.Now().Add(skew)
+		putNTPTime(resp[32:40], now)
+		putNTPTime(resp[40:48], now)
+		_, _ = conn.WriteTo(resp, addr)
+	}()
+	return conn.LocalAddr().String()
+}
+
+func putNTPTime(b []byte, t time.Time) {
+	binary.BigEndian.PutUint32(b[0:4], uint32(t.Unix()+ntpEpochDelta))
+	binary.BigEndian.PutUint32(b[4:8], uint32((int64(t.Nanosecond())<<32)/int64(time.Second)))
+}
+
+func TestClockOffset(t *testing.T) {
+	server := serveNTP(t, -3*time.Minute)
+	offset, err := ClockOffset(context.Background(), server)
+	if err != nil {
+		t.Fatalf("ClockOffset() error = %v", err)
+	}
+	if diff := offset - 3*time.Minute; diff.Abs() > time.Second {
+		t.Errorf("ClockOffset() = %v, want about 3m (local clock ahead)", offset)
+	}
+}
+
+func TestNTPTime(t *testing.T) {
+	want := time.Date(2025, 1, 1, 12, 0, 0, 500000000, time.UTC)
+	b := make([]byte, 8)
+	putNTPTime(b, want)
+	if got := ntpTime(b); got.Sub(want).Abs() > time.Microsecond {
+		t.Errorf("ntpTime() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,281 @@
+// Package common provides loading and application of external OS configurators.
+package common
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"go.yaml.in/yaml/v3"
+)
+
+// Configurator describes OS configuration changes applied to an image in addition to
+// the built-in scripts. Configurators are read from YAML files so that teams can add
+// OS support without recompiling Kopru, and are applied with virt-customize.
+type Configurator struct {
+	Name        string            `yaml:"name"`
+	Description string            `yaml:"description"`
+	Match       ConfiguratorMatch `yaml:"match"`
+	Packages    []string          `yaml:"packages"`
+	Edits       []FileEdit        `yaml:"edits"`
+	Renames     []FileRename      `yaml:"renames"`
+	Hooks       []ScriptHook      `yaml:"hooks"`
+
+	path string
+}
+
+// ConfiguratorMatch selects the images a configurator applies to. Empty lists match all.
+type ConfiguratorMatch struct {
+	OSIDs           []string `yaml:"os_ids"`           // ID values from the guest's /etc/os-release
+	SourcePlatforms []string `yaml:"source_platforms"` // e.g. azure, linux_image
+}
+
+// FileEdit changes a file in the guest. Exactly one of Content, Append or Replace is set.
+type FileEdit struct {
+	Path    string `yaml:"path"`
+	Content string `yaml:"content"` // Replaces the file content
+	Append  string `yaml:"append"`  // Appends a line to the file
+	Replace string `yaml:"replace"` // Perl expression applied to each line, e.g. s/^foo/bar/
+}
+
+// FileRename moves a file or directory in the guest.
+type FileRename struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// ScriptHook runs a command or script inside the guest, either during image
+// configuration or, with Firstboot set, on the first boot of the instance.
+type ScriptHook struct {
+	Run       string `yaml:"run"`    // Shell command
+	Script    string `yaml:"script"` // Local script file, relative to the YAML file
+	Firstboot bool   `yaml:"firstboot"`
+}
+
+// DefaultConfiguratorsDir returns the directory searched for configurators when
+// CONFIGURATORS_DIR is not set.
+func DefaultConfiguratorsDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kopru", "configurators")
+}
+
+// LoadConfigurators reads all *.yaml and *.yml configurators in dir, sorted by file name.
+// A missing directory yields no configurators.
+func LoadConfigurators(dir string) ([]Configurator, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list configurators: %w", err)
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	configurators := make([]Configurator, 0, len(files))
+	for _, file := range files {
+		c, err := loadConfigurator(file)
+		if err != nil {
+			return nil, err
+		}
+		configurators = append(configurators, c)
+	}
+	return configurators, nil
+}
+
+func loadConfigurator(file string) (Configurator, error) {
+	// #nosec G304 -- configurators are read from the user's configurators directory
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Configurator{}, fmt.Errorf("failed to read configurator: %w", err)
+	}
+	var c Configurator
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return Configurator{}, fmt.Errorf("invalid configurator %s: %w", file, err)
+	}
+	c.path = file
+	if c.Name == "" {
+		c.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	if err := c.validate(); err != nil {
+		return Configurator{}, fmt.Errorf("invalid configurator %s: %w", file, err)
+	}
+	return c, nil
+}
+
+// ScriptConfigurator returns a configurator that runs the local script inside the guest,
+// for script steps of the configuration chain.
+func ScriptConfigurator(script string) (Configurator, error) {
+	path, err := filepath.Abs(script)
+	if err != nil {
+		return Configurator{}, fmt.Errorf("invalid script %s: %w", script, err)
+	}
+	c := Configurator{Name: filepath.Base(path), Hooks: []ScriptHook{{Script: filepath.Base(path)}}, path: path}
+	if err := c.validate(); err != nil {
+		return Configurator{}, fmt.Errorf("invalid script %s: %w", script, err)
+	}
+	return c, nil
+}
+
+func (c Configurator) validate() error {
+	for i, e := range c.Edits {
+		set := 0
+		for _, v := range []string{e.Content, e.Append, e.Replace} {
+			if v != "" {
+				set++
+			}
+		}
+		if !filepath.IsAbs(e.Path) {
+			return fmt.Errorf("edit %d: path must be absolute", i+1)
+		}
+		if set != 1 {
+			return fmt.Errorf("edit %d: exactly one of content, append or replace must be set", i+1)
+		}
+	}
+	for i, r := range c.Renames {
+		if !filepath.IsAbs(r.From) || !filepath.IsAbs(r.To) {
+			return fmt.Errorf("rename %d: from and to must be absolute paths", i+1)
+		}
+	}
+	for i, h := range c.Hooks {
+		if (h.Run == "") == (h.Script == "") {
+			return fmt.Errorf("hook %d: exactly one of run or script must be set", i+1)
+		}
+		if h.Script != "" {
+			if err := CheckScript(c.scriptPath(h.Script)); err != nil {
+				return fmt.Errorf("hook %d: %w", i+1, err)
+			}
+		}
+	}
+	if len(c.Packages)+len(c.Edits)+len(c.Renames)+len(c.Hooks) == 0 {
+		return fmt.Errorf("no packages, edits, renames or hooks defined")
+	}
+	return nil
+}
+
+func (c Configurator) scriptPath(script string) string {
+	if filepath.IsAbs(script) {
+		return script
+	}
+	return filepath.Join(filepath.Dir(c.path), script)
+}
+
+// Scripts returns the local scripts the hooks of the configurator run inside the guest.
+func (c Configurator) Scripts() []string {
+	var scripts []string
+	for _, h := range c.Hooks {
+		if h.Script != "" {
+			scripts = append(scripts, c.scriptPath(h.Script))
+		}
+	}
+	return scripts
+}
+
+// Matches reports whether the configurator applies to a guest with the given
+// /etc/os-release ID migrated from the given source platform.
+func (c Configurator) Matches(osID, sourcePlatform string) bool {
+	if len(c.Match.OSIDs) > 0 && !slices.Contains(c.Match.OSIDs, osID) {
+		return false
+	}
+	return len(c.Match.SourcePlatforms) == 0 || slices.Contains(c.Match.SourcePlatforms, sourcePlatform)
+}
+
+// customizeArgs returns the virt-customize operations of the configurator, in the
+// order packages, edits, renames, hooks.
+func (c Configurator) customizeArgs() []string {
+	var args []string
+	if len(c.Packages) > 0 {
+		args = append(args, "--install", strings.Join(c.Packages, ","))
+	}
+	for _, e := range c.Edits {
+		switch {
+		case e.Content != "":
+			args = append(args, "--write", e.Path+":"+e.Content)
+		case e.Append != "":
+			args = append(args, "--append-line", e.Path+":"+e.Append)
+		default:
+			args = append(args, "--edit", e.Path+":"+e.Replace)
+		}
+	}
+	for _, r := range c.Renames {
+		args = append(args, "--move", r.From+":"+r.To)
+	}
+	for _, h := range c.Hooks {
+		switch {
+		case h.Run != "" && h.Firstboot:
+			args = append(args, "--firstboot-command", h.Run)
+		case h.Run != "":
+			args = append(args, "--run-command", h.Run)
+		case h.Firstboot:
+			args = append(args, "--firstboot", c.scriptPath(h.Script))
+		default:
+			args = append(args, "--run", c.scriptPath(h.Script))
+		}
+	}
+	return args
+}
+
+// ApplyConfigurators applies the configurators matching the guest OS of imageFile and
+// the source platform, after the built-in OS configuration script has run. LUKS
+// containers are opened with the key selector luksKey when set. With
+// deferGuestCommands, commands, scripts and package installs run on the first boot.
+func ApplyConfigurators(imageFile, sourcePlatform, luksKey string, configurators []Configurator, deferGuestCommands bool, log *logger.Logger) error {
+	if len(configurators) == 0 {
+		return nil
+	}
+	osRelease, err := RunCommand("sudo", append(append([]string{"virt-cat"}, guestfsKeyArgs(luksKey)...), "-a", imageFile, "/etc/os-release")...)
+	if err != nil {
+		return fmt.Errorf("failed to read /etc/os-release from image: %w", err)
+	}
+	osID := osReleaseID(osRelease)
+
+	for _, c := range configurators {
+		if !c.Matches(osID, sourcePlatform) {
+			log.Debugf("Configurator %s does not match OS ID '%s', skipping", c.Name, osID)
+			continue
+		}
+		log.Infof("Applying configurator: %s (%s)", c.Name, c.path)
+		// #nosec G204 -- operations are read from the user's configurators directory
+		args := append(append([]string{"virt-customize"}, guestfsKeyArgs(luksKey)...), "-a", imageFile)
+		ops := c.customizeArgs()
+		if deferGuestCommands {
+			ops = firstbootArgs(ops)
+		}
+		cmd := exec.Command("sudo", append(args, ops...)...)
+		cmd.Env = append(os.Environ(), "LIBGUESTFS_BACKEND=direct")
+		if _, err := runWithProgress(cmd, func(line string) { log.Info(line) }); err != nil {
+			return fmt.Errorf("configurator %s failed: %w", c.Name, err)
+		}
+		log.Successf("Configurator applied: %s", c.Name)
+	}
+	return nil
+}
+
+// osReleaseID returns the ID field of an /etc/os-release file.
+func osReleaseID(osRelease string) string {
+	for _, line := range strings.Split(osRelease, "\n") {
+		if value, found := strings.CutPrefix(strings.TrimSpace(line), "ID="); found {
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfigurator(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigurators(t *testing.T) {
+	dir := t.TempDir()
+	writeConfigurator(t, dir, "20-sles.yml", `
+name: sles-oci
+match:
+  os_ids: [sles]
+edits:
+  - path: /etc/sysconfig/network/dhcp
+    replace: s/^DHCLIENT_SET_HOSTNAME=.*/DHCLIENT_SET_HOSTNAME="yes"/
+renames:
+  - from: /etc/udev/rules.d/70-persistent-net.rules
+    to: /etc/udev/rules.d/70-persistent-net.rules.bak
+hooks:
+  - script: hooks/sles.sh
+  - run: systemctl enable oracle-cloud-agent
+    firstboot: true
+`)
+	writeConfigurator(t, dir, "10-motd.yaml", `
+edits:
+  - path: /etc/motd
+    content: Migrated by Kopru
+  - path: /etc/hosts
+    append: 169.254.169.254 metadata
+`)
+	writeConfigurator(t, dir, "README.md", "not a configurator")
+	if err := os.MkdirAll(filepath.Join(dir, "hooks"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeConfigurator(t, dir, "hooks/sles.sh", "#!/bin/bash\n")
+	if err := os.Chmod(filepath.Join(dir, "hooks/sles.sh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	configurators, err := LoadConfigurators(dir)
+	if err != nil {
+		t.Fatalf("LoadConfigurators() error = %v", err)
+	}
+	if len(configurators) != 2 || configurators[0].Name != "10-motd" || configurators[1].Name != "sles-oci" {
+		t.Fatalf("Unexpected configurators: %+v", configurators)
+	}
+
+	wantMotd := []string{
+		"--write", "/etc/motd:Migrated by Kopru",
+		"--append-line", "/etc/hosts:169.254.169.254 metadata",
+	}
+	if got := configurators[0].customizeArgs(); !reflect.DeepEqual(got, wantMotd) {
+		t.Errorf("customizeArgs() = %q, want %q", got, wantMotd)
+	}
+	wantSLES := []string{
+		"--edit", `/etc/sysconfig/network/dhcp:s/^DHCLIENT_SET_HOSTNAME=.*/DHCLIENT_SET_HOSTNAME="yes"/`,
+		"--move", "/etc/udev/rules.d/70-persistent-net.rules:/etc/udev/rules.d/70-persistent-net.rules.bak",
+		"--run", filepath.Join(dir, "hooks", "sles.sh"),
+		"--firstboot-command", "systemctl enable oracle-cloud-agent",
+	}
+	if got := configurators[1].customizeArgs(); !reflect.DeepEqual(got, wantSLES) {
+		t.Errorf("customizeArgs() = %q, want %q", got, wantSLES)
+	}
+
+	if configurators, err := LoadConfigurators(filepath.Join(dir, "missing")); err != nil || configurators != nil {
+		t.Errorf("Expected no configurators for missing directory, got %v, %v", configurators, err)
+	}
+}
+
+func TestLoadConfiguratorsInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"unknown field", "edits:\n  - path: /etc/motd\n    contents: x\n", "field contents not found"},
+		{"relative path", "edits:\n  - path: etc/motd\n    content: x\n", "path must be absolute"},
+		{"two operations", "edits:\n  - path: /etc/motd\n    content: x\n    append: y\n", "exactly one of content"},
+		{"missing script", "hooks:\n  - script: missing.sh\n", "missing.sh"},
+		{"empty", "name: empty\n", "no packages"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfigurator(t, dir, "c.yaml", tt.content)
+			_, err := LoadConfigurators(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfigurators() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfiguratorPackages(t *testing.T) {
+	c := Configurator{Packages: []string{"oci-utils", "open-iscsi"}, Hooks: []ScriptHook{{Run: "systemctl enable iscsid"}}}
+	want := []string{"--install", "oci-utils,open-iscsi", "--run-command", "systemctl enable iscsid"}
+	if got := c.customizeArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("customizeArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestConfiguratorMatches(t *testing.T) {
+	c := Configurator{Match: ConfiguratorMatch{OSIDs: []string{"sles", "opensuse-leap"}, SourcePlatforms: []string{"azure"}}}
+	tests := []struct {
+		osID, platform string
+		want           bool
+	}{
+		{"sles", "azure", true},
+		{"opensuse-leap", "azure", true},
+		{"ubuntu", "azure", false},
+		{"sles", "linux_image", false},
+	}
+	for _, tt := range tests {
+		if got := c.Matches(tt.osID, tt.platform); got != tt.want {
+			t.Errorf("Matches(%q, %q) = %v, want %v", tt.osID, tt.platform, got, tt.want)
+		}
+	}
+	if !(Configurator{}).Matches("ubuntu", "linux_image") {
+		t.Error("Expected configurator without match rules to match all images")
+	}
+}
+
+func TestOSReleaseID(t *testing.T) {
+	osRelease := "NAME=\"SLES\"\nVERSION_ID=\"15.5\"\nID=\"sles\"\nID_LIKE=\"suse\"\n"
+	if got := osReleaseID(osRelease); got != "sles" {
+		t.Errorf("osReleaseID() = %q, want sles", got)
+	}
+}
+
+func TestScriptConfigurator(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "fix.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	c, err := ScriptConfigurator(script)
+	if err != nil {
+		t.Fatalf("ScriptConfigurator() error = %v", err)
+	}
+	if want := []string{"--run", script}; !reflect.DeepEqual(c.customizeArgs(), want) {
+		t.Errorf("customizeArgs() = %q, want %q", c.customizeArgs(), want)
+	}
+	if _, err := ScriptConfigurator(filepath.Join(dir, "missing.sh")); err == nil {
+		t.Error("Expected error for missing script")
+	}
+}
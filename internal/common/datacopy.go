@@ -0,0 +1,64 @@
+// Package common provides the strategies for copying data disks to OCI block volumes.
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
+)
+
+// Strategies for copying data disks to OCI block volumes.
+const (
+	DataDiskCopyDD     = "dd"     // Copy every block of the disk with dd
+	DataDiskCopySparse = "sparse" // Zero free filesystem space, then write only allocated blocks
+	DataDiskCopyNBD    = "nbd"    // Copy the whole VHD, exposed as an NBD device, with dd
+)
+
+// SparsifyImage zeroes the free space of the filesystems in imageFile and punches holes
+// for it with virt-sparsify, so that deleted files are not copied to the block volume.
+// Partition tables, filesystem UUIDs and LVM metadata are left unchanged.
+func SparsifyImage(imageFile string, log *logger.Logger) error {
+	log.Infof("Zeroing free space in %s with virt-sparsify...", filepath.Base(imageFile))
+	// #nosec G204 -- the image file is produced by the workflow
+	cmd := exec.Command("sudo", "env", "LIBGUESTFS_BACKEND=direct", "virt-sparsify", "--in-place", imageFile)
+	if output, err := runWithProgress(cmd, func(line string) { log.Debug(line) }); err != nil {
+		return fmt.Errorf("virt-sparsify failed: %w\nOutput: %s", err, output)
+	}
+	return nil
+}
+
+// allocatedCopyArgs returns the qemu-img arguments that copy the RAW image source to the
+// existing device destination. Zero regions are skipped because a new OCI block volume
+// reads as zeros.
+func allocatedCopyArgs(source, destination string) []string {
+	return []string{"convert", "-p", "-n", "--target-is-zero", "-f", "raw", "-O", "raw", source, destination}
+}
+
+// CopyAllocatedData copies the allocated blocks of the RAW image source to the new block
+// volume at destination with qemu-img, reporting progress from its percentages.
+func CopyAllocatedData(source, destination string, log *logger.Logger) error {
+	var total int64
+	if info, err := os.Stat(source); err == nil {
+		total = info.Size()
+	}
+	rep := progress.New(log, "Copying "+filepath.Base(source), total).Record(progress.PhaseCopy, filepath.Base(source))
+	defer rep.Done()
+	// #nosec G204 -- source and destination are controlled by the application
+	cmd := exec.Command("qemu-img", allocatedCopyArgs(source, destination)...)
+	output, err := runWithProgress(cmd, func(line string) {
+		if m := qemuProgressPattern.FindStringSubmatch(line); m != nil {
+			if pct, err := strconv.ParseFloat(m[1], 64); err == nil {
+				rep.Set(int64(pct / 100 * float64(total)))
+			}
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy data with qemu-img: %w\nOutput: %s", err, output)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllocatedCopyArgs(t *testing.T) {
+	want := []string{"convert", "-p", "-n", "--target-is-zero", "-f", "raw", "-O", "raw", "/data/disk1.raw", "/dev/oracleoci/oraclevdc"}
+	if got := allocatedCopyArgs("/data/disk1.raw", "/dev/oracleoci/oraclevdc"); !reflect.DeepEqual(got, want) {
+		t.Errorf("allocatedCopyArgs() = %q, want %q", got, want)
+	}
+}
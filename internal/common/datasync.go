@@ -0,0 +1,190 @@
+// Package common provides the filesystem sync used to catch up data volumes after a migration.
+package common
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// GuestFilesystem is a filesystem found in a disk image.
+type GuestFilesystem struct {
+	Device string // libguestfs device name, e.g. /dev/sda1 or /dev/vg/lv
+	Type   string // Filesystem type, e.g. ext4 or xfs
+	UUID   string
+}
+
+// syncableFilesystems are the filesystem types whose content can be synced with rsync.
+var syncableFilesystems = map[string]bool{"ext2": true, "ext3": true, "ext4": true, "xfs": true, "btrfs": true, "vfat": true}
+
+// parseGuestFilesystems parses the CSV output of virt-filesystems --long --uuid --csv,
+// keeping the filesystems rsync can sync. Columns are located by their header, as they
+// depend on the options given.
+func parseGuestFilesystems(output string) ([]GuestFilesystem, error) {
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse virt-filesystems output: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	for _, name := range []string{"Name", "VFS", "UUID"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("virt-filesystems output has no %s column", name)
+		}
+	}
+	var filesystems []GuestFilesystem
+	for _, record := range records[1:] {
+		fs := GuestFilesystem{Device: record[columns["Name"]], Type: record[columns["VFS"]], UUID: record[columns["UUID"]]}
+		if syncableFilesystems[fs.Type] && fs.UUID != "" {
+			filesystems = append(filesystems, fs)
+		}
+	}
+	return filesystems, nil
+}
+
+// ListGuestFilesystems returns the filesystems of imageFile that can be synced.
+func ListGuestFilesystems(imageFile, luksKey string) ([]GuestFilesystem, error) {
+	args := append(guestfsToolArgs("virt-filesystems", imageFile, luksKey), "--filesystems", "--long", "--uuid", "--csv")
+	output, err := RunCommand("sudo", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list filesystems of %s: %w\nOutput: %s", imageFile, err, output)
+	}
+	return parseGuestFilesystems(output)
+}
+
+// MountGuestFilesystem mounts the filesystem device of imageFile read-only at mountPoint
+// with guestmount and returns where it was mounted. It is unmounted with
+// UnmountGuestFilesystem. A guestmount that gets stuck is killed by the watchdog, and
+// the filesystem is mounted at a new mount point next to mountPoint, as the stuck mount
+// may keep the first one busy.
+func MountGuestFilesystem(imageFile, device, mountPoint, luksKey string, log *logger.Logger) (string, error) {
+	var stuckErr error
+	for attempt := 1; attempt <= mountAttempts; attempt++ {
+		target := mountPoint
+		if attempt > 1 {
+			target = fmt.Sprintf("%s-%d", mountPoint, attempt)
+			if err := EnsureDir(target); err != nil {
+				return "", err
+			}
+		}
+		args := append(guestfsToolArgs("guestmount", imageFile, luksKey), "--ro", "-o", "allow_other", "-m", device, target)
+		output, err := runWatched(mountTimeout, "sudo", args...)
+		var stuck *StuckCommandError
+		if errors.As(err, &stuck) {
+			stuckErr = err
+			log.Warningf("%v while mounting %s at %s", err, device, target)
+			if detachErr := detachMount(target); detachErr != nil {
+				log.Warning(detachErr.Error())
+			}
+			recovery := "gave up"
+			if attempt < mountAttempts {
+				recovery = fmt.Sprintf("retried at %s-%d", mountPoint, attempt+1)
+			}
+			recordIncident(stuck.Command, target, recovery)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to mount %s: %w\nOutput: %s", device, err, output)
+		}
+		return target, nil
+	}
+	return "", fmt.Errorf("failed to mount %s after %d attempts: %w", device, mountAttempts, stuckErr)
+}
+
+// UnmountGuestFilesystem unmounts a filesystem mounted by MountGuestFilesystem. A
+// guestunmount that gets stuck is killed by the watchdog, and the mount point is
+// detached lazily instead.
+func UnmountGuestFilesystem(mountPoint string, log *logger.Logger) error {
+	output, err := runWatched(mountTimeout, "sudo", "guestunmount", mountPoint)
+	var stuck *StuckCommandError
+	if errors.As(err, &stuck) {
+		log.Warningf("%v while unmounting %s; detaching it lazily", err, mountPoint)
+		recordIncident(stuck.Command, mountPoint, "detached lazily")
+		return detachMount(mountPoint)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unmount %s: %w\nOutput: %s", mountPoint, err, output)
+	}
+	return nil
+}
+
+// SSHTarget is a host reached with ssh in batch mode.
+type SSHTarget struct {
+	Host         string
+	User         string
+	IdentityFile string // Private key; ssh defaults are used when empty
+}
+
+// sshArgs returns the ssh options that reach the target without prompting. Host keys
+// are accepted on first use, as the migrated instance is new to the Kopru host.
+func (t SSHTarget) sshArgs() []string {
+	args := []string{"-o", "BatchMode=yes", "-o", "StrictHostKeyChecking=accept-new"}
+	if t.IdentityFile != "" {
+		args = append(args, "-i", t.IdentityFile)
+	}
+	if t.User != "" {
+		args = append(args, "-l", t.User)
+	}
+	return args
+}
+
+// Run runs command on the target and returns its output.
+func (t SSHTarget) Run(command string) (string, error) {
+	output, err := RunCommand("ssh", append(t.sshArgs(), t.Host, command)...)
+	if err != nil {
+		return output, fmt.Errorf("ssh %s failed: %w\nOutput: %s", t.Host, err, output)
+	}
+	return output, nil
+}
+
+// RemoteMountPoint returns where the target mounts the filesystem with uuid, or "" when
+// it is not mounted there.
+func (t SSHTarget) RemoteMountPoint(uuid string) (string, error) {
+	output, err := t.Run("findmnt -rn -o TARGET -S UUID=" + uuid + " || true")
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Fields(output)
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return lines[0], nil
+}
+
+// rsyncArgs returns the rsync arguments that make remoteDir on the target an exact copy
+// of localDir, preserving hard links, ACLs, extended attributes, sparse files and
+// numeric ownership. rsync runs with sudo on both ends so that every file can be read
+// and written.
+func rsyncArgs(localDir, remoteDir string, target SSHTarget, dryRun bool) []string {
+	args := []string{"rsync", "-aHAXS", "--numeric-ids", "--delete", "--itemize-changes", "--stats",
+		"--rsync-path", "sudo rsync", "-e", "ssh " + strings.Join(target.sshArgs(), " ")}
+	if dryRun {
+		args = append(args, "--dry-run")
+	}
+	return append(args, strings.TrimSuffix(localDir, "/")+"/", target.Host+":"+strings.TrimSuffix(remoteDir, "/")+"/")
+}
+
+// RsyncToHost transfers the differences between localDir and remoteDir on the target,
+// deleting files that no longer exist in localDir. With dryRun, the changes are only
+// listed.
+func RsyncToHost(localDir, remoteDir string, target SSHTarget, dryRun bool, log *logger.Logger) error {
+	logLine := log.Debug
+	if dryRun {
+		logLine = log.Info
+	}
+	// #nosec G204 -- the directories and target are controlled by the application
+	cmd := exec.Command("sudo", rsyncArgs(localDir, remoteDir, target, dryRun)...)
+	if output, err := runWithProgress(cmd, logLine); err != nil {
+		return fmt.Errorf("rsync to %s:%s failed: %w\nOutput: %s", target.Host, remoteDir, err, output)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGuestFilesystems(t *testing.T) {
+	output := `Name,Type,VFS,Label,Size,Parent,UUID
+/dev/sda1,filesystem,xfs,data,1071644672,-,4f9c6b0e-1a2b-4c3d-9e8f-0a1b2c3d4e5f
+/dev/sda2,filesystem,swap,-,536870912,-,1d2e3f40-5a6b-4c7d-8e9f-a0b1c2d3e4f5
+/dev/vg0/lv_app,filesystem,ext4,-,2147483648,-,9a8b7c6d-5e4f-4a3b-2c1d-0e9f8a7b6c5d
+/dev/sdb,filesystem,unknown,-,1073741824,-,
+`
+	got, err := parseGuestFilesystems(output)
+	if err != nil {
+		t.Fatalf("parseGuestFilesystems() error = %v", err)
+	}
+	want := []GuestFilesystem{
+		{Device: "/dev/sda1", Type: "xfs", UUID: "4f9c6b0e-1a2b-4c3d-9e8f-0a1b2c3d4e5f"},
+		{Device: "/dev/vg0/lv_app", Type: "ext4", UUID: "9a8b7c6d-5e4f-4a3b-2c1d-0e9f8a7b6c5d"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseGuestFilesystems() = %+v, want %+v", got, want)
+	}
+	if _, err := parseGuestFilesystems("Name,Type\n/dev/sda1,filesystem\n"); err == nil {
+		t.Error("Expected output without VFS and UUID columns to fail")
+	}
+}
+
+func TestRsyncArgs(t *testing.T) {
+	target := SSHTarget{Host: "10.0.0.5", User: "kopru", IdentityFile: "/home/me/.ssh/id_ed25519"}
+	want := []string{"rsync", "-aHAXS", "--numeric-ids", "--delete", "--itemize-changes", "--stats",
+		"--rsync-path", "sudo rsync", "-e", "ssh -o BatchMode=yes -o StrictHostKeyChecking=accept-new -i /home/me/.ssh/id_ed25519 -l kopru",
+		"--dry-run", "./sync/data-sda1/", "10.0.0.5:/data/"}
+	if got := rsyncArgs("./sync/data-sda1", "/data/", target, true); !reflect.DeepEqual(got, want) {
+		t.Errorf("rsyncArgs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+// Package common provides the safety checks made before data is written to a block device.
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// lsblkDevice is a block device in the JSON output of lsblk. util-linux before 2.33
+// reports sizes as strings.
+type lsblkDevice struct {
+	Name       string          `json:"name"`
+	Size       json.RawMessage `json:"size"`
+	Type       string          `json:"type"`
+	MountPoint *string         `json:"mountpoint"`
+	Children   []lsblkDevice   `json:"children"`
+}
+
+// lsblk returns the lsblk JSON description of device and the devices it holds, such as
+// partitions and LVM volumes. Tests replace it.
+var lsblk = func(device string) ([]byte, error) {
+	// #nosec G204 -- the device is the one attached by Kopru
+	output, err := exec.Command("lsblk", "-J", "-b", "-o", "NAME,SIZE,TYPE,MOUNTPOINT", device).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe %s: %w", device, err)
+	}
+	return output, nil
+}
+
+// isBlockDevice reports whether path is a block device. Tests replace it.
+var isBlockDevice = func(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeDevice != 0 && info.Mode()&os.ModeCharDevice == 0
+}
+
+// mountPoints returns the mount points of d and the devices it holds.
+func (d lsblkDevice) mountPoints() []string {
+	var points []string
+	if d.MountPoint != nil && *d.MountPoint != "" {
+		points = append(points, *d.MountPoint)
+	}
+	for _, c := range d.Children {
+		points = append(points, c.mountPoints()...)
+	}
+	return points
+}
+
+// CheckTargetDevice checks that the block device at devicePath can be overwritten with
+// a disk image before dd or another destructive write runs with sudo: it must be a whole
+// disk of exactly wantBytes, the size of the volume Kopru created and attached, and
+// neither it nor its partitions or volumes may be mounted, so that a wrongly detected
+// device such as the boot volume of the host is never written to.
+func CheckTargetDevice(devicePath string, wantBytes int64) error {
+	resolved, err := filepath.EvalSymlinks(devicePath)
+	if err != nil {
+		return fmt.Errorf("refusing to write to %s: %w", devicePath, err)
+	}
+	if !isBlockDevice(resolved) {
+		return fmt.Errorf("refusing to write to %s: %s is not a block device", devicePath, resolved)
+	}
+	output, err := lsblk(resolved)
+	if err != nil {
+		return fmt.Errorf("refusing to write to %s: %w", devicePath, err)
+	}
+	var described struct {
+		BlockDevices []lsblkDevice `json:"blockdevices"`
+	}
+	if err := json.Unmarshal(output, &described); err != nil || len(described.BlockDevices) != 1 {
+		return fmt.Errorf("refusing to write to %s: unexpected lsblk output: %s", devicePath, output)
+	}
+	dev := described.BlockDevices[0]
+	if dev.Type != "disk" {
+		return fmt.Errorf("refusing to write to %s: %s is a %s, not a whole disk", devicePath, resolved, dev.Type)
+	}
+	for _, point := range dev.mountPoints() {
+		if point == "/" {
+			return fmt.Errorf("refusing to write to %s: %s holds the root filesystem of the host", devicePath, resolved)
+		}
+	}
+	if points := dev.mountPoints(); len(points) > 0 {
+		return fmt.Errorf("refusing to write to %s: %s is in use (mounted at %s)", devicePath, resolved, strings.Join(points, ", "))
+	}
+	size, err := strconv.ParseInt(strings.Trim(string(dev.Size), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("refusing to write to %s: unexpected size %s", devicePath, dev.Size)
+	}
+	if size != wantBytes {
+		return fmt.Errorf("refusing to write to %s: %s has %d bytes, but the attached volume has %d bytes", devicePath, resolved, size, wantBytes)
+	}
+	return nil
+}
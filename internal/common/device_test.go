@@ -0,0 +1,55 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckTargetDevice(t *testing.T) {
+	device := filepath.Join(t.TempDir(), "sdb")
+	if err := os.WriteFile(device, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "oraclevdb")
+	if err := os.Symlink(device, link); err != nil {
+		t.Fatal(err)
+	}
+	originalLsblk, originalIsBlockDevice := lsblk, isBlockDevice
+	defer func() { lsblk, isBlockDevice = originalLsblk, originalIsBlockDevice }()
+
+	const size = 50 * 1024 * 1024 * 1024
+	tests := []struct {
+		name  string
+		block bool
+		json  string
+		want  string
+	}{
+		{"empty volume", true, `{"blockdevices": [{"name": "sdb", "size": 53687091200, "type": "disk", "mountpoint": null}]}`, ""},
+		{"old lsblk", true, `{"blockdevices": [{"name": "sdb", "size": "53687091200", "type": "disk", "mountpoint": null}]}`, ""},
+		{"not a block device", false, "", "not a block device"},
+		{"partition", true, `{"blockdevices": [{"name": "sdb1", "size": 53687091200, "type": "part", "mountpoint": null}]}`, "not a whole disk"},
+		{"host root on LVM", true, `{"blockdevices": [{"name": "sda", "size": 53687091200, "type": "disk", "mountpoint": null, "children": [
+			{"name": "sda1", "size": 1073741824, "type": "part", "mountpoint": "/boot"},
+			{"name": "sda2", "size": 52613349376, "type": "part", "mountpoint": null, "children": [{"name": "ocivolume-root", "size": 52613349376, "type": "lvm", "mountpoint": "/"}]}]}]}`, "root filesystem of the host"},
+		{"mounted", true, `{"blockdevices": [{"name": "sdb", "size": 53687091200, "type": "disk", "mountpoint": null, "children": [{"name": "sdb1", "size": 53686042624, "type": "part", "mountpoint": "/work"}]}]}`, "mounted at /work"},
+		{"size mismatch", true, `{"blockdevices": [{"name": "sdb", "size": 107374182400, "type": "disk", "mountpoint": null}]}`, "has 107374182400 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isBlockDevice = func(path string) bool { return tt.block && path == device }
+			lsblk = func(string) ([]byte, error) { return []byte(tt.json), nil }
+			err := CheckTargetDevice(link, size)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("CheckTargetDevice() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.HasPrefix(err.Error(), "refusing to write to "+link) {
+				t.Errorf("CheckTargetDevice() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
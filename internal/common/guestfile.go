@@ -0,0 +1,74 @@
+// Package common provides access to individual files in the guest filesystems of an image.
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// guestfsToolArgs returns the sudo arguments that run a libguestfs tool on imageFile,
+// opening its LUKS containers with luksKey.
+func guestfsToolArgs(tool, imageFile, luksKey string, env ...string) []string {
+	args := append([]string{"env", "LIBGUESTFS_BACKEND=direct"}, env...)
+	args = append(append(args, tool), guestfsKeyArgs(luksKey)...)
+	return append(args, "-a", imageFile)
+}
+
+// CatGuestFile writes the content of the file at guestPath in imageFile to w.
+func CatGuestFile(imageFile, guestPath, luksKey string, w io.Writer) error {
+	var stderr bytes.Buffer
+	// #nosec G204 -- the image and guest path are given by the user
+	cmd := exec.Command("sudo", append(guestfsToolArgs("virt-cat", imageFile, luksKey), guestPath)...)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to read %s: %w\nOutput: %s", guestPath, err, stderr.String())
+	}
+	return nil
+}
+
+// CopyFromGuest copies the file or directory at guestPath in imageFile to localDir.
+func CopyFromGuest(imageFile, guestPath, localDir, luksKey string) error {
+	if output, err := RunCommand("sudo", append(guestfsToolArgs("virt-copy-out", imageFile, luksKey), guestPath, localDir)...); err != nil {
+		return fmt.Errorf("failed to copy %s out of the image: %w\nOutput: %s", guestPath, err, output)
+	}
+	return nil
+}
+
+// CopyToGuest copies the local file or directory localPath into guestDir in imageFile.
+func CopyToGuest(imageFile, localPath, guestDir, luksKey string) error {
+	if output, err := RunCommand("sudo", append(guestfsToolArgs("virt-copy-in", imageFile, luksKey), localPath, guestDir)...); err != nil {
+		return fmt.Errorf("failed to copy %s into the image: %w\nOutput: %s", localPath, err, output)
+	}
+	return nil
+}
+
+// editArgs returns the virt-edit arguments that edit guestPath in imageFile with the Perl
+// expression expr, or with editor when expr is empty.
+func editArgs(imageFile, guestPath, luksKey, expr, editor string) []string {
+	var env []string
+	if expr == "" && editor != "" {
+		env = []string{"EDITOR=" + editor}
+	}
+	args := append(guestfsToolArgs("virt-edit", imageFile, luksKey, env...), guestPath)
+	if expr != "" {
+		args = append(args, "-e", expr)
+	}
+	return args
+}
+
+// EditGuestFile edits the file at guestPath in imageFile in place, with the Perl
+// expression expr (e.g. 's/^SELINUX=.*/SELINUX=permissive/') or, when expr is empty,
+// interactively in $EDITOR.
+func EditGuestFile(imageFile, guestPath, luksKey, expr string) error {
+	// #nosec G204 -- the image, guest path and expression are given by the user
+	cmd := exec.Command("sudo", editArgs(imageFile, guestPath, luksKey, expr, os.Getenv("EDITOR"))...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to edit %s: %w", guestPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditArgs(t *testing.T) {
+	tests := []struct {
+		name, luksKey, expr, editor string
+		want                        []string
+	}{
+		{"editor", "", "", "vim", []string{"env", "LIBGUESTFS_BACKEND=direct", "EDITOR=vim", "virt-edit", "-a", "disk.qcow2", "/etc/fstab"}},
+		{"expression", "", "s/a/b/", "vim", []string{"env", "LIBGUESTFS_BACKEND=direct", "virt-edit", "-a", "disk.qcow2", "/etc/fstab", "-e", "s/a/b/"}},
+		{"luks", "all:file:/tmp/key", "s/a/b/", "", []string{"env", "LIBGUESTFS_BACKEND=direct", "virt-edit", "--key", "all:file:/tmp/key", "-a", "disk.qcow2", "/etc/fstab", "-e", "s/a/b/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := editArgs("disk.qcow2", "/etc/fstab", tt.luksKey, tt.expr, tt.editor); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("editArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package common provides the network block devices that expose disk images with qemu-nbd.
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// sysBlockDir is where the kernel describes block devices. Tests replace it.
+var sysBlockDir = "/sys/block"
+
+// nbdTimeout is how long qemu-nbd may take to connect or disconnect a device before the
+// watchdog kills it. Overridable in tests.
+var nbdTimeout = 2 * time.Minute
+
+// nbdAttempts is the number of NBD devices on which ConnectImage tries to connect an
+// image whose connection gets stuck.
+const nbdAttempts = 3
+
+// nbdMu serializes the choice and connection of NBD devices, so that disks imported in
+// parallel never pick the same device.
+var nbdMu sync.Mutex
+
+// nbdDevices returns the NBD devices described in sysDir, such as nbd0, in numeric order.
+func nbdDevices(sysDir string) []string {
+	matches, _ := filepath.Glob(filepath.Join(sysDir, "nbd*"))
+	var names []string
+	for _, m := range matches {
+		if _, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(m), "nbd")); err == nil {
+			names = append(names, filepath.Base(m))
+		}
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		x, _ := strconv.Atoi(strings.TrimPrefix(a, "nbd"))
+		y, _ := strconv.Atoi(strings.TrimPrefix(b, "nbd"))
+		return x - y
+	})
+	return names
+}
+
+// freeNBDDevice returns the first NBD device in sysDir that no qemu-nbd process serves,
+// other than those in skip. The kernel creates the pid file of a device when it is
+// connected.
+func freeNBDDevice(sysDir string, skip map[string]bool) (string, error) {
+	names := nbdDevices(sysDir)
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(sysDir, name, "pid")); os.IsNotExist(err) && !skip[name] {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("all %d NBD devices are in use; reload the nbd module with more devices (modprobe nbd nbds_max=32)", len(names))
+}
+
+// blockDeviceBytes returns the size of the block device name in sysDir, which the
+// kernel reports in 512-byte sectors.
+func blockDeviceBytes(sysDir, name string) (int64, error) {
+	data, err := os.ReadFile(filepath.Join(sysDir, name, "size")) // #nosec G304 -- sysfs attribute
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the size of %s: %w", name, err)
+	}
+	sectors, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected size of %s: %q", name, data)
+	}
+	return sectors * 512, nil
+}
+
+// ConnectImage exposes the disk image imageFile of the given qemu-img format, such as
+// vpc, read-only as a whole NBD device with qemu-nbd, so that its partition table and all
+// its partitions can be copied without converting it first. It returns the device and
+// a function that disconnects it. A qemu-nbd that gets stuck is killed by the watchdog,
+// and the image is connected to the next free device, as the stuck one may stay busy.
+func ConnectImage(imageFile, format string, log *logger.Logger) (string, func() error, error) {
+	nbdMu.Lock()
+	defer nbdMu.Unlock()
+	if len(nbdDevices(sysBlockDir)) == 0 {
+		if output, err := RunCommand("sudo", "modprobe", "nbd", "max_part=16"); err != nil {
+			return "", nil, fmt.Errorf("failed to load the nbd module: %w\nOutput: %s", err, output)
+		}
+	}
+	stuckDevices := map[string]bool{}
+	var stuckErr error
+	for attempt := 1; attempt <= nbdAttempts; attempt++ {
+		name, err := freeNBDDevice(sysBlockDir, stuckDevices)
+		if err != nil {
+			return "", nil, err
+		}
+		device := "/dev/" + name
+		output, err := runWatched(nbdTimeout, "sudo", "qemu-nbd", "--read-only", "--format="+format, "--connect="+device, imageFile)
+		var stuck *StuckCommandError
+		if errors.As(err, &stuck) {
+			stuckErr = err
+			stuckDevices[name] = true
+			log.Warningf("%v while connecting %s to %s", err, filepath.Base(imageFile), device)
+			if _, disconnectErr := runWatched(nbdTimeout, "sudo", "qemu-nbd", "--disconnect", device); disconnectErr != nil {
+				log.Warningf("Failed to disconnect %s: %v", device, disconnectErr)
+			}
+			recovery := "gave up"
+			if attempt < nbdAttempts {
+				recovery = "retried on the next free NBD device"
+			}
+			recordIncident(stuck.Command, device, recovery)
+			continue
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("qemu-nbd failed to connect %s to %s: %w\nOutput: %s", filepath.Base(imageFile), device, err, output)
+		}
+		disconnect := nbdDisconnecter(device)
+		// qemu-nbd returns before the kernel has read the size of the device.
+		for range 30 {
+			if size, err := blockDeviceBytes(sysBlockDir, name); err == nil && size > 0 {
+				return device, disconnect, nil
+			}
+			time.Sleep(time.Second)
+		}
+		_ = disconnect()
+		return "", nil, fmt.Errorf("%s did not come up after connecting %s", device, filepath.Base(imageFile))
+	}
+	return "", nil, fmt.Errorf("failed to connect %s after %d attempts: %w", filepath.Base(imageFile), nbdAttempts, stuckErr)
+}
+
+// nbdDisconnecter returns the function that disconnects device. A qemu-nbd that gets
+// stuck is killed by the watchdog, and the device is left for the operator.
+func nbdDisconnecter(device string) func() error {
+	return func() error {
+		output, err := runWatched(nbdTimeout, "sudo", "qemu-nbd", "--disconnect", device)
+		var stuck *StuckCommandError
+		if errors.As(err, &stuck) {
+			recordIncident(stuck.Command, device, "left connected")
+			return fmt.Errorf("%w; disconnect %s with qemu-nbd --disconnect", err, device)
+		}
+		if err != nil {
+			return fmt.Errorf("qemu-nbd failed to disconnect %s: %w\nOutput: %s", device, err, output)
+		}
+		return nil
+	}
+}
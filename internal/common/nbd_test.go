@@ -0,0 +1,112 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// writeSysBlock creates the sysfs attributes of the block device name in dir.
+func writeSysBlock(t *testing.T, dir, name string, attrs map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for attr, value := range attrs {
+		if err := os.WriteFile(filepath.Join(dir, name, attr), []byte(value), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNBDDevices(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"nbd10", "nbd2", "nbd0", "sda", "nbd1p1"} {
+		writeSysBlock(t, dir, name, nil)
+	}
+	want := []string{"nbd0", "nbd2", "nbd10"}
+	if got := nbdDevices(dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("nbdDevices() = %v, want %v", got, want)
+	}
+}
+
+func TestFreeNBDDevice(t *testing.T) {
+	dir := t.TempDir()
+	writeSysBlock(t, dir, "nbd0", map[string]string{"pid": "1234\n"})
+	writeSysBlock(t, dir, "nbd1", map[string]string{"pid": "1235\n"})
+	writeSysBlock(t, dir, "nbd2", nil)
+	writeSysBlock(t, dir, "nbd3", nil)
+	got, err := freeNBDDevice(dir, nil)
+	if err != nil || got != "nbd2" {
+		t.Errorf("freeNBDDevice() = %q, %v, want nbd2", got, err)
+	}
+	if got, err := freeNBDDevice(dir, map[string]bool{"nbd2": true}); err != nil || got != "nbd3" {
+		t.Errorf("freeNBDDevice() skipping nbd2 = %q, %v, want nbd3", got, err)
+	}
+
+	writeSysBlock(t, dir, "nbd2", map[string]string{"pid": "1236\n"})
+	writeSysBlock(t, dir, "nbd3", map[string]string{"pid": "1237\n"})
+	if _, err := freeNBDDevice(dir, nil); err == nil {
+		t.Error("freeNBDDevice() succeeded with every device in use")
+	}
+}
+
+func TestBlockDeviceBytes(t *testing.T) {
+	dir := t.TempDir()
+	writeSysBlock(t, dir, "nbd0", map[string]string{"size": "2097152\n"})
+	got, err := blockDeviceBytes(dir, "nbd0")
+	if err != nil || got != 1<<30 {
+		t.Errorf("blockDeviceBytes() = %d, %v, want %d", got, err, 1<<30)
+	}
+	if _, err := blockDeviceBytes(dir, "nbd1"); err == nil {
+		t.Error("blockDeviceBytes() succeeded for a missing device")
+	}
+}
+
+func TestConnectImageRetriesStuckConnection(t *testing.T) {
+	defer func(run func(time.Duration, string, ...string) (string, error)) { runWatched = run }(runWatched)
+	defer func(dir string) { sysBlockDir = dir }(sysBlockDir)
+	sysBlockDir = t.TempDir()
+	writeSysBlock(t, sysBlockDir, "nbd0", nil)
+	writeSysBlock(t, sysBlockDir, "nbd1", nil)
+	var commands []string
+	runWatched = func(timeout time.Duration, name string, args ...string) (string, error) {
+		commands = append(commands, strings.Join(args, " "))
+		if slices.Contains(args, "--connect=/dev/nbd0") {
+			return "", &StuckCommandError{Command: "qemu-nbd", Timeout: timeout}
+		}
+		if slices.Contains(args, "--connect=/dev/nbd1") {
+			writeSysBlock(t, sysBlockDir, "nbd1", map[string]string{"size": "2048\n"})
+		}
+		return "", nil
+	}
+	device, disconnect, err := ConnectImage("disk.vhd", "vpc", logger.New(false))
+	if err != nil {
+		t.Fatalf("ConnectImage() error = %v", err)
+	}
+	if device != "/dev/nbd1" {
+		t.Errorf("ConnectImage() = %s, want /dev/nbd1", device)
+	}
+	if err := disconnect(); err != nil {
+		t.Errorf("disconnect() error = %v", err)
+	}
+	want := []string{
+		"qemu-nbd --read-only --format=vpc --connect=/dev/nbd0 disk.vhd",
+		"qemu-nbd --disconnect /dev/nbd0",
+		"qemu-nbd --read-only --format=vpc --connect=/dev/nbd1 disk.vhd",
+		"qemu-nbd --disconnect /dev/nbd1",
+	}
+	if !slices.Equal(commands, want) {
+		t.Errorf("Unexpected commands: %q", commands)
+	}
+	incidents := WatchdogIncidents()
+	if len(incidents) == 0 || incidents[len(incidents)-1].Target != "/dev/nbd0" || incidents[len(incidents)-1].Recovery != "retried on the next free NBD device" {
+		t.Errorf("Unexpected incidents: %+v", incidents)
+	}
+}
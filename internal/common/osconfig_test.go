@@ -0,0 +1,170 @@
+package common
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fstabHeader is the header of the virt-filesystems --long --uuid --csv output.
+const fstabHeader = "Name,Type,VFS,Label,MBR,Size,Parent,UUID\n"
+
+// runRemediateFstab runs remediate_fstab of the built-in scripts on an image whose
+// /etc/fstab is fstab and whose filesystems virt-filesystems lists as filesystems, and
+// returns the fstab it uploads, or "" when it leaves the image unchanged.
+func runRemediateFstab(t *testing.T, fstab, filesystems string) string {
+	t.Helper()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	dir := t.TempDir()
+	for name, content := range map[string]string{"fstab": fstab, "filesystems.csv": fstabHeader + filesystems} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script, err := filepath.Abs("../../scripts/os-config/common.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The libguestfs tools are replaced by functions that read the fixtures and keep the
+	// uploaded fstab.
+	cmd := exec.Command("bash", "-c", `set -euo pipefail
+source "$1"
+virt-cat() { cat "$FIXTURES/fstab"; }
+virt-filesystems() { cat "$FIXTURES/filesystems.csv"; }
+virt-customize() {
+    while [[ $# -gt 0 ]]; do
+        [[ "$1" == --upload ]] && cp "${2%%:*}" "$FIXTURES/fstab.out"
+        shift
+    done
+}
+remediate_fstab disk.qcow2`, "bash", script)
+	cmd.Env = append(os.Environ(), "FIXTURES="+dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("remediate_fstab failed: %v\nOutput: %s", err, output)
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "fstab.out"))
+	if os.IsNotExist(err) {
+		return ""
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestRemediateFstab(t *testing.T) {
+	tests := []struct {
+		name        string
+		fstab       string
+		filesystems string
+		want        string
+	}{
+		{
+			name: "btrfs subvolumes",
+			fstab: "/dev/sda2 / btrfs defaults 0 0\n" +
+				"UUID=4c6b-btrfs /home btrfs defaults 0 0\n" +
+				"UUID=4c6b-btrfs /var/log btrfs defaults,subvol=@log 0 0\n" +
+				"UUID=1f2e-boot /boot ext4 defaults 0 2\n",
+			filesystems: "/dev/sda1,filesystem,ext4,,-,1073741824,-,1f2e-boot\n" +
+				"/dev/sda2,filesystem,btrfs,,-,30064771072,-,4c6b-btrfs\n" +
+				"btrfsvol:/dev/sda2/@,filesystem,btrfs,,-,-,-,4c6b-btrfs\n" +
+				"btrfsvol:/dev/sda2/@home,filesystem,btrfs,,-,-,-,4c6b-btrfs\n" +
+				"btrfsvol:/dev/sda2/@log,filesystem,btrfs,,-,-,-,4c6b-btrfs\n",
+			want: "UUID=4c6b-btrfs / btrfs defaults,subvol=@ 0 0\n" +
+				"UUID=4c6b-btrfs /home btrfs defaults,subvol=@home 0 0\n" +
+				"UUID=4c6b-btrfs /var/log btrfs defaults,subvol=@log 0 0\n" +
+				"UUID=1f2e-boot /boot ext4 defaults 0 2\n",
+		},
+		{
+			name: "btrfs with snapper keeps the default subvolume",
+			fstab: "UUID=9a8b-btrfs / btrfs defaults 0 0\n" +
+				"UUID=9a8b-btrfs /home btrfs subvol=/@/home 0 0\n",
+			filesystems: "/dev/sda2,filesystem,btrfs,,-,30064771072,-,9a8b-btrfs\n" +
+				"btrfsvol:/dev/sda2/@,filesystem,btrfs,,-,-,-,9a8b-btrfs\n" +
+				"btrfsvol:/dev/sda2/@/.snapshots,filesystem,btrfs,,-,-,-,9a8b-btrfs\n" +
+				"btrfsvol:/dev/sda2/@/home,filesystem,btrfs,,-,-,-,9a8b-btrfs\n",
+			want: "",
+		},
+		{
+			name: "xfs with duplicate UUIDs",
+			fstab: "UUID=7d1c-root / xfs defaults 0 0\n" +
+				"/dev/sda3 /srv xfs defaults 0 0\n" +
+				"UUID=2b3a-boot /boot xfs defaults 0 0\n" +
+				"UUID=5e6f-data /data xfs defaults 0 0\n",
+			filesystems: "/dev/sda1,filesystem,xfs,,-,1073741824,-,2b3a-boot\n" +
+				"/dev/sda2,filesystem,xfs,,-,30064771072,-,7d1c-root\n" +
+				"/dev/sda3,filesystem,xfs,,-,10737418240,-,7d1c-root\n",
+			want: "UUID=7d1c-root / xfs defaults,nouuid 0 0\n" +
+				"UUID=7d1c-root /srv xfs defaults,nouuid 0 0\n" +
+				"UUID=2b3a-boot /boot xfs defaults 0 0\n" +
+				"UUID=5e6f-data /data xfs defaults,nofail 0 0\n",
+		},
+		{
+			name:        "unique xfs UUIDs unchanged",
+			fstab:       "UUID=7d1c-root / xfs defaults 0 0\n# /dev/sdb1 /mnt xfs defaults 0 0\n",
+			filesystems: "/dev/sda2,filesystem,xfs,,-,30064771072,-,7d1c-root\n",
+			want:        "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runRemediateFstab(t, tt.fstab, tt.filesystems)
+			if got != tt.want {
+				t.Errorf("remediate_fstab wrote:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if got != "" && strings.Count(got, "\n") != strings.Count(tt.fstab, "\n") {
+				t.Errorf("remediate_fstab changed the number of fstab lines")
+			}
+		})
+	}
+}
+
+// runInstallOCIUtilities runs install_oci_utilities of the built-in scripts with a
+// virt-customize that fails --install when installFails, and returns the arguments of
+// each virt-customize call, one call per line.
+func runInstallOCIUtilities(t *testing.T, installFails bool) []string {
+	t.Helper()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	script, err := filepath.Abs("../../scripts/os-config/common.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := filepath.Join(t.TempDir(), "calls")
+	cmd := exec.Command("bash", "-c", `set -euo pipefail
+source "$1"
+virt-customize() {
+    local IFS='|'
+    echo "$*" >> "$CALLS"
+    [[ "$INSTALL_FAILS" != true || "$3" != --install ]]
+}
+install_oci_utilities disk.qcow2`, "bash", script)
+	cmd.Env = append(os.Environ(), "CALLS="+calls, "INSTALL_FAILS="+strconv.FormatBool(installFails))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("install_oci_utilities failed: %v\nOutput: %s", err, output)
+	}
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestInstallOCIUtilities(t *testing.T) {
+	calls := runInstallOCIUtilities(t, false)
+	if len(calls) != 2 || calls[0] != "-a|disk.qcow2|--install|oci-utils,oracle-cloud-agent" {
+		t.Errorf("virt-customize calls = %q, want the packages installed as a comma-separated list", calls)
+	}
+
+	calls = runInstallOCIUtilities(t, true)
+	want := "-a|disk.qcow2|--firstboot-command|(dnf install -y oci-utils oracle-cloud-agent || yum install -y oci-utils oracle-cloud-agent) && systemctl enable --now ocid.service oracle-cloud-agent.service"
+	if len(calls) != 2 || calls[1] != want {
+		t.Errorf("virt-customize calls = %q, want the packages installed at first boot as a space-separated list", calls)
+	}
+}
@@ -0,0 +1,124 @@
+// Package common provides offline package repositories for guest configuration on
+// air-gapped migration hosts.
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// packageCacheGuestDir receives the package cache in the guest while it is configured.
+const packageCacheGuestDir = "/var/cache/kopru-packages"
+
+// Package manager families of guests.
+const (
+	packageFamilyDebian = "debian"
+	packageFamilyRHEL   = "rhel"
+	packageFamilySUSE   = "suse"
+)
+
+// osReleaseFamily returns the package manager family of a guest from the ID and
+// ID_LIKE fields of its /etc/os-release.
+func osReleaseFamily(osRelease string) string {
+	var ids []string
+	for _, line := range strings.Split(osRelease, "\n") {
+		line = strings.TrimSpace(line)
+		for _, key := range []string{"ID=", "ID_LIKE="} {
+			if value, found := strings.CutPrefix(line, key); found {
+				ids = append(ids, strings.Fields(strings.Trim(value, `"'`))...)
+			}
+		}
+	}
+	for _, id := range ids {
+		switch {
+		case id == "debian" || id == "ubuntu":
+			return packageFamilyDebian
+		case id == "suse" || id == "sles" || strings.HasPrefix(id, "opensuse"):
+			return packageFamilySUSE
+		case id == "rhel" || id == "fedora" || id == "centos":
+			return packageFamilyRHEL
+		}
+	}
+	return ""
+}
+
+// checkPackageCache checks that dir holds the repository metadata the package manager
+// of family reads.
+func checkPackageCache(dir, family string) error {
+	var metadata []string
+	var hint string
+	if family == packageFamilyDebian {
+		metadata = []string{"Packages", "Packages.gz", "Packages.xz"}
+		hint = "dpkg-scanpackages . /dev/null | gzip > Packages.gz"
+	} else {
+		metadata = []string{filepath.Join("repodata", "repomd.xml")}
+		hint = "createrepo_c ."
+	}
+	for _, name := range metadata {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("package cache %s has no %s repository metadata; create it with '%s' in the directory", dir, family, hint)
+}
+
+// packageCacheCommands returns the guest commands that replace the package
+// repositories of the guest with the cache at repo, and that restore them.
+func packageCacheCommands(family, repo string) (attach, detach string) {
+	switch family {
+	case packageFamilyDebian:
+		attach = "mkdir -p /etc/apt/kopru-saved && for f in /etc/apt/sources.list /etc/apt/sources.list.d; do [ -e $f ] && mv $f /etc/apt/kopru-saved/; done; " +
+			"mkdir -p /etc/apt/sources.list.d && echo 'deb [trusted=yes] file:" + repo + " ./' > /etc/apt/sources.list.d/kopru-local.list"
+		detach = "rm -rf /etc/apt/sources.list.d && mv /etc/apt/kopru-saved/* /etc/apt/ 2>/dev/null; rmdir /etc/apt/kopru-saved; rm -rf " + packageCacheGuestDir
+	default:
+		dir, extra := "/etc/yum.repos.d", ""
+		if family == packageFamilySUSE {
+			dir, extra = "/etc/zypp/repos.d", "type=rpm-md\\nautorefresh=0\\n"
+		}
+		attach = "mv " + dir + " " + dir + ".kopru-saved && mkdir " + dir + " && printf '[kopru-local]\\nname=Kopru local packages\\nbaseurl=file://" + repo +
+			"\\nenabled=1\\ngpgcheck=0\\n" + extra + "' > " + dir + "/kopru-local.repo"
+		detach = "rm -rf " + dir + " && mv " + dir + ".kopru-saved " + dir + " && rm -rf " + packageCacheGuestDir
+	}
+	return attach, detach
+}
+
+// AttachPackageCache copies the local package repository dir into imageFile and
+// replaces the guest's package repositories with it, so that package installs during
+// the configuration need no network access. The returned function restores the
+// guest's repositories and removes the cache; it must be called before the image is
+// scrubbed.
+func AttachPackageCache(imageFile, dir, luksKey string, log *logger.Logger) (func() error, error) {
+	osRelease, err := RunCommand("sudo", append(guestfsToolArgs("virt-cat", imageFile, luksKey), "/etc/os-release")...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read /etc/os-release from image: %w", err)
+	}
+	family := osReleaseFamily(osRelease)
+	if family == "" {
+		return nil, errors.New("package cache is not supported for the OS of the image (supported: Debian, Ubuntu, RHEL-compatible and SUSE guests)")
+	}
+	if err := checkPackageCache(dir, family); err != nil {
+		return nil, err
+	}
+	repo := packageCacheGuestDir + "/" + filepath.Base(filepath.Clean(dir))
+	attach, detach := packageCacheCommands(family, repo)
+	log.Infof("Attaching package cache %s to the image ...", dir)
+	args := append(guestfsToolArgs("virt-customize", imageFile, luksKey),
+		"--mkdir", packageCacheGuestDir, "--copy-in", dir+":"+packageCacheGuestDir, "--run-command", attach)
+	if output, err := RunCommand("sudo", args...); err != nil {
+		return nil, fmt.Errorf("failed to attach package cache: %w\nOutput: %s", err, output)
+	}
+	log.Successf("Package cache attached at %s", repo)
+	return func() error {
+		args := append(guestfsToolArgs("virt-customize", imageFile, luksKey), "--run-command", detach)
+		if output, err := RunCommand("sudo", args...); err != nil {
+			return fmt.Errorf("failed to restore the package repositories of the image: %w\nOutput: %s", err, output)
+		}
+		log.Success("Package cache removed, package repositories of the image restored")
+		return nil
+	}, nil
+}
@@ -0,0 +1,72 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOSReleaseFamily(t *testing.T) {
+	tests := []struct {
+		osRelease string
+		want      string
+	}{
+		{"ID=ubuntu\nID_LIKE=debian\n", packageFamilyDebian},
+		{"ID=\"ol\"\nID_LIKE=\"fedora\"\n", packageFamilyRHEL},
+		{"ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n", packageFamilyRHEL},
+		{"ID=\"sles\"\nID_LIKE=\"suse\"\n", packageFamilySUSE},
+		{"ID=\"opensuse-leap\"\n", packageFamilySUSE},
+		{"ID=arch\n", ""},
+	}
+	for _, tt := range tests {
+		if got := osReleaseFamily(tt.osRelease); got != tt.want {
+			t.Errorf("osReleaseFamily(%q) = %q, want %q", tt.osRelease, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPackageCache(t *testing.T) {
+	dir := t.TempDir()
+	if err := checkPackageCache(dir, packageFamilyDebian); err == nil || !strings.Contains(err.Error(), "dpkg-scanpackages") {
+		t.Errorf("Expected missing apt metadata error, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Packages.gz"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPackageCache(dir, packageFamilyDebian); err != nil {
+		t.Errorf("checkPackageCache() error = %v", err)
+	}
+	if err := checkPackageCache(dir, packageFamilyRHEL); err == nil || !strings.Contains(err.Error(), "createrepo_c") {
+		t.Errorf("Expected missing repodata error, got %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "repodata"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "repodata", "repomd.xml"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPackageCache(dir, packageFamilySUSE); err != nil {
+		t.Errorf("checkPackageCache() error = %v", err)
+	}
+}
+
+func TestPackageCacheCommands(t *testing.T) {
+	repo := packageCacheGuestDir + "/packages"
+	tests := []struct {
+		family, attach, detach string
+	}{
+		{packageFamilyDebian, "deb [trusted=yes] file:" + repo + " ./", "mv /etc/apt/kopru-saved/* /etc/apt/"},
+		{packageFamilyRHEL, "baseurl=file://" + repo, "mv /etc/yum.repos.d.kopru-saved /etc/yum.repos.d"},
+		{packageFamilySUSE, "type=rpm-md", "mv /etc/zypp/repos.d.kopru-saved /etc/zypp/repos.d"},
+	}
+	for _, tt := range tests {
+		attach, detach := packageCacheCommands(tt.family, repo)
+		if !strings.Contains(attach, tt.attach) {
+			t.Errorf("%s attach = %q, want it to contain %q", tt.family, attach, tt.attach)
+		}
+		if !strings.Contains(detach, tt.detach) || !strings.Contains(detach, "rm -rf "+packageCacheGuestDir) {
+			t.Errorf("%s detach = %q, want it to contain %q and remove the cache", tt.family, detach, tt.detach)
+		}
+	}
+}
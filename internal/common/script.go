@@ -0,0 +1,69 @@
+// Package common provides the static checks of custom scripts run inside guests.
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CheckScript checks a custom script before it is run inside a guest: the file must be
+// executable and start with a shebang, and shell scripts must pass bash -n. The syntax
+// check is skipped when bash is not installed.
+func CheckScript(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("script %s is not a regular file", path)
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("script %s is not executable (chmod +x %s)", path, path)
+	}
+	interpreter, err := scriptInterpreter(path)
+	if err != nil {
+		return err
+	}
+	if !isShell(interpreter) {
+		return nil
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		return nil
+	}
+	if output, err := RunCommand("bash", "-n", path); err != nil {
+		return fmt.Errorf("script %s has syntax errors: %w\nOutput: %s", path, err, output)
+	}
+	return nil
+}
+
+// scriptInterpreter returns the interpreter named by the shebang of the script.
+func scriptInterpreter(path string) (string, error) {
+	f, err := os.Open(path) // #nosec G304 -- scripts are configured by the user
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	shebang, ok := strings.CutPrefix(strings.TrimSpace(line), "#!")
+	fields := strings.Fields(shebang)
+	if !ok || len(fields) == 0 {
+		return "", fmt.Errorf("script %s has no shebang (e.g. #!/bin/bash)", path)
+	}
+	if filepath.Base(fields[0]) == "env" && len(fields) > 1 {
+		return fields[1], nil
+	}
+	return fields[0], nil
+}
+
+// isShell reports whether interpreter is a POSIX shell or bash.
+func isShell(interpreter string) bool {
+	switch filepath.Base(interpreter) {
+	case "sh", "bash", "dash", "ksh":
+		return true
+	}
+	return false
+}
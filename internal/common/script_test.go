@@ -0,0 +1,47 @@
+package common
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		mode    os.FileMode
+		bash    bool
+		wantErr string
+	}{
+		{"valid", "#!/bin/bash\necho ok\n", 0700, false, ""},
+		{"env shebang", "#!/usr/bin/env python3\nprint('ok')\n", 0755, false, ""},
+		{"not executable", "#!/bin/bash\necho ok\n", 0600, false, "not executable"},
+		{"no shebang", "echo ok\n", 0700, false, "no shebang"},
+		{"syntax error", "#!/bin/sh\nif true; then\n", 0700, true, "syntax errors"},
+		{"other interpreter not checked", "#!/usr/bin/python3\nif (\n", 0700, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := exec.LookPath("bash"); tt.bash && err != nil {
+				t.Skip("bash is not installed")
+			}
+			path := filepath.Join(t.TempDir(), "script")
+			if err := os.WriteFile(path, []byte(tt.content), tt.mode); err != nil {
+				t.Fatal(err)
+			}
+			err := CheckScript(path)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("CheckScript() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("CheckScript() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	if err := CheckScript(filepath.Join(t.TempDir(), "missing.sh")); err == nil {
+		t.Error("Expected error for missing script")
+	}
+}
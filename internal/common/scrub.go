@@ -0,0 +1,76 @@
+// Package common provides the removal of host-specific data and secrets from configured images.
+package common
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// ScrubCloudAgentCache is the scrub operation that deletes the state of cloud-init and
+// the Azure Linux agent. The other operations are virt-sysprep operations.
+const ScrubCloudAgentCache = "cloud-agent-cache"
+
+// DefaultScrubOperations are the scrub operations applied unless excluded.
+var DefaultScrubOperations = []string{
+	"machine-id", "ssh-hostkeys", "bash-history", "dhcp-client-state", "logfiles", "tmp-files", ScrubCloudAgentCache,
+}
+
+var cloudAgentCachePaths = []string{"/var/lib/cloud/*", "/var/lib/waagent/*"}
+
+// ScrubOptions selects what is removed from an image.
+type ScrubOptions struct {
+	Exclude []string // Default operations to skip
+	Include []string // Additional virt-sysprep operations, or absolute paths (globs) to delete
+	LUKSKey string   // libguestfs key selector that opens LUKS containers in the image
+}
+
+// virtSysprepArgs returns the virt-sysprep arguments for scrubbing imageFile. Paths are
+// deleted by the customize operation of virt-sysprep.
+func virtSysprepArgs(imageFile string, opts ScrubOptions) []string {
+	var operations, paths []string
+	for _, op := range DefaultScrubOperations {
+		if slices.Contains(opts.Exclude, op) {
+			continue
+		}
+		if op == ScrubCloudAgentCache {
+			paths = append(paths, cloudAgentCachePaths...)
+			continue
+		}
+		operations = append(operations, op)
+	}
+	for _, item := range opts.Include {
+		if strings.HasPrefix(item, "/") {
+			paths = append(paths, item)
+		} else if !slices.Contains(operations, item) {
+			operations = append(operations, item)
+		}
+	}
+	if len(paths) > 0 {
+		operations = append(operations, "customize")
+	}
+	args := append([]string{"env", "LIBGUESTFS_BACKEND=direct", "virt-sysprep"}, guestfsKeyArgs(opts.LUKSKey)...)
+	args = append(args, "-a", imageFile, "--operations", strings.Join(operations, ","))
+	for _, p := range paths {
+		args = append(args, "--delete", p)
+	}
+	return args
+}
+
+// ScrubImage removes machine identity, SSH host keys, shell histories, DHCP leases, logs
+// and cloud agent caches from imageFile with virt-sysprep, so that the image can be used
+// as a template for new instances.
+func ScrubImage(imageFile string, opts ScrubOptions, log *logger.Logger) error {
+	args := virtSysprepArgs(imageFile, opts)
+	log.Infof("Scrubbing image with virt-sysprep operations: %s", args[slices.Index(args, "--operations")+1])
+	// #nosec G204 -- the image file is produced by the workflow
+	cmd := exec.Command("sudo", args...)
+	if _, err := runWithProgress(cmd, func(line string) { log.Info(line) }); err != nil {
+		return fmt.Errorf("image scrubbing failed: %w", err)
+	}
+	log.Success("Image scrubbed")
+	return nil
+}
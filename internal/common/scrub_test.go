@@ -0,0 +1,50 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVirtSysprepArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ScrubOptions
+		want []string
+	}{
+		{
+			name: "defaults",
+			want: []string{
+				"env", "LIBGUESTFS_BACKEND=direct", "virt-sysprep", "-a", "os.qcow2",
+				"--operations", "machine-id,ssh-hostkeys,bash-history,dhcp-client-state,logfiles,tmp-files,customize",
+				"--delete", "/var/lib/cloud/*", "--delete", "/var/lib/waagent/*",
+			},
+		},
+		{
+			name: "exclude and include",
+			opts: ScrubOptions{
+				Exclude: []string{"logfiles", ScrubCloudAgentCache},
+				Include: []string{"package-manager-cache", "machine-id", "/opt/app/cache/*"},
+			},
+			want: []string{
+				"env", "LIBGUESTFS_BACKEND=direct", "virt-sysprep", "-a", "os.qcow2",
+				"--operations", "machine-id,ssh-hostkeys,bash-history,dhcp-client-state,tmp-files,package-manager-cache,customize",
+				"--delete", "/opt/app/cache/*",
+			},
+		},
+		{
+			name: "no paths",
+			opts: ScrubOptions{Exclude: []string{ScrubCloudAgentCache}},
+			want: []string{
+				"env", "LIBGUESTFS_BACKEND=direct", "virt-sysprep", "-a", "os.qcow2",
+				"--operations", "machine-id,ssh-hostkeys,bash-history,dhcp-client-state,logfiles,tmp-files",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := virtSysprepArgs("os.qcow2", tt.opts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("virtSysprepArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
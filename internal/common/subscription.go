@@ -0,0 +1,193 @@
+// Package common provides the detection of guest OS subscriptions and their re-registration on the first boot in OCI.
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// Guest paths that show how an image receives its updates.
+const (
+	rhsmConsumerCertPath   = "/etc/pki/consumer/cert.pem"             // Registered with subscription-manager
+	rhuiDir                = "/etc/pki/rhui"                          // Red Hat Update Infrastructure of the cloud (pay-as-you-go)
+	sccCredentialsPath     = "/etc/zypp/credentials.d/SCCcredentials" // Registered with SUSEConnect
+	suseRegionServerConfig = "/etc/regionserverclnt.cfg"              // SUSE public cloud update infrastructure (pay-as-you-go)
+)
+
+// Guest paths of the re-registration service.
+const (
+	ReregisterScriptPath = "/usr/local/sbin/kopru-reregister"
+	ReregisterConfigPath = "/etc/kopru/reregister.conf"
+	ReregisterUnitPath   = "/etc/systemd/system/kopru-reregister.service"
+)
+
+// GuestSubscriptions records how the RHEL or SLES guest of an image is subscribed.
+type GuestSubscriptions struct {
+	RHSM        bool // Registered with Red Hat Subscription Management
+	RHUI        bool // Updated from the Red Hat Update Infrastructure of the source cloud
+	SUSEConnect bool // Registered with the SUSE Customer Center or a registration proxy
+	SUSECloud   bool // Updated from the SUSE public cloud update infrastructure of the source cloud
+}
+
+// Any reports whether the guest has a subscription.
+func (s GuestSubscriptions) Any() bool {
+	return s.RHSM || s.RHUI || s.SUSEConnect || s.SUSECloud
+}
+
+// RHEL reports whether the guest is subscribed to Red Hat updates.
+func (s GuestSubscriptions) RHEL() bool {
+	return s.RHSM || s.RHUI
+}
+
+// SLES reports whether the guest is subscribed to SUSE updates.
+func (s GuestSubscriptions) SLES() bool {
+	return s.SUSEConnect || s.SUSECloud
+}
+
+// subscriptionPaths are the guest paths checked by DetectGuestSubscriptions, in the
+// order of their guestfish checks.
+var subscriptionPaths = []string{rhsmConsumerCertPath, rhuiDir, sccCredentialsPath, suseRegionServerConfig}
+
+// subscriptionCheckArgs returns the guestfish arguments that print true or false for
+// each of subscriptionPaths.
+func subscriptionCheckArgs(imageFile, luksKey string) []string {
+	args := append([]string{"env", "LIBGUESTFS_BACKEND=direct", "guestfish", "--ro"}, guestfsKeyArgs(luksKey)...)
+	args = append(args, "-a", imageFile, "-i")
+	for i, path := range subscriptionPaths {
+		if i > 0 {
+			args = append(args, ":")
+		}
+		args = append(args, "exists", path)
+	}
+	return args
+}
+
+// parseSubscriptionChecks maps the guestfish output of subscriptionCheckArgs. Lines
+// other than the results, such as libguestfs warnings, are ignored.
+func parseSubscriptionChecks(output string) (GuestSubscriptions, error) {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line == "true" || line == "false" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) != len(subscriptionPaths) {
+		return GuestSubscriptions{}, fmt.Errorf("unexpected guestfish output: %s", output)
+	}
+	found := make(map[string]bool, len(lines))
+	for i, line := range lines {
+		found[subscriptionPaths[i]] = line == "true"
+	}
+	return GuestSubscriptions{
+		RHSM:        found[rhsmConsumerCertPath],
+		RHUI:        found[rhuiDir],
+		SUSEConnect: found[sccCredentialsPath],
+		SUSECloud:   found[suseRegionServerConfig],
+	}, nil
+}
+
+// DetectGuestSubscriptions checks imageFile for the registration of its guest with Red
+// Hat Subscription Management or SUSEConnect, and for the update infrastructure of the
+// source cloud, which instances cannot reach in OCI.
+func DetectGuestSubscriptions(imageFile, luksKey string) (GuestSubscriptions, error) {
+	output, err := RunCommand("sudo", subscriptionCheckArgs(imageFile, luksKey)...)
+	if err != nil {
+		return GuestSubscriptions{}, fmt.Errorf("failed to check guest subscriptions: %w\nOutput: %s", err, output)
+	}
+	return parseSubscriptionChecks(output)
+}
+
+// ReregisterCredentials are the credentials the re-registration service registers with.
+type ReregisterCredentials struct {
+	RHSMOrgID            string
+	RHSMActivationKey    string
+	SUSERegistrationCode string
+	SUSEEmail            string
+}
+
+// reregisterScript removes the registration and cloud update infrastructure of the
+// source, and registers the instance with the configured credentials. It runs once: on
+// success it deletes its configuration, which holds the credentials, and disables itself.
+const reregisterScript = `#!/bin/sh
+# Kopru re-registration: registers the migrated instance with its subscription service.
+. ` + ReregisterConfigPath + ` || exit 0
+set -e
+if [ -n "$RHSM_ORG" ] && command -v subscription-manager >/dev/null 2>&1; then
+    if rpm -qa 'rhui-azure-*' | grep -q .; then
+        yum remove -y 'rhui-azure-*' || dnf remove -y 'rhui-azure-*'
+    fi
+    subscription-manager clean
+    subscription-manager register --org "$RHSM_ORG" --activationkey "$RHSM_KEY" --force
+elif [ -n "$SUSE_CODE" ] && command -v SUSEConnect >/dev/null 2>&1; then
+    if command -v registercloudguest >/dev/null 2>&1; then
+        registercloudguest --clean || true
+    fi
+    SUSEConnect --cleanup || true
+    if [ -n "$SUSE_EMAIL" ]; then
+        SUSEConnect -r "$SUSE_CODE" -e "$SUSE_EMAIL"
+    else
+        SUSEConnect -r "$SUSE_CODE"
+    fi
+else
+    echo "kopru-reregister: no subscription service to register with"
+fi
+rm -f ` + ReregisterConfigPath + `
+systemctl disable kopru-reregister.service >/dev/null 2>&1
+echo "kopru-reregister: instance registered"
+`
+
+const reregisterUnit = `[Unit]
+Description=Kopru subscription re-registration
+Wants=network-online.target
+After=network-online.target
+ConditionPathExists=` + ReregisterConfigPath + `
+
+[Service]
+Type=oneshot
+ExecStart=` + ReregisterScriptPath + `
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// shellQuote quotes value for a POSIX shell.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// reregisterConfig returns the configuration sourced by the re-registration script.
+func reregisterConfig(creds ReregisterCredentials) string {
+	return fmt.Sprintf("RHSM_ORG=%s\nRHSM_KEY=%s\nSUSE_CODE=%s\nSUSE_EMAIL=%s\n",
+		shellQuote(creds.RHSMOrgID), shellQuote(creds.RHSMActivationKey), shellQuote(creds.SUSERegistrationCode), shellQuote(creds.SUSEEmail))
+}
+
+// reregisterArgs returns the virt-customize operations that install the re-registration
+// service. Files are only written and linked, so no command runs inside the guest.
+func reregisterArgs(creds ReregisterCredentials) []string {
+	return []string{
+		"--mkdir", "/etc/kopru",
+		"--mkdir", "/etc/systemd/system/multi-user.target.wants",
+		"--write", ReregisterScriptPath + ":" + reregisterScript,
+		"--chmod", "0755:" + ReregisterScriptPath,
+		"--write", ReregisterConfigPath + ":" + reregisterConfig(creds),
+		"--chmod", "0600:" + ReregisterConfigPath,
+		"--write", ReregisterUnitPath + ":" + reregisterUnit,
+		"--link", ReregisterUnitPath + ":/etc/systemd/system/multi-user.target.wants/kopru-reregister.service",
+	}
+}
+
+// InstallReregistration installs a one-shot systemd service into imageFile that, on the
+// first boot of the instance, registers it with subscription-manager or SUSEConnect.
+// Like the boot beacon, it must be installed before the OS configuration, which
+// relabels SELinux guests.
+func InstallReregistration(imageFile string, creds ReregisterCredentials, luksKey string, log *logger.Logger) error {
+	log.Info("Installing subscription re-registration service ...")
+	args := append(guestfsToolArgs("virt-customize", imageFile, luksKey), reregisterArgs(creds)...)
+	if output, err := RunCommand("sudo", args...); err != nil {
+		return fmt.Errorf("failed to install the re-registration service: %w\nOutput: %s", err, output)
+	}
+	log.Success("Subscription re-registration service installed")
+	return nil
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSubscriptionChecks(t *testing.T) {
+	args := subscriptionCheckArgs("disk.qcow2", "all:file:/tmp/key")
+	want := "env LIBGUESTFS_BACKEND=direct guestfish --ro --key all:file:/tmp/key -a disk.qcow2 -i exists /etc/pki/consumer/cert.pem : exists /etc/pki/rhui : exists /etc/zypp/credentials.d/SCCcredentials : exists /etc/regionserverclnt.cfg"
+	if got := strings.Join(args, " "); got != want {
+		t.Errorf("subscriptionCheckArgs() = %s", got)
+	}
+
+	got, err := parseSubscriptionChecks("libguestfs: warning: something\nfalse\ntrue\nfalse\nfalse\n")
+	if err != nil || got != (GuestSubscriptions{RHUI: true}) || !got.RHEL() || got.SLES() {
+		t.Errorf("parseSubscriptionChecks() = %+v, %v", got, err)
+	}
+	if _, err := parseSubscriptionChecks("true\n"); err == nil {
+		t.Error("Expected an error for incomplete output")
+	}
+}
+
+func TestReregisterArgs(t *testing.T) {
+	args := reregisterArgs(ReregisterCredentials{RHSMOrgID: "1234", RHSMActivationKey: "it's-secret"})
+	for _, op := range []string{"--run-command", "--firstboot-command", "--run"} {
+		if slices.Contains(args, op) {
+			t.Errorf("reregisterArgs() runs commands in the guest: %v", args)
+		}
+	}
+	joined := strings.Join(args, "\n")
+	for _, want := range []string{
+		ReregisterScriptPath + ":#!/bin/sh",
+		ReregisterConfigPath + ":RHSM_ORG='1234'\nRHSM_KEY='it'\\''s-secret'\nSUSE_CODE=''\nSUSE_EMAIL=''\n",
+		"0600:" + ReregisterConfigPath,
+		ReregisterUnitPath + ":/etc/systemd/system/multi-user.target.wants/kopru-reregister.service",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("reregisterArgs() missing %q", want)
+		}
+	}
+}
@@ -3,20 +3,29 @@ package common
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
 	"golang.org/x/sys/unix"
 )
 
+var (
+	qemuProgressPattern = regexp.MustCompile(`^\((\d+(?:\.\d+)?)/100%\)$`) // qemu-img convert -p output, e.g. "(42.00/100%)"
+	ddProgressPattern   = regexp.MustCompile(`^(\d+) bytes .* copied`)     // dd status=progress output
+)
+
 const (
 	OCIMinVolumeSizeGB = 50  // Minimum volume size in GB for OCI block volumes
 	MinDiskSpaceGB     = 500 // Recommended minimum disk space in GB for migration operations
@@ -96,21 +105,39 @@ func GetAvailableDiskSpace(path string, minDiskSpaceGB int64) (int64, error) {
 	return available, nil
 }
 
-// GetFileSizeGB returns the size of a file in gigabytes, rounded up and enforcing OCI minimum.
+// ImageSize returns the size of a disk image, which is either a file or a block device
+// such as an NBD device, whose size os.Stat does not report.
+func ImageSize(path string) (int64, error) {
+	f, err := os.Open(path) // #nosec G304 -- the image is produced by the workflow
+	if err != nil {
+		return 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the size of %s: %w", path, err)
+	}
+	return size, nil
+}
+
+// GetFileSizeGB returns the size of a file or block device in gigabytes, rounded up and enforcing OCI minimum.
 func GetFileSizeGB(filePath string) (int64, error) {
-	info, err := os.Stat(filePath)
+	size, err := ImageSize(filePath)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get file info: %w", err)
 	}
-	sizeGB := (info.Size() + (1024*1024*1024 - 1)) / (1024 * 1024 * 1024)
+	sizeGB := (size + (1024*1024*1024 - 1)) / (1024 * 1024 * 1024)
 	if sizeGB < OCIMinVolumeSizeGB {
 		sizeGB = OCIMinVolumeSizeGB
 	}
 	return sizeGB, nil
 }
 
-// CopyDataWithDD copies data from source to destination using dd.
-func CopyDataWithDD(source, destination string) error {
+// CopyDataWithDD copies data from source to destination using dd, reporting progress from dd's status output.
+func CopyDataWithDD(source, destination string, log *logger.Logger) error {
+	total, _ := ImageSize(source)
+	rep := progress.New(log, "Copying "+filepath.Base(source), total).Record(progress.PhaseCopy, filepath.Base(source))
+	defer rep.Done()
 	// #nosec G204 -- source and destination are controlled by the application
 	cmd := exec.Command("dd",
 		"if="+source,
@@ -118,15 +145,15 @@ func CopyDataWithDD(source, destination string) error {
 		"bs=8M",
 		"status=progress",
 		"conv=sparse")
-	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	output, err := runWithProgress(cmd, func(line string) {
+		if m := ddProgressPattern.FindStringSubmatch(line); m != nil {
+			if n, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+				rep.Set(n)
+			}
+		}
+	})
 	if err != nil {
-		return fmt.Errorf("failed to open /dev/null: %w", err)
-	}
-	defer devNull.Close()
-	cmd.Stdout = devNull
-	cmd.Stderr = devNull
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to copy data with dd: %w", err)
+		return fmt.Errorf("failed to copy data with dd: %w\nOutput: %s", err, output)
 	}
 	return nil
 }
@@ -162,7 +189,9 @@ func ListBlockDevices() ([]string, error) {
 	return devices, nil
 }
 
-// DetectNewBlockDevice detects a newly attached block device by comparing before and after device lists.
+// DetectNewBlockDevice detects a newly attached block device by comparing before and after
+// device lists. It fails when several devices appeared, as it cannot tell which one was
+// attached; check the device with CheckTargetDevice before writing to it.
 func DetectNewBlockDevice(beforeDevices []string) (string, error) {
 	const (
 		retryInterval = 5 * time.Second
@@ -177,7 +206,10 @@ func DetectNewBlockDevice(beforeDevices []string) (string, error) {
 			return "", fmt.Errorf("failed to list block devices on attempt %d: %w", i+1, err)
 		}
 		newDevices := SliceDifference(afterDevices, beforeDevices)
-		if len(newDevices) > 0 {
+		if len(newDevices) > 1 {
+			return "", fmt.Errorf("several new block devices detected (%s); cannot tell which one was attached", strings.Join(newDevices, ", "))
+		}
+		if len(newDevices) == 1 {
 			return "/dev/" + newDevices[0], nil
 		}
 	}
@@ -199,6 +231,44 @@ func DataDiskDevicePath(index int) string {
 	return "/dev/oracleoci/oraclevd" + suffix
 }
 
+// dataDiskDevices tracks the data disk device paths reserved by volume attachments of
+// this process.
+var dataDiskDevices = struct {
+	sync.Mutex
+	reserved map[string]bool
+}{reserved: make(map[string]bool)}
+
+// deviceExists reports whether a device path is present on the host.
+var deviceExists = func(devicePath string) bool {
+	_, err := os.Lstat(devicePath)
+	return err == nil
+}
+
+// ReserveDataDiskDevicePath returns the first OCI paravirtualized device path that is
+// neither present on the host, for example because a volume holding the work directory
+// is attached there, nor reserved by another attachment of this process. Release it with
+// ReleaseDataDiskDevicePath once the volume is detached.
+func ReserveDataDiskDevicePath() (string, error) {
+	dataDiskDevices.Lock()
+	defer dataDiskDevices.Unlock()
+	for i := 0; i <= 31; i++ {
+		devicePath := DataDiskDevicePath(i)
+		if dataDiskDevices.reserved[devicePath] || deviceExists(devicePath) {
+			continue
+		}
+		dataDiskDevices.reserved[devicePath] = true
+		return devicePath, nil
+	}
+	return "", fmt.Errorf("no free data disk device path under /dev/oracleoci")
+}
+
+// ReleaseDataDiskDevicePath releases a device path reserved by ReserveDataDiskDevicePath.
+func ReleaseDataDiskDevicePath(devicePath string) {
+	dataDiskDevices.Lock()
+	defer dataDiskDevices.Unlock()
+	delete(dataDiskDevices.reserved, devicePath)
+}
+
 // WaitForDevice waits for a specific block device to become available at the given path.
 func WaitForDevice(devicePath string) (string, error) {
 	const (
@@ -217,8 +287,8 @@ func WaitForDevice(devicePath string) (string, error) {
 }
 
 // ConvertVHDToQCOW2 converts a VHD file to QCOW2 format. The VHD file is always kept for auditing purposes.
-func ConvertVHDToQCOW2(vhdFile, qcow2File string) error {
-	if output, err := RunCommand("qemu-img", "convert", "-f", "vpc", "-O", "qcow2", vhdFile, qcow2File); err != nil {
+func ConvertVHDToQCOW2(vhdFile, qcow2File string, log *logger.Logger) error {
+	if output, err := convertImage(vhdFile, qcow2File, "qcow2", log); err != nil {
 		return fmt.Errorf("qemu-img convert failed: %w\nOutput: %s", err, output)
 	}
 	if output, err := RunCommand("qemu-img", "resize", qcow2File, "+5M"); err != nil {
@@ -228,7 +298,7 @@ func ConvertVHDToQCOW2(vhdFile, qcow2File string) error {
 }
 
 // ConvertVHDToRAW converts a VHD file to RAW format. The VHD file is always kept for auditing purposes.
-func ConvertVHDToRAW(vhdFile, rawFile string) error {
+func ConvertVHDToRAW(vhdFile, rawFile string, log *logger.Logger) error {
 	if vhdFile == "" {
 		return fmt.Errorf("VHD file path cannot be empty")
 	}
@@ -238,12 +308,82 @@ func ConvertVHDToRAW(vhdFile, rawFile string) error {
 	if _, err := os.Stat(vhdFile); os.IsNotExist(err) {
 		return fmt.Errorf("VHD file not found: %s", vhdFile)
 	}
-	if output, err := RunCommand("qemu-img", "convert", "-f", "vpc", "-O", "raw", vhdFile, rawFile); err != nil {
+	if output, err := convertImage(vhdFile, rawFile, "raw", log); err != nil {
 		return fmt.Errorf("qemu-img convert to RAW failed: %w\nOutput: %s", err, output)
 	}
 	return nil
 }
 
+// convertImage runs qemu-img convert from VHD to the given output format, reporting
+// progress from the percentages printed by qemu-img -p.
+func convertImage(vhdFile, outFile, format string, log *logger.Logger) (string, error) {
+	var total int64
+	if info, err := os.Stat(vhdFile); err == nil {
+		total = info.Size()
+	}
+	rep := progress.New(log, "Converting "+filepath.Base(vhdFile), total).Record(progress.PhaseConvert, filepath.Base(vhdFile))
+	defer rep.Done()
+	// #nosec G204 -- file paths are controlled by the application
+	cmd := exec.Command("qemu-img", "convert", "-p", "-f", "vpc", "-O", format, vhdFile, outFile)
+	return runWithProgress(cmd, func(line string) {
+		if m := qemuProgressPattern.FindStringSubmatch(line); m != nil {
+			if pct, err := strconv.ParseFloat(m[1], 64); err == nil {
+				rep.Set(int64(pct / 100 * float64(total)))
+			}
+		}
+	})
+}
+
+// runWithProgress runs cmd and passes each line of its combined output to onLine as it
+// is produced. Lines terminated by a carriage return (progress updates) are included.
+// Progress updates are not part of the returned output.
+func runWithProgress(cmd *exec.Cmd, onLine func(string)) (string, error) {
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("command failed: %w", err)
+	}
+
+	var output strings.Builder
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(pr)
+		scanner.Split(scanProgressLines)
+		for scanner.Scan() {
+			line := scanner.Text()
+			onLine(line)
+			if !qemuProgressPattern.MatchString(line) && !ddProgressPattern.MatchString(line) {
+				output.WriteString(line + "\n")
+			}
+		}
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+
+	err := cmd.Wait()
+	pw.Close()
+	<-done
+	if err != nil {
+		return output.String(), fmt.Errorf("command failed: %w", err)
+	}
+	return output.String(), nil
+}
+
+// scanProgressLines is a bufio.SplitFunc that splits on both newlines and carriage returns.
+func scanProgressLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, bytes.TrimSpace(data[:i]), nil
+	}
+	if atEOF {
+		return len(data), bytes.TrimSpace(data), nil
+	}
+	return 0, nil, nil
+}
+
 // GetComputeOSDiskSizeGB reads the virtual size of a QCOW2 file and returns the size in GB.
 func GetComputeOSDiskSizeGB(qcow2File string) (int64, error) {
 	output, err := RunCommand("qemu-img", "info", qcow2File)
@@ -271,18 +411,47 @@ func GetComputeOSDiskSizeGB(qcow2File string) (int64, error) {
 	return 0, fmt.Errorf("virtual size not found in qemu-img output")
 }
 
+// OSConfigOptions holds optional settings passed to the OS configuration scripts.
+type OSConfigOptions struct {
+	SSHPublicKey         string // Added to the authorized_keys of the image's login users
+	BreakglassUser       string // Temporary user with passwordless sudo, created when set
+	BreakglassExpiryDays int    // Days until the break-glass account expires (0 never expires)
+	LUKSKey              string // libguestfs key selector that opens LUKS containers in the image
+	DeferGuestCommands   bool   // Run commands and package installs on the first boot instead
+}
+
+// env returns the options as environment variables for the scripts.
+func (o OSConfigOptions) env() []string {
+	return []string{
+		"KOPRU_SSH_PUBLIC_KEY=" + o.SSHPublicKey,
+		"KOPRU_BREAKGLASS_USER=" + o.BreakglassUser,
+		fmt.Sprintf("KOPRU_BREAKGLASS_EXPIRY_DAYS=%d", o.BreakglassExpiryDays),
+		"KOPRU_LUKS_KEY=" + o.LUKSKey,
+		fmt.Sprintf("KOPRU_DEFER_GUEST_COMMANDS=%t", o.DeferGuestCommands),
+	}
+}
+
 // ExecuteOSConfigScript executes an OS configuration script from the scripts/os-config directory.
-func ExecuteOSConfigScript(imageFile, osType, sourcePlatform string, log *logger.Logger) error {
+func ExecuteOSConfigScript(imageFile, osType, sourcePlatform string, opts OSConfigOptions, log *logger.Logger) error {
 	if sourcePlatform == "azure" && IsLinuxOS(osType) {
-		return executeScript(imageFile, "azure_to_oci.sh", log)
+		return executeScript(imageFile, "azure_to_oci.sh", opts, log)
 	}
 	if sourcePlatform == "linux_image" {
-		return executeScript(imageFile, "linux_image_to_oci.sh", log)
+		return executeScript(imageFile, "linux_image_to_oci.sh", opts, log)
 	}
 	log.Infof("Skipping OS configuration for OS type '%s'", osType)
 	return nil
 }
 
+// guestfsKeyArgs returns the libguestfs tool arguments that open LUKS containers with
+// the key selector luksKey, e.g. all:file:/path/to/keyfile.
+func guestfsKeyArgs(luksKey string) []string {
+	if luksKey == "" {
+		return nil
+	}
+	return []string{"--key", luksKey}
+}
+
 // IsLinuxOS checks if the given operating system string is a Linux-based OS.
 func IsLinuxOS(operatingSystem string) bool {
 	osLower := strings.ToLower(strings.TrimSpace(operatingSystem))
@@ -299,7 +468,7 @@ func IsLinuxOS(operatingSystem string) bool {
 }
 
 // executeScript executes a built-in bash script from the scripts/os-config directory with the image file path as argument.
-func executeScript(imageFile, scriptPath string, log *logger.Logger) error {
+func executeScript(imageFile, scriptPath string, opts OSConfigOptions, log *logger.Logger) error {
 	execPath, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("failed to get executable path: %w", err)
@@ -315,10 +484,11 @@ func executeScript(imageFile, scriptPath string, log *logger.Logger) error {
 		log.Warningf("Could not make script executable: %v", err)
 	}
 
-	env := append(os.Environ(), fmt.Sprintf("KOPRU_IMAGE_FILE=%s", imageFile))
+	// sudo resets the environment, so the script settings are passed through env(1).
+	args := append([]string{"env", fmt.Sprintf("KOPRU_IMAGE_FILE=%s", imageFile)}, opts.env()...)
+	args = append(args, fullScriptPath, imageFile)
 	// #nosec G204 -- fullScriptPath is resolved from the application's own executable directory
-	cmd := exec.Command("sudo", fullScriptPath, imageFile)
-	cmd.Env = env
+	cmd := exec.Command("sudo", args...)
 
 	log.Infof("Starting script execution: %s", filepath.Base(fullScriptPath))
 
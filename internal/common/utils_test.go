@@ -2,9 +2,11 @@
 package common
 
 import (
+	"bufio"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -123,6 +125,27 @@ func TestSliceDifference(t *testing.T) {
 	}
 }
 
+func TestGetFileSizeGB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "disk.raw")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Truncate(60<<30 + 1); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if size, err := ImageSize(path); err != nil || size != 60<<30+1 {
+		t.Errorf("ImageSize() = %d, %v, want %d", size, err, int64(60<<30+1))
+	}
+	if got, err := GetFileSizeGB(path); err != nil || got != 61 {
+		t.Errorf("GetFileSizeGB() = %d, %v, want 61", got, err)
+	}
+	if _, err := ImageSize(filepath.Join(t.TempDir(), "missing.raw")); err == nil {
+		t.Error("ImageSize() succeeded for a missing file")
+	}
+}
+
 func TestDataDiskDevicePath(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -148,6 +171,35 @@ func TestDataDiskDevicePath(t *testing.T) {
 	}
 }
 
+func TestReserveDataDiskDevicePath(t *testing.T) {
+	orig := deviceExists
+	t.Cleanup(func() { deviceExists = orig })
+	deviceExists = func(devicePath string) bool { return devicePath == "/dev/oracleoci/oraclevdb" }
+
+	first, err := ReserveDataDiskDevicePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ReserveDataDiskDevicePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != "/dev/oracleoci/oraclevdc" || second != "/dev/oracleoci/oraclevdd" {
+		t.Errorf("Reserved %q and %q, want oraclevdc and oraclevdd", first, second)
+	}
+
+	ReleaseDataDiskDevicePath(first)
+	again, err := ReserveDataDiskDevicePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != first {
+		t.Errorf("Reserved %q after release, want %q", again, first)
+	}
+	ReleaseDataDiskDevicePath(again)
+	ReleaseDataDiskDevicePath(second)
+}
+
 func TestWaitForDevice(t *testing.T) {
 	t.Run("Device exists immediately", func(t *testing.T) {
 		dir := t.TempDir()
@@ -180,3 +232,24 @@ func TestWaitForDevice(t *testing.T) {
 		}
 	})
 }
+
+func TestScanProgressLines(t *testing.T) {
+	input := "    (0.00/100%)\r    (42.50/100%)\r1073741824 bytes (1.1 GB, 1.0 GiB) copied, 2 s, 537 MB/s\rdone\n"
+	scanner := bufio.NewScanner(strings.NewReader(input))
+	scanner.Split(scanProgressLines)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	expected := []string{"(0.00/100%)", "(42.50/100%)", "1073741824 bytes (1.1 GB, 1.0 GiB) copied, 2 s, 537 MB/s", "done"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected %q, got %q", expected, lines)
+	}
+
+	if m := qemuProgressPattern.FindStringSubmatch(lines[1]); m == nil || m[1] != "42.50" {
+		t.Errorf("Expected qemu-img progress 42.50, got %v", m)
+	}
+	if m := ddProgressPattern.FindStringSubmatch(lines[2]); m == nil || m[1] != "1073741824" {
+		t.Errorf("Expected dd progress 1073741824, got %v", m)
+	}
+}
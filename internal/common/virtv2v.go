@@ -0,0 +1,55 @@
+// Package common provides guest conversion of images with virt-v2v.
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// Engines used to configure images for OCI.
+const (
+	ConfigureEngineBuiltin = "builtin"  // Built-in OS configuration scripts
+	ConfigureEngineVirtV2V = "virt-v2v" // Guest conversion by virt-v2v
+)
+
+// virtV2VArgs returns the virt-v2v arguments that convert imageFile for KVM with virtio
+// devices and write the result as <outputDir>/<name>-sda in QCOW2 format.
+func virtV2VArgs(imageFile, outputDir, name, luksKey string) []string {
+	args := []string{"env", "LIBGUESTFS_BACKEND=direct"}
+	// virt-v2v installs the Windows virtio drivers from the virtio-win ISO or directory in VIRTIO_WIN.
+	if virtioWin := os.Getenv("VIRTIO_WIN"); virtioWin != "" {
+		args = append(args, "VIRTIO_WIN="+virtioWin)
+	}
+	args = append(append(args, "virt-v2v"), guestfsKeyArgs(luksKey)...)
+	return append(args, "-i", "disk", imageFile, "-o", "local", "-os", outputDir, "-of", "qcow2", "-on", name)
+}
+
+// ConvertWithVirtV2V converts the guest in imageFile with virt-v2v, which installs the
+// virtio drivers, rebuilds the initramfs and updates the boot loader, and replaces
+// imageFile with the converted image. LUKS containers are opened with the key selector
+// luksKey when set.
+func ConvertWithVirtV2V(imageFile, luksKey string, log *logger.Logger) error {
+	outputDir, err := os.MkdirTemp(filepath.Dir(imageFile), "virt-v2v-")
+	if err != nil {
+		return fmt.Errorf("failed to create virt-v2v output directory: %w", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	name := strings.TrimSuffix(filepath.Base(imageFile), filepath.Ext(imageFile))
+	log.Infof("Converting guest with virt-v2v: %s", imageFile)
+	// #nosec G204 -- the image file is produced by the workflow
+	cmd := exec.Command("sudo", virtV2VArgs(imageFile, outputDir, name, luksKey)...)
+	if _, err := runWithProgress(cmd, func(line string) { log.Info(line) }); err != nil {
+		return fmt.Errorf("virt-v2v conversion failed: %w", err)
+	}
+	if err := os.Rename(filepath.Join(outputDir, name+"-sda"), imageFile); err != nil {
+		return fmt.Errorf("failed to replace image with converted image: %w", err)
+	}
+	log.Success("virt-v2v conversion complete")
+	return nil
+}
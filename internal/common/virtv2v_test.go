@@ -0,0 +1,30 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVirtV2VArgs(t *testing.T) {
+	t.Setenv("VIRTIO_WIN", "")
+	want := []string{
+		"env", "LIBGUESTFS_BACKEND=direct",
+		"virt-v2v", "-i", "disk", "/data/vm-os.qcow2", "-o", "local", "-os", "/data/virt-v2v-1", "-of", "qcow2", "-on", "vm-os",
+	}
+	if got := virtV2VArgs("/data/vm-os.qcow2", "/data/virt-v2v-1", "vm-os", ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("virtV2VArgs() = %q, want %q", got, want)
+	}
+
+	t.Setenv("VIRTIO_WIN", "/usr/share/virtio-win/virtio-win.iso")
+	if got := virtV2VArgs("/data/vm-os.qcow2", "/data/virt-v2v-1", "vm-os", ""); got[2] != "VIRTIO_WIN=/usr/share/virtio-win/virtio-win.iso" {
+		t.Errorf("Expected VIRTIO_WIN to be passed to virt-v2v, got %q", got)
+	}
+
+	want = []string{
+		"env", "LIBGUESTFS_BACKEND=direct", "VIRTIO_WIN=/usr/share/virtio-win/virtio-win.iso",
+		"virt-v2v", "--key", "all:file:/tmp/luks", "-i", "disk", "/data/vm-os.qcow2", "-o", "local", "-os", "/data/virt-v2v-1", "-of", "qcow2", "-on", "vm-os",
+	}
+	if got := virtV2VArgs("/data/vm-os.qcow2", "/data/virt-v2v-1", "vm-os", "all:file:/tmp/luks"); !reflect.DeepEqual(got, want) {
+		t.Errorf("virtV2VArgs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+// Package common provides the watchdog that recovers stuck mount and unmount commands.
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// mountTimeout is how long guestmount, guestunmount and fusermount may run before the
+// watchdog kills them. Overridable in tests.
+var mountTimeout = 5 * time.Minute
+
+// mountAttempts is the number of mount points at which MountGuestFilesystem tries to
+// mount a filesystem whose mount gets stuck.
+const mountAttempts = 3
+
+// killGrace is how long a command terminated by the watchdog may take to exit before it
+// is killed.
+const killGrace = 10 * time.Second
+
+// StuckCommandError reports a command that the watchdog killed because it did not
+// complete within its timeout, e.g. a guestmount waiting for a wedged FUSE device.
+type StuckCommandError struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (e *StuckCommandError) Error() string {
+	return fmt.Sprintf("%s did not complete within %s and was killed", e.Command, e.Timeout)
+}
+
+// WatchdogIncident records a stuck command killed by the watchdog and how the
+// operation was recovered.
+type WatchdogIncident struct {
+	Time     time.Time
+	Command  string
+	Target   string // Mount point or NBD device the command operated on
+	Recovery string // What was done instead, e.g. a retry at another mount point
+}
+
+var watchdogIncidents struct {
+	mu        sync.Mutex
+	incidents []WatchdogIncident
+}
+
+// recordIncident records that the watchdog killed command operating on target.
+func recordIncident(command, target, recovery string) {
+	watchdogIncidents.mu.Lock()
+	defer watchdogIncidents.mu.Unlock()
+	watchdogIncidents.incidents = append(watchdogIncidents.incidents, WatchdogIncident{
+		Time:     time.Now().UTC(),
+		Command:  command,
+		Target:   target,
+		Recovery: recovery,
+	})
+}
+
+// WatchdogIncidents returns the stuck commands killed by the watchdog so far.
+func WatchdogIncidents() []WatchdogIncident {
+	watchdogIncidents.mu.Lock()
+	defer watchdogIncidents.mu.Unlock()
+	return append([]WatchdogIncident(nil), watchdogIncidents.incidents...)
+}
+
+// runWatched runs a command like RunCommand, except that a command that does not
+// complete within timeout is terminated, and killed if it does not exit within
+// killGrace; a StuckCommandError is then returned. The command is terminated rather
+// than killed first so that sudo relays the signal to the command it runs.
+var runWatched = func(timeout time.Duration, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	cmd.WaitDelay = killGrace
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return string(output), &StuckCommandError{Command: commandName(name, args), Timeout: timeout}
+	}
+	if err != nil {
+		return string(output), fmt.Errorf("command failed: %w", err)
+	}
+	return string(output), nil
+}
+
+// commandName returns the name of the command run by name and args, skipping sudo and
+// env with its variable assignments.
+func commandName(name string, args []string) string {
+	command := append([]string{name}, args...)
+	for len(command) > 1 && (command[0] == "sudo" || command[0] == "env" || strings.Contains(command[0], "=")) {
+		command = command[1:]
+	}
+	return command[0]
+}
+
+// detachMount lazily unmounts mountPoint, so that a mount left behind by a killed
+// command no longer blocks the mount point, whatever state its FUSE daemon is in.
+func detachMount(mountPoint string) error {
+	if output, err := runWatched(mountTimeout, "sudo", "fusermount", "-u", "-z", mountPoint); err != nil {
+		return fmt.Errorf("failed to detach %s: %w\nOutput: %s", mountPoint, err, output)
+	}
+	return nil
+}
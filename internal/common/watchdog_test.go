@@ -0,0 +1,54 @@
+package common
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestRunWatchedKillsStuckCommand(t *testing.T) {
+	start := time.Now()
+	_, err := runWatched(50*time.Millisecond, "sleep", "10")
+	var stuck *StuckCommandError
+	if !errors.As(err, &stuck) || stuck.Command != "sleep" {
+		t.Fatalf("runWatched() error = %v, want a StuckCommandError", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Stuck command was killed after %s", elapsed)
+	}
+	if output, err := runWatched(time.Minute, "echo", "mounted"); err != nil || strings.TrimSpace(output) != "mounted" {
+		t.Errorf("runWatched() = %q, %v", output, err)
+	}
+}
+
+func TestMountGuestFilesystemRetriesStuckMount(t *testing.T) {
+	defer func(run func(time.Duration, string, ...string) (string, error)) { runWatched = run }(runWatched)
+	var commands [][]string
+	runWatched = func(timeout time.Duration, name string, args ...string) (string, error) {
+		commands = append(commands, args)
+		if commandName(name, args) == "guestmount" && len(commands) == 1 {
+			return "", &StuckCommandError{Command: "guestmount", Timeout: timeout}
+		}
+		return "", nil
+	}
+	mountPoint := filepath.Join(t.TempDir(), "data-sda1")
+	got, err := MountGuestFilesystem("disk.raw", "/dev/sda1", mountPoint, "", logger.New(false))
+	if err != nil {
+		t.Fatalf("MountGuestFilesystem() error = %v", err)
+	}
+	if got != mountPoint+"-2" {
+		t.Errorf("MountGuestFilesystem() = %s, want %s-2", got, mountPoint)
+	}
+	if len(commands) != 3 || !slices.Equal(commands[1], []string{"fusermount", "-u", "-z", mountPoint}) {
+		t.Errorf("Unexpected commands: %q", commands)
+	}
+	incidents := WatchdogIncidents()
+	if len(incidents) == 0 || incidents[len(incidents)-1].Target != mountPoint || incidents[len(incidents)-1].Recovery != "retried at "+mountPoint+"-2" {
+		t.Errorf("Unexpected incidents: %+v", incidents)
+	}
+}
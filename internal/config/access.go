@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var userNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+// SSHPublicKey returns OCI_SSH_PUBLIC_KEY, or the content of SSH_KEY_FILE when only
+// the key file is configured. It returns an empty string when neither is set.
+func (c *Config) SSHPublicKey() (string, error) {
+	if c.OCISSHPublicKey != "" {
+		return strings.TrimSpace(c.OCISSHPublicKey), nil
+	}
+	if c.SSHKeyFilePath == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(c.SSHKeyFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read SSH key file %s: %w", c.SSHKeyFilePath, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// validateAccess checks the SSH key and break-glass user settings.
+func (c *Config) validateAccess() error {
+	var errs []error
+	if c.OCISSHPublicKey != "" && len(strings.Fields(c.OCISSHPublicKey)) < 2 {
+		errs = append(errs, fmt.Errorf("OCI_SSH_PUBLIC_KEY is not an OpenSSH public key"))
+	}
+	if c.BreakglassUser != "" {
+		if !userNamePattern.MatchString(c.BreakglassUser) || c.BreakglassUser == "root" {
+			errs = append(errs, fmt.Errorf("KOPRU_BREAKGLASS_USER is not a valid user name: '%s'", c.BreakglassUser))
+		}
+		if c.OCISSHPublicKey == "" && c.SSHKeyFilePath == "" {
+			errs = append(errs, fmt.Errorf("KOPRU_BREAKGLASS_USER requires OCI_SSH_PUBLIC_KEY or SSH_KEY_FILE"))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSSHPublicKey(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519.pub")
+	if err := os.WriteFile(keyFile, []byte("ssh-ed25519 AAAAfile user@host\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		want    string
+		wantErr bool
+	}{
+		{"not configured", Config{}, "", false},
+		{"key file", Config{SSHKeyFilePath: keyFile}, "ssh-ed25519 AAAAfile user@host", false},
+		{"key takes precedence", Config{OCISSHPublicKey: " ssh-rsa AAAAkey ", SSHKeyFilePath: keyFile}, "ssh-rsa AAAAkey", false},
+		{"missing key file", Config{SSHKeyFilePath: filepath.Join(t.TempDir(), "missing.pub")}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.SSHPublicKey()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SSHPublicKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SSHPublicKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{"not configured", Config{}, ""},
+		{"break-glass user with key", Config{OCISSHPublicKey: "ssh-ed25519 AAAA", BreakglassUser: "kopru-admin"}, ""},
+		{"break-glass user without key", Config{BreakglassUser: "kopru-admin"}, "requires OCI_SSH_PUBLIC_KEY"},
+		{"invalid user name", Config{OCISSHPublicKey: "ssh-ed25519 AAAA", BreakglassUser: "Admin User"}, "not a valid user name"},
+		{"root", Config{OCISSHPublicKey: "ssh-ed25519 AAAA", BreakglassUser: "root"}, "not a valid user name"},
+		{"malformed key", Config{OCISSHPublicKey: "AAAA"}, "not an OpenSSH public key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validateAccess()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AgentPlugins are the Oracle Cloud Agent plugins that OCI_AGENT_PLUGINS can enable, by
+// their short name.
+var AgentPlugins = map[string]string{
+	"monitoring":             "Compute Instance Monitoring",
+	"custom-logs":            "Custom Logs Monitoring",
+	"management":             "Management Agent",
+	"vulnerability-scanning": "Vulnerability Scanning",
+	"bastion":                "Bastion",
+	"os-management-hub":      "OS Management Hub Agent",
+	"run-command":            "Compute Instance Run Command",
+}
+
+// Desired states of Oracle Cloud Agent plugins.
+const (
+	AgentPluginEnabled  = "ENABLED"
+	AgentPluginDisabled = "DISABLED"
+)
+
+// AgentPluginStates returns the desired state of each plugin of AgentPlugins by plugin
+// name. The plugins of OCI_AGENT_PLUGINS are enabled, as is Run Command when
+// FINISHING_SCRIPT is set, and the others are disabled. Unknown names are reported by
+// validateAgentPlugins.
+func (c *Config) AgentPluginStates() map[string]string {
+	states := make(map[string]string, len(AgentPlugins))
+	for _, plugin := range AgentPlugins {
+		states[plugin] = AgentPluginDisabled
+	}
+	for _, name := range splitList(c.OCIAgentPlugins) {
+		if plugin, ok := AgentPlugins[strings.ToLower(name)]; ok {
+			states[plugin] = AgentPluginEnabled
+		}
+	}
+	if c.FinishingScript != "" {
+		states[AgentPlugins["run-command"]] = AgentPluginEnabled
+	}
+	return states
+}
+
+// validateAgentPlugins checks that OCI_AGENT_PLUGINS names known plugins.
+func (c *Config) validateAgentPlugins() error {
+	var errs []error
+	for _, name := range splitList(c.OCIAgentPlugins) {
+		if _, ok := AgentPlugins[strings.ToLower(name)]; !ok {
+			errs = append(errs, fmt.Errorf("OCI_AGENT_PLUGINS: unknown plugin '%s', expected one of %s", name, strings.Join(agentPluginNames(), ", ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// agentPluginNames returns the short names of AgentPlugins in order.
+func agentPluginNames() []string {
+	names := make([]string, 0, len(AgentPlugins))
+	for name := range AgentPlugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
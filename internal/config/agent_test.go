@@ -0,0 +1,32 @@
+package config
+
+import "testing"
+
+func TestAgentPluginStates(t *testing.T) {
+	cfg := &Config{OCIAgentPlugins: "monitoring, Vulnerability-Scanning", FinishingScript: "finish.sh"}
+	states := cfg.AgentPluginStates()
+	if len(states) != len(AgentPlugins) {
+		t.Fatalf("AgentPluginStates() = %v, want a state for each plugin", states)
+	}
+	want := map[string]string{
+		"Compute Instance Monitoring":  AgentPluginEnabled,
+		"Vulnerability Scanning":       AgentPluginEnabled,
+		"Compute Instance Run Command": AgentPluginEnabled,
+		"Bastion":                      AgentPluginDisabled,
+		"Management Agent":             AgentPluginDisabled,
+	}
+	for plugin, state := range want {
+		if states[plugin] != state {
+			t.Errorf("AgentPluginStates()[%q] = %q, want %q", plugin, states[plugin], state)
+		}
+	}
+}
+
+func TestValidateAgentPlugins(t *testing.T) {
+	for value, expectError := range map[string]bool{"": false, "monitoring,bastion": false, "monitoring,cloud-guard": true} {
+		err := (&Config{OCIAgentPlugins: value}).validateAgentPlugins()
+		if (err != nil) != expectError {
+			t.Errorf("validateAgentPlugins(%q) error = %v, expectError %v", value, err, expectError)
+		}
+	}
+}
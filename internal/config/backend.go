@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// State backends of the generated template with a meaning of their own. Any other
+// TEMPLATE_BACKEND is the type of the backend block, configured by TEMPLATE_BACKEND_CONFIG.
+const (
+	BackendLocal = "local" // State in the template output directory
+	BackendOCI   = "oci"   // State in OCI Object Storage, through its S3-compatible API
+)
+
+var backendIdentifierPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// BackendSetting is a setting of the backend block, e.g. address=https://state.example.com.
+type BackendSetting struct {
+	Key   string
+	Value string
+}
+
+// TemplateBackendSettings returns the settings of TEMPLATE_BACKEND_CONFIG, a
+// comma-separated list of key=value pairs, in the order they are given.
+func (c *Config) TemplateBackendSettings() ([]BackendSetting, error) {
+	var settings []BackendSetting
+	for _, item := range strings.Split(c.TemplateBackendConfig, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !backendIdentifierPattern.MatchString(key) {
+			return nil, fmt.Errorf("TEMPLATE_BACKEND_CONFIG: invalid setting '%s', expected key=value", item)
+		}
+		settings = append(settings, BackendSetting{Key: key, Value: strings.TrimSpace(value)})
+	}
+	return settings, nil
+}
+
+// validateTemplateBackend checks the backend type and its settings.
+func (c *Config) validateTemplateBackend() error {
+	backend := c.TemplateBackend
+	if backend == "" || backend == BackendLocal {
+		if c.TemplateBackendConfig != "" || c.TemplateBackendBucket != "" {
+			return errors.New("TEMPLATE_BACKEND_CONFIG and TEMPLATE_BACKEND_BUCKET require TEMPLATE_BACKEND")
+		}
+		return nil
+	}
+	if !backendIdentifierPattern.MatchString(backend) {
+		return fmt.Errorf("invalid TEMPLATE_BACKEND '%s'", backend)
+	}
+	if backend == BackendOCI && c.TemplateBackendBucket == "" {
+		return errors.New("TEMPLATE_BACKEND=oci requires TEMPLATE_BACKEND_BUCKET")
+	}
+	_, err := c.TemplateBackendSettings()
+	return err
+}
@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestValidateTemplateBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"local", Config{TemplateBackend: BackendLocal}, false},
+		{"not set", Config{}, false},
+		{"settings without backend", Config{TemplateBackend: BackendLocal, TemplateBackendConfig: "address=x"}, true},
+		{"oci", Config{TemplateBackend: BackendOCI, TemplateBackendBucket: "tfstate"}, false},
+		{"oci without bucket", Config{TemplateBackend: BackendOCI}, true},
+		{"other backend", Config{TemplateBackend: "http", TemplateBackendConfig: "address=https://state.example.com, lock_method=POST"}, false},
+		{"invalid backend", Config{TemplateBackend: "s3\" {"}, true},
+		{"invalid setting", Config{TemplateBackend: "http", TemplateBackendConfig: "address"}, true},
+		{"invalid key", Config{TemplateBackend: "http", TemplateBackendConfig: "Bad Key=x"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.validateTemplateBackend(); (err != nil) != tt.wantErr {
+				t.Errorf("validateTemplateBackend() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// IsComputeNamePattern reports whether name is a glob pattern, such as web-*, that
+// selects several Azure VMs of the resource group rather than a single VM.
+func IsComputeNamePattern(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// ForComputeName returns a copy of the configuration that migrates the VM name, with the
+// OCI instance and image names derived from it. It is used to migrate each of the VMs
+// selected by a pattern. A VM resource ID, from BATCH_MANIFEST_FILE, also sets the
+// subscription and resource group of the VM.
+func (c *Config) ForComputeName(name string) *Config {
+	vm := *c
+	vm.AzureComputeName = name
+	if id, ok := virtualMachineID(name); ok {
+		vm.AzureSubscriptionID, vm.AzureResourceGroup, vm.AzureComputeName = id.SubscriptionID, id.ResourceGroupName, id.Name
+	}
+	vm.DeriveNames()
+	return &vm
+}
+
+// validateComputeNamePattern checks that a pattern in AZURE_COMPUTE_NAME is well-formed
+// and does not give the VMs it selects the same instance or image name, or the same
+// template state.
+func (c *Config) validateComputeNamePattern() error {
+	if c.SourcePlatform != "azure" || !IsComputeNamePattern(c.AzureComputeName) {
+		return nil
+	}
+	if _, err := path.Match(c.AzureComputeName, ""); err != nil {
+		return fmt.Errorf("AZURE_COMPUTE_NAME: invalid pattern '%s': %w", c.AzureComputeName, err)
+	}
+	if c.OCIInstanceName != "" && c.OCIInstanceName != defaultInstanceName {
+		return fmt.Errorf("OCI_INSTANCE_NAME cannot be set when AZURE_COMPUTE_NAME is a pattern ('%s'); each instance is named after its VM", c.AzureComputeName)
+	}
+	if c.OCIImageName != "" && c.OCIImageName != defaultImageName {
+		return fmt.Errorf("OCI_IMAGE_NAME cannot be set when AZURE_COMPUTE_NAME is a pattern ('%s'); each image is named after its VM", c.AzureComputeName)
+	}
+	if c.OCIHostnameLabel != "" {
+		return fmt.Errorf("OCI_HOSTNAME_LABEL cannot be set when AZURE_COMPUTE_NAME is a pattern ('%s'); hostnames must be unique in the subnet", c.AzureComputeName)
+	}
+	if c.TemplateBackendKey != "" {
+		return fmt.Errorf("TEMPLATE_BACKEND_KEY cannot be set when AZURE_COMPUTE_NAME is a pattern ('%s'); each instance keeps its state under <OCI_INSTANCE_NAME>/terraform.tfstate", c.AzureComputeName)
+	}
+	settings, _ := c.TemplateBackendSettings()
+	for _, setting := range settings {
+		if setting.Key == "key" {
+			return fmt.Errorf("TEMPLATE_BACKEND_CONFIG cannot set key when AZURE_COMPUTE_NAME is a pattern ('%s'); the instances would share one state", c.AzureComputeName)
+		}
+	}
+	return nil
+}
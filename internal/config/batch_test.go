@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeNamePattern(t *testing.T) {
+	cfg := &Config{SourcePlatform: "azure", AzureComputeName: "web-*"}
+	cfg.DeriveNames()
+	if cfg.OCIInstanceName != defaultInstanceName || cfg.OCIImageName != defaultImageName {
+		t.Errorf("DeriveNames() derived %q and %q from a pattern", cfg.OCIInstanceName, cfg.OCIImageName)
+	}
+	vm := cfg.ForComputeName("Web_01")
+	if vm.AzureComputeName != "Web_01" || vm.OCIInstanceName != "web_01" || vm.OCIImageName != "web_01-image" || cfg.AzureComputeName != "web-*" {
+		t.Errorf("ForComputeName() = %q, %q", vm.AzureComputeName, vm.OCIInstanceName)
+	}
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"pattern", Config{SourcePlatform: "azure", AzureComputeName: "web-*", OCIInstanceName: defaultInstanceName}, ""},
+		{"single VM", Config{SourcePlatform: "azure", AzureComputeName: "web-01", OCIInstanceName: "app"}, ""},
+		{"bad pattern", Config{SourcePlatform: "azure", AzureComputeName: "web-[", OCIInstanceName: defaultInstanceName}, "invalid pattern"},
+		{"instance name", Config{SourcePlatform: "azure", AzureComputeName: "web-*", OCIInstanceName: "app"}, "OCI_INSTANCE_NAME"},
+		{"image name", Config{SourcePlatform: "azure", AzureComputeName: "web-*", OCIImageName: "app-image"}, "OCI_IMAGE_NAME"},
+		{"hostname label", Config{SourcePlatform: "azure", AzureComputeName: "web-*", OCIHostnameLabel: "web"}, "OCI_HOSTNAME_LABEL"},
+		{"backend key", Config{SourcePlatform: "azure", AzureComputeName: "web-*", TemplateBackendKey: "web/terraform.tfstate"}, "TEMPLATE_BACKEND_KEY"},
+		{"backend config key", Config{SourcePlatform: "azure", AzureComputeName: "web-*", TemplateBackendConfig: "key=web.tfstate,use_lockfile=true"}, "TEMPLATE_BACKEND_CONFIG"},
+		{"backend config", Config{SourcePlatform: "azure", AzureComputeName: "web-*", TemplateBackendConfig: "use_lockfile=true"}, ""},
+	}
+	for _, tt := range tests {
+		err := tt.cfg.validateComputeNamePattern()
+		if (tt.want == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("%s: validateComputeNamePattern() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of CONFIGURE_CHAIN steps.
+const (
+	ChainBuiltin       = "builtin"       // The CONFIGURE_ENGINE configuration
+	ChainConfigurators = "configurators" // All matching configurators of CONFIGURATORS_DIR
+	ChainConfigurator  = "configurator"  // One configurator of CONFIGURATORS_DIR, by name
+	ChainScript        = "script"        // A local script run inside the guest
+)
+
+const defaultConfigureChain = ChainBuiltin + "," + ChainConfigurators
+
+// ChainStep is a step of CONFIGURE_CHAIN.
+type ChainStep struct {
+	Kind string
+	Arg  string // Configurator name or script path
+}
+
+func (s ChainStep) String() string {
+	if s.Arg == "" {
+		return s.Kind
+	}
+	return s.Kind + ":" + s.Arg
+}
+
+// ConfigureChainSteps returns the image configuration steps in the order they run,
+// builtin followed by all configurators unless CONFIGURE_CHAIN is set.
+func (c *Config) ConfigureChainSteps() []ChainStep {
+	chain := c.ConfigureChain
+	if strings.TrimSpace(chain) == "" {
+		chain = defaultConfigureChain
+	}
+	var steps []ChainStep
+	for _, entry := range strings.Split(chain, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		kind, arg, _ := strings.Cut(entry, ":")
+		steps = append(steps, ChainStep{Kind: strings.TrimSpace(kind), Arg: strings.TrimSpace(arg)})
+	}
+	return steps
+}
+
+// validateConfigureChain checks the syntax of CONFIGURE_CHAIN. Configurator names and
+// script files are checked when the configurators are loaded.
+func (c *Config) validateConfigureChain() error {
+	var errs []error
+	builtin := 0
+	for _, s := range c.ConfigureChainSteps() {
+		switch s.Kind {
+		case ChainBuiltin, ChainConfigurators:
+			if s.Arg != "" {
+				errs = append(errs, fmt.Errorf("CONFIGURE_CHAIN step '%s' does not take an argument", s))
+			}
+			if s.Kind == ChainBuiltin {
+				builtin++
+			}
+		case ChainConfigurator, ChainScript:
+			if s.Arg == "" {
+				errs = append(errs, fmt.Errorf("CONFIGURE_CHAIN step '%s' requires a configurator name or script path", s.Kind))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unknown CONFIGURE_CHAIN step '%s' (supported: %s, %s, %s:<name>, %s:<path>)", s, ChainBuiltin, ChainConfigurators, ChainConfigurator, ChainScript))
+		}
+	}
+	if builtin > 1 {
+		errs = append(errs, fmt.Errorf("CONFIGURE_CHAIN must not contain %s more than once", ChainBuiltin))
+	}
+	return errors.Join(errs...)
+}
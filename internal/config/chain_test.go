@@ -0,0 +1,48 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfigureChainSteps(t *testing.T) {
+	tests := []struct {
+		chain    string
+		expected []ChainStep
+	}{
+		{"", []ChainStep{{Kind: ChainBuiltin}, {Kind: ChainConfigurators}}},
+		{"builtin, script:/opt/fix.sh ,configurator:sles", []ChainStep{{Kind: ChainBuiltin}, {Kind: ChainScript, Arg: "/opt/fix.sh"}, {Kind: ChainConfigurator, Arg: "sles"}}},
+		{"script:hooks/a.sh,builtin", []ChainStep{{Kind: ChainScript, Arg: "hooks/a.sh"}, {Kind: ChainBuiltin}}},
+	}
+	for _, tt := range tests {
+		cfg := &Config{ConfigureChain: tt.chain}
+		if got := cfg.ConfigureChainSteps(); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("ConfigureChainSteps(%q) = %v, expected %v", tt.chain, got, tt.expected)
+		}
+	}
+}
+
+func TestValidateConfigureChain(t *testing.T) {
+	tests := []struct {
+		chain       string
+		expectError bool
+	}{
+		{"", false},
+		{"builtin,script:/opt/fix.sh,configurators", false},
+		{"configurator:sles", false},
+		{"builtin:ubuntu", true},
+		{"script:", true},
+		{"builtin,builtin", true},
+		{"custom", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{ConfigureChain: tt.chain}
+		err := cfg.validateConfigureChain()
+		if tt.expectError && err == nil {
+			t.Errorf("Expected error for %q but got nil", tt.chain)
+		}
+		if !tt.expectError && err != nil {
+			t.Errorf("Expected no error for %q, got %v", tt.chain, err)
+		}
+	}
+}
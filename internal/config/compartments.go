@@ -0,0 +1,58 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// compartmentTimeout bounds the resolution of a compartment path.
+const compartmentTimeout = time.Minute
+
+// CompartmentResolver resolves the path of a compartment below the root compartment
+// of the tenancy, e.g. prod/app-team, to its OCID. cfg holds the configuration loaded
+// so far.
+type CompartmentResolver func(ctx context.Context, cfg *Config, path string) (string, error)
+
+var (
+	compartmentResolverMu sync.RWMutex
+	compartmentResolver   CompartmentResolver
+)
+
+// RegisterCompartmentResolver makes resolver resolve compartment paths given in
+// OCI_COMPARTMENT_ID. Without a resolver, compartments must be given by OCID.
+func RegisterCompartmentResolver(resolver CompartmentResolver) {
+	compartmentResolverMu.Lock()
+	defer compartmentResolverMu.Unlock()
+	compartmentResolver = resolver
+}
+
+// IsCompartmentPath reports whether value names a compartment by its path, such as
+// prod/app-team, rather than by OCID.
+func IsCompartmentPath(value string) bool {
+	return value != "" && !strings.HasPrefix(value, "ocid1.")
+}
+
+// resolveCompartmentPath replaces a compartment path given in OCI_COMPARTMENT_ID with
+// the OCID of the compartment, so that the rest of Kopru only deals with OCIDs.
+func (c *Config) resolveCompartmentPath() error {
+	if !IsCompartmentPath(c.OCICompartmentID) {
+		return nil
+	}
+	compartmentResolverMu.RLock()
+	resolver := compartmentResolver
+	compartmentResolverMu.RUnlock()
+	if resolver == nil {
+		return fmt.Errorf("OCI_COMPARTMENT_ID: compartment paths such as '%s' cannot be resolved; use the compartment OCID", c.OCICompartmentID)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), compartmentTimeout)
+	defer cancel()
+	id, err := resolver(ctx, c, c.OCICompartmentID)
+	if err != nil {
+		return fmt.Errorf("OCI_COMPARTMENT_ID: failed to resolve compartment path '%s': %w", c.OCICompartmentID, err)
+	}
+	c.OCICompartmentID = id
+	return nil
+}
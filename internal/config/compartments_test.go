@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolveCompartmentPath(t *testing.T) {
+	defer RegisterCompartmentResolver(nil)
+
+	cfg := &Config{OCICompartmentID: "prod/app-team"}
+	if err := cfg.resolveCompartmentPath(); err == nil || !strings.Contains(err.Error(), "use the compartment OCID") {
+		t.Errorf("resolveCompartmentPath() without resolver error = %v", err)
+	}
+
+	RegisterCompartmentResolver(func(_ context.Context, _ *Config, path string) (string, error) {
+		if path != "prod/app-team" {
+			return "", errors.New("no accessible compartment")
+		}
+		return "ocid1.compartment.oc1..appteam", nil
+	})
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"prod/app-team", "ocid1.compartment.oc1..appteam", false},
+		{"ocid1.compartment.oc1..prod", "ocid1.compartment.oc1..prod", false},
+		{"", "", false},
+		{"prod/unknown", "", true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{OCICompartmentID: tt.value}
+		err := cfg.resolveCompartmentPath()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveCompartmentPath(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			if !strings.HasPrefix(err.Error(), "OCI_COMPARTMENT_ID: ") {
+				t.Errorf("resolveCompartmentPath(%q) error = %v", tt.value, err)
+			}
+			continue
+		}
+		if cfg.OCICompartmentID != tt.want {
+			t.Errorf("resolveCompartmentPath(%q) = %q, want %q", tt.value, cfg.OCICompartmentID, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigOfflineKeepsCompartmentPath(t *testing.T) {
+	RegisterCompartmentResolver(func(context.Context, *Config, string) (string, error) {
+		t.Error("LoadConfigOffline() resolved the compartment path")
+		return "", errors.New("offline")
+	})
+	defer RegisterCompartmentResolver(nil)
+	t.Setenv("AZURE_COMPUTE_NAME", "test-vm")
+	t.Setenv("AZURE_RESOURCE_GROUP", "test-rg")
+	t.Setenv("OCI_COMPARTMENT_ID", "prod/app-team")
+	t.Setenv("OCI_SUBNET_ID", "ocid1.subnet.oc1.iad.aaaaaaaatest")
+	t.Setenv("OCI_REGION", "us-ashburn-1")
+
+	cfg, err := LoadConfigOffline()
+	if err != nil {
+		t.Fatalf("LoadConfigOffline() error = %v", err)
+	}
+	if cfg.OCICompartmentID != "prod/app-team" {
+		t.Errorf("OCICompartmentID = %q, want prod/app-team", cfg.OCICompartmentID)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() of an unresolved compartment path error = %v", err)
+	}
+
+	cfg.compartmentPath = false
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "not a valid OCID") {
+		t.Errorf("Validate() of a compartment path that should have been resolved error = %v", err)
+	}
+}
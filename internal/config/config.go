@@ -2,53 +2,190 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/codebypatrickleung/kopru-cli/internal/common"
 	"github.com/spf13/viper"
 )
 
 const (
-	defaultImageName           = "kopru-image"
-	defaultInstanceName        = "kopru-instance"
-	imageSuffix                = "-image"
-	defaultDataDiskParallelism = 4
+	defaultImageName    = "kopru-image"
+	defaultInstanceName = "kopru-instance"
+	imageSuffix         = "-image"
+
+	// parallelDiskPipelinesKey is the former name of PARALLEL_STEPS, still accepted.
+	parallelDiskPipelinesKey = "parallel_disk_pipelines"
 )
 
 // Config holds all configuration for the Kopru CLI.
+//
+// Each field is described by struct tags that form the configuration schema:
+//   - env:       environment variable / config file key
+//   - desc:      human readable description
+//   - default:   default value applied when the key is not set
+//   - required:  "always" or a condition such as "SOURCE_PLATFORM=azure"
+//   - format:    value format to validate (ocid, ocid:<type>[|<type>], region, url)
+//   - oneof:     comma-separated list of allowed values
+//   - conflicts: environment variable of a mutually exclusive option
 type Config struct {
-	SourcePlatform        string
-	TargetPlatform        string
-	AzureComputeName      string
-	AzureResourceGroup    string
-	AzureSubscriptionID   string
-	OCICompartmentID      string
-	OCISubnetID           string
-	OCIBucketName         string
-	OCIImageName          string
-	OCIImageOS            string
-	OCIImageOSVersion     string
-	OCIImageEnableUEFI    bool
-	OCIInstanceName       string
-	OCIRegion             string
-	OCIAvailabilityDomain string
-	OSImageURL            string
-	SSHKeyFilePath        string
-	SkipExport            bool
-	SkipTemplateDeploy    bool
-	DataDiskParallelism   int
-	Debug                 bool
+	SourcePlatform               string `env:"SOURCE_PLATFORM" desc:"Source cloud platform" default:"azure" required:"always" oneof:"azure,linux_image"`
+	TargetPlatform               string `env:"TARGET_PLATFORM" desc:"Target cloud platform" default:"oci" required:"always" oneof:"oci"`
+	AzureComputeName             string `env:"AZURE_COMPUTE_NAME" desc:"Name or full resource ID of the Azure VM to migrate, or a pattern such as web-* that migrates each matching VM of the resource group" required:"SOURCE_PLATFORM=azure"`
+	AzureComputeGroup            string `env:"AZURE_COMPUTE_GROUP" desc:"Availability set or VM scale set (Flexible orchestration) whose VMs are migrated as a batch, as availabilitySets/<name>, virtualMachineScaleSets/<name> or its resource ID"`
+	BatchManifestFile            string `env:"BATCH_MANIFEST_FILE" desc:"File listing the Azure VMs to migrate as a batch, one VM name or resource ID per line, as written by kopru discover"`
+	AzureResourceGroup           string `env:"AZURE_RESOURCE_GROUP" desc:"Azure resource group containing the VM (name or resource ID)" required:"SOURCE_PLATFORM=azure"`
+	AzureSubscriptionID          string `env:"AZURE_SUBSCRIPTION_ID" desc:"Azure subscription ID (derived from resource IDs or the migration VM when not set)"`
+	AzureManagedIdentityClientID string `env:"AZURE_MANAGED_IDENTITY_CLIENT_ID" desc:"Client ID of the user-assigned managed identity of the migration VM to use"`
+	AzureAuth                    string `env:"AZURE_AUTH" desc:"Azure authentication method: auto (service principal from the environment, managed identity or DefaultAzureCredential), client-secret, client-certificate, managed-identity, device-code or azure-cli" default:"auto" oneof:"auto,client-secret,client-certificate,managed-identity,device-code,azure-cli"`
+	AzureTenantID                string `env:"AZURE_TENANT_ID" desc:"Microsoft Entra tenant ID of the service principal, or of the device code and Azure CLI sign-in"`
+	AzureClientID                string `env:"AZURE_CLIENT_ID" desc:"Application (client) ID of the service principal, or of the application for device code sign-in"`
+	AzureClientSecret            string `env:"AZURE_CLIENT_SECRET" desc:"Client secret of the service principal" required:"AZURE_AUTH=client-secret" secret:"true"`
+	AzureClientCertificatePath   string `env:"AZURE_CLIENT_CERTIFICATE_PATH" desc:"PEM or PKCS#12 file with the certificate and private key of the service principal" required:"AZURE_AUTH=client-certificate"`
+	AzureClientCertPassword      string `env:"AZURE_CLIENT_CERTIFICATE_PASSWORD" desc:"Password of the client certificate file" secret:"true"`
+	OCICompartmentID             string `env:"OCI_COMPARTMENT_ID" desc:"OCI compartment OCID, or path below the root compartment (e.g. prod/app-team), where resources will be created" required:"TARGET_PLATFORM=oci" format:"ocid:compartment|tenancy"`
+	OCISubnetID                  string `env:"OCI_SUBNET_ID" desc:"OCI subnet OCID for the new instance" required:"TARGET_PLATFORM=oci" format:"ocid:subnet"`
+	OCIBucketName                string `env:"OCI_BUCKET_NAME" desc:"OCI Object Storage bucket name for image upload" default:"kopru-bucket"`
+	OCIImageName                 string `env:"OCI_IMAGE_NAME" desc:"OCI custom image name (derived from AZURE_COMPUTE_NAME by default)" default:"kopru-image"`
+	OCIImageOS                   string `env:"OCI_IMAGE_OS" desc:"Operating system of the imported image" oneof:"Oracle Linux,AlmaLinux,CentOS,Debian,RHEL,Rocky Linux,SUSE,Ubuntu,Windows,Generic Linux"`
+	OCIImageOSVersion            string `env:"OCI_IMAGE_OS_VERSION" desc:"Operating system version of the imported image (e.g. 22.04, 2022)"`
+	OCIImageEnableUEFI           bool   `env:"OCI_IMAGE_ENABLE_UEFI" desc:"Enable UEFI_64 firmware for the imported image" default:"false"`
+	OCIImageLaunchMode           string `env:"OCI_IMAGE_LAUNCH_MODE" desc:"Launch mode of the imported image (EMULATED for legacy kernels without virtio drivers)" default:"PARAVIRTUALIZED" oneof:"NATIVE,EMULATED,PARAVIRTUALIZED"`
+	ImageConflictPolicy          string `env:"IMAGE_CONFLICT_POLICY" desc:"Action when an image with the same name exists in the compartment: reuse it, fail, or import with a -v2, -v3, ... suffix" default:"suffix" oneof:"reuse,fail,suffix"`
+	OCIBootVolumeVPUsPerGB       int    `env:"OCI_BOOT_VOLUME_VPUS_PER_GB" desc:"Performance of the boot volume in VPUs per GB (10 Balanced, 20 Higher Performance, 30-120 Ultra High Performance)" default:"10"`
+	OCIDataVolumeVPUsPerGB       int    `env:"OCI_DATA_VOLUME_VPUS_PER_GB" desc:"Performance of the block volumes restored from data disks in VPUs per GB (0 Lower Cost, 10 Balanced, 20 Higher Performance, 30-120 Ultra High Performance)" default:"10"`
+	OCIDataVolumeVPUs            string `env:"OCI_DATA_VOLUME_VPUS" desc:"Comma-separated per-disk overrides of OCI_DATA_VOLUME_VPUS_PER_GB as <azure-disk-name>=<vpus>"`
+	OCIVolumeAutotuneMaxVPUs     int    `env:"OCI_VOLUME_AUTOTUNE_MAX_VPUS_PER_GB" desc:"Maximum VPUs per GB that performance-based auto-tune may raise restored data volumes to (0 disables auto-tune)" default:"120"`
+	OCIBootVolumeAutoTune        bool   `env:"OCI_BOOT_VOLUME_AUTO_TUNE" desc:"Enable detached volume auto-tune on the boot volume, which lowers it to Lower Cost while it is detached (applied with the OCI CLI)" default:"false"`
+	OCIDataVolumeAutoTune        bool   `env:"OCI_DATA_VOLUME_AUTO_TUNE" desc:"Enable detached volume auto-tune on the restored data volumes (applied with the OCI CLI)" default:"false"`
+	OCIBootVolumeBackupPolicy    string `env:"OCI_BOOT_VOLUME_BACKUP_POLICY" desc:"Backup policy assigned to the boot volume: gold, silver, bronze or the OCID of a volume backup policy"`
+	OCIDataVolumeBackupPolicy    string `env:"OCI_DATA_VOLUME_BACKUP_POLICY" desc:"Backup policy assigned to the restored data volumes: gold, silver, bronze or the OCID of a volume backup policy"`
+	OCIInstanceName              string `env:"OCI_INSTANCE_NAME" desc:"OCI instance name (derived from AZURE_COMPUTE_NAME by default)" default:"kopru-instance"`
+	OCIInstanceState             string `env:"OCI_INSTANCE_STATE" desc:"State of the instance after deployment: RUNNING, or STOPPED to finish network and DNS work before starting it with kopru start" default:"RUNNING" oneof:"RUNNING,STOPPED"`
+	OCIRegion                    string `env:"OCI_REGION" desc:"OCI region identifier (e.g. us-ashburn-1)" required:"TARGET_PLATFORM=oci" format:"region"`
+	OCIDefaultRealm              string `env:"OCI_DEFAULT_REALM" desc:"Realm domain for regions unknown to the OCI SDK (e.g. oraclegovcloud.uk)"`
+	OCIRegionMetadata            string `env:"OCI_REGION_METADATA" desc:"JSON metadata of a dedicated region (realmKey, realmDomainComponent, regionKey, regionIdentifier)"`
+	OCIAvailabilityDomain        string `env:"OCI_AVAILABILITY_DOMAIN" desc:"OCI availability domain number for the instance"`
+	OCIShapes                    string `env:"OCI_SHAPES" desc:"Comma-separated OCI shapes of the instance in order of preference; the first one the availability domain offers with capacity and limits for the instance is used (default: VM.Standard.E5.Flex, VM.Standard.E4.Flex, VM.Standard3.Flex, or VM.Standard.A1.Flex for ARM64)"`
+	OCIBurstableBaseline         string `env:"OCI_BURSTABLE_BASELINE" desc:"Baseline OCPU utilization of instances migrated from Azure B-series VMs: BASELINE_1_8, BASELINE_1_2, or BASELINE_1_1 for regular instances" default:"BASELINE_1_2" oneof:"BASELINE_1_8,BASELINE_1_2,BASELINE_1_1"`
+	OCIDataVolumeAD              string `env:"OCI_DATA_VOLUME_AVAILABILITY_DOMAIN" desc:"Availability domain number of the restored data volumes (default: that of the host running Kopru); volumes are moved there through a volume backup"`
+	OCITargetInstanceID          string `env:"OCI_TARGET_INSTANCE_ID" desc:"OCID of an existing instance to attach the migrated data disks to; the OS disk is not migrated and no instance is created" format:"ocid:instance"`
+	OCIFaultDomain               string `env:"OCI_FAULT_DOMAIN" desc:"Fault domain of the instance (default: chosen by OCI)" oneof:"FAULT-DOMAIN-1,FAULT-DOMAIN-2,FAULT-DOMAIN-3"`
+	OCINSGIDs                    string `env:"OCI_NSG_IDS" desc:"Comma-separated OCIDs of up to 5 network security groups of the instance VNIC"`
+	OCIHostnameLabel             string `env:"OCI_HOSTNAME_LABEL" desc:"Hostname label of the instance VNIC, for a DNS name in the subnet's domain (the subnet must have a DNS label)"`
+	OCIDefinedTags               string `env:"OCI_DEFINED_TAGS" desc:"Comma-separated defined tags of the instance as namespace.key=value (e.g. Operations.CostCenter=42)"`
+	PreserveSourceTags           bool   `env:"PRESERVE_SOURCE_TAGS" desc:"Carry the tags of the Azure VM and its disks over to the freeform tags of the instance, image and data volumes" default:"false"`
+	SourceTagMapFile             string `env:"SOURCE_TAG_MAP_FILE" desc:"File mapping Azure tag keys to OCI defined tags, one <Azure tag>=<namespace>.<key> per line; mapped tags become defined tags instead of freeform tags"`
+	OCISubnetMapFile             string `env:"OCI_SUBNET_MAP_FILE" desc:"File mapping Azure subnets to OCI subnets, one <vnet>/<subnet>=<subnet OCID> per line, to reconstruct the secondary network interfaces and IP configurations of the VM as VNICs and private IPs"`
+	OCIPreservePrivateIPs        bool   `env:"OCI_PRESERVE_PRIVATE_IPS" desc:"Assign the private IP addresses of the source VM to the VNICs and secondary private IPs (the OCI subnets must contain them)" default:"false"`
+	OSImageURL                   string `env:"OS_IMAGE_URL" desc:"URL to the Linux OS image in QCOW2 format" required:"SOURCE_PLATFORM=linux_image" format:"url"`
+	SSHKeyFilePath               string `env:"SSH_KEY_FILE" desc:"Path to SSH public key file for instance access"`
+	OCISSHPublicKey              string `env:"OCI_SSH_PUBLIC_KEY" desc:"SSH public key for instance access, injected into the image and the instance metadata (takes precedence over SSH_KEY_FILE)"`
+	CloudInitFile                string `env:"CLOUD_INIT_FILE" desc:"Path to a cloud-init file (cloud-config, shell script or MIME multi-part) passed to the instance as user_data"`
+	CloudInitUserData            string `env:"CLOUD_INIT_USER_DATA" desc:"Inline cloud-init user data passed to the instance (takes precedence over CLOUD_INIT_FILE)"`
+	OCIAgentPlugins              string `env:"OCI_AGENT_PLUGINS" desc:"Comma-separated Oracle Cloud Agent plugins enabled on the instance (monitoring, custom-logs, management, vulnerability-scanning, bastion, os-management-hub, run-command); the others are disabled" default:"monitoring"`
+	BreakglassUser               string `env:"KOPRU_BREAKGLASS_USER" desc:"Temporary user with passwordless sudo created in the image for emergency access with the SSH key"`
+	BreakglassExpiryDays         int    `env:"KOPRU_BREAKGLASS_EXPIRY_DAYS" desc:"Days after which the break-glass user account expires (0 never expires)" default:"7"`
+	TemplateEnvironments         string `env:"TEMPLATE_ENVIRONMENTS" desc:"Comma-separated environments (e.g. dev,prod) to generate <env>.tfvars for, from <ENV>_OCI_COMPARTMENT_ID, <ENV>_OCI_SUBNET_ID, <ENV>_OCI_INSTANCE_NAME and <ENV>_OCI_AVAILABILITY_DOMAIN"`
+	TemplateDir                  string `env:"TEMPLATE_DIR" desc:"Directory of templates (*.tmpl) that override the embedded templates of the generated files or add files, e.g. backend.tf.tmpl"`
+	TemplateBackend              string `env:"TEMPLATE_BACKEND" desc:"State backend of the generated template: local, oci (OCI Object Storage through its S3-compatible API), or the type of another backend configured with TEMPLATE_BACKEND_CONFIG (e.g. http)" default:"local"`
+	TemplateBackendBucket        string `env:"TEMPLATE_BACKEND_BUCKET" desc:"OCI Object Storage bucket of the state with TEMPLATE_BACKEND=oci"`
+	TemplateBackendNamespace     string `env:"TEMPLATE_BACKEND_NAMESPACE" desc:"Object Storage namespace of TEMPLATE_BACKEND_BUCKET (default: namespace of the tenancy)"`
+	TemplateBackendKey           string `env:"TEMPLATE_BACKEND_KEY" desc:"Object name of the state with TEMPLATE_BACKEND=oci (default: <OCI_INSTANCE_NAME>/terraform.tfstate)"`
+	TemplateBackendConfig        string `env:"TEMPLATE_BACKEND_CONFIG" desc:"Comma-separated key=value settings of the backend block, added to or replacing the settings of TEMPLATE_BACKEND=oci"`
+	SkipExport                   bool   `env:"SKIP_OS_EXPORT" desc:"Skip OS disk export" default:"false"`
+	SkipTemplateDeploy           bool   `env:"SKIP_TEMPLATE_DEPLOY" desc:"Skip template deployment" default:"false"`
+	OCIInstancePool              bool   `env:"OCI_INSTANCE_POOL" desc:"Add the instances migrated from the VMs of AZURE_COMPUTE_GROUP to an OCI instance pool" default:"false"`
+	IaCEngine                    string `env:"IAC_ENGINE" desc:"Infrastructure as code engine that deploys the generated template: tofu (OpenTofu) or terraform" default:"tofu" oneof:"tofu,terraform"`
+	DataDiskParallelism          int    `env:"DATA_DISK_PARALLELISM" desc:"Maximum number of data disks processed in parallel (minimum 1)" default:"4"`
+	VerifyChecksums              bool   `env:"VERIFY_CHECKSUMS" desc:"Hash exported disks, compare converted images with their source and verify the MD5 of uploaded objects, and record the results in the run summary" default:"false"`
+	DataDiskVerifyMode           string `env:"DATA_DISK_VERIFY_MODE" desc:"How each data disk is read back from its block volume and compared with the source before the volume is used: sample compares 64 blocks, full compares the SHA-256 of the whole disk, off skips the check" default:"sample" oneof:"sample,full,off"`
+	ParallelSteps                bool   `env:"PARALLEL_STEPS" desc:"Run each step as soon as the artifacts it consumes are available, e.g. data disks concurrently with the OS disk" default:"false"`
+	StepTimeouts                 string `env:"STEP_TIMEOUTS" desc:"Comma-separated hard timeouts of workflow steps as <step>=<duration>, e.g. export=4h,upload=6h,deploy=30m"`
+	HeartbeatMinutes             int    `env:"HEARTBEAT_MINUTES" desc:"Minutes between heartbeat log lines of a running step (0 disables heartbeats)" default:"5"`
+	DataDiskCopyStrategy         string `env:"DATA_DISK_COPY_STRATEGY" desc:"How data disks are copied to OCI block volumes: dd copies every block, sparse zeroes free filesystem space with virt-sparsify and writes only allocated blocks, nbd copies the whole VHD through qemu-nbd without a RAW copy" default:"dd" oneof:"dd,sparse,nbd"`
+	DownloadBlockSizeMB          int    `env:"AZURE_DOWNLOAD_BLOCK_SIZE_MB" desc:"Block size in MB for parallel ranged disk downloads" default:"64"`
+	DownloadWorkers              int    `env:"AZURE_DOWNLOAD_WORKERS" desc:"Number of concurrent ranged GETs per disk download" default:"8"`
+	DownloadMBPerSecond          int    `env:"AZURE_DOWNLOAD_MB_PER_SECOND" desc:"Expected disk download throughput in MB/s, used to size the validity of snapshot SAS URLs" default:"25"`
+	AzureSnapshotNameTemplate    string `env:"AZURE_SNAPSHOT_NAME_TEMPLATE" desc:"Name of the snapshots created to export disks, with {disk}, {timestamp} and {migration} placeholders" default:"ss-{disk}-{timestamp}"`
+	AzureSnapshotConsistency     string `env:"AZURE_SNAPSHOT_CONSISTENCY" desc:"Consistency of the export snapshots of a running VM: crash, or fsfreeze to freeze the Linux filesystems through Run Command while all disks are snapshotted together" default:"crash" oneof:"crash,fsfreeze"`
+	AzureFreezeSeconds           int    `env:"AZURE_FREEZE_SECONDS" desc:"Seconds the filesystems stay frozen with AZURE_SNAPSHOT_CONSISTENCY=fsfreeze; must cover a Run Command round trip and the start of the snapshots" default:"90"`
+	AzurePreSnapshotScript       string `env:"AZURE_PRE_SNAPSHOT_SCRIPT" desc:"Script run on the running VM through Run Command before its disks are snapshotted together, e.g. to flush and suspend a database"`
+	AzurePostSnapshotScript      string `env:"AZURE_POST_SNAPSHOT_SCRIPT" desc:"Script run on the VM through Run Command after its disks are snapshotted, e.g. to resume a database"`
+	LiveSync                     bool   `env:"LIVE_SYNC" desc:"Export the disks of the running VM, wait for it to be stopped, then export only the pages changed since, through incremental snapshots" default:"false"`
+	LiveSyncStopTimeoutMinutes   int    `env:"LIVE_SYNC_STOP_TIMEOUT_MINUTES" desc:"Minutes LIVE_SYNC waits for the VM to be stopped after the first pass" default:"60"`
+	AzureStopVM                  bool   `env:"AZURE_STOP_VM" desc:"Deallocate the running Azure VM before its disks are exported, after a typed confirmation (after the first pass with LIVE_SYNC)" default:"false"`
+	AzureForceStop               bool   `env:"AZURE_FORCE_STOP" desc:"Deallocate the running Azure VM as AZURE_STOP_VM does, without confirmation" default:"false"`
+	AzureRestartVM               bool   `env:"AZURE_RESTART_VM" desc:"Start the Azure VM again once its disks are exported, or when the run fails, if Kopru deallocated it" default:"false"`
+	AzurePowerTimeoutMinutes     int    `env:"AZURE_POWER_TIMEOUT_MINUTES" desc:"Minutes to wait for the Azure VM to be deallocated or running again" default:"15"`
+	LUKSPassphrase               string `env:"LUKS_PASSPHRASE" desc:"Passphrase of the LUKS containers in the image, used to configure encrypted disks" conflicts:"LUKS_KEY_FILE" secret:"true"`
+	LUKSKeyFile                  string `env:"LUKS_KEY_FILE" desc:"Path to a key file of the LUKS containers in the image"`
+	LUKSDevice                   string `env:"LUKS_DEVICE" desc:"LUKS device or UUID the key applies to (all requires libguestfs 1.50 or later; e.g. /dev/sda2 otherwise)" default:"all"`
+	ConfigureEngine              string `env:"CONFIGURE_ENGINE" desc:"Engine that configures the image for OCI (virt-v2v falls back to builtin when not installed)" default:"builtin" oneof:"builtin,virt-v2v"`
+	ConfiguratorsDir             string `env:"CONFIGURATORS_DIR" desc:"Directory of YAML configurators applied to the image after the built-in OS configuration (default ~/.kopru/configurators)"`
+	ConfigureChain               string `env:"CONFIGURE_CHAIN" desc:"Ordered, comma-separated image configuration steps: builtin, configurators, configurator:<name> and script:<path>" default:"builtin,configurators"`
+	AcceptCustomScript           bool   `env:"ACCEPT_CUSTOM_SCRIPT" desc:"Acknowledge that the scripts of configurators and CONFIGURE_CHAIN run as root in the image with sudo" default:"false"`
+	PackageCacheDir              string `env:"PACKAGE_CACHE_DIR" desc:"Local apt or yum/dnf/zypper repository that replaces the package repositories of the image while it is configured, for air-gapped migration hosts"`
+	ArchMismatchAction           string `env:"ARCH_MISMATCH_ACTION" desc:"Handling of commands inside images whose architecture differs from the migration host: defer them to the first boot, run them through qemu-user-static binfmt, or fail" default:"firstboot" oneof:"firstboot,emulate,fail"`
+	ScrubImage                   bool   `env:"SCRUB_IMAGE" desc:"Remove machine-id, SSH host keys, shell histories, DHCP leases, logs and cloud agent caches from the configured image" default:"false"`
+	ScrubExclude                 string `env:"SCRUB_EXCLUDE" desc:"Comma-separated default scrub operations to skip (machine-id, ssh-hostkeys, bash-history, dhcp-client-state, logfiles, tmp-files, cloud-agent-cache)"`
+	ScrubInclude                 string `env:"SCRUB_INCLUDE" desc:"Comma-separated additional virt-sysprep operations or absolute paths (globs) to remove from the image"`
+	PrebootValidation            bool   `env:"PREBOOT_VALIDATION" desc:"Boot the configured Linux image under QEMU/KVM before upload and check that it reaches userland and acquires a DHCP lease" default:"false"`
+	PrebootTimeoutMinutes        int    `env:"PREBOOT_TIMEOUT_MINUTES" desc:"Minutes to wait for the pre-boot validation to succeed" default:"10"`
+	FinishingScript              string `env:"FINISHING_SCRIPT" desc:"Script run as root on the deployed instance through the OCI Run Command plugin after first boot, for changes that cannot be made offline"`
+	FinishingTimeoutMinutes      int    `env:"FINISHING_TIMEOUT_MINUTES" desc:"Minutes to wait for the finishing script, including the time for the instance to boot and its agent to pick it up" default:"30"`
+	BootBeacon                   bool   `env:"BOOT_BEACON" desc:"Install a one-shot service in Linux images that reports the first boot in OCI (instance OCID, boot time, cloud-init status), and wait for it during verification" default:"false"`
+	BootBeaconEndpoint           string `env:"BOOT_BEACON_ENDPOINT" desc:"URL the boot beacon is POSTed to instead of the OCI Object Storage bucket; Kopru does not wait for it" format:"url"`
+	BootBeaconTimeoutMinutes     int    `env:"BOOT_BEACON_TIMEOUT_MINUTES" desc:"Minutes to wait for the boot beacon of the deployed instance" default:"15"`
+	SubscriptionReregister       bool   `env:"SUBSCRIPTION_REREGISTER" desc:"Install a one-shot service in RHEL and SLES images that registers them again with subscription-manager or SUSEConnect on the first boot in OCI" default:"false"`
+	RHSMOrgID                    string `env:"RHSM_ORG_ID" desc:"Red Hat organization ID used to register RHEL instances again"`
+	RHSMActivationKey            string `env:"RHSM_ACTIVATION_KEY" desc:"Red Hat activation key used to register RHEL instances again" secret:"true"`
+	SUSERegistrationCode         string `env:"SUSE_REGISTRATION_CODE" desc:"SUSE Customer Center registration code used to register SLES instances again" secret:"true"`
+	SUSERegistrationEmail        string `env:"SUSE_REGISTRATION_EMAIL" desc:"Email address of the SUSE Customer Center registration"`
+	NTPServer                    string `env:"NTP_SERVER" desc:"NTP server used to check the local clock for skew during the prerequisite checks" default:"pool.ntp.org"`
+	MaxClockSkewSeconds          int    `env:"MAX_CLOCK_SKEW_SECONDS" desc:"Fail the prerequisite checks when the local clock is off by more than this many seconds (0 disables the check)" default:"60"`
+	Language                     string `env:"KOPRU_LANG" desc:"Language for user-facing messages" default:"en" oneof:"en,es"`
+	ExistingMigrationAction      string `env:"EXISTING_MIGRATION_ACTION" desc:"Action when the source was migrated by an earlier run: prompt asks (replace with --yes), resume reuses its exported disk and imported image, replace runs the full migration again, abort stops" default:"prompt" oneof:"prompt,resume,replace,abort"`
+	MigrationHistoryFile         string `env:"MIGRATION_HISTORY_FILE" desc:"File recording the last migration run per source (default ~/.kopru/migrations.json)"`
+	AssumeYes                    bool   `env:"ASSUME_YES" desc:"Skip typed confirmations before costly or destructive operations" default:"false"`
+	UploadConfirmThresholdGB     int    `env:"UPLOAD_CONFIRM_THRESHOLD_GB" desc:"Ask for confirmation before uploading images larger than this size in GB (0 disables)" default:"100"`
+	LogFormat                    string `env:"LOG_FORMAT" desc:"Log output format (text or json for structured records)" default:"text" oneof:"text,json"`
+	OTLPEndpoint                 string `env:"OTEL_EXPORTER_OTLP_ENDPOINT" desc:"OTLP/HTTP endpoint of an OpenTelemetry collector for traces and metrics (disabled when not set)" format:"url"`
+	CMDBFormat                   string `env:"CMDB_FORMAT" desc:"Format of the CMDB record written after each run" default:"json" oneof:"json,csv"`
+	CMDBEndpoint                 string `env:"CMDB_ENDPOINT" desc:"HTTP endpoint that receives the CMDB record as JSON after a successful run (disabled when not set)" format:"url"`
+	CMDBAuthorization            string `env:"CMDB_AUTHORIZATION" desc:"Authorization header sent with the CMDB record (e.g. Bearer <token>)" secret:"true"`
+	ChangeTicketSystem           string `env:"CHANGE_TICKET_SYSTEM" desc:"Change ticket system updated at workflow start and end (disabled when not set)" oneof:"jira,servicenow"`
+	ChangeTicketURL              string `env:"CHANGE_TICKET_URL" desc:"Base URL of the Jira or ServiceNow instance" format:"url"`
+	ChangeTicketID               string `env:"CHANGE_TICKET_ID" desc:"Existing Jira issue key or ServiceNow change number (a ticket is created when not set)"`
+	ChangeTicketProject          string `env:"CHANGE_TICKET_PROJECT" desc:"Jira project key used when creating an issue"`
+	ChangeTicketAuthorization    string `env:"CHANGE_TICKET_AUTHORIZATION" desc:"Authorization header sent to the change ticket system (e.g. Basic <base64>)" secret:"true"`
+	NotifyWebhookURL             string `env:"NOTIFY_WEBHOOK_URL" desc:"Webhook that receives step completion and failure notifications (disabled when not set)" format:"url" secret:"true"`
+	NotifyWebhookFormat          string `env:"NOTIFY_WEBHOOK_FORMAT" desc:"Payload format of the notification webhook" default:"json" oneof:"json,slack,teams"`
+	NotifyEvents                 string `env:"NOTIFY_EVENTS" desc:"Events posted to the notification webhook: all steps and the start and end of the run, or failures only" default:"all" oneof:"all,failures"`
+	RetryMaxAttempts             int    `env:"RETRY_MAX_ATTEMPTS" desc:"Attempts per Azure and OCI API call before a transient error (throttling, 5xx) fails the step" default:"8"`
+	RetryMaxDelaySeconds         int    `env:"RETRY_MAX_DELAY_SECONDS" desc:"Maximum delay in seconds between attempts of an API call; the delay grows exponentially with jitter" default:"60"`
+	RetryBudget                  int    `env:"RETRY_BUDGET" desc:"Retries allowed across all API calls of a run before transient errors are no longer retried (0 for unlimited)" default:"500"`
+	Debug                        bool   `env:"DEBUG" desc:"Enable debug logging" default:"false"`
+
+	secretRefs      *map[string]string // secret:// references of resolved options, by environment variable; a pointer keeps Config comparable
+	compartmentPath bool               // OCI_COMPARTMENT_ID holds a compartment path left unresolved by LoadConfigOffline
 }
 
 // Load initializes configuration from file, environment variables, and flags.
 func Load(configFile string) (*Config, error) {
-	viper.SetDefault("source_platform", "azure")
-	viper.SetDefault("target_platform", "oci")
-	viper.SetDefault("oci_bucket_name", "kopru-bucket")
-	viper.SetDefault("oci_image_name", defaultImageName)
-	viper.SetDefault("oci_instance_name", defaultInstanceName)
-	viper.SetDefault("data_disk_parallelism", defaultDataDiskParallelism)
+	return load(configFile, true)
+}
+
+// load initializes the configuration, resolving a compartment path in OCI_COMPARTMENT_ID
+// with OCI Identity when resolveCompartment is set.
+func load(configFile string, resolveCompartment bool) (*Config, error) {
+	for _, field := range Schema() {
+		if field.Default != "" {
+			viper.SetDefault(field.Key(), field.Default)
+		}
+	}
 
 	viper.AutomaticEnv()
 
@@ -61,74 +198,98 @@ func Load(configFile string) (*Config, error) {
 		}
 	}
 
-	azureComputeName := viper.GetString("azure_compute_name")
-
-	ociInstanceName := viper.GetString("oci_instance_name")
-	if (ociInstanceName == defaultInstanceName || ociInstanceName == "") && azureComputeName != "" {
-		ociInstanceName = common.SanitizeName(azureComputeName)
-	} else if ociInstanceName == "" {
-		ociInstanceName = defaultInstanceName
+	cfg := &Config{}
+	if err := loadFields(cfg); err != nil {
+		return nil, err
 	}
-
-	ociImageName := viper.GetString("oci_image_name")
-	if (ociImageName == defaultImageName || ociImageName == "") && azureComputeName != "" {
-		ociImageName = fmt.Sprintf("%s%s", common.SanitizeName(azureComputeName), imageSuffix)
-	} else if ociImageName == "" {
-		ociImageName = defaultImageName
+	if viper.GetBool(parallelDiskPipelinesKey) {
+		cfg.ParallelSteps = true
+	}
+	if err := cfg.resolveSecrets(); err != nil {
+		return nil, err
 	}
+	cfg.exportRealmSettings()
+	cfg.configureRetries()
+	if err := cfg.resolveAzureResourceIDs(); err != nil {
+		return nil, err
+	}
+	if err := cfg.resolveComputeGroup(); err != nil {
+		return nil, err
+	}
+	cfg.resolveBatchManifest()
 
-	parallelism := viper.GetInt("data_disk_parallelism")
-	if parallelism < 1 {
-		parallelism = 1
+	cfg.DeriveNames()
+	cfg.OCIRegion = CanonicalRegion(cfg.OCIRegion)
+	if resolveCompartment {
+		if err := cfg.resolveCompartmentPath(); err != nil {
+			return nil, err
+		}
+	} else {
+		cfg.compartmentPath = IsCompartmentPath(cfg.OCICompartmentID)
 	}
 
-	cfg := &Config{
-		SourcePlatform:        viper.GetString("source_platform"),
-		TargetPlatform:        viper.GetString("target_platform"),
-		AzureComputeName:      azureComputeName,
-		AzureResourceGroup:    viper.GetString("azure_resource_group"),
-		AzureSubscriptionID:   viper.GetString("azure_subscription_id"),
-		OCICompartmentID:      viper.GetString("oci_compartment_id"),
-		OCISubnetID:           viper.GetString("oci_subnet_id"),
-		OCIBucketName:         viper.GetString("oci_bucket_name"),
-		OCIImageName:          ociImageName,
-		OCIImageOS:            viper.GetString("oci_image_os"),
-		OCIImageOSVersion:     viper.GetString("oci_image_os_version"),
-		OCIImageEnableUEFI:    viper.GetBool("oci_image_enable_uefi"),
-		OCIInstanceName:       ociInstanceName,
-		OCIRegion:             viper.GetString("oci_region"),
-		OCIAvailabilityDomain: viper.GetString("oci_availability_domain"),
-		OSImageURL:            viper.GetString("os_image_url"),
-		SSHKeyFilePath:        viper.GetString("ssh_key_file"),
-		SkipExport:            viper.GetBool("skip_os_export"),
-		SkipTemplateDeploy:    viper.GetBool("skip_template_deploy"),
-		DataDiskParallelism:   parallelism,
-		Debug:                 viper.GetBool("debug"),
+	if cfg.DataDiskParallelism < 1 {
+		cfg.DataDiskParallelism = 1
+	}
+	if cfg.PrebootTimeoutMinutes < 1 {
+		cfg.PrebootTimeoutMinutes = 1
 	}
 
 	return cfg, nil
 }
 
-// Validate checks that required configuration is present.
-func (c *Config) Validate() error {
-	if c.SourcePlatform == "azure" {
-		if c.AzureComputeName == "" {
-			return fmt.Errorf("azure_compute_name is required for Azure source platform")
+// DeriveNames sets the OCI instance and image names from the Azure Compute name
+// unless they were configured explicitly.
+func (c *Config) DeriveNames() {
+	if IsComputeNamePattern(c.AzureComputeName) {
+		// The names are derived from each VM the pattern selects, see ForComputeName.
+		if c.OCIInstanceName == "" {
+			c.OCIInstanceName = defaultInstanceName
 		}
-		if c.AzureResourceGroup == "" {
-			return fmt.Errorf("azure_resource_group is required for Azure source platform")
+		if c.OCIImageName == "" {
+			c.OCIImageName = defaultImageName
 		}
+		return
 	}
-	if c.TargetPlatform == "oci" {
-		if c.OCICompartmentID == "" {
-			return fmt.Errorf("oci_compartment_id is required for OCI target platform")
-		}
-		if c.OCISubnetID == "" {
-			return fmt.Errorf("oci_subnet_id is required for OCI target platform")
-		}
-		if c.OCIRegion == "" {
-			return fmt.Errorf("oci_region is required for OCI target platform")
-		}
+	if (c.OCIInstanceName == defaultInstanceName || c.OCIInstanceName == "") && c.AzureComputeName != "" {
+		c.OCIInstanceName = common.SanitizeName(c.AzureComputeName)
+	} else if c.OCIInstanceName == "" {
+		c.OCIInstanceName = defaultInstanceName
+	}
+
+	if (c.OCIImageName == defaultImageName || c.OCIImageName == "") && c.AzureComputeName != "" {
+		c.OCIImageName = fmt.Sprintf("%s%s", common.SanitizeName(c.AzureComputeName), imageSuffix)
+	} else if c.OCIImageName == "" {
+		c.OCIImageName = defaultImageName
+	}
+}
+
+// Validate checks that required configuration is present and that values are well-formed.
+// All problems found are reported together.
+func (c *Config) Validate() error {
+	return errors.Join(validateFields(c), c.validateTemplateEnvironments(), c.validateAccess(), c.validateUserData(), c.validateAgentPlugins(), c.validateSnapshotNameTemplate(), c.validateVolumePerformance(), c.validateBackupPolicies(), c.validateDataVolumeAD(), c.validateStepTimeouts(), c.validateConfigureChain(), c.validateComputeNamePattern(), c.validateComputeGroup(), c.validateBatchManifest(), c.validateLiveSync(), c.validateSourcePower(), c.validateSubscriptionReregister(), c.validateTemplateDir(), c.validateTemplateBackend(), c.validateInstanceCompliance(), c.validateShapes(), c.validateTargetInstance(), c.validateSubnetMap(), c.validateSourceTagMap())
+}
+
+// validateTemplateDir checks that TEMPLATE_DIR is a directory, so that a typo fails
+// before the migration rather than when the template is generated.
+func (c *Config) validateTemplateDir() error {
+	if c.TemplateDir == "" {
+		return nil
+	}
+	info, err := os.Stat(c.TemplateDir)
+	if err != nil {
+		return fmt.Errorf("TEMPLATE_DIR: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("TEMPLATE_DIR is not a directory: '%s'", c.TemplateDir)
+	}
+	return nil
+}
+
+// validateSnapshotNameTemplate checks that snapshot names differ between the disks of a VM.
+func (c *Config) validateSnapshotNameTemplate() error {
+	if c.AzureSnapshotNameTemplate != "" && !strings.Contains(c.AzureSnapshotNameTemplate, "{disk}") {
+		return fmt.Errorf("AZURE_SNAPSHOT_NAME_TEMPLATE must contain the {disk} placeholder: '%s'", c.AzureSnapshotNameTemplate)
 	}
 	return nil
 }
@@ -137,3 +298,9 @@ func (c *Config) Validate() error {
 func LoadConfig() (*Config, error) {
 	return Load("")
 }
+
+// LoadConfigOffline loads the configuration like LoadConfig without contacting a cloud:
+// a compartment path in OCI_COMPARTMENT_ID is kept as it is instead of being resolved.
+func LoadConfigOffline() (*Config, error) {
+	return load("", false)
+}
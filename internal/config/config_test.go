@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -21,8 +22,8 @@ func TestConfigLoad(t *testing.T) {
 	envVars := map[string]string{
 		"AZURE_COMPUTE_NAME":   "test-vm",
 		"AZURE_RESOURCE_GROUP": "test-rg",
-		"OCI_COMPARTMENT_ID":   "ocid1.compartment.test",
-		"OCI_SUBNET_ID":        "ocid1.subnet.test",
+		"OCI_COMPARTMENT_ID":   "ocid1.compartment.oc1..aaaaaaaatest",
+		"OCI_SUBNET_ID":        "ocid1.subnet.oc1.iad.aaaaaaaatest",
 	}
 	setEnvVars(envVars)
 	defer unsetEnvVars([]string{
@@ -64,8 +65,8 @@ func TestConfigValidate(t *testing.T) {
 				TargetPlatform:     "oci",
 				AzureComputeName:   "test-vm",
 				AzureResourceGroup: "test-rg",
-				OCICompartmentID:   "ocid1.compartment.test",
-				OCISubnetID:        "ocid1.subnet.test",
+				OCICompartmentID:   "ocid1.compartment.oc1..aaaaaaaatest",
+				OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
 				OCIRegion:          "us-ashburn-1",
 			},
 			expectError: false,
@@ -76,8 +77,8 @@ func TestConfigValidate(t *testing.T) {
 				SourcePlatform:     "azure",
 				TargetPlatform:     "oci",
 				AzureResourceGroup: "test-rg",
-				OCICompartmentID:   "ocid1.compartment.test",
-				OCISubnetID:        "ocid1.subnet.test",
+				OCICompartmentID:   "ocid1.compartment.oc1..aaaaaaaatest",
+				OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
 				OCIRegion:          "us-ashburn-1",
 			},
 			expectError: true,
@@ -89,7 +90,7 @@ func TestConfigValidate(t *testing.T) {
 				TargetPlatform:     "oci",
 				AzureComputeName:   "test-vm",
 				AzureResourceGroup: "test-rg",
-				OCISubnetID:        "ocid1.subnet.test",
+				OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
 				OCIRegion:          "us-ashburn-1",
 			},
 			expectError: true,
@@ -101,8 +102,150 @@ func TestConfigValidate(t *testing.T) {
 				TargetPlatform:     "oci",
 				AzureComputeName:   "test-vm",
 				AzureResourceGroup: "test-rg",
-				OCICompartmentID:   "ocid1.compartment.test",
-				OCISubnetID:        "ocid1.subnet.test",
+				OCICompartmentID:   "ocid1.compartment.oc1..aaaaaaaatest",
+				OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
+			},
+			expectError: true,
+		},
+		{
+			name: "malformed OCI compartment OCID",
+			config: &Config{
+				SourcePlatform:     "azure",
+				TargetPlatform:     "oci",
+				AzureComputeName:   "test-vm",
+				AzureResourceGroup: "test-rg",
+				OCICompartmentID:   "compartment-1",
+				OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
+				OCIRegion:          "us-ashburn-1",
+			},
+			expectError: true,
+		},
+		{
+			name: "malformed OCI region",
+			config: &Config{
+				SourcePlatform:     "azure",
+				TargetPlatform:     "oci",
+				AzureComputeName:   "test-vm",
+				AzureResourceGroup: "test-rg",
+				OCICompartmentID:   "ocid1.compartment.oc1..aaaaaaaatest",
+				OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
+				OCIRegion:          "Ashburn",
+			},
+			expectError: true,
+		},
+		{
+			name: "swapped compartment and subnet OCIDs",
+			config: &Config{
+				SourcePlatform:     "azure",
+				TargetPlatform:     "oci",
+				AzureComputeName:   "test-vm",
+				AzureResourceGroup: "test-rg",
+				OCICompartmentID:   "ocid1.subnet.oc1.iad.aaaaaaaatest",
+				OCISubnetID:        "ocid1.compartment.oc1..aaaaaaaatest",
+				OCIRegion:          "us-ashburn-1",
+			},
+			expectError: true,
+		},
+		{
+			name: "tenancy OCID as compartment",
+			config: &Config{
+				SourcePlatform:     "azure",
+				TargetPlatform:     "oci",
+				AzureComputeName:   "test-vm",
+				AzureResourceGroup: "test-rg",
+				OCICompartmentID:   "ocid1.tenancy.oc1..aaaaaaaatest",
+				OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
+				OCIRegion:          "us-ashburn-1",
+			},
+			expectError: false,
+		},
+		{
+			name: "unknown OCI region",
+			config: &Config{
+				SourcePlatform:     "azure",
+				TargetPlatform:     "oci",
+				AzureComputeName:   "test-vm",
+				AzureResourceGroup: "test-rg",
+				OCICompartmentID:   "ocid1.compartment.oc1..aaaaaaaatest",
+				OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
+				OCIRegion:          "xx-nowhere-9",
+			},
+			expectError: true,
+		},
+		{
+			name: "OCID from another realm",
+			config: &Config{
+				SourcePlatform:     "azure",
+				TargetPlatform:     "oci",
+				AzureComputeName:   "test-vm",
+				AzureResourceGroup: "test-rg",
+				OCICompartmentID:   "ocid1.compartment.oc2..aaaaaaaatest",
+				OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
+				OCIRegion:          "us-ashburn-1",
+			},
+			expectError: true,
+		},
+		{
+			name: "unsupported source platform",
+			config: &Config{
+				SourcePlatform:   "aws",
+				TargetPlatform:   "oci",
+				OCICompartmentID: "ocid1.compartment.oc1..aaaaaaaatest",
+				OCISubnetID:      "ocid1.subnet.oc1.iad.aaaaaaaatest",
+				OCIRegion:        "us-ashburn-1",
+			},
+			expectError: true,
+		},
+		{
+			name: "snapshot name template without disk placeholder",
+			config: &Config{
+				SourcePlatform:            "azure",
+				TargetPlatform:            "oci",
+				AzureComputeName:          "test-vm",
+				AzureResourceGroup:        "test-rg",
+				OCICompartmentID:          "ocid1.compartment.oc1..aaaaaaaatest",
+				OCISubnetID:               "ocid1.subnet.oc1.iad.aaaaaaaatest",
+				OCIRegion:                 "us-ashburn-1",
+				AzureSnapshotNameTemplate: "kopru-{timestamp}",
+			},
+			expectError: true,
+		},
+		{
+			name: "data disk verification off",
+			config: &Config{
+				SourcePlatform:     "azure",
+				TargetPlatform:     "oci",
+				AzureComputeName:   "test-vm",
+				AzureResourceGroup: "test-rg",
+				OCICompartmentID:   "ocid1.compartment.oc1..aaaaaaaatest",
+				OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
+				OCIRegion:          "us-ashburn-1",
+				DataDiskVerifyMode: "off",
+			},
+			expectError: false,
+		},
+		{
+			name: "unknown data disk verification mode",
+			config: &Config{
+				SourcePlatform:     "azure",
+				TargetPlatform:     "oci",
+				AzureComputeName:   "test-vm",
+				AzureResourceGroup: "test-rg",
+				OCICompartmentID:   "ocid1.compartment.oc1..aaaaaaaatest",
+				OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
+				OCIRegion:          "us-ashburn-1",
+				DataDiskVerifyMode: "none",
+			},
+			expectError: true,
+		},
+		{
+			name: "linux image without OS image URL",
+			config: &Config{
+				SourcePlatform:   "linux_image",
+				TargetPlatform:   "oci",
+				OCICompartmentID: "ocid1.compartment.oc1..aaaaaaaatest",
+				OCISubnetID:      "ocid1.subnet.oc1.iad.aaaaaaaatest",
+				OCIRegion:        "us-ashburn-1",
 			},
 			expectError: true,
 		},
@@ -121,6 +264,47 @@ func TestConfigValidate(t *testing.T) {
 	}
 }
 
+func TestValidateTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.tf.tmpl")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		dir     string
+		wantErr bool
+	}{
+		{"not set", "", false},
+		{"directory", dir, false},
+		{"missing", filepath.Join(dir, "missing"), true},
+		{"file", file, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{TemplateDir: tt.dir}
+			if err := cfg.validateTemplateDir(); (err != nil) != tt.wantErr {
+				t.Errorf("validateTemplateDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParallelDiskPipelinesAlias(t *testing.T) {
+	for _, env := range []string{"PARALLEL_STEPS", "PARALLEL_DISK_PIPELINES"} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, "true")
+			cfg, err := Load("")
+			if err != nil {
+				t.Fatalf("Failed to load config: %v", err)
+			}
+			if !cfg.ParallelSteps {
+				t.Errorf("%s=true did not enable ParallelSteps", env)
+			}
+		})
+	}
+}
+
 func TestConfigDefaults(t *testing.T) {
 	os.Clearenv()
 	cfg, err := Load("")
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/spf13/viper"
+)
+
+var environmentNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// TemplateEnvironment holds the values that differ between the environments a
+// template is deployed to, such as a sandbox compartment used to rehearse the
+// deployment before production.
+type TemplateEnvironment struct {
+	Name               string
+	CompartmentID      string
+	SubnetID           string
+	InstanceName       string
+	AvailabilityDomain string
+	NSGIDs             []string // Network security groups, which belong to the VCN of SubnetID
+}
+
+// EnvPrefix returns the prefix of the environment's variables, e.g. DEV_ for dev.
+func (e TemplateEnvironment) EnvPrefix() string {
+	return strings.ToUpper(strings.ReplaceAll(e.Name, "-", "_")) + "_"
+}
+
+// TemplateEnvironmentList returns the environments listed in TEMPLATE_ENVIRONMENTS, with
+// <ENV>_OCI_COMPARTMENT_ID, <ENV>_OCI_SUBNET_ID, <ENV>_OCI_INSTANCE_NAME,
+// <ENV>_OCI_AVAILABILITY_DOMAIN and <ENV>_OCI_NSG_IDS read from the environment or
+// configuration file.
+func (c *Config) TemplateEnvironmentList() []TemplateEnvironment {
+	var envs []TemplateEnvironment
+	for _, name := range strings.Split(c.TemplateEnvironments, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		e := TemplateEnvironment{Name: name}
+		prefix := strings.ToLower(e.EnvPrefix())
+		e.CompartmentID = viper.GetString(prefix + "oci_compartment_id")
+		e.SubnetID = viper.GetString(prefix + "oci_subnet_id")
+		e.InstanceName = viper.GetString(prefix + "oci_instance_name")
+		e.AvailabilityDomain = viper.GetString(prefix + "oci_availability_domain")
+		e.NSGIDs = splitList(viper.GetString(prefix + "oci_nsg_ids"))
+		envs = append(envs, e)
+	}
+	return envs
+}
+
+// validateTemplateEnvironments checks that each environment has a valid name and
+// valid compartment, subnet and network security group OCIDs.
+func (c *Config) validateTemplateEnvironments() error {
+	var errs []error
+	for _, e := range c.TemplateEnvironmentList() {
+		if !environmentNamePattern.MatchString(e.Name) || e.Name == "terraform" {
+			errs = append(errs, fmt.Errorf("invalid template environment name '%s'", e.Name))
+			continue
+		}
+		for _, f := range []struct {
+			suffix, value, format string
+		}{
+			{"OCI_COMPARTMENT_ID", e.CompartmentID, "ocid:compartment|tenancy"},
+			{"OCI_SUBNET_ID", e.SubnetID, "ocid:subnet"},
+		} {
+			field := Field{Env: e.EnvPrefix() + f.suffix, Format: f.format}
+			if f.value == "" {
+				errs = append(errs, errors.New(i18n.T("config.required", field.Key())))
+				continue
+			}
+			if err := validateFormat(field, f.value, nil); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		field := Field{Env: e.EnvPrefix() + "OCI_NSG_IDS", Format: "ocid:networksecuritygroup"}
+		for _, id := range e.NSGIDs {
+			if err := validateFormat(field, id, nil); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
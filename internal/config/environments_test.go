@@ -0,0 +1,52 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestTemplateEnvironmentList(t *testing.T) {
+	viper.AutomaticEnv()
+	t.Setenv("DEV_OCI_COMPARTMENT_ID", "ocid1.compartment.oc1..aaaaaaaadev")
+	t.Setenv("DEV_OCI_SUBNET_ID", "ocid1.subnet.oc1.iad.aaaaaaaadev")
+	t.Setenv("DEV_OCI_AVAILABILITY_DOMAIN", "2")
+	t.Setenv("PRE_PROD_OCI_COMPARTMENT_ID", "ocid1.compartment.oc1..aaaaaaaapre")
+	t.Setenv("PRE_PROD_OCI_SUBNET_ID", "ocid1.subnet.oc1.iad.aaaaaaaapre")
+	t.Setenv("PRE_PROD_OCI_INSTANCE_NAME", "app-preprod")
+
+	cfg := &Config{TemplateEnvironments: "dev, Pre-Prod,"}
+	envs := cfg.TemplateEnvironmentList()
+	if len(envs) != 2 {
+		t.Fatalf("Expected 2 environments, got %+v", envs)
+	}
+	if envs[0].Name != "dev" || envs[0].CompartmentID != "ocid1.compartment.oc1..aaaaaaaadev" || envs[0].AvailabilityDomain != "2" {
+		t.Errorf("Unexpected dev environment: %+v", envs[0])
+	}
+	if envs[1].Name != "pre-prod" || envs[1].EnvPrefix() != "PRE_PROD_" || envs[1].InstanceName != "app-preprod" {
+		t.Errorf("Unexpected pre-prod environment: %+v", envs[1])
+	}
+	if err := cfg.validateTemplateEnvironments(); err != nil {
+		t.Errorf("Expected valid environments, got %v", err)
+	}
+}
+
+func TestValidateTemplateEnvironments(t *testing.T) {
+	viper.AutomaticEnv()
+	t.Setenv("STAGE_OCI_COMPARTMENT_ID", "ocid1.subnet.oc1.iad.aaaaaaaastage")
+
+	err := (&Config{TemplateEnvironments: "stage"}).validateTemplateEnvironments()
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+	for _, want := range []string{"stage_oci_compartment_id", "stage_oci_subnet_id is required"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %q, got: %v", want, err)
+		}
+	}
+
+	if err := (&Config{TemplateEnvironments: "terraform"}).validateTemplateEnvironments(); err == nil {
+		t.Error("Expected error for environment that would overwrite terraform.tfvars")
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+)
+
+// Azure resource types of the groups of VMs of AZURE_COMPUTE_GROUP.
+const (
+	GroupAvailabilitySet = "availabilitySets"
+	GroupScaleSet        = "virtualMachineScaleSets"
+)
+
+// ComputeGroup returns the resource type and name of AZURE_COMPUTE_GROUP, e.g.
+// availabilitySets and web-avset for availabilitySets/web-avset. ok is false when it
+// is not set or names another resource type.
+func (c *Config) ComputeGroup() (groupType, name string, ok bool) {
+	groupType, name, found := strings.Cut(c.AzureComputeGroup, "/")
+	if !found || name == "" || strings.Contains(name, "/") {
+		return "", "", false
+	}
+	for _, t := range []string{GroupAvailabilitySet, GroupScaleSet} {
+		if strings.EqualFold(groupType, t) {
+			return t, name, true
+		}
+	}
+	return "", "", false
+}
+
+// resolveComputeGroup reduces an AZURE_COMPUTE_GROUP resource ID to <type>/<name>, taking
+// the subscription and resource group from it, and makes AZURE_COMPUTE_NAME select all
+// the VMs of the group when it is not set.
+func (c *Config) resolveComputeGroup() error {
+	if c.AzureComputeGroup == "" {
+		return nil
+	}
+	if IsAzureResourceID(c.AzureComputeGroup) {
+		id, err := arm.ParseResourceID(c.AzureComputeGroup)
+		if err != nil || !strings.EqualFold(id.ResourceType.Namespace, "Microsoft.Compute") || len(id.ResourceType.Types) != 1 {
+			return fmt.Errorf("AZURE_COMPUTE_GROUP is not the resource ID of an availability set or a VM scale set: '%s'", c.AzureComputeGroup)
+		}
+		if err := c.setAzureSubscription(id.SubscriptionID); err != nil {
+			return err
+		}
+		if c.AzureResourceGroup != "" && !strings.EqualFold(c.AzureResourceGroup, id.ResourceGroupName) {
+			return errors.New(i18n.T("config.azure_id_conflict", "AZURE_RESOURCE_GROUP", c.AzureResourceGroup, id.ResourceGroupName))
+		}
+		c.AzureResourceGroup = id.ResourceGroupName
+		c.AzureComputeGroup = id.ResourceType.Type + "/" + id.Name
+	}
+	if c.AzureComputeName == "" {
+		c.AzureComputeName = "*"
+	}
+	return nil
+}
+
+// validateComputeGroup checks that AZURE_COMPUTE_GROUP names an availability set or a
+// VM scale set, whose VMs AZURE_COMPUTE_NAME can only narrow down with a pattern, and
+// that OCI_INSTANCE_POOL has instances of a group to pool.
+func (c *Config) validateComputeGroup() error {
+	if c.AzureComputeGroup != "" {
+		if c.SourcePlatform != "azure" {
+			return errors.New("AZURE_COMPUTE_GROUP requires SOURCE_PLATFORM=azure")
+		}
+		if _, _, ok := c.ComputeGroup(); !ok {
+			return fmt.Errorf("AZURE_COMPUTE_GROUP: '%s' is not %s/<name>, %s/<name> or the resource ID of one", c.AzureComputeGroup, GroupAvailabilitySet, GroupScaleSet)
+		}
+		if !IsComputeNamePattern(c.AzureComputeName) {
+			return fmt.Errorf("AZURE_COMPUTE_NAME must be empty or a pattern with AZURE_COMPUTE_GROUP, got '%s'", c.AzureComputeName)
+		}
+	}
+	if !c.OCIInstancePool {
+		return nil
+	}
+	switch {
+	case c.AzureComputeGroup == "":
+		return errors.New("OCI_INSTANCE_POOL requires AZURE_COMPUTE_GROUP")
+	case c.SkipTemplateDeploy:
+		return errors.New("OCI_INSTANCE_POOL cannot be set with SKIP_TEMPLATE_DEPLOY; the pool is made of the deployed instances")
+	case c.AttachesToInstance():
+		return errors.New("OCI_INSTANCE_POOL cannot be set with OCI_TARGET_INSTANCE_ID")
+	}
+	return nil
+}
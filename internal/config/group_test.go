@@ -0,0 +1,76 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeGroup(t *testing.T) {
+	tests := []struct {
+		group    string
+		wantType string
+		wantName string
+		wantOK   bool
+	}{
+		{"availabilitySets/web-avset", GroupAvailabilitySet, "web-avset", true},
+		{"VirtualMachineScaleSets/web-vmss", GroupScaleSet, "web-vmss", true},
+		{"virtualMachines/web-01", "", "", false},
+		{"availabilitySets/", "", "", false},
+		{"web-avset", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		cfg := &Config{AzureComputeGroup: tt.group}
+		groupType, name, ok := cfg.ComputeGroup()
+		if groupType != tt.wantType || name != tt.wantName || ok != tt.wantOK {
+			t.Errorf("ComputeGroup(%q) = %q, %q, %v", tt.group, groupType, name, ok)
+		}
+	}
+}
+
+func TestResolveComputeGroup(t *testing.T) {
+	cfg := &Config{AzureComputeGroup: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/web-rg/providers/Microsoft.Compute/availabilitySets/web-avset"}
+	if err := cfg.resolveComputeGroup(); err != nil {
+		t.Fatalf("resolveComputeGroup() error = %v", err)
+	}
+	if cfg.AzureComputeGroup != "availabilitySets/web-avset" || cfg.AzureResourceGroup != "web-rg" || cfg.AzureComputeName != "*" {
+		t.Errorf("resolveComputeGroup() = %q, %q, %q", cfg.AzureComputeGroup, cfg.AzureResourceGroup, cfg.AzureComputeName)
+	}
+	if cfg.AzureSubscriptionID != "00000000-0000-0000-0000-000000000000" {
+		t.Errorf("Expected the subscription of the resource ID, got %q", cfg.AzureSubscriptionID)
+	}
+
+	cfg = &Config{AzureComputeGroup: "virtualMachineScaleSets/web-vmss", AzureComputeName: "web-0*"}
+	if err := cfg.resolveComputeGroup(); err != nil || cfg.AzureComputeName != "web-0*" {
+		t.Errorf("resolveComputeGroup() = %q, %v; want the pattern kept", cfg.AzureComputeName, err)
+	}
+
+	cfg = &Config{AzureComputeGroup: "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/web-rg/providers/Microsoft.Network/virtualNetworks/vnet"}
+	if err := cfg.resolveComputeGroup(); err == nil {
+		t.Error("Expected an error for the resource ID of another resource type")
+	}
+}
+
+func TestValidateComputeGroup(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"no group", Config{SourcePlatform: "azure", AzureComputeName: "web-01"}, ""},
+		{"group", Config{SourcePlatform: "azure", AzureComputeGroup: "availabilitySets/web", AzureComputeName: "*"}, ""},
+		{"pool", Config{SourcePlatform: "azure", AzureComputeGroup: "availabilitySets/web", AzureComputeName: "*", OCIInstancePool: true}, ""},
+		{"platform", Config{SourcePlatform: "ubuntu", AzureComputeGroup: "availabilitySets/web", AzureComputeName: "*"}, "SOURCE_PLATFORM"},
+		{"type", Config{SourcePlatform: "azure", AzureComputeGroup: "virtualMachines/web", AzureComputeName: "*"}, "AZURE_COMPUTE_GROUP"},
+		{"single VM", Config{SourcePlatform: "azure", AzureComputeGroup: "availabilitySets/web", AzureComputeName: "web-01"}, "AZURE_COMPUTE_NAME"},
+		{"pool without group", Config{SourcePlatform: "azure", AzureComputeName: "web-*", OCIInstancePool: true}, "requires AZURE_COMPUTE_GROUP"},
+		{"pool without deploy", Config{SourcePlatform: "azure", AzureComputeGroup: "availabilitySets/web", AzureComputeName: "*", OCIInstancePool: true, SkipTemplateDeploy: true}, "SKIP_TEMPLATE_DEPLOY"},
+		{"pool of attachments", Config{SourcePlatform: "azure", AzureComputeGroup: "availabilitySets/web", AzureComputeName: "*", OCIInstancePool: true, OCITargetInstanceID: "ocid1.instance.oc1..x"}, "OCI_TARGET_INSTANCE_ID"},
+	}
+	for _, tt := range tests {
+		err := tt.cfg.validateComputeGroup()
+		if (tt.want == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("%s: validateComputeGroup() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
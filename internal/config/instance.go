@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxNSGsPerVNIC is the number of network security groups a VNIC can belong to.
+const MaxNSGsPerVNIC = 5
+
+var (
+	hostnameLabelPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{0,62}$`)
+	definedTagKeyPattern = regexp.MustCompile(`^[^.\s]+\.[^.\s]+$`)
+	shapePattern         = regexp.MustCompile(`^(VM|BM)\.[A-Za-z0-9.-]+$`)
+)
+
+// States of the instance after deployment.
+const (
+	InstanceStateRunning = "RUNNING"
+	InstanceStateStopped = "STOPPED"
+)
+
+// InstanceState returns the state the instance is left in after deployment, RUNNING
+// unless OCI_INSTANCE_STATE is set.
+func (c *Config) InstanceState() string {
+	if c.OCIInstanceState == "" {
+		return InstanceStateRunning
+	}
+	return c.OCIInstanceState
+}
+
+// StartsStopped reports whether the instance is stopped after deployment, to be started
+// with kopru start once the platform team has finished network and DNS work.
+func (c *Config) StartsStopped() bool {
+	return c.InstanceState() == InstanceStateStopped
+}
+
+// NSGIDs returns the OCIDs of the network security groups in OCI_NSG_IDS.
+func (c *Config) NSGIDs() []string {
+	return splitList(c.OCINSGIDs)
+}
+
+// DefinedTags returns the defined tags of OCI_DEFINED_TAGS by namespace.key. Malformed
+// entries are reported by validateInstanceCompliance.
+func (c *Config) DefinedTags() map[string]string {
+	tags := make(map[string]string)
+	for _, entry := range splitList(c.OCIDefinedTags) {
+		if key, value, ok := strings.Cut(entry, "="); ok {
+			tags[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return tags
+}
+
+// validateInstanceCompliance checks the network security groups, hostname label and
+// defined tags that landing zones require of the instance.
+func (c *Config) validateInstanceCompliance() error {
+	var errs []error
+	ids := c.NSGIDs()
+	if len(ids) > MaxNSGsPerVNIC {
+		errs = append(errs, fmt.Errorf("OCI_NSG_IDS has %d network security groups, a VNIC can belong to at most %d", len(ids), MaxNSGsPerVNIC))
+	}
+	field := Field{Env: "OCI_NSG_IDS", Format: "ocid:networksecuritygroup"}
+	for _, id := range ids {
+		if err := validateFormat(field, id, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.OCIHostnameLabel != "" && !hostnameLabelPattern.MatchString(c.OCIHostnameLabel) {
+		errs = append(errs, fmt.Errorf("OCI_HOSTNAME_LABEL must start with a letter and have at most 63 letters, digits and hyphens: '%s'", c.OCIHostnameLabel))
+	}
+	for _, entry := range splitList(c.OCIDefinedTags) {
+		key, _, ok := strings.Cut(entry, "=")
+		if !ok || !definedTagKeyPattern.MatchString(strings.TrimSpace(key)) {
+			errs = append(errs, fmt.Errorf("OCI_DEFINED_TAGS: invalid tag '%s', expected namespace.key=value", entry))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// splitList returns the non-empty, trimmed items of a comma-separated list.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// AttachesToInstance reports whether only the data disks are migrated, and attached to
+// the existing instance OCI_TARGET_INSTANCE_ID instead of a new instance.
+func (c *Config) AttachesToInstance() bool {
+	return c.OCITargetInstanceID != ""
+}
+
+// validateTargetInstance checks that OCI_TARGET_INSTANCE_ID is only combined with
+// settings that apply to data disks.
+func (c *Config) validateTargetInstance() error {
+	if !c.AttachesToInstance() {
+		return nil
+	}
+	if c.SourcePlatform != "azure" {
+		return errors.New("OCI_TARGET_INSTANCE_ID requires SOURCE_PLATFORM=azure; only the data disks of Azure VMs can be attached to an existing instance")
+	}
+	if c.TemplateEnvironments != "" {
+		return errors.New("TEMPLATE_ENVIRONMENTS cannot be set with OCI_TARGET_INSTANCE_ID")
+	}
+	return nil
+}
+
+// Shapes returns the shapes of OCI_SHAPES in order of preference.
+func (c *Config) Shapes() []string {
+	return splitList(c.OCIShapes)
+}
+
+// validateShapes checks that OCI_SHAPES names virtual machine or bare metal shapes.
+func (c *Config) validateShapes() error {
+	var errs []error
+	for _, shape := range c.Shapes() {
+		if !shapePattern.MatchString(shape) {
+			errs = append(errs, fmt.Errorf("OCI_SHAPES: '%s' is not a shape name, e.g. VM.Standard.E5.Flex", shape))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefinedTags(t *testing.T) {
+	cfg := &Config{OCIDefinedTags: "Operations.CostCenter=42, Security.Classification = internal,"}
+	want := map[string]string{"Operations.CostCenter": "42", "Security.Classification": "internal"}
+	if got := cfg.DefinedTags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DefinedTags() = %v, want %v", got, want)
+	}
+}
+
+func TestValidateInstanceCompliance(t *testing.T) {
+	nsg := "ocid1.networksecuritygroup.oc1.iad.aaaaaaaatest"
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"not set", Config{}, ""},
+		{"valid", Config{OCINSGIDs: nsg + "," + nsg, OCIHostnameLabel: "web-01", OCIDefinedTags: "Operations.CostCenter=42"}, ""},
+		{"subnet as NSG", Config{OCINSGIDs: "ocid1.subnet.oc1.iad.aaaaaaaatest"}, "oci_nsg_ids"},
+		{"too many NSGs", Config{OCINSGIDs: strings.Repeat(nsg+",", 6)}, "at most 5"},
+		{"hostname label with dot", Config{OCIHostnameLabel: "web.example"}, "OCI_HOSTNAME_LABEL"},
+		{"hostname label starting with digit", Config{OCIHostnameLabel: "1web"}, "OCI_HOSTNAME_LABEL"},
+		{"tag without namespace", Config{OCIDefinedTags: "CostCenter=42"}, "OCI_DEFINED_TAGS"},
+		{"tag without value", Config{OCIDefinedTags: "Operations.CostCenter"}, "OCI_DEFINED_TAGS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validateInstanceCompliance()
+			if (tt.want == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("validateInstanceCompliance() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateShapes(t *testing.T) {
+	for value, expectError := range map[string]bool{
+		"":                                       false,
+		"VM.Standard.E5.Flex, VM.Standard3.Flex": false,
+		"BM.Standard.E4.128":                     false,
+		"E5.Flex":                                true,
+	} {
+		err := (&Config{OCIShapes: value}).validateShapes()
+		if (err != nil) != expectError {
+			t.Errorf("validateShapes(%q) error = %v, expectError %v", value, err, expectError)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package config
+
+import "errors"
+
+// validateLiveSync checks that LIVE_SYNC applies to Azure VMs, whose disks it does not
+// also snapshot while quiesced, and that the VM is given time to stop.
+func (c *Config) validateLiveSync() error {
+	if !c.LiveSync {
+		return nil
+	}
+	if c.SourcePlatform != "azure" {
+		return errors.New("LIVE_SYNC requires SOURCE_PLATFORM=azure")
+	}
+	if c.AzureSnapshotConsistency == "fsfreeze" || c.AzurePreSnapshotScript != "" || c.AzurePostSnapshotScript != "" {
+		return errors.New("LIVE_SYNC cannot be set with AZURE_SNAPSHOT_CONSISTENCY=fsfreeze or snapshot scripts: the final pass is taken from the stopped VM")
+	}
+	if c.LiveSyncStopTimeoutMinutes < 1 {
+		return errors.New("LIVE_SYNC_STOP_TIMEOUT_MINUTES must be at least 1")
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+func TestValidateLiveSync(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"disabled", Config{}, false},
+		{"azure", Config{LiveSync: true, SourcePlatform: "azure", AzureSnapshotConsistency: "crash", LiveSyncStopTimeoutMinutes: 60}, false},
+		{"linux image", Config{LiveSync: true, SourcePlatform: "linux_image", LiveSyncStopTimeoutMinutes: 60}, true},
+		{"fsfreeze", Config{LiveSync: true, SourcePlatform: "azure", AzureSnapshotConsistency: "fsfreeze", LiveSyncStopTimeoutMinutes: 60}, true},
+		{"pre-snapshot script", Config{LiveSync: true, SourcePlatform: "azure", AzurePreSnapshotScript: "pre.sh", LiveSyncStopTimeoutMinutes: 60}, true},
+		{"no stop timeout", Config{LiveSync: true, SourcePlatform: "azure"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.validateLiveSync(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateLiveSync() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+)
+
+// BatchManifest returns the VMs listed in BATCH_MANIFEST_FILE, one per line, as names of
+// VMs of AZURE_RESOURCE_GROUP or as VM resource IDs. Blank lines and lines starting
+// with # are ignored. kopru discover writes such a file.
+func (c *Config) BatchManifest() ([]string, error) {
+	if c.BatchManifestFile == "" {
+		return nil, nil
+	}
+	f, err := os.Open(c.BatchManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("BATCH_MANIFEST_FILE: %w", err)
+	}
+	defer f.Close()
+	var vms []string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, ok := virtualMachineID(line); !ok && (IsAzureResourceID(line) || strings.Contains(line, "/") || IsComputeNamePattern(line)) {
+			return nil, fmt.Errorf("BATCH_MANIFEST_FILE line %d: expected a VM name or the resource ID of a VM, got '%s'", n, line)
+		}
+		vms = append(vms, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read BATCH_MANIFEST_FILE: %w", err)
+	}
+	return vms, nil
+}
+
+// AzureVMName returns the name of an Azure VM resource ID, or vm itself when it is not a
+// resource ID.
+func AzureVMName(vm string) string {
+	if id, ok := virtualMachineID(vm); ok {
+		return id.Name
+	}
+	return vm
+}
+
+// virtualMachineID parses the resource ID of an Azure VM.
+func virtualMachineID(value string) (*arm.ResourceID, bool) {
+	if !IsAzureResourceID(value) {
+		return nil, false
+	}
+	id, err := arm.ParseResourceID(value)
+	if err != nil || !strings.EqualFold(id.ResourceType.String(), virtualMachineResourceType.String()) {
+		return nil, false
+	}
+	return id, true
+}
+
+// resolveBatchManifest makes AZURE_COMPUTE_NAME select all the VMs of BATCH_MANIFEST_FILE
+// when it is not set, and takes AZURE_RESOURCE_GROUP, in which the VMs listed by name
+// are looked up, from the first VM listed by resource ID when it is not set either.
+// A manifest that cannot be read is reported by Validate.
+func (c *Config) resolveBatchManifest() {
+	if c.BatchManifestFile == "" {
+		return
+	}
+	if c.AzureComputeName == "" {
+		c.AzureComputeName = "*"
+	}
+	if c.AzureResourceGroup != "" {
+		return
+	}
+	vms, _ := c.BatchManifest()
+	for _, vm := range vms {
+		if id, ok := virtualMachineID(vm); ok {
+			c.AzureResourceGroup = id.ResourceGroupName
+			if c.AzureSubscriptionID == "" {
+				c.AzureSubscriptionID = id.SubscriptionID
+			}
+			return
+		}
+	}
+}
+
+// validateBatchManifest checks that BATCH_MANIFEST_FILE lists VMs, which AZURE_COMPUTE_NAME
+// can only narrow down with a pattern.
+func (c *Config) validateBatchManifest() error {
+	if c.BatchManifestFile == "" {
+		return nil
+	}
+	if c.SourcePlatform != "azure" {
+		return errors.New("BATCH_MANIFEST_FILE requires SOURCE_PLATFORM=azure")
+	}
+	if c.AzureComputeGroup != "" {
+		return errors.New("BATCH_MANIFEST_FILE cannot be set with AZURE_COMPUTE_GROUP")
+	}
+	if !IsComputeNamePattern(c.AzureComputeName) {
+		return fmt.Errorf("AZURE_COMPUTE_NAME must be empty or a pattern with BATCH_MANIFEST_FILE, got '%s'", c.AzureComputeName)
+	}
+	vms, err := c.BatchManifest()
+	if err != nil {
+		return err
+	}
+	if len(vms) == 0 {
+		return fmt.Errorf("BATCH_MANIFEST_FILE lists no VM: '%s'", c.BatchManifestFile)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const manifestVMID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/web-rg/providers/Microsoft.Compute/virtualMachines/web-01"
+
+func writeManifest(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "vms.txt")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBatchManifest(t *testing.T) {
+	cfg := &Config{BatchManifestFile: writeManifest(t, "# VMs tagged migrate=true\n\n"+manifestVMID+"\n  web-02  \n")}
+	vms, err := cfg.BatchManifest()
+	if err != nil {
+		t.Fatalf("BatchManifest() error = %v", err)
+	}
+	if want := []string{manifestVMID, "web-02"}; !slices.Equal(vms, want) {
+		t.Errorf("BatchManifest() = %v, want %v", vms, want)
+	}
+
+	for _, line := range []string{"web-*", "web-rg/web-01", "/subscriptions/s/resourceGroups/web-rg/providers/Microsoft.Network/virtualNetworks/vnet"} {
+		cfg := &Config{BatchManifestFile: writeManifest(t, "web-01\n"+line+"\n")}
+		if _, err := cfg.BatchManifest(); err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("BatchManifest() with %q: error = %v, want an error on line 2", line, err)
+		}
+	}
+}
+
+func TestAzureVMName(t *testing.T) {
+	if got := AzureVMName(manifestVMID); got != "web-01" {
+		t.Errorf("AzureVMName(resource ID) = %q, want web-01", got)
+	}
+	if got := AzureVMName("web-02"); got != "web-02" {
+		t.Errorf("AzureVMName(name) = %q, want web-02", got)
+	}
+}
+
+func TestResolveBatchManifest(t *testing.T) {
+	cfg := &Config{BatchManifestFile: writeManifest(t, "web-02\n"+manifestVMID+"\n")}
+	cfg.resolveBatchManifest()
+	if cfg.AzureComputeName != "*" || cfg.AzureResourceGroup != "web-rg" || cfg.AzureSubscriptionID != "00000000-0000-0000-0000-000000000000" {
+		t.Errorf("resolveBatchManifest() = %q, %q, %q", cfg.AzureComputeName, cfg.AzureResourceGroup, cfg.AzureSubscriptionID)
+	}
+
+	cfg = &Config{BatchManifestFile: cfg.BatchManifestFile, AzureComputeName: "web-0*", AzureResourceGroup: "app-rg"}
+	cfg.resolveBatchManifest()
+	if cfg.AzureComputeName != "web-0*" || cfg.AzureResourceGroup != "app-rg" {
+		t.Errorf("resolveBatchManifest() overrode %q, %q", cfg.AzureComputeName, cfg.AzureResourceGroup)
+	}
+
+	vm := cfg.ForComputeName(manifestVMID)
+	if vm.AzureComputeName != "web-01" || vm.AzureResourceGroup != "web-rg" || vm.OCIInstanceName != "web-01" {
+		t.Errorf("ForComputeName(resource ID) = %q, %q, %q", vm.AzureComputeName, vm.AzureResourceGroup, vm.OCIInstanceName)
+	}
+}
+
+func TestValidateBatchManifest(t *testing.T) {
+	manifest := writeManifest(t, manifestVMID+"\n")
+	empty := writeManifest(t, "# no VM\n")
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"no manifest", Config{SourcePlatform: "azure", AzureComputeName: "web-01"}, ""},
+		{"manifest", Config{SourcePlatform: "azure", BatchManifestFile: manifest, AzureComputeName: "*"}, ""},
+		{"platform", Config{SourcePlatform: "ubuntu", BatchManifestFile: manifest, AzureComputeName: "*"}, "SOURCE_PLATFORM"},
+		{"group", Config{SourcePlatform: "azure", BatchManifestFile: manifest, AzureComputeName: "*", AzureComputeGroup: "availabilitySets/web"}, "AZURE_COMPUTE_GROUP"},
+		{"single VM", Config{SourcePlatform: "azure", BatchManifestFile: manifest, AzureComputeName: "web-01"}, "AZURE_COMPUTE_NAME"},
+		{"empty", Config{SourcePlatform: "azure", BatchManifestFile: empty, AzureComputeName: "*"}, "lists no VM"},
+		{"missing", Config{SourcePlatform: "azure", BatchManifestFile: filepath.Join(t.TempDir(), "missing.txt"), AzureComputeName: "*"}, "BATCH_MANIFEST_FILE"},
+	}
+	for _, tt := range tests {
+		err := tt.cfg.validateBatchManifest()
+		if (tt.want == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("%s: validateBatchManifest() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
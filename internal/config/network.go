@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SubnetMap returns the OCI subnet OCIDs of OCI_SUBNET_MAP_FILE by Azure subnet. Each
+// line of the file maps an Azure subnet, as <vnet>/<subnet> or its resource ID, to an
+// OCI subnet: <vnet>/<subnet>=<subnet OCID>. Keys are case-insensitive, like Azure
+// resource names.
+func (c *Config) SubnetMap() (map[string]string, error) {
+	if c.OCISubnetMapFile == "" {
+		return nil, nil
+	}
+	entries, err := readMapFile("OCI_SUBNET_MAP_FILE", c.OCISubnetMapFile, "<vnet>/<subnet>=<subnet OCID>")
+	if err != nil {
+		return nil, err
+	}
+	subnets := make(map[string]string, len(entries))
+	for key, value := range entries {
+		subnets[subnetMapKey(key)] = value
+	}
+	return subnets, nil
+}
+
+// MapSubnet returns the OCI subnet that subnets maps an Azure subnet to, if any. The
+// Azure subnet is given as <vnet>/<subnet> or its resource ID.
+func MapSubnet(subnets map[string]string, azureSubnet string) (string, bool) {
+	id, ok := subnets[subnetMapKey(azureSubnet)]
+	return id, ok
+}
+
+// AzureSubnetName returns the <vnet>/<subnet> name of an Azure subnet resource ID, or
+// subnet itself when it is not a resource ID.
+func AzureSubnetName(subnet string) string {
+	parts := strings.Split(strings.Trim(subnet, "/"), "/")
+	if n := len(parts); n >= 4 && strings.EqualFold(parts[n-4], "virtualNetworks") && strings.EqualFold(parts[n-2], "subnets") {
+		return parts[n-3] + "/" + parts[n-1]
+	}
+	return subnet
+}
+
+// subnetMapKey returns the lookup key of an Azure subnet in OCI_SUBNET_MAP_FILE.
+func subnetMapKey(subnet string) string {
+	return strings.ToLower(AzureSubnetName(subnet))
+}
+
+// validateSubnetMap checks that OCI_SUBNET_MAP_FILE can be read and maps Azure subnets
+// to subnet OCIDs.
+func (c *Config) validateSubnetMap() error {
+	subnets, err := c.SubnetMap()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	field := Field{Env: "OCI_SUBNET_MAP_FILE", Format: "ocid:subnet"}
+	for key, id := range subnets {
+		if err := validateFormat(field, id, nil); err != nil {
+			errs = append(errs, fmt.Errorf("%w (subnet %s)", err, key))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSubnetMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subnets.map")
+	content := `# Azure subnet = OCI subnet
+Hub/DB = ocid1.subnet.oc1.iad.db
+
+/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/hub/subnets/app=ocid1.subnet.oc1.iad.app
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	subnets, err := (&Config{OCISubnetMapFile: path}).SubnetMap()
+	if err != nil {
+		t.Fatalf("SubnetMap() error = %v", err)
+	}
+	tests := map[string]string{
+		"hub/db": "ocid1.subnet.oc1.iad.db",
+		"/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/hub/subnets/db": "ocid1.subnet.oc1.iad.db",
+		"hub/app": "ocid1.subnet.oc1.iad.app",
+		"hub/web": "",
+	}
+	for subnet, want := range tests {
+		if got, _ := MapSubnet(subnets, subnet); got != want {
+			t.Errorf("MapSubnet(%q) = %q, want %q", subnet, got, want)
+		}
+	}
+}
+
+func TestValidateSubnetMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"valid", "hub/db=ocid1.subnet.oc1.iad.db\n", false},
+		{"missing OCID", "hub/db\n", true},
+		{"not a subnet OCID", "hub/db=ocid1.vcn.oc1.iad.hub\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "subnets.map")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			if err := (&Config{OCISubnetMapFile: path}).validateSubnetMap(); (err != nil) != tt.wantErr {
+				t.Errorf("validateSubnetMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if err := (&Config{OCISubnetMapFile: filepath.Join(t.TempDir(), "missing")}).validateSubnetMap(); err == nil {
+		t.Error("validateSubnetMap() with a missing file succeeded")
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import "errors"
+
+// StopsSourceVM reports whether Kopru deallocates the running Azure VM before its disks
+// are exported.
+func (c *Config) StopsSourceVM() bool {
+	return c.AzureStopVM || c.AzureForceStop
+}
+
+// validateSourcePower checks that the VM is only restarted when Kopru deallocates it, and
+// is given time to change its power state.
+func (c *Config) validateSourcePower() error {
+	if !c.StopsSourceVM() {
+		if c.AzureRestartVM {
+			return errors.New("AZURE_RESTART_VM requires AZURE_STOP_VM or AZURE_FORCE_STOP")
+		}
+		return nil
+	}
+	if c.SourcePlatform != "azure" {
+		return errors.New("AZURE_STOP_VM and AZURE_FORCE_STOP require SOURCE_PLATFORM=azure")
+	}
+	if c.AzurePowerTimeoutMinutes < 1 {
+		return errors.New("AZURE_POWER_TIMEOUT_MINUTES must be at least 1")
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestValidateSourcePower(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"disabled", Config{}, false},
+		{"stop", Config{AzureStopVM: true, SourcePlatform: "azure", AzurePowerTimeoutMinutes: 15}, false},
+		{"force stop and restart", Config{AzureForceStop: true, AzureRestartVM: true, SourcePlatform: "azure", AzurePowerTimeoutMinutes: 15}, false},
+		{"restart without stop", Config{AzureRestartVM: true, SourcePlatform: "azure", AzurePowerTimeoutMinutes: 15}, true},
+		{"linux image", Config{AzureStopVM: true, SourcePlatform: "linux_image", AzurePowerTimeoutMinutes: 15}, true},
+		{"no timeout", Config{AzureForceStop: true, SourcePlatform: "azure"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.validateSourcePower(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateSourcePower() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestStopsSourceVM(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		cfg  Config
+		want bool
+	}{
+		{"disabled", Config{}, false},
+		{"stop", Config{AzureStopVM: true}, true},
+		{"force stop", Config{AzureForceStop: true}, true},
+	} {
+		if got := tt.cfg.StopsSourceVM(); got != tt.want {
+			t.Errorf("%s: StopsSourceVM() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// DefaultProfile is the profile used when --profile is not set.
+const DefaultProfile = "default"
+
+// profileHeader matches the section headers of a configuration file with profiles,
+// "[profile name]" or "[name]".
+var profileHeader = regexp.MustCompile(`^\[\s*(?:profile\s+)?([^\]\s]+)\s*\]$`)
+
+// DefaultProfilesDir returns the directory searched for <profile>.env files when no
+// configuration file is given and ./kopru-config.env does not exist.
+func DefaultProfilesDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kopru", "profiles")
+}
+
+// Profiles returns the names of the profiles defined in a configuration file, in the
+// order they appear.
+func Profiles(data []byte) []string {
+	var profiles []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if m := profileHeader.FindStringSubmatch(strings.TrimSpace(scanner.Text())); m != nil {
+			profiles = append(profiles, m[1])
+		}
+	}
+	return profiles
+}
+
+// ListProfiles returns the profiles of path, a configuration file or a directory of
+// <profile>.env files.
+func ListProfiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		files, err := filepath.Glob(filepath.Join(path, "*.env"))
+		if err != nil {
+			return nil, err
+		}
+		profiles := make([]string, 0, len(files))
+		for _, file := range files {
+			profiles = append(profiles, strings.TrimSuffix(filepath.Base(file), ".env"))
+		}
+		return profiles, nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- the configuration file is given by the user
+	if err != nil {
+		return nil, err
+	}
+	if profiles := Profiles(data); len(profiles) > 0 {
+		return profiles, nil
+	}
+	return []string{DefaultProfile}, nil
+}
+
+// ProfileContent returns the settings of profile from a configuration file: the lines
+// before the first section, shared by all profiles, followed by the lines of the
+// profile's section. A file without sections only has the default profile.
+func ProfileContent(data []byte, profile string) ([]byte, error) {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	var b bytes.Buffer
+	found, include := false, true
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := profileHeader.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			include = m[1] == profile
+			found = found || include
+			continue
+		}
+		if include {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found && profile != DefaultProfile {
+		return nil, fmt.Errorf("profile %s not found (available: %s)", profile, strings.Join(Profiles(data), ", "))
+	}
+	return b.Bytes(), nil
+}
+
+// ReadConfigFile reads the settings of profile into the global Viper instance from
+// path, either a configuration file with profile sections or a directory of
+// <profile>.env files. Files in other formats than env (e.g. YAML) have no profiles.
+// It returns the file read.
+func ReadConfigFile(path, profile string) (string, error) {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	section := profile
+	if info.IsDir() {
+		path, section = filepath.Join(path, profile+".env"), DefaultProfile
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("profile %s not found: %w", profile, err)
+		}
+	} else if ext := filepath.Ext(path); ext != "" && ext != ".env" {
+		if profile != DefaultProfile {
+			return "", fmt.Errorf("profiles are only supported in env configuration files: %s", path)
+		}
+		viper.SetConfigFile(path)
+		if err := viper.ReadInConfig(); err != nil {
+			return "", fmt.Errorf("failed to read config file: %w", err)
+		}
+		return path, nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- the configuration file is given by the user
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+	content, err := ProfileContent(data, section)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	viper.SetConfigType("env")
+	if err := viper.ReadConfig(bytes.NewReader(content)); err != nil {
+		return "", fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const profilesFile = `# shared by all profiles
+OCI_IMAGE_OS="Ubuntu"
+
+[profile prod]
+OCI_REGION="us-ashburn-1"
+AZURE_COMPUTE_NAME="vm-prod"
+
+[dev]
+OCI_REGION="eu-frankfurt-1"
+`
+
+func TestProfileContent(t *testing.T) {
+	tests := []struct {
+		profile  string
+		expected string
+	}{
+		{"prod", "# shared by all profiles\nOCI_IMAGE_OS=\"Ubuntu\"\n\nOCI_REGION=\"us-ashburn-1\"\nAZURE_COMPUTE_NAME=\"vm-prod\"\n\n"},
+		{"dev", "# shared by all profiles\nOCI_IMAGE_OS=\"Ubuntu\"\n\nOCI_REGION=\"eu-frankfurt-1\"\n"},
+		{"", "# shared by all profiles\nOCI_IMAGE_OS=\"Ubuntu\"\n\n"},
+	}
+	for _, tt := range tests {
+		got, err := ProfileContent([]byte(profilesFile), tt.profile)
+		if err != nil {
+			t.Fatalf("ProfileContent(%q) error = %v", tt.profile, err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("ProfileContent(%q) = %q, expected %q", tt.profile, got, tt.expected)
+		}
+	}
+	if _, err := ProfileContent([]byte(profilesFile), "staging"); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+	if got := Profiles([]byte(profilesFile)); !reflect.DeepEqual(got, []string{"prod", "dev"}) {
+		t.Errorf("Profiles() = %v", got)
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "kopru-config.env")
+	if err := os.WriteFile(file, []byte(profilesFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+	profilesDir := filepath.Join(dir, "profiles")
+	if err := os.MkdirAll(profilesDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(profilesDir, "test.env"), []byte("OCI_REGION=\"uk-london-1\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path, profile, region string
+	}{
+		{file, "prod", "us-ashburn-1"},
+		{file, "dev", "eu-frankfurt-1"},
+		{profilesDir, "test", "uk-london-1"},
+	}
+	for _, tt := range tests {
+		viper.Reset()
+		if _, err := ReadConfigFile(tt.path, tt.profile); err != nil {
+			t.Fatalf("ReadConfigFile(%s, %s) error = %v", tt.path, tt.profile, err)
+		}
+		if got := viper.GetString("oci_region"); got != tt.region {
+			t.Errorf("ReadConfigFile(%s, %s): oci_region = %q, expected %q", tt.path, tt.profile, got, tt.region)
+		}
+	}
+	viper.Reset()
+	if _, err := ReadConfigFile(profilesDir, "prod"); err == nil {
+		t.Error("Expected error for missing profile file")
+	}
+	if got, err := ListProfiles(profilesDir); err != nil || !reflect.DeepEqual(got, []string{"test"}) {
+		t.Errorf("ListProfiles() = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,19 @@
+package config
+
+import "os"
+
+// exportRealmSettings sets the OCI SDK environment variables for realms and regions the
+// SDK does not know about, such as dedicated regions, from the configuration. Values
+// from the configuration file are then seen by the SDK (for region validation and
+// endpoints) and by OpenTofu or Terraform. Variables already set in the environment are kept.
+func (c *Config) exportRealmSettings() {
+	for name, value := range map[string]string{
+		"OCI_DEFAULT_REALM":   c.OCIDefaultRealm,
+		"OCI_REGION_METADATA": c.OCIRegionMetadata,
+	} {
+		if _, set := os.LookupEnv(name); set || value == "" {
+			continue
+		}
+		_ = os.Setenv(name, value)
+	}
+}
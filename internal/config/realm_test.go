@@ -0,0 +1,23 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestExportRealmSettings(t *testing.T) {
+	t.Setenv("OCI_DEFAULT_REALM", "oraclecloud.example")
+	t.Setenv("OCI_REGION_METADATA", "")
+	os.Unsetenv("OCI_REGION_METADATA")
+
+	metadata := `{"realmKey":"oc99","realmDomainComponent":"oraclecloud.example","regionKey":"xyz","regionIdentifier":"xx-example-1"}`
+	cfg := &Config{OCIDefaultRealm: "oraclegovcloud.uk", OCIRegionMetadata: metadata}
+	cfg.exportRealmSettings()
+
+	if got := os.Getenv("OCI_DEFAULT_REALM"); got != "oraclecloud.example" {
+		t.Errorf("Expected environment value to be kept, got %q", got)
+	}
+	if got := os.Getenv("OCI_REGION_METADATA"); got != metadata {
+		t.Errorf("OCI_REGION_METADATA = %q, want %q", got, metadata)
+	}
+}
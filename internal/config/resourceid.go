@@ -0,0 +1,60 @@
+// Package config provides parsing of Azure resource IDs given in place of plain names.
+package config
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+)
+
+// virtualMachineResourceType is the ARM resource type of Azure virtual machines.
+var virtualMachineResourceType = arm.NewResourceType("Microsoft.Compute", "virtualMachines")
+
+// IsAzureResourceID reports whether value looks like a full Azure resource ID
+// (e.g. /subscriptions/<id>/resourceGroups/<rg>/providers/...).
+func IsAzureResourceID(value string) bool {
+	return strings.HasPrefix(strings.ToLower(value), "/subscriptions/")
+}
+
+// resolveAzureResourceIDs expands AZURE_COMPUTE_NAME and AZURE_RESOURCE_GROUP when
+// they are given as full resource IDs copied from the Azure portal. The subscription,
+// resource group and VM name are taken from the ID; explicitly configured values
+// that disagree with the ID are reported as errors.
+func (c *Config) resolveAzureResourceIDs() error {
+	if IsAzureResourceID(c.AzureResourceGroup) {
+		id, err := arm.ParseResourceID(c.AzureResourceGroup)
+		if err != nil || !strings.EqualFold(id.ResourceType.String(), arm.ResourceGroupResourceType.String()) {
+			return errors.New(i18n.T("config.invalid_azure_id", "AZURE_RESOURCE_GROUP", c.AzureResourceGroup, arm.ResourceGroupResourceType.String()))
+		}
+		if err := c.setAzureSubscription(id.SubscriptionID); err != nil {
+			return err
+		}
+		c.AzureResourceGroup = id.Name
+	}
+
+	if IsAzureResourceID(c.AzureComputeName) {
+		id, err := arm.ParseResourceID(c.AzureComputeName)
+		if err != nil || !strings.EqualFold(id.ResourceType.String(), virtualMachineResourceType.String()) {
+			return errors.New(i18n.T("config.invalid_azure_id", "AZURE_COMPUTE_NAME", c.AzureComputeName, virtualMachineResourceType.String()))
+		}
+		if err := c.setAzureSubscription(id.SubscriptionID); err != nil {
+			return err
+		}
+		if c.AzureResourceGroup != "" && !strings.EqualFold(c.AzureResourceGroup, id.ResourceGroupName) {
+			return errors.New(i18n.T("config.azure_id_conflict", "AZURE_RESOURCE_GROUP", c.AzureResourceGroup, id.ResourceGroupName))
+		}
+		c.AzureResourceGroup = id.ResourceGroupName
+		c.AzureComputeName = id.Name
+	}
+	return nil
+}
+
+func (c *Config) setAzureSubscription(subscriptionID string) error {
+	if c.AzureSubscriptionID != "" && !strings.EqualFold(c.AzureSubscriptionID, subscriptionID) {
+		return errors.New(i18n.T("config.azure_id_conflict", "AZURE_SUBSCRIPTION_ID", c.AzureSubscriptionID, subscriptionID))
+	}
+	c.AzureSubscriptionID = subscriptionID
+	return nil
+}
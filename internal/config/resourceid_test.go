@@ -0,0 +1,74 @@
+package config
+
+import "testing"
+
+func TestResolveAzureResourceIDs(t *testing.T) {
+	const (
+		sub   = "00000000-0000-0000-0000-000000000001"
+		vmID  = "/subscriptions/" + sub + "/resourceGroups/prod-rg/providers/Microsoft.Compute/virtualMachines/vm1"
+		rgID  = "/subscriptions/" + sub + "/resourceGroups/prod-rg"
+		nicID = "/subscriptions/" + sub + "/resourceGroups/prod-rg/providers/Microsoft.Network/networkInterfaces/nic1"
+	)
+
+	tests := []struct {
+		name        string
+		config      Config
+		expected    Config
+		expectError bool
+	}{
+		{
+			name:     "plain names are unchanged",
+			config:   Config{AzureComputeName: "vm1", AzureResourceGroup: "prod-rg"},
+			expected: Config{AzureComputeName: "vm1", AzureResourceGroup: "prod-rg"},
+		},
+		{
+			name:     "VM resource ID",
+			config:   Config{AzureComputeName: vmID},
+			expected: Config{AzureComputeName: "vm1", AzureResourceGroup: "prod-rg", AzureSubscriptionID: sub},
+		},
+		{
+			name:     "VM resource ID with matching resource group",
+			config:   Config{AzureComputeName: vmID, AzureResourceGroup: "PROD-RG"},
+			expected: Config{AzureComputeName: "vm1", AzureResourceGroup: "prod-rg", AzureSubscriptionID: sub},
+		},
+		{
+			name:     "resource group ID",
+			config:   Config{AzureComputeName: "vm1", AzureResourceGroup: rgID},
+			expected: Config{AzureComputeName: "vm1", AzureResourceGroup: "prod-rg", AzureSubscriptionID: sub},
+		},
+		{
+			name:        "conflicting resource group",
+			config:      Config{AzureComputeName: vmID, AzureResourceGroup: "other-rg"},
+			expectError: true,
+		},
+		{
+			name:        "conflicting subscription",
+			config:      Config{AzureComputeName: vmID, AzureSubscriptionID: "00000000-0000-0000-0000-000000000002"},
+			expectError: true,
+		},
+		{
+			name:        "resource ID of another type",
+			config:      Config{AzureComputeName: nicID},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.config
+			err := cfg.resolveAzureResourceIDs()
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if cfg != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, cfg)
+			}
+		})
+	}
+}
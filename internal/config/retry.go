@@ -0,0 +1,17 @@
+package config
+
+import (
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/retry"
+)
+
+// configureRetries applies the retry settings to the policy shared by the Azure and
+// OCI clients. Each loaded configuration starts a fresh retry budget.
+func (c *Config) configureRetries() {
+	maxDelay := time.Duration(c.RetryMaxDelaySeconds) * time.Second
+	if maxDelay <= 0 {
+		maxDelay = retry.DefaultMaxDelay
+	}
+	retry.Configure(retry.New(c.RetryMaxAttempts, retry.DefaultBaseDelay, maxDelay, c.RetryBudget))
+}
@@ -0,0 +1,243 @@
+// Package config provides the typed configuration schema, schema rendering and validation.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/spf13/viper"
+)
+
+// Supported schema output formats.
+const (
+	SchemaFormatMarkdown = "markdown"
+	SchemaFormatJSON     = "json"
+	SchemaFormatEnv      = "env"
+)
+
+// Field describes a single configuration option of the schema.
+type Field struct {
+	Name        string   `json:"name"`
+	Env         string   `json:"env"`
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Default     string   `json:"default,omitempty"`
+	Required    string   `json:"required,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	OneOf       []string `json:"oneOf,omitempty"`
+	Conflicts   string   `json:"conflicts,omitempty"`
+	Secret      bool     `json:"secret,omitempty"`
+	index       int
+}
+
+// Key returns the Viper key of the field.
+func (f Field) Key() string {
+	return strings.ToLower(f.Env)
+}
+
+// Schema returns the configuration schema derived from the Config struct tags.
+func Schema() []Field {
+	return schemaOf(reflect.TypeOf(Config{}))
+}
+
+func schemaOf(t reflect.Type) []Field {
+	fields := make([]Field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		env := sf.Tag.Get("env")
+		if env == "" {
+			continue
+		}
+		var oneOf []string
+		if v := sf.Tag.Get("oneof"); v != "" {
+			oneOf = strings.Split(v, ",")
+		}
+		fields = append(fields, Field{
+			Name:        sf.Name,
+			Env:         env,
+			Type:        sf.Type.Kind().String(),
+			Description: sf.Tag.Get("desc"),
+			Default:     sf.Tag.Get("default"),
+			Required:    sf.Tag.Get("required"),
+			Format:      sf.Tag.Get("format"),
+			OneOf:       oneOf,
+			Conflicts:   sf.Tag.Get("conflicts"),
+			Secret:      sf.Tag.Get("secret") == "true",
+			index:       i,
+		})
+	}
+	return fields
+}
+
+// loadFields populates the schema fields of cfg from Viper.
+func loadFields(cfg *Config) error {
+	v := reflect.ValueOf(cfg).Elem()
+	for _, field := range Schema() {
+		fv := v.Field(field.index)
+		switch fv.Kind() {
+		case reflect.String:
+			fv.SetString(viper.GetString(field.Key()))
+		case reflect.Bool:
+			fv.SetBool(viper.GetBool(field.Key()))
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			fv.SetInt(viper.GetInt64(field.Key()))
+		default:
+			return fmt.Errorf("unsupported configuration field type %s for %s", fv.Kind(), field.Env)
+		}
+	}
+	return nil
+}
+
+// validateFields checks required conditions, formats, allowed values and conflicts
+// of all schema fields of the given struct pointer.
+func validateFields(target interface{}) error {
+	v := reflect.ValueOf(target).Elem()
+	fields := schemaOf(v.Type())
+	values := make(map[string]reflect.Value, len(fields))
+	for _, f := range fields {
+		values[f.Env] = v.Field(f.index)
+	}
+
+	var errs []error
+	for _, f := range fields {
+		fv := values[f.Env]
+		set := !fv.IsZero()
+		if !set {
+			if f.Required == "always" {
+				errs = append(errs, errors.New(i18n.T("config.required", f.Key())))
+			} else if cond, ok := requiredCondition(f.Required, values); ok && cond {
+				errs = append(errs, errors.New(i18n.T("config.required_when", f.Key(), f.Required)))
+			}
+			continue
+		}
+		if fv.Kind() == reflect.String {
+			// A compartment path left by LoadConfigOffline is not an OCID yet.
+			c, ok := target.(*Config)
+			unresolved := ok && c.compartmentPath && f.Env == "OCI_COMPARTMENT_ID"
+			if err := validateFormat(f, fv.String(), values); err != nil && !unresolved {
+				errs = append(errs, err)
+			}
+			if len(f.OneOf) > 0 && !contains(f.OneOf, fv.String()) {
+				errs = append(errs, errors.New(i18n.T("config.invalid_value", f.Key(), fv.String(), strings.Join(f.OneOf, ", "))))
+			}
+		}
+		if f.Conflicts != "" {
+			if other, ok := values[f.Conflicts]; ok && !other.IsZero() {
+				errs = append(errs, errors.New(i18n.T("config.mutually_exclusive", f.Env, f.Conflicts)))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// requiredCondition evaluates a "KEY=value" condition. ok is false when the condition is not of that form.
+func requiredCondition(required string, values map[string]reflect.Value) (result bool, ok bool) {
+	key, want, found := strings.Cut(required, "=")
+	if !found {
+		return false, false
+	}
+	fv, exists := values[key]
+	if !exists {
+		return false, false
+	}
+	return fmt.Sprint(fv.Interface()) == want, true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderSchema writes the configuration schema in the given format (markdown, json or env).
+func RenderSchema(w io.Writer, format string) error {
+	fields := Schema()
+	switch strings.ToLower(format) {
+	case SchemaFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(fields)
+	case SchemaFormatMarkdown:
+		var b strings.Builder
+		b.WriteString("| Variable | Type | Default | Required | Description |\n")
+		b.WriteString("|----------|------|---------|----------|-------------|\n")
+		for _, f := range fields {
+			desc := f.Description
+			if len(f.OneOf) > 0 {
+				desc += fmt.Sprintf(" (one of: %s)", strings.Join(f.OneOf, ", "))
+			}
+			if f.Format != "" {
+				desc += fmt.Sprintf(" (format: %s)", f.Format)
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n", f.Env, f.Type, f.Default, f.Required, desc)
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	case SchemaFormatEnv:
+		var b strings.Builder
+		for _, f := range fields {
+			fmt.Fprintf(&b, "# %s\n", f.Description)
+			if f.Required != "" {
+				fmt.Fprintf(&b, "# Required: %s\n", f.Required)
+			}
+			if len(f.OneOf) > 0 {
+				fmt.Fprintf(&b, "# Allowed values: %s\n", strings.Join(f.OneOf, ", "))
+			}
+			fmt.Fprintf(&b, "%s=%q\n\n", f.Env, f.Default)
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	default:
+		return fmt.Errorf("unsupported schema format '%s' (supported: %s, %s, %s)", format, SchemaFormatMarkdown, SchemaFormatJSON, SchemaFormatEnv)
+	}
+}
+
+// WriteEnv writes the options of cfg that differ from their defaults as a configuration
+// file, each preceded by its description. Options resolved from a secret store are
+// written as their secret:// reference.
+func (c *Config) WriteEnv(w io.Writer) error {
+	v := reflect.ValueOf(c).Elem()
+	var b strings.Builder
+	for _, f := range Schema() {
+		value := fmt.Sprint(v.Field(f.index).Interface())
+		if ref, ok := c.SecretReference(f.Env); ok {
+			value = ref
+		}
+		if value == f.Default || (f.Default == "" && (value == "" || value == "0" || value == "false")) {
+			continue
+		}
+		fmt.Fprintf(&b, "# %s\n%s=%q\n\n", f.Description, f.Env, value)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// secretMask replaces the values of secret options in the effective configuration.
+const secretMask = "********"
+
+// WriteEffective writes every option of cfg with its effective value, including
+// defaults, with the values of secret options masked. Options resolved from a secret
+// store are written as their secret:// reference.
+func (c *Config) WriteEffective(w io.Writer) error {
+	v := reflect.ValueOf(c).Elem()
+	var b strings.Builder
+	for _, f := range Schema() {
+		value := fmt.Sprint(v.Field(f.index).Interface())
+		if ref, ok := c.SecretReference(f.Env); ok {
+			value = ref
+		} else if f.Secret && value != "" {
+			value = secretMask
+		}
+		fmt.Fprintf(&b, "%s=%q\n", f.Env, value)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
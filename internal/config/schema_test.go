@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestIsValidOCID(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{"Compartment without region", "ocid1.compartment.oc1..aaaaaaaaexample", true},
+		{"Subnet with region", "ocid1.subnet.oc1.iad.aaaaaaaaexample", true},
+		{"Government realm", "ocid1.image.oc2.us-langley-1.aaaaaaaaexample", true},
+		{"Missing prefix", "compartment.oc1..aaaaaaaaexample", false},
+		{"Too few segments", "ocid1.compartment.test", false},
+		{"Uppercase resource type", "ocid1.Compartment.oc1..aaaaaaaaexample", false},
+		{"Empty unique ID", "ocid1.compartment.oc1..", false},
+		{"Empty string", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsValidOCID(tt.input); result != tt.expected {
+				t.Errorf("IsValidOCID(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSchemaCoversConfig(t *testing.T) {
+	fields := Schema()
+	if len(fields) == 0 {
+		t.Fatal("Schema() returned no fields")
+	}
+	for _, f := range fields {
+		if f.Description == "" {
+			t.Errorf("field %s has no description", f.Env)
+		}
+		if f.Env != strings.ToUpper(f.Env) {
+			t.Errorf("field %s env name must be upper case", f.Env)
+		}
+	}
+}
+
+func TestValidateMutuallyExclusive(t *testing.T) {
+	type exclusiveConfig struct {
+		KeyFile string `env:"KEY_FILE" desc:"Key file" conflicts:"KEY_DATA"`
+		KeyData string `env:"KEY_DATA" desc:"Key data"`
+	}
+	if err := validateFields(&exclusiveConfig{KeyFile: "a"}); err != nil {
+		t.Errorf("Expected no error but got: %v", err)
+	}
+	if err := validateFields(&exclusiveConfig{KeyFile: "a", KeyData: "b"}); err == nil {
+		t.Error("Expected error for mutually exclusive options but got nil")
+	}
+}
+
+func TestRenderSchema(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderSchema(&buf, SchemaFormatJSON); err != nil {
+		t.Fatalf("RenderSchema(json) failed: %v", err)
+	}
+	var fields []Field
+	if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+		t.Fatalf("RenderSchema(json) produced invalid JSON: %v", err)
+	}
+	if len(fields) != len(Schema()) {
+		t.Errorf("Expected %d fields, got %d", len(Schema()), len(fields))
+	}
+
+	buf.Reset()
+	if err := RenderSchema(&buf, SchemaFormatMarkdown); err != nil {
+		t.Fatalf("RenderSchema(markdown) failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "| `OCI_REGION` |") {
+		t.Errorf("Markdown schema missing OCI_REGION row:\n%s", buf.String())
+	}
+
+	if err := RenderSchema(&buf, "yaml"); err == nil {
+		t.Error("Expected error for unsupported format but got nil")
+	}
+}
+
+func TestWriteEnv(t *testing.T) {
+	cfg := &Config{SourcePlatform: "azure", TargetPlatform: "oci", AzureResourceGroup: "rg-prod", DataDiskParallelism: 4, VerifyChecksums: true}
+	var buf bytes.Buffer
+	if err := cfg.WriteEnv(&buf); err != nil {
+		t.Fatalf("WriteEnv failed: %v", err)
+	}
+	out := buf.String()
+	for _, expected := range []string{"AZURE_RESOURCE_GROUP=\"rg-prod\"\n", "VERIFY_CHECKSUMS=\"true\"\n", "# Azure resource group"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+	for _, unexpected := range []string{"SOURCE_PLATFORM", "DATA_DISK_PARALLELISM", "DEBUG"} {
+		if strings.Contains(out, unexpected) {
+			t.Errorf("Expected default %s to be omitted, got:\n%s", unexpected, out)
+		}
+	}
+}
+
+func TestWriteEffective(t *testing.T) {
+	cfg := &Config{SourcePlatform: "azure", LUKSPassphrase: "s3cret", CMDBAuthorization: ""}
+	var buf bytes.Buffer
+	if err := cfg.WriteEffective(&buf); err != nil {
+		t.Fatalf("WriteEffective failed: %v", err)
+	}
+	out := buf.String()
+	for _, expected := range []string{"SOURCE_PLATFORM=\"azure\"\n", "LUKS_PASSPHRASE=\"********\"\n", "CMDB_AUTHORIZATION=\"\"\n", "DEBUG=\"false\"\n"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "s3cret") {
+		t.Errorf("Expected the LUKS passphrase to be masked, got:\n%s", out)
+	}
+}
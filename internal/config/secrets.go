@@ -0,0 +1,198 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecretScheme prefixes configuration values that reference a secret in an external
+// store, as secret://<backend>/<reference>, e.g. secret://env/AZURE_CLIENT_SECRET or
+// secret://oci-vault/ocid1.vaultsecret.oc1.iad.example.
+const SecretScheme = "secret://"
+
+// secretTimeout bounds the resolution of all secret references of a configuration.
+const secretTimeout = 2 * time.Minute
+
+// SecretResolver resolves references to secrets in an external store. cfg holds the
+// configuration loaded so far; references not resolved yet are left as they are.
+type SecretResolver interface {
+	Resolve(ctx context.Context, cfg *Config, reference string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to a SecretResolver.
+type SecretResolverFunc func(ctx context.Context, cfg *Config, reference string) (string, error)
+
+// Resolve calls f.
+func (f SecretResolverFunc) Resolve(ctx context.Context, cfg *Config, reference string) (string, error) {
+	return f(ctx, cfg, reference)
+}
+
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = map[string]SecretResolver{
+		"env":             SecretResolverFunc(resolveEnvSecret),
+		"hashicorp-vault": SecretResolverFunc(resolveHashiCorpVaultSecret),
+	}
+)
+
+// RegisterSecretResolver makes resolver available for secret://<backend>/ references,
+// replacing the resolver of the backend, if any.
+func RegisterSecretResolver(backend string, resolver SecretResolver) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	secretResolvers[backend] = resolver
+}
+
+// SecretBackends returns the names of the registered secret backends.
+func SecretBackends() []string {
+	secretResolversMu.RLock()
+	defer secretResolversMu.RUnlock()
+	backends := make([]string, 0, len(secretResolvers))
+	for backend := range secretResolvers {
+		backends = append(backends, backend)
+	}
+	sort.Strings(backends)
+	return backends
+}
+
+// parseSecretReference splits a secret:// value into its backend and reference.
+func parseSecretReference(value string) (backend, reference string, err error) {
+	backend, reference, ok := strings.Cut(strings.TrimPrefix(value, SecretScheme), "/")
+	if !ok || backend == "" || reference == "" {
+		return "", "", fmt.Errorf("invalid secret reference '%s' (expected %s<backend>/<reference>)", value, SecretScheme)
+	}
+	return backend, reference, nil
+}
+
+// resolveSecrets replaces the secret:// values of string options with the secrets they
+// reference, in schema order, and records the references so that they, rather than the
+// secrets, are written back by WriteEnv and WriteEffective.
+func (c *Config) resolveSecrets() error {
+	ctx, cancel := context.WithTimeout(context.Background(), secretTimeout)
+	defer cancel()
+	v := reflect.ValueOf(c).Elem()
+	for _, f := range Schema() {
+		fv := v.Field(f.index)
+		if fv.Kind() != reflect.String || !strings.HasPrefix(fv.String(), SecretScheme) {
+			continue
+		}
+		value := fv.String()
+		backend, reference, err := parseSecretReference(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Env, err)
+		}
+		secretResolversMu.RLock()
+		resolver, ok := secretResolvers[backend]
+		secretResolversMu.RUnlock()
+		if !ok {
+			return fmt.Errorf("%s: unknown secret backend '%s' (supported: %s)", f.Env, backend, strings.Join(SecretBackends(), ", "))
+		}
+		secret, err := resolver.Resolve(ctx, c, reference)
+		if err != nil {
+			return fmt.Errorf("%s: failed to resolve %s: %w", f.Env, value, err)
+		}
+		fv.SetString(strings.TrimRight(secret, "\r\n"))
+		if c.secretRefs == nil {
+			c.secretRefs = &map[string]string{}
+		}
+		(*c.secretRefs)[f.Env] = value
+	}
+	return nil
+}
+
+// SecretReference returns the secret:// reference the option env was resolved from.
+func (c *Config) SecretReference(env string) (string, bool) {
+	if c.secretRefs == nil {
+		return "", false
+	}
+	ref, ok := (*c.secretRefs)[env]
+	return ref, ok
+}
+
+// resolveEnvSecret reads the secret from the environment variable named by reference,
+// so that a configuration file can name a variable set by a CI system or wrapper.
+func resolveEnvSecret(_ context.Context, _ *Config, reference string) (string, error) {
+	value, ok := os.LookupEnv(reference)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", reference)
+	}
+	return value, nil
+}
+
+// resolveHashiCorpVaultSecret reads a field of a HashiCorp Vault KV secret, referenced
+// as <mount>/<path>#<field>, from VAULT_ADDR with VAULT_TOKEN (and VAULT_NAMESPACE, if
+// set). KV version 2 is tried first, then version 1.
+func resolveHashiCorpVaultSecret(ctx context.Context, _ *Config, reference string) (string, error) {
+	addr, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set")
+	}
+	path, field, ok := strings.Cut(reference, "#")
+	mount, secretPath, hasPath := strings.Cut(path, "/")
+	if !ok || field == "" || !hasPath || secretPath == "" {
+		return "", fmt.Errorf("invalid HashiCorp Vault reference '%s' (expected <mount>/<path>#<field>)", reference)
+	}
+	base := strings.TrimSuffix(addr, "/") + "/v1/" + mount
+	var v2 struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	found, err := vaultGet(ctx, base+"/data/"+secretPath, token, &v2)
+	if err != nil {
+		return "", err
+	}
+	data := v2.Data.Data
+	if !found || data == nil {
+		var v1 struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if found, err = vaultGet(ctx, base+"/"+secretPath, token, &v1); err != nil {
+			return "", err
+		}
+		if !found {
+			return "", fmt.Errorf("secret %s not found", path)
+		}
+		data = v1.Data
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no field %s", path, field)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// vaultGet reads a HashiCorp Vault API path into out; found is false for a 404.
+func vaultGet(ctx context.Context, url, token string, out interface{}) (found bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from HashiCorp Vault: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("HashiCorp Vault returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode HashiCorp Vault response: %w", err)
+	}
+	return true, nil
+}
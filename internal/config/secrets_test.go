@@ -0,0 +1,91 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("KOPRU_TEST_CLIENT_SECRET", "s3cr3t\n")
+	RegisterSecretResolver("test", SecretResolverFunc(func(_ context.Context, cfg *Config, reference string) (string, error) {
+		return cfg.AzureResourceGroup + "/" + reference, nil
+	}))
+	cfg := &Config{
+		AzureResourceGroup: "rg-prod",
+		AzureClientSecret:  "secret://env/KOPRU_TEST_CLIENT_SECRET",
+		OCISSHPublicKey:    "secret://test/ssh-key",
+	}
+	if err := cfg.resolveSecrets(); err != nil {
+		t.Fatalf("resolveSecrets() error = %v", err)
+	}
+	if cfg.AzureClientSecret != "s3cr3t" || cfg.OCISSHPublicKey != "rg-prod/ssh-key" {
+		t.Errorf("resolveSecrets() resolved %q and %q", cfg.AzureClientSecret, cfg.OCISSHPublicKey)
+	}
+	if ref, ok := cfg.SecretReference("AZURE_CLIENT_SECRET"); !ok || ref != "secret://env/KOPRU_TEST_CLIENT_SECRET" {
+		t.Errorf("SecretReference() = %q, %v", ref, ok)
+	}
+	var b bytes.Buffer
+	if err := cfg.WriteEffective(&b); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "s3cr3t") || !strings.Contains(b.String(), `OCI_SSH_PUBLIC_KEY="secret://test/ssh-key"`) {
+		t.Errorf("WriteEffective() wrote resolved secrets:\n%s", b.String())
+	}
+
+	for value, want := range map[string]string{
+		"secret://env/KOPRU_TEST_UNSET": "KOPRU_TEST_UNSET is not set",
+		"secret://unknown/name":         "unknown secret backend 'unknown'",
+		"secret://env":                  "invalid secret reference",
+	} {
+		cfg := &Config{AzureClientSecret: value}
+		if err := cfg.resolveSecrets(); err == nil || !strings.Contains(err.Error(), want) || !strings.HasPrefix(err.Error(), "AZURE_CLIENT_SECRET: ") {
+			t.Errorf("resolveSecrets(%s) error = %v, want %q", value, err, want)
+		}
+	}
+}
+
+func TestResolveHashiCorpVaultSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/kopru/azure":
+			_, _ = w.Write([]byte(`{"data": {"data": {"client_secret": "from-kv2"}}}`))
+		case "/v1/kv1/kopru/cmdb":
+			_, _ = w.Write([]byte(`{"data": {"token": "from-kv1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "token")
+
+	tests := []struct {
+		reference, want, wantErr string
+	}{
+		{"secret/kopru/azure#client_secret", "from-kv2", ""},
+		{"kv1/kopru/cmdb#token", "from-kv1", ""},
+		{"secret/kopru/azure#missing", "", "has no field missing"},
+		{"secret/kopru/other#key", "", "not found"},
+		{"secret/kopru/azure", "", "invalid HashiCorp Vault reference"},
+	}
+	for _, tt := range tests {
+		got, err := resolveHashiCorpVaultSecret(context.Background(), nil, tt.reference)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolveHashiCorpVaultSecret(%s) error = %v, want %q", tt.reference, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("resolveHashiCorpVaultSecret(%s) = %q, %v, want %q", tt.reference, got, err, tt.want)
+		}
+	}
+}
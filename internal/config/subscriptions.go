@@ -0,0 +1,15 @@
+package config
+
+import "errors"
+
+// validateSubscriptionReregister checks that SUBSCRIPTION_REREGISTER has the credentials
+// of at least one subscription service, and both values of Red Hat activation keys.
+func (c *Config) validateSubscriptionReregister() error {
+	if (c.RHSMOrgID == "") != (c.RHSMActivationKey == "") {
+		return errors.New("RHSM_ORG_ID and RHSM_ACTIVATION_KEY must be set together")
+	}
+	if c.SubscriptionReregister && c.RHSMOrgID == "" && c.SUSERegistrationCode == "" {
+		return errors.New("SUBSCRIPTION_REREGISTER requires RHSM_ORG_ID and RHSM_ACTIVATION_KEY, or SUSE_REGISTRATION_CODE")
+	}
+	return nil
+}
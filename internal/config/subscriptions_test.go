@@ -0,0 +1,22 @@
+package config
+
+import "testing"
+
+func TestValidateSubscriptionReregister(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"disabled", Config{}, false},
+		{"rhsm", Config{SubscriptionReregister: true, RHSMOrgID: "1234", RHSMActivationKey: "key"}, false},
+		{"suse", Config{SubscriptionReregister: true, SUSERegistrationCode: "code"}, false},
+		{"no credentials", Config{SubscriptionReregister: true}, true},
+		{"org without key", Config{RHSMOrgID: "1234"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.validateSubscriptionReregister(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validateSubscriptionReregister() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SourceTagMap returns the OCI defined tags of SOURCE_TAG_MAP_FILE by Azure tag key, in
+// lowercase as Azure tag keys are case-insensitive. Each line of the file maps an Azure
+// tag key to the namespace.key of a defined tag: <Azure tag>=<namespace>.<key>.
+func (c *Config) SourceTagMap() (map[string]string, error) {
+	if c.SourceTagMapFile == "" {
+		return nil, nil
+	}
+	entries, err := readMapFile("SOURCE_TAG_MAP_FILE", c.SourceTagMapFile, "<Azure tag>=<namespace>.<key>")
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string, len(entries))
+	for key, value := range entries {
+		tags[strings.ToLower(key)] = value
+	}
+	return tags, nil
+}
+
+// validateSourceTagMap checks that SOURCE_TAG_MAP_FILE maps Azure tags to defined tags,
+// and is only set when the source tags are preserved.
+func (c *Config) validateSourceTagMap() error {
+	if c.SourceTagMapFile != "" && !c.PreserveSourceTags {
+		return errors.New("SOURCE_TAG_MAP_FILE requires PRESERVE_SOURCE_TAGS=true")
+	}
+	tags, err := c.SourceTagMap()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for key, definedTag := range tags {
+		if !definedTagKeyPattern.MatchString(definedTag) {
+			errs = append(errs, fmt.Errorf("SOURCE_TAG_MAP_FILE: invalid defined tag '%s' of Azure tag '%s', expected namespace.key", definedTag, key))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceTagMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tags.map")
+	content := `# Azure tag = namespace.key
+CostCenter = Operations.CostCenter
+owner=Operations.Owner
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tags, err := (&Config{SourceTagMapFile: path}).SourceTagMap()
+	if err != nil {
+		t.Fatalf("SourceTagMap() error = %v", err)
+	}
+	if len(tags) != 2 || tags["costcenter"] != "Operations.CostCenter" || tags["owner"] != "Operations.Owner" {
+		t.Errorf("SourceTagMap() = %v", tags)
+	}
+}
+
+func TestValidateSourceTagMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		preserve bool
+		wantErr  bool
+	}{
+		{"valid", "CostCenter=Operations.CostCenter\n", true, false},
+		{"not a defined tag", "CostCenter=CostCenter\n", true, true},
+		{"missing defined tag", "CostCenter\n", true, true},
+		{"tags not preserved", "CostCenter=Operations.CostCenter\n", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tags.map")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			cfg := &Config{SourceTagMapFile: path, PreserveSourceTags: tt.preserve}
+			if err := cfg.validateSourceTagMap(); (err != nil) != tt.wantErr {
+				t.Errorf("validateSourceTagMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
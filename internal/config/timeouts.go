@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StepTimeoutList parses STEP_TIMEOUTS, a comma-separated list of <step>=<duration>
+// pairs such as export=4h,deploy=30m, into the timeout of each step. Malformed entries
+// are reported by validateStepTimeouts.
+func (c *Config) StepTimeoutList() map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, entry := range strings.Split(c.StepTimeouts, ",") {
+		step, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		if timeout, err := time.ParseDuration(strings.TrimSpace(value)); err == nil && timeout > 0 {
+			timeouts[strings.TrimSpace(step)] = timeout
+		}
+	}
+	return timeouts
+}
+
+// validateStepTimeouts checks that STEP_TIMEOUTS entries are <step>=<duration> with a
+// positive duration. Step names are checked against the steps of the workflow when it
+// is created.
+func (c *Config) validateStepTimeouts() error {
+	var errs []error
+	for _, entry := range strings.Split(c.StepTimeouts, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		step, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(step) == "" {
+			errs = append(errs, fmt.Errorf("STEP_TIMEOUTS entries must be <step>=<duration>: '%s'", entry))
+			continue
+		}
+		if timeout, err := time.ParseDuration(strings.TrimSpace(value)); err != nil || timeout <= 0 {
+			errs = append(errs, fmt.Errorf("STEP_TIMEOUTS for %s must be a positive duration such as 30m or 4h: '%s'", strings.TrimSpace(step), strings.TrimSpace(value)))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStepTimeoutList(t *testing.T) {
+	cfg := Config{StepTimeouts: "export=4h, upload = 6h,deploy=30m"}
+	timeouts := cfg.StepTimeoutList()
+	want := map[string]time.Duration{"export": 4 * time.Hour, "upload": 6 * time.Hour, "deploy": 30 * time.Minute}
+	if len(timeouts) != len(want) {
+		t.Fatalf("StepTimeoutList() = %v, want %v", timeouts, want)
+	}
+	for step, timeout := range want {
+		if timeouts[step] != timeout {
+			t.Errorf("Timeout of %s = %s, want %s", step, timeouts[step], timeout)
+		}
+	}
+}
+
+func TestValidateStepTimeouts(t *testing.T) {
+	tests := []struct {
+		value       string
+		expectError bool
+	}{
+		{"", false},
+		{"export=4h,upload=6h,deploy=30m", false},
+		{"export", true},
+		{"=4h", true},
+		{"deploy=soon", true},
+		{"deploy=-30m", true},
+	}
+	for _, tt := range tests {
+		err := (&Config{StepTimeouts: tt.value}).validateStepTimeouts()
+		if (err != nil) != tt.expectError {
+			t.Errorf("validateStepTimeouts(%q) error = %v, expectError %v", tt.value, err, tt.expectError)
+		}
+	}
+}
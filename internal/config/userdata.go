@@ -0,0 +1,41 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+// maxUserDataBytes is the size limit of the instance metadata, which holds the
+// base64-encoded user data.
+const maxUserDataBytes = 32000
+
+// UserData returns the cloud-init user data of CLOUD_INIT_USER_DATA, or the content of
+// CLOUD_INIT_FILE when only the file is configured, base64-encoded for the user_data key
+// of the instance metadata. It returns an empty string when neither is set.
+func (c *Config) UserData() (string, error) {
+	data := []byte(c.CloudInitUserData)
+	if c.CloudInitUserData == "" {
+		if c.CloudInitFile == "" {
+			return "", nil
+		}
+		var err error
+		if data, err = os.ReadFile(c.CloudInitFile); err != nil {
+			return "", fmt.Errorf("failed to read cloud-init file %s: %w", c.CloudInitFile, err)
+		}
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// validateUserData checks that the cloud-init user data can be read and fits the
+// instance metadata.
+func (c *Config) validateUserData() error {
+	userData, err := c.UserData()
+	if err != nil {
+		return err
+	}
+	if len(userData) > maxUserDataBytes {
+		return fmt.Errorf("cloud-init user data is %d bytes when base64-encoded, the instance metadata allows %d", len(userData), maxUserDataBytes)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUserData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cloud-init.yaml")
+	const content = "#cloud-config\nruncmd:\n  - [touch, /etc/migrated]\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"not set", Config{}, ""},
+		{"file", Config{CloudInitFile: path}, content},
+		{"inline takes precedence", Config{CloudInitFile: path, CloudInitUserData: "#!/bin/sh\necho ok\n"}, "#!/bin/sh\necho ok\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.UserData()
+			if err != nil {
+				t.Fatalf("UserData() error = %v", err)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(got)
+			if err != nil {
+				t.Fatalf("UserData() is not base64: %v", err)
+			}
+			if string(decoded) != tt.want {
+				t.Errorf("UserData() decodes to %q, want %q", decoded, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUserData(t *testing.T) {
+	if err := (&Config{CloudInitFile: filepath.Join(t.TempDir(), "missing")}).validateUserData(); err == nil {
+		t.Error("validateUserData() with a missing file succeeded")
+	}
+	if err := (&Config{CloudInitUserData: strings.Repeat("x", maxUserDataBytes)}).validateUserData(); err == nil {
+		t.Error("validateUserData() with user data larger than the instance metadata succeeded")
+	}
+	if err := (&Config{CloudInitUserData: "#cloud-config\n"}).validateUserData(); err != nil {
+		t.Errorf("validateUserData() error = %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package config provides validators for OCI region identifiers and OCIDs.
+package config
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	ocicommon "github.com/oracle/oci-go-sdk/v65/common"
+)
+
+// regionEnv is the configuration key holding the target OCI region. OCIDs are
+// checked against the realm of this region to catch values from another realm.
+const regionEnv = "OCI_REGION"
+
+// validateFormat checks value against the format declared in the field's schema.
+// OCID formats may restrict the resource type, e.g. "ocid:compartment|tenancy".
+func validateFormat(f Field, value string, values map[string]reflect.Value) error {
+	format, arg, _ := strings.Cut(f.Format, ":")
+	switch format {
+	case "ocid":
+		if !IsValidOCID(value) {
+			return errors.New(i18n.T("config.invalid_ocid", f.Key(), value))
+		}
+		if arg != "" {
+			allowed := strings.Split(arg, "|")
+			if resourceType := OCIDResourceType(value); !contains(allowed, resourceType) {
+				return errors.New(i18n.T("config.wrong_ocid_type", f.Key(), resourceType, strings.Join(allowed, " or ")))
+			}
+		}
+		if region, ok := values[regionEnv]; ok && region.String() != "" && IsKnownRegion(region.String()) {
+			realm, _ := ocicommon.StringToRegion(region.String()).RealmID()
+			if ocidRealm := OCIDRealm(value); ocidRealm != realm {
+				return errors.New(i18n.T("config.wrong_ocid_realm", f.Key(), ocidRealm, region.String(), realm))
+			}
+		}
+	case "region":
+		if !IsKnownRegion(value) {
+			return errors.New(i18n.T("config.invalid_region", f.Key(), value))
+		}
+	case "url":
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return errors.New(i18n.T("config.invalid_url", f.Key(), value))
+		}
+	}
+	return nil
+}
+
+// IsValidOCID reports whether value has the syntax of an OCID:
+// ocid1.<resource type>.<realm>.[region][.future use].<unique id>
+func IsValidOCID(value string) bool {
+	parts := strings.Split(value, ".")
+	if len(parts) < 5 || parts[0] != "ocid1" {
+		return false
+	}
+	for _, part := range []string{parts[1], parts[2], parts[len(parts)-1]} {
+		if part == "" || strings.Trim(part, "abcdefghijklmnopqrstuvwxyz0123456789") != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// OCIDResourceType returns the resource type segment of an OCID (e.g. "compartment"),
+// or an empty string if value is not a valid OCID.
+func OCIDResourceType(value string) string {
+	if !IsValidOCID(value) {
+		return ""
+	}
+	return strings.Split(value, ".")[1]
+}
+
+// OCIDRealm returns the realm segment of an OCID (e.g. "oc1"), or an empty string
+// if value is not a valid OCID.
+func OCIDRealm(value string) string {
+	if !IsValidOCID(value) {
+		return ""
+	}
+	return strings.Split(value, ".")[2]
+}
+
+// IsKnownRegion reports whether region is a region identifier or short code known
+// to the OCI SDK, including regions added through the SDK's region metadata
+// configuration (OCI_REGION_METADATA or ~/.oci/regions-config.json).
+func IsKnownRegion(region string) bool {
+	if region == "" || strings.ContainsAny(region, " \t") {
+		return false
+	}
+	_, err := ocicommon.StringToRegion(region).RealmID()
+	return err == nil
+}
+
+// CanonicalRegion returns the full region identifier for a region name or short code
+// (e.g. "iad" becomes "us-ashburn-1"). Unknown regions are returned unchanged.
+func CanonicalRegion(region string) string {
+	if !IsKnownRegion(region) {
+		return region
+	}
+	return string(ocicommon.StringToRegion(region))
+}
@@ -0,0 +1,50 @@
+package config
+
+import "testing"
+
+func TestOCIDResourceType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"ocid1.compartment.oc1..aaaaaaaaexample", "compartment"},
+		{"ocid1.subnet.oc1.iad.aaaaaaaaexample", "subnet"},
+		{"ocid1.image.oc1.phx.aaaaaaaaexample", "image"},
+		{"not-an-ocid", ""},
+	}
+
+	for _, tt := range tests {
+		if result := OCIDResourceType(tt.input); result != tt.expected {
+			t.Errorf("OCIDResourceType(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestIsKnownRegion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"us-ashburn-1", true},
+		{"eu-frankfurt-1", true},
+		{"iad", true},
+		{"xx-nowhere-9", false},
+		{"Ashburn", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if result := IsKnownRegion(tt.input); result != tt.expected {
+			t.Errorf("IsKnownRegion(%q) = %v, want %v", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestCanonicalRegion(t *testing.T) {
+	if got := CanonicalRegion("iad"); got != "us-ashburn-1" {
+		t.Errorf("CanonicalRegion(\"iad\") = %q, want \"us-ashburn-1\"", got)
+	}
+	if got := CanonicalRegion("xx-nowhere-9"); got != "xx-nowhere-9" {
+		t.Errorf("CanonicalRegion(\"xx-nowhere-9\") = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Block volume performance limits in VPUs per GB. 10 is the Balanced tier, 20 Higher
+// Performance and 30 to 120 Ultra High Performance; 0 (Lower Cost) is not available
+// for boot volumes.
+const (
+	DefaultVolumeVPUsPerGB = 10
+	MaxVolumeVPUsPerGB     = 120
+	volumeVPUsIncrement    = 10
+)
+
+// OracleBackupPolicies are the names of the volume backup policies defined by Oracle.
+var OracleBackupPolicies = []string{"gold", "silver", "bronze"}
+
+// BootVolumeVPUsPerGB returns the performance of the boot volume, Balanced unless
+// OCI_BOOT_VOLUME_VPUS_PER_GB is set.
+func (c *Config) BootVolumeVPUsPerGB() int {
+	if c.OCIBootVolumeVPUsPerGB == 0 {
+		return DefaultVolumeVPUsPerGB
+	}
+	return c.OCIBootVolumeVPUsPerGB
+}
+
+// DataVolumeVPUsPerGB returns the performance of the block volume restored from the
+// Azure data disk diskName: its OCI_DATA_VOLUME_VPUS override, or OCI_DATA_VOLUME_VPUS_PER_GB.
+func (c *Config) DataVolumeVPUsPerGB(diskName string) int {
+	if vpus, ok := c.dataVolumeVPUsOverrides()[diskName]; ok {
+		return vpus
+	}
+	return c.OCIDataVolumeVPUsPerGB
+}
+
+// dataVolumeVPUsOverrides parses OCI_DATA_VOLUME_VPUS, a comma-separated list of
+// <disk-name>=<vpus> pairs. Malformed entries are reported by validateVolumePerformance.
+func (c *Config) dataVolumeVPUsOverrides() map[string]int {
+	overrides := make(map[string]int)
+	for _, entry := range strings.Split(c.OCIDataVolumeVPUs, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		if vpus, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			overrides[strings.TrimSpace(name)] = vpus
+		}
+	}
+	return overrides
+}
+
+// validateVolumePerformance checks that volume performance settings are multiples of 10
+// VPUs per GB within the limits of OCI block and boot volumes.
+func (c *Config) validateVolumePerformance() error {
+	var errs []error
+	check := func(name string, vpus, minVPUs int) {
+		if vpus < minVPUs || vpus > MaxVolumeVPUsPerGB || vpus%volumeVPUsIncrement != 0 {
+			errs = append(errs, fmt.Errorf("%s must be a multiple of %d between %d and %d VPUs per GB: %d", name, volumeVPUsIncrement, minVPUs, MaxVolumeVPUsPerGB, vpus))
+		}
+	}
+	check("OCI_BOOT_VOLUME_VPUS_PER_GB", c.BootVolumeVPUsPerGB(), volumeVPUsIncrement)
+	check("OCI_DATA_VOLUME_VPUS_PER_GB", c.OCIDataVolumeVPUsPerGB, 0)
+	checkAutotune := func(name string, vpus int) {
+		if c.OCIVolumeAutotuneMaxVPUs != 0 && c.OCIVolumeAutotuneMaxVPUs < vpus {
+			errs = append(errs, fmt.Errorf("OCI_VOLUME_AUTOTUNE_MAX_VPUS_PER_GB must be 0 or at least %s (%d)", name, vpus))
+		}
+	}
+	checkAutotune("OCI_DATA_VOLUME_VPUS_PER_GB", c.OCIDataVolumeVPUsPerGB)
+	check("OCI_VOLUME_AUTOTUNE_MAX_VPUS_PER_GB", c.OCIVolumeAutotuneMaxVPUs, 0)
+	for _, entry := range strings.Split(c.OCIDataVolumeVPUs, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		vpus, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || strings.TrimSpace(name) == "" || err != nil {
+			errs = append(errs, fmt.Errorf("OCI_DATA_VOLUME_VPUS entries must be <disk-name>=<vpus>: '%s'", entry))
+			continue
+		}
+		check("OCI_DATA_VOLUME_VPUS for "+strings.TrimSpace(name), vpus, 0)
+		checkAutotune("OCI_DATA_VOLUME_VPUS for "+strings.TrimSpace(name), vpus)
+	}
+	return errors.Join(errs...)
+}
+
+// validateBackupPolicies checks that the backup policies name an Oracle-defined policy
+// or are the OCID of a volume backup policy.
+func (c *Config) validateBackupPolicies() error {
+	var errs []error
+	for _, policy := range []struct{ name, value string }{
+		{"OCI_BOOT_VOLUME_BACKUP_POLICY", c.OCIBootVolumeBackupPolicy},
+		{"OCI_DATA_VOLUME_BACKUP_POLICY", c.OCIDataVolumeBackupPolicy},
+	} {
+		if policy.value == "" || slices.Contains(OracleBackupPolicies, policy.value) || OCIDResourceType(policy.value) == "volumebackuppolicy" {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s must be %s or the OCID of a volume backup policy: '%s'", policy.name, strings.Join(OracleBackupPolicies, ", "), policy.value))
+	}
+	return errors.Join(errs...)
+}
+
+// validateDataVolumeAD checks that OCI_DATA_VOLUME_AVAILABILITY_DOMAIN is an availability
+// domain number.
+func (c *Config) validateDataVolumeAD() error {
+	if c.OCIDataVolumeAD == "" {
+		return nil
+	}
+	if n, err := strconv.Atoi(c.OCIDataVolumeAD); err != nil || n < 1 {
+		return fmt.Errorf("OCI_DATA_VOLUME_AVAILABILITY_DOMAIN must be an availability domain number (1, 2, 3): '%s'", c.OCIDataVolumeAD)
+	}
+	return nil
+}
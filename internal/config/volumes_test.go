@@ -0,0 +1,81 @@
+package config
+
+import "testing"
+
+func TestDataVolumeVPUsPerGB(t *testing.T) {
+	cfg := &Config{OCIDataVolumeVPUsPerGB: 10, OCIDataVolumeVPUs: "vm-data-1=30, vm-data-2 = 0"}
+	tests := []struct {
+		disk     string
+		expected int
+	}{
+		{"vm-data-1", 30},
+		{"vm-data-2", 0},
+		{"vm-data-3", 10},
+	}
+	for _, tt := range tests {
+		if got := cfg.DataVolumeVPUsPerGB(tt.disk); got != tt.expected {
+			t.Errorf("DataVolumeVPUsPerGB(%q) = %d, expected %d", tt.disk, got, tt.expected)
+		}
+	}
+}
+
+func TestValidateVolumePerformance(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		expectError bool
+	}{
+		{"defaults", Config{OCIBootVolumeVPUsPerGB: 10, OCIDataVolumeVPUsPerGB: 10, OCIVolumeAutotuneMaxVPUs: 120}, false},
+		{"unset boot volume uses balanced", Config{}, false},
+		{"ultra high performance", Config{OCIBootVolumeVPUsPerGB: 120, OCIDataVolumeVPUsPerGB: 30, OCIDataVolumeVPUs: "db=120"}, false},
+		{"not a multiple of 10", Config{OCIDataVolumeVPUsPerGB: 15}, true},
+		{"above maximum", Config{OCIBootVolumeVPUsPerGB: 130}, true},
+		{"malformed override", Config{OCIDataVolumeVPUs: "db"}, true},
+		{"auto-tune below override", Config{OCIDataVolumeVPUs: "db=40", OCIVolumeAutotuneMaxVPUs: 30}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validateVolumePerformance()
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateBackupPolicies(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         Config
+		expectError bool
+	}{
+		{"no backups", Config{}, false},
+		{"oracle-defined policies", Config{OCIBootVolumeBackupPolicy: "gold", OCIDataVolumeBackupPolicy: "bronze"}, false},
+		{"policy OCID", Config{OCIDataVolumeBackupPolicy: "ocid1.volumebackuppolicy.oc1.iad.aaaaexample"}, false},
+		{"unknown policy name", Config{OCIBootVolumeBackupPolicy: "platinum"}, true},
+		{"OCID of another resource", Config{OCIDataVolumeBackupPolicy: "ocid1.volume.oc1.iad.aaaaexample"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validateBackupPolicies()
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateDataVolumeAD(t *testing.T) {
+	for value, expectError := range map[string]bool{"": false, "2": false, "0": true, "AD-2": true} {
+		err := (&Config{OCIDataVolumeAD: value}).validateDataVolumeAD()
+		if (err != nil) != expectError {
+			t.Errorf("validateDataVolumeAD(%q) error = %v, expectError %v", value, err, expectError)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Package i18n provides a message catalog for user-facing log lines and errors.
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language used when no language is configured or a message is missing.
+const DefaultLanguage = "en"
+
+var catalogs = map[string]map[string]string{
+	"en": messagesEN,
+	"es": messagesES,
+}
+
+var (
+	mu      sync.RWMutex
+	current = DefaultLanguage
+)
+
+// SetLanguage selects the language used by T. Locale strings such as "es_ES.UTF-8"
+// are normalized to their language code. Unsupported languages fall back to English.
+func SetLanguage(lang string) error {
+	code := normalize(lang)
+	if code == "" {
+		code = DefaultLanguage
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := catalogs[code]; !ok {
+		current = DefaultLanguage
+		return fmt.Errorf("unsupported language '%s' (supported: %s)", lang, strings.Join(Supported(), ", "))
+	}
+	current = code
+	return nil
+}
+
+// Language returns the currently selected language code.
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Supported returns the sorted list of supported language codes.
+func Supported() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// T returns the message for key in the current language, formatted with args.
+// Missing translations fall back to English, and unknown keys are returned as-is.
+func T(key string, args ...interface{}) string {
+	msg, ok := catalogs[Language()][key]
+	if !ok {
+		if msg, ok = messagesEN[key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// normalize converts a locale string (e.g. "es_ES.UTF-8") into a language code (e.g. "es").
+func normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, "_-."); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
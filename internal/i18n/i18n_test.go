@@ -0,0 +1,86 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestCatalogsHaveNoUnknownKeys(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for key := range catalog {
+			if _, ok := messagesEN[key]; !ok {
+				t.Errorf("catalog %q has key %q that is missing from the English catalog", lang, key)
+			}
+		}
+	}
+}
+
+// formatVerb matches the verbs of a message, with their argument index, flags and width.
+var formatVerb = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z]`)
+
+func TestCatalogsHaveMatchingVerbs(t *testing.T) {
+	verbs := func(message string) []string {
+		v := formatVerb.FindAllString(message, -1)
+		slices.Sort(v)
+		return v
+	}
+	for lang, catalog := range catalogs {
+		for key, message := range catalog {
+			if got, want := verbs(message), verbs(messagesEN[key]); !slices.Equal(got, want) {
+				t.Errorf("catalog %q key %q has verbs %v, want %v as in the English catalog", lang, key, got, want)
+			}
+		}
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{"English", "en", "en", false},
+		{"Spanish locale", "es_ES.UTF-8", "es", false},
+		{"Empty uses default", "", "en", false},
+		{"Unsupported falls back to English", "xx", "en", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetLanguage(tt.input)
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+			if Language() != tt.expected {
+				t.Errorf("Language() = %q, want %q", Language(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	if got := T("step.convert_disk"); got != "Converting VHD to QCOW2" {
+		t.Errorf("T(step.convert_disk) = %q", got)
+	}
+	if got := T("workflow.executing", "Azure to OCI Migration"); got != "Executing: Azure to OCI Migration" {
+		t.Errorf("T(workflow.executing) = %q", got)
+	}
+	if got := T("unknown.key"); got != "unknown.key" {
+		t.Errorf("T(unknown.key) = %q, want key returned as-is", got)
+	}
+	if err := SetLanguage("es"); err != nil {
+		t.Fatalf("SetLanguage(es) failed: %v", err)
+	}
+	if got := T("step.convert_disk"); got != "Convirtiendo VHD a QCOW2" {
+		t.Errorf("T(step.convert_disk) in Spanish = %q", got)
+	}
+}
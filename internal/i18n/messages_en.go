@@ -0,0 +1,115 @@
+package i18n
+
+// messagesEN is the English message catalog. Every key must be present here.
+var messagesEN = map[string]string{
+	// Workflow banners
+	"workflow.header":             "Kopru - Compute Migration Tool v%s",
+	"workflow.source_platform":    "Source Platform: %s",
+	"workflow.target_platform":    "Target Platform: %s",
+	"workflow.executing":          "Executing: %s",
+	"workflow.failed":             "Workflow failed: %v",
+	"workflow.azure_completed":    "Azure to OCI migration completed successfully!",
+	"workflow.linux_completed":    "Linux Image to OCI deployment completed successfully!",
+	"workflow.prereq_passed":      "Prerequisite checks passed",
+	"workflow.verify_complete":    "Workflow verification complete",
+	"workflow.next_steps":         "Next Steps:",
+	"workflow.next_start":         "%d. Finish network and DNS work, then start the stopped instance: kopru start",
+	"workflow.next_check_console": "%d. Check the OCI console for the deployed instance",
+	"workflow.next_verify":        "%d. Verify the instance is running as expected",
+	"workflow.next_navigate":      "%d. Navigate to: %s",
+	"workflow.next_run_iac":       "%d. Run: %[2]s init && %[2]s apply",
+
+	// Step titles
+	"step.review_migration":  "Reviewing Migration Configuration",
+	"step.review_deployment": "Reviewing Deployment Configuration",
+	"step.prerequisites":     "Running Prerequisite Checks",
+	"step.export_os_disk":    "Exporting OS Disk",
+	"step.download_image":    "Downloading Linux Cloud Image",
+	"step.convert_disk":      "Converting VHD to QCOW2",
+	"step.configure_image":   "Configuring Image for OCI",
+	"step.upload_image":      "Uploading Image to OCI",
+	"step.import_image":      "Importing OS Image in OCI",
+	"step.export_data_disks": "Exporting Data Disks",
+	"step.import_data_disks": "Importing Data Disks",
+	"step.generate_template": "Generating Template",
+	"step.deploy_template":   "Deploying the Template",
+	"step.finishing_script":  "Running Finishing Script on the Instance",
+	"step.verify_workflow":   "Verifying Workflow",
+
+	// Configuration validation
+	"config.required":           "%s is required",
+	"config.required_when":      "%s is required when %s",
+	"config.invalid_value":      "%s has invalid value '%s' (allowed values: %s)",
+	"config.invalid_ocid":       "%s is not a valid OCID: '%s'",
+	"config.invalid_region":     "%s is not a valid OCI region identifier: '%s'",
+	"config.invalid_url":        "%s is not a valid URL: '%s'",
+	"config.wrong_ocid_type":    "%s must be an OCID of type %[3]s, got an OCID of type '%[2]s'",
+	"config.wrong_ocid_realm":   "%s belongs to realm '%s' but region %s is in realm '%s'",
+	"config.invalid_azure_id":   "%s is not a valid Azure resource ID of type %[3]s: '%[2]s'",
+	"config.azure_id_conflict":  "%s is set to '%s' but the Azure resource ID refers to '%s'",
+	"config.mutually_exclusive": "%s and %s are mutually exclusive",
+
+	// Guardrail confirmations
+	"guardrail.upload_title":       "About to upload %s (%d GB) to Object Storage bucket '%s'",
+	"guardrail.apply_title":        "About to deploy instance '%s' with %s apply",
+	"guardrail.type_to_confirm":    "Type '%s' to continue",
+	"guardrail.confirmed_yes":      "%s: confirmed by --yes",
+	"guardrail.non_interactive":    "%s: confirmation required, re-run with --yes to proceed in non-interactive sessions",
+	"guardrail.declined":           "%s: cancelled by user",
+	"guardrail.gc_images_title":    "About to delete %d custom images in compartment %s",
+	"guardrail.gc_snapshots_title": "About to delete %d export snapshots in resource group %s",
+	"guardrail.stop_vm_title":      "About to deallocate Azure VM '%s' in resource group %s",
+	"history.previous_migration":   "%s was already migrated by an earlier run (%s, finished %s)",
+	"history.select":               "Previous migration found",
+	"history.replace_title":        "Replacing the previous migration",
+	"history.replacing":            "Running the full migration again; resources of the previous run are not deleted",
+	"history.non_interactive":      "previous migration found, set EXISTING_MIGRATION_ACTION or re-run with --yes to replace it in non-interactive sessions",
+	"history.aborted":              "previous migration of %s found, aborting",
+
+	// Run and cleanup
+	"run.cleaning_up":        "Cleaning up %d temporary resource(s) left by the run...",
+	"run.cleanup_incomplete": "Some temporary resources could not be cleaned up and may need manual removal",
+	"run.summary_written":    "Run summary written to %s",
+	"run.history_failed":     "Failed to record the migration history: %v",
+	"cleanup.running":        "Cleaning up: %s",
+	"cleanup.failed":         "Cleanup action failed (%s): %v",
+
+	// Batch runs
+	"batch.migrating_from": "Migrating %d VM(s) of %s matching '%s': %s",
+	"batch.migrating":      "Migrating %d VM(s) matching '%s': %s",
+	"batch.migrating_vm":   "Migrating VM %d of %d: %s",
+	"batch.interrupted":    "Batch interrupted; %d VM(s) not migrated",
+	"batch.results":        "Batch results:",
+
+	// Run summaries
+	"summary.throughput":              "Throughput of this run (see throughput in the run summary):",
+	"summary.throughput_phase":        "  %-8s %d transfer(s), min %.1f MB/s, avg %.1f MB/s, max %.1f MB/s",
+	"summary.api_operations":          "API operations invoked by this run (see apiOperations in the run summary):",
+	"summary.watchdog_incidents":      "%d stuck mount or NBD operation(s) were killed by the watchdog:",
+	"summary.watchdog_incident":       "  %s %s on %s: %s",
+	"summary.dependencies_unreadable": "Could not read the extensions and managed identities of the Azure VM: %v",
+	"summary.no_dependencies":         "✓ No VM extension, managed identity or Key Vault dependency that would not carry over to OCI",
+	"summary.dependencies":            "%d Azure dependency(ies) of the VM will not carry over to OCI; re-provision them after the migration:",
+
+	// Source VM power
+	"power.already_stopped": "Compute instance is already %s",
+	"power.force_stop":      "%s: confirmed by AZURE_FORCE_STOP",
+	"power.starting":        "Starting Azure VM %s...",
+	"power.running_again":   "✓ Azure VM %s is running again",
+	"power.deallocating":    "Deallocating Azure VM %s...",
+	"power.deallocated":     "✓ Azure VM %s is deallocated",
+
+	// Live sync
+	"livesync.single_pass":     "Compute instance is stopped, its disks are exported in a single pass",
+	"livesync.first_pass":      "Live sync: exporting %d disk(s) while the VM is running...",
+	"livesync.first_pass_done": "✓ First pass of %d disk(s) done in %s",
+	"livesync.stop_vm":         "Stop or deallocate the Azure VM %s now: the changes made since the first pass are exported once it is stopped (waiting up to %s)",
+	"livesync.stopped":         "✓ Compute instance is stopped, exporting the changes",
+	"livesync.final_pass_done": "✓ Final pass done in %s, %d MB of changes applied",
+
+	// Upload during conversion
+	"upload.staged_start":    "Uploading %s to bucket %s while it is converted",
+	"upload.staged_skipped":  "Not uploading the image while it is converted: %v",
+	"upload.staged_stopped":  "Stopped uploading %s while it is written, the upload step uploads the remaining parts: %v",
+	"upload.staged_complete": "Uploading the parts of %s that are missing or changed since the conversion...",
+}
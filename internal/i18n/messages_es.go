@@ -0,0 +1,115 @@
+package i18n
+
+// messagesES is the Spanish message catalog. Missing keys fall back to English.
+var messagesES = map[string]string{
+	// Workflow banners
+	"workflow.header":             "Kopru - Herramienta de Migración de Cómputo v%s",
+	"workflow.source_platform":    "Plataforma de origen: %s",
+	"workflow.target_platform":    "Plataforma de destino: %s",
+	"workflow.executing":          "Ejecutando: %s",
+	"workflow.failed":             "El flujo de trabajo falló: %v",
+	"workflow.azure_completed":    "¡Migración de Azure a OCI completada correctamente!",
+	"workflow.linux_completed":    "¡Despliegue de imagen Linux en OCI completado correctamente!",
+	"workflow.prereq_passed":      "Comprobaciones previas superadas",
+	"workflow.verify_complete":    "Verificación del flujo de trabajo completada",
+	"workflow.next_steps":         "Próximos pasos:",
+	"workflow.next_start":         "%d. Termine la configuración de red y DNS y arranque la instancia detenida: kopru start",
+	"workflow.next_check_console": "%d. Compruebe la instancia desplegada en la consola de OCI",
+	"workflow.next_verify":        "%d. Verifique que la instancia funciona como se espera",
+	"workflow.next_navigate":      "%d. Vaya a: %s",
+	"workflow.next_run_iac":       "%d. Ejecute: %[2]s init && %[2]s apply",
+
+	// Step titles
+	"step.review_migration":  "Revisando la configuración de la migración",
+	"step.review_deployment": "Revisando la configuración del despliegue",
+	"step.prerequisites":     "Ejecutando comprobaciones previas",
+	"step.export_os_disk":    "Exportando el disco del sistema operativo",
+	"step.download_image":    "Descargando la imagen cloud de Linux",
+	"step.convert_disk":      "Convirtiendo VHD a QCOW2",
+	"step.configure_image":   "Configurando la imagen para OCI",
+	"step.upload_image":      "Subiendo la imagen a OCI",
+	"step.import_image":      "Importando la imagen del sistema operativo en OCI",
+	"step.export_data_disks": "Exportando los discos de datos",
+	"step.import_data_disks": "Importando los discos de datos",
+	"step.generate_template": "Generando la plantilla",
+	"step.deploy_template":   "Desplegando la plantilla",
+	"step.finishing_script":  "Ejecutando el script de finalización en la instancia",
+	"step.verify_workflow":   "Verificando el flujo de trabajo",
+
+	// Configuration validation
+	"config.required":           "%s es obligatorio",
+	"config.required_when":      "%s es obligatorio cuando %s",
+	"config.invalid_value":      "%s tiene un valor no válido '%s' (valores permitidos: %s)",
+	"config.invalid_ocid":       "%s no es un OCID válido: '%s'",
+	"config.invalid_region":     "%s no es un identificador de región de OCI válido: '%s'",
+	"config.invalid_url":        "%s no es una URL válida: '%s'",
+	"config.wrong_ocid_type":    "%s debe ser un OCID de tipo %[3]s, pero es un OCID de tipo '%[2]s'",
+	"config.wrong_ocid_realm":   "%s pertenece al realm '%s' pero la región %s está en el realm '%s'",
+	"config.invalid_azure_id":   "%s no es un ID de recurso de Azure válido de tipo %[3]s: '%[2]s'",
+	"config.azure_id_conflict":  "%s tiene el valor '%s' pero el ID de recurso de Azure hace referencia a '%s'",
+	"config.mutually_exclusive": "%s y %s son mutuamente excluyentes",
+
+	// Guardrail confirmations
+	"guardrail.upload_title":       "Se va a subir %s (%d GB) al bucket de Object Storage '%s'",
+	"guardrail.apply_title":        "Se va a desplegar la instancia '%s' con %s apply",
+	"guardrail.type_to_confirm":    "Escriba '%s' para continuar",
+	"guardrail.confirmed_yes":      "%s: confirmado con --yes",
+	"guardrail.non_interactive":    "%s: se requiere confirmación, vuelva a ejecutar con --yes para continuar en sesiones no interactivas",
+	"guardrail.declined":           "%s: cancelado por el usuario",
+	"guardrail.gc_images_title":    "Se van a eliminar %d imágenes personalizadas del compartimento %s",
+	"guardrail.gc_snapshots_title": "Se van a eliminar %d instantáneas de exportación del grupo de recursos %s",
+	"guardrail.stop_vm_title":      "Se va a desasignar la VM de Azure '%s' del grupo de recursos %s",
+	"history.previous_migration":   "%s ya fue migrado por una ejecución anterior (%s, finalizada %s)",
+	"history.select":               "Se encontró una migración anterior",
+	"history.replace_title":        "Reemplazando la migración anterior",
+	"history.replacing":            "Ejecutando de nuevo la migración completa; los recursos de la ejecución anterior no se eliminan",
+	"history.non_interactive":      "se encontró una migración anterior, configure EXISTING_MIGRATION_ACTION o vuelva a ejecutar con --yes para reemplazarla en sesiones no interactivas",
+	"history.aborted":              "se encontró una migración anterior de %s, abortando",
+
+	// Run and cleanup
+	"run.cleaning_up":        "Limpiando %d recurso(s) temporal(es) que dejó la ejecución...",
+	"run.cleanup_incomplete": "Algunos recursos temporales no se pudieron limpiar y puede que haya que eliminarlos manualmente",
+	"run.summary_written":    "Resumen de la ejecución escrito en %s",
+	"run.history_failed":     "No se pudo registrar el historial de migraciones: %v",
+	"cleanup.running":        "Limpiando: %s",
+	"cleanup.failed":         "Falló la acción de limpieza (%s): %v",
+
+	// Batch runs
+	"batch.migrating_from": "Migrando %d VM de %s que coinciden con '%s': %s",
+	"batch.migrating":      "Migrando %d VM que coinciden con '%s': %s",
+	"batch.migrating_vm":   "Migrando la VM %d de %d: %s",
+	"batch.interrupted":    "Lote interrumpido; %d VM sin migrar",
+	"batch.results":        "Resultados del lote:",
+
+	// Run summaries
+	"summary.throughput":              "Rendimiento de esta ejecución (vea throughput en el resumen de la ejecución):",
+	"summary.throughput_phase":        "  %-8s %d transferencia(s), mín. %.1f MB/s, media %.1f MB/s, máx. %.1f MB/s",
+	"summary.api_operations":          "Operaciones de API invocadas por esta ejecución (vea apiOperations en el resumen de la ejecución):",
+	"summary.watchdog_incidents":      "El watchdog terminó %d operación(es) de montaje o NBD bloqueada(s):",
+	"summary.watchdog_incident":       "  %s %s en %s: %s",
+	"summary.dependencies_unreadable": "No se pudieron leer las extensiones ni las identidades administradas de la VM de Azure: %v",
+	"summary.no_dependencies":         "✓ Ninguna extensión de VM, identidad administrada ni dependencia de Key Vault que no se traslade a OCI",
+	"summary.dependencies":            "%d dependencia(s) de Azure de la VM no se trasladan a OCI; vuelva a aprovisionarlas después de la migración:",
+
+	// Source VM power
+	"power.already_stopped": "La instancia de cómputo ya está en estado %s",
+	"power.force_stop":      "%s: confirmado por AZURE_FORCE_STOP",
+	"power.starting":        "Iniciando la VM de Azure %s...",
+	"power.running_again":   "✓ La VM de Azure %s vuelve a estar en ejecución",
+	"power.deallocating":    "Desasignando la VM de Azure %s...",
+	"power.deallocated":     "✓ La VM de Azure %s está desasignada",
+
+	// Live sync
+	"livesync.single_pass":     "La instancia de cómputo está detenida, sus discos se exportan en una sola pasada",
+	"livesync.first_pass":      "Sincronización en vivo: exportando %d disco(s) mientras la VM está en ejecución...",
+	"livesync.first_pass_done": "✓ Primera pasada de %d disco(s) completada en %s",
+	"livesync.stop_vm":         "Detenga o desasigne ahora la VM de Azure %s: los cambios realizados desde la primera pasada se exportan en cuanto se detenga (esperando hasta %s)",
+	"livesync.stopped":         "✓ La instancia de cómputo está detenida, exportando los cambios",
+	"livesync.final_pass_done": "✓ Pasada final completada en %s, %d MB de cambios aplicados",
+
+	// Upload during conversion
+	"upload.staged_start":    "Subiendo %s al bucket %s mientras se convierte",
+	"upload.staged_skipped":  "La imagen no se sube mientras se convierte: %v",
+	"upload.staged_stopped":  "Se dejó de subir %s mientras se escribe, el paso de subida sube las partes restantes: %v",
+	"upload.staged_complete": "Subiendo las partes de %s que faltan o cambiaron desde la conversión...",
+}
@@ -2,13 +2,35 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"strings"
+	"sync"
 	"time"
 )
 
+// Supported log output formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Fields holds structured key-value pairs attached to a log record.
+type Fields map[string]interface{}
+
+// Record is a single structured log entry emitted in JSON format.
+type Record struct {
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	Workflow  string `json:"workflow,omitempty"`
+	Step      string `json:"step,omitempty"`
+	Message   string `json:"message"`
+	Fields    Fields `json:"fields,omitempty"`
+}
+
 // Logger provides structured logging with different severity levels.
 type Logger struct {
 	infoLog    *log.Logger
@@ -18,6 +40,12 @@ type Logger struct {
 	debugLog   *log.Logger
 	debug      bool
 	logFile    *os.File
+
+	mu       sync.Mutex
+	out      io.Writer
+	format   string
+	workflow string
+	step     string
 }
 
 // New creates a new Logger instance.
@@ -30,6 +58,8 @@ func New(debug bool) *Logger {
 		errorLog:   log.New(os.Stderr, "[ERROR] ", flags),
 		debugLog:   log.New(os.Stderr, "[DEBUG] ", flags),
 		debug:      debug,
+		out:        os.Stderr,
+		format:     FormatText,
 	}
 }
 
@@ -50,9 +80,36 @@ func NewWithFile(debug bool, logFilePath string) (*Logger, error) {
 		debugLog:   log.New(multiWriter, "[DEBUG] ", flags),
 		debug:      debug,
 		logFile:    logFile,
+		out:        multiWriter,
+		format:     FormatText,
 	}, nil
 }
 
+// SetFormat selects the output format: text (default) or json.
+func (l *Logger) SetFormat(format string) error {
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		l.format = FormatText
+	case FormatJSON:
+		l.format = FormatJSON
+	default:
+		return fmt.Errorf("unsupported log format '%s' (supported: %s, %s)", format, FormatText, FormatJSON)
+	}
+	return nil
+}
+
+// Format returns the selected output format.
+func (l *Logger) Format() string {
+	return l.format
+}
+
+// SetWorkflow sets the workflow name included in structured log records.
+func (l *Logger) SetWorkflow(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.workflow = name
+}
+
 // Close closes the log file if one is open.
 func (l *Logger) Close() error {
 	if l.logFile != nil {
@@ -63,60 +120,100 @@ func (l *Logger) Close() error {
 
 // Info logs an informational message.
 func (l *Logger) Info(msg string) {
-	l.infoLog.Println(msg)
+	l.output(l.infoLog, "info", msg, nil)
 }
 
 // Infof logs a formatted informational message.
 func (l *Logger) Infof(format string, args ...interface{}) {
-	l.infoLog.Printf(format, args...)
+	l.output(l.infoLog, "info", fmt.Sprintf(format, args...), nil)
 }
 
 // Success logs a success message.
 func (l *Logger) Success(msg string) {
-	l.successLog.Println(msg)
+	l.output(l.successLog, "success", msg, nil)
 }
 
 // Successf logs a formatted success message.
 func (l *Logger) Successf(format string, args ...interface{}) {
-	l.successLog.Printf(format, args...)
+	l.output(l.successLog, "success", fmt.Sprintf(format, args...), nil)
 }
 
 // Warning logs a warning message.
 func (l *Logger) Warning(msg string) {
-	l.warningLog.Println(msg)
+	l.output(l.warningLog, "warning", msg, nil)
 }
 
 // Warningf logs a formatted warning message.
 func (l *Logger) Warningf(format string, args ...interface{}) {
-	l.warningLog.Printf(format, args...)
+	l.output(l.warningLog, "warning", fmt.Sprintf(format, args...), nil)
 }
 
 // Error logs an error message.
 func (l *Logger) Error(msg string) {
-	l.errorLog.Println(msg)
+	l.output(l.errorLog, "error", msg, nil)
 }
 
 // Errorf logs a formatted error message.
 func (l *Logger) Errorf(format string, args ...interface{}) {
-	l.errorLog.Printf(format, args...)
+	l.output(l.errorLog, "error", fmt.Sprintf(format, args...), nil)
 }
 
 // Debug logs a debug message (only if debug mode is enabled).
 func (l *Logger) Debug(msg string) {
 	if l.debug {
-		l.debugLog.Println(msg)
+		l.output(l.debugLog, "debug", msg, nil)
 	}
 }
 
 // Debugf logs a formatted debug message (only if debug mode is enabled).
 func (l *Logger) Debugf(format string, args ...interface{}) {
 	if l.debug {
-		l.debugLog.Printf(format, args...)
+		l.output(l.debugLog, "debug", fmt.Sprintf(format, args...), nil)
 	}
 }
 
+// InfoFields logs an informational message with structured fields. The fields
+// are only emitted in JSON format; text output shows the message alone.
+func (l *Logger) InfoFields(msg string, fields Fields) {
+	l.output(l.infoLog, "info", msg, fields)
+}
+
+// output writes msg with the given text logger, or as a JSON record when the JSON format is selected.
+func (l *Logger) output(textLog *log.Logger, level, msg string, fields Fields) {
+	if l.format != FormatJSON {
+		textLog.Println(msg)
+		return
+	}
+	// Blank lines and separator banners only structure the text output.
+	if strings.Trim(msg, "=") == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	data, err := json.Marshal(Record{
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Level:     level,
+		Workflow:  l.workflow,
+		Step:      l.step,
+		Message:   msg,
+		Fields:    fields,
+	})
+	if err != nil {
+		textLog.Println(msg)
+		return
+	}
+	_, _ = l.out.Write(append(data, '\n'))
+}
+
 // Step logs a step header for workflow progress.
 func (l *Logger) Step(stepNum int, description string) {
+	l.mu.Lock()
+	l.step = fmt.Sprintf("%d. %s", stepNum, description)
+	l.mu.Unlock()
+	if l.format == FormatJSON {
+		l.InfoFields(fmt.Sprintf("Step %d: %s", stepNum, description), Fields{"step_number": stepNum})
+		return
+	}
 	l.Info("")
 	l.Info("=========================================")
 	l.Infof("Step %d: %s", stepNum, description)
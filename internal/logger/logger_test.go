@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
@@ -120,3 +121,70 @@ func TestLoggerOutput(t *testing.T) {
 	log := New(false)
 	log.Info("test")
 }
+
+func TestLoggerSetFormat(t *testing.T) {
+	log := New(false)
+	if log.Format() != FormatText {
+		t.Errorf("Expected default format to be '%s', got '%s'", FormatText, log.Format())
+	}
+	if err := log.SetFormat("JSON"); err != nil {
+		t.Fatalf("Failed to set JSON format: %v", err)
+	}
+	if log.Format() != FormatJSON {
+		t.Errorf("Expected format to be '%s', got '%s'", FormatJSON, log.Format())
+	}
+	if err := log.SetFormat("xml"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestLoggerJSONOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "test.log")
+	log, err := NewWithFile(false, logFile)
+	if err != nil {
+		t.Fatalf("Failed to create logger with file: %v", err)
+	}
+	if err := log.SetFormat(FormatJSON); err != nil {
+		t.Fatalf("Failed to set JSON format: %v", err)
+	}
+	log.SetWorkflow("azure-to-oci")
+	log.Step(3, "Exporting OS Disk")
+	log.Info("")
+	log.Info("=========================================")
+	log.Warningf("disk %s is large", "os")
+	log.InfoFields("Downloading", Fields{"bytes": 1024})
+	log.Close()
+
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 JSON records, got %d:\n%s", len(lines), content)
+	}
+
+	var records []Record
+	for _, line := range lines {
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("Failed to parse JSON record %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+	if records[0].Message != "Step 3: Exporting OS Disk" || records[0].Fields["step_number"] != float64(3) {
+		t.Errorf("Unexpected step record: %+v", records[0])
+	}
+	if records[1].Level != "warning" || records[1].Message != "disk os is large" {
+		t.Errorf("Unexpected warning record: %+v", records[1])
+	}
+	for _, r := range records {
+		if r.Workflow != "azure-to-oci" || r.Step != "3. Exporting OS Disk" || r.Timestamp == "" {
+			t.Errorf("Expected workflow, step and timestamp on every record, got %+v", r)
+		}
+	}
+	if records[2].Fields["bytes"] != float64(1024) {
+		t.Errorf("Expected bytes field, got %+v", records[2].Fields)
+	}
+}
@@ -0,0 +1,150 @@
+// Package notify posts migration events to webhooks such as Slack and Microsoft Teams.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Supported webhook payload formats.
+const (
+	FormatJSON  = "json"
+	FormatSlack = "slack"
+	FormatTeams = "teams"
+)
+
+// Event types.
+const (
+	EventRunStarted  = "run_started"
+	EventStepDone    = "step_completed"
+	EventRunFinished = "run_finished"
+)
+
+const requestTimeout = 15 * time.Second
+
+// Event describes a step completion or the start or end of a run.
+type Event struct {
+	Type            string    `json:"type"`
+	RunID           string    `json:"runId"`
+	Workflow        string    `json:"workflow"`
+	VM              string    `json:"vm"`
+	Step            string    `json:"step,omitempty"`
+	Status          string    `json:"status,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	DurationSeconds float64   `json:"durationSeconds,omitempty"`
+	Time            time.Time `json:"time"`
+}
+
+// Failed reports whether the event describes a failure.
+func (e Event) Failed() bool {
+	return e.Error != ""
+}
+
+// Text describes the event in one line, followed by the error, if any.
+func (e Event) Text() string {
+	var b strings.Builder
+	switch e.Type {
+	case EventRunStarted:
+		fmt.Fprintf(&b, "Kopru %s of %s started (run %s)", e.Workflow, e.VM, e.RunID)
+	case EventStepDone:
+		fmt.Fprintf(&b, "Kopru %s of %s: step '%s' %s after %s (run %s)", e.Workflow, e.VM, e.Step, e.Status, e.duration(), e.RunID)
+	default:
+		fmt.Fprintf(&b, "Kopru %s of %s %s after %s (run %s)", e.Workflow, e.VM, e.Status, e.duration(), e.RunID)
+	}
+	if e.Error != "" {
+		fmt.Fprintf(&b, "\nError: %s", e.Error)
+	}
+	return b.String()
+}
+
+func (e Event) duration() time.Duration {
+	return time.Duration(e.DurationSeconds * float64(time.Second)).Round(time.Second)
+}
+
+// Notifier posts events to a webhook.
+type Notifier struct {
+	url    string
+	format string
+	client *http.Client
+}
+
+// New returns a notifier that posts to webhookURL in format.
+func New(webhookURL, format string) (*Notifier, error) {
+	switch format {
+	case FormatJSON, FormatSlack, FormatTeams:
+	default:
+		return nil, fmt.Errorf("unsupported notification format %q", format)
+	}
+	return &Notifier{url: webhookURL, format: format, client: &http.Client{Timeout: requestTimeout}}, nil
+}
+
+// payload returns the request body of e in the format of the notifier.
+func (n *Notifier) payload(e Event) any {
+	switch n.format {
+	case FormatSlack:
+		return map[string]string{"text": e.Text()}
+	case FormatTeams:
+		// Teams webhooks created with the Workflows app expect a message with an
+		// Adaptive Card attachment.
+		color := "Good"
+		if e.Failed() {
+			color = "Attention"
+		}
+		body := []map[string]any{
+			{"type": "TextBlock", "text": strings.SplitN(e.Text(), "\n", 2)[0], "weight": "Bolder", "wrap": true, "color": color},
+		}
+		if e.Error != "" {
+			body = append(body, map[string]any{"type": "TextBlock", "text": e.Error, "wrap": true})
+		}
+		return map[string]any{
+			"type": "message",
+			"attachments": []map[string]any{{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]any{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			}},
+		}
+	default:
+		return e
+	}
+}
+
+// Send posts e to the webhook.
+func (n *Notifier) Send(ctx context.Context, e Event) error {
+	body, err := json.Marshal(n.payload(e))
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("failed to create notification request: invalid webhook URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// The URL of Slack and Teams webhooks is a credential; keep it out of the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notification webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
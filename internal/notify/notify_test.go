@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotifierFormats(t *testing.T) {
+	event := Event{
+		Type:            EventStepDone,
+		RunID:           "a1b2c3",
+		Workflow:        "azure-to-oci",
+		VM:              "web-01",
+		Step:            "Export OS disk",
+		Status:          "failed",
+		Error:           "snapshot failed",
+		DurationSeconds: 90,
+		Time:            time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{FormatJSON, []string{`"type":"step_completed"`, `"runId":"a1b2c3"`, `"vm":"web-01"`, `"error":"snapshot failed"`}},
+		{FormatSlack, []string{`"text":"Kopru azure-to-oci of web-01: step 'Export OS disk' failed after 1m30s (run a1b2c3)\nError: snapshot failed"`}},
+		{FormatTeams, []string{`"type":"message"`, `"contentType":"application/vnd.microsoft.card.adaptive"`, `"color":"Attention"`, `"text":"snapshot failed"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var body, contentType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				body, contentType = string(data), r.Header.Get("Content-Type")
+			}))
+			defer server.Close()
+
+			n, err := New(server.URL, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := n.Send(context.Background(), event); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if !json.Valid([]byte(body)) || contentType != "application/json" {
+				t.Fatalf("Expected a JSON body, got %q (%s)", body, contentType)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("Expected body to contain %s, got %s", want, body)
+				}
+			}
+		})
+	}
+
+	if _, err := New("https://example.com", "email"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
+
+func TestNotifierErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_token", http.StatusForbidden)
+	}))
+	defer server.Close()
+	n, _ := New(server.URL+"/services/T000/B000/secret", FormatSlack)
+	err := n.Send(context.Background(), Event{Type: EventRunStarted})
+	if err == nil || !strings.Contains(err.Error(), "403 Forbidden: invalid_token") {
+		t.Errorf("Send() error = %v, want the webhook response", err)
+	}
+
+	n, _ = New("http://127.0.0.1:1/services/T000/B000/secret", FormatSlack)
+	if err := n.Send(context.Background(), Event{Type: EventRunStarted}); err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("Send() error = %v, want an error without the webhook URL", err)
+	}
+}
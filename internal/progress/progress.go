@@ -0,0 +1,327 @@
+// Package progress reports the progress of long-running transfers and conversions.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+const (
+	refreshInterval = 500 * time.Millisecond // Redraw interval of TTY progress bars
+	logInterval     = 30 * time.Second       // Interval between plain-log progress lines
+	barWidth        = 30
+)
+
+// Phases of the transfers whose throughput is recorded for the run report.
+const (
+	PhaseDownload = "download"
+	PhaseConvert  = "convert"
+	PhaseCopy     = "copy"
+	PhaseUpload   = "upload"
+)
+
+// Transfer is the throughput of a finished operation of a phase.
+type Transfer struct {
+	Phase   string
+	Item    string        // What was transferred, e.g. the disk file
+	Bytes   int64         // Bytes processed, excluding those skipped
+	Elapsed time.Duration // Duration of the operation
+}
+
+// MBPerSecond returns the throughput of the transfer in MB (MiB) per second.
+func (t Transfer) MBPerSecond() float64 {
+	if t.Elapsed <= 0 {
+		return 0
+	}
+	return float64(t.Bytes) / (1024 * 1024) / t.Elapsed.Seconds()
+}
+
+var (
+	transfersMu sync.Mutex
+	transfers   []Transfer
+)
+
+// Transfers returns the transfers recorded by reporters so far, in the order they finished.
+func Transfers() []Transfer {
+	transfersMu.Lock()
+	defer transfersMu.Unlock()
+	return append([]Transfer(nil), transfers...)
+}
+
+// ResetTransfers forgets the recorded transfers.
+func ResetTransfers() {
+	transfersMu.Lock()
+	defer transfersMu.Unlock()
+	transfers = nil
+}
+
+// Reporter tracks the number of bytes processed by a single operation and
+// periodically reports throughput, percent complete and ETA. On a terminal with
+// text logging the progress is drawn as a bar on stderr; otherwise a log line with
+// structured progress fields is written at a fixed interval so that long steps are
+// visible in log files.
+type Reporter struct {
+	label   string
+	total   int64
+	current atomic.Int64
+	skipped atomic.Int64
+	start   time.Time
+	log     *logger.Logger
+	lastLog time.Time
+	bar     bool
+	once    sync.Once
+	phase   string // Phase recorded as a Transfer when done; none when empty
+	item    string
+}
+
+// New creates and starts a reporter for an operation of total bytes.
+// A total of zero means the size is unknown; percent and ETA are then omitted.
+func New(log *logger.Logger, label string, total int64) *Reporter {
+	r := &Reporter{label: label, total: total, start: time.Now(), log: log}
+	r.lastLog = r.start
+	r.bar = display.tty && log.Format() == logger.FormatText
+	display.add(r)
+	return r
+}
+
+// Record makes Done record the operation as a transfer of item in phase, so that its
+// throughput is included in the run report. It returns r.
+func (r *Reporter) Record(phase, item string) *Reporter {
+	r.phase, r.item = phase, item
+	return r
+}
+
+// Skip marks n bytes as already completed without counting them towards the
+// throughput, e.g. blocks restored from a resumed download.
+func (r *Reporter) Skip(n int64) {
+	r.skipped.Add(n)
+	r.current.Add(n)
+}
+
+// Add records n more bytes as processed.
+func (r *Reporter) Add(n int64) {
+	r.current.Add(n)
+}
+
+// Set records the absolute number of bytes processed so far.
+func (r *Reporter) Set(n int64) {
+	r.current.Store(n)
+}
+
+// Write implements io.Writer so a Reporter can be used with io.TeeReader or io.MultiWriter.
+func (r *Reporter) Write(p []byte) (int, error) {
+	r.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// Done stops the reporter and logs a summary of the operation. The throughput of an
+// operation marked with Record is recorded as a transfer.
+func (r *Reporter) Done() {
+	r.once.Do(func() {
+		display.remove(r)
+		now := time.Now()
+		elapsed := now.Sub(r.start)
+		msg := fmt.Sprintf("%s: %s in %s (%s)", r.label, formatBytes(r.current.Load()), formatDuration(elapsed), formatRate(r.rate(elapsed)))
+		display.mu.Lock()
+		display.last = msg
+		display.mu.Unlock()
+		if r.phase != "" {
+			transfersMu.Lock()
+			transfers = append(transfers, Transfer{Phase: r.phase, Item: r.item, Bytes: r.current.Load() - r.skipped.Load(), Elapsed: elapsed})
+			transfersMu.Unlock()
+		}
+		r.log.InfoFields(msg, r.fields(now))
+	})
+}
+
+// rate returns the throughput in bytes per second, excluding skipped bytes.
+func (r *Reporter) rate(elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(r.current.Load()-r.skipped.Load()) / elapsed.Seconds()
+}
+
+// fields returns the current progress as structured log fields.
+func (r *Reporter) fields(now time.Time) logger.Fields {
+	current := r.current.Load()
+	rate := r.rate(now.Sub(r.start))
+	fields := logger.Fields{
+		"operation":        r.label,
+		"bytes":            current,
+		"bytes_per_second": int64(rate),
+		"elapsed_seconds":  int64(now.Sub(r.start).Seconds()),
+	}
+	if r.total > 0 {
+		fields["total_bytes"] = r.total
+		fields["percent"] = float64(min(current, r.total)) / float64(r.total) * 100
+		if rate > 0 && current < r.total {
+			fields["eta_seconds"] = int64(float64(r.total-current) / rate)
+		}
+	}
+	return fields
+}
+
+// status formats the current progress, optionally with a bar.
+func (r *Reporter) status(now time.Time, withBar bool) string {
+	current := r.current.Load()
+	rate := r.rate(now.Sub(r.start))
+	var b strings.Builder
+	b.WriteString(r.label)
+	if r.total <= 0 {
+		fmt.Fprintf(&b, ": %s, %s", formatBytes(current), formatRate(rate))
+		return b.String()
+	}
+	if current > r.total {
+		current = r.total
+	}
+	fraction := float64(current) / float64(r.total)
+	if withBar {
+		filled := int(fraction * barWidth)
+		fmt.Fprintf(&b, " [%s%s]", strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled))
+	} else {
+		b.WriteString(":")
+	}
+	fmt.Fprintf(&b, " %5.1f%% %s/%s, %s", fraction*100, formatBytes(current), formatBytes(r.total), formatRate(rate))
+	if rate > 0 && current < r.total {
+		eta := time.Duration(float64(r.total-current) / rate * float64(time.Second))
+		fmt.Fprintf(&b, ", ETA %s", formatDuration(eta))
+	}
+	return b.String()
+}
+
+// renderer owns the goroutine that periodically reports all active reporters.
+// Concurrent reporters (e.g. parallel data disk exports) share one TTY line.
+type renderer struct {
+	mu        sync.Mutex
+	reporters []*Reporter
+	out       io.Writer
+	tty       bool
+	stop      chan struct{}
+	lineWidth int
+	last      string // Summary of the most recently finished reporter
+}
+
+var display = &renderer{out: os.Stderr, tty: isTerminal(os.Stderr)}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (d *renderer) add(r *Reporter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reporters = append(d.reporters, r)
+	if d.stop == nil {
+		d.stop = make(chan struct{})
+		go d.loop(d.stop)
+	}
+}
+
+func (d *renderer) remove(r *Reporter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, existing := range d.reporters {
+		if existing == r {
+			d.reporters = append(d.reporters[:i], d.reporters[i+1:]...)
+			break
+		}
+	}
+	d.clearLine()
+	if len(d.reporters) == 0 && d.stop != nil {
+		close(d.stop)
+		d.stop = nil
+	}
+}
+
+func (d *renderer) loop(stop <-chan struct{}) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			d.render(now)
+		}
+	}
+}
+
+func (d *renderer) render(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var bars []*Reporter
+	for _, r := range d.reporters {
+		if r.bar {
+			bars = append(bars, r)
+		} else if now.Sub(r.lastLog) >= logInterval {
+			r.lastLog = now
+			r.log.InfoFields(r.status(now, false), r.fields(now))
+		}
+	}
+	if len(bars) == 0 {
+		return
+	}
+	parts := make([]string, len(bars))
+	for i, r := range bars {
+		parts[i] = r.status(now, len(bars) == 1)
+	}
+	line := strings.Join(parts, " | ")
+	d.clearLine()
+	fmt.Fprint(d.out, line)
+	d.lineWidth = len(line)
+}
+
+// Snapshot returns the progress of the active reporters, or the summary of the most
+// recently finished one when none is active, e.g. for heartbeat log lines. It returns
+// an empty string when no progress was reported yet.
+func Snapshot() string {
+	display.mu.Lock()
+	defer display.mu.Unlock()
+	if len(display.reporters) == 0 {
+		return display.last
+	}
+	now := time.Now()
+	parts := make([]string, len(display.reporters))
+	for i, r := range display.reporters {
+		parts[i] = r.status(now, false)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// clearLine erases the progress line drawn on the terminal, if any.
+func (d *renderer) clearLine() {
+	if d.tty && d.lineWidth > 0 {
+		fmt.Fprintf(d.out, "\r%s\r", strings.Repeat(" ", d.lineWidth))
+		d.lineWidth = 0
+	}
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func formatRate(bytesPerSecond float64) string {
+	return formatBytes(int64(bytesPerSecond)) + "/s"
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,100 @@
+package progress
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestReporterStatus(t *testing.T) {
+	r := New(logger.New(false), "Downloading disk.vhd", 100*1024*1024)
+	defer r.Done()
+	r.Add(25 * 1024 * 1024)
+
+	status := r.status(r.start.Add(10*time.Second), false)
+	for _, want := range []string{"Downloading disk.vhd:", "25.0%", "25.0 MiB/100.0 MiB", "2.5 MiB/s", "ETA 30s"} {
+		if !strings.Contains(status, want) {
+			t.Errorf("Expected status to contain %q, got %q", want, status)
+		}
+	}
+
+	bar := r.status(r.start.Add(10*time.Second), true)
+	if !strings.Contains(bar, "[=======       ") {
+		t.Errorf("Expected status to contain a quarter-filled bar, got %q", bar)
+	}
+}
+
+func TestReporterSkipExcludedFromRate(t *testing.T) {
+	r := New(logger.New(false), "Downloading", 100*1024*1024)
+	defer r.Done()
+	r.Skip(50 * 1024 * 1024)
+	r.Add(10 * 1024 * 1024)
+
+	status := r.status(r.start.Add(10*time.Second), false)
+	if !strings.Contains(status, "60.0%") || !strings.Contains(status, "1.0 MiB/s") || !strings.Contains(status, "ETA 40s") {
+		t.Errorf("Unexpected status for resumed transfer: %q", status)
+	}
+}
+
+func TestReporterUnknownTotal(t *testing.T) {
+	r := New(logger.New(false), "Copying", 0)
+	defer r.Done()
+	if _, err := r.Write(make([]byte, 2048)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	status := r.status(r.start.Add(time.Second), false)
+	if status != "Copying: 2.0 KiB, 2.0 KiB/s" {
+		t.Errorf("Unexpected status for unknown total: %q", status)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	r := New(logger.New(false), "Uploading image.qcow2", 100*1024*1024)
+	r.Add(50 * 1024 * 1024)
+	if snapshot := Snapshot(); !strings.Contains(snapshot, "Uploading image.qcow2:") || !strings.Contains(snapshot, "50.0%") {
+		t.Errorf("Expected snapshot of the active reporter, got %q", snapshot)
+	}
+
+	r.Done()
+	if snapshot := Snapshot(); !strings.HasPrefix(snapshot, "Uploading image.qcow2: 50.0 MiB in ") {
+		t.Errorf("Expected summary of the finished reporter, got %q", snapshot)
+	}
+}
+
+func TestReporterRecord(t *testing.T) {
+	ResetTransfers()
+	defer ResetTransfers()
+	r := New(logger.New(false), "Downloading disk.vhd", 100).Record(PhaseDownload, "disk.vhd")
+	r.Skip(40)
+	r.Add(60)
+	r.Done()
+	New(logger.New(false), "Hashing disk.vhd", 100).Done()
+
+	got := Transfers()
+	if len(got) != 1 || got[0].Phase != PhaseDownload || got[0].Item != "disk.vhd" || got[0].Bytes != 60 {
+		t.Errorf("Transfers() = %+v, want the download without its skipped bytes", got)
+	}
+	if rate := (Transfer{Bytes: 50 * 1024 * 1024, Elapsed: 2 * time.Second}).MBPerSecond(); rate != 25 {
+		t.Errorf("MBPerSecond() = %v, want 25", rate)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{512, "512 B"},
+		{1024, "1.0 KiB"},
+		{1536 * 1024, "1.5 MiB"},
+		{30 * 1024 * 1024 * 1024, "30.0 GiB"},
+	}
+	for _, tt := range tests {
+		if result := formatBytes(tt.input); result != tt.expected {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Package prompt provides interactive prompts for selecting values in a terminal session.
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNoOptions is returned by Select when there is nothing to choose from.
+	ErrNoOptions = errors.New("no options available")
+	// ErrNotConfirmed is returned by Confirm when the typed answer does not match.
+	ErrNotConfirmed = errors.New("operation not confirmed")
+)
+
+// Option is a selectable value with a human readable label.
+type Option struct {
+	Label string
+	Value string
+}
+
+// Prompter reads answers from an input stream and writes questions to an output stream.
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// New creates a Prompter reading from in and writing to out.
+func New(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// IsInteractive reports whether both stdin and stderr are attached to a terminal.
+func IsInteractive() bool {
+	return isTerminal(os.Stdin) && isTerminal(os.Stderr)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Select shows a numbered list of options and returns the value of the chosen one.
+// A single option is still shown so the user can confirm it.
+func (p *Prompter) Select(label string, options []Option) (string, error) {
+	if len(options) == 0 {
+		return "", fmt.Errorf("%s: %w", label, ErrNoOptions)
+	}
+	fmt.Fprintf(p.out, "\n%s:\n", label)
+	for i, opt := range options {
+		fmt.Fprintf(p.out, "  %2d) %s\n", i+1, opt.Label)
+	}
+	for {
+		answer, err := p.ask(fmt.Sprintf("Enter a number [1-%d]: ", len(options)))
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1].Value, nil
+		}
+		fmt.Fprintf(p.out, "Invalid selection '%s'\n", answer)
+	}
+}
+
+// Input asks for a free-form, non-empty value.
+func (p *Prompter) Input(label string) (string, error) {
+	for {
+		answer, err := p.ask(label + ": ")
+		if err != nil {
+			return "", err
+		}
+		if answer != "" {
+			return answer, nil
+		}
+	}
+}
+
+// Confirm asks the user to type expected exactly, guarding against accidental
+// confirmation of destructive or costly operations.
+func (p *Prompter) Confirm(label, expected string) error {
+	answer, err := p.ask(label + ": ")
+	if err != nil {
+		return err
+	}
+	if answer != expected {
+		return ErrNotConfirmed
+	}
+	return nil
+}
+
+func (p *Prompter) ask(question string) (string, error) {
+	fmt.Fprint(p.out, question)
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
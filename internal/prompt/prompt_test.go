@@ -0,0 +1,66 @@
+package prompt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	var out bytes.Buffer
+	p := New(strings.NewReader("abc\n5\n2\n"), &out)
+	options := []Option{
+		{Label: "dev", Value: "ocid1.compartment.oc1..dev"},
+		{Label: "prod", Value: "ocid1.compartment.oc1..prod"},
+	}
+
+	value, err := p.Select("OCI compartment", options)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if value != "ocid1.compartment.oc1..prod" {
+		t.Errorf("Expected prod compartment, got '%s'", value)
+	}
+	if strings.Count(out.String(), "Invalid selection") != 2 {
+		t.Errorf("Expected two invalid selections to be reported, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), " 1) dev") || !strings.Contains(out.String(), " 2) prod") {
+		t.Errorf("Expected numbered options, got:\n%s", out.String())
+	}
+}
+
+func TestSelectNoOptions(t *testing.T) {
+	p := New(strings.NewReader("1\n"), &bytes.Buffer{})
+	if _, err := p.Select("OCI subnet", nil); !errors.Is(err, ErrNoOptions) {
+		t.Errorf("Expected ErrNoOptions, got %v", err)
+	}
+}
+
+func TestInput(t *testing.T) {
+	p := New(strings.NewReader("\n  my-rg  \n"), &bytes.Buffer{})
+	value, err := p.Input("Azure resource group")
+	if err != nil {
+		t.Fatalf("Input failed: %v", err)
+	}
+	if value != "my-rg" {
+		t.Errorf("Expected 'my-rg', got '%s'", value)
+	}
+}
+
+func TestInputEOF(t *testing.T) {
+	p := New(strings.NewReader(""), &bytes.Buffer{})
+	if _, err := p.Input("Azure resource group"); err == nil {
+		t.Error("Expected error at end of input")
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	p := New(strings.NewReader("vm1\nvm2\n"), &bytes.Buffer{})
+	if err := p.Confirm("Type 'vm1' to continue", "vm1"); err != nil {
+		t.Errorf("Expected confirmation, got: %v", err)
+	}
+	if err := p.Confirm("Type 'vm1' to continue", "vm1"); !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("Expected ErrNotConfirmed, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+// Package retry provides the backoff policy shared by the Azure and OCI clients, so that
+// transient throttling and server errors do not abort a migration that runs for hours.
+package retry
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of the shared policy.
+const (
+	DefaultMaxAttempts = 8
+	DefaultBaseDelay   = time.Second
+	DefaultMaxDelay    = 60 * time.Second
+	DefaultBudget      = 500
+)
+
+// Policy retries transient failures with exponential backoff and full jitter. Retries
+// of all calls using the policy are taken from a shared budget, so that an outage of a
+// service fails the run instead of retrying every call for minutes.
+type Policy struct {
+	MaxAttempts int           // Attempts per call, including the first
+	BaseDelay   time.Duration // Delay before the first retry, doubled for each further retry
+	MaxDelay    time.Duration // Upper bound of the delay between attempts
+	budget      *atomic.Int64 // Retries left in the run; nil for an unlimited budget
+}
+
+// New returns a policy allowing budget retries in total; a budget of zero or less is
+// unlimited.
+func New(maxAttempts int, baseDelay, maxDelay time.Duration, budget int) *Policy {
+	p := &Policy{MaxAttempts: max(maxAttempts, 1), BaseDelay: baseDelay, MaxDelay: max(maxDelay, baseDelay)}
+	if budget > 0 {
+		p.budget = &atomic.Int64{}
+		p.budget.Store(int64(budget))
+	}
+	return p
+}
+
+// Delay returns the randomized delay before attempt (2 for the first retry).
+func (p *Policy) Delay(attempt int) time.Duration {
+	delay := p.MaxDelay
+	if shift := attempt - 2; shift < 30 {
+		delay = min(p.BaseDelay<<max(shift, 0), p.MaxDelay)
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// Allow reports whether a call that failed on attempt may be retried, taking the retry
+// from the budget.
+func (p *Policy) Allow(attempt int) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	if p.budget == nil {
+		return true
+	}
+	return p.budget.Add(-1) >= 0
+}
+
+// Remaining returns the retries left in the budget, or -1 for an unlimited budget.
+func (p *Policy) Remaining() int64 {
+	if p.budget == nil {
+		return -1
+	}
+	return max(p.budget.Load(), 0)
+}
+
+// RetryableStatus reports whether an HTTP status is transient: 408, 429, and 5xx except
+// 501 Not Implemented and 505 HTTP Version Not Supported.
+func RetryableStatus(status int) bool {
+	switch {
+	case status == http.StatusRequestTimeout, status == http.StatusTooManyRequests:
+		return true
+	case status == http.StatusNotImplemented, status == http.StatusHTTPVersionNotSupported:
+		return false
+	default:
+		return status >= http.StatusInternalServerError
+	}
+}
+
+var (
+	mu      sync.RWMutex
+	current = New(DefaultMaxAttempts, DefaultBaseDelay, DefaultMaxDelay, DefaultBudget)
+)
+
+// Configure replaces the policy returned by Default.
+func Configure(p *Policy) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = p
+}
+
+// Default returns the policy used by the cloud clients.
+func Default() *Policy {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
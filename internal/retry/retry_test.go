@@ -0,0 +1,65 @@
+package retry
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	p := New(8, time.Second, 60*time.Second, 0)
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{2, 500 * time.Millisecond, time.Second},
+		{3, time.Second, 2 * time.Second},
+		{5, 4 * time.Second, 8 * time.Second},
+		{9, 30 * time.Second, 60 * time.Second},
+		{100, 30 * time.Second, 60 * time.Second},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			if d := p.Delay(tt.attempt); d < tt.min || d > tt.max {
+				t.Fatalf("Delay(%d) = %s, want between %s and %s", tt.attempt, d, tt.min, tt.max)
+			}
+		}
+	}
+}
+
+func TestAllow(t *testing.T) {
+	p := New(3, time.Second, time.Minute, 2)
+	if !p.Allow(1) || !p.Allow(2) {
+		t.Fatal("Allow() refused retries within the attempts and budget")
+	}
+	if p.Allow(3) {
+		t.Error("Allow() retried after the maximum attempts")
+	}
+	if p.Remaining() != 0 || p.Allow(1) {
+		t.Errorf("Allow() retried with an exhausted budget (remaining %d)", p.Remaining())
+	}
+
+	unlimited := New(0, time.Second, time.Minute, 0)
+	if unlimited.MaxAttempts != 1 || unlimited.Allow(1) || unlimited.Remaining() != -1 {
+		t.Errorf("New() with zero attempts = %+v, want a single attempt and an unlimited budget", unlimited)
+	}
+}
+
+func TestRetryableStatus(t *testing.T) {
+	tests := map[int]bool{
+		http.StatusOK:                      false,
+		http.StatusNotFound:                false,
+		http.StatusConflict:                false,
+		http.StatusRequestTimeout:          true,
+		http.StatusTooManyRequests:         true,
+		http.StatusInternalServerError:     true,
+		http.StatusNotImplemented:          false,
+		http.StatusServiceUnavailable:      true,
+		http.StatusHTTPVersionNotSupported: false,
+	}
+	for status, want := range tests {
+		if got := RetryableStatus(status); got != want {
+			t.Errorf("RetryableStatus(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
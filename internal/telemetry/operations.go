@@ -0,0 +1,49 @@
+package telemetry
+
+import (
+	"sort"
+	"sync"
+)
+
+// Clouds whose API operations are recorded.
+const (
+	CloudAzure = "azure"
+	CloudOCI   = "oci"
+)
+
+var (
+	operationsMu sync.Mutex
+	operations   = map[string]map[string]bool{}
+)
+
+// RecordOperation records that the API operation of cloud was invoked, whatever its
+// outcome, so that the permissions a run needed can be reported at its end.
+func RecordOperation(cloud, operation string) {
+	operationsMu.Lock()
+	defer operationsMu.Unlock()
+	if operations[cloud] == nil {
+		operations[cloud] = map[string]bool{}
+	}
+	operations[cloud][operation] = true
+}
+
+// Operations returns the sorted API operations recorded per cloud.
+func Operations() map[string][]string {
+	operationsMu.Lock()
+	defer operationsMu.Unlock()
+	result := make(map[string][]string, len(operations))
+	for cloud, ops := range operations {
+		for op := range ops {
+			result[cloud] = append(result[cloud], op)
+		}
+		sort.Strings(result[cloud])
+	}
+	return result
+}
+
+// ResetOperations forgets the recorded API operations.
+func ResetOperations() {
+	operationsMu.Lock()
+	defer operationsMu.Unlock()
+	operations = map[string]map[string]bool{}
+}
@@ -0,0 +1,119 @@
+// Package telemetry exports OpenTelemetry traces and metrics of migration runs via OTLP.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/codebypatrickleung/kopru-cli"
+	serviceName         = "kopru"
+)
+
+// Instruments are created from the global providers, which forward to the
+// providers installed by Setup. Without Setup they are no-ops.
+var (
+	tracer = otel.Tracer(instrumentationName)
+	meter  = otel.Meter(instrumentationName)
+
+	stepDuration    = must(meter.Float64Histogram("kopru.step.duration", metric.WithUnit("s"), metric.WithDescription("Duration of workflow steps")))
+	uploadedBytes   = must(meter.Int64Counter("kopru.upload.bytes", metric.WithUnit("By"), metric.WithDescription("Bytes uploaded to object storage")))
+	downloadedBytes = must(meter.Int64Counter("kopru.download.bytes", metric.WithUnit("By"), metric.WithDescription("Bytes downloaded from the source platform")))
+	retries         = must(meter.Int64Counter("kopru.retries", metric.WithDescription("Retried cloud provider requests and transfers")))
+)
+
+func must[T any](instrument T, err error) T {
+	if err != nil {
+		otel.Handle(err)
+	}
+	return instrument
+}
+
+// Setup installs OTLP/HTTP exporters for traces and metrics sending to endpoint,
+// the base URL of an OpenTelemetry collector (e.g. http://localhost:4318).
+// Telemetry stays disabled when endpoint is empty. The returned function flushes
+// and stops the exporters and must be called before the process exits.
+func Setup(ctx context.Context, endpoint, version string) (func(context.Context) error, error) {
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+		attribute.String("service.version", version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telemetry resource: %w", err)
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint+"/v1/traces"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	metricExporter, err := otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpointURL(endpoint+"/v1/metrics"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(traceExporter), sdktrace.WithResource(res))
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)), sdkmetric.WithResource(res))
+	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
+
+	return func(ctx context.Context) error {
+		return errors.Join(tp.Shutdown(ctx), mp.Shutdown(ctx))
+	}, nil
+}
+
+// StartSpan starts a span as a child of the span in ctx, if any.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan ends span, marking it as failed when err is not nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// RecordStep records the duration and outcome of a workflow step.
+func RecordStep(ctx context.Context, step, status string, duration time.Duration) {
+	stepDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(
+		attribute.String("step", step),
+		attribute.String("status", status),
+	))
+}
+
+// AddUploadedBytes records n bytes uploaded to object storage.
+func AddUploadedBytes(ctx context.Context, n int64) {
+	uploadedBytes.Add(ctx, n)
+}
+
+// AddDownloadedBytes records n bytes downloaded from the source platform.
+func AddDownloadedBytes(ctx context.Context, n int64) {
+	downloadedBytes.Add(ctx, n)
+}
+
+// AddRetries records n retries of a request or transfer of the given operation.
+func AddRetries(ctx context.Context, operation string, n int64) {
+	retries.Add(ctx, n, metric.WithAttributes(attribute.String("operation", operation)))
+}
@@ -0,0 +1,93 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetupDisabledWithoutEndpoint(t *testing.T) {
+	shutdown, err := Setup(context.Background(), "", "test")
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+}
+
+func TestInstrumentation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	ctx, parent := StartSpan(context.Background(), "kopru.run")
+	_, step := StartSpan(ctx, "Exporting OS Disk")
+	EndSpan(step, errors.New("snapshot failed"))
+	EndSpan(parent, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 ended spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "Exporting OS Disk" || spans[0].Status().Code != codes.Error {
+		t.Errorf("Expected failed step span, got %q with status %v", spans[0].Name(), spans[0].Status())
+	}
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Error("Expected step span to be a child of the run span")
+	}
+	if spans[1].Status().Code == codes.Error {
+		t.Error("Expected run span without error status")
+	}
+
+	RecordStep(ctx, "Exporting OS Disk", "succeeded", 90*time.Second)
+	AddUploadedBytes(ctx, 1024)
+	AddUploadedBytes(ctx, 2048)
+	AddRetries(ctx, "azure", 2)
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	metrics := map[string]metricdata.Aggregation{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	if h, ok := metrics["kopru.step.duration"].(metricdata.Histogram[float64]); !ok || len(h.DataPoints) != 1 || h.DataPoints[0].Sum != 90 {
+		t.Errorf("Unexpected step duration metric: %#v", metrics["kopru.step.duration"])
+	}
+	if s, ok := metrics["kopru.upload.bytes"].(metricdata.Sum[int64]); !ok || len(s.DataPoints) != 1 || s.DataPoints[0].Value != 3072 {
+		t.Errorf("Unexpected upload bytes metric: %#v", metrics["kopru.upload.bytes"])
+	}
+	if s, ok := metrics["kopru.retries"].(metricdata.Sum[int64]); !ok || len(s.DataPoints) != 1 || s.DataPoints[0].Value != 2 {
+		t.Errorf("Unexpected retries metric: %#v", metrics["kopru.retries"])
+	}
+}
+
+func TestOperations(t *testing.T) {
+	ResetOperations()
+	defer ResetOperations()
+	RecordOperation(CloudOCI, "objectstorage:PutObject")
+	RecordOperation(CloudOCI, "core:ListImages")
+	RecordOperation(CloudOCI, "objectstorage:PutObject")
+	RecordOperation(CloudAzure, "Microsoft.Compute/disks/read")
+
+	ops := Operations()
+	if got := ops[CloudOCI]; len(got) != 2 || got[0] != "core:ListImages" || got[1] != "objectstorage:PutObject" {
+		t.Errorf("OCI operations = %v", got)
+	}
+	if got := ops[CloudAzure]; len(got) != 1 || got[0] != "Microsoft.Compute/disks/read" {
+		t.Errorf("Azure operations = %v", got)
+	}
+}
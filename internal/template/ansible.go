@@ -0,0 +1,147 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+)
+
+// ansibleDir is the directory of the template output that holds the Ansible files.
+const ansibleDir = "ansible"
+
+// Mount is a filesystem mounted by the source VM, as listed in its /etc/fstab.
+type Mount struct {
+	Source  string // Device, e.g. UUID=..., LABEL=... or /dev/sdc1
+	Path    string
+	FSType  string
+	Options string
+}
+
+// systemMountPoints are mounted from the OS disk, or from the Azure resource disk that
+// is not migrated, rather than from a data disk.
+var systemMountPoints = map[string]bool{"/": true, "/boot": true, "/boot/efi": true, "/mnt": true, "/mnt/resource": true}
+
+// virtualFSTypes are filesystems that are not stored on a disk, or not on a disk of the VM.
+var virtualFSTypes = map[string]bool{
+	"swap": true, "tmpfs": true, "devtmpfs": true, "proc": true, "sysfs": true, "devpts": true,
+	"none": true, "nfs": true, "nfs4": true, "cifs": true, "smb3": true, "fuse": true,
+}
+
+// ParseFstab returns the data disk mounts listed in the content of an /etc/fstab. The
+// filesystems of the OS disk, swap, virtual and network filesystems and bind mounts are
+// left out. As data disks are copied block by block, the UUID= and LABEL= sources of
+// the mounts are the same on the migrated instance.
+func ParseFstab(content string) []Mount {
+	var mounts []Mount
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		m := Mount{Source: fields[0], Path: fields[1], FSType: fields[2], Options: "defaults"}
+		if len(fields) > 3 {
+			m.Options = fields[3]
+		}
+		if !strings.HasPrefix(m.Path, "/") || systemMountPoints[m.Path] || strings.HasPrefix(m.Path, "/boot/") ||
+			virtualFSTypes[m.FSType] || strings.HasPrefix(m.FSType, "fuse.") || strings.Contains(","+m.Options+",", ",bind,") {
+			continue
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts
+}
+
+// SetMounts sets the data disk mounts of the source VM that the generated playbook
+// recreates on the instance.
+func (g *OCIGenerator) SetMounts(mounts []Mount) {
+	g.mounts = mounts
+}
+
+// generateAnsible writes a starter playbook for the configuration management of Linux
+// instances, with the data disk mounts of the source VM in group_vars/all.yml. The
+// inventory needs the IP addresses of the instance and is written after deployment,
+// from the ansible_inventory output.
+func (g *OCIGenerator) generateAnsible() error {
+	if !common.IsLinuxOS(g.config.OCIImageOS) || g.config.AttachesToInstance() {
+		return nil
+	}
+	dir := filepath.Join(g.templateOutputDir, ansibleDir)
+	if err := common.EnsureDir(filepath.Join(dir, "group_vars")); err != nil {
+		return fmt.Errorf("failed to create Ansible directory: %w", err)
+	}
+
+	playbook := fmt.Sprintf(`---
+# Starter playbook generated by Kopru for the instance migrated from %s.
+# Write the inventory after deployment and run the playbook from this directory:
+#
+#   %s -chdir=.. output -raw ansible_inventory > inventory.yml
+#   ansible-playbook -i inventory.yml playbook.yml
+#
+# The mount tasks need the ansible.posix collection (ansible-galaxy collection install ansible.posix).
+- name: Adopt the migrated instance
+  hosts: all
+  become: true
+  tasks:
+    - name: Wait for the instance to accept connections
+      ansible.builtin.wait_for_connection:
+        timeout: 600
+
+    - name: Mount the data disks at the mount points of the source VM
+      ansible.posix.mount:
+        src: "{{ item.src }}"
+        path: "{{ item.path }}"
+        fstype: "{{ item.fstype }}"
+        opts: "{{ item.opts }}"
+        state: mounted
+      loop: "{{ data_mounts }}"
+`, g.sourceName(), g.engine())
+	if err := os.WriteFile(filepath.Join(dir, "playbook.yml"), []byte(playbook), 0600); err != nil {
+		return fmt.Errorf("failed to write Ansible playbook: %w", err)
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n# Data disk mounts of the source VM, read from its /etc/fstab.\n")
+	if len(g.mounts) == 0 {
+		b.WriteString("data_mounts: []\n")
+	} else {
+		b.WriteString("data_mounts:\n")
+		for _, m := range g.mounts {
+			fmt.Fprintf(&b, "  - src: %q\n    path: %q\n    fstype: %q\n    opts: %q\n", m.Source, m.Path, m.FSType, m.Options)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "group_vars", "all.yml"), []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write Ansible variables: %w", err)
+	}
+	return nil
+}
+
+// sourceName returns the name of the source VM or image of the instance.
+func (g *OCIGenerator) sourceName() string {
+	if g.config.AzureComputeName != "" {
+		return g.config.AzureComputeName
+	}
+	return g.config.OCIImageName
+}
+
+// writeAnsibleInventory writes the inventory of the deployed instance to the Ansible
+// directory. A failure is only logged, as the instance is deployed.
+func (g *OCIGenerator) writeAnsibleInventory() {
+	dir := filepath.Join(g.templateOutputDir, ansibleDir)
+	if _, err := os.Stat(dir); err != nil {
+		return
+	}
+	out, err := common.RunCommand(g.engine(), "-chdir="+g.templateOutputDir, "output", "-raw", "ansible_inventory")
+	if err != nil {
+		g.logger.Warningf("Could not read the ansible_inventory output: %v", err)
+		return
+	}
+	path := filepath.Join(dir, "inventory.yml")
+	if err := os.WriteFile(path, []byte(out), 0600); err != nil {
+		g.logger.Warningf("Failed to write Ansible inventory: %v", err)
+		return
+	}
+	g.logger.Infof("Ansible inventory written to %s", path)
+}
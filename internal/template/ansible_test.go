@@ -0,0 +1,66 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestParseFstab(t *testing.T) {
+	fstab := `# /etc/fstab
+UUID=1111 /               ext4   defaults,discard 0 1
+UUID=2222 /boot           ext4   defaults 0 2
+UUID=3333 /boot/efi       vfat   umask=0077 0 1
+/dev/disk/cloud/azure_resource-part1 /mnt auto defaults,nofail,x-systemd.requires=cloud-init.service 0 2
+/swapfile none swap sw 0 0
+tmpfs /tmp tmpfs defaults 0 0
+UUID=4444 /data xfs defaults,nofail 0 2
+LABEL=logs  /var/log/app ext4
+/data/www /var/www none bind 0 0
+nfs01:/export /shared nfs4 defaults 0 0
+`
+	want := []Mount{
+		{Source: "UUID=4444", Path: "/data", FSType: "xfs", Options: "defaults,nofail"},
+		{Source: "LABEL=logs", Path: "/var/log/app", FSType: "ext4", Options: "defaults"},
+	}
+	if got := ParseFstab(fstab); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseFstab() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAnsibleGeneration(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{OCIInstanceName: "web-01", OCIImageOS: "Ubuntu", AzureComputeName: "web-01"}
+	gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", []string{"ocid1.volume.oc1..data"}, []string{"web-01-data-0"}, 50, 2, 8, "x86_64", tmpDir)
+	gen.SetMounts([]Mount{{Source: "UUID=4444", Path: "/data", FSType: "xfs", Options: "defaults,nofail"}})
+	if err := gen.GenerateTemplate(); err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	vars, err := os.ReadFile(filepath.Join(tmpDir, ansibleDir, "group_vars", "all.yml"))
+	if err != nil || !strings.Contains(string(vars), `- src: "UUID=4444"`) || !strings.Contains(string(vars), `path: "/data"`) {
+		t.Errorf("Unexpected group_vars/all.yml: %s, %v", vars, err)
+	}
+	playbook, err := os.ReadFile(filepath.Join(tmpDir, ansibleDir, "playbook.yml"))
+	if err != nil || !strings.Contains(string(playbook), "ansible.posix.mount") || !strings.Contains(string(playbook), "migrated from web-01") {
+		t.Errorf("Unexpected playbook.yml: %s, %v", playbook, err)
+	}
+	outputs, _ := os.ReadFile(filepath.Join(tmpDir, "outputs.tf"))
+	if !strings.Contains(string(outputs), `output "ansible_inventory"`) {
+		t.Error("Expected outputs.tf to define the ansible_inventory output")
+	}
+
+	windowsDir := t.TempDir()
+	cfg = &config.Config{OCIInstanceName: "win-01", OCIImageOS: "Windows"}
+	gen = NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 2, 8, "x86_64", windowsDir)
+	if err := gen.GenerateTemplate(); err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(windowsDir, ansibleDir)); !os.IsNotExist(err) {
+		t.Errorf("Expected no Ansible files for Windows, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	ocicommon "github.com/oracle/oci-go-sdk/v65/common"
+)
+
+// backendRequiredVersion is the oldest Terraform version whose s3 backend supports the
+// endpoints and use_path_style settings of the OCI Object Storage backend.
+const backendRequiredVersion = ">= 1.6.0"
+
+// Backend is the state backend block of provider.tf.
+type Backend struct {
+	Type     string
+	Settings []config.BackendSetting // Values are HCL expressions
+}
+
+// KeyWidth returns the length of the longest setting key, to align the settings.
+func (b *Backend) KeyWidth() int {
+	width := 0
+	for _, s := range b.Settings {
+		width = max(width, len(s.Key))
+	}
+	return width
+}
+
+// SetBackendNamespace sets the Object Storage namespace of the state bucket, when
+// TEMPLATE_BACKEND_NAMESPACE does not.
+func (g *OCIGenerator) SetBackendNamespace(namespace string) {
+	g.backendNamespace = namespace
+}
+
+// backend returns the backend block of TEMPLATE_BACKEND, or nil for the local backend.
+func (g *OCIGenerator) backend() (*Backend, error) {
+	switch g.config.TemplateBackend {
+	case "", config.BackendLocal:
+		return nil, nil
+	}
+	extra, err := g.config.TemplateBackendSettings()
+	if err != nil {
+		return nil, err
+	}
+	b := &Backend{Type: g.config.TemplateBackend}
+	if b.Type == config.BackendOCI {
+		if b.Settings, err = g.ociBackendSettings(); err != nil {
+			return nil, err
+		}
+		b.Type = "s3"
+	}
+	for _, s := range extra {
+		s.Value = hclValue(s.Value)
+		replaced := false
+		for i := range b.Settings {
+			if b.Settings[i].Key == s.Key {
+				b.Settings[i], replaced = s, true
+			}
+		}
+		if !replaced {
+			b.Settings = append(b.Settings, s)
+		}
+	}
+	return b, nil
+}
+
+// ociBackendSettings returns the settings of the s3 backend that keep the state in an
+// OCI Object Storage bucket through the S3-compatible API. The engine authenticates with
+// a customer secret key, from AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+func (g *OCIGenerator) ociBackendSettings() ([]config.BackendSetting, error) {
+	namespace := g.config.TemplateBackendNamespace
+	if namespace == "" {
+		namespace = g.backendNamespace
+	}
+	if namespace == "" {
+		return nil, fmt.Errorf("the Object Storage namespace of the state bucket is unknown; set TEMPLATE_BACKEND_NAMESPACE")
+	}
+	key := g.config.TemplateBackendKey
+	if key == "" {
+		key = g.config.OCIInstanceName + "/terraform.tfstate"
+	}
+	region := g.config.OCIRegion
+	endpoint := fmt.Sprintf("https://%s.compat.objectstorage.%s.%s", namespace, region, ocicommon.StringToRegion(region).SecondLevelDomain())
+	return []config.BackendSetting{
+		{Key: "bucket", Value: strconv.Quote(g.config.TemplateBackendBucket)},
+		{Key: "key", Value: strconv.Quote(key)},
+		{Key: "region", Value: strconv.Quote(region)},
+		{Key: "endpoints", Value: fmt.Sprintf("{ s3 = %q }", endpoint)},
+		{Key: "skip_region_validation", Value: "true"},
+		{Key: "skip_credentials_validation", Value: "true"},
+		{Key: "skip_requesting_account_id", Value: "true"},
+		{Key: "skip_metadata_api_check", Value: "true"},
+		{Key: "skip_s3_checksum", Value: "true"},
+		{Key: "use_path_style", Value: "true"},
+	}, nil
+}
+
+// hclValue returns value of TEMPLATE_BACKEND_CONFIG as an HCL expression: booleans and
+// integers as they are, and anything else as a string.
+func hclValue(value string) string {
+	if value == "true" || value == "false" {
+		return value
+	}
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// checkBackendCredentials warns when the state is kept in OCI Object Storage and the
+// engine has no S3 credentials, which fails init.
+func (g *OCIGenerator) checkBackendCredentials() {
+	if g.config.TemplateBackend != config.BackendOCI || os.Getenv("AWS_ACCESS_KEY_ID") != "" || os.Getenv("AWS_PROFILE") != "" {
+		return
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if _, err := os.Stat(filepath.Join(home, ".aws", "credentials")); err == nil {
+			return
+		}
+	}
+	g.logger.Warning("TEMPLATE_BACKEND=oci needs a customer secret key in AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY to access the state bucket")
+}
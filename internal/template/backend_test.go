@@ -0,0 +1,119 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestBackendGeneration(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.Config
+		namespace string
+		expected  []string
+		absent    []string
+		wantErr   bool
+	}{
+		{
+			name:   "local backend",
+			cfg:    config.Config{TemplateBackend: config.BackendLocal},
+			absent: []string{"backend "},
+		},
+		{
+			name:      "OCI Object Storage",
+			cfg:       config.Config{TemplateBackend: config.BackendOCI, TemplateBackendBucket: "tfstate", IaCEngine: EngineTerraform},
+			namespace: "axaxnpcrorw5",
+			expected: []string{
+				`backend "s3" {`,
+				`bucket                      = "tfstate"`,
+				`key                         = "web/terraform.tfstate"`,
+				`endpoints                   = { s3 = "https://axaxnpcrorw5.compat.objectstorage.us-ashburn-1.oraclecloud.com" }`,
+				`use_path_style              = true`,
+				`required_version = ">= 1.6.0"`,
+			},
+		},
+		{
+			name:      "OCI Object Storage with overrides",
+			cfg:       config.Config{TemplateBackend: config.BackendOCI, TemplateBackendBucket: "tfstate", TemplateBackendNamespace: "ns", TemplateBackendKey: "migrations/web.tfstate", TemplateBackendConfig: "use_lockfile=true,region=us-phoenix-1"},
+			namespace: "ignored",
+			expected: []string{
+				`key                         = "migrations/web.tfstate"`,
+				`region                      = "us-phoenix-1"`,
+				`endpoints                   = { s3 = "https://ns.compat.`,
+				`use_lockfile                = true`,
+				`required_version = ">= 1.6.0"`,
+			},
+			absent: []string{"ignored", `"us-ashburn-1"`},
+		},
+		{
+			name: "other backend",
+			cfg:  config.Config{TemplateBackend: "http", TemplateBackendConfig: "address=https://state.example.com/web, retry_max=5"},
+			expected: []string{
+				`backend "http" {`,
+				`address   = "https://state.example.com/web"`,
+				`retry_max = 5`,
+			},
+		},
+		{
+			name:    "unknown namespace",
+			cfg:     config.Config{TemplateBackend: config.BackendOCI, TemplateBackendBucket: "tfstate"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := tt.cfg
+			cfg.OCIInstanceName = "web"
+			cfg.OCIRegion = "us-ashburn-1"
+			gen := NewOCIGenerator(&cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 0, 0, "x86_64", tmpDir)
+			gen.SetBackendNamespace(tt.namespace)
+			err := gen.GenerateTemplate()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected GenerateTemplate to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateTemplate failed: %v", err)
+			}
+			content, err := os.ReadFile(filepath.Join(tmpDir, "provider.tf"))
+			if err != nil {
+				t.Fatalf("Failed to read provider.tf: %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("Expected provider.tf to contain %q, got:\n%s", want, content)
+				}
+			}
+			for _, unwanted := range tt.absent {
+				if strings.Contains(string(content), unwanted) {
+					t.Errorf("Expected provider.tf not to contain %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestHCLValue(t *testing.T) {
+	tests := map[string]string{
+		"true":        "true",
+		"5":           "5",
+		"t":           `"t"`,
+		"1.5":         `"1.5"`,
+		`say "hi"`:    `"say \"hi\""`,
+		"https://a/b": `"https://a/b"`,
+	}
+	for value, want := range tests {
+		if got := hclValue(value); got != want {
+			t.Errorf("hclValue(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Infrastructure as code engines that deploy the generated template, by binary name.
+const (
+	EngineOpenTofu  = "tofu"
+	EngineTerraform = "terraform"
+)
+
+// lockFileName is the dependency lock file written by init.
+const lockFileName = ".terraform.lock.hcl"
+
+// engineRegistries are the registries each engine installs the OCI provider from. Each
+// engine records the provider under the address of its registry in the lock file.
+var engineRegistries = map[string]string{
+	EngineOpenTofu:  "registry.opentofu.org",
+	EngineTerraform: "registry.terraform.io",
+}
+
+// engineRequiredVersions are the oldest versions of each engine that support the
+// generated template: terraform_data resources and workspace select -or-create came
+// with Terraform 1.4, and OpenTofu supports both since its first release.
+var engineRequiredVersions = map[string]string{
+	EngineOpenTofu:  ">= 1.6.0",
+	EngineTerraform: ">= 1.4.0",
+}
+
+// Engine returns the binary of the configured engine, defaulting to OpenTofu.
+func Engine(engine string) string {
+	if engine == EngineTerraform {
+		return EngineTerraform
+	}
+	return EngineOpenTofu
+}
+
+// EngineName returns the product name of engine.
+func EngineName(engine string) string {
+	if Engine(engine) == EngineTerraform {
+		return "Terraform"
+	}
+	return "OpenTofu"
+}
+
+// engine returns the binary of the engine that deploys the template.
+func (g *OCIGenerator) engine() string {
+	return Engine(g.config.IaCEngine)
+}
+
+// checkLockFile removes the dependency lock file of the template directory when it was
+// written by another engine than the one deploying the template, e.g. when a template
+// first deployed with OpenTofu is deployed again with Terraform. Its provider entries
+// name the registry of the other engine, and their checksums do not match the packages
+// of this engine's registry, so init would fail; init writes a new lock file instead.
+func (g *OCIGenerator) checkLockFile() error {
+	path := filepath.Join(g.templateOutputDir, lockFileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read %s: %w", lockFileName, err)
+	}
+	for engine, registry := range engineRegistries {
+		if engine == g.engine() || !strings.Contains(string(data), `provider "`+registry+"/") {
+			continue
+		}
+		g.logger.Warningf("%s was written by %s; replacing it with the providers of %s", lockFileName, EngineName(engine), EngineName(g.engine()))
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", lockFileName, err)
+		}
+		return nil
+	}
+	return nil
+}
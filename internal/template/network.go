@@ -0,0 +1,24 @@
+package template
+
+// Network is the network topology of the source VM that the instance reconstructs
+// beyond its primary VNIC in OCI_SUBNET_ID.
+type Network struct {
+	PrimaryPrivateIP    string   // Private IP of the primary network interface of the source VM
+	SecondaryPrivateIPs []string // Secondary private IPs of the primary VNIC
+	VNICs               []VNIC   // Secondary VNICs
+}
+
+// VNIC is a secondary VNIC of the instance, reconstructed from a secondary network
+// interface of the source VM.
+type VNIC struct {
+	Name                string
+	SubnetID            string // OCID of the OCI subnet the Azure subnet is mapped to
+	PrivateIP           string
+	SecondaryPrivateIPs []string
+}
+
+// SetNetwork sets the secondary VNICs and private IPs of the source VM that the
+// template reconstructs on the instance.
+func (g *OCIGenerator) SetNetwork(network Network) {
+	g.network = network
+}
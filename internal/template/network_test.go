@@ -0,0 +1,71 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestSecondaryVNICs(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{OCIInstanceName: "web", OCIPreservePrivateIPs: true, TemplateEnvironments: "dev"}
+	gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 2, 8, "x86_64", tmpDir)
+	gen.SetNetwork(Network{
+		PrimaryPrivateIP:    "10.0.1.4",
+		SecondaryPrivateIPs: []string{"10.0.1.5"},
+		VNICs: []VNIC{
+			{Name: "web-nic-db", SubnetID: "ocid1.subnet.oc1.iad.db", PrivateIP: "10.0.2.4", SecondaryPrivateIPs: []string{"10.0.2.5", "10.0.2.6"}},
+		},
+	})
+	if err := gen.GenerateTemplate(); err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	tfvars, err := os.ReadFile(filepath.Join(tmpDir, "terraform.tfvars"))
+	if err != nil {
+		t.Fatalf("Failed to read terraform.tfvars: %v", err)
+	}
+	want := `preserve_private_ips       = true
+primary_private_ip         = "10.0.1.4"
+primary_vnic_secondary_ips = [
+  "10.0.1.5"
+]
+secondary_vnics = [
+  {
+    display_name          = "web-nic-db"
+    subnet_id             = "ocid1.subnet.oc1.iad.db"
+    private_ip            = "10.0.2.4"
+    secondary_private_ips = ["10.0.2.5", "10.0.2.6"]
+  },
+]
+
+fault_domain`
+	if !strings.Contains(string(tfvars), want) {
+		t.Errorf("Expected terraform.tfvars to contain:\n%s\ngot:\n%s", want, tfvars)
+	}
+	mainTf, err := os.ReadFile(filepath.Join(tmpDir, "main.tf"))
+	if err != nil {
+		t.Fatalf("Failed to read main.tf: %v", err)
+	}
+	for _, want := range []string{
+		`resource "oci_core_vnic_attachment" "secondary_vnics"`,
+		`resource "oci_core_private_ip" "primary_vnic_secondary_ips"`,
+		`resource "oci_core_private_ip" "secondary_vnic_ips"`,
+	} {
+		if !strings.Contains(string(mainTf), want) {
+			t.Errorf("Expected main.tf to contain %q", want)
+		}
+	}
+	devTfvars, err := os.ReadFile(filepath.Join(tmpDir, "dev.tfvars"))
+	if err != nil {
+		t.Fatalf("Failed to read dev.tfvars: %v", err)
+	}
+	for _, want := range []string{"secondary_vnics = []", "preserve_private_ips = false"} {
+		if !strings.Contains(string(devTfvars), want) {
+			t.Errorf("Expected dev.tfvars to contain %q, got:\n%s", want, devTfvars)
+		}
+	}
+}
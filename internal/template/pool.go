@@ -0,0 +1,54 @@
+package template
+
+// InstancePool is the OCI instance pool of the instances migrated from the VMs of an
+// Azure availability set or VM scale set.
+type InstancePool struct {
+	Name        string
+	InstanceIDs []string // The first instance is the source of the instance configuration
+}
+
+// poolPrefix is the prefix of the templates rendered instead of those of templateFiles
+// for an instance pool. The generated files are named without it.
+const poolPrefix = "pool-"
+
+// poolTemplateFiles are the embedded templates of OCI_INSTANCE_POOL, in the order they
+// are rendered.
+var poolTemplateFiles = []string{
+	"provider.tf.tmpl",
+	poolPrefix + "variables.tf.tmpl",
+	poolPrefix + "main.tf.tmpl",
+	poolPrefix + "outputs.tf.tmpl",
+	poolPrefix + "terraform.tfvars.tmpl",
+	poolPrefix + "README.md.tmpl",
+}
+
+// SetInstancePool makes the generator render the templates of the instance pool of
+// existing instances instead of those of an instance.
+func (g *OCIGenerator) SetInstancePool(pool InstancePool) {
+	g.pool = &pool
+}
+
+// poolTemplateData returns the data the instance pool templates are executed with.
+func (g *OCIGenerator) poolTemplateData() (*TemplateData, error) {
+	backend, err := g.backend()
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateData{
+		Engine:          g.engine(),
+		EngineName:      EngineName(g.engine()),
+		RequiredVersion: g.requiredVersion(),
+		SessionProfile:  g.sessionProfile,
+		CLIAuth:         g.cliAuth(),
+		Backend:         backend,
+
+		CompartmentID:      g.config.OCICompartmentID,
+		SubnetID:           g.config.OCISubnetID,
+		Region:             g.config.OCIRegion,
+		AvailabilityDomain: g.availabilityDomain(),
+		InstanceName:       g.pool.Name,
+		InstancePool:       g.pool,
+
+		Config: g.config,
+	}, nil
+}
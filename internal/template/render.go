@@ -0,0 +1,414 @@
+package template
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+// templateSuffix is the suffix of template files; the generated file is named without it.
+const templateSuffix = ".tmpl"
+
+// environmentTemplate is rendered once per template environment, to <env>.tfvars.
+const environmentTemplate = "environment.tfvars" + templateSuffix
+
+//go:embed templates/*.tmpl
+var embeddedTemplates embed.FS
+
+// templateFiles are the embedded templates, in the order they are rendered.
+var templateFiles = []string{
+	"provider.tf.tmpl",
+	"variables.tf.tmpl",
+	"main.tf.tmpl",
+	"outputs.tf.tmpl",
+	"terraform.tfvars.tmpl",
+	environmentTemplate,
+	"README.md.tmpl",
+}
+
+// attachPrefix is the prefix of the templates rendered instead of those of templateFiles
+// when the data volumes are attached to an existing instance. The generated files are
+// named without it.
+const attachPrefix = "attach-"
+
+// attachTemplateFiles are the embedded templates of OCI_TARGET_INSTANCE_ID, in the order
+// they are rendered.
+var attachTemplateFiles = []string{
+	"provider.tf.tmpl",
+	attachPrefix + "variables.tf.tmpl",
+	attachPrefix + "main.tf.tmpl",
+	attachPrefix + "outputs.tf.tmpl",
+	attachPrefix + "terraform.tfvars.tmpl",
+	attachPrefix + "README.md.tmpl",
+}
+
+// TemplateData is the data the templates are executed with. Templates of TEMPLATE_DIR
+// can use these fields, e.g. {{.InstanceName}}, and any configuration value through
+// Config, e.g. {{.Config.OCIRegion}}.
+type TemplateData struct {
+	Engine           string        // Binary of the engine deploying the template: tofu or terraform
+	EngineName       string        // OpenTofu or Terraform
+	RequiredVersion  string        // Oldest engine version that supports the template
+	SessionProfile   string        // OCI CLI profile of session token authentication, if used
+	CLIAuth          string        // OCI CLI arguments of the session token authentication, if used
+	Backend          *Backend      // State backend, or nil for the local backend
+	TargetInstanceID string        // Existing instance the data volumes are attached to, if any
+	InstancePool     *InstancePool // Instance pool of existing instances, if rendered
+
+	CompartmentID      string
+	SubnetID           string
+	Region             string
+	AvailabilityDomain string
+	ImageID            string // OCID of the imported custom image
+	InstanceName       string
+	InstanceState      string
+	Shape              string
+	OCPUs              int32
+	MemoryGB           int32  // 0 for the default memory of the shape
+	Baseline           string // Baseline OCPU utilization of a burstable instance, e.g. BASELINE_1_2
+
+	BootVolumeSizeGB       int64
+	BootVolumeVPUsPerGB    int
+	BootVolumeAutoTune     bool
+	DataVolumeAutoTune     bool
+	BootVolumeBackupPolicy string
+	DataVolumeBackupPolicy string
+	DataDiskVolumeIDs      []string
+	DataDiskNames          []string
+
+	SourceImage        string
+	SourceCPUs         int32
+	SourceMemoryGB     int32
+	SourceArchitecture string
+	SSHPublicKey       string
+	UserData           string // Base64-encoded cloud-init user data
+
+	NSGIDs        []string
+	FaultDomain   string
+	HostnameLabel string
+	DefinedTags   map[string]string // Values by namespace.key
+	SourceTags    map[string]string // Freeform tags carried over from the source VM
+
+	Network            Network // Secondary VNICs and private IPs of the source VM
+	PreservePrivateIPs bool
+
+	UEFI                         bool // Whether the image boots with UEFI firmware
+	UEFISchemaData               string
+	ImageCapabilitySchemaVersion string
+	ARM64                        bool
+	ARM64Shape                   string
+	RunCommand                   bool // Whether the Run Command plugin runs the finishing script
+	RunCommandPlugin             string
+	AgentPlugins                 map[string]string // Desired state of the Oracle Cloud Agent plugins by name
+
+	Environment config.TemplateEnvironment // Environment of environment.tfvars.tmpl
+	Config      *config.Config
+}
+
+// templateFuncs are the functions available to the templates.
+var templateFuncs = texttemplate.FuncMap{
+	"list":  formatTemplateList,
+	"quote": hclQuote,
+}
+
+// hclQuote returns s as an HCL string literal, in which template sequences are escaped
+// so that values from the source, e.g. tags, are not interpolated.
+func hclQuote(s string) string {
+	s = strings.NewReplacer("${", "$${", "%{", "%%{").Replace(s)
+	return strconv.Quote(s)
+}
+
+// instanceFreeformTags are the freeform tags terraform.tfvars sets on the instance, which
+// take precedence over the tags of the source VM.
+var instanceFreeformTags = []string{"created-by", "source-image", "source-cpus", "source-memory-gb", "source-architecture"}
+
+// requiredVersion returns the oldest engine version that supports the template.
+func (g *OCIGenerator) requiredVersion() string {
+	if g.config.TemplateBackend == config.BackendOCI && g.engine() == EngineTerraform {
+		return backendRequiredVersion
+	}
+	return engineRequiredVersions[g.engine()]
+}
+
+// cliAuth returns the OCI CLI arguments of the session token authentication, if used.
+func (g *OCIGenerator) cliAuth() string {
+	if g.sessionProfile == "" {
+		return ""
+	}
+	return " --auth security_token --profile " + g.sessionProfile
+}
+
+// availabilityDomain returns the OCI_AVAILABILITY_DOMAIN number of the instance.
+func (g *OCIGenerator) availabilityDomain() string {
+	if g.config.OCIAvailabilityDomain == "" {
+		return DefaultAvailabilityDomain
+	}
+	return g.config.OCIAvailabilityDomain
+}
+
+// templateData returns the data the templates of the generator are executed with.
+func (g *OCIGenerator) templateData() (*TemplateData, error) {
+	backend, err := g.backend()
+	if err != nil {
+		return nil, err
+	}
+	// Boot volume size: max of 50GB or the source Azure VM boot disk size
+	bootVolumeSize := max(int64(50), g.bootVolumeSizeGB)
+	shape := g.selectOCIShape()
+	ocpus, memoryGB := g.calculateOCIResources(shape)
+
+	// Read SSH public key from OCI_SSH_PUBLIC_KEY or the key file if provided
+	sshPublicKey, err := g.config.SSHPublicKey()
+	if err != nil {
+		g.logger.Warningf("%v. SSH key will not be configured.", err)
+	} else if sshPublicKey != "" {
+		g.logger.Info("SSH public key will be added to the instance metadata")
+	}
+	userData, err := g.config.UserData()
+	if err != nil {
+		return nil, err
+	}
+	if userData != "" {
+		g.logger.Info("Cloud-init user data will be added to the instance metadata")
+	}
+
+	return &TemplateData{
+		Engine:           g.engine(),
+		EngineName:       EngineName(g.engine()),
+		RequiredVersion:  g.requiredVersion(),
+		SessionProfile:   g.sessionProfile,
+		CLIAuth:          g.cliAuth(),
+		TargetInstanceID: g.config.OCITargetInstanceID,
+		Backend:          backend,
+
+		CompartmentID:      g.config.OCICompartmentID,
+		SubnetID:           g.config.OCISubnetID,
+		Region:             g.config.OCIRegion,
+		AvailabilityDomain: g.availabilityDomain(),
+		ImageID:            g.importedImageID,
+		InstanceName:       g.config.OCIInstanceName,
+		InstanceState:      g.config.InstanceState(),
+		Shape:              shape,
+		OCPUs:              ocpus,
+		MemoryGB:           memoryGB,
+		Baseline:           g.burstableBaseline(shape),
+
+		BootVolumeSizeGB:       bootVolumeSize,
+		BootVolumeVPUsPerGB:    g.config.BootVolumeVPUsPerGB(),
+		BootVolumeAutoTune:     g.config.OCIBootVolumeAutoTune,
+		DataVolumeAutoTune:     g.config.OCIDataVolumeAutoTune,
+		BootVolumeBackupPolicy: g.config.OCIBootVolumeBackupPolicy,
+		DataVolumeBackupPolicy: g.config.OCIDataVolumeBackupPolicy,
+		DataDiskVolumeIDs:      g.dataDiskVolumeIDs,
+		DataDiskNames:          g.dataDiskVolumeNames,
+
+		SourceImage:        g.config.OCIImageName,
+		SourceCPUs:         g.vmCPUs,
+		SourceMemoryGB:     g.vmMemoryGB,
+		SourceArchitecture: g.vmArchitecture,
+		SSHPublicKey:       sshPublicKey,
+		UserData:           userData,
+
+		NSGIDs:        g.config.NSGIDs(),
+		FaultDomain:   g.config.OCIFaultDomain,
+		HostnameLabel: g.config.OCIHostnameLabel,
+		DefinedTags:   g.definedTags(),
+		SourceTags:    g.freeformSourceTags(),
+
+		Network:            g.network,
+		PreservePrivateIPs: g.config.OCIPreservePrivateIPs,
+
+		// ARM64 requires UEFI
+		UEFI:                         g.config.OCIImageEnableUEFI || g.vmArchitecture == "ARM64",
+		UEFISchemaData:               uefiSchemaData,
+		ImageCapabilitySchemaVersion: defaultImageCapabilitySchemaVersion,
+		ARM64:                        g.vmArchitecture == "ARM64",
+		ARM64Shape:                   shape,
+		RunCommand:                   g.config.FinishingScript != "",
+		RunCommandPlugin:             runCommandPlugin,
+		AgentPlugins:                 g.config.AgentPluginStates(),
+
+		Config: g.config,
+	}, nil
+}
+
+// freeformSourceTags returns the tags of the source VM without those that the template
+// sets itself.
+func (g *OCIGenerator) freeformSourceTags() map[string]string {
+	tags := maps.Clone(g.sourceTags)
+	for _, key := range instanceFreeformTags {
+		delete(tags, key)
+	}
+	return tags
+}
+
+// definedTags returns the defined tags of the instance: those of the source VM mapped
+// by SOURCE_TAG_MAP_FILE, overridden by OCI_DEFINED_TAGS.
+func (g *OCIGenerator) definedTags() map[string]string {
+	tags := maps.Clone(g.sourceDefinedTags)
+	if tags == nil {
+		tags = make(map[string]string)
+	}
+	maps.Copy(tags, g.config.DefinedTags())
+	return tags
+}
+
+// loadTemplate parses the template name from TEMPLATE_DIR when it is there, and the
+// embedded template otherwise.
+func (g *OCIGenerator) loadTemplate(name string) (*texttemplate.Template, error) {
+	var data []byte
+	source := name
+	if dir := g.config.TemplateDir; dir != "" {
+		path := filepath.Join(dir, name)
+		custom, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			data, source = custom, path
+		case !os.IsNotExist(err):
+			return nil, fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+	}
+	if data == nil {
+		embedded, err := embeddedTemplates.ReadFile("templates/" + name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", name, err)
+		}
+		data = embedded
+	}
+	tmpl, err := texttemplate.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", source, err)
+	}
+	return tmpl, nil
+}
+
+// render executes the template name with data and writes the result to fileName in the
+// template output directory.
+func (g *OCIGenerator) render(name, fileName string, data *TemplateData) error {
+	tmpl, err := g.loadTemplate(name)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return os.WriteFile(filepath.Join(g.templateOutputDir, fileName), []byte(b.String()), 0600)
+}
+
+// renderTemplates renders the embedded templates, or their overrides from TEMPLATE_DIR,
+// and the additional templates of TEMPLATE_DIR, e.g. a backend.tf.tmpl. When the data
+// volumes are attached to an existing instance, the attach- templates are rendered instead,
+// and for an instance pool the pool- templates.
+func (g *OCIGenerator) renderTemplates(data *TemplateData) error {
+	names, err := g.customTemplates()
+	if err != nil {
+		return err
+	}
+	files := templateFiles
+	switch {
+	case g.pool != nil:
+		files = poolTemplateFiles
+	case g.config.AttachesToInstance():
+		files = attachTemplateFiles
+	}
+	for _, name := range append(files[:len(files):len(files)], names...) {
+		if name != environmentTemplate {
+			fileName := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSuffix(name, templateSuffix), attachPrefix), poolPrefix)
+			if err := g.render(name, fileName, data); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, env := range g.config.TemplateEnvironmentList() {
+			if env.InstanceName == "" {
+				env.InstanceName = fmt.Sprintf("%s-%s", g.config.OCIInstanceName, env.Name)
+			}
+			envData := *data
+			envData.Environment = env
+			fileName := env.Name + ".tfvars"
+			if err := g.render(name, fileName, &envData); err != nil {
+				return err
+			}
+			g.logger.Infof("Generated %s for the %s environment", fileName, env.Name)
+		}
+	}
+	return nil
+}
+
+// customTemplates returns the templates of TEMPLATE_DIR that do not override an
+// embedded template, sorted by name.
+func (g *OCIGenerator) customTemplates() ([]string, error) {
+	if g.config.TemplateDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(g.config.TemplateDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TEMPLATE_DIR: %w", err)
+	}
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, templateSuffix) {
+			continue
+		}
+		if isEmbeddedTemplate(name) {
+			g.logger.Infof("Using %s from %s", name, g.config.TemplateDir)
+			continue
+		}
+		g.logger.Infof("Adding %s from %s", strings.TrimSuffix(name, templateSuffix), g.config.TemplateDir)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// isEmbeddedTemplate reports whether name is one of the embedded templates.
+func isEmbeddedTemplate(name string) bool {
+	_, err := fs.Stat(embeddedTemplates, "templates/"+name)
+	return err == nil
+}
+
+// ExportTemplates writes the embedded templates to dir, as a starting point for the
+// templates of TEMPLATE_DIR. Existing files are not overwritten.
+func ExportTemplates(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	var written []string
+	err := fs.WalkDir(embeddedTemplates, "templates", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := embeddedTemplates.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, d.Name())
+		f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			return fmt.Errorf("%s already exists", target)
+		} else if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			_ = f.Close()
+			return err
+		}
+		written = append(written, target)
+		return f.Close()
+	})
+	if err != nil {
+		return written, fmt.Errorf("failed to export templates: %w", err)
+	}
+	return written, nil
+}
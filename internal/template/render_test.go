@@ -0,0 +1,178 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestTemplateDir(t *testing.T) {
+	templateDir := t.TempDir()
+	files := map[string]string{
+		"provider.tf.tmpl": "# {{.Config.OCIRegion}}\nprovider \"oci\" {\n  region = var.region\n}\n",
+		"backend.tf.tmpl":  "terraform {\n  backend \"s3\" {\n    key = \"{{.InstanceName}}.tfstate\"\n  }\n}\n",
+		"notes.txt":        "not a template",
+		"terraform.tfvars": "not a template either",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{OCIInstanceName: "web", OCIRegion: "eu-frankfurt-1", TemplateDir: templateDir}
+	gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 0, 0, "x86_64", outputDir)
+	if err := gen.GenerateTemplate(); err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"provider.tf": "# eu-frankfurt-1\n",
+		"backend.tf":  `key = "web.tfstate"`,
+		"main.tf":     `resource "oci_core_instance" "kopru_instance"`,
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", name, want, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "notes")); !os.IsNotExist(err) {
+		t.Errorf("Expected only *.tmpl files of TEMPLATE_DIR to be rendered")
+	}
+}
+
+func TestTemplateDirErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  string
+	}{
+		{"parse error", "{{if .InstanceName}}", "failed to parse template"},
+		{"unknown field", "{{.NoSuchField}}", "failed to render template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(templateDir, "main.tf.tmpl"), []byte(tt.template), 0600); err != nil {
+				t.Fatal(err)
+			}
+			cfg := &config.Config{OCIInstanceName: "web", TemplateDir: templateDir}
+			gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 0, 0, "x86_64", t.TempDir())
+			err := gen.GenerateTemplate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GenerateTemplate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExportTemplates(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "templates")
+	written, err := ExportTemplates(dir)
+	if err != nil {
+		t.Fatalf("ExportTemplates failed: %v", err)
+	}
+	if want := len(templateFiles) + len(attachTemplateFiles) + len(poolTemplateFiles) - 2; len(written) != want {
+		t.Errorf("Expected %d templates, got %d", want, len(written))
+	}
+
+	// The exported templates render the same files as the embedded ones.
+	embeddedDir, exportedDir := t.TempDir(), t.TempDir()
+	for _, c := range []struct{ templateDir, outputDir string }{{"", embeddedDir}, {dir, exportedDir}} {
+		cfg := &config.Config{OCIInstanceName: "web", TemplateDir: c.templateDir}
+		gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 0, 0, "x86_64", c.outputDir)
+		if err := gen.GenerateTemplate(); err != nil {
+			t.Fatalf("GenerateTemplate failed: %v", err)
+		}
+	}
+	for _, name := range []string{"main.tf", "terraform.tfvars", "README.md"} {
+		want, _ := os.ReadFile(filepath.Join(embeddedDir, name))
+		got, _ := os.ReadFile(filepath.Join(exportedDir, name))
+		if string(got) != string(want) {
+			t.Errorf("%s rendered from the exported templates differs from the embedded templates", name)
+		}
+	}
+
+	if _, err := ExportTemplates(dir); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected ExportTemplates to refuse to overwrite templates, got %v", err)
+	}
+}
+
+func TestAttachTemplates(t *testing.T) {
+	outputDir := t.TempDir()
+	cfg := &config.Config{OCIRegion: "eu-frankfurt-1", OCIImageOS: "Ubuntu", OCITargetInstanceID: "ocid1.instance.oc1.test.target"}
+	volumeIDs := []string{"ocid1.volume.oc1.test.data-0"}
+	gen := NewOCIGenerator(cfg, logger.New(false), "", volumeIDs, []string{"data-0"}, 0, 0, 0, "x86_64", outputDir)
+	if err := gen.GenerateTemplate(); err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"main.tf":          `resource "oci_core_volume_attachment" "data_volume_attachments"`,
+		"terraform.tfvars": `instance_id = "ocid1.instance.oc1.test.target"`,
+		"README.md":        "ocid1.instance.oc1.test.target",
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", name, want, content)
+		}
+	}
+	main, _ := os.ReadFile(filepath.Join(outputDir, "main.tf"))
+	if strings.Contains(string(main), "oci_core_instance\" \"kopru_instance") {
+		t.Error("Expected no instance in the template of an existing instance")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, ansibleDir)); !os.IsNotExist(err) {
+		t.Error("Expected no Ansible files for an existing instance")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "attach-main.tf")); !os.IsNotExist(err) {
+		t.Error("Expected the attach- prefix to be removed from the generated files")
+	}
+}
+
+func TestInstancePoolTemplates(t *testing.T) {
+	outputDir := t.TempDir()
+	cfg := &config.Config{OCIRegion: "eu-frankfurt-1", OCICompartmentID: "ocid1.compartment.oc1..test", OCISubnetID: "ocid1.subnet.oc1.test.subnet"}
+	gen := NewOCIGenerator(cfg, logger.New(false), "", nil, nil, 0, 0, 0, "", outputDir)
+	gen.SetInstancePool(InstancePool{Name: "web-pool", InstanceIDs: []string{"ocid1.instance.oc1.test.web-01", "ocid1.instance.oc1.test.web-02"}})
+	if err := gen.GenerateTemplate(); err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"main.tf":          `resource "oci_core_instance_pool" "pool"`,
+		"terraform.tfvars": `"ocid1.instance.oc1.test.web-02"`,
+		"README.md":        "web-pool",
+	}
+	for name, want := range expected {
+		content, err := os.ReadFile(filepath.Join(outputDir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected %s to contain %q, got:\n%s", name, want, content)
+		}
+	}
+	main, _ := os.ReadFile(filepath.Join(outputDir, "main.tf"))
+	if strings.Contains(string(main), "oci_core_instance\" \"kopru_instance") {
+		t.Error("Expected no instance in the template of an instance pool")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, ansibleDir)); !os.IsNotExist(err) {
+		t.Error("Expected no Ansible files for an instance pool")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "pool-main.tf")); !os.IsNotExist(err) {
+		t.Error("Expected the pool- prefix to be removed from the generated files")
+	}
+}
@@ -0,0 +1,65 @@
+package template
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Workload profiles of Azure VM sizes, which map to other OCI shapes or shape
+// configurations than general purpose sizes.
+const (
+	ProfileGeneral   = ""
+	ProfileBurstable = "burstable" // B-series, mapped to burstable instances
+	ProfileDenseIO   = "denseio"   // Storage optimized L-series, mapped to DenseIO shapes
+)
+
+// defaultDenseIOShapes are the x86_64 shapes tried, in order, for instances migrated
+// from storage optimized VMs, before the general purpose shapes
+var defaultDenseIOShapes = []string{"VM.DenseIO.E5.Flex", "VM.DenseIO.E4.Flex"}
+
+// DenseIO Flex shapes allocate OCPUs, with their memory and NVMe drives, in blocks
+const denseIOOCPUBlock = 8
+
+// fullBaseline is the baseline OCPU utilization of regular instances
+const fullBaseline = "BASELINE_1_1"
+
+// burstableShapePattern matches the Flex shapes that support a baseline OCPU utilization
+var burstableShapePattern = regexp.MustCompile(`(?i)^VM\.Standard(\.E[3-9]|3)\.Flex$`)
+
+// SizeProfile returns the workload profile of an Azure VM size, e.g. ProfileBurstable
+// for Standard_B2ms and ProfileDenseIO for Standard_L8s_v3.
+func SizeProfile(vmSize string) string {
+	name := vmSize
+	if _, rest, ok := strings.Cut(vmSize, "_"); ok {
+		name = rest
+	}
+	switch {
+	case strings.HasPrefix(name, "B"):
+		return ProfileBurstable
+	case strings.HasPrefix(name, "L"):
+		return ProfileDenseIO
+	}
+	return ProfileGeneral
+}
+
+// IsDenseIOShape reports whether shape has local NVMe drives, e.g. VM.DenseIO.E5.Flex.
+func IsDenseIOShape(shape string) bool {
+	return strings.Contains(strings.ToLower(shape), ".denseio")
+}
+
+// SupportsBurstable reports whether instances of shape can run with a baseline OCPU
+// utilization.
+func SupportsBurstable(shape string) bool {
+	return burstableShapePattern.MatchString(shape)
+}
+
+// ShapeResources maps the vCPUs and memory of a source VM to the OCPUs and memory of an
+// instance of shape. DenseIO Flex shapes take OCPUs in blocks of 8 with a fixed amount
+// of memory per OCPU, returned as 0 for the shape's default.
+func ShapeResources(shape string, vcpus, memoryGB int32, architecture string) (ocpus int32, memGB int32) {
+	ocpus, memGB = InstanceResources(vcpus, memoryGB, architecture)
+	if !IsDenseIOShape(shape) {
+		return ocpus, memGB
+	}
+	return (ocpus + denseIOOCPUBlock - 1) / denseIOOCPUBlock * denseIOOCPUBlock, 0
+}
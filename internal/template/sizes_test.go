@@ -0,0 +1,128 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestSizeProfile(t *testing.T) {
+	for size, want := range map[string]string{
+		"Standard_B2ms":       ProfileBurstable,
+		"Standard_B4als_v2":   ProfileBurstable,
+		"Standard_L8s_v3":     ProfileDenseIO,
+		"Standard_L16as_v3":   ProfileDenseIO,
+		"Standard_D4s_v5":     ProfileGeneral,
+		"Standard_E8ds_v5":    ProfileGeneral,
+		"Basic_A1":            ProfileGeneral,
+		"":                    ProfileGeneral,
+		"Standard_DC2s_v3":    ProfileGeneral,
+		"Standard_D2pls_v5":   ProfileGeneral,
+		"Standard_B2pts_v2":   ProfileBurstable,
+		"Standard_L80as_v3":   ProfileDenseIO,
+		"Standard_NC6s_v3":    ProfileGeneral,
+		"Standard_M128ms":     ProfileGeneral,
+		"Standard_HB120rs_v3": ProfileGeneral,
+	} {
+		if got := SizeProfile(size); got != want {
+			t.Errorf("SizeProfile(%q) = %q, want %q", size, got, want)
+		}
+	}
+}
+
+func TestShapeResources(t *testing.T) {
+	tests := []struct {
+		shape      string
+		vcpus      int32
+		memoryGB   int32
+		wantOCPUs  int32
+		wantMemory int32
+	}{
+		{"VM.Standard.E5.Flex", 8, 64, 4, 64},
+		{"VM.DenseIO.E5.Flex", 8, 64, 8, 0},
+		{"VM.DenseIO.E4.Flex", 32, 256, 16, 0},
+		{"VM.DenseIO.E5.Flex", 40, 320, 24, 0},
+	}
+	for _, tt := range tests {
+		ocpus, memoryGB := ShapeResources(tt.shape, tt.vcpus, tt.memoryGB, "x86_64")
+		if ocpus != tt.wantOCPUs || memoryGB != tt.wantMemory {
+			t.Errorf("ShapeResources(%s, %d, %d) = %d, %d, want %d, %d", tt.shape, tt.vcpus, tt.memoryGB, ocpus, memoryGB, tt.wantOCPUs, tt.wantMemory)
+		}
+	}
+	for shape, want := range map[string]bool{
+		"VM.Standard.E5.Flex": true,
+		"VM.Standard.E3.Flex": true,
+		"VM.Standard3.Flex":   true,
+		"VM.Standard.A1.Flex": false,
+		"VM.DenseIO.E5.Flex":  false,
+		"VM.Standard.E4.64":   false,
+	} {
+		if got := SupportsBurstable(shape); got != want {
+			t.Errorf("SupportsBurstable(%s) = %v, want %v", shape, got, want)
+		}
+	}
+}
+
+func TestSizeProfileTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		vmSize   string
+		shape    string
+		baseline string
+		want     []string
+		notWant  []string
+	}{
+		{
+			name: "burstable", vmSize: "Standard_B2ms", baseline: "BASELINE_1_2",
+			want: []string{`instance_shape     = "VM.Standard.E5.Flex"`, `instance_baseline_ocpu_utilization = "BASELINE_1_2"`},
+		},
+		{
+			name: "burstable as regular instance", vmSize: "Standard_B2ms", baseline: "BASELINE_1_1",
+			notWant: []string{"instance_baseline_ocpu_utilization"},
+		},
+		{
+			name: "burstable on a shape without baselines", vmSize: "Standard_B2ms", shape: "VM.DenseIO.E4.Flex", baseline: "BASELINE_1_8",
+			notWant: []string{"instance_baseline_ocpu_utilization"},
+		},
+		{
+			name: "dense IO", vmSize: "Standard_L8s_v3", baseline: "BASELINE_1_2",
+			want:    []string{`instance_shape     = "VM.DenseIO.E5.Flex"`, "instance_ocpus     = 8", "instance_memory_gb = 0"},
+			notWant: []string{"instance_baseline_ocpu_utilization"},
+		},
+		{
+			name: "general purpose", vmSize: "Standard_D4s_v5", baseline: "BASELINE_1_2",
+			want:    []string{`instance_shape     = "VM.Standard.E5.Flex"`, "instance_ocpus     = 2", "instance_memory_gb = 16"},
+			notWant: []string{"instance_baseline_ocpu_utilization"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &config.Config{OCIInstanceName: "test-instance", OCIBurstableBaseline: tt.baseline}
+			gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 4, 16, "x86_64", tmpDir)
+			gen.SetVMSize(tt.vmSize)
+			gen.SetShape(tt.shape)
+			if err := gen.GenerateTemplate(); err != nil {
+				t.Fatalf("GenerateTemplate failed: %v", err)
+			}
+			tfvars, err := os.ReadFile(filepath.Join(tmpDir, "terraform.tfvars"))
+			if err != nil {
+				t.Fatalf("Failed to read terraform.tfvars: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(tfvars), want) {
+					t.Errorf("terraform.tfvars does not contain %q:\n%s", want, tfvars)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(string(tfvars), notWant) {
+					t.Errorf("terraform.tfvars contains %q", notWant)
+				}
+			}
+		})
+	}
+}
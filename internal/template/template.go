@@ -3,8 +3,8 @@ package template
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/codebypatrickleung/kopru-cli/internal/common"
@@ -18,6 +18,13 @@ const DefaultAvailabilityDomain = "1"
 const DefaultARM64Shape = "VM.Standard.A1.Flex"
 const Defaultx8664Shape = "VM.Standard.E5.Flex"
 
+// defaultx8664Fallbacks are the x86_64 shapes tried, in order, when the default shape is
+// not available
+var defaultx8664Fallbacks = []string{"VM.Standard.E4.Flex", "VM.Standard3.Flex"}
+
+// arm64ShapePattern matches the shapes of Ampere processors, e.g. VM.Standard.A1.Flex
+var arm64ShapePattern = regexp.MustCompile(`(?i)\.A\d+\.`)
+
 // OCI Flex shape resource constraints
 const (
 	MinOCPUs         = 1  // Minimum OCPUs for OCI Flex shapes
@@ -30,6 +37,9 @@ const (
 // uefiSchemaData is the JSON configuration for enabling UEFI_64 firmware in OCI image capability schema
 const uefiSchemaData = `{\"values\": [\"UEFI_64\"],\"defaultValue\": \"UEFI_64\",\"descriptorType\": \"enumstring\",\"source\": \"IMAGE\"}`
 
+// runCommandPlugin is the Oracle Cloud Agent plugin that runs the finishing script on the instance
+const runCommandPlugin = "Compute Instance Run Command"
+
 // defaultImageCapabilitySchemaVersion is the fallback version when no global schemas are available
 const defaultImageCapabilitySchemaVersion = "1"
 
@@ -44,7 +54,37 @@ type OCIGenerator struct {
 	vmCPUs              int32
 	vmMemoryGB          int32
 	vmArchitecture      string
+	vmSize              string
 	templateOutputDir   string
+	confirmApply        func(planSummary string) error
+	sessionProfile      string
+	backendNamespace    string
+	mounts              []Mount
+	network             Network
+	sourceTags          map[string]string
+	sourceDefinedTags   map[string]string
+	shape               string
+	pool                *InstancePool
+}
+
+// SetShape sets the shape of the instance, as selected from the shapes the availability
+// domain offers. Without it, the default shape of the source architecture is used.
+func (g *OCIGenerator) SetShape(shape string) {
+	g.shape = shape
+}
+
+// SetVMSize sets the Azure VM size of the source VM, e.g. Standard_B2ms, whose workload
+// profile maps B-series VMs to burstable instances and storage optimized VMs to DenseIO
+// shapes.
+func (g *OCIGenerator) SetVMSize(vmSize string) {
+	g.vmSize = vmSize
+}
+
+// SetSourceTags sets the tags of the source VM that the instance carries over, as
+// freeform tags and as defined tags by namespace.key. OCI_DEFINED_TAGS takes precedence
+// over the defined tags.
+func (g *OCIGenerator) SetSourceTags(freeform, defined map[string]string) {
+	g.sourceTags, g.sourceDefinedTags = freeform, defined
 }
 
 // NewOCIGenerator creates a new OCI template generator.
@@ -63,6 +103,40 @@ func NewOCIGenerator(cfg *config.Config, log *logger.Logger, importedImageID str
 	}
 }
 
+// SetApplyConfirmation registers a callback invoked with the plan summary before the
+// plan is applied. Deployment stops if the callback returns an error.
+func (g *OCIGenerator) SetApplyConfirmation(fn func(planSummary string) error) {
+	g.confirmApply = fn
+}
+
+// SetSessionProfile makes the generated provider authenticate with the session token
+// of the given OCI CLI profile, as created by `oci session authenticate`, instead of
+// the API key of the DEFAULT profile.
+func (g *OCIGenerator) SetSessionProfile(profile string) {
+	g.sessionProfile = profile
+}
+
+// InstanceID returns the OCID of the deployed instance from the template outputs,
+// or an empty string if it is not available.
+func (g *OCIGenerator) InstanceID() string {
+	out, err := common.RunCommand(g.engine(), "-chdir="+g.templateOutputDir, "output", "-raw", "instance_id")
+	if err != nil {
+		g.logger.Debugf("Could not read instance_id output: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// planSummaryLine returns the "Plan: N to add, ..." line of plan output, if any.
+func planSummaryLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "Plan:") {
+			return line
+		}
+	}
+	return ""
+}
+
 // formatTemplateList converts a string slice to template list format.
 func formatTemplateList(items []string) string {
 	if len(items) == 0 {
@@ -82,54 +156,103 @@ func formatTemplateList(items []string) string {
 	return b.String()
 }
 
-// selectOCIShape determines the appropriate OCI shape based on the architecture.
-func (g *OCIGenerator) selectOCIShape() string {
-	if g.vmArchitecture == "ARM64" {
-		g.logger.Infof("Selecting ARM64 shape (%s) based on source VM architecture", DefaultARM64Shape)
-		return DefaultARM64Shape
+// Shape returns the OCI shape of instances migrated from a VM of the given architecture.
+func Shape(architecture string) string {
+	return DefaultShapes(architecture, ProfileGeneral)[0]
+}
+
+// DefaultShapes returns the shapes tried, in order, for instances migrated from a VM of
+// the given architecture and workload profile when OCI_SHAPES is not set.
+func DefaultShapes(architecture, profile string) []string {
+	if architecture == "ARM64" {
+		return []string{DefaultARM64Shape}
+	}
+	shapes := append([]string{Defaultx8664Shape}, defaultx8664Fallbacks...)
+	if profile == ProfileDenseIO {
+		return append(slices.Clone(defaultDenseIOShapes), shapes...)
 	}
-	g.logger.Infof("Selecting x86_64 shape (%s) based on source VM architecture", Defaultx8664Shape)
-	return Defaultx8664Shape
+	return shapes
 }
 
-// calculateOCIResources determines the appropriate OCPU and memory configuration for OCI.
-func (g *OCIGenerator) calculateOCIResources() (ocpus int32, memoryGB int32) {
-	if g.vmCPUs == 0 || g.vmMemoryGB == 0 {
-		g.logger.Warningf("No source VM configuration available, using default: %d OCPU, %d GB memory", DefaultOCPUs, DefaultMemoryGB)
-		return DefaultOCPUs, DefaultMemoryGB
+// ShapeArchitecture returns the processor architecture of a shape, ARM64 for Ampere
+// shapes and x86_64 otherwise.
+func ShapeArchitecture(shape string) string {
+	if arm64ShapePattern.MatchString(shape) {
+		return "ARM64"
 	}
+	return "x86_64"
+}
 
-	if g.vmArchitecture == "ARM64" {
+// InstanceResources maps the vCPUs and memory of a source VM of the given architecture
+// to the OCPUs and memory of an OCI Flex shape. Without a source configuration, the
+// defaults are used.
+func InstanceResources(vcpus, memoryGB int32, architecture string) (ocpus int32, memGB int32) {
+	if vcpus == 0 || memoryGB == 0 {
+		return DefaultOCPUs, DefaultMemoryGB
+	}
+	if architecture == "ARM64" {
 		// ARM64: 1 vCPU = 1 OCPU (direct mapping)
-		ocpus = g.vmCPUs
+		ocpus = vcpus
 	} else {
 		// x86_64: 1 OCPU = 2 vCPUs
-		ocpus = (g.vmCPUs + 1) / 2
+		ocpus = (vcpus + 1) / 2
 	}
-	memoryGB = g.vmMemoryGB
-
 	// Ensure minimum OCPUs
 	if ocpus < MinOCPUs {
 		ocpus = MinOCPUs
 	}
-
 	// OCI Flex shapes support 1-64 GB memory per OCPU
-	minMemory := ocpus * MinMemoryPerOCPU
-	maxMemory := ocpus * MaxMemoryPerOCPU
+	return ocpus, min(max(memoryGB, ocpus*MinMemoryPerOCPU), ocpus*MaxMemoryPerOCPU)
+}
 
-	if memoryGB < minMemory {
-		g.logger.Infof("Adjusting memory from %d GB to minimum %d GB for %d OCPUs", memoryGB, minMemory, ocpus)
-		memoryGB = minMemory
-	} else if memoryGB > maxMemory {
-		g.logger.Infof("Adjusting memory from %d GB to maximum %d GB for %d OCPUs", memoryGB, maxMemory, ocpus)
-		memoryGB = maxMemory
+// selectOCIShape determines the appropriate OCI shape based on the architecture.
+func (g *OCIGenerator) selectOCIShape() string {
+	if g.shape != "" {
+		g.logger.Infof("Using shape %s selected for the availability domain", g.shape)
+		return g.shape
 	}
+	shape := DefaultShapes(g.vmArchitecture, SizeProfile(g.vmSize))[0]
+	if g.vmArchitecture == "ARM64" {
+		g.logger.Infof("Selecting ARM64 shape (%s) based on source VM architecture", shape)
+	} else {
+		g.logger.Infof("Selecting x86_64 shape (%s) based on source VM architecture", shape)
+	}
+	return shape
+}
 
+// calculateOCIResources determines the appropriate OCPU and memory configuration for OCI.
+func (g *OCIGenerator) calculateOCIResources(shape string) (ocpus int32, memoryGB int32) {
+	if g.vmCPUs == 0 || g.vmMemoryGB == 0 {
+		g.logger.Warningf("No source VM configuration available, using default: %d OCPU, %d GB memory", DefaultOCPUs, DefaultMemoryGB)
+		return ShapeResources(shape, 0, 0, g.vmArchitecture)
+	}
+	ocpus, memoryGB = ShapeResources(shape, g.vmCPUs, g.vmMemoryGB, g.vmArchitecture)
+	if memoryGB == 0 {
+		g.logger.Infof("Mapped Azure VM (%d vCPUs, %d GB) to %d OCPUs of %s with the memory and NVMe drives of the shape", g.vmCPUs, g.vmMemoryGB, ocpus, shape)
+		return ocpus, memoryGB
+	}
+	if memoryGB != g.vmMemoryGB {
+		g.logger.Infof("Adjusting memory from %d GB to %d GB for %d OCPUs", g.vmMemoryGB, memoryGB, ocpus)
+	}
 	g.logger.Infof("Mapped Azure VM (%d vCPUs, %d GB) to OCI (%d OCPUs, %d GB)", g.vmCPUs, g.vmMemoryGB, ocpus, memoryGB)
-
 	return ocpus, memoryGB
 }
 
+// burstableBaseline returns the baseline OCPU utilization of OCI_BURSTABLE_BASELINE for
+// an instance of shape migrated from a B-series VM, or "" for a regular instance.
+func (g *OCIGenerator) burstableBaseline(shape string) string {
+	baseline := g.config.OCIBurstableBaseline
+	if SizeProfile(g.vmSize) != ProfileBurstable || baseline == "" || baseline == fullBaseline {
+		return ""
+	}
+	if !SupportsBurstable(shape) {
+		g.logger.Warningf("Shape %s does not support burstable instances, the B-series VM %s is migrated to a regular instance", shape, g.vmSize)
+		return ""
+	}
+	g.logger.Infof("Mapped B-series VM %s to a burstable instance with baseline %s", g.vmSize, baseline)
+	return baseline
+}
+
 // GenerateTemplate generates all template configuration files.
 func (g *OCIGenerator) GenerateTemplate() error {
 	if err := common.EnsureDir(g.templateOutputDir); err != nil {
@@ -137,508 +260,71 @@ func (g *OCIGenerator) GenerateTemplate() error {
 	}
 	g.logger.Infof("Generating template files in: %s", g.templateOutputDir)
 
-	generators := []func() error{
-		g.generateProviderTF,
-		g.generateVariablesTF,
-		g.generateMainTF,
-		g.generateOutputsTF,
-		g.generateTFVars,
-		g.generateReadme,
-	}
-	for _, gen := range generators {
-		if err := gen(); err != nil {
+	if g.pool != nil {
+		data, err := g.poolTemplateData()
+		if err != nil {
 			return err
 		}
+		if err := g.renderTemplates(data); err != nil {
+			return err
+		}
+		g.logger.Successf("Instance pool template generated in %s", g.templateOutputDir)
+		return nil
+	}
+	data, err := g.templateData()
+	if err != nil {
+		return err
+	}
+	if err := g.renderTemplates(data); err != nil {
+		return err
+	}
+	if err := g.generateAnsible(); err != nil {
+		return err
 	}
 	g.logger.Successf("Template generated in %s", g.templateOutputDir)
 	return nil
 }
 
-// DeployTemplate executes OpenTofu commands to deploy the infrastructure.
+// DeployTemplate deploys the infrastructure with OpenTofu or Terraform, as selected by
+// IAC_ENGINE.
 func (g *OCIGenerator) DeployTemplate() error {
-	if err := common.CheckCommand("tofu"); err != nil {
-		return fmt.Errorf("tofu not found: %w", err)
+	engine, name := g.engine(), EngineName(g.engine())
+	if err := common.CheckCommand(engine); err != nil {
+		return fmt.Errorf("%s not found: %w", engine, err)
 	}
 	dir := g.templateOutputDir
+	if err := g.checkLockFile(); err != nil {
+		return err
+	}
+	g.checkBackendCredentials()
 
 	steps := []struct {
 		msg  string
 		args []string
 		succ string
 	}{
-		{"Running tofu init...", []string{"-chdir=" + dir, "init"}, "✓ OpenTofu initialized"},
-		{"Running tofu plan...", []string{"-chdir=" + dir, "plan", "-out=tfplan"}, "✓ OpenTofu plan created"},
-		{"Running tofu apply (this may take a while)...", []string{"-chdir=" + dir, "apply", "-auto-approve", "tfplan"}, "Instance deployed with OpenTofu"},
+		{"Running " + engine + " init...", []string{"-chdir=" + dir, "init"}, "✓ " + name + " initialized"},
+		{"Running " + engine + " plan...", []string{"-chdir=" + dir, "plan", "-out=tfplan"}, "✓ " + name + " plan created"},
+		{"Running " + engine + " apply (this may take a while)...", []string{"-chdir=" + dir, "apply", "-auto-approve", "tfplan"}, "Instance deployed with " + name},
 	}
+	var planSummary string
 	for _, step := range steps {
+		if step.args[1] == "apply" && g.confirmApply != nil {
+			if err := g.confirmApply(planSummary); err != nil {
+				return err
+			}
+		}
 		g.logger.Info(step.msg)
-		out, err := common.RunCommand("tofu", step.args...)
+		out, err := common.RunCommand(engine, step.args...)
 		if err != nil {
-			return fmt.Errorf("%s failed: %w\nOutput: %s", strings.Fields(step.msg)[1], err, out)
+			return fmt.Errorf("%s %s failed: %w\nOutput: %s", engine, step.args[1], err, out)
+		}
+		if step.args[1] == "plan" {
+			planSummary = planSummaryLine(out)
 		}
 		g.logger.Success(step.succ)
 	}
-	g.logger.Infof("Run 'tofu output' in %s to see instance details", dir)
+	g.writeAnsibleInventory()
+	g.logger.Infof("Run '%s output' in %s to see instance details", engine, dir)
 	return nil
 }
-
-func (g *OCIGenerator) generateProviderTF() error {
-	content := `# --------------------------------------------------------------------------------------------
-# OCI Provider Configuration
-# --------------------------------------------------------------------------------------------
-
-terraform {
-  required_version = ">= 1.0.0"
-  required_providers {
-	oci = {
-	  source  = "oracle/oci"
-	  version = ">= 5.0.0"
-	}
-  }
-}
-
-provider "oci" {
-  region = var.region
-}
-`
-	return os.WriteFile(filepath.Join(g.templateOutputDir, "provider.tf"), []byte(content), 0600)
-}
-
-func (g *OCIGenerator) generateVariablesTF() error {
-	content := `# --------------------------------------------------------------------------------------------
-# Variable Definitions for OCI Instance Deployment
-# --------------------------------------------------------------------------------------------
-
-variable "compartment_id" {
-  description = "The OCID of the compartment where resources will be created"
-  type        = string
-}
-
-variable "subnet_id" {
-  description = "The OCID of the subnet for the instance"
-  type        = string
-}
-
-variable "imported_image_id" {
-  description = "The OCID of the imported custom image"
-  type        = string
-}
-
-variable "instance_ad_number" {
-  description = "The availability domain number where the instance will be created"
-  type        = number
-  default     = 1
-}
-
-variable "instance_name" {
-  description = "Display name for the OCI instance"
-  type        = string
-  default     = "kopru-instance"
-}
-
-variable "instance_shape" {
-  description = "The shape of the instance (e.g., VM.Standard.E5.Flex)"
-  type        = string
-  default     = "VM.Standard.E5.Flex"
-}
-
-variable "instance_ocpus" {
-  description = "Number of OCPUs for flex shapes"
-  type        = number
-  default     = 1
-}
-
-variable "instance_memory_gb" {
-  description = "Amount of memory in GB for flex shapes"
-  type        = number
-  default     = 12
-}
-
-variable "region" {
-  description = "OCI region"
-  type        = string
-}
-
-variable "data_disk_volume_ids" {
-  description = "List of existing block volume OCIDs to attach as data disks"
-  type        = list(string)
-  default     = []
-}
-
-variable "data_disk_names" {
-  description = "List of display names for restored data disk volumes"
-  type        = list(string)
-  default     = []
-}
-
-variable "boot_volume_size_in_gbs" {
-  description = "Size of the boot volume in GB (minimum 50GB)"
-  type        = number
-  default     = 50
-}
-
-variable "freeform_tags" {
-  description = "Freeform tags for resources"
-  type        = map(string)
-  default = {
-	"created-by" = "kopru"
-  }
-}
-
-variable "ssh_public_key" {
-  description = "SSH public key for instance access (optional)"
-  type        = string
-  default     = ""
-}
-`
-	return os.WriteFile(filepath.Join(g.templateOutputDir, "variables.tf"), []byte(content), 0600)
-}
-
-func (g *OCIGenerator) generateMainTF() error {
-	// Build the base content
-	var b strings.Builder
-	b.WriteString(`# --------------------------------------------------------------------------------------------
-# OCI Instance Configuration
-# --------------------------------------------------------------------------------------------
-
-locals {
-  data_attachment_names = [
-	for idx in range(length(var.data_disk_volume_ids)) :
-	length(var.data_disk_names) > idx ? "attachment-${var.data_disk_names[idx]}" : "attachment-data-disk-${idx}"
-  ]
-}
-
-data "oci_identity_availability_domain" "ad" {
-  compartment_id = var.compartment_id
-  ad_number      = var.instance_ad_number
-}
-
-data "oci_core_subnet" "selected_subnet" {
-  subnet_id = var.subnet_id
-}
-
-locals {
-  assign_public_ip = !data.oci_core_subnet.selected_subnet.prohibit_public_ip_on_vnic
-}
-
-`)
-
-	// Add image capability schema for UEFI if enabled or if ARM64 (ARM64 requires UEFI)
-	needsUEFI := g.config.OCIImageEnableUEFI || g.vmArchitecture == "ARM64"
-	if needsUEFI {
-		uefiCapabilitySection := fmt.Sprintf(`# --------------------------------------------------------------------------------------------
-# Image Capability Schema Configuration
-# --------------------------------------------------------------------------------------------
-
-data "oci_core_compute_global_image_capability_schemas" "image_capability_schemas" {
-  compartment_id = null
-}
-
-locals {
-  global_image_capability_schemas = data.oci_core_compute_global_image_capability_schemas.image_capability_schemas.compute_global_image_capability_schemas
-  # Select the first available schema version, or use a default if none exist
-  schema_version_name = length(local.global_image_capability_schemas) > 0 ? local.global_image_capability_schemas[0].current_version_name : "%s"
-  image_schema_data = {
-    "Compute.Firmware" = "%s"
-  }
-}
-
-resource "oci_core_compute_image_capability_schema" "worker_image_capability_schema" {
-  compartment_id                                      = var.compartment_id
-  compute_global_image_capability_schema_version_name = local.schema_version_name
-  image_id                                            = var.imported_image_id
-  schema_data                                         = local.image_schema_data
-}
-
-`, defaultImageCapabilitySchemaVersion, uefiSchemaData)
-		b.WriteString(uefiCapabilitySection)
-	}
-
-	// Add shape management resource for ARM64 architecture to enable A1 shapes
-	if g.vmArchitecture == "ARM64" {
-		shapeManagementSection := fmt.Sprintf(`# --------------------------------------------------------------------------------------------
-# Shape Management Configuration for ARM64
-# --------------------------------------------------------------------------------------------
-
-resource "oci_core_shape_management" "arm64_shape_support" {
-  compartment_id = var.compartment_id
-  image_id   = var.imported_image_id
-  shape_name = "%s"
-}
-
-`, DefaultARM64Shape)
-		b.WriteString(shapeManagementSection)
-	}
-
-	b.WriteString(`resource "oci_core_instance" "kopru_instance" {
-  compartment_id      = var.compartment_id
-  availability_domain = data.oci_identity_availability_domain.ad.name
-  display_name        = var.instance_name
-  shape               = var.instance_shape
-
-  dynamic "shape_config" {
-	for_each = can(regex("Flex", var.instance_shape)) ? [1] : []
-	content {
-	  ocpus         = var.instance_ocpus
-	  memory_in_gbs = var.instance_memory_gb
-	}
-  }
-
-  source_details {
-	source_type = "image"
-	source_id   = var.imported_image_id
-	boot_volume_size_in_gbs = var.boot_volume_size_in_gbs
-  }
-
-  create_vnic_details {
-	subnet_id        = var.subnet_id
-	assign_public_ip = local.assign_public_ip
-	display_name     = "${var.instance_name}-vnic"
-  }
-
-  metadata = var.ssh_public_key != "" ? {
-	ssh_authorized_keys = var.ssh_public_key
-  } : {}
-
-  lifecycle {
-	prevent_destroy = false
-  }
-
-  freeform_tags = var.freeform_tags
-}
-
-resource "oci_core_volume_attachment" "data_volume_attachments" {
-  count = length(var.data_disk_volume_ids)
-  attachment_type = "paravirtualized"
-  instance_id     = oci_core_instance.kopru_instance.id
-  volume_id       = var.data_disk_volume_ids[count.index]
-  display_name    = local.data_attachment_names[count.index]
-  depends_on      = [oci_core_instance.kopru_instance]
-}
-`)
-
-	return os.WriteFile(filepath.Join(g.templateOutputDir, "main.tf"), []byte(b.String()), 0600)
-}
-
-func (g *OCIGenerator) generateOutputsTF() error {
-	content := `# --------------------------------------------------------------------------------------------
-# Output Definitions
-# --------------------------------------------------------------------------------------------
-
-output "instance_id" {
-  description = "The OCID of the created instance"
-  value       = oci_core_instance.kopru_instance.id
-}
-
-output "instance_name" {
-  description = "The display name of the instance"
-  value       = oci_core_instance.kopru_instance.display_name
-}
-
-output "instance_state" {
-  description = "The current state of the instance"
-  value       = oci_core_instance.kopru_instance.state
-}
-
-output "instance_public_ip" {
-  description = "The public IP address of the instance (if assigned)"
-  value       = oci_core_instance.kopru_instance.public_ip
-}
-
-output "instance_private_ip" {
-  description = "The private IP address of the instance"
-  value       = oci_core_instance.kopru_instance.private_ip
-}
-
-output "data_volume_attachment_ids" {
-  description = "The OCIDs of the volume attachments"
-  value       = oci_core_volume_attachment.data_volume_attachments[*].id
-}
-
-output "ssh_connection" {
-  description = "SSH connection string"
-  value = (
-	oci_core_instance.kopru_instance.public_ip != null
-	? "ssh -i <private-key-file> <user>@${oci_core_instance.kopru_instance.public_ip}"
-	: "ssh -i <private-key-file> <user>@${oci_core_instance.kopru_instance.private_ip}"
-  )
-}
-`
-	return os.WriteFile(filepath.Join(g.templateOutputDir, "outputs.tf"), []byte(content), 0600)
-}
-
-func (g *OCIGenerator) generateTFVars() error {
-	ad := g.config.OCIAvailabilityDomain
-	if ad == "" {
-		ad = DefaultAvailabilityDomain
-	}
-
-	volumeIDsList := formatTemplateList(g.dataDiskVolumeIDs)
-	volumeNamesList := formatTemplateList(g.dataDiskVolumeNames)
-
-	// Calculate boot volume size: max of 50GB or the source Azure VM boot disk size
-	bootVolumeSize := int64(50)
-	if g.bootVolumeSizeGB > bootVolumeSize {
-		bootVolumeSize = g.bootVolumeSizeGB
-	}
-
-	// Select OCI shape based on architecture
-	ociShape := g.selectOCIShape()
-
-	// Calculate OCPU and memory based on source VM configuration
-	ocpus, memoryGB := g.calculateOCIResources()
-
-	// Read SSH public key from file if provided
-	var sshPublicKey string
-	if g.config.SSHKeyFilePath != "" {
-		keyData, err := os.ReadFile(g.config.SSHKeyFilePath)
-		if err != nil {
-			g.logger.Warningf("Failed to read SSH key file %s: %v. SSH key will not be configured.", g.config.SSHKeyFilePath, err)
-		} else {
-			sshPublicKey = strings.TrimSpace(string(keyData))
-			g.logger.Infof("Successfully read SSH public key from %s", g.config.SSHKeyFilePath)
-		}
-	}
-
-	content := fmt.Sprintf(`# --------------------------------------------------------------------------------------------
-# Variable Values for OpenTofu
-# --------------------------------------------------------------------------------------------
-# Generated by Kopru
-# Modify these values as needed before deployment
-# --------------------------------------------------------------------------------------------
-
-compartment_id      = "%s"
-subnet_id           = "%s"
-imported_image_id   = "%s"
-instance_ad_number  = "%s"
-
-instance_name      = "%s"
-instance_shape     = "%s"
-instance_ocpus     = %d
-instance_memory_gb = %d
-
-boot_volume_size_in_gbs = %d
-
-region = "%s"
-
-data_disk_volume_ids = %s
-data_disk_names      = %s
-
-freeform_tags = {
-  "created-by"    = "kopru"
-  "source-image"  = "%s"
-  "source-cpus"   = "%d"
-  "source-memory-gb" = "%d"
-  "source-architecture" = "%s"
-}
-`,
-		g.config.OCICompartmentID,
-		g.config.OCISubnetID,
-		g.importedImageID,
-		ad,
-		g.config.OCIInstanceName,
-		ociShape,
-		ocpus,
-		memoryGB,
-		bootVolumeSize,
-		g.config.OCIRegion,
-		volumeIDsList,
-		volumeNamesList,
-		g.config.OCIImageName,
-		g.vmCPUs,
-		g.vmMemoryGB,
-		g.vmArchitecture,
-	)
-
-	// Append SSH public key if provided
-	if sshPublicKey != "" {
-		content += fmt.Sprintf("\nssh_public_key = \"%s\"\n", sshPublicKey)
-	}
-
-	return os.WriteFile(filepath.Join(g.templateOutputDir, "terraform.tfvars"), []byte(content), 0600)
-}
-
-func (g *OCIGenerator) generateReadme() error {
-	content := `# OpenTofu Configuration for OCI Instance
-
-This directory contains OpenTofu configuration files generated by Kopru.
-Use these files to deploy the imported VM in OCI.
-
-## Files
-
-- ` + "`provider.tf`" + ` - OCI provider configuration
-- ` + "`variables.tf`" + ` - Variable definitions
-- ` + "`main.tf`" + ` - Main infrastructure configuration (instance, volumes, attachments)
-- ` + "`outputs.tf`" + ` - Output definitions
-- ` + "`terraform.tfvars`" + ` - Variable values (customize before deployment)
-- ` + "`README.md`" + ` - This file
-
-## Usage
-
-### 1. Review and Customize Configuration
-
-Before deploying, review ` + "`terraform.tfvars`" + ` and adjust values as needed:
-
-` + "```" + `hcl
-# Adjust instance resources
-instance_ocpus     = 2      # Number of OCPUs
-instance_memory_gb = 16     # Memory in GB
-
-# Change instance shape if needed
-instance_shape = "VM.Standard.E5.Flex"
-` + "```" + `
-
-### 2. Initialize OpenTofu
-
-` + "```" + `bash
-cd template-output
-tofu init
-` + "```" + `
-
-### 3. Review Deployment Plan
-
-` + "```" + `bash
-tofu plan
-` + "```" + `
-
-### 4. Deploy the Instance
-
-` + "```" + `bash
-tofu apply --auto-approve
-` + "```" + `
-
-### 5. View Outputs
-
-After successful deployment:
-
-` + "```" + `bash
-tofu output
-` + "```" + `
-
-This will display:
-- Instance OCID
-- Public and private IP addresses
-- SSH connection string
-- Attached volume information
-
-### 6. Connect to the Instance
-
-` + "```" + `bash
-# Using the SSH connection from outputs
-ssh -i <private-key-file> <user>@<public_ip>
-
-# Or use the output directly
-$(tofu output -raw ssh_connection)
-` + "```" + `
-
-### Destroy Resources
-
-**Warning**: This will terminate the instance and delete all attached volumes!
-
-` + "```" + `bash
-tofu destroy
-` + "```" + `
-
-`
-	return os.WriteFile(filepath.Join(g.templateOutputDir, "README.md"), []byte(content), 0600)
-}
@@ -5,12 +5,33 @@ import (
 	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/codebypatrickleung/kopru-cli/internal/config"
 	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/spf13/viper"
 )
 
+func TestBootVolumeVPUs(t *testing.T) {
+	for _, vpus := range []int{0, 30} {
+		tmpDir := t.TempDir()
+		cfg := &config.Config{OCIInstanceName: "test-instance", OCIBootVolumeVPUsPerGB: vpus}
+		gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 0, 0, "x86_64", tmpDir)
+		if err := gen.GenerateTemplate(); err != nil {
+			t.Fatalf("GenerateTemplate failed: %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(tmpDir, "terraform.tfvars"))
+		if err != nil {
+			t.Fatalf("Failed to read terraform.tfvars: %v", err)
+		}
+		expected := "boot_volume_vpus_per_gb = " + strconv.Itoa(cfg.BootVolumeVPUsPerGB())
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected terraform.tfvars to contain %q", expected)
+		}
+	}
+}
+
 func TestBootVolumeSizeCalculation(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -406,3 +427,407 @@ func TestSubnetPublicIPAssignment(t *testing.T) {
 
 	t.Log("✓ Subnet data source and assign_public_ip logic correctly configured in main.tf")
 }
+
+func TestRunCommandPluginConfiguration(t *testing.T) {
+	for _, script := range []string{"", "finish.sh"} {
+		tmpDir := t.TempDir()
+		cfg := &config.Config{
+			OCICompartmentID: "test-compartment",
+			OCISubnetID:      "test-subnet",
+			OCIRegion:        "us-ashburn-1",
+			OCIInstanceName:  "test-instance",
+			OCIImageName:     "test-image",
+			FinishingScript:  script,
+		}
+		gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 2, 8, "x86_64", tmpDir)
+		if err := gen.GenerateTemplate(); err != nil {
+			t.Fatalf("GenerateTemplate failed: %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(tmpDir, "terraform.tfvars"))
+		if err != nil {
+			t.Fatalf("Failed to read terraform.tfvars: %v", err)
+		}
+		enabled := regexp.MustCompile(`(?s)agent_plugins = \{.*"Compute Instance Run Command" = "ENABLED"`).Match(content)
+		if enabled != (script != "") {
+			t.Errorf("FINISHING_SCRIPT=%q: Run Command plugin enabled = %v, want %v", script, enabled, script != "")
+		}
+	}
+}
+
+func TestInstanceStateConfiguration(t *testing.T) {
+	for _, state := range []string{"", "STOPPED"} {
+		tmpDir := t.TempDir()
+		cfg := &config.Config{
+			OCICompartmentID: "test-compartment",
+			OCISubnetID:      "test-subnet",
+			OCIRegion:        "us-ashburn-1",
+			OCIInstanceName:  "test-instance",
+			OCIImageName:     "test-image",
+			OCIInstanceState: state,
+		}
+		gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 2, 8, "x86_64", tmpDir)
+		if err := gen.GenerateTemplate(); err != nil {
+			t.Fatalf("GenerateTemplate failed: %v", err)
+		}
+		mainTf, err := os.ReadFile(filepath.Join(tmpDir, "main.tf"))
+		if err != nil {
+			t.Fatalf("Failed to read main.tf: %v", err)
+		}
+		if !regexp.MustCompile(`state\s*=\s*var\.instance_state`).Match(mainTf) {
+			t.Error("Expected main.tf to set the instance state from var.instance_state")
+		}
+		tfvars, err := os.ReadFile(filepath.Join(tmpDir, "terraform.tfvars"))
+		if err != nil {
+			t.Fatalf("Failed to read terraform.tfvars: %v", err)
+		}
+		want := cfg.InstanceState()
+		if !regexp.MustCompile(`instance_state\s*=\s*"` + want + `"`).Match(tfvars) {
+			t.Errorf("OCI_INSTANCE_STATE=%q: expected terraform.tfvars to set instance_state = %q", state, want)
+		}
+	}
+}
+
+func TestInstanceComplianceConfiguration(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OCICompartmentID: "test-compartment",
+		OCISubnetID:      "test-subnet",
+		OCIRegion:        "us-ashburn-1",
+		OCIInstanceName:  "test-instance",
+		OCIImageName:     "test-image",
+		OCINSGIDs:        "ocid1.networksecuritygroup.oc1.iad.web, ocid1.networksecuritygroup.oc1.iad.ssh",
+		OCIFaultDomain:   "FAULT-DOMAIN-2",
+		OCIHostnameLabel: "web01",
+		OCIDefinedTags:   "Security.Classification=internal,Operations.CostCenter=42",
+	}
+	gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 2, 8, "x86_64", tmpDir)
+	if err := gen.GenerateTemplate(); err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	mainTf, err := os.ReadFile(filepath.Join(tmpDir, "main.tf"))
+	if err != nil {
+		t.Fatalf("Failed to read main.tf: %v", err)
+	}
+	for _, want := range []string{
+		`fault_domain        = var.fault_domain != "" ? var.fault_domain : null`,
+		`nsg_ids          = var.nsg_ids`,
+		`hostname_label   = var.hostname_label != "" ? var.hostname_label : null`,
+		`defined_tags  = length(var.defined_tags) > 0 ? var.defined_tags : null`,
+	} {
+		if !strings.Contains(string(mainTf), want) {
+			t.Errorf("Expected main.tf to contain %q", want)
+		}
+	}
+	tfvars, err := os.ReadFile(filepath.Join(tmpDir, "terraform.tfvars"))
+	if err != nil {
+		t.Fatalf("Failed to read terraform.tfvars: %v", err)
+	}
+	want := `fault_domain   = "FAULT-DOMAIN-2"
+hostname_label = "web01"
+nsg_ids        = [
+  "ocid1.networksecuritygroup.oc1.iad.web",
+  "ocid1.networksecuritygroup.oc1.iad.ssh"
+]
+
+defined_tags = {
+  "Operations.CostCenter" = "42"
+  "Security.Classification" = "internal"
+}
+`
+	if !strings.Contains(string(tfvars), want) {
+		t.Errorf("Expected terraform.tfvars to contain:\n%s\ngot:\n%s", want, tfvars)
+	}
+}
+
+func TestSourceTags(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{OCIInstanceName: "test-instance", OCIDefinedTags: "Operations.CostCenter=42"}
+	gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 2, 8, "x86_64", tmpDir)
+	gen.SetSourceTags(
+		map[string]string{"env": "prod", "created-by": "terraform", "template": "${var.secret}"},
+		map[string]string{"Operations.CostCenter": "7", "Operations.Owner": "ops"},
+	)
+	if err := gen.GenerateTemplate(); err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	tfvars, err := os.ReadFile(filepath.Join(tmpDir, "terraform.tfvars"))
+	if err != nil {
+		t.Fatalf("Failed to read terraform.tfvars: %v", err)
+	}
+	for _, want := range []string{
+		`"Operations.CostCenter" = "42"`,
+		`"Operations.Owner" = "ops"`,
+		`"created-by"    = "kopru"`,
+		`"env" = "prod"`,
+		`"template" = "$${var.secret}"`,
+	} {
+		if !strings.Contains(string(tfvars), want) {
+			t.Errorf("Expected terraform.tfvars to contain %q, got:\n%s", want, tfvars)
+		}
+	}
+	if strings.Contains(string(tfvars), `"terraform"`) {
+		t.Error("Expected the created-by tag of the source VM to be replaced")
+	}
+}
+
+func TestCloudInitUserData(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OCIInstanceName:   "test-instance",
+		OCISSHPublicKey:   "ssh-ed25519 AAAAC3Nza test@example",
+		CloudInitUserData: "#cloud-config\nruncmd:\n  - [touch, /etc/migrated]\n",
+	}
+	gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 2, 8, "x86_64", tmpDir)
+	if err := gen.GenerateTemplate(); err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	mainTf, err := os.ReadFile(filepath.Join(tmpDir, "main.tf"))
+	if err != nil {
+		t.Fatalf("Failed to read main.tf: %v", err)
+	}
+	if want := `var.user_data != "" ? { user_data = var.user_data } : {},`; !strings.Contains(string(mainTf), want) {
+		t.Errorf("Expected main.tf to contain %q", want)
+	}
+	tfvars, err := os.ReadFile(filepath.Join(tmpDir, "terraform.tfvars"))
+	if err != nil {
+		t.Fatalf("Failed to read terraform.tfvars: %v", err)
+	}
+	want := `ssh_public_key = "ssh-ed25519 AAAAC3Nza test@example"
+
+user_data = "I2Nsb3VkLWNvbmZpZwpydW5jbWQ6CiAgLSBbdG91Y2gsIC9ldGMvbWlncmF0ZWRdCg=="
+`
+	if !strings.Contains(string(tfvars), want) {
+		t.Errorf("Expected terraform.tfvars to contain:\n%s\ngot:\n%s", want, tfvars)
+	}
+}
+
+func TestVolumeBackupAndAutoTune(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.Config
+		wantTFVars []string
+	}{
+		{"defaults", config.Config{}, []string{
+			`boot_volume_auto_tune_enabled = false`,
+			`data_volume_auto_tune_enabled = false`,
+			`boot_volume_backup_policy     = ""`,
+			`data_volume_backup_policy     = ""`,
+		}},
+		{"configured", config.Config{
+			OCIBootVolumeAutoTune:     true,
+			OCIDataVolumeAutoTune:     true,
+			OCIBootVolumeBackupPolicy: "gold",
+			OCIDataVolumeBackupPolicy: "ocid1.volumebackuppolicy.oc1.iad.aaaaexample",
+		}, []string{
+			`boot_volume_auto_tune_enabled = true`,
+			`data_volume_auto_tune_enabled = true`,
+			`boot_volume_backup_policy     = "gold"`,
+			`data_volume_backup_policy     = "ocid1.volumebackuppolicy.oc1.iad.aaaaexample"`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := tt.cfg
+			cfg.OCIInstanceName = "test-instance"
+			gen := NewOCIGenerator(&cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", []string{"ocid1.volume.oc1.test.data"}, []string{"data"}, 50, 2, 8, "x86_64", tmpDir)
+			if err := gen.GenerateTemplate(); err != nil {
+				t.Fatalf("GenerateTemplate failed: %v", err)
+			}
+			tfvars, err := os.ReadFile(filepath.Join(tmpDir, "terraform.tfvars"))
+			if err != nil {
+				t.Fatalf("Failed to read terraform.tfvars: %v", err)
+			}
+			for _, want := range tt.wantTFVars {
+				if !strings.Contains(string(tfvars), want) {
+					t.Errorf("Expected terraform.tfvars to contain %q", want)
+				}
+			}
+			mainTf, err := os.ReadFile(filepath.Join(tmpDir, "main.tf"))
+			if err != nil {
+				t.Fatalf("Failed to read main.tf: %v", err)
+			}
+			for _, want := range []string{
+				`asset_id  = oci_core_instance.kopru_instance.boot_volume_id`,
+				`count     = var.data_volume_backup_policy != "" ? length(var.data_disk_volume_ids) : 0`,
+				`count            = var.boot_volume_auto_tune_enabled ? 1 : 0`,
+				`--is-auto-tune-enabled true`,
+			} {
+				if !strings.Contains(string(mainTf), want) {
+					t.Errorf("Expected main.tf to contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanSummaryLine(t *testing.T) {
+	output := "OpenTofu will perform the following actions:\n\n  # oci_core_instance.instance will be created\n\nPlan: 3 to add, 0 to change, 0 to destroy.\n"
+	if got := planSummaryLine(output); got != "Plan: 3 to add, 0 to change, 0 to destroy." {
+		t.Errorf("Unexpected plan summary: %q", got)
+	}
+	if got := planSummaryLine("No changes."); got != "" {
+		t.Errorf("Expected empty plan summary, got %q", got)
+	}
+}
+
+func TestEnvironmentTFVarsGeneration(t *testing.T) {
+	viper.AutomaticEnv()
+	t.Setenv("SANDBOX_OCI_COMPARTMENT_ID", "ocid1.compartment.oc1..sandbox")
+	t.Setenv("SANDBOX_OCI_SUBNET_ID", "ocid1.subnet.oc1.iad.sandbox")
+	t.Setenv("SANDBOX_OCI_AVAILABILITY_DOMAIN", "3")
+	t.Setenv("SANDBOX_OCI_NSG_IDS", "ocid1.networksecuritygroup.oc1.iad.sandbox")
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{
+		OCICompartmentID:     "ocid1.compartment.oc1..prod",
+		OCISubnetID:          "ocid1.subnet.oc1.iad.prod",
+		OCIRegion:            "us-ashburn-1",
+		OCIInstanceName:      "app",
+		OCIImageName:         "app-image",
+		TemplateEnvironments: "sandbox",
+	}
+	gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 0, 0, "x86_64", tmpDir)
+	if err := gen.GenerateTemplate(); err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(tmpDir, "sandbox.tfvars"))
+	if err != nil {
+		t.Fatalf("Failed to read sandbox.tfvars: %v", err)
+	}
+	for _, want := range []string{
+		`compartment_id = "ocid1.compartment.oc1..sandbox"`,
+		`subnet_id      = "ocid1.subnet.oc1.iad.sandbox"`,
+		`instance_name  = "app-sandbox"`,
+		`instance_ad_number = "3"`,
+		"nsg_ids = [\n  \"ocid1.networksecuritygroup.oc1.iad.sandbox\"\n]",
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Expected sandbox.tfvars to contain %q, got:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "imported_image_id") {
+		t.Error("Expected environment tfvars to inherit the image from terraform.tfvars")
+	}
+}
+
+func TestSessionTokenProvider(t *testing.T) {
+	for _, profile := range []string{"", "sso"} {
+		tmpDir := t.TempDir()
+		cfg := &config.Config{
+			OCICompartmentID: "test-compartment",
+			OCISubnetID:      "test-subnet",
+			OCIRegion:        "us-ashburn-1",
+			OCIInstanceName:  "test-instance",
+			OCIImageName:     "test-image",
+		}
+		gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 2, 8, "x86_64", tmpDir)
+		gen.SetSessionProfile(profile)
+		if err := gen.GenerateTemplate(); err != nil {
+			t.Fatalf("GenerateTemplate failed: %v", err)
+		}
+		content, err := os.ReadFile(filepath.Join(tmpDir, "provider.tf"))
+		if err != nil {
+			t.Fatalf("Failed to read provider.tf: %v", err)
+		}
+		session := strings.Contains(string(content), `auth                = "SecurityToken"`) &&
+			strings.Contains(string(content), `config_file_profile = "sso"`)
+		if session != (profile != "") {
+			t.Errorf("profile %q: session token authentication = %v, want %v\n%s", profile, session, profile != "", content)
+		}
+	}
+}
+
+func TestIaCEngine(t *testing.T) {
+	tests := []struct {
+		engine      string
+		wantVersion string
+		wantReadme  string
+	}{
+		{"", `required_version = ">= 1.6.0"`, "tofu init"},
+		{"tofu", `required_version = ">= 1.6.0"`, "tofu init"},
+		{"terraform", `required_version = ">= 1.4.0"`, "terraform init"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.engine, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cfg := &config.Config{OCIInstanceName: "test-instance", IaCEngine: tt.engine}
+			gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 2, 8, "x86_64", tmpDir)
+			if err := gen.GenerateTemplate(); err != nil {
+				t.Fatalf("GenerateTemplate failed: %v", err)
+			}
+			provider, _ := os.ReadFile(filepath.Join(tmpDir, "provider.tf"))
+			if !strings.Contains(string(provider), tt.wantVersion) {
+				t.Errorf("Expected provider.tf to contain %q", tt.wantVersion)
+			}
+			readme, _ := os.ReadFile(filepath.Join(tmpDir, "README.md"))
+			if !strings.Contains(string(readme), tt.wantReadme) || !strings.Contains(string(readme), "# "+EngineName(tt.engine)+" Configuration") {
+				t.Errorf("Expected README.md to describe %s", EngineName(tt.engine))
+			}
+		})
+	}
+}
+
+func TestCheckLockFile(t *testing.T) {
+	tests := []struct {
+		engine   string
+		lock     string
+		wantKept bool
+	}{
+		{"tofu", `provider "registry.opentofu.org/oracle/oci" {`, true},
+		{"terraform", `provider "registry.opentofu.org/oracle/oci" {`, false},
+		{"tofu", `provider "registry.terraform.io/oracle/oci" {`, false},
+		{"terraform", `provider "registry.terraform.io/oracle/oci" {`, true},
+	}
+	for _, tt := range tests {
+		tmpDir := t.TempDir()
+		path := filepath.Join(tmpDir, lockFileName)
+		if err := os.WriteFile(path, []byte(tt.lock+"\n}\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		gen := NewOCIGenerator(&config.Config{IaCEngine: tt.engine}, logger.New(false), "", nil, nil, 50, 2, 8, "x86_64", tmpDir)
+		if err := gen.checkLockFile(); err != nil {
+			t.Fatalf("checkLockFile() error = %v", err)
+		}
+		if _, err := os.Stat(path); (err == nil) != tt.wantKept {
+			t.Errorf("%s with %s: lock file kept = %v, want %v", tt.engine, tt.lock, err == nil, tt.wantKept)
+		}
+	}
+	gen := NewOCIGenerator(&config.Config{}, logger.New(false), "", nil, nil, 50, 2, 8, "x86_64", t.TempDir())
+	if err := gen.checkLockFile(); err != nil {
+		t.Errorf("checkLockFile() without a lock file error = %v", err)
+	}
+}
+
+func TestShapeSelection(t *testing.T) {
+	if got := DefaultShapes("x86_64", ProfileGeneral); len(got) != 3 || got[0] != Defaultx8664Shape {
+		t.Errorf("DefaultShapes(x86_64) = %v", got)
+	}
+	for shape, want := range map[string]string{
+		"VM.Standard.A1.Flex": "ARM64",
+		"VM.Standard.A2.Flex": "ARM64",
+		"BM.Standard.A1.160":  "ARM64",
+		"VM.Standard.E5.Flex": "x86_64",
+		"VM.Standard3.Flex":   "x86_64",
+		"VM.DenseIO.E4.Flex":  "x86_64",
+	} {
+		if got := ShapeArchitecture(shape); got != want {
+			t.Errorf("ShapeArchitecture(%s) = %s, want %s", shape, got, want)
+		}
+	}
+
+	tmpDir := t.TempDir()
+	cfg := &config.Config{OCIInstanceName: "test-instance"}
+	gen := NewOCIGenerator(cfg, logger.New(false), "ocid1.image.oc1.test.fake-image-id", nil, nil, 50, 4, 16, "x86_64", tmpDir)
+	gen.SetShape("VM.Standard.E4.Flex")
+	if err := gen.GenerateTemplate(); err != nil {
+		t.Fatalf("GenerateTemplate failed: %v", err)
+	}
+	tfvars, err := os.ReadFile(filepath.Join(tmpDir, "terraform.tfvars"))
+	if err != nil {
+		t.Fatalf("Failed to read terraform.tfvars: %v", err)
+	}
+	if want := `instance_shape     = "VM.Standard.E4.Flex"`; !strings.Contains(string(tfvars), want) {
+		t.Errorf("Expected terraform.tfvars to contain %q", want)
+	}
+}
@@ -0,0 +1,25 @@
+// Package workflow provides the SSH access settings applied to configured images.
+package workflow
+
+import (
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// osConfigOptions returns the settings passed to the OS configuration scripts. The SSH
+// key and break-glass user are injected into the image so that the instance remains
+// reachable even when cloud-init fails on OCI.
+func osConfigOptions(log *logger.Logger, cfg *config.Config) (common.OSConfigOptions, error) {
+	key, err := cfg.SSHPublicKey()
+	if err != nil {
+		return common.OSConfigOptions{}, err
+	}
+	opts := common.OSConfigOptions{SSHPublicKey: key}
+	if key != "" && cfg.BreakglassUser != "" {
+		opts.BreakglassUser = cfg.BreakglassUser
+		opts.BreakglassExpiryDays = cfg.BreakglassExpiryDays
+		log.Infof("Break-glass user '%s' will be created in the image", cfg.BreakglassUser)
+	}
+	return opts, nil
+}
@@ -0,0 +1,38 @@
+// Package workflow provides the data disk mounts of the source VM for the generated Ansible playbook.
+package workflow
+
+import (
+	"bytes"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/template"
+)
+
+// sourceMounts returns the data disk mounts in the /etc/fstab of the OS disk image in
+// exportDir. The playbook is only a starting point, so when the mounts cannot be read,
+// a warning is logged and the playbook mounts nothing.
+func sourceMounts(cfg *config.Config, log *logger.Logger, exportDir string) []template.Mount {
+	imageFile, err := common.FindDiskFile(exportDir, ".qcow2")
+	if err != nil {
+		log.Warningf("Could not read the data disk mounts of the source VM for the Ansible playbook: %v", err)
+		return nil
+	}
+	luksKey, cleanup, err := luksKeySelector(cfg)
+	if err != nil {
+		log.Warningf("Could not read the data disk mounts of the source VM for the Ansible playbook: %v", err)
+		return nil
+	}
+	defer cleanup()
+	var fstab bytes.Buffer
+	if err := common.CatGuestFile(imageFile, "/etc/fstab", luksKey, &fstab); err != nil {
+		log.Warningf("Could not read the data disk mounts of the source VM for the Ansible playbook: %v", err)
+		return nil
+	}
+	mounts := template.ParseFstab(fstab.String())
+	for _, m := range mounts {
+		log.Debugf("Data disk mount of the source VM: %s on %s (%s)", m.Source, m.Path, m.FSType)
+	}
+	return mounts
+}
@@ -0,0 +1,61 @@
+// Package workflow provides the handling of images whose architecture differs from the
+// migration host.
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// Actions of ARCH_MISMATCH_ACTION.
+const (
+	ArchMismatchFirstboot = "firstboot" // Defer commands inside the image to its first boot
+	ArchMismatchEmulate   = "emulate"   // Run commands through qemu-user-static binfmt
+	ArchMismatchFail      = "fail"      // Stop before the image is configured
+)
+
+// Overridable in tests.
+var (
+	guestArchitecture = common.GuestArchitecture
+	hostArchitecture  = common.HostArchitecture
+	binfmtEmulation   = common.BinfmtEmulation
+)
+
+// guestExecution describes how commands run inside an image.
+type guestExecution struct {
+	mismatch bool // The image architecture differs from the migration host
+	deferred bool // Commands, scripts and package installs run on the first boot
+}
+
+// resolveGuestExecution compares the architecture of imageFile with the migration host.
+// libguestfs cannot run binaries of another architecture inside the image, so on a
+// mismatch ARCH_MISMATCH_ACTION decides whether they are deferred to the first boot,
+// run through qemu-user-static binfmt, or the configuration fails.
+func resolveGuestExecution(log *logger.Logger, cfg *config.Config, imageFile, luksKey string) (guestExecution, error) {
+	guest, err := guestArchitecture(imageFile, luksKey)
+	if err != nil {
+		log.Warningf("%v, assuming the architecture of the migration host", err)
+		return guestExecution{}, nil
+	}
+	host := hostArchitecture()
+	if guest == host {
+		log.Debugf("Image architecture %s matches the migration host", guest)
+		return guestExecution{}, nil
+	}
+	switch cfg.ArchMismatchAction {
+	case ArchMismatchFail:
+		return guestExecution{}, fmt.Errorf("image architecture %s differs from the migration host (%s); run Kopru on a %s host or set ARCH_MISMATCH_ACTION to firstboot or emulate", guest, host, guest)
+	case ArchMismatchEmulate:
+		if !binfmtEmulation(guest) {
+			return guestExecution{}, fmt.Errorf("image architecture %s differs from the migration host (%s) and qemu-user-static is not registered with binfmt_misc for %s with the F flag; install qemu-user-static or set ARCH_MISMATCH_ACTION=firstboot", guest, host, guest)
+		}
+		log.Infof("Image architecture %s differs from the migration host (%s): running commands inside the image through qemu-user-static", guest, host)
+		return guestExecution{mismatch: true}, nil
+	default:
+		log.Warningf("Image architecture %s differs from the migration host (%s): commands, scripts, package installs and the SELinux relabel are deferred to the first boot of the instance", guest, host)
+		return guestExecution{mismatch: true, deferred: true}, nil
+	}
+}
@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestResolveGuestExecution(t *testing.T) {
+	origGuest, origHost, origBinfmt := guestArchitecture, hostArchitecture, binfmtEmulation
+	t.Cleanup(func() { guestArchitecture, hostArchitecture, binfmtEmulation = origGuest, origHost, origBinfmt })
+	hostArchitecture = func() string { return "x86_64" }
+
+	tests := []struct {
+		name      string
+		guest     string
+		guestErr  error
+		action    string
+		binfmt    bool
+		expected  guestExecution
+		expectErr bool
+	}{
+		{"same architecture", "x86_64", nil, ArchMismatchFail, false, guestExecution{}, false},
+		{"undetected architecture", "", errors.New("no arch"), ArchMismatchFail, false, guestExecution{}, false},
+		{"firstboot", "aarch64", nil, ArchMismatchFirstboot, false, guestExecution{mismatch: true, deferred: true}, false},
+		{"emulate", "aarch64", nil, ArchMismatchEmulate, true, guestExecution{mismatch: true}, false},
+		{"emulate without binfmt", "aarch64", nil, ArchMismatchEmulate, false, guestExecution{}, true},
+		{"fail", "aarch64", nil, ArchMismatchFail, false, guestExecution{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guestArchitecture = func(string, string) (string, error) { return tt.guest, tt.guestErr }
+			binfmtEmulation = func(string) bool { return tt.binfmt }
+			got, err := resolveGuestExecution(logger.New(false), &config.Config{ArchMismatchAction: tt.action}, "image.qcow2", "")
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("resolveGuestExecution() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if got != tt.expected {
+				t.Errorf("resolveGuestExecution() = %+v, expected %+v", got, tt.expected)
+			}
+		})
+	}
+}
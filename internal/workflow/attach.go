@@ -0,0 +1,87 @@
+// Package workflow provides the checks of the existing instance that migrated data disks are attached to.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// checkTargetInstance verifies that the instance of OCI_TARGET_INSTANCE_ID can receive
+// the data volumes. The volumes are placed in the availability domain of the local
+// instance, which copies the data disks to them, or in that of
+// OCI_DATA_VOLUME_AVAILABILITY_DOMAIN, and can only be attached to instances of that
+// availability domain.
+func checkTargetInstance(ctx context.Context, log *logger.Logger, provider *oci.Provider, cfg *config.Config) error {
+	instance, err := provider.GetInstance(ctx, cfg.OCITargetInstanceID)
+	if err != nil {
+		return fmt.Errorf("OCI_TARGET_INSTANCE_ID: %w", err)
+	}
+	localInstanceID, err := provider.GetLocalInstanceID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get local instance ID: %w", err)
+	}
+	localAvailabilityDomain, err := provider.GetLocalAvailabilityDomain(ctx, localInstanceID)
+	if err != nil {
+		return fmt.Errorf("failed to get availability domain: %w", err)
+	}
+	availabilityDomain, err := dataVolumeAvailabilityDomain(ctx, provider, cfg, localAvailabilityDomain)
+	if err != nil {
+		return err
+	}
+	if err := targetInstanceProblem(instance, availabilityDomain); err != nil {
+		return err
+	}
+	log.Successf("✓ Data volumes will be attached to instance '%s' (%s)", stringValue(instance.DisplayName), instance.LifecycleState)
+	return nil
+}
+
+// targetInstanceProblem returns why instance cannot receive data volumes created in
+// availabilityDomain, or nil.
+func targetInstanceProblem(instance core.Instance, availabilityDomain string) error {
+	switch instance.LifecycleState {
+	case core.InstanceLifecycleStateRunning, core.InstanceLifecycleStateStopped:
+	default:
+		return fmt.Errorf("OCI_TARGET_INSTANCE_ID: instance '%s' is %s, it must be RUNNING or STOPPED", stringValue(instance.DisplayName), instance.LifecycleState)
+	}
+	if ad := stringValue(instance.AvailabilityDomain); ad != availabilityDomain {
+		return fmt.Errorf("OCI_TARGET_INSTANCE_ID: instance '%s' is in availability domain %s, but the data volumes are placed in %s; set OCI_DATA_VOLUME_AVAILABILITY_DOMAIN to the number of %s, or run Kopru on a host in it", stringValue(instance.DisplayName), ad, availabilityDomain, ad)
+	}
+	return nil
+}
+
+// osDiskSteps are the steps that migrate the OS disk to a custom image, which are
+// skipped when the data disks are attached to an existing instance.
+var osDiskSteps = map[string]bool{
+	StepExport: true, StepConvert: true, StepConfigure: true,
+	StepUpload: true, StepImport: true, StepWaitImport: true,
+}
+
+// attachSteps skips the OS disk steps of steps when OCI_TARGET_INSTANCE_ID is set, so
+// that only the data disks are restored and the template attaches them to the instance.
+func attachSteps(cfg *config.Config, steps []Step) []Step {
+	if !cfg.AttachesToInstance() {
+		return steps
+	}
+	for i := range steps {
+		if osDiskSteps[steps[i].ID] {
+			steps[i].Skip = true
+			steps[i].SkipMsg = fmt.Sprintf("Skipping %s (OCI_TARGET_INSTANCE_ID is set, only data disks are migrated)", strings.ToLower(steps[i].Name[:1])+steps[i].Name[1:])
+			steps[i].SkipHint = ""
+		}
+	}
+	return steps
+}
+
+// stringValue returns the value of an optional string of the OCI SDK.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,59 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+func TestTargetInstanceProblem(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   core.InstanceLifecycleStateEnum
+		ad      string
+		wantErr string
+	}{
+		{"running", core.InstanceLifecycleStateRunning, "AD-1", ""},
+		{"stopped", core.InstanceLifecycleStateStopped, "AD-1", ""},
+		{"terminated", core.InstanceLifecycleStateTerminated, "AD-1", "must be RUNNING or STOPPED"},
+		{"other availability domain", core.InstanceLifecycleStateRunning, "AD-2", "availability domain AD-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instance := core.Instance{DisplayName: common.String("app"), LifecycleState: tt.state, AvailabilityDomain: common.String(tt.ad)}
+			err := targetInstanceProblem(instance, "AD-1")
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("targetInstanceProblem() = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("targetInstanceProblem() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAttachSteps(t *testing.T) {
+	steps := func() []Step {
+		return []Step{
+			{ID: StepPrerequisites, Name: "Run prerequisite checks"},
+			{ID: StepExport, Name: "Export OS disk"},
+			{ID: StepImport, Name: "Import OS image"},
+			{ID: StepImportData, Name: "Import data disks"},
+			{ID: StepDeploy, Name: "Deploy template"},
+		}
+	}
+	for _, step := range attachSteps(&config.Config{}, steps()) {
+		if step.Skip {
+			t.Errorf("Expected %s to run without OCI_TARGET_INSTANCE_ID", step.ID)
+		}
+	}
+	cfg := &config.Config{OCITargetInstanceID: "ocid1.instance.oc1..abc"}
+	for _, step := range attachSteps(cfg, steps()) {
+		if step.Skip != osDiskSteps[step.ID] {
+			t.Errorf("%s skipped = %t, want %t", step.ID, step.Skip, osDiskSteps[step.ID])
+		}
+	}
+}
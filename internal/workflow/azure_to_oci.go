@@ -9,11 +9,13 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
 	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
 	"github.com/codebypatrickleung/kopru-cli/internal/common"
 	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
 	"github.com/codebypatrickleung/kopru-cli/internal/logger"
 	"github.com/codebypatrickleung/kopru-cli/internal/template"
 	"github.com/oracle/oci-go-sdk/v65/core"
@@ -31,10 +33,29 @@ type AzureToOCIHandler struct {
 	azureVMCPUs         int32
 	azureVMMemoryGB     int32
 	azureVMArchitecture string
+	azureVMSize         string
+	instanceShape       string
+	azureInventory      *azure.ComputeInventory
+	network             template.Network
+	sourceTags          sourceTags
+	dependencies        []SourceDependency
+	migrationID         string
+	checksums           checksumLog
+	snapshots           snapshotGroup
+	liveSync            liveSync
+	power               sourcePower
+	stagedUpload        *stagedUpload
+	bootBeacon          *BootBeaconResult
+	finishing           *FinishingResult
+	configureEngine     string
+	configurators       []common.Configurator
 	osExportDir         string
 	dataExportDir       string
 	templateOutputDir   string
 	importedImageID     string
+	instanceID          string
+	privateIPs          []string
+	publicIPs           []string
 }
 
 func NewAzureToOCIHandler() *AzureToOCIHandler      { return &AzureToOCIHandler{} }
@@ -42,15 +63,47 @@ func (h *AzureToOCIHandler) Name() string           { return "Azure to OCI Migra
 func (h *AzureToOCIHandler) SourcePlatform() string { return "azure" }
 func (h *AzureToOCIHandler) TargetPlatform() string { return "oci" }
 
+// AzureAuth returns the Azure authentication selected by the configuration.
+func AzureAuth(cfg *config.Config) azure.Auth {
+	return azure.Auth{
+		Method:                  cfg.AzureAuth,
+		TenantID:                cfg.AzureTenantID,
+		ClientID:                cfg.AzureClientID,
+		ClientSecret:            cfg.AzureClientSecret,
+		CertificatePath:         cfg.AzureClientCertificatePath,
+		CertificatePassword:     cfg.AzureClientCertPassword,
+		ManagedIdentityClientID: cfg.AzureManagedIdentityClientID,
+	}
+}
+
 func (h *AzureToOCIHandler) Initialize(cfg *config.Config, log *logger.Logger) error {
-	h.config, h.logger = cfg, log
+	if err := h.InitializeOffline(cfg, log); err != nil {
+		return err
+	}
 	var err error
-	if h.azureProvider, err = azure.NewProvider(cfg.AzureSubscriptionID, log); err != nil {
+	if h.azureProvider, err = azure.NewProvider(cfg.AzureSubscriptionID, AzureAuth(cfg), log); err != nil {
 		return fmt.Errorf("failed to initialize Azure provider: %w", err)
 	}
+	h.azureProvider.ConfigureDownload(cfg.DownloadBlockSizeMB, cfg.DownloadWorkers, cfg.DownloadMBPerSecond)
+	h.migrationID = newMigrationID()
+	snapshots := snapshotOptions(cfg, h.migrationID)
+	if snapshots.Quiesce, err = snapshotQuiesce(cfg); err != nil {
+		return err
+	}
+	h.azureProvider.ConfigureSnapshots(snapshots)
+	if cfg.StopsSourceVM() {
+		h.azureProvider.ConfigurePowerControl(cfg.AzureRestartVM)
+	}
 	if h.ociProvider, err = oci.NewProvider(cfg.OCIRegion, log); err != nil {
 		return fmt.Errorf("failed to initialize OCI provider: %w", err)
 	}
+	return nil
+}
+
+// InitializeOffline sets the configuration and the directories of the workflow, which
+// are all that Steps needs.
+func (h *AzureToOCIHandler) InitializeOffline(cfg *config.Config, log *logger.Logger) error {
+	h.config, h.logger = cfg, log
 
 	// Set export and template output directories based on Azure compute name
 	sanitizedName := common.SanitizeName(cfg.AzureComputeName)
@@ -63,80 +116,164 @@ func (h *AzureToOCIHandler) Initialize(cfg *config.Config, log *logger.Logger) e
 
 func (h *AzureToOCIHandler) Execute(ctx context.Context) error {
 	h.logger.Info("=========================================")
-	h.logger.Infof("Executing: %s", h.Name())
+	h.logger.Info(i18n.T("workflow.executing", h.Name()))
 	h.logger.Info("=========================================")
 
-	steps := []struct {
-		skip    bool
-		skipMsg string
-		errMsg  string
-		fn      func(context.Context) error
-	}{
-		{h.config.SkipExport, "Skipping OS disk export (SKIP_OS_EXPORT=true)", "OS disk export failed", h.exportOSDisk},
+	defer h.ociProvider.MonitorSession(ctx)()
+	h.azureProvider.SetTeardown(cleanupFromContext(ctx).push)
+	run := runSteps
+	if h.config.ParallelSteps {
+		run = runStepGraph
 	}
-
-	// Run prerequisite checks
-	if err := h.runPrerequisites(ctx); err != nil {
-		return fmt.Errorf("prerequisite checks failed: %w", err)
+	if err := run(ctx, h.logger, h.Steps()); err != nil {
+		return err
 	}
 
-	// Run steps with skip logic
-	for _, step := range steps {
-		if step.skip {
-			h.logger.Warning(step.skipMsg)
-			continue
-		}
-		if err := step.fn(ctx); err != nil {
-			return fmt.Errorf("%s: %w", step.errMsg, err)
-		}
-	}
+	h.logger.Success("=========================================")
+	h.logger.Success(i18n.T("workflow.azure_completed"))
+	h.logger.Success("=========================================")
+	return nil
+}
 
-	if err := h.convertDisk(ctx); err != nil {
-		return fmt.Errorf("disk conversion failed: %w", err)
-	}
-	if err := h.configureImage(ctx); err != nil {
-		return fmt.Errorf("image configuration failed: %w", err)
-	}
-	if err := h.uploadImage(ctx); err != nil {
-		return fmt.Errorf("image upload failed: %w", err)
-	}
-	if err := h.importOSImage(ctx); err != nil {
-		return fmt.Errorf("image import failed: %w", err)
-	}
-	if err := h.exportDataDisks(ctx); err != nil {
-		return fmt.Errorf("data disk export failed: %w", err)
-	}
-	if err := h.importDataDisks(ctx); err != nil {
-		return fmt.Errorf("data disk import failed: %w", err)
-	}
-	if err := h.generateTemplate(ctx); err != nil {
-		return fmt.Errorf("template generation failed: %w", err)
-	}
-	if err := h.waitForImageImportCompletion(ctx); err != nil {
-		return fmt.Errorf("failed waiting for image import: %w", err)
-	}
+// Steps returns the ordered list of steps that make up the Azure to OCI workflow.
+func (h *AzureToOCIHandler) Steps() []Step {
+	return configureSteps(h.config, attachSteps(h.config, []Step{
+		{ID: StepPrerequisites, Name: "Run prerequisite checks", ErrMsg: "prerequisite checks failed", Fn: h.runPrerequisites},
+		{
+			ID: StepExport, Name: "Export OS disk", Skip: h.config.SkipExport,
+			SkipMsg: "Skipping OS disk export (SKIP_OS_EXPORT=true)", ErrMsg: "OS disk export failed",
+			Outputs: []string{ArtifactOSDiskVHD}, Fn: h.exportOSDisk,
+		},
+		{
+			ID: StepConvert, Name: "Convert VHD to QCOW2", ErrMsg: "disk conversion failed",
+			Inputs: []string{ArtifactOSDiskVHD}, Outputs: []string{ArtifactOSImageQCOW2}, Fn: h.convertDisk,
+		},
+		{
+			ID: StepConfigure, Name: "Configure image for OCI", ErrMsg: "image configuration failed",
+			Inputs: []string{ArtifactOSImageQCOW2}, Outputs: []string{ArtifactConfiguredImage}, Fn: h.configureImage,
+		},
+		{
+			ID: StepUpload, Name: "Upload image to OCI", ErrMsg: "image upload failed",
+			Inputs: []string{ArtifactConfiguredImage}, Outputs: []string{ArtifactUploadedObject}, Fn: h.uploadImage,
+		},
+		{
+			ID: StepImport, Name: "Import OS image", ErrMsg: "image import failed",
+			Inputs: []string{ArtifactUploadedObject}, Outputs: []string{ArtifactCustomImage}, Fn: h.importOSImage,
+		},
+		{
+			ID: StepExportData, Name: "Export data disks", ErrMsg: "data disk export failed",
+			Outputs: []string{ArtifactDataDiskVHDs}, Fn: h.exportDataDisks,
+		},
+		{
+			ID: StepImportData, Name: "Import data disks", ErrMsg: "data disk import failed",
+			Inputs: []string{ArtifactDataDiskVHDs}, Outputs: []string{ArtifactBlockVolumes}, Fn: h.importDataDisks,
+		},
+		{
+			ID: StepTemplate, Name: "Generate template", ErrMsg: "template generation failed",
+			Inputs: []string{ArtifactCustomImage, ArtifactBlockVolumes}, Outputs: []string{ArtifactTemplate}, Fn: h.generateTemplate,
+		},
+		{
+			ID: StepWaitImport, Name: "Wait for image import", ErrMsg: "failed waiting for image import",
+			Inputs: []string{ArtifactCustomImage}, Outputs: []string{ArtifactAvailableImage}, Fn: h.waitForImageImportCompletion,
+		},
+		{
+			ID: StepDeploy, Name: "Deploy template", Skip: h.config.SkipTemplateDeploy,
+			SkipMsg:  "Skipping template deployment (SKIP_TEMPLATE_DEPLOY=true)",
+			SkipHint: fmt.Sprintf("To deploy manually, run: cd %[1]s && %[2]s init && %[2]s apply", h.templateOutputDir, template.Engine(h.config.IaCEngine)),
+			ErrMsg:   "template deployment failed",
+			Inputs:   []string{ArtifactTemplate, ArtifactAvailableImage}, Outputs: []string{ArtifactInstance}, Fn: h.deployTemplate,
+		},
+		{
+			ID: StepFinishing, Name: "Run finishing script", Skip: h.config.SkipTemplateDeploy || h.config.FinishingScript == "" || h.config.StartsStopped(),
+			SkipMsg: "Skipping finishing script (FINISHING_SCRIPT not set, SKIP_TEMPLATE_DEPLOY=true or OCI_INSTANCE_STATE=STOPPED)",
+			ErrMsg:  "finishing script failed", Inputs: []string{ArtifactInstance}, Fn: h.runFinishingScript,
+		},
+		{ID: StepVerify, Name: "Verify workflow", ErrMsg: "workflow verification failed", Fn: h.verifyWorkflow},
+	}))
+}
 
-	if !h.config.SkipTemplateDeploy {
-		if err := h.deployTemplate(ctx); err != nil {
-			return fmt.Errorf("template deployment failed: %w", err)
+// RunID returns the migration ID with which the snapshots of the run are tagged.
+func (h *AzureToOCIHandler) RunID() string {
+	return h.migrationID
+}
+
+// Summarize adds the source VM details and produced OCI resources to the run summary.
+func (h *AzureToOCIHandler) Summarize(s *RunSummary) {
+	s.Source = map[string]string{
+		"platform":       "azure",
+		"subscriptionId": h.config.AzureSubscriptionID,
+		"resourceGroup":  h.config.AzureResourceGroup,
+		"computeName":    h.config.AzureComputeName,
+		"architecture":   h.azureVMArchitecture,
+		"migrationId":    h.migrationID,
+	}
+	if h.azureVMCPUs > 0 {
+		s.Source["cpus"] = fmt.Sprint(h.azureVMCPUs)
+		s.Source["memoryGB"] = fmt.Sprint(h.azureVMMemoryGB)
+	}
+	if h.azureVMSize != "" {
+		s.Source["vmSize"] = h.azureVMSize
+	}
+	if h.azureOSDiskSizeGB > 0 {
+		s.Source["osDiskSizeGB"] = fmt.Sprint(h.azureOSDiskSizeGB)
+	}
+	if inv := h.azureInventory; inv != nil {
+		s.Source["resourceId"] = inv.ResourceID
+		s.Source["osDiskId"] = inv.OSDiskID
+		if len(inv.DataDiskIDs) > 0 {
+			s.Source["dataDiskIds"] = strings.Join(inv.DataDiskIDs, ",")
 		}
-	} else {
-		h.logger.Warning("Skipping template deployment (SKIP_TEMPLATE_DEPLOY=true)")
-		h.logger.Infof("To deploy manually, run: cd %s && tofu init && tofu apply", h.templateOutputDir)
-	}
+		s.SourceTags = inv.Tags
+	}
+	s.Artifacts = SummaryArtifacts{
+		ImageID:       h.importedImageID,
+		DataVolumeIDs: h.dataDiskVolumeIDs,
+		InstanceID:    h.instanceID,
+		PrivateIPs:    h.privateIPs,
+		PublicIPs:     h.publicIPs,
+		TemplateDir:   h.templateOutputDir,
+		ExportDir:     h.osExportDir,
+	}
+	if h.importedImageID != "" {
+		s.Artifacts.ImageLaunchMode = h.config.OCIImageLaunchMode
+	}
+	s.NotCarriedOver = h.dependencies
+	s.LiveSync = h.liveSync.result
+	s.Checksums = h.checksums.list()
+	s.Finishing = h.finishing
+	s.BootBeacon = h.bootBeacon
+}
 
-	if err := h.verifyWorkflow(ctx); err != nil {
-		return fmt.Errorf("workflow verification failed: %w", err)
+// readComputeSize reads the vCPUs, memory and CPU architecture of the source VM's size
+// from the Resource SKUs API. When the size cannot be resolved, the instance gets the
+// default OCPUs and memory, and the architecture is derived from the size name.
+func (h *AzureToOCIHandler) readComputeSize(ctx context.Context) {
+	size, err := h.azureProvider.GetComputeSize(ctx, h.config.AzureResourceGroup, h.config.AzureComputeName)
+	if err == nil {
+		h.azureVMSize, h.azureVMCPUs, h.azureVMMemoryGB, h.azureVMArchitecture = size.Name, size.VCPUs, size.MemoryGB, size.Architecture
+		h.logger.Successf("✓ Source VM size %s: %d vCPUs, %d GB memory, %s", size.Name, size.VCPUs, size.MemoryGB, size.Architecture)
+		if profile := template.SizeProfile(size.Name); profile != template.ProfileGeneral {
+			h.logger.Successf("✓ Source VM size %s is mapped as %s", size.Name, profile)
+		}
+		return
 	}
-
-	h.logger.Success("=========================================")
-	h.logger.Success("Azure to OCI migration completed successfully!")
-	h.logger.Success("=========================================")
-	return nil
+	h.logger.Warningf("Failed to get VM size configuration: %v", err)
+	h.logger.Warningf("Will use default configuration (%d OCPU, %d GB) for OCI instance", template.DefaultOCPUs, template.DefaultMemoryGB)
+	h.azureVMCPUs, h.azureVMMemoryGB = 0, 0
+	architecture, err := h.azureProvider.GetComputeArchitecture(ctx, h.config.AzureResourceGroup, h.config.AzureComputeName)
+	if err != nil {
+		h.logger.Warningf("Failed to get VM architecture: %v", err)
+		h.logger.Warning("Will assume x86_64 architecture for OCI instance")
+		h.azureVMArchitecture = "x86_64"
+		return
+	}
+	h.azureVMArchitecture = architecture
+	h.azureVMSize, _ = h.azureProvider.GetComputeVMSizeName(ctx, h.config.AzureResourceGroup, h.config.AzureComputeName)
+	h.logger.Successf("✓ Source VM CPU architecture: %s", architecture)
 }
 
 func (h *AzureToOCIHandler) runPrerequisites(ctx context.Context) error {
-	h.logger.Step(1, "Reviewing Migration Configuration")
+	h.logger.Step(1, i18n.T("step.review_migration"))
 	h.logger.Infof("Azure Resource Group: %s", h.config.AzureResourceGroup)
 	h.logger.Infof("Azure Compute Name: %s", h.config.AzureComputeName)
 	h.logger.Infof("OCI Compartment ID: %s", h.config.OCICompartmentID)
@@ -146,12 +283,17 @@ func (h *AzureToOCIHandler) runPrerequisites(ctx context.Context) error {
 	h.logger.Infof("OCI Image Name: %s", h.config.OCIImageName)
 	h.logger.Infof("OCI Image OS: %s", h.config.OCIImageOS)
 	h.logger.Infof("OCI Image OS Version: %s", h.config.OCIImageOSVersion)
+	h.logger.Infof("OCI Image Launch Mode: %s", h.config.OCIImageLaunchMode)
 	h.logger.Infof("OCI Image UEFI Enabled: %t", h.config.OCIImageEnableUEFI)
 	h.logger.Infof("Template Output Dir: %s", h.templateOutputDir)
 	h.logger.Infof("SSH Key File Path: %s", h.config.SSHKeyFilePath)
 	h.logger.Infof("Data Disk Parallelism: %d", h.config.DataDiskParallelism)
-	h.logger.Step(2, "Running Prerequisite Checks")
-	for _, tool := range []string{"qemu-img", "virt-customize"} {
+	h.logger.Infof("Data Disk Copy Strategy: %s", h.config.DataDiskCopyStrategy)
+	h.logger.Infof("Parallel Steps: %t", h.config.ParallelSteps)
+	h.logger.Infof("Verify Checksums: %t", h.config.VerifyChecksums)
+	h.logger.Infof("Disk Download: %d MB blocks, %d workers, expected %d MB/s", h.config.DownloadBlockSizeMB, h.config.DownloadWorkers, h.config.DownloadMBPerSecond)
+	h.logger.Step(2, i18n.T("step.prerequisites"))
+	for _, tool := range append([]string{"qemu-img", "virt-customize"}, optionalTools(h.config)...) {
 		if err := common.CheckCommand(tool); err != nil {
 			return fmt.Errorf("required tool missing: %w", err)
 		}
@@ -164,35 +306,65 @@ func (h *AzureToOCIHandler) runPrerequisites(ctx context.Context) error {
 		h.logger.Successf("✓ Available disk space: %d GB", availableBytes/(1024*1024*1024))
 	}
 	h.logger.Warning("Ignore this warning if your available disk space exceeds 2x the VM disks plus 50 GB.")
+	h.configureEngine = resolveConfigureEngine(h.logger, h.config)
+	configurators, err := loadConfigurators(h.logger, h.config)
+	if err != nil {
+		return err
+	}
+	h.configurators = configurators
+	if err := checkFinishingScript(h.config); err != nil {
+		return err
+	}
+	if err := checkClockSkew(ctx, h.logger, h.config); err != nil {
+		return err
+	}
+	tokenExpiry, err := h.azureProvider.CheckToken(ctx)
+	if err != nil {
+		return err
+	}
+	h.logger.Successf("✓ Azure access token acquired (valid until %s, refreshed automatically)", tokenExpiry.Format(time.RFC3339))
+	if h.azureProvider.UsesManagedIdentity() {
+		identity, err := h.azureProvider.DescribeIdentity(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to identify managed identity: %w", err)
+		}
+		h.logger.Infof("Managed identity client ID: %s, object ID: %s", identity.ClientID, identity.ObjectID)
+		if identity.ResourceID != "" {
+			h.logger.Infof("Managed identity resource: %s", identity.ResourceID)
+		}
+	}
+	if err := h.azureProvider.CheckPermissions(ctx, h.config.AzureResourceGroup); err != nil {
+		return fmt.Errorf("azure permission check failed: %w", err)
+	}
+	h.logger.Successf("✓ Azure permissions on resource group '%s' are sufficient", h.config.AzureResourceGroup)
 	if err := h.azureProvider.CheckComputeExists(ctx, h.config.AzureResourceGroup, h.config.AzureComputeName); err != nil {
 		return fmt.Errorf("azure Compute instance check failed: %w", err)
 	}
 	h.logger.Successf("✓ Azure Compute instance '%s' is accessible", h.config.AzureComputeName)
+	if h.azureInventory, err = h.azureProvider.GetComputeInventory(ctx, h.config.AzureResourceGroup, h.config.AzureComputeName); err != nil {
+		h.logger.Warningf("Could not read Azure resource IDs and tags: %v", err)
+	}
+	var vmTags map[string]string
+	if h.azureInventory != nil {
+		vmTags = h.azureInventory.Tags
+	}
+	if h.sourceTags, err = readSourceTags(ctx, h.logger, h.azureProvider, h.config, vmTags); err != nil {
+		return err
+	}
 	osType, err := h.azureProvider.GetComputeOSType(ctx, h.config.AzureResourceGroup, h.config.AzureComputeName)
 	if err != nil {
 		return fmt.Errorf("failed to get Compute instance OS type: %w", err)
 	}
 	h.logger.Successf("✓ Compute instance OS type: %s", osType)
-	cpus, memoryGB, err := h.azureProvider.GetComputeCPUAndMemory(ctx, h.config.AzureResourceGroup, h.config.AzureComputeName)
-	if err != nil {
-		h.logger.Warningf("Failed to get VM CPU/memory configuration: %v", err)
-		h.logger.Warning("Will use default configuration (1 OCPU, 12 GB) for OCI instance")
-		h.azureVMCPUs = 0
-		h.azureVMMemoryGB = 0
-	} else {
-		h.azureVMCPUs = cpus
-		h.azureVMMemoryGB = memoryGB
-		h.logger.Successf("✓ Source VM configuration: %d vCPUs, %d GB memory", cpus, memoryGB)
-	}
-	architecture, err := h.azureProvider.GetComputeArchitecture(ctx, h.config.AzureResourceGroup, h.config.AzureComputeName)
-	if err != nil {
-		h.logger.Warningf("Failed to get VM architecture: %v", err)
-		h.logger.Warning("Will assume x86_64 architecture for OCI instance")
-		h.azureVMArchitecture = "x86_64"
-	} else {
-		h.azureVMArchitecture = architecture
-		h.logger.Successf("✓ Source VM CPU architecture: %s", architecture)
+	if !h.config.AttachesToInstance() {
+		if h.network, err = sourceNetwork(ctx, h.logger, h.azureProvider, h.config); err != nil {
+			return err
+		}
 	}
+	checkAzureLicensing(ctx, h.logger, h.azureProvider, h.config)
+	h.dependencies = reportSourceDependencies(ctx, h.logger, h.azureProvider, h.config)
+	h.readComputeSize(ctx)
+	checkSourceFirmware(ctx, h.logger, h.azureProvider, h.config)
 	if h.config.OCIImageOS == "" {
 		return fmt.Errorf("operating system (OCI_IMAGE_OS) is required when migrating a Compute instance. Allowed values: 'Oracle Linux', 'AlmaLinux', 'CentOS', 'Debian', 'RHEL', 'Rocky Linux', 'SUSE', 'Ubuntu', 'Windows'")
 	}
@@ -221,10 +393,21 @@ func (h *AzureToOCIHandler) runPrerequisites(ctx context.Context) error {
 		return fmt.Errorf("failed to check Compute instance state: %w", err)
 	}
 	if !isStopped {
-		h.logger.Warning("Compute instance is running - it's recommended to stop the instance before export to ensure data consistency")
+		if h.config.StopsSourceVM() && !h.config.LiveSync {
+			h.logger.Info("Compute instance is running - it will be deallocated before its disks are exported")
+		} else if h.config.LiveSync {
+			h.logger.Infof("Compute instance is running - its disks will be exported while it runs, then synced once it is stopped (waiting up to %d minutes)", h.config.LiveSyncStopTimeoutMinutes)
+		} else if !quiesceConfigured(h.config) {
+			h.logger.Warning("Compute instance is running - it's recommended to stop the instance before export, or to set AZURE_SNAPSHOT_CONSISTENCY or AZURE_PRE_SNAPSHOT_SCRIPT, to ensure data consistency")
+		} else {
+			h.logger.Info("Compute instance is running - its disks will be snapshotted together while it is quiesced")
+		}
 	} else {
 		h.logger.Success("✓ Compute instance is stopped")
 	}
+	if err := checkOCISession(ctx, h.logger, h.ociProvider); err != nil {
+		return err
+	}
 	if err := h.ociProvider.CheckCompartmentExists(ctx, h.config.OCICompartmentID); err != nil {
 		return fmt.Errorf("OCI compartment check failed: %w", err)
 	}
@@ -233,11 +416,36 @@ func (h *AzureToOCIHandler) runPrerequisites(ctx context.Context) error {
 		return fmt.Errorf("OCI subnet check failed: %w", err)
 	}
 	h.logger.Success("✓ OCI subnet is accessible")
+	if problem := dataVolumePlacementProblem(h.config); problem != "" {
+		h.logger.Warning(problem)
+	}
+	osDiskGB, dataDisksGB, err := h.azureProvider.GetComputeDiskSizesGB(ctx, h.config.AzureResourceGroup, h.config.AzureComputeName)
+	if err != nil {
+		h.logger.Warningf("Failed to get disk sizes, OCI limits will be checked with minimum volume sizes: %v", err)
+	}
+	if h.config.AttachesToInstance() {
+		if err := checkTargetInstance(ctx, h.logger, h.ociProvider, h.config); err != nil {
+			return err
+		}
+		osDiskGB = 0
+	}
 	namespace, err := h.ociProvider.GetNamespace(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get OCI namespace: %w", err)
 	}
 	h.logger.Successf("✓ OCI namespace retrieved: %s", namespace)
+	if !h.config.AttachesToInstance() {
+		h.instanceShape = selectShape(ctx, h.logger, h.ociProvider, h.config, h.azureVMArchitecture, template.SizeProfile(h.azureVMSize), h.azureVMCPUs, h.azureVMMemoryGB)
+	}
+	if err := checkOCIPolicies(ctx, h.logger, h.ociProvider, h.config.OCICompartmentID, namespace, ociPolicyResources(true, !h.config.SkipTemplateDeploy)); err != nil {
+		return err
+	}
+	if err := checkOCILimits(ctx, h.logger, h.ociProvider, h.config, migrationFootprint{
+		osDiskGB: osDiskGB, dataDisksGB: dataDisksGB, architecture: h.azureVMArchitecture, shape: h.instanceShape,
+		vcpus: h.azureVMCPUs, memoryGB: h.azureVMMemoryGB, instance: !h.config.SkipTemplateDeploy && !h.config.AttachesToInstance(),
+	}); err != nil {
+		return err
+	}
 	bucketExists, err := h.ociProvider.CheckBucketExists(ctx, namespace, h.config.OCIBucketName)
 	if err != nil {
 		return fmt.Errorf("failed to check bucket: %w", err)
@@ -247,12 +455,13 @@ func (h *AzureToOCIHandler) runPrerequisites(ctx context.Context) error {
 	} else {
 		h.logger.Successf("✓ Bucket '%s' exists", h.config.OCIBucketName)
 	}
-	h.logger.Success("Prerequisite checks passed")
+	h.logger.Success(i18n.T("workflow.prereq_passed"))
 	return nil
 }
 
 func (h *AzureToOCIHandler) exportOSDisk(ctx context.Context) error {
-	h.logger.Step(3, "Exporting OS Disk")
+	h.logger.Step(3, i18n.T("step.export_os_disk"))
+	defer h.power.exportStepsDone(h.config)
 	if err := common.EnsureDir(h.osExportDir); err != nil {
 		return fmt.Errorf("failed to create export directory: %w", err)
 	}
@@ -262,81 +471,153 @@ func (h *AzureToOCIHandler) exportOSDisk(ctx context.Context) error {
 		return fmt.Errorf("failed to get OS disk name: %w", err)
 	}
 	h.logger.Infof("OS disk name: %s", osDiskName)
-	vhdFile, err := h.azureProvider.ExportAzureDisk(ctx, osDiskName, h.config.AzureResourceGroup, h.osExportDir)
-	if err != nil {
-		return fmt.Errorf("failed to export OS disk: %w", err)
+	if err := h.power.stop(ctx, h.logger, h.azureProvider, h.config); err != nil {
+		return err
+	}
+	if err := h.liveSync.run(ctx, h.logger, h.azureProvider, h.config, &h.power, h.osExportDir, h.dataExportDir); err != nil {
+		return err
+	}
+	vhdFile, synced := h.liveSync.file(osDiskName)
+	if !synced {
+		if err := h.snapshots.create(ctx, h.logger, h.azureProvider, h.config); err != nil {
+			return err
+		}
+		if vhdFile, err = h.azureProvider.ExportAzureDisk(ctx, osDiskName, h.config.AzureResourceGroup, h.osExportDir); err != nil {
+			return fmt.Errorf("failed to export OS disk: %w", err)
+		}
 	}
 	h.logger.Successf("OS disk exported to: %s", vhdFile)
+	if h.config.VerifyChecksums {
+		return h.checksums.hashFile(h.logger, ArtifactOSDiskVHD, vhdFile)
+	}
 	return nil
 }
 
 func (h *AzureToOCIHandler) convertDisk(ctx context.Context) error {
-	h.logger.Step(4, "Converting VHD to QCOW2")
+	h.logger.Step(4, i18n.T("step.convert_disk"))
 	vhdFile, err := common.FindDiskFile(h.osExportDir, ".vhd")
 	if err != nil {
 		return fmt.Errorf("failed to find VHD file: %w", err)
 	}
 	h.logger.Infof("Converting VHD file: %s", vhdFile)
 	qcow2File := strings.TrimSuffix(vhdFile, ".vhd") + ".qcow2"
+	h.stagedUpload = h.startStagedUpload(ctx, vhdFile, qcow2File)
 	h.logger.Info("Running qemu-img convert (this may take a while)...")
-	if err := common.ConvertVHDToQCOW2(vhdFile, qcow2File); err != nil {
+	if err := common.ConvertVHDToQCOW2(vhdFile, qcow2File, h.logger); err != nil {
+		if h.stagedUpload != nil {
+			h.stagedUpload.abort()
+			h.stagedUpload = nil
+		}
 		return err
 	}
+	if h.stagedUpload != nil {
+		h.stagedUpload.written()
+	}
 	h.logger.Successf("Disk converted to QCOW2: %s", qcow2File)
+	if h.config.VerifyChecksums {
+		return h.checksums.verifyConversion(h.logger, ArtifactOSImageQCOW2, vhdFile, "vpc", qcow2File, "qcow2")
+	}
 	return nil
 }
 
+// startStagedUpload starts uploading qcow2File while convertDisk writes it, when steps
+// run in parallel and the upload does not ask for confirmation. It returns nil when the
+// upload is left to the upload step.
+func (h *AzureToOCIHandler) startStagedUpload(ctx context.Context, vhdFile, qcow2File string) *stagedUpload {
+	if !h.config.ParallelSteps {
+		return nil
+	}
+	// The QCOW2 is at most as large as the VHD, plus a few MB of metadata.
+	info, err := os.Stat(vhdFile)
+	if err != nil || (!h.config.AssumeYes && uploadNeedsConfirmation(h.config, info.Size())) {
+		return nil
+	}
+	namespace, err := ensureBucket(ctx, h.logger, h.ociProvider, h.config)
+	if err != nil {
+		h.logger.Warning(i18n.T("upload.staged_skipped", err))
+		return nil
+	}
+	objectName := filepath.Base(qcow2File)
+	upload, err := h.ociProvider.CreateMultipartUpload(ctx, namespace, h.config.OCIBucketName, objectName)
+	if err != nil {
+		h.logger.Warning(i18n.T("upload.staged_skipped", err))
+		return nil
+	}
+	// An image left by an earlier run must not be uploaded before qemu-img replaces it.
+	if err := os.Remove(qcow2File); err != nil && !errors.Is(err, os.ErrNotExist) {
+		h.logger.Warningf("Failed to remove %s: %v", qcow2File, err)
+	}
+	h.logger.Info(i18n.T("upload.staged_start", objectName, h.config.OCIBucketName))
+	return startStagedUpload(ctx, h.logger, upload, qcow2File, objectName, oci.UploadPartSize)
+}
+
 func (h *AzureToOCIHandler) configureImage(ctx context.Context) error {
-	h.logger.Step(5, "Configuring Image for OCI")
+	h.logger.Step(5, i18n.T("step.configure_image"))
 	qcow2File, err := common.FindDiskFile(h.osExportDir, ".qcow2")
 	if err != nil {
 		return fmt.Errorf("failed to find QCOW2 file: %w", err)
 	}
 	h.logger.Infof("Configuring QCOW2 file: %s", qcow2File)
-	osType := h.config.OCIImageOS
-	if common.IsLinuxOS(osType) {
-		h.logger.Info("Applying OS configurations ...")
-		if err := common.ExecuteOSConfigScript(qcow2File, osType, h.SourcePlatform(), h.logger); err != nil {
-			return fmt.Errorf("failed to execute OS configuration script: %w", err)
+	c := h.imageConfiguration()
+	if h.config.BootBeacon {
+		if c.beaconURL, c.beaconMethod, err = prepareBootBeacon(ctx, h.logger, h.ociProvider, h.config); err != nil {
+			return err
 		}
-		h.logger.Success("Image configurations complete")
-	} else {
-		h.logger.Infof("Skipping image configuration for %s OS", osType)
 	}
-	return nil
+	return c.apply(ctx, qcow2File, h.osExportDir, common.IsLinuxOS(h.config.OCIImageOS))
+}
+
+func (h *AzureToOCIHandler) imageConfiguration() imageConfiguration {
+	return imageConfiguration{
+		cfg:            h.config,
+		log:            h.logger,
+		sourcePlatform: h.SourcePlatform(),
+		engine:         h.configureEngine,
+		configurators:  h.configurators,
+	}
 }
 
 func (h *AzureToOCIHandler) uploadImage(ctx context.Context) error {
-	h.logger.Step(6, "Uploading Image to OCI")
+	h.logger.Step(6, i18n.T("step.upload_image"))
 	qcow2File, err := common.FindDiskFile(h.osExportDir, ".qcow2")
 	if err != nil {
 		return fmt.Errorf("failed to find QCOW2 file: %w", err)
 	}
-	namespace, err := h.ociProvider.GetNamespace(ctx)
+	namespace, err := ensureBucket(ctx, h.logger, h.ociProvider, h.config)
 	if err != nil {
-		return fmt.Errorf("failed to get namespace: %w", err)
+		return err
 	}
-	bucketExists, err := h.ociProvider.CheckBucketExists(ctx, namespace, h.config.OCIBucketName)
-	if err != nil {
-		return fmt.Errorf("failed to check bucket: %w", err)
+	objectName := filepath.Base(qcow2File)
+	if err := confirmUpload(h.config, h.logger, qcow2File, namespace, objectName); err != nil {
+		return err
 	}
-	if !bucketExists {
-		h.logger.Infof("Creating bucket '%s'...", h.config.OCIBucketName)
-		if err := h.ociProvider.CreateBucket(ctx, namespace, h.config.OCICompartmentID, h.config.OCIBucketName); err != nil {
-			return fmt.Errorf("failed to create bucket: %w", err)
+	var hashes common.FileHashes
+	if h.config.VerifyChecksums {
+		if hashes, err = common.HashFile(qcow2File, oci.UploadPartSize, h.logger); err != nil {
+			return err
 		}
 	}
-	objectName := filepath.Base(qcow2File)
-	h.logger.Infof("Uploading %s to bucket %s (this may take a while)...", objectName, h.config.OCIBucketName)
-	if err := h.ociProvider.UploadToObjectStorage(ctx, namespace, h.config.OCIBucketName, objectName, qcow2File); err != nil {
+	var objectMD5 string
+	if staged := h.stagedUpload; staged != nil {
+		h.stagedUpload = nil
+		h.logger.Info(i18n.T("upload.staged_complete", objectName))
+		objectMD5, err = staged.complete(ctx)
+	} else {
+		h.logger.Infof("Uploading %s to bucket %s (this may take a while)...", objectName, h.config.OCIBucketName)
+		objectMD5, err = h.ociProvider.UploadToObjectStorage(ctx, namespace, h.config.OCIBucketName, objectName, qcow2File)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to upload to Object Storage: %w", err)
 	}
 	h.logger.Success("Image uploaded to OCI")
+	if h.config.VerifyChecksums {
+		return h.checksums.verifyUpload(h.logger, qcow2File, hashes, objectMD5)
+	}
 	return nil
 }
 
 func (h *AzureToOCIHandler) importOSImage(ctx context.Context) error {
-	h.logger.Step(7, "Importing OS Image in OCI")
+	h.logger.Step(7, i18n.T("step.import_image"))
 
 	namespace, objectName, err := h.getImageImportDetails(ctx)
 	if err != nil {
@@ -344,19 +625,23 @@ func (h *AzureToOCIHandler) importOSImage(ctx context.Context) error {
 	}
 
 	imageName := fmt.Sprintf("%s-imported-image", common.SanitizeName(h.config.AzureComputeName))
+	imageName, existingID, err := resolveImageName(ctx, h.logger, h.config.ImageConflictPolicy, imageName, func(ctx context.Context, name string) (string, error) {
+		return h.ociProvider.FindImageByName(ctx, h.config.OCICompartmentID, name)
+	})
+	if err != nil {
+		return err
+	}
+	if existingID != "" {
+		h.importedImageID = existingID
+		return nil
+	}
 	h.logger.Infof("Starting OS image import: %s", imageName)
 	h.logger.Info("Image import will run in the background (10-20 minutes)")
 
-	imageID, err := h.ociProvider.ImportImage(
-		ctx,
-		h.config.OCICompartmentID,
-		namespace,
-		h.config.OCIBucketName,
-		objectName,
-		imageName,
-		h.config.OCIImageOS,
-		h.config.OCIImageOSVersion,
-	)
+	opts := imageImportOptions(h.config, imageName)
+	tags := h.sourceTags.image()
+	opts.FreeformTags, opts.DefinedTags = tags.Freeform, tags.Defined
+	imageID, err := h.ociProvider.ImportImage(ctx, h.config.OCICompartmentID, namespace, h.config.OCIBucketName, objectName, opts)
 	if err != nil {
 		return fmt.Errorf("failed to start image import: %w", err)
 	}
@@ -369,7 +654,8 @@ func (h *AzureToOCIHandler) importOSImage(ctx context.Context) error {
 }
 
 func (h *AzureToOCIHandler) exportDataDisks(ctx context.Context) error {
-	h.logger.Step(8, "Exporting Data Disks")
+	h.logger.Step(8, i18n.T("step.export_data_disks"))
+	defer h.power.exportStepsDone(h.config)
 	if err := common.EnsureDir(h.dataExportDir); err != nil {
 		return fmt.Errorf("failed to create export directory: %w", err)
 	}
@@ -382,6 +668,15 @@ func (h *AzureToOCIHandler) exportDataDisks(ctx context.Context) error {
 		h.logger.Info("No data disks found for Compute instance")
 		return nil
 	}
+	if err := h.power.stop(ctx, h.logger, h.azureProvider, h.config); err != nil {
+		return err
+	}
+	if err := h.liveSync.run(ctx, h.logger, h.azureProvider, h.config, &h.power, h.osExportDir, h.dataExportDir); err != nil {
+		return err
+	}
+	if err := h.snapshots.create(ctx, h.logger, h.azureProvider, h.config); err != nil {
+		return err
+	}
 	h.logger.Infof("Found %d data disk(s) to export", len(diskNames))
 	h.logger.Info("Exporting all data disks in parallel...")
 	exportErrors := make([]error, len(diskNames))
@@ -395,13 +690,23 @@ func (h *AzureToOCIHandler) exportDataDisks(ctx context.Context) error {
 				<-sem
 				wg.Done()
 			}()
-			h.logger.Infof("Exporting data disk: %s", diskName)
-			if _, err := h.azureProvider.ExportAzureDisk(ctx, diskName, h.config.AzureResourceGroup, h.dataExportDir); err != nil {
-				exportErrors[i] = err
-				h.logger.Warningf("Failed to export data disk %s: %v", diskName, err)
-				return
+			vhdFile, synced := h.liveSync.file(diskName)
+			if !synced {
+				h.logger.Infof("Exporting data disk: %s", diskName)
+				var err error
+				if vhdFile, err = h.azureProvider.ExportAzureDisk(ctx, diskName, h.config.AzureResourceGroup, h.dataExportDir); err != nil {
+					exportErrors[i] = err
+					h.logger.Warningf("Failed to export data disk %s: %v", diskName, err)
+					return
+				}
 			}
 			h.logger.Successf("✓ Exported: %s", diskName)
+			if h.config.VerifyChecksums {
+				if err := h.checksums.hashFile(h.logger, ArtifactDataDiskVHDs, vhdFile); err != nil {
+					exportErrors[i] = err
+					h.logger.Warningf("Failed to hash data disk %s: %v", diskName, err)
+				}
+			}
 		}()
 	}
 	wg.Wait()
@@ -409,8 +714,12 @@ func (h *AzureToOCIHandler) exportDataDisks(ctx context.Context) error {
 	return errors.Join(exportErrors...)
 }
 
+// importDataDisks copies each exported data disk whole onto a new block volume, so that
+// the partition table (MBR or GPT), all partitions and LVM metadata are replicated and
+// the guest sees an identical disk. The disk is first converted to a RAW image, or with
+// DATA_DISK_COPY_STRATEGY=nbd exposed as an NBD device and copied from it directly.
 func (h *AzureToOCIHandler) importDataDisks(ctx context.Context) error {
-	h.logger.Step(9, "Importing Data Disks")
+	h.logger.Step(9, i18n.T("step.import_data_disks"))
 	h.dataDiskVolumeIDs, h.dataDiskVolumeNames = []string{}, []string{}
 	if _, err := os.Stat(h.dataExportDir); os.IsNotExist(err) {
 		h.logger.Info("No data disk export directory found - skipping data disk import")
@@ -440,6 +749,10 @@ func (h *AzureToOCIHandler) importDataDisks(ctx context.Context) error {
 	}
 	h.logger.Infof("Local instance: %s", localInstanceID)
 	h.logger.Infof("Availability domain: %s", localAvailabilityDomain)
+	availabilityDomain, err := dataVolumeAvailabilityDomain(ctx, h.ociProvider, h.config, localAvailabilityDomain)
+	if err != nil {
+		return err
+	}
 
 	n := len(vhdFiles)
 	type diskInfo struct {
@@ -456,34 +769,39 @@ func (h *AzureToOCIHandler) importDataDisks(ctx context.Context) error {
 		}
 	}
 
-	devicePaths := make([]string, n)
-	for i := range devicePaths {
-		devicePaths[i] = common.DataDiskDevicePath(i)
-	}
-
-	// Phase 1: Convert all VHDs to RAW format in parallel
-	h.logger.Info("Phase 1: Converting VHD files to RAW format in parallel...")
+	// Phase 1: Convert all VHDs to RAW format in parallel, unless they are copied from NBD devices
 	convErrors := make([]error, n)
 	sem := make(chan struct{}, h.config.DataDiskParallelism)
 	var wg sync.WaitGroup
-	for i, disk := range disks {
-		sem <- struct{}{}
-		wg.Add(1)
-		go func() {
-			defer func() {
-				<-sem
-				wg.Done()
-			}()
-			h.logger.Infof("[%s] Converting VHD to RAW format...", disk.baseDiskName)
-			if err := common.ConvertVHDToRAW(disk.vhdFile, disk.rawFile); err != nil {
-				convErrors[i] = err
-				h.logger.Warningf("[%s] Failed to convert VHD to RAW: %v", disk.baseDiskName, err)
-			} else {
+	copyFromNBD := h.config.DataDiskCopyStrategy == common.DataDiskCopyNBD
+	if copyFromNBD {
+		h.logger.Info("Phase 1: Skipped, the VHD files are copied from NBD devices")
+	} else {
+		h.logger.Info("Phase 1: Converting VHD files to RAW format in parallel...")
+		for i, disk := range disks {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				h.logger.Infof("[%s] Converting VHD to RAW format...", disk.baseDiskName)
+				if err := common.ConvertVHDToRAW(disk.vhdFile, disk.rawFile, h.logger); err != nil {
+					convErrors[i] = err
+					h.logger.Warningf("[%s] Failed to convert VHD to RAW: %v", disk.baseDiskName, err)
+					return
+				}
 				h.logger.Successf("[%s] VHD converted to RAW format", disk.baseDiskName)
-			}
-		}()
+				if h.config.DataDiskCopyStrategy == common.DataDiskCopySparse {
+					if err := common.SparsifyImage(disk.rawFile, h.logger); err != nil {
+						h.logger.Warningf("[%s] Free space not zeroed, deleted files will be copied: %v", disk.baseDiskName, err)
+					}
+				}
+			}()
+		}
+		wg.Wait()
 	}
-	wg.Wait()
 
 	// Phase 2: Copy data to OCI block volumes in parallel.
 	h.logger.Info("Phase 2: Copying data to OCI block volumes in parallel...")
@@ -502,15 +820,30 @@ func (h *AzureToOCIHandler) importDataDisks(ctx context.Context) error {
 				<-sem
 				wg.Done()
 			}()
-			diskSizeGB, err := common.GetFileSizeGB(disk.rawFile)
+			source := disk.rawFile
+			if copyFromNBD {
+				device, disconnect, err := common.ConnectImage(disk.vhdFile, "vpc", h.logger)
+				if err != nil {
+					ddErrors[i] = err
+					h.logger.Warningf("[%s] Failed to connect the VHD to an NBD device: %v", disk.baseDiskName, err)
+					return
+				}
+				defer cleanupFromContext(ctx).push("disconnect NBD device "+device, func(context.Context) error {
+					return disconnect()
+				})()
+				h.logger.Infof("[%s] VHD connected to %s", disk.baseDiskName, device)
+				source = device
+			}
+			diskSizeGB, err := common.GetFileSizeGB(source)
 			if err != nil {
 				ddErrors[i] = fmt.Errorf("failed to get disk size: %w", err)
 				h.logger.Warningf("[%s] Failed to get disk size: %v", disk.baseDiskName, err)
 				return
 			}
 			volumeName := fmt.Sprintf("bv-%s", disk.baseDiskName)
-			h.logger.Infof("[%s] Creating OCI volume '%s' of size %d GB...", disk.baseDiskName, volumeName, diskSizeGB)
-			volumeID, err := h.ociProvider.CreateBlockVolume(ctx, h.config.OCICompartmentID, localAvailabilityDomain, volumeName, diskSizeGB)
+			vpusPerGB := h.config.DataVolumeVPUsPerGB(disk.baseDiskName)
+			h.logger.Infof("[%s] Creating OCI volume '%s' of size %d GB with %d VPUs/GB...", disk.baseDiskName, volumeName, diskSizeGB, vpusPerGB)
+			volumeID, err := h.ociProvider.CreateBlockVolume(ctx, h.config.OCICompartmentID, localAvailabilityDomain, volumeName, diskSizeGB, int64(vpusPerGB), int64(h.config.OCIVolumeAutotuneMaxVPUs), h.sourceTags.dataVolume(disk.baseDiskName))
 			if err != nil {
 				ddErrors[i] = fmt.Errorf("failed to create OCI volume: %w", err)
 				h.logger.Warningf("[%s] Failed to create OCI volume: %v", disk.baseDiskName, err)
@@ -520,7 +853,13 @@ func (h *AzureToOCIHandler) importDataDisks(ctx context.Context) error {
 			volumeIDs[i] = volumeID
 			volumeNames[i] = volumeName
 
-			devicePath := devicePaths[i]
+			devicePath, err := common.ReserveDataDiskDevicePath()
+			if err != nil {
+				ddErrors[i] = err
+				h.logger.Warningf("[%s] Failed to reserve a device path: %v", disk.baseDiskName, err)
+				return
+			}
+			defer common.ReleaseDataDiskDevicePath(devicePath)
 			h.logger.Infof("[%s] Attaching volume to local instance at %s...", disk.baseDiskName, devicePath)
 			attachmentID, err := h.ociProvider.AttachVolume(ctx, localInstanceID, volumeID, devicePath)
 			if err != nil {
@@ -529,38 +868,81 @@ func (h *AzureToOCIHandler) importDataDisks(ctx context.Context) error {
 				return
 			}
 			h.logger.Infof("[%s] Volume attached (attachment: %s)", disk.baseDiskName, attachmentID)
+			detach := cleanupFromContext(ctx).push(fmt.Sprintf("detach volume %s from the local instance", volumeName), func(ctx context.Context) error {
+				h.logger.Infof("[%s] Detaching volume...", disk.baseDiskName)
+				if err := h.ociProvider.DetachVolume(ctx, attachmentID); err != nil {
+					return err
+				}
+				h.logger.Infof("[%s] Volume detached", disk.baseDiskName)
+				return nil
+			})
+			defer detach()
 			attachedDevice, err := common.WaitForDevice(devicePath)
 			if err != nil {
 				h.logger.Warningf("[%s] Could not detect attached device: %v", disk.baseDiskName, err)
-				if detachErr := h.ociProvider.DetachVolume(ctx, attachmentID); detachErr != nil {
-					h.logger.Warningf("[%s] Failed to detach volume during cleanup: %v", disk.baseDiskName, detachErr)
-				}
 				ddErrors[i] = fmt.Errorf("failed to detect attached device: %w", err)
 				return
 			}
 			h.logger.Infof("[%s] Attached device: %s", disk.baseDiskName, attachedDevice)
+			if err := common.CheckTargetDevice(attachedDevice, diskSizeGB*1024*1024*1024); err != nil {
+				h.logger.Warningf("[%s] %v", disk.baseDiskName, err)
+				ddErrors[i] = err
+				return
+			}
 
-			h.logger.Infof("[%s] Copying data from RAW file to %s (this may take a while)...", disk.baseDiskName, attachedDevice)
-			if err := common.CopyDataWithDD(disk.rawFile, attachedDevice); err != nil {
+			h.logger.Infof("[%s] Copying data from %s to %s (this may take a while)...", disk.baseDiskName, source, attachedDevice)
+			copyData := common.CopyDataWithDD
+			if h.config.DataDiskCopyStrategy == common.DataDiskCopySparse {
+				copyData = common.CopyAllocatedData
+			}
+			if err := copyData(source, attachedDevice, h.logger); err != nil {
 				h.logger.Warningf("[%s] Failed to copy data: %v", disk.baseDiskName, err)
-				if detachErr := h.ociProvider.DetachVolume(ctx, attachmentID); detachErr != nil {
-					h.logger.Warningf("[%s] Failed to detach volume during cleanup: %v", disk.baseDiskName, detachErr)
-				}
-				ddErrors[i] = fmt.Errorf("failed to copy data with dd: %w", err)
+				ddErrors[i] = fmt.Errorf("failed to copy data: %w", err)
 				return
 			}
 			h.logger.Successf("[%s] Data copy completed", disk.baseDiskName)
-
-			h.logger.Infof("[%s] Detaching volume...", disk.baseDiskName)
-			if err := h.ociProvider.DetachVolume(ctx, attachmentID); err != nil {
-				h.logger.Warningf("[%s] Failed to detach volume: %v", disk.baseDiskName, err)
-			} else {
-				h.logger.Infof("[%s] Volume detached", disk.baseDiskName)
+			// Read the copy back while the volume is still attached, so that a corrupt copy
+			// fails the import instead of being deployed and backed up by the backup policy.
+			if h.config.DataDiskVerifyMode != common.VerifyOff {
+				h.logger.Infof("[%s] Verifying copied data (%s)...", disk.baseDiskName, h.config.DataDiskVerifyMode)
+				if err := h.checksums.verifyDeviceCopy(h.logger, source, attachedDevice, volumeName, h.config.DataDiskVerifyMode); err != nil {
+					h.logger.Warningf("[%s] Copied data does not match: %v", disk.baseDiskName, err)
+					ddErrors[i] = fmt.Errorf("data verification failed: %w", err)
+				} else {
+					h.logger.Successf("[%s] Copied data verified", disk.baseDiskName)
+				}
 			}
 		}()
 	}
 	wg.Wait()
 
+	// Phase 3: Volumes can only be attached in the availability domain they are created
+	// in, so those of another availability domain are restored there from a backup.
+	if availabilityDomain != localAvailabilityDomain {
+		h.logger.Infof("Phase 3: Moving volumes to availability domain %s...", availabilityDomain)
+		for i, disk := range disks {
+			if ddErrors[i] != nil || volumeIDs[i] == "" {
+				continue
+			}
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				movedID, err := h.moveDataVolume(ctx, volumeIDs[i], volumeNames[i], disk.baseDiskName, availabilityDomain)
+				if err != nil {
+					h.logger.Warningf("[%s] Failed to move volume: %v", disk.baseDiskName, err)
+					ddErrors[i] = fmt.Errorf("failed to move volume to %s: %w", availabilityDomain, err)
+					return
+				}
+				volumeIDs[i] = movedID
+			}()
+		}
+		wg.Wait()
+	}
+
 	var failedCount int
 	for i := range disks {
 		if convErrors[i] != nil || ddErrors[i] != nil {
@@ -601,8 +983,8 @@ func (h *AzureToOCIHandler) getImageImportDetails(ctx context.Context) (namespac
 }
 
 func (h *AzureToOCIHandler) generateTemplate(ctx context.Context) error {
-	h.logger.Step(10, "Generating Template")
-	if h.azureOSDiskSizeGB == 0 {
+	h.logger.Step(10, i18n.T("step.generate_template"))
+	if h.azureOSDiskSizeGB == 0 && !h.config.AttachesToInstance() {
 		h.logger.Info("Reading OS disk size from QCOW2 file...")
 		qcow2File, err := common.FindDiskFile(h.osExportDir, ".qcow2")
 		if err != nil {
@@ -625,6 +1007,17 @@ func (h *AzureToOCIHandler) generateTemplate(ctx context.Context) error {
 		h.azureOSDiskSizeGB, h.azureVMCPUs, h.azureVMMemoryGB, h.azureVMArchitecture,
 		h.templateOutputDir,
 	)
+	tfGen.SetSessionProfile(h.ociProvider.SessionProfile())
+	tfGen.SetNetwork(h.network)
+	tfGen.SetSourceTags(h.sourceTags.instance())
+	tfGen.SetShape(h.instanceShape)
+	tfGen.SetVMSize(h.azureVMSize)
+	if err := setBackendNamespace(ctx, h.config, h.ociProvider, tfGen); err != nil {
+		return err
+	}
+	if common.IsLinuxOS(h.config.OCIImageOS) && len(h.dataDiskVolumeIDs) > 0 && !h.config.AttachesToInstance() {
+		tfGen.SetMounts(sourceMounts(h.config, h.logger, h.osExportDir))
+	}
 	return tfGen.GenerateTemplate()
 }
 
@@ -644,7 +1037,7 @@ func (h *AzureToOCIHandler) waitForImageImportCompletion(ctx context.Context) er
 }
 
 func (h *AzureToOCIHandler) deployTemplate(ctx context.Context) error {
-	h.logger.Step(11, "Deploying the template")
+	h.logger.Step(11, i18n.T("step.deploy_template"))
 
 	tfGen := template.NewOCIGenerator(
 		h.config, h.logger, h.importedImageID,
@@ -652,11 +1045,31 @@ func (h *AzureToOCIHandler) deployTemplate(ctx context.Context) error {
 		h.azureOSDiskSizeGB, h.azureVMCPUs, h.azureVMMemoryGB, h.azureVMArchitecture,
 		h.templateOutputDir,
 	)
-	return tfGen.DeployTemplate()
+	tfGen.SetApplyConfirmation(func(planSummary string) error {
+		return confirmApply(h.config, h.logger, planSummary)
+	})
+	if err := tfGen.DeployTemplate(); err != nil {
+		return err
+	}
+	h.instanceID = tfGen.InstanceID()
+	h.privateIPs, h.publicIPs = lookupInstanceIPs(ctx, h.logger, h.ociProvider, h.config.OCICompartmentID, h.instanceID)
+	return nil
+}
+
+func (h *AzureToOCIHandler) runFinishingScript(ctx context.Context) error {
+	h.logger.Step(12, i18n.T("step.finishing_script"))
+	result, err := runFinishingScript(ctx, h.logger, h.ociProvider, h.config, h.instanceID)
+	h.finishing = result
+	return err
 }
 
 func (h *AzureToOCIHandler) verifyWorkflow(ctx context.Context) error {
-	h.logger.Step(12, "Verifying Workflow")
+	h.logger.Step(13, i18n.T("step.verify_workflow"))
+	beacon, err := verifyBootBeacon(ctx, h.logger, h.ociProvider, h.config, h.instanceID)
+	h.bootBeacon = beacon
+	if err != nil {
+		return err
+	}
 	if !h.config.SkipExport {
 		if vhdFile, err := common.FindDiskFile(h.osExportDir, ".vhd"); err == nil {
 			h.logger.Successf("✓ VHD file exists: %s", filepath.Base(vhdFile))
@@ -668,17 +1081,20 @@ func (h *AzureToOCIHandler) verifyWorkflow(ctx context.Context) error {
 	if _, err := os.Stat(h.templateOutputDir); err == nil {
 		h.logger.Successf("✓ Template files exist in: %s", h.templateOutputDir)
 	}
-	h.logger.Success("Workflow verification complete")
+	h.logger.Success(i18n.T("workflow.verify_complete"))
 	h.logger.Info("=========================================")
-	h.logger.Info("Next Steps:")
-	if !h.config.SkipTemplateDeploy {
-		h.logger.Info("1. Check the OCI console for the deployed instance")
-		h.logger.Info("2. Verify the instance is running as expected")
+	h.logger.Info(i18n.T("workflow.next_steps"))
+	if h.config.SkipTemplateDeploy {
+		h.logger.Info(i18n.T("workflow.next_navigate", 1, h.templateOutputDir))
+		h.logger.Info(i18n.T("workflow.next_run_iac", 2, template.Engine(h.config.IaCEngine)))
+		h.logger.Info(i18n.T("workflow.next_check_console", 3))
+	} else if h.config.StartsStopped() {
+		h.logger.Info(i18n.T("workflow.next_start", 1))
+		h.logger.Info(i18n.T("workflow.next_verify", 2))
 	} else {
-		h.logger.Infof("1. Navigate to: %s", h.templateOutputDir)
-		h.logger.Info("2. Run: tofu init && tofu apply")
-		h.logger.Info("3. Check the OCI console for the deployed instance")
+		h.logger.Info(i18n.T("workflow.next_check_console", 1))
+		h.logger.Info(i18n.T("workflow.next_verify", 2))
 	}
 	h.logger.Info("=========================================")
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,25 @@
+// Package workflow provides the Object Storage namespace of the state backend of the generated template.
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/template"
+)
+
+// setBackendNamespace gives tfGen the Object Storage namespace of the tenancy when the
+// state is kept in OCI Object Storage and TEMPLATE_BACKEND_NAMESPACE is not set.
+func setBackendNamespace(ctx context.Context, cfg *config.Config, provider *oci.Provider, tfGen *template.OCIGenerator) error {
+	if cfg.TemplateBackend != config.BackendOCI || cfg.TemplateBackendNamespace != "" {
+		return nil
+	}
+	namespace, err := provider.GetNamespace(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the namespace of the state bucket: %w", err)
+	}
+	tfGen.SetBackendNamespace(namespace)
+	return nil
+}
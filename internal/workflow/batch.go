@@ -0,0 +1,173 @@
+// Package workflow provides the migration of the Azure VMs selected by a name pattern, an availability set or a VM scale set.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
+)
+
+// BatchResult records the outcome of the migration of one VM of a batch.
+type BatchResult struct {
+	VM          string
+	Status      string
+	Error       string
+	SummaryFile string
+	InstanceID  string // Instance deployed for the VM, from its run summary
+}
+
+// runMigration migrates the VM of cfg and writes its run summary to summaryFile; tests
+// replace it.
+var runMigration = func(ctx context.Context, cfg *config.Config, log *logger.Logger, version, summaryFile string) error {
+	mgr, err := NewManager(cfg, log, version)
+	if err != nil {
+		return fmt.Errorf("failed to create workflow manager: %w", err)
+	}
+	mgr.summaryFile = summaryFile
+	return mgr.Run(ctx)
+}
+
+// MatchComputeNames returns the names that match pattern, sorted. Names are matched
+// without regard to case, as Azure does; VM resource IDs by the name of their VM.
+func MatchComputeNames(pattern string, names []string) []string {
+	var matched []string
+	for _, name := range names {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(config.AzureVMName(name))); ok {
+			matched = append(matched, name)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+// ExpandComputeNames lists the VMs of the resource group, of the availability set or
+// VM scale set of AZURE_COMPUTE_GROUP, or of BATCH_MANIFEST_FILE, that match the pattern
+// in AZURE_COMPUTE_NAME.
+func ExpandComputeNames(ctx context.Context, cfg *config.Config, log *logger.Logger) ([]string, error) {
+	if cfg.BatchManifestFile != "" {
+		vms, err := cfg.BatchManifest()
+		if err != nil {
+			return nil, err
+		}
+		if matched := MatchComputeNames(cfg.AzureComputeName, vms); len(matched) > 0 {
+			return matched, nil
+		}
+		return nil, fmt.Errorf("no VM of %s matches '%s'", cfg.BatchManifestFile, cfg.AzureComputeName)
+	}
+	provider, err := azure.NewProvider(cfg.AzureSubscriptionID, AzureAuth(cfg), log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Azure provider: %w", err)
+	}
+	groupType, groupName, grouped := cfg.ComputeGroup()
+	var names []string
+	if grouped {
+		names, err = provider.ListComputeGroupMembers(ctx, cfg.AzureResourceGroup, groupType, groupName)
+	} else {
+		names, err = provider.ListComputeNames(ctx, cfg.AzureResourceGroup)
+	}
+	if err != nil {
+		return nil, err
+	}
+	matched := MatchComputeNames(cfg.AzureComputeName, names)
+	switch {
+	case len(matched) > 0:
+		return matched, nil
+	case grouped && len(names) == 0 && groupType == config.GroupScaleSet:
+		return nil, fmt.Errorf("scale set %s in resource group %s has no instances", groupName, cfg.AzureResourceGroup)
+	case grouped:
+		return nil, fmt.Errorf("no VM of %s in resource group %s matches '%s'", cfg.AzureComputeGroup, cfg.AzureResourceGroup, cfg.AzureComputeName)
+	}
+	return nil, fmt.Errorf("no VM in resource group %s matches '%s'", cfg.AzureResourceGroup, cfg.AzureComputeName)
+}
+
+// batchSummaryFileName is the run summary of each VM of a batch, written instead of
+// SummaryFileName so that the runs of a batch do not overwrite each other's summary.
+func batchSummaryFileName(vm string) string {
+	return fmt.Sprintf("kopru-summary-%s.json", common.SanitizeName(vm))
+}
+
+// RunBatch migrates the VMs one after another, each with the configuration of cfg and
+// the instance and image names derived from the VM. A failed migration does not stop
+// the batch; an interrupt does. With OCI_INSTANCE_POOL, the instances of the migrated
+// VMs are then added to an instance pool. It returns an error when any migration failed.
+func RunBatch(ctx context.Context, cfg *config.Config, log *logger.Logger, version string, vms []string) error {
+	names := make([]string, len(vms))
+	for i, vm := range vms {
+		names[i] = config.AzureVMName(vm)
+	}
+	switch {
+	case cfg.AzureComputeGroup != "":
+		log.Info(i18n.T("batch.migrating_from", len(vms), cfg.AzureComputeGroup, cfg.AzureComputeName, strings.Join(names, ", ")))
+	case cfg.BatchManifestFile != "":
+		log.Info(i18n.T("batch.migrating_from", len(vms), cfg.BatchManifestFile, cfg.AzureComputeName, strings.Join(names, ", ")))
+	default:
+		log.Info(i18n.T("batch.migrating", len(vms), cfg.AzureComputeName, strings.Join(names, ", ")))
+	}
+	var results []BatchResult
+	var failed []error
+	succeeded := 0
+	for i, vm := range names {
+		if ctx.Err() != nil {
+			log.Warning(i18n.T("batch.interrupted", len(vms)-i))
+			failed = append(failed, ctx.Err())
+			break
+		}
+		log.Info(i18n.T("batch.migrating_vm", i+1, len(vms), vm))
+		telemetry.ResetOperations()
+		progress.ResetTransfers()
+		summaryFile := batchSummaryFileName(vm)
+		_ = os.Remove(summaryFile)
+
+		result := BatchResult{VM: vm, Status: StatusSucceeded}
+		if err := runMigration(ctx, cfg.ForComputeName(vms[i]), log, version, summaryFile); err != nil {
+			log.Errorf("Migration of %s failed: %v", vm, err)
+			result.Status, result.Error = StatusFailed, err.Error()
+			failed = append(failed, fmt.Errorf("%s: %w", vm, err))
+		} else {
+			succeeded++
+		}
+		if _, err := os.Stat(summaryFile); err == nil {
+			result.SummaryFile = summaryFile
+			result.InstanceID, _ = summaryInstanceID(summaryFile)
+		}
+		results = append(results, result)
+	}
+	reportBatch(log, results)
+	if cfg.OCIInstancePool && ctx.Err() == nil {
+		if err := deployInstancePool(ctx, cfg, log, results); err != nil {
+			log.Errorf("Instance pool deployment failed: %v", err)
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d VM migration(s) did not succeed: %w", len(vms)-succeeded, len(vms), errors.Join(failed...))
+	}
+	return nil
+}
+
+// reportBatch logs the outcome of each migration of a batch.
+func reportBatch(log *logger.Logger, results []BatchResult) {
+	log.Info(i18n.T("batch.results"))
+	for _, r := range results {
+		line := fmt.Sprintf("  %-24s %s", r.VM, r.Status)
+		if r.SummaryFile != "" {
+			line += " (" + r.SummaryFile + ")"
+		}
+		if r.Error != "" {
+			line += ": " + r.Error
+		}
+		log.Info(line)
+	}
+}
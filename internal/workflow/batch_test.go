@@ -0,0 +1,78 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestMatchComputeNames(t *testing.T) {
+	names := []string{"web-02", "db-01", "WEB-01", "web", "api-web-01"}
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"web-*", "WEB-01,web-02"},
+		{"web-0?", "WEB-01,web-02"},
+		{"*web*", "WEB-01,api-web-01,web,web-02"},
+		{"cache-*", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(MatchComputeNames(tt.pattern, names), ","); got != tt.want {
+			t.Errorf("MatchComputeNames(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	t.Chdir(t.TempDir())
+	var migrated []string
+	original := runMigration
+	defer func() { runMigration = original }()
+	runMigration = func(_ context.Context, cfg *config.Config, _ *logger.Logger, _, summaryFile string) error {
+		migrated = append(migrated, cfg.AzureComputeName+"="+cfg.OCIInstanceName)
+		summary := &RunSummary{Workflow: cfg.AzureComputeName}
+		if err := summary.Write(summaryFile); err != nil {
+			return err
+		}
+		if cfg.AzureComputeName == "web-02" {
+			return errors.New("export failed")
+		}
+		return nil
+	}
+
+	// The summary of an earlier single-VM run is left alone.
+	if err := os.WriteFile(SummaryFileName, []byte(`{"workflow": "db-01"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{SourcePlatform: "azure", AzureComputeName: "web-*", OCIInstanceName: "kopru-instance", OCIImageName: "kopru-image"}
+	err := RunBatch(context.Background(), cfg, logger.New(false), "test", []string{"web-01", "web-02", "web-03"})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3") || !strings.Contains(err.Error(), "web-02: export failed") {
+		t.Errorf("RunBatch() error = %v", err)
+	}
+	if got := strings.Join(migrated, ","); got != "web-01=web-01,web-02=web-02,web-03=web-03" {
+		t.Errorf("Migrated %s", got)
+	}
+	for _, vm := range []string{"web-01", "web-02", "web-03"} {
+		data, err := os.ReadFile(batchSummaryFileName(vm))
+		if err != nil || !strings.Contains(string(data), `"workflow": "`+vm+`"`) {
+			t.Errorf("Summary of %s = %s, %v", vm, data, err)
+		}
+	}
+	if data, err := os.ReadFile(SummaryFileName); err != nil || string(data) != `{"workflow": "db-01"}` {
+		t.Errorf("%s = %s, %v, want it unchanged", SummaryFileName, data, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	migrated = nil
+	if err := RunBatch(ctx, cfg, logger.New(false), "test", []string{"web-01", "web-02"}); err == nil || !strings.Contains(err.Error(), "2 of 2") || len(migrated) != 0 {
+		t.Errorf("RunBatch() after interrupt error = %v, migrated %v", err, migrated)
+	}
+}
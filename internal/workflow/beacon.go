@@ -0,0 +1,133 @@
+// Package workflow provides the boot beacon that reports the first boot of migrated instances.
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// bootBeaconURLValidity is how long the upload URL baked into the image stays valid. It
+// covers instances deployed stopped and started later with kopru start.
+const bootBeaconURLValidity = 30 * 24 * time.Hour
+
+// bootBeaconPollInterval is the interval between reads of the beacon object.
+const bootBeaconPollInterval = 20 * time.Second
+
+// bootBeaconObject returns the name of the Object Storage object the beacon of the
+// instance is written to. It is stable across runs; beacons of earlier instances are
+// told apart by their instance OCID.
+func bootBeaconObject(cfg *config.Config) string {
+	return "kopru-beacons/" + cfg.OCIInstanceName + ".json"
+}
+
+// prepareBootBeacon returns the URL and HTTP method the boot beacon is sent with: the
+// BOOT_BEACON_ENDPOINT, or a pre-authenticated request that allows writing the beacon
+// object into the OCI bucket, which is created if needed. The URL is empty when the
+// beacon cannot be installed.
+func prepareBootBeacon(ctx context.Context, log *logger.Logger, provider *oci.Provider, cfg *config.Config) (url, method string, err error) {
+	if !common.IsLinuxOS(cfg.OCIImageOS) {
+		log.Warningf("Skipping the boot beacon: it is only supported for Linux images, not %s", cfg.OCIImageOS)
+		return "", "", nil
+	}
+	if cfg.BootBeaconEndpoint != "" {
+		log.Infof("Boot beacon will be sent to %s", cfg.BootBeaconEndpoint)
+		return cfg.BootBeaconEndpoint, http.MethodPost, nil
+	}
+	namespace, err := provider.GetNamespace(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get namespace: %w", err)
+	}
+	bucketExists, err := provider.CheckBucketExists(ctx, namespace, cfg.OCIBucketName)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to check bucket: %w", err)
+	}
+	if !bucketExists {
+		log.Infof("Creating bucket '%s'...", cfg.OCIBucketName)
+		if err := provider.CreateBucket(ctx, namespace, cfg.OCICompartmentID, cfg.OCIBucketName); err != nil {
+			return "", "", fmt.Errorf("failed to create bucket: %w", err)
+		}
+	}
+	object := bootBeaconObject(cfg)
+	url, err = provider.CreateObjectWriteURL(ctx, namespace, cfg.OCIBucketName, object, time.Now().Add(bootBeaconURLValidity))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create boot beacon upload URL: %w", err)
+	}
+	log.Infof("Boot beacon will be written to %s/%s", cfg.OCIBucketName, object)
+	return url, http.MethodPut, nil
+}
+
+// parseBootBeacon decodes a beacon sent by the instance.
+func parseBootBeacon(data []byte, object string) (*BootBeaconResult, error) {
+	var result BootBeaconResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid boot beacon %s: %w", object, err)
+	}
+	result.Object = object
+	result.Status = StatusSucceeded
+	return &result, nil
+}
+
+// verifyBootBeacon waits for the boot beacon of the deployed instance, which proves that
+// it booted in OCI, reached the network and ran cloud-init, without SSH access. It
+// returns nil when there is no beacon to wait for: the beacon is disabled or sent to
+// BOOT_BEACON_ENDPOINT, or the instance was not deployed or not started.
+func verifyBootBeacon(ctx context.Context, log *logger.Logger, provider *oci.Provider, cfg *config.Config, instanceID string) (*BootBeaconResult, error) {
+	switch {
+	case !cfg.BootBeacon || !common.IsLinuxOS(cfg.OCIImageOS) || instanceID == "" || cfg.AttachesToInstance():
+		return nil, nil
+	case cfg.BootBeaconEndpoint != "":
+		log.Infof("Boot beacon is sent to %s, not waiting for it", cfg.BootBeaconEndpoint)
+		return nil, nil
+	case cfg.StartsStopped():
+		log.Info("The boot beacon will be sent when the instance is started")
+		return nil, nil
+	}
+	namespace, err := provider.GetNamespace(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace: %w", err)
+	}
+	object := bootBeaconObject(cfg)
+	timeout := time.Duration(cfg.BootBeaconTimeoutMinutes) * time.Minute
+	log.Infof("Waiting for the boot beacon of instance %s (timeout %s)...", instanceID, timeout)
+	deadline := time.Now().Add(timeout)
+	for {
+		data, found, err := provider.GetObject(ctx, namespace, cfg.OCIBucketName, object)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			result, err := parseBootBeacon(data, object)
+			if err != nil {
+				return nil, err
+			}
+			if result.InstanceID == instanceID {
+				log.Successf("✓ Instance booted at %s (hostname %s, kernel %s)",
+					time.Unix(result.BootTime, 0).UTC().Format(time.RFC3339), result.Hostname, result.Kernel)
+				if result.CloudInitStatus == "error" {
+					log.Warning("cloud-init reported errors on the first boot; check /var/log/cloud-init.log on the instance")
+				} else {
+					log.Successf("✓ cloud-init status: %s", result.CloudInitStatus)
+				}
+				return result, nil
+			}
+			log.Debugf("Ignoring boot beacon of instance %s", result.InstanceID)
+		}
+		if time.Now().After(deadline) {
+			result := &BootBeaconResult{Object: object, Status: StatusFailed}
+			return result, fmt.Errorf("no boot beacon from instance %s after %s; check the serial console of the instance", instanceID, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(bootBeaconPollInterval):
+		}
+	}
+}
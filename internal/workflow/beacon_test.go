@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestParseBootBeacon(t *testing.T) {
+	data := []byte(`{"instanceId":"ocid1.instance.oc1..a","bootTime":1760000000,"cloudInitStatus":"done","hostname":"web01","kernel":"5.15.0-1074-oracle"}`)
+	got, err := parseBootBeacon(data, "kopru-beacons/web01.json")
+	if err != nil {
+		t.Fatalf("parseBootBeacon() error = %v", err)
+	}
+	want := BootBeaconResult{
+		Object: "kopru-beacons/web01.json", Status: StatusSucceeded, InstanceID: "ocid1.instance.oc1..a",
+		BootTime: 1760000000, CloudInitStatus: "done", Hostname: "web01", Kernel: "5.15.0-1074-oracle",
+	}
+	if *got != want {
+		t.Errorf("parseBootBeacon() = %+v, want %+v", *got, want)
+	}
+	if _, err := parseBootBeacon([]byte("not json"), "kopru-beacons/web01.json"); err == nil {
+		t.Error("Expected an error for an invalid beacon")
+	}
+}
+
+func TestVerifyBootBeaconSkips(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.Config
+		instanceID string
+	}{
+		{"disabled", config.Config{OCIImageOS: "Ubuntu"}, "ocid1.instance.oc1..a"},
+		{"windows", config.Config{BootBeacon: true, OCIImageOS: "Windows"}, "ocid1.instance.oc1..a"},
+		{"not deployed", config.Config{BootBeacon: true, OCIImageOS: "Ubuntu"}, ""},
+		{"endpoint", config.Config{BootBeacon: true, OCIImageOS: "Ubuntu", BootBeaconEndpoint: "https://example.com/beacon"}, "ocid1.instance.oc1..a"},
+		{"stopped", config.Config{BootBeacon: true, OCIImageOS: "Ubuntu", OCIInstanceState: config.InstanceStateStopped}, "ocid1.instance.oc1..a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A nil provider proves that nothing is read from OCI
+			got, err := verifyBootBeacon(context.Background(), logger.New(false), nil, &tt.cfg, tt.instanceID)
+			if got != nil || err != nil {
+				t.Errorf("verifyBootBeacon() = %v, %v, want nil, nil", got, err)
+			}
+		})
+	}
+}
+
+func TestBootBeaconObject(t *testing.T) {
+	if got := bootBeaconObject(&config.Config{OCIInstanceName: "web01"}); got != "kopru-beacons/web01.json" {
+		t.Errorf("bootBeaconObject() = %q", got)
+	}
+}
@@ -0,0 +1,27 @@
+// Package workflow provides the optional pre-boot validation of configured images.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// validateImageBoot boots the configured image locally when PREBOOT_VALIDATION is
+// enabled, so that a broken initramfs or boot loader is found before the upload.
+func validateImageBoot(ctx context.Context, log *logger.Logger, cfg *config.Config, imageFile, workDir string) error {
+	if !cfg.PrebootValidation {
+		return nil
+	}
+	log.Info("Validating that the configured image boots ...")
+	timeout := time.Duration(cfg.PrebootTimeoutMinutes) * time.Minute
+	if err := common.BootCheckImage(ctx, imageFile, cfg.OCIImageEnableUEFI, timeout, workDir, log); err != nil {
+		return fmt.Errorf("pre-boot validation failed: %w", err)
+	}
+	log.Success("Pre-boot validation passed")
+	return nil
+}
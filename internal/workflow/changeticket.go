@@ -0,0 +1,109 @@
+// Package workflow provides the change ticket updates made at workflow start and end.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/changeticket"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// newTicketClient creates the change ticket client; tests replace it with a fake.
+var newTicketClient = changeticket.New
+
+// changeTicket is the change ticket a workflow run is recorded on.
+type changeTicket struct {
+	client changeticket.Client
+	id     string
+}
+
+// startChangeTicket records the start of a run on the configured change ticket,
+// creating the ticket when no ID is configured. It returns nil when change ticket
+// integration is disabled or the ticket could not be updated; ticket failures are
+// logged as warnings and never fail the migration.
+func startChangeTicket(ctx context.Context, cfg *config.Config, log *logger.Logger, workflowName, version string) *changeTicket {
+	if cfg.ChangeTicketSystem == "" {
+		return nil
+	}
+	if cfg.ChangeTicketURL == "" {
+		log.Warning("CHANGE_TICKET_URL is not set, skipping change ticket updates")
+		return nil
+	}
+	client, err := newTicketClient(changeticket.Options{
+		System:        cfg.ChangeTicketSystem,
+		BaseURL:       cfg.ChangeTicketURL,
+		Authorization: cfg.ChangeTicketAuthorization,
+		Project:       cfg.ChangeTicketProject,
+	})
+	if err != nil {
+		log.Warningf("%v", err)
+		return nil
+	}
+
+	message := fmt.Sprintf("Kopru %s started %s to %s at %s.\n%s",
+		version, workflowName, cfg.OCIRegion, time.Now().UTC().Format(time.RFC3339), runDescription(cfg))
+	t := &changeTicket{client: client, id: cfg.ChangeTicketID}
+	if t.id == "" {
+		if t.id, err = client.Create(ctx, fmt.Sprintf("Kopru: %s (%s)", workflowName, cfg.OCIInstanceName), message); err != nil {
+			log.Warningf("%v", err)
+			return nil
+		}
+		log.Successf("✓ Created change ticket %s", t.id)
+		return t
+	}
+	if err := client.Comment(ctx, t.id, message); err != nil {
+		log.Warningf("%v", err)
+		return nil
+	}
+	log.Successf("✓ Updated change ticket %s", t.id)
+	return t
+}
+
+// finish records the outcome of the run on the ticket and attaches the run summary.
+func (t *changeTicket) finish(ctx context.Context, log *logger.Logger, s *RunSummary) {
+	if t == nil {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Kopru %s %s at %s after %s.\n", s.Workflow, s.Status,
+		s.FinishedAt.Format(time.RFC3339), time.Duration(s.DurationSeconds*float64(time.Second)).Round(time.Second))
+	if s.Error != "" {
+		fmt.Fprintf(&b, "Error: %s\n", s.Error)
+	}
+	if s.Artifacts.ImageID != "" {
+		fmt.Fprintf(&b, "Image: %s\n", s.Artifacts.ImageID)
+	}
+	if s.Artifacts.InstanceID != "" {
+		fmt.Fprintf(&b, "Instance: %s\n", s.Artifacts.InstanceID)
+	}
+	if err := t.client.Comment(ctx, t.id, b.String()); err != nil {
+		log.Warningf("%v", err)
+		return
+	}
+
+	data, err := s.JSON()
+	if err == nil {
+		err = t.client.Attach(ctx, t.id, SummaryFileName, data)
+	}
+	if err != nil {
+		log.Warningf("%v", err)
+		return
+	}
+	log.Successf("✓ Change ticket %s updated with the migration report", t.id)
+}
+
+// runDescription describes the source and target of a run for the ticket.
+func runDescription(cfg *config.Config) string {
+	var source string
+	switch cfg.SourcePlatform {
+	case "azure":
+		source = fmt.Sprintf("Azure VM %s in resource group %s", cfg.AzureComputeName, cfg.AzureResourceGroup)
+	default:
+		source = fmt.Sprintf("Image %s", cfg.OSImageURL)
+	}
+	return fmt.Sprintf("Source: %s\nTarget: OCI instance %s in compartment %s", source, cfg.OCIInstanceName, cfg.OCICompartmentID)
+}
@@ -0,0 +1,69 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/changeticket"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+type fakeTicketClient struct {
+	created     string
+	comments    []string
+	attachments []string
+	commentErr  error
+}
+
+func (c *fakeTicketClient) Create(_ context.Context, _, description string) (string, error) {
+	c.created = description
+	return "OPS-1", nil
+}
+
+func (c *fakeTicketClient) Comment(_ context.Context, id, text string) error {
+	c.comments = append(c.comments, id+": "+text)
+	return c.commentErr
+}
+
+func (c *fakeTicketClient) Attach(_ context.Context, id, fileName string, _ []byte) error {
+	c.attachments = append(c.attachments, id+": "+fileName)
+	return nil
+}
+
+func TestChangeTicket(t *testing.T) {
+	fake := &fakeTicketClient{}
+	orig := newTicketClient
+	t.Cleanup(func() { newTicketClient = orig })
+	newTicketClient = func(changeticket.Options) (changeticket.Client, error) { return fake, nil }
+	log := logger.New(false)
+	ctx := context.Background()
+
+	if startChangeTicket(ctx, &config.Config{}, log, "Azure to OCI Migration", "1.0.0") != nil {
+		t.Error("Expected no ticket when the integration is disabled")
+	}
+
+	cfg := &config.Config{ChangeTicketSystem: "jira", ChangeTicketURL: "https://example.atlassian.net", SourcePlatform: "azure", AzureComputeName: "vm1"}
+	ticket := startChangeTicket(ctx, cfg, log, "Azure to OCI Migration", "1.0.0")
+	if ticket == nil || ticket.id != "OPS-1" || !strings.Contains(fake.created, "Azure VM vm1") {
+		t.Fatalf("Expected created ticket, got %+v (%q)", ticket, fake.created)
+	}
+
+	ticket.finish(ctx, log, &RunSummary{Workflow: "Azure to OCI Migration", Status: StatusFailed, Error: "upload failed"})
+	if len(fake.comments) != 1 || !strings.Contains(fake.comments[0], "Error: upload failed") {
+		t.Errorf("Unexpected comments: %q", fake.comments)
+	}
+	if len(fake.attachments) != 1 || fake.attachments[0] != "OPS-1: "+SummaryFileName {
+		t.Errorf("Unexpected attachments: %q", fake.attachments)
+	}
+
+	fake.commentErr = errors.New("unauthorized")
+	cfg.ChangeTicketID = "OPS-2"
+	if startChangeTicket(ctx, cfg, log, "Azure to OCI Migration", "1.0.0") != nil {
+		t.Error("Expected no ticket when the ticket cannot be updated")
+	}
+	var none *changeTicket
+	none.finish(ctx, log, &RunSummary{})
+}
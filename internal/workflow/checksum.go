@@ -0,0 +1,93 @@
+// Package workflow provides the integrity checks of migrated disks recorded in the run summary.
+package workflow
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// checksumLog collects the checksum results of a run. Results may be recorded by
+// concurrent data disk workers.
+type checksumLog struct {
+	mu      sync.Mutex
+	results []ChecksumResult
+}
+
+func (c *checksumLog) record(r ChecksumResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results = append(c.results, r)
+}
+
+// list returns the recorded results.
+func (c *checksumLog) list() []ChecksumResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ChecksumResult(nil), c.results...)
+}
+
+// hashFile records the SHA-256 of an artifact file.
+func (c *checksumLog) hashFile(log *logger.Logger, artifact, file string) error {
+	sum, err := common.SHA256File(file, log)
+	if err != nil {
+		return err
+	}
+	log.Infof("SHA-256 of %s: %s", file, sum)
+	c.record(ChecksumResult{Artifact: artifact, File: file, SHA256: sum, Status: StatusSucceeded})
+	return nil
+}
+
+// verifyConversion checks that the converted image has the same content as the source
+// and records the SHA-256 of the converted image.
+func (c *checksumLog) verifyConversion(log *logger.Logger, artifact, source, sourceFormat, converted, convertedFormat string) error {
+	result := ChecksumResult{Artifact: artifact, File: converted, VerifiedAgainst: source + " (qemu-img compare)", Status: StatusSucceeded}
+	if err := common.CompareImages(source, sourceFormat, converted, convertedFormat); err != nil {
+		result.Status = StatusFailed
+		c.record(result)
+		return err
+	}
+	sum, err := common.SHA256File(converted, log)
+	if err != nil {
+		return err
+	}
+	result.SHA256 = sum
+	c.record(result)
+	log.Successf("✓ Converted image matches %s (SHA-256 %s)", source, sum)
+	return nil
+}
+
+// verifyUpload compares the MD5 computed locally for an uploaded file with the MD5
+// reported by Object Storage.
+func (c *checksumLog) verifyUpload(log *logger.Logger, file string, hashes common.FileHashes, reported string) error {
+	result := ChecksumResult{
+		Artifact: ArtifactUploadedObject, File: file, SHA256: hashes.SHA256, ObjectMD5: hashes.ObjectMD5,
+		VerifiedAgainst: "Object Storage MD5", Status: StatusSucceeded,
+	}
+	if reported != hashes.ObjectMD5 {
+		result.Status = StatusFailed
+		c.record(result)
+		return fmt.Errorf("uploaded object MD5 %q does not match the local MD5 %q", reported, hashes.ObjectMD5)
+	}
+	c.record(result)
+	log.Successf("✓ Uploaded object matches the local file (MD5 %s)", reported)
+	return nil
+}
+
+// verifyDeviceCopy checks that the block volume attached at device holds source, the
+// RAW image or NBD device it was copied from, by full SHA-256 or sampled blocks.
+func (c *checksumLog) verifyDeviceCopy(log *logger.Logger, source, device, volumeName, mode string) error {
+	result := ChecksumResult{
+		Artifact: ArtifactBlockVolumes, File: source,
+		VerifiedAgainst: fmt.Sprintf("block volume %s (%s)", volumeName, mode), Status: StatusSucceeded,
+	}
+	sum, err := common.VerifyDeviceCopy(source, device, mode, log)
+	result.SHA256 = sum
+	if err != nil {
+		result.Status = StatusFailed
+	}
+	c.record(result)
+	return err
+}
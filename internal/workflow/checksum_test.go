@@ -0,0 +1,26 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestVerifyUpload(t *testing.T) {
+	var c checksumLog
+	hashes := common.FileHashes{SHA256: "abc", ObjectMD5: "bWQ1-2"}
+	if err := c.verifyUpload(logger.New(false), "image.qcow2", hashes, "bWQ1-2"); err != nil {
+		t.Errorf("Expected matching MD5 to verify, got %v", err)
+	}
+	if err := c.verifyUpload(logger.New(false), "image.qcow2", hashes, "b3RoZXI=-2"); err == nil {
+		t.Error("Expected an error for a mismatching MD5")
+	}
+	results := c.list()
+	if len(results) != 2 || results[0].Status != StatusSucceeded || results[1].Status != StatusFailed {
+		t.Errorf("Unexpected checksum results: %+v", results)
+	}
+	if results[0].SHA256 != "abc" || results[0].Artifact != ArtifactUploadedObject {
+		t.Errorf("Expected SHA-256 and artifact to be recorded, got %+v", results[0])
+	}
+}
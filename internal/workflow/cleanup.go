@@ -0,0 +1,123 @@
+// Package workflow provides the cleanup manager that tears down temporary resources of a run.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// cleanupTimeout bounds each teardown action, which runs with a context of its own so
+// that it completes after the run is interrupted.
+const cleanupTimeout = 10 * time.Minute
+
+// cleanupAction is a registered teardown action.
+type cleanupAction struct {
+	name string
+	fn   func(context.Context) error
+}
+
+// cleanupStack collects the teardown actions of temporary resources created by the
+// steps of a run, such as snapshots, SAS grants and volume attachments. A step runs an
+// action as soon as the resource is no longer needed; the actions still pending when the
+// run ends, because a step failed or the run was interrupted, are run last in first out
+// by unwind. A nil stack runs actions only when the step does.
+type cleanupStack struct {
+	log     *logger.Logger
+	mu      sync.Mutex
+	actions []*cleanupAction
+}
+
+func newCleanupStack(log *logger.Logger) *cleanupStack {
+	return &cleanupStack{log: log}
+}
+
+// push registers the teardown action fn and returns a function that runs it once and
+// unregisters it. Calling the function again, or after unwind, does nothing.
+func (s *cleanupStack) push(name string, fn func(context.Context) error) func() {
+	action := &cleanupAction{name: name, fn: fn}
+	if s != nil {
+		s.mu.Lock()
+		s.actions = append(s.actions, action)
+		s.mu.Unlock()
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if s.remove(action) {
+				if err := runCleanupAction(action); err != nil && s != nil {
+					s.log.Warning(i18n.T("cleanup.failed", name, err))
+				}
+			}
+		})
+	}
+}
+
+// remove unregisters action and reports whether it was still pending.
+func (s *cleanupStack) remove(action *cleanupAction) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, a := range s.actions {
+		if a == action {
+			s.actions = append(s.actions[:i], s.actions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// pending returns the number of registered actions that have not run.
+func (s *cleanupStack) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.actions)
+}
+
+// unwind runs the pending actions, the most recently registered first, and returns the
+// errors of those that failed. All actions are attempted.
+func (s *cleanupStack) unwind() error {
+	var errs []error
+	for {
+		s.mu.Lock()
+		if len(s.actions) == 0 {
+			s.mu.Unlock()
+			return errors.Join(errs...)
+		}
+		action := s.actions[len(s.actions)-1]
+		s.actions = s.actions[:len(s.actions)-1]
+		s.mu.Unlock()
+		s.log.Info(i18n.T("cleanup.running", action.name))
+		if err := runCleanupAction(action); err != nil {
+			s.log.Warning(i18n.T("cleanup.failed", action.name, err))
+			errs = append(errs, fmt.Errorf("%s: %w", action.name, err))
+		}
+	}
+}
+
+// runCleanupAction runs action with a context of its own, bounded by cleanupTimeout.
+func runCleanupAction(action *cleanupAction) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	return action.fn(ctx)
+}
+
+type cleanupKey struct{}
+
+// withCleanup returns a context through which steps register teardown actions with s.
+func withCleanup(ctx context.Context, s *cleanupStack) context.Context {
+	return context.WithValue(ctx, cleanupKey{}, s)
+}
+
+// cleanupFromContext returns the cleanup stack of the run, or nil outside of a run.
+func cleanupFromContext(ctx context.Context) *cleanupStack {
+	s, _ := ctx.Value(cleanupKey{}).(*cleanupStack)
+	return s
+}
@@ -0,0 +1,71 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestCleanupStack(t *testing.T) {
+	var ran []string
+	action := func(name string, err error) func(context.Context) error {
+		return func(ctx context.Context) error {
+			if ctx.Err() != nil {
+				t.Errorf("%s ran with a done context", name)
+			}
+			ran = append(ran, name)
+			return err
+		}
+	}
+	s := newCleanupStack(logger.New(false))
+	s.push("delete snapshot", action("delete snapshot", nil))
+	revoke := s.push("revoke access", action("revoke access", nil))
+	s.push("detach volume", action("detach volume", errors.New("conflict")))
+	s.push("remove directory", action("remove directory", nil))
+
+	revoke()
+	revoke()
+	if s.pending() != 3 {
+		t.Fatalf("pending() = %d after running one of 4 actions, want 3", s.pending())
+	}
+	err := s.unwind()
+	if err == nil {
+		t.Error("Expected the error of the failed action")
+	}
+	want := []string{"revoke access", "remove directory", "detach volume", "delete snapshot"}
+	if !slices.Equal(ran, want) {
+		t.Errorf("Actions ran in order %v, want %v", ran, want)
+	}
+	if s.pending() != 0 {
+		t.Errorf("pending() = %d after unwind, want 0", s.pending())
+	}
+}
+
+func TestCleanupStackRunAfterUnwind(t *testing.T) {
+	runs := 0
+	s := newCleanupStack(logger.New(false))
+	run := s.push("delete snapshot", func(context.Context) error { runs++; return nil })
+	_ = s.unwind()
+	run()
+	if runs != 1 {
+		t.Errorf("Action ran %d times, want 1", runs)
+	}
+}
+
+func TestCleanupFromContext(t *testing.T) {
+	if s := cleanupFromContext(context.Background()); s != nil {
+		t.Fatalf("Expected no cleanup stack outside of a run")
+	}
+	runs := 0
+	cleanupFromContext(context.Background()).push("remove file", func(context.Context) error { runs++; return nil })()
+	if runs != 1 {
+		t.Errorf("Action without a stack ran %d times, want 1", runs)
+	}
+	s := newCleanupStack(logger.New(false))
+	if cleanupFromContext(withCleanup(context.Background(), s)) != s {
+		t.Error("Expected the cleanup stack of the context")
+	}
+}
@@ -0,0 +1,215 @@
+// Package workflow provides the CMDB record exported after each workflow run.
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// CMDBFilePrefix is the name, without extension, of the CMDB record written to the current directory.
+const CMDBFilePrefix = "kopru-cmdb"
+
+const cmdbPushTimeout = 30 * time.Second
+
+// CMDBRecord is a flat description of a migrated instance for configuration management
+// databases. Multi-valued fields are joined with ";" so that every field maps to a
+// single string column, as expected by ServiceNow import sets and CSV imports.
+type CMDBRecord struct {
+	Name                string `json:"name"`
+	SourcePlatform      string `json:"source_platform"`
+	SourceResourceID    string `json:"source_resource_id"`
+	SourceOSDiskID      string `json:"source_os_disk_id"`
+	SourceDataDiskIDs   string `json:"source_data_disk_ids"`
+	SourceSubscription  string `json:"source_subscription_id"`
+	SourceResourceGroup string `json:"source_resource_group"`
+	SourceImageURL      string `json:"source_image_url"`
+	TargetPlatform      string `json:"target_platform"`
+	TargetRegion        string `json:"target_region"`
+	TargetCompartmentID string `json:"target_compartment_id"`
+	TargetInstanceID    string `json:"target_instance_id"`
+	TargetImageID       string `json:"target_image_id"`
+	TargetVolumeIDs     string `json:"target_volume_ids"`
+	PrivateIPs          string `json:"private_ips"`
+	PublicIPs           string `json:"public_ips"`
+	Owner               string `json:"owner"`
+	Tags                string `json:"tags"`
+	MigrationStatus     string `json:"migration_status"`
+	StartedAt           string `json:"started_at"`
+	FinishedAt          string `json:"finished_at"`
+	KopruVersion        string `json:"kopru_version"`
+}
+
+// newCMDBRecord builds the CMDB record of a finished run.
+func newCMDBRecord(cfg *config.Config, s *RunSummary) CMDBRecord {
+	r := CMDBRecord{
+		Name:                cfg.OCIInstanceName,
+		SourcePlatform:      s.Source["platform"],
+		SourceResourceID:    s.Source["resourceId"],
+		SourceOSDiskID:      s.Source["osDiskId"],
+		SourceDataDiskIDs:   strings.ReplaceAll(s.Source["dataDiskIds"], ",", ";"),
+		SourceSubscription:  s.Source["subscriptionId"],
+		SourceResourceGroup: s.Source["resourceGroup"],
+		SourceImageURL:      s.Source["imageUrl"],
+		TargetPlatform:      cfg.TargetPlatform,
+		TargetRegion:        cfg.OCIRegion,
+		TargetCompartmentID: cfg.OCICompartmentID,
+		TargetInstanceID:    s.Artifacts.InstanceID,
+		TargetImageID:       s.Artifacts.ImageID,
+		TargetVolumeIDs:     strings.Join(s.Artifacts.DataVolumeIDs, ";"),
+		PrivateIPs:          strings.Join(s.Artifacts.PrivateIPs, ";"),
+		PublicIPs:           strings.Join(s.Artifacts.PublicIPs, ";"),
+		MigrationStatus:     s.Status,
+		StartedAt:           s.StartedAt.Format(time.RFC3339),
+		FinishedAt:          s.FinishedAt.Format(time.RFC3339),
+		KopruVersion:        s.Version,
+	}
+
+	keys := make([]string, 0, len(s.SourceTags))
+	for k := range s.SourceTags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tags := make([]string, 0, len(keys))
+	for _, k := range keys {
+		tags = append(tags, k+"="+s.SourceTags[k])
+		if strings.EqualFold(k, "owner") {
+			r.Owner = s.SourceTags[k]
+		}
+	}
+	r.Tags = strings.Join(tags, ";")
+	return r
+}
+
+// columns returns the field names and values of the record in declaration order.
+func (r CMDBRecord) columns() (header, values []string) {
+	v := reflect.ValueOf(r)
+	t := v.Type()
+	for i := range t.NumField() {
+		header = append(header, t.Field(i).Tag.Get("json"))
+		values = append(values, v.Field(i).String())
+	}
+	return header, values
+}
+
+// WriteCSV writes the record as a CSV header line followed by a single row.
+func (r CMDBRecord) WriteCSV(w io.Writer) error {
+	header, values := r.columns()
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll([][]string{header, values}); err != nil {
+		return fmt.Errorf("failed to encode CMDB record: %w", err)
+	}
+	return nil
+}
+
+// WriteJSON writes the record as an indented JSON object.
+func (r CMDBRecord) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("failed to encode CMDB record: %w", err)
+	}
+	return nil
+}
+
+// Write saves the record in the given format (json or csv) and returns the file name.
+func (r CMDBRecord) Write(format string) (string, error) {
+	if format == "" {
+		format = "json"
+	}
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "json":
+		err = r.WriteJSON(&buf)
+	case "csv":
+		err = r.WriteCSV(&buf)
+	default:
+		return "", fmt.Errorf("unsupported CMDB format %q", format)
+	}
+	if err != nil {
+		return "", err
+	}
+	path := CMDBFilePrefix + "." + format
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return "", fmt.Errorf("failed to write CMDB record: %w", err)
+	}
+	return path, nil
+}
+
+// Push posts the record as JSON to endpoint, sending authorization verbatim as the
+// Authorization header when set.
+func (r CMDBRecord) Push(ctx context.Context, endpoint, authorization string) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode CMDB record: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, cmdbPushTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create CMDB request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send CMDB record: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("CMDB endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// exportCMDBRecord writes the CMDB record of a finished run and, after a successful
+// run, pushes it to the configured endpoint. Failures are logged as warnings since
+// the migration itself has already completed.
+func exportCMDBRecord(ctx context.Context, cfg *config.Config, log *logger.Logger, s *RunSummary) {
+	record := newCMDBRecord(cfg, s)
+	if path, err := record.Write(cfg.CMDBFormat); err != nil {
+		log.Warningf("%v", err)
+	} else {
+		log.Infof("CMDB record written to %s", path)
+	}
+	if cfg.CMDBEndpoint == "" || s.Status != StatusSucceeded {
+		return
+	}
+	if err := record.Push(ctx, cfg.CMDBEndpoint, cfg.CMDBAuthorization); err != nil {
+		log.Warningf("%v", err)
+		return
+	}
+	log.Successf("✓ CMDB record sent to %s", cfg.CMDBEndpoint)
+}
+
+// lookupInstanceIPs returns the IP addresses of a deployed instance for the run summary.
+// Lookup failures are logged as warnings and yield no addresses.
+func lookupInstanceIPs(ctx context.Context, log *logger.Logger, provider *oci.Provider, compartmentID, instanceID string) (privateIPs, publicIPs []string) {
+	if instanceID == "" {
+		return nil, nil
+	}
+	privateIPs, publicIPs, err := provider.GetInstanceIPs(ctx, compartmentID, instanceID)
+	if err != nil {
+		log.Warningf("Could not read IP addresses of instance %s: %v", instanceID, err)
+		return nil, nil
+	}
+	return privateIPs, publicIPs
+}
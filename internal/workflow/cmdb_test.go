@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func testCMDBSummary() *RunSummary {
+	return &RunSummary{
+		Version:    "1.2.3",
+		Status:     StatusSucceeded,
+		StartedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		FinishedAt: time.Date(2026, 1, 2, 4, 4, 5, 0, time.UTC),
+		Source: map[string]string{
+			"platform":    "azure",
+			"resourceId":  "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm1",
+			"dataDiskIds": "/disks/data1,/disks/data2",
+		},
+		SourceTags: map[string]string{"env": "prod", "Owner": "team-a"},
+		Artifacts: SummaryArtifacts{
+			ImageID:       "ocid1.image.oc1..example",
+			DataVolumeIDs: []string{"ocid1.volume.oc1..a", "ocid1.volume.oc1..b"},
+			InstanceID:    "ocid1.instance.oc1..example",
+			PrivateIPs:    []string{"10.0.0.5"},
+		},
+	}
+}
+
+func TestNewCMDBRecord(t *testing.T) {
+	cfg := &config.Config{OCIInstanceName: "vm1", OCIRegion: "us-ashburn-1", TargetPlatform: "oci"}
+	r := newCMDBRecord(cfg, testCMDBSummary())
+
+	if r.Owner != "team-a" {
+		t.Errorf("Owner = %q, want team-a", r.Owner)
+	}
+	if r.Tags != "Owner=team-a;env=prod" {
+		t.Errorf("Tags = %q", r.Tags)
+	}
+	if r.SourceDataDiskIDs != "/disks/data1;/disks/data2" {
+		t.Errorf("SourceDataDiskIDs = %q", r.SourceDataDiskIDs)
+	}
+	if r.TargetVolumeIDs != "ocid1.volume.oc1..a;ocid1.volume.oc1..b" {
+		t.Errorf("TargetVolumeIDs = %q", r.TargetVolumeIDs)
+	}
+	if r.StartedAt != "2026-01-02T03:04:05Z" || r.MigrationStatus != StatusSucceeded {
+		t.Errorf("Unexpected record: %+v", r)
+	}
+}
+
+func TestCMDBRecordWriteCSV(t *testing.T) {
+	r := newCMDBRecord(&config.Config{OCIInstanceName: "vm1"}, testCMDBSummary())
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected header and one row, got %d lines", len(lines))
+	}
+	if !strings.HasPrefix(lines[0], "name,source_platform,source_resource_id,") {
+		t.Errorf("Unexpected header: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "vm1,azure,/subscriptions/sub/") {
+		t.Errorf("Unexpected row: %s", lines[1])
+	}
+}
+
+func TestCMDBRecordPush(t *testing.T) {
+	var got CMDBRecord
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	r := newCMDBRecord(&config.Config{OCIInstanceName: "vm1"}, testCMDBSummary())
+	if err := r.Push(context.Background(), server.URL, "Bearer secret"); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if got.TargetInstanceID != "ocid1.instance.oc1..example" {
+		t.Errorf("Unexpected pushed record: %+v", got)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid table", http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	if err := r.Push(context.Background(), failing.URL, ""); err == nil || !strings.Contains(err.Error(), "invalid table") {
+		t.Errorf("Expected error with response body, got %v", err)
+	}
+}
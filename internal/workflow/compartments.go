@@ -0,0 +1,33 @@
+// Package workflow provides the resolution of OCI compartment paths with the Identity API.
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// RegisterCompartmentResolver lets OCI_COMPARTMENT_ID name a compartment by its path
+// below the root compartment of the tenancy, e.g. prod/app-team.
+func RegisterCompartmentResolver() {
+	config.RegisterCompartmentResolver(resolveCompartmentPath)
+}
+
+// resolveCompartmentPath looks up the compartment at path with the OCI CLI configuration
+// or session token.
+func resolveCompartmentPath(ctx context.Context, cfg *config.Config, path string) (string, error) {
+	log := logger.New(cfg.Debug)
+	provider, err := oci.NewProvider(cfg.OCIRegion, log)
+	if err != nil {
+		return "", fmt.Errorf("failed to create OCI provider: %w", err)
+	}
+	id, err := provider.ResolveCompartmentPath(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	log.Infof("Resolved compartment path '%s' to %s", path, id)
+	return id, nil
+}
@@ -0,0 +1,107 @@
+// Package workflow provides discovery of external OS configurators for workflow handlers.
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// loadConfigurators loads the external configurators from CONFIGURATORS_DIR (or
+// ~/.kopru/configurators), resolves the configuration chain and checks the package
+// cache during the prerequisite checks, so that invalid definitions fail the run before
+// any disk is exported.
+func loadConfigurators(log *logger.Logger, cfg *config.Config) ([]common.Configurator, error) {
+	configurators, err := common.LoadConfigurators(configuratorsDir(cfg))
+	if err != nil {
+		return nil, err
+	}
+	chain, err := resolveConfigureChain(cfg, configurators)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCustomScripts(cfg, chain); err != nil {
+		return nil, err
+	}
+	if err := checkPackageCacheDir(cfg); err != nil {
+		return nil, err
+	}
+	for _, c := range configurators {
+		log.Successf("✓ Loaded configurator: %s", c.Name)
+	}
+	return configurators, nil
+}
+
+func configuratorsDir(cfg *config.Config) string {
+	if cfg.ConfiguratorsDir != "" {
+		return cfg.ConfiguratorsDir
+	}
+	return common.DefaultConfiguratorsDir()
+}
+
+// configureLink is a resolved step of the configuration chain: the CONFIGURE_ENGINE
+// configuration, or configurators applied with virt-customize.
+type configureLink struct {
+	builtin       bool
+	configurators []common.Configurator
+}
+
+// resolveConfigureChain maps the CONFIGURE_CHAIN steps to the loaded configurators and
+// the local scripts they name.
+func resolveConfigureChain(cfg *config.Config, configurators []common.Configurator) ([]configureLink, error) {
+	var links []configureLink
+	for _, step := range cfg.ConfigureChainSteps() {
+		switch step.Kind {
+		case config.ChainBuiltin:
+			links = append(links, configureLink{builtin: true})
+		case config.ChainConfigurators:
+			links = append(links, configureLink{configurators: configurators})
+		case config.ChainConfigurator:
+			i := slices.IndexFunc(configurators, func(c common.Configurator) bool { return c.Name == step.Arg })
+			if i < 0 {
+				return nil, fmt.Errorf("configurator %s of CONFIGURE_CHAIN not found in %s", step.Arg, configuratorsDir(cfg))
+			}
+			links = append(links, configureLink{configurators: configurators[i : i+1]})
+		case config.ChainScript:
+			c, err := common.ScriptConfigurator(step.Arg)
+			if err != nil {
+				return nil, err
+			}
+			links = append(links, configureLink{configurators: []common.Configurator{c}})
+		}
+	}
+	return links, nil
+}
+
+// checkCustomScripts requires ACCEPT_CUSTOM_SCRIPT when the configuration chain runs
+// custom scripts, which virt-customize runs as root in the image with sudo.
+func checkCustomScripts(cfg *config.Config, chain []configureLink) error {
+	var scripts []string
+	for _, link := range chain {
+		for _, c := range link.configurators {
+			scripts = append(scripts, c.Scripts()...)
+		}
+	}
+	if len(scripts) == 0 || cfg.AcceptCustomScript {
+		return nil
+	}
+	return fmt.Errorf("custom scripts run as root in the image with sudo: %s; review them and set --accept-custom-script (ACCEPT_CUSTOM_SCRIPT=true) to run them",
+		strings.Join(scripts, ", "))
+}
+
+// checkPackageCacheDir checks that PACKAGE_CACHE_DIR, when set, is a directory. Its
+// repository metadata is checked against the guest OS when it is attached.
+func checkPackageCacheDir(cfg *config.Config) error {
+	if cfg.PackageCacheDir == "" {
+		return nil
+	}
+	if info, err := os.Stat(cfg.PackageCacheDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("package cache %s (PACKAGE_CACHE_DIR) is not a directory", cfg.PackageCacheDir)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func TestResolveConfigureChain(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "fix.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	configurators := []common.Configurator{{Name: "motd"}, {Name: "sles"}}
+
+	cfg := &config.Config{ConfigureChain: "builtin,script:" + script + ",configurator:sles"}
+	links, err := resolveConfigureChain(cfg, configurators)
+	if err != nil {
+		t.Fatalf("resolveConfigureChain() error = %v", err)
+	}
+	if len(links) != 3 || !links[0].builtin || links[1].configurators[0].Name != "fix.sh" || links[2].configurators[0].Name != "sles" {
+		t.Errorf("Unexpected chain: %+v", links)
+	}
+
+	links, err = resolveConfigureChain(&config.Config{}, configurators)
+	if err != nil || len(links) != 2 || !links[0].builtin || len(links[1].configurators) != 2 {
+		t.Errorf("Expected builtin followed by all configurators by default, got %+v, %v", links, err)
+	}
+
+	for _, chain := range []string{"configurator:missing", "script:" + filepath.Join(dir, "missing.sh")} {
+		if _, err := resolveConfigureChain(&config.Config{ConfigureChain: chain, ConfiguratorsDir: dir}, configurators); err == nil {
+			t.Errorf("Expected error for CONFIGURE_CHAIN=%s", chain)
+		}
+	}
+}
+
+func TestCheckCustomScripts(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "fix.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{ConfigureChain: "builtin,script:" + script}
+	chain, err := resolveConfigureChain(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkCustomScripts(cfg, chain); err == nil || !strings.Contains(err.Error(), script) {
+		t.Errorf("Expected error naming %s without --accept-custom-script, got %v", script, err)
+	}
+	cfg.AcceptCustomScript = true
+	if err := checkCustomScripts(cfg, chain); err != nil {
+		t.Errorf("Expected accepted scripts to pass, got %v", err)
+	}
+	if err := checkCustomScripts(&config.Config{}, []configureLink{{builtin: true}}); err != nil {
+		t.Errorf("Expected no acknowledgment without scripts, got %v", err)
+	}
+}
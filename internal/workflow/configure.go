@@ -0,0 +1,150 @@
+// Package workflow provides the configuration stack applied to images for OCI.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// imageConfiguration is the configuration stack applied to a QCOW2 image: the boot
+// beacon, the steps of the configuration chain (the virt-v2v conversion or the built-in
+// OS scripts, the external configurators and local scripts), scrubbing and the pre-boot
+// validation.
+type imageConfiguration struct {
+	cfg            *config.Config
+	log            *logger.Logger
+	sourcePlatform string
+	engine         string
+	configurators  []common.Configurator
+	guest          guestExecution
+	beaconURL      string // Boot beacon upload URL; no beacon is installed when empty
+	beaconMethod   string
+}
+
+// apply configures imageFile in place. Only the virt-v2v conversion applies to images
+// that are not Linux; workDir receives the pre-boot validation logs.
+func (c imageConfiguration) apply(ctx context.Context, imageFile, workDir string, linux bool) error {
+	if !linux && c.engine != common.ConfigureEngineVirtV2V {
+		c.log.Infof("Skipping image configuration for %s OS", c.cfg.OCIImageOS)
+		return nil
+	}
+	chain, err := resolveConfigureChain(c.cfg, c.configurators)
+	if err != nil {
+		return err
+	}
+	luksKey, cleanup, err := luksKeySelector(c.cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if linux {
+		if c.guest, err = resolveGuestExecution(c.log, c.cfg, imageFile, luksKey); err != nil {
+			return err
+		}
+	}
+	if linux {
+		if err := checkGuestSubscriptions(c.log, c.cfg, imageFile, luksKey); err != nil {
+			return err
+		}
+	}
+	if linux && c.beaconURL != "" {
+		if err := common.InstallBootBeacon(imageFile, c.beaconURL, c.beaconMethod, luksKey, c.log); err != nil {
+			return err
+		}
+	}
+	if err := c.applyChain(imageFile, luksKey, chain, linux); err != nil {
+		return err
+	}
+	if linux {
+		if err := scrubImage(c.log, c.cfg, imageFile, luksKey); err != nil {
+			return err
+		}
+		if c.guest.mismatch && c.cfg.PrebootValidation {
+			c.log.Warning("Skipping pre-boot validation: the image cannot be booted with the hypervisor of the migration host")
+		} else if err := validateImageBoot(ctx, c.log, c.cfg, imageFile, workDir); err != nil {
+			return err
+		}
+	}
+	c.log.Success("Image configurations complete")
+	return nil
+}
+
+// applyChain runs the steps of the configuration chain, with the package repositories
+// of Linux images replaced by PACKAGE_CACHE_DIR when it is set.
+func (c imageConfiguration) applyChain(imageFile, luksKey string, chain []configureLink, linux bool) (err error) {
+	if linux && c.cfg.PackageCacheDir != "" && c.guest.deferred {
+		c.log.Warning("Skipping the package cache: package installs are deferred to the first boot and use the repositories of the image")
+	} else if linux && c.cfg.PackageCacheDir != "" {
+		detach, err := common.AttachPackageCache(imageFile, c.cfg.PackageCacheDir, luksKey, c.log)
+		if err != nil {
+			return err
+		}
+		defer func() { err = errors.Join(err, detach()) }()
+	}
+	for _, link := range chain {
+		switch {
+		case link.builtin:
+			if err := c.applyEngine(imageFile, luksKey); err != nil {
+				return err
+			}
+		case linux:
+			if err := common.ApplyConfigurators(imageFile, c.sourcePlatform, luksKey, link.configurators, c.guest.deferred, c.log); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyEngine runs the virt-v2v conversion or the built-in OS configuration script.
+func (c imageConfiguration) applyEngine(imageFile, luksKey string) error {
+	if c.engine == common.ConfigureEngineVirtV2V {
+		return common.ConvertWithVirtV2V(imageFile, luksKey, c.log)
+	}
+	c.log.Info("Applying OS configurations ...")
+	opts, err := osConfigOptions(c.log, c.cfg)
+	if err != nil {
+		return err
+	}
+	opts.LUKSKey = luksKey
+	opts.DeferGuestCommands = c.guest.deferred
+	if err := common.ExecuteOSConfigScript(imageFile, c.cfg.OCIImageOS, c.sourcePlatform, opts, c.log); err != nil {
+		return fmt.Errorf("failed to execute OS configuration script: %w", err)
+	}
+	return nil
+}
+
+// ConfigureImage applies the configuration stack of a migration from sourcePlatform to
+// a local QCOW2 image in place, without any cloud steps, so that guest fixups can be
+// validated repeatedly. The OS is taken from OCI_IMAGE_OS.
+func ConfigureImage(ctx context.Context, cfg *config.Config, log *logger.Logger, imageFile, sourcePlatform string) error {
+	if _, err := os.Stat(imageFile); err != nil {
+		return fmt.Errorf("image not found: %w", err)
+	}
+	tools := append([]string{"virt-customize"}, optionalTools(cfg)...)
+	for _, tool := range tools {
+		if err := common.CheckCommand(tool); err != nil {
+			return err
+		}
+	}
+	configurators, err := loadConfigurators(log, cfg)
+	if err != nil {
+		return err
+	}
+	c := imageConfiguration{
+		cfg:            cfg,
+		log:            log,
+		sourcePlatform: sourcePlatform,
+		engine:         resolveConfigureEngine(log, cfg),
+		configurators:  configurators,
+	}
+	log.Infof("Configuring QCOW2 file: %s", imageFile)
+	return c.apply(ctx, imageFile, filepath.Dir(imageFile), common.IsLinuxOS(cfg.OCIImageOS))
+}
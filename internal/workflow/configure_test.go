@@ -0,0 +1,19 @@
+package workflow
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestConfigureImageMissingImage(t *testing.T) {
+	cfg := &config.Config{OCIImageOS: "Ubuntu"}
+	err := ConfigureImage(context.Background(), cfg, logger.New(false), filepath.Join(t.TempDir(), "missing.qcow2"), "azure")
+	if err == nil || !strings.Contains(err.Error(), "image not found") {
+		t.Errorf("Expected image not found error, got %v", err)
+	}
+}
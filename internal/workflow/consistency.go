@@ -0,0 +1,106 @@
+// Package workflow provides the application-consistent snapshots of running Azure VMs.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// SnapshotConsistencyFsfreeze freezes the filesystems of running Linux VMs while their
+// disks are snapshotted. The default, crash, snapshots each disk as it is.
+const SnapshotConsistencyFsfreeze = "fsfreeze"
+
+// quiesceConfigured reports whether running VMs are quiesced before their disks are snapshotted.
+func quiesceConfigured(cfg *config.Config) bool {
+	return cfg.AzureSnapshotConsistency == SnapshotConsistencyFsfreeze || cfg.AzurePreSnapshotScript != "" || cfg.AzurePostSnapshotScript != ""
+}
+
+// snapshotQuiesce returns how a running VM is quiesced before its disks are snapshotted,
+// or nil when each disk is snapshotted crash-consistently on its own.
+func snapshotQuiesce(cfg *config.Config) (*azure.Quiesce, error) {
+	if !quiesceConfigured(cfg) {
+		return nil, nil
+	}
+	q := &azure.Quiesce{Windows: !common.IsLinuxOS(cfg.OCIImageOS)}
+	if cfg.AzureSnapshotConsistency == SnapshotConsistencyFsfreeze {
+		if q.Windows {
+			return nil, fmt.Errorf("AZURE_SNAPSHOT_CONSISTENCY=fsfreeze requires a Linux VM; quiesce %s VMs with AZURE_PRE_SNAPSHOT_SCRIPT and AZURE_POST_SNAPSHOT_SCRIPT", cfg.OCIImageOS)
+		}
+		if cfg.AzureFreezeSeconds <= 0 {
+			return nil, errors.New("AZURE_FREEZE_SECONDS must be positive")
+		}
+		q.Freeze = time.Duration(cfg.AzureFreezeSeconds) * time.Second
+	}
+	for _, script := range []struct {
+		key, path string
+		content   *string
+	}{
+		{"AZURE_PRE_SNAPSHOT_SCRIPT", cfg.AzurePreSnapshotScript, &q.PreScript},
+		{"AZURE_POST_SNAPSHOT_SCRIPT", cfg.AzurePostSnapshotScript, &q.PostScript},
+	} {
+		if script.path == "" {
+			continue
+		}
+		data, err := os.ReadFile(script.path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", script.key, err)
+		}
+		*script.content = string(data)
+	}
+	return q, nil
+}
+
+// snapshotGroup takes the consistent snapshots of the disks of a running VM once,
+// before the first disk is exported, whichever export step runs first.
+type snapshotGroup struct {
+	once sync.Once
+	err  error
+}
+
+// create snapshots the disks of the VM together when quiescing is configured and the
+// VM is running. The OS disk is left out when it is not exported.
+func (g *snapshotGroup) create(ctx context.Context, log *logger.Logger, provider *azure.Provider, cfg *config.Config) error {
+	g.once.Do(func() {
+		if !quiesceConfigured(cfg) {
+			return
+		}
+		stopped, err := provider.CheckComputeIsStopped(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+		if err != nil {
+			g.err = fmt.Errorf("failed to check Compute instance state: %w", err)
+			return
+		}
+		if stopped {
+			log.Info("Compute instance is stopped, its disks are snapshotted without quiescing")
+			return
+		}
+		var diskNames []string
+		if !cfg.SkipExport {
+			osDiskName, err := provider.GetComputeOSDiskName(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+			if err != nil {
+				g.err = fmt.Errorf("failed to get OS disk name: %w", err)
+				return
+			}
+			diskNames = append(diskNames, osDiskName)
+		}
+		dataDiskNames, err := provider.GetComputeDataDiskNames(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+		if err != nil {
+			g.err = fmt.Errorf("failed to get data disk names: %w", err)
+			return
+		}
+		if diskNames = append(diskNames, dataDiskNames...); len(diskNames) == 0 {
+			return
+		}
+		log.Infof("Taking application-consistent snapshots of %d disk(s) of the running VM...", len(diskNames))
+		g.err = provider.CreateConsistentSnapshots(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName, diskNames)
+	})
+	return g.err
+}
@@ -0,0 +1,44 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func TestSnapshotQuiesce(t *testing.T) {
+	dir := t.TempDir()
+	pre := filepath.Join(dir, "pre.sh")
+	if err := os.WriteFile(pre, []byte("mysql -e 'FLUSH TABLES'\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := snapshotQuiesce(&config.Config{AzureSnapshotConsistency: "crash", OCIImageOS: "Ubuntu"})
+	if q != nil || err != nil {
+		t.Errorf("crash consistency: snapshotQuiesce() = %+v, %v, want nil", q, err)
+	}
+
+	q, err = snapshotQuiesce(&config.Config{AzureSnapshotConsistency: SnapshotConsistencyFsfreeze, AzureFreezeSeconds: 90, AzurePreSnapshotScript: pre, OCIImageOS: "Ubuntu"})
+	if err != nil {
+		t.Fatalf("snapshotQuiesce() error = %v", err)
+	}
+	if q.Windows || q.Freeze != 90*time.Second || q.PreScript != "mysql -e 'FLUSH TABLES'\n" || q.PostScript != "" {
+		t.Errorf("snapshotQuiesce() = %+v", q)
+	}
+
+	q, err = snapshotQuiesce(&config.Config{AzureSnapshotConsistency: "crash", AzurePreSnapshotScript: pre, OCIImageOS: "Windows"})
+	if err != nil || !q.Windows || q.Freeze != 0 {
+		t.Errorf("Windows pre-snapshot script: snapshotQuiesce() = %+v, %v", q, err)
+	}
+
+	if _, err := snapshotQuiesce(&config.Config{AzureSnapshotConsistency: SnapshotConsistencyFsfreeze, AzureFreezeSeconds: 90, OCIImageOS: "Windows"}); err == nil || !strings.Contains(err.Error(), "requires a Linux VM") {
+		t.Errorf("Expected fsfreeze on Windows to fail, got %v", err)
+	}
+	if _, err := snapshotQuiesce(&config.Config{AzureSnapshotConsistency: "crash", AzurePostSnapshotScript: filepath.Join(dir, "missing.sh"), OCIImageOS: "Ubuntu"}); err == nil || !strings.Contains(err.Error(), "AZURE_POST_SNAPSHOT_SCRIPT") {
+		t.Errorf("Expected missing post-snapshot script to fail, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+// Package workflow provides the report of the Azure dependencies of the source VM that do not carry over to OCI.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// Kinds of the Azure dependencies of a source VM.
+const (
+	DependencyExtension         = "extension"
+	DependencyManagedIdentity   = "managedIdentity"
+	DependencyKeyVault          = "keyVault"
+	DependencyDiskEncryptionSet = "diskEncryptionSet"
+)
+
+// SourceDependency is an Azure dependency of the source VM that does not carry over to
+// OCI, with what to re-provision in its place.
+type SourceDependency struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+}
+
+// extensionActions are the actions for the VM extensions whose role is known, by
+// lowercase <publisher>/<type>.
+var extensionActions = map[string]string{
+	"microsoft.azure.activedirectory/aadsshloginforlinux":               "Microsoft Entra ID sign-in is not available in OCI; create local accounts or SSH keys for the users before the cutover",
+	"microsoft.azure.activedirectory/aadloginforwindows":                "Microsoft Entra ID sign-in is not available in OCI; create local or domain accounts for the users before the cutover",
+	"microsoft.azure.keyvault/keyvaultforlinux":                         "Certificates are no longer renewed from Key Vault; install and renew them from OCI Vault or another source",
+	"microsoft.azure.keyvault/keyvaultforwindows":                       "Certificates are no longer renewed from Key Vault; install and renew them from OCI Vault or another source",
+	"microsoft.azure.monitor/azuremonitorlinuxagent":                    "Logs and metrics no longer reach Azure Monitor; enable the monitoring and custom-logs plugins with OCI_AGENT_PLUGINS",
+	"microsoft.azure.monitor/azuremonitorwindowsagent":                  "Logs and metrics no longer reach Azure Monitor; enable the monitoring and custom-logs plugins with OCI_AGENT_PLUGINS",
+	"microsoft.enterprisecloud.monitoring/omsagentforlinux":             "Logs and metrics no longer reach Log Analytics; enable the monitoring and custom-logs plugins with OCI_AGENT_PLUGINS",
+	"microsoft.enterprisecloud.monitoring/microsoftmonitoringagent":     "Logs and metrics no longer reach Log Analytics; enable the monitoring and custom-logs plugins with OCI_AGENT_PLUGINS",
+	"microsoft.azure.monitoring.dependencyagent/dependencyagentlinux":   "Service Map data is no longer collected; remove the Dependency agent from the instance",
+	"microsoft.azure.monitoring.dependencyagent/dependencyagentwindows": "Service Map data is no longer collected; remove the Dependency agent from the instance",
+	"microsoft.azure.diagnostics/linuxdiagnostic":                       "Diagnostics no longer reach Azure Storage; enable the monitoring plugin with OCI_AGENT_PLUGINS",
+	"microsoft.azure.diagnostics/iaasdiagnostics":                       "Diagnostics no longer reach Azure Storage; enable the monitoring plugin with OCI_AGENT_PLUGINS",
+	"microsoft.azure.security/azurediskencryptionforlinux":              "The disks are encrypted with keys kept in Key Vault; set LUKS_PASSPHRASE or LUKS_KEY_FILE, and manage the keys outside Azure",
+	"microsoft.azure.security/azurediskencryption":                      "The disks are encrypted with BitLocker keys kept in Key Vault; keep the recovery keys outside Azure before the migration",
+	"microsoft.azure.extensions/customscript":                           "The script is not run in OCI; run it with CLOUD_INIT_USER_DATA or the run-command plugin if the instance needs it",
+	"microsoft.compute/customscriptextension":                           "The script is not run in OCI; run it with CLOUD_INIT_USER_DATA or the run-command plugin if the instance needs it",
+	"microsoft.ostcextensions/vmaccessforlinux":                         "Password and SSH key resets from Azure are not available; use the instance console connection of OCI",
+	"microsoft.compute/vmaccessagent":                                   "Password resets from Azure are not available; use the instance console connection of OCI",
+	"microsoft.azure.azuredefenderforservers/mde.linux":                 "The VM is onboarded to Defender for Endpoint through Azure; onboard the instance directly, or use Cloud Guard and the vulnerability-scanning plugin",
+	"microsoft.azure.azuredefenderforservers/mde.windows":               "The VM is onboarded to Defender for Endpoint through Azure; onboard the instance directly, or use Cloud Guard and the vulnerability-scanning plugin",
+	"microsoft.guestconfiguration/configurationforlinux":                "Azure Policy guest configuration no longer applies; audit the instance with OS Management Hub or another tool",
+	"microsoft.guestconfiguration/configurationforwindows":              "Azure Policy guest configuration no longer applies; audit the instance with OS Management Hub or another tool",
+	"microsoft.azure.automation.hybridworker/hybridworkerforlinux":      "The instance is no longer an Azure Automation Hybrid Runbook Worker; move its runbooks to another worker",
+	"microsoft.azure.automation.hybridworker/hybridworkerforwindows":    "The instance is no longer an Azure Automation Hybrid Runbook Worker; move its runbooks to another worker",
+}
+
+// defaultExtensionAction is the action for the VM extensions whose role is not known.
+const defaultExtensionAction = "Azure VM extensions are not installed in OCI; re-provision what the extension manages"
+
+// sourceDependencies returns the Azure dependencies of the source VM that do not carry
+// over to OCI.
+func sourceDependencies(deps *azure.ComputeDependencies) []SourceDependency {
+	var result []SourceDependency
+	for _, e := range deps.Extensions {
+		action, ok := extensionActions[strings.ToLower(e.Publisher+"/"+e.Type)]
+		if !ok {
+			action = defaultExtensionAction
+		}
+		name := e.Name
+		if e.Publisher != "" {
+			name = fmt.Sprintf("%s (%s.%s)", e.Name, e.Publisher, e.Type)
+		}
+		result = append(result, SourceDependency{Kind: DependencyExtension, Name: name, Action: action})
+	}
+	if deps.SystemAssignedIdentity {
+		result = append(result, SourceDependency{Kind: DependencyManagedIdentity, Name: "system-assigned",
+			Action: "Applications that get Azure tokens from the instance metadata service lose access; give the instance access to OCI with a dynamic group and instance principals, and to Azure with other credentials"})
+	}
+	for _, id := range deps.UserAssignedIdentities {
+		result = append(result, SourceDependency{Kind: DependencyManagedIdentity, Name: resourceIDName(id),
+			Action: "Applications that get Azure tokens for this identity lose access; give the instance access to OCI with a dynamic group and instance principals, and to Azure with other credentials"})
+	}
+	for _, id := range deps.KeyVaultCertificates {
+		result = append(result, SourceDependency{Kind: DependencyKeyVault, Name: resourceIDName(id),
+			Action: "Certificates installed from this key vault are no longer renewed; install and renew them from OCI Vault or another source"})
+	}
+	for _, id := range deps.DiskEncryptionSets {
+		result = append(result, SourceDependency{Kind: DependencyDiskEncryptionSet, Name: resourceIDName(id),
+			Action: "The disks are encrypted with customer-managed keys; the OCI volumes are encrypted with Oracle-managed keys unless a key of OCI Vault is assigned to them"})
+	}
+	return result
+}
+
+// resourceIDName returns the name of the resource of an Azure resource ID.
+func resourceIDName(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
+
+// reportSourceDependencies logs the Azure dependencies of the source VM that do not
+// carry over to OCI during the prerequisite checks, and returns them for the run
+// summary. It does not fail the checks.
+func reportSourceDependencies(ctx context.Context, log *logger.Logger, provider *azure.Provider, cfg *config.Config) []SourceDependency {
+	deps, err := provider.GetComputeDependencies(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+	if err != nil {
+		log.Warning(i18n.T("summary.dependencies_unreadable", err))
+		return nil
+	}
+	report := sourceDependencies(deps)
+	if len(report) == 0 {
+		log.Success(i18n.T("summary.no_dependencies"))
+		return nil
+	}
+	log.Warning(i18n.T("summary.dependencies", len(report)))
+	for _, d := range report {
+		log.Warningf("  %s %s: %s", d.Kind, d.Name, d.Action)
+	}
+	return report
+}
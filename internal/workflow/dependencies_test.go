@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+)
+
+func TestSourceDependencies(t *testing.T) {
+	deps := &azure.ComputeDependencies{
+		Extensions: []azure.VMExtension{
+			{Name: "AADSSHLogin", Publisher: "Microsoft.Azure.ActiveDirectory", Type: "AADSSHLoginForLinux"},
+			{Name: "custom", Publisher: "Contoso.Agents", Type: "Inventory"},
+		},
+		SystemAssignedIdentity: true,
+		UserAssignedIdentities: []string{"/subscriptions/s/resourceGroups/rg/providers/Microsoft.ManagedIdentity/userAssignedIdentities/web-id"},
+		KeyVaultCertificates:   []string{"/subscriptions/s/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/web-kv"},
+		DiskEncryptionSets:     []string{"/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/diskEncryptionSets/cmk"},
+	}
+	report := sourceDependencies(deps)
+	if len(report) != 6 {
+		t.Fatalf("Expected 6 dependencies, got %+v", report)
+	}
+	tests := []struct {
+		kind, name, action string
+	}{
+		{DependencyExtension, "AADSSHLogin (Microsoft.Azure.ActiveDirectory.AADSSHLoginForLinux)", "Entra ID"},
+		{DependencyExtension, "custom (Contoso.Agents.Inventory)", defaultExtensionAction},
+		{DependencyManagedIdentity, "system-assigned", "instance principals"},
+		{DependencyManagedIdentity, "web-id", "instance principals"},
+		{DependencyKeyVault, "web-kv", "no longer renewed"},
+		{DependencyDiskEncryptionSet, "cmk", "customer-managed keys"},
+	}
+	for i, tt := range tests {
+		d := report[i]
+		if d.Kind != tt.kind || d.Name != tt.name || !strings.Contains(d.Action, tt.action) {
+			t.Errorf("dependency %d = %+v, want %s %s with %q", i, d, tt.kind, tt.name, tt.action)
+		}
+	}
+
+	if report := sourceDependencies(&azure.ComputeDependencies{}); len(report) != 0 {
+		t.Errorf("Expected no dependencies, got %+v", report)
+	}
+}
@@ -0,0 +1,148 @@
+// Package workflow provides the discovery of the Azure VMs to migrate by their tags.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// DiscoverOptions selects the VMs listed by Discover.
+type DiscoverOptions struct {
+	Tags     []string // Tag filters, <key>=<value> or <key> for any value; a VM must match all
+	Manifest string   // Batch manifest written with the selected VMs, if set
+}
+
+// tagFilter is a tag a VM must have, with the value value unless anyValue is set.
+type tagFilter struct {
+	key      string
+	value    string
+	anyValue bool
+}
+
+// parseTagFilters parses the <key>=<value> and <key> tag filters of kopru discover.
+func parseTagFilters(filters []string) ([]tagFilter, error) {
+	var parsed []tagFilter
+	for _, f := range filters {
+		key, value, hasValue := strings.Cut(f, "=")
+		if key = strings.TrimSpace(key); key == "" {
+			return nil, fmt.Errorf("invalid tag filter '%s', expected <key>=<value> or <key>", f)
+		}
+		parsed = append(parsed, tagFilter{key: key, value: value, anyValue: !hasValue})
+	}
+	return parsed, nil
+}
+
+// matchesTags reports whether tags match all the filters. Tag keys are matched without
+// regard to case, as Azure does; values are matched exactly.
+func matchesTags(tags map[string]string, filters []tagFilter) bool {
+	for _, f := range filters {
+		matched := false
+		for key, value := range tags {
+			if strings.EqualFold(key, f.key) && (f.anyValue || value == f.value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// Discover lists the VMs of AZURE_RESOURCE_GROUP, or of the subscription when it is not
+// set, that match the tag filters of opts, with their size, OS and disks. With
+// opts.Manifest, the resource IDs of the VMs are written to a batch manifest that
+// BATCH_MANIFEST_FILE migrates.
+func Discover(ctx context.Context, cfg *config.Config, log *logger.Logger, w io.Writer, opts DiscoverOptions) error {
+	if cfg.AzureSubscriptionID == "" {
+		return errors.New("AZURE_SUBSCRIPTION_ID is required")
+	}
+	filters, err := parseTagFilters(opts.Tags)
+	if err != nil {
+		return err
+	}
+	provider, err := azure.NewProvider(cfg.AzureSubscriptionID, AzureAuth(cfg), log)
+	if err != nil {
+		return fmt.Errorf("failed to create Azure provider: %w", err)
+	}
+	vms, err := provider.ListComputeSummaries(ctx, cfg.AzureResourceGroup)
+	if err != nil {
+		return err
+	}
+	var selected []azure.ComputeSummary
+	for _, vm := range vms {
+		if matchesTags(vm.Tags, filters) {
+			selected = append(selected, vm)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		if !strings.EqualFold(selected[i].ResourceGroup, selected[j].ResourceGroup) {
+			return strings.ToLower(selected[i].ResourceGroup) < strings.ToLower(selected[j].ResourceGroup)
+		}
+		return strings.ToLower(selected[i].Name) < strings.ToLower(selected[j].Name)
+	})
+	if err := renderDiscovered(w, selected); err != nil {
+		return err
+	}
+	scope := "subscription " + cfg.AzureSubscriptionID
+	if cfg.AzureResourceGroup != "" {
+		scope = "resource group " + cfg.AzureResourceGroup
+	}
+	log.Infof("%d of %d VM(s) in %s match the tag filters", len(selected), len(vms), scope)
+	if opts.Manifest == "" {
+		return nil
+	}
+	if len(selected) == 0 {
+		return errors.New("no VM matches the tag filters, the batch manifest is not written")
+	}
+	if err := writeBatchManifest(opts.Manifest, selected, opts.Tags, time.Now()); err != nil {
+		return err
+	}
+	log.Successf("✓ Batch manifest of %d VM(s) written to %s; set BATCH_MANIFEST_FILE=%s to migrate them", len(selected), opts.Manifest, opts.Manifest)
+	return nil
+}
+
+// renderDiscovered writes the VMs as a table.
+func renderDiscovered(w io.Writer, vms []azure.ComputeSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tRESOURCE GROUP\tLOCATION\tSIZE\tOS\tOS DISK\tDATA DISKS\tIMAGE")
+	for _, vm := range vms {
+		dataDisks := "-"
+		if vm.DataDisks > 0 {
+			dataDisks = fmt.Sprintf("%d (%d GB)", vm.DataDisks, vm.DataDisksGB)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d GB\t%s\t%s\n", vm.Name, vm.ResourceGroup, vm.Location, valueOrDash(vm.Size),
+			valueOrDash(vm.OSType), vm.OSDiskGB, dataDisks, valueOrDash(vm.Image))
+	}
+	return tw.Flush()
+}
+
+// writeBatchManifest writes the resource IDs of the VMs to the batch manifest path, one
+// per line, after comments recording how they were selected.
+func writeBatchManifest(path string, vms []azure.ComputeSummary, tags []string, now time.Time) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Kopru batch manifest written by kopru discover on %s\n", now.UTC().Format(time.RFC3339))
+	if len(tags) > 0 {
+		fmt.Fprintf(&b, "# Tag filters: %s\n", strings.Join(tags, ", "))
+	}
+	fmt.Fprintf(&b, "# Migrate these VMs with BATCH_MANIFEST_FILE=%s; remove the lines of VMs to leave out.\n", path)
+	for _, vm := range vms {
+		fmt.Fprintf(&b, "%s\n", vm.ID)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("failed to write batch manifest: %w", err)
+	}
+	return nil
+}
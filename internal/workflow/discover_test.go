@@ -0,0 +1,92 @@
+package workflow
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func TestMatchesTags(t *testing.T) {
+	filters, err := parseTagFilters([]string{"migrate=true", "owner"})
+	if err != nil {
+		t.Fatalf("parseTagFilters() error = %v", err)
+	}
+	tests := []struct {
+		tags map[string]string
+		want bool
+	}{
+		{map[string]string{"Migrate": "true", "Owner": "web-team"}, true},
+		{map[string]string{"migrate": "true", "owner": ""}, true},
+		{map[string]string{"migrate": "True", "owner": "web-team"}, false},
+		{map[string]string{"migrate": "true"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := matchesTags(tt.tags, filters); got != tt.want {
+			t.Errorf("matchesTags(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+	if !matchesTags(nil, nil) {
+		t.Error("Expected a VM to match no tag filters")
+	}
+	if _, err := parseTagFilters([]string{"=true"}); err == nil {
+		t.Error("Expected an error for a tag filter without a key")
+	}
+}
+
+func TestRenderDiscovered(t *testing.T) {
+	var buf bytes.Buffer
+	vms := []azure.ComputeSummary{
+		{Name: "web-01", ResourceGroup: "web-rg", Location: "westeurope", Size: "Standard_D4s_v3", OSType: "Linux", OSDiskGB: 30, DataDisks: 2, DataDisksGB: 384},
+		{Name: "db-01", ResourceGroup: "db-rg", Location: "westeurope", OSDiskGB: 127},
+	}
+	if err := renderDiscovered(&buf, vms); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"RESOURCE GROUP", "Standard_D4s_v3", "2 (384 GB)", "127 GB"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected the table to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteBatchManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vms.txt")
+	ids := []string{
+		"/subscriptions/s/resourceGroups/web-rg/providers/Microsoft.Compute/virtualMachines/web-01",
+		"/subscriptions/s/resourceGroups/web-rg/providers/Microsoft.Compute/virtualMachines/web-02",
+	}
+	vms := []azure.ComputeSummary{{Name: "web-01", ID: ids[0]}, {Name: "web-02", ID: ids[1]}}
+	if err := writeBatchManifest(path, vms, []string{"migrate=true"}, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.Contains(string(content), "# Tag filters: migrate=true") {
+		t.Errorf("Expected the tag filters in the manifest, got:\n%s", content)
+	}
+
+	// The manifest is read back by batch migrations.
+	cfg := &config.Config{SourcePlatform: "azure", BatchManifestFile: path, AzureComputeName: "*"}
+	got, err := ExpandComputeNames(t.Context(), cfg, nil)
+	if err != nil {
+		t.Fatalf("ExpandComputeNames() error = %v", err)
+	}
+	if strings.Join(got, ",") != strings.Join(ids, ",") {
+		t.Errorf("ExpandComputeNames() = %v, want %v", got, ids)
+	}
+	cfg.AzureComputeName = "*-02"
+	if got, _ := ExpandComputeNames(t.Context(), cfg, nil); len(got) != 1 || got[0] != ids[1] {
+		t.Errorf("ExpandComputeNames(*-02) = %v", got)
+	}
+	cfg.AzureComputeName = "db-*"
+	if _, err := ExpandComputeNames(t.Context(), cfg, nil); err == nil {
+		t.Error("Expected an error when no VM of the manifest matches")
+	}
+}
@@ -0,0 +1,27 @@
+// Package workflow provides the selection of the engine that configures images for OCI.
+package workflow
+
+import (
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+var lookupVirtV2V = func() error { return common.CheckCommand("virt-v2v") }
+
+// resolveConfigureEngine returns the configured CONFIGURE_ENGINE, falling back to the
+// built-in scripts when virt-v2v is requested but not installed.
+func resolveConfigureEngine(log *logger.Logger, cfg *config.Config) string {
+	if cfg.ConfigureEngine != common.ConfigureEngineVirtV2V {
+		return common.ConfigureEngineBuiltin
+	}
+	if err := lookupVirtV2V(); err != nil {
+		log.Warningf("%v, falling back to the built-in OS configuration", err)
+		return common.ConfigureEngineBuiltin
+	}
+	log.Success("✓ virt-v2v is installed")
+	if cfg.BreakglassUser != "" || cfg.OCISSHPublicKey != "" || cfg.SSHKeyFilePath != "" {
+		log.Info("The SSH key and break-glass user are only injected into the image by the built-in engine; use a configurator with virt-v2v")
+	}
+	return common.ConfigureEngineVirtV2V
+}
@@ -0,0 +1,40 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestResolveConfigureEngine(t *testing.T) {
+	orig := lookupVirtV2V
+	t.Cleanup(func() { lookupVirtV2V = orig })
+
+	tests := []struct {
+		name      string
+		engine    string
+		installed bool
+		want      string
+	}{
+		{"default", "", true, common.ConfigureEngineBuiltin},
+		{"builtin", common.ConfigureEngineBuiltin, true, common.ConfigureEngineBuiltin},
+		{"virt-v2v", common.ConfigureEngineVirtV2V, true, common.ConfigureEngineVirtV2V},
+		{"virt-v2v missing", common.ConfigureEngineVirtV2V, false, common.ConfigureEngineBuiltin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookupVirtV2V = func() error {
+				if tt.installed {
+					return nil
+				}
+				return errors.New("command 'virt-v2v' not found in PATH")
+			}
+			if got := resolveConfigureEngine(logger.New(false), &config.Config{ConfigureEngine: tt.engine}); got != tt.want {
+				t.Errorf("resolveConfigureEngine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Package workflow provides the finishing script run on deployed instances by workflow handlers.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// checkFinishingScript verifies that the configured finishing script can be read.
+func checkFinishingScript(cfg *config.Config) error {
+	if cfg.FinishingScript == "" {
+		return nil
+	}
+	info, err := os.Stat(cfg.FinishingScript)
+	if err != nil {
+		return fmt.Errorf("FINISHING_SCRIPT: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("FINISHING_SCRIPT: %s is not a regular file", cfg.FinishingScript)
+	}
+	return nil
+}
+
+// runFinishingScript runs the finishing script on the deployed instance through the
+// OCI Run Command plugin, for changes that cannot be made while the image is offline,
+// such as completing an SELinux relabel or building drivers against the running
+// kernel. The result is returned even when the script fails, so that it can be
+// recorded in the run summary.
+func runFinishingScript(ctx context.Context, log *logger.Logger, provider *oci.Provider, cfg *config.Config, instanceID string) (*FinishingResult, error) {
+	script, err := os.ReadFile(cfg.FinishingScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read finishing script: %w", err)
+	}
+	timeout := time.Duration(cfg.FinishingTimeoutMinutes) * time.Minute
+	log.Infof("Running %s on instance %s (timeout %s)", cfg.FinishingScript, instanceID, timeout)
+	log.Info("The script runs once the instance has booted and its Oracle Cloud Agent has picked it up")
+	displayName := fmt.Sprintf("kopru-finishing-%s", filepath.Base(cfg.FinishingScript))
+	cmd, err := provider.RunCommand(ctx, cfg.OCICompartmentID, instanceID, displayName, string(script), timeout)
+	result := &FinishingResult{Script: cfg.FinishingScript}
+	if cmd != nil {
+		result.CommandID = cmd.CommandID
+		result.State = cmd.State
+		result.ExitCode = cmd.ExitCode
+		result.Output = cmd.Output
+		result.Message = cmd.Message
+	}
+	if err != nil {
+		log.Warning("Check that the instance runs the Oracle Cloud Agent with the Compute Instance Run Command plugin and that a dynamic group policy allows it to use instance-agent-command-execution-family")
+		return result, err
+	}
+	for _, line := range strings.Split(strings.TrimRight(result.Output, "\n"), "\n") {
+		if line != "" {
+			log.Infof("  | %s", line)
+		}
+	}
+	if result.State != "SUCCEEDED" || result.ExitCode != 0 {
+		return result, fmt.Errorf("finishing script ended in state %s with exit code %d: %s", result.State, result.ExitCode, result.Message)
+	}
+	log.Successf("✓ Finishing script completed on instance %s", instanceID)
+	return result, nil
+}
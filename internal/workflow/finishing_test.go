@@ -0,0 +1,40 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func TestCheckFinishingScript(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "finish.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/bash\nrestorecon -R /\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{"not set", "", ""},
+		{"file", script, ""},
+		{"missing", filepath.Join(dir, "missing.sh"), "no such file"},
+		{"directory", dir, "not a regular file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFinishingScript(&config.Config{FinishingScript: tt.script})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkFinishingScript() error = %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkFinishingScript() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package workflow provides the detection of the boot firmware of Azure VMs, which selects the firmware of the OCI image.
+package workflow
+
+import (
+	"context"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// Launch modes of the imported image that matter to the firmware.
+const (
+	launchModeEmulated        = "EMULATED"
+	launchModeParavirtualized = "PARAVIRTUALIZED"
+)
+
+// checkSourceFirmware reads the boot firmware of the source VM and applies it to the
+// image settings. It does not fail the checks.
+func checkSourceFirmware(ctx context.Context, log *logger.Logger, provider *azure.Provider, cfg *config.Config) {
+	firmware, err := provider.GetComputeFirmware(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+	if err != nil {
+		log.Warningf("Could not read the firmware of the Azure VM, OCI_IMAGE_ENABLE_UEFI=%t is used: %v", cfg.OCIImageEnableUEFI, err)
+		return
+	}
+	applySourceFirmware(log, cfg, firmware)
+}
+
+// applySourceFirmware enables UEFI firmware, with the image capability schema of the
+// template, for the image of a VM that boots with UEFI, in a launch mode that supports
+// it. It warns about the Trusted Launch features that are not carried over.
+func applySourceFirmware(log *logger.Logger, cfg *config.Config, firmware *azure.ComputeFirmware) {
+	if !firmware.UEFI() {
+		if cfg.OCIImageEnableUEFI {
+			log.Warning("The Azure VM is Generation 1 and boots with BIOS, but OCI_IMAGE_ENABLE_UEFI is true; the instance may not boot")
+		} else {
+			log.Success("✓ The Azure VM is Generation 1, the image boots with BIOS")
+		}
+		return
+	}
+	if !cfg.OCIImageEnableUEFI {
+		cfg.OCIImageEnableUEFI = true
+		log.Success("✓ The Azure VM is Generation 2, UEFI firmware is enabled for the image")
+	} else {
+		log.Success("✓ The Azure VM is Generation 2, the image boots with UEFI")
+	}
+	if cfg.OCIImageLaunchMode == launchModeEmulated {
+		cfg.OCIImageLaunchMode = launchModeParavirtualized
+		log.Warningf("Launch mode %s does not support UEFI firmware, the image is imported in %s mode", launchModeEmulated, launchModeParavirtualized)
+	}
+	if firmware.SecureBoot {
+		log.Warningf("Secure Boot of the %s VM is not carried over: the instance boots without it, and keys enrolled in the VM's UEFI databases are lost", firmware.SecurityType)
+	}
+	if firmware.VTPM {
+		log.Warning("The vTPM of the VM is not carried over: keys sealed to it, such as BitLocker or LUKS keys, are lost; have the recovery keys at hand")
+	}
+}
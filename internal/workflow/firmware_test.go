@@ -0,0 +1,35 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestApplySourceFirmware(t *testing.T) {
+	tests := []struct {
+		name           string
+		firmware       azure.ComputeFirmware
+		uefi           bool
+		launchMode     string
+		wantUEFI       bool
+		wantLaunchMode string
+	}{
+		{"generation 1", azure.ComputeFirmware{HyperVGeneration: "V1"}, false, "PARAVIRTUALIZED", false, "PARAVIRTUALIZED"},
+		{"generation 1 with UEFI set", azure.ComputeFirmware{HyperVGeneration: "V1"}, true, "NATIVE", true, "NATIVE"},
+		{"generation 2", azure.ComputeFirmware{HyperVGeneration: "V2"}, false, "PARAVIRTUALIZED", true, "PARAVIRTUALIZED"},
+		{"generation 2 emulated", azure.ComputeFirmware{HyperVGeneration: "V2"}, false, "EMULATED", true, "PARAVIRTUALIZED"},
+		{"trusted launch", azure.ComputeFirmware{SecurityType: "TrustedLaunch", SecureBoot: true, VTPM: true}, false, "NATIVE", true, "NATIVE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{OCIImageEnableUEFI: tt.uefi, OCIImageLaunchMode: tt.launchMode}
+			applySourceFirmware(logger.New(false), cfg, &tt.firmware)
+			if cfg.OCIImageEnableUEFI != tt.wantUEFI || cfg.OCIImageLaunchMode != tt.wantLaunchMode {
+				t.Errorf("UEFI = %t, launch mode = %s, want %t, %s", cfg.OCIImageEnableUEFI, cfg.OCIImageLaunchMode, tt.wantUEFI, tt.wantLaunchMode)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Package workflow provides garbage collection of custom images and snapshots left by earlier runs.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// staleImportAge is the age after which an image that is not AVAILABLE is treated as a
+// failed import. Workflows wait at most 5 hours for an import to complete.
+const staleImportAge = 6 * time.Hour
+
+// ImageGCOptions selects the images removed by GarbageCollectImages.
+type ImageGCOptions struct {
+	Retention time.Duration // Available images older than this are deleted (0 keeps them)
+	Delete    bool          // Delete the selected images instead of only listing them
+}
+
+// gcImage is a custom image created by Kopru and the reason it is deleted, if any.
+type gcImage struct {
+	ID     string
+	Name   string
+	State  string
+	Age    time.Duration
+	Reason string
+}
+
+// classifyImages selects the images that never became available or are older than the
+// retention period.
+func classifyImages(images []core.Image, now time.Time, retention time.Duration) []gcImage {
+	result := make([]gcImage, 0, len(images))
+	for _, image := range images {
+		if image.LifecycleState == core.ImageLifecycleStateDeleted {
+			continue
+		}
+		g := gcImage{ID: *image.Id, State: string(image.LifecycleState)}
+		if image.DisplayName != nil {
+			g.Name = *image.DisplayName
+		}
+		if image.TimeCreated != nil {
+			g.Age = now.Sub(image.TimeCreated.Time)
+		}
+		switch {
+		case image.LifecycleState != core.ImageLifecycleStateAvailable && g.Age > staleImportAge:
+			g.Reason = "not available"
+		case image.LifecycleState == core.ImageLifecycleStateAvailable && retention > 0 && g.Age > retention:
+			g.Reason = "retention expired"
+		}
+		result = append(result, g)
+	}
+	return result
+}
+
+// GarbageCollectImages lists the custom images created by Kopru in OCI_COMPARTMENT_ID
+// with their state and age, and deletes those that never became available or are older
+// than the retention period when opts.Delete is set.
+func GarbageCollectImages(ctx context.Context, cfg *config.Config, log *logger.Logger, w io.Writer, opts ImageGCOptions) error {
+	if cfg.OCICompartmentID == "" || cfg.OCIRegion == "" {
+		return errors.New("OCI_COMPARTMENT_ID and OCI_REGION are required")
+	}
+	provider, err := oci.NewProvider(cfg.OCIRegion, log)
+	if err != nil {
+		return fmt.Errorf("failed to create OCI provider: %w", err)
+	}
+	if err := checkOCISession(ctx, log, provider); err != nil {
+		return err
+	}
+	images, err := provider.ListImagesByTag(ctx, cfg.OCICompartmentID, createdByTagKey, createdByTagValue)
+	if err != nil {
+		return err
+	}
+	candidates := classifyImages(images, time.Now(), opts.Retention)
+	var selected []gcImage
+	for _, g := range candidates {
+		if g.Reason != "" {
+			selected = append(selected, g)
+		}
+	}
+	if err := renderGCImages(w, candidates); err != nil {
+		return err
+	}
+	if len(selected) == 0 || !opts.Delete {
+		log.Infof("%d of %d images selected for deletion", len(selected), len(candidates))
+		return nil
+	}
+
+	summary := make([]string, 0, len(selected))
+	for _, g := range selected {
+		summary = append(summary, fmt.Sprintf("%s (%s, %s)", g.Name, g.State, g.Reason))
+	}
+	if err := confirmOperation(cfg, log, i18n.T("guardrail.gc_images_title", len(selected), cfg.OCICompartmentID), summary, "delete"); err != nil {
+		return err
+	}
+	var errs []error
+	for _, g := range selected {
+		if err := provider.DeleteImage(ctx, g.ID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", g.Name, err))
+			continue
+		}
+		log.Successf("Deleted image %s (%s)", g.Name, g.ID)
+	}
+	return errors.Join(errs...)
+}
+
+// SnapshotGCOptions selects the snapshots removed by GarbageCollectSnapshots.
+type SnapshotGCOptions struct {
+	MinAge time.Duration // Snapshots younger than this may belong to a running migration
+	Delete bool          // Delete the selected snapshots instead of only listing them
+}
+
+// gcSnapshot is an export snapshot created by Kopru and the reason it is deleted, if any.
+type gcSnapshot struct {
+	azure.Snapshot
+	Age    time.Duration
+	Reason string
+}
+
+// classifySnapshots selects the export snapshots older than minAge. Snapshots are
+// recognised by the created-by=kopru tag, or by the ss- name prefix of untagged
+// snapshots created by earlier versions.
+func classifySnapshots(snapshots []azure.Snapshot, now time.Time, minAge time.Duration) []gcSnapshot {
+	var result []gcSnapshot
+	for _, s := range snapshots {
+		tagged := s.Tags[azure.SnapshotCreatedByTag] == azure.SnapshotCreatedByValue
+		if !tagged && (len(s.Tags) > 0 || !strings.HasPrefix(s.Name, "ss-")) {
+			continue
+		}
+		g := gcSnapshot{Snapshot: s}
+		if !s.TimeCreated.IsZero() {
+			g.Age = now.Sub(s.TimeCreated)
+		}
+		if g.Age > minAge {
+			g.Reason = "orphaned"
+		}
+		result = append(result, g)
+	}
+	return result
+}
+
+// GarbageCollectSnapshots lists the export snapshots in AZURE_RESOURCE_GROUP with their
+// migration ID and age, and deletes those older than opts.MinAge when opts.Delete is
+// set. Such snapshots are left behind when a run crashes before its cleanup.
+func GarbageCollectSnapshots(ctx context.Context, cfg *config.Config, log *logger.Logger, w io.Writer, opts SnapshotGCOptions) error {
+	if cfg.AzureResourceGroup == "" {
+		return errors.New("AZURE_RESOURCE_GROUP is required")
+	}
+	provider, err := azure.NewProvider(cfg.AzureSubscriptionID, AzureAuth(cfg), log)
+	if err != nil {
+		return fmt.Errorf("failed to create Azure provider: %w", err)
+	}
+	snapshots, err := provider.ListSnapshots(ctx, cfg.AzureResourceGroup)
+	if err != nil {
+		return err
+	}
+	candidates := classifySnapshots(snapshots, time.Now(), opts.MinAge)
+	var selected []gcSnapshot
+	for _, g := range candidates {
+		if g.Reason != "" {
+			selected = append(selected, g)
+		}
+	}
+	if err := renderGCSnapshots(w, candidates); err != nil {
+		return err
+	}
+	if len(selected) == 0 || !opts.Delete {
+		log.Infof("%d of %d snapshots selected for deletion", len(selected), len(candidates))
+		return nil
+	}
+
+	summary := make([]string, 0, len(selected))
+	for _, g := range selected {
+		summary = append(summary, fmt.Sprintf("%s (%s, %s)", g.Name, formatAge(g.Age), g.Reason))
+	}
+	if err := confirmOperation(cfg, log, i18n.T("guardrail.gc_snapshots_title", len(selected), cfg.AzureResourceGroup), summary, "delete"); err != nil {
+		return err
+	}
+	var errs []error
+	for _, g := range selected {
+		if g.AccessGranted() {
+			if err := provider.RevokeSnapshotAccess(ctx, cfg.AzureResourceGroup, g.Name); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", g.Name, err))
+				continue
+			}
+		}
+		if err := provider.DeleteSnapshot(ctx, cfg.AzureResourceGroup, g.Name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", g.Name, err))
+			continue
+		}
+		log.Successf("Deleted snapshot %s", g.Name)
+	}
+	return errors.Join(errs...)
+}
+
+// renderGCSnapshots writes the snapshots as a table.
+func renderGCSnapshots(w io.Writer, snapshots []gcSnapshot) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tMIGRATION\tCREATOR\tAGE\tACTION")
+	for _, g := range snapshots {
+		action := "keep"
+		if g.Reason != "" {
+			action = "delete (" + g.Reason + ")"
+		}
+		migration, creator := g.Tags[azure.SnapshotMigrationIDTag], g.Tags[azure.SnapshotCreatorTag]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", g.Name, valueOrDash(migration), valueOrDash(creator), formatAge(g.Age), action)
+	}
+	return tw.Flush()
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// renderGCImages writes the images as a table.
+func renderGCImages(w io.Writer, images []gcImage) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATE\tAGE\tACTION\tOCID")
+	for _, g := range images {
+		action := "keep"
+		if g.Reason != "" {
+			action = "delete (" + g.Reason + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", g.Name, g.State, formatAge(g.Age), action, g.ID)
+	}
+	return tw.Flush()
+}
+
+// formatAge formats an age in days, or in hours below two days.
+func formatAge(age time.Duration) string {
+	if age >= 48*time.Hour {
+		return fmt.Sprintf("%dd", int(age/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%dh", int(age/time.Hour))
+}
@@ -0,0 +1,92 @@
+package workflow
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+func TestClassifyImages(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	image := func(name string, state core.ImageLifecycleStateEnum, age time.Duration) core.Image {
+		return core.Image{
+			Id:             common.String("ocid1.image.oc1..." + name),
+			DisplayName:    common.String(name),
+			LifecycleState: state,
+			TimeCreated:    &common.SDKTime{Time: now.Add(-age)},
+		}
+	}
+	images := []core.Image{
+		image("importing", core.ImageLifecycleStateImporting, time.Hour),
+		image("stuck", core.ImageLifecycleStateImporting, 7*time.Hour),
+		image("disabled", core.ImageLifecycleStateDisabled, 10*24*time.Hour),
+		image("recent", core.ImageLifecycleStateAvailable, 5*24*time.Hour),
+		image("old", core.ImageLifecycleStateAvailable, 40*24*time.Hour),
+		image("deleted", core.ImageLifecycleStateDeleted, 40*24*time.Hour),
+	}
+
+	tests := []struct {
+		retention time.Duration
+		want      map[string]string
+	}{
+		{0, map[string]string{"importing": "", "stuck": "not available", "disabled": "not available", "recent": "", "old": ""}},
+		{30 * 24 * time.Hour, map[string]string{"importing": "", "stuck": "not available", "disabled": "not available", "recent": "", "old": "retention expired"}},
+	}
+	for _, tt := range tests {
+		got := classifyImages(images, now, tt.retention)
+		if len(got) != len(tt.want) {
+			t.Fatalf("classifyImages() returned %d images, want %d", len(got), len(tt.want))
+		}
+		for _, g := range got {
+			if reason, ok := tt.want[g.Name]; !ok || g.Reason != reason {
+				t.Errorf("retention %s: image %s reason = %q, want %q", tt.retention, g.Name, g.Reason, reason)
+			}
+		}
+	}
+}
+
+func TestRenderGCImages(t *testing.T) {
+	var buf bytes.Buffer
+	images := []gcImage{
+		{ID: "ocid1.image.oc1..a", Name: "vm-imported-image", State: "AVAILABLE", Age: 3 * 24 * time.Hour},
+		{ID: "ocid1.image.oc1..b", Name: "vm-imported-image-v2", State: "IMPORTING", Age: 7 * time.Hour, Reason: "not available"},
+	}
+	if err := renderGCImages(&buf, images); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"NAME", "vm-imported-image  ", "3d", "keep", "7h", "delete (not available)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestClassifySnapshots(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	kopru := map[string]string{azure.SnapshotCreatedByTag: azure.SnapshotCreatedByValue}
+	snapshots := []azure.Snapshot{
+		{Name: "ss-running-abc", TimeCreated: now.Add(-2 * time.Hour), Tags: kopru},
+		{Name: "custom-crashed", TimeCreated: now.Add(-30 * time.Hour), Tags: kopru},
+		{Name: "ss-legacy", TimeCreated: now.Add(-72 * time.Hour), Tags: map[string]string{}},
+		{Name: "ss-owned-by-someone", TimeCreated: now.Add(-72 * time.Hour), Tags: map[string]string{"owner": "dba"}},
+		{Name: "nightly-backup", TimeCreated: now.Add(-72 * time.Hour), Tags: map[string]string{}},
+	}
+	want := map[string]string{"ss-running-abc": "", "custom-crashed": "orphaned", "ss-legacy": "orphaned"}
+
+	got := classifySnapshots(snapshots, now, 24*time.Hour)
+	if len(got) != len(want) {
+		t.Fatalf("classifySnapshots() returned %d snapshots, want %d", len(got), len(want))
+	}
+	for _, g := range got {
+		if reason, ok := want[g.Name]; !ok || g.Reason != reason {
+			t.Errorf("snapshot %s reason = %q, want %q", g.Name, g.Reason, reason)
+		}
+	}
+}
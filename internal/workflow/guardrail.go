@@ -0,0 +1,83 @@
+// Package workflow provides confirmation guardrails for destructive or costly operations.
+package workflow
+
+import (
+	"errors"
+	"os"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/prompt"
+	"github.com/codebypatrickleung/kopru-cli/internal/template"
+)
+
+// Overridable in tests.
+var (
+	isInteractive = prompt.IsInteractive
+	newPrompter   = func() *prompt.Prompter { return prompt.New(os.Stdin, os.Stderr) }
+)
+
+// confirmOperation shows a summary of an operation and requires the user to type
+// token before it proceeds. It passes immediately when --yes was given and fails in
+// non-interactive sessions without --yes.
+func confirmOperation(cfg *config.Config, log *logger.Logger, title string, summary []string, token string) error {
+	if cfg.AssumeYes {
+		log.Info(i18n.T("guardrail.confirmed_yes", title))
+		return nil
+	}
+	if !isInteractive() {
+		return errors.New(i18n.T("guardrail.non_interactive", title))
+	}
+	log.Warning(title)
+	for _, line := range summary {
+		log.Info("  " + line)
+	}
+	if err := newPrompter().Confirm(i18n.T("guardrail.type_to_confirm", token), token); err != nil {
+		if errors.Is(err, prompt.ErrNotConfirmed) {
+			return errors.New(i18n.T("guardrail.declined", title))
+		}
+		return err
+	}
+	return nil
+}
+
+// confirmUpload asks for confirmation before uploading a file larger than the configured threshold.
+func confirmUpload(cfg *config.Config, log *logger.Logger, filePath, namespace, objectName string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	if !uploadNeedsConfirmation(cfg, info.Size()) {
+		return nil
+	}
+	sizeGB := info.Size() / (1024 * 1024 * 1024)
+	return confirmOperation(cfg, log,
+		i18n.T("guardrail.upload_title", objectName, sizeGB, cfg.OCIBucketName),
+		[]string{
+			"Namespace: " + namespace,
+			"Bucket: " + cfg.OCIBucketName,
+			"Compartment: " + cfg.OCICompartmentID,
+			"Region: " + cfg.OCIRegion,
+		},
+		cfg.OCIBucketName)
+}
+
+// uploadNeedsConfirmation reports whether uploading size bytes exceeds the configured threshold.
+func uploadNeedsConfirmation(cfg *config.Config, size int64) bool {
+	return cfg.UploadConfirmThresholdGB > 0 && size/(1024*1024*1024) >= int64(cfg.UploadConfirmThresholdGB)
+}
+
+// confirmApply asks for confirmation before the template is applied to create the instance.
+func confirmApply(cfg *config.Config, log *logger.Logger, planSummary string) error {
+	summary := []string{
+		"Instance: " + cfg.OCIInstanceName,
+		"Compartment: " + cfg.OCICompartmentID,
+		"Subnet: " + cfg.OCISubnetID,
+		"Region: " + cfg.OCIRegion,
+	}
+	if planSummary != "" {
+		summary = append(summary, planSummary)
+	}
+	return confirmOperation(cfg, log, i18n.T("guardrail.apply_title", cfg.OCIInstanceName, template.Engine(cfg.IaCEngine)), summary, cfg.OCIInstanceName)
+}
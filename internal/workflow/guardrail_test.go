@@ -0,0 +1,69 @@
+package workflow
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/prompt"
+)
+
+func withPrompt(t *testing.T, interactive bool, input string) {
+	t.Helper()
+	origInteractive, origPrompter := isInteractive, newPrompter
+	isInteractive = func() bool { return interactive }
+	newPrompter = func() *prompt.Prompter { return prompt.New(strings.NewReader(input), &bytes.Buffer{}) }
+	t.Cleanup(func() { isInteractive, newPrompter = origInteractive, origPrompter })
+}
+
+func TestConfirmOperation(t *testing.T) {
+	tests := []struct {
+		name        string
+		assumeYes   bool
+		interactive bool
+		input       string
+		expectError bool
+	}{
+		{"assume yes", true, false, "", false},
+		{"non-interactive without --yes", false, false, "", true},
+		{"typed token", false, true, "kopru-instance\n", false},
+		{"wrong token", false, true, "yes\n", true},
+		{"no answer", false, true, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPrompt(t, tt.interactive, tt.input)
+			cfg := &config.Config{AssumeYes: tt.assumeYes, OCIInstanceName: "kopru-instance"}
+			err := confirmApply(cfg, logger.New(false), "Plan: 1 to add, 0 to change, 0 to destroy.")
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestConfirmUploadBelowThreshold(t *testing.T) {
+	withPrompt(t, false, "")
+	file := filepath.Join(t.TempDir(), "image.qcow2")
+	if err := os.WriteFile(file, []byte("qcow2"), 0600); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	cfg := &config.Config{OCIBucketName: "kopru-bucket", UploadConfirmThresholdGB: 100}
+	if err := confirmUpload(cfg, logger.New(false), file, "ns", "image.qcow2"); err != nil {
+		t.Errorf("Expected small upload to proceed without confirmation, got: %v", err)
+	}
+
+	cfg.UploadConfirmThresholdGB = 0
+	if err := confirmUpload(cfg, logger.New(false), file, "ns", "image.qcow2"); err != nil {
+		t.Errorf("Expected threshold 0 to disable confirmation, got: %v", err)
+	}
+}
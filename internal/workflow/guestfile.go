@@ -0,0 +1,78 @@
+// Package workflow provides the guest file commands that read and write single files in an image.
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+// GuestPathPrefix marks a path inside the image in the arguments of the copy command.
+const GuestPathPrefix = ":"
+
+// checkGuestImage checks that the image exists and that libguestfs is installed.
+func checkGuestImage(imageFile, tool string) error {
+	if _, err := os.Stat(imageFile); err != nil {
+		return fmt.Errorf("image not found: %w", err)
+	}
+	return common.CheckCommand(tool)
+}
+
+// CatGuestFile writes a file of the image to w, opening LUKS containers with the
+// configured key.
+func CatGuestFile(cfg *config.Config, imageFile, guestPath string, w io.Writer) error {
+	if err := checkGuestImage(imageFile, "virt-cat"); err != nil {
+		return err
+	}
+	key, cleanup, err := luksKeySelector(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return common.CatGuestFile(imageFile, guestPath, key, w)
+}
+
+// CopyGuestFile copies a file between the image and the local filesystem. Exactly one of
+// source and destination is a guest path, prefixed with GuestPathPrefix: a guest source
+// is copied into the local directory destination, and a local source is copied into the
+// guest directory destination.
+func CopyGuestFile(cfg *config.Config, imageFile, source, destination string) error {
+	guestSource := strings.HasPrefix(source, GuestPathPrefix)
+	if guestSource == strings.HasPrefix(destination, GuestPathPrefix) {
+		return fmt.Errorf("exactly one of %q and %q must be a guest path starting with %q", source, destination, GuestPathPrefix)
+	}
+	tool := "virt-copy-in"
+	if guestSource {
+		tool = "virt-copy-out"
+	}
+	if err := checkGuestImage(imageFile, tool); err != nil {
+		return err
+	}
+	key, cleanup, err := luksKeySelector(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if guestSource {
+		return common.CopyFromGuest(imageFile, strings.TrimPrefix(source, GuestPathPrefix), destination, key)
+	}
+	return common.CopyToGuest(imageFile, source, strings.TrimPrefix(destination, GuestPathPrefix), key)
+}
+
+// EditGuestFile edits a file of the image in place with a Perl expression or, when expr
+// is empty, interactively in $EDITOR.
+func EditGuestFile(cfg *config.Config, imageFile, guestPath, expr string) error {
+	if err := checkGuestImage(imageFile, "virt-edit"); err != nil {
+		return err
+	}
+	key, cleanup, err := luksKeySelector(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return common.EditGuestFile(imageFile, guestPath, key, expr)
+}
@@ -0,0 +1,25 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func TestCopyGuestFileRequiresOneGuestPath(t *testing.T) {
+	tests := []struct {
+		name, source, destination string
+	}{
+		{"both local", "./fstab", "./copy"},
+		{"both guest", ":/etc/fstab", ":/tmp/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CopyGuestFile(&config.Config{}, "disk.qcow2", tt.source, tt.destination)
+			if err == nil || !strings.Contains(err.Error(), "exactly one of") {
+				t.Errorf("Expected a guest path error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Package workflow provides the local history of the last migration run per source.
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/prompt"
+)
+
+// Actions taken when the source was already migrated by an earlier run.
+const (
+	ExistingMigrationPrompt  = "prompt"  // Ask, or replace with --yes
+	ExistingMigrationResume  = "resume"  // Reuse the exported disk and imported image
+	ExistingMigrationReplace = "replace" // Run the full workflow again
+	ExistingMigrationAbort   = "abort"   // Stop before any step runs
+)
+
+// MigrationRecord describes the last run of a migration of a source.
+type MigrationRecord struct {
+	Source         string    `json:"source"`
+	Workflow       string    `json:"workflow"`
+	Status         string    `json:"status"`
+	ImageID        string    `json:"imageId,omitempty"`
+	InstanceID     string    `json:"instanceId,omitempty"`
+	ExportDir      string    `json:"exportDir,omitempty"`
+	CompletedSteps []string  `json:"completedSteps,omitempty"`
+	FinishedAt     time.Time `json:"finishedAt"`
+}
+
+// migrationHistory is the content of the history file.
+type migrationHistory struct {
+	Migrations map[string]MigrationRecord `json:"migrations"`
+}
+
+// DefaultHistoryFile returns the history file used when MIGRATION_HISTORY_FILE is not set.
+func DefaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kopru", "migrations.json")
+}
+
+func historyFile(cfg *config.Config) string {
+	if cfg.MigrationHistoryFile != "" {
+		return cfg.MigrationHistoryFile
+	}
+	return DefaultHistoryFile()
+}
+
+// migrationSource returns the key identifying the migrated source in the history.
+func migrationSource(cfg *config.Config) string {
+	switch cfg.SourcePlatform {
+	case "azure":
+		return fmt.Sprintf("azure/%s/%s/%s", cfg.AzureSubscriptionID, cfg.AzureResourceGroup, cfg.AzureComputeName)
+	case "linux_image":
+		return "linux_image/" + cfg.OSImageURL
+	}
+	return ""
+}
+
+// loadHistory reads the history file. A missing file yields an empty history.
+func loadHistory(path string) (*migrationHistory, error) {
+	h := &migrationHistory{Migrations: make(map[string]MigrationRecord)}
+	data, err := os.ReadFile(path) // #nosec G304 -- the history file is configured by the user
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration history: %w", err)
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse migration history %s: %w", path, err)
+	}
+	if h.Migrations == nil {
+		h.Migrations = make(map[string]MigrationRecord)
+	}
+	return h, nil
+}
+
+// save writes the history file, replacing it atomically.
+func (h *migrationHistory) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create migration history directory: %w", err)
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode migration history: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write migration history: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write migration history: %w", err)
+	}
+	return nil
+}
+
+// newMigrationRecord returns the history record of the run described by s.
+func newMigrationRecord(source string, s *RunSummary) MigrationRecord {
+	r := MigrationRecord{
+		Source:     source,
+		Workflow:   s.Workflow,
+		Status:     s.Status,
+		ImageID:    s.Artifacts.ImageID,
+		InstanceID: s.Artifacts.InstanceID,
+		ExportDir:  s.Artifacts.ExportDir,
+		FinishedAt: s.FinishedAt,
+	}
+	for _, step := range s.Steps {
+		if step.Status == StatusSucceeded {
+			r.CompletedSteps = append(r.CompletedSteps, step.Name)
+		}
+	}
+	return r
+}
+
+// recordMigration saves the outcome of the run described by s as the last migration of
+// the configured source.
+func recordMigration(cfg *config.Config, s *RunSummary) error {
+	path, source := historyFile(cfg), migrationSource(cfg)
+	if path == "" || source == "" {
+		return nil
+	}
+	h, err := loadHistory(path)
+	if err != nil {
+		return err
+	}
+	h.Migrations[source] = newMigrationRecord(source, s)
+	return h.save(path)
+}
+
+// checkPreviousMigration warns when the configured source was migrated by an earlier run
+// and applies the configured, or chosen, action before the workflow runs.
+func checkPreviousMigration(cfg *config.Config, log *logger.Logger) error {
+	path, source := historyFile(cfg), migrationSource(cfg)
+	if path == "" || source == "" {
+		return nil
+	}
+	h, err := loadHistory(path)
+	if err != nil {
+		return err
+	}
+	r, ok := h.Migrations[source]
+	if !ok {
+		return nil
+	}
+	log.Warning(i18n.T("history.previous_migration", source, r.Status, r.FinishedAt.Local().Format(time.RFC1123)))
+	log.Infof("  Image: %s", valueOrDash(r.ImageID))
+	log.Infof("  Instance: %s", valueOrDash(r.InstanceID))
+
+	action, err := existingMigrationAction(cfg, log)
+	if err != nil {
+		return err
+	}
+	switch action {
+	case ExistingMigrationAbort:
+		return errors.New(i18n.T("history.aborted", source))
+	case ExistingMigrationResume:
+		resumeMigration(cfg, log, r)
+	default:
+		log.Info(i18n.T("history.replacing"))
+	}
+	return nil
+}
+
+// existingMigrationAction returns EXISTING_MIGRATION_ACTION, asking the user when it is
+// prompt. Without a terminal, --yes replaces the migration and anything else fails.
+func existingMigrationAction(cfg *config.Config, log *logger.Logger) (string, error) {
+	if cfg.ExistingMigrationAction != ExistingMigrationPrompt && cfg.ExistingMigrationAction != "" {
+		return cfg.ExistingMigrationAction, nil
+	}
+	if cfg.AssumeYes {
+		log.Info(i18n.T("guardrail.confirmed_yes", i18n.T("history.replace_title")))
+		return ExistingMigrationReplace, nil
+	}
+	if !isInteractive() {
+		return "", errors.New(i18n.T("history.non_interactive"))
+	}
+	return newPrompter().Select(i18n.T("history.select"), []prompt.Option{
+		{Label: "Resume: reuse the exported disk and imported image of the previous run", Value: ExistingMigrationResume},
+		{Label: "Replace: run the full migration again", Value: ExistingMigrationReplace},
+		{Label: "Abort", Value: ExistingMigrationAbort},
+	})
+}
+
+// resumeMigration skips the export when the previous run exported the OS disk and reuses
+// the image it imported.
+func resumeMigration(cfg *config.Config, log *logger.Logger, r MigrationRecord) {
+	if slices.Contains(r.CompletedSteps, "Export OS disk") || slices.Contains(r.CompletedSteps, "Download OS image") {
+		cfg.SkipExport = true
+		log.Infof("Resuming: reusing the OS disk exported by the previous run to %s", valueOrDash(r.ExportDir))
+	}
+	if r.ImageID != "" || slices.Contains(r.CompletedSteps, "Import OS image") {
+		cfg.ImageConflictPolicy = imageConflictReuse
+		log.Info("Resuming: reusing the image imported by the previous run")
+	}
+}
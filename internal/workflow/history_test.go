@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func testHistoryConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		SourcePlatform:       "azure",
+		AzureSubscriptionID:  "sub",
+		AzureResourceGroup:   "rg",
+		AzureComputeName:     "vm",
+		ImageConflictPolicy:  imageConflictSuffix,
+		MigrationHistoryFile: filepath.Join(t.TempDir(), "kopru", "migrations.json"),
+	}
+}
+
+func TestRecordMigration(t *testing.T) {
+	cfg := testHistoryConfig(t)
+	s := &RunSummary{
+		Workflow:   "Azure to OCI",
+		Status:     StatusFailed,
+		FinishedAt: time.Now().UTC(),
+		Artifacts:  SummaryArtifacts{ImageID: "ocid1.image.oc1..v1", ExportDir: "./vm-os-disk-export"},
+		Steps: []StepResult{
+			{Name: "Export OS disk", Status: StatusSucceeded},
+			{Name: "Deploy template", Status: StatusFailed},
+		},
+	}
+	if err := recordMigration(cfg, s); err != nil {
+		t.Fatalf("recordMigration failed: %v", err)
+	}
+
+	h, err := loadHistory(cfg.MigrationHistoryFile)
+	if err != nil {
+		t.Fatalf("loadHistory failed: %v", err)
+	}
+	r, ok := h.Migrations["azure/sub/rg/vm"]
+	if !ok {
+		t.Fatalf("Expected a record for the source, got %v", h.Migrations)
+	}
+	if r.ImageID != "ocid1.image.oc1..v1" || r.Status != StatusFailed || len(r.CompletedSteps) != 1 {
+		t.Errorf("Unexpected record: %+v", r)
+	}
+}
+
+func TestCheckPreviousMigration(t *testing.T) {
+	tests := []struct {
+		name        string
+		action      string
+		assumeYes   bool
+		interactive bool
+		input       string
+		expectError bool
+		expectSkip  bool
+	}{
+		{"resume", ExistingMigrationResume, false, false, "", false, true},
+		{"replace", ExistingMigrationReplace, false, false, "", false, false},
+		{"abort", ExistingMigrationAbort, false, false, "", true, false},
+		{"prompt with --yes replaces", ExistingMigrationPrompt, true, false, "", false, false},
+		{"prompt without a terminal", ExistingMigrationPrompt, false, false, "", true, false},
+		{"prompt resume", ExistingMigrationPrompt, false, true, "1\n", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withPrompt(t, tt.interactive, tt.input)
+			cfg := testHistoryConfig(t)
+			s := &RunSummary{
+				Status:    StatusFailed,
+				Artifacts: SummaryArtifacts{ImageID: "ocid1.image.oc1..v1"},
+				Steps:     []StepResult{{Name: "Export OS disk", Status: StatusSucceeded}},
+			}
+			if err := recordMigration(cfg, s); err != nil {
+				t.Fatalf("recordMigration failed: %v", err)
+			}
+			cfg.ExistingMigrationAction = tt.action
+			cfg.AssumeYes = tt.assumeYes
+
+			err := checkPreviousMigration(cfg, logger.New(false))
+			if tt.expectError != (err != nil) {
+				t.Fatalf("Expected error %t, got %v", tt.expectError, err)
+			}
+			if cfg.SkipExport != tt.expectSkip {
+				t.Errorf("Expected SkipExport %t, got %t", tt.expectSkip, cfg.SkipExport)
+			}
+			if tt.expectSkip && cfg.ImageConflictPolicy != imageConflictReuse {
+				t.Errorf("Expected the imported image to be reused, got policy %q", cfg.ImageConflictPolicy)
+			}
+		})
+	}
+}
+
+func TestCheckPreviousMigrationNoHistory(t *testing.T) {
+	withPrompt(t, false, "")
+	cfg := testHistoryConfig(t)
+	if err := checkPreviousMigration(cfg, logger.New(false)); err != nil {
+		t.Errorf("Expected no error without a history, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+// Package workflow provides the custom image import settings shared by workflow handlers.
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// Policies for an image name already used in the compartment (IMAGE_CONFLICT_POLICY).
+const (
+	imageConflictReuse  = "reuse"
+	imageConflictFail   = "fail"
+	imageConflictSuffix = "suffix"
+)
+
+// Freeform tag set on the OCI resources created by Kopru, used to find them for clean-up.
+const (
+	createdByTagKey   = "created-by"
+	createdByTagValue = "kopru"
+)
+
+// maxImageVersion bounds the versioned names tried by the suffix policy.
+const maxImageVersion = 99
+
+// imageLookup returns the OCID of the image with the given display name, or "" when
+// there is none.
+type imageLookup func(ctx context.Context, displayName string) (string, error)
+
+// imageImportOptions returns the settings of the custom image imported from the
+// uploaded QCOW2 object.
+func imageImportOptions(cfg *config.Config, imageName string) oci.ImageImportOptions {
+	return oci.ImageImportOptions{
+		DisplayName:            imageName,
+		OperatingSystem:        cfg.OCIImageOS,
+		OperatingSystemVersion: cfg.OCIImageOSVersion,
+		LaunchMode:             core.CreateImageDetailsLaunchModeEnum(cfg.OCIImageLaunchMode),
+		FreeformTags:           map[string]string{createdByTagKey: createdByTagValue},
+	}
+}
+
+// resolveImageName applies the conflict policy when an image named imageName already
+// exists. It returns the name to import the image as, or the OCID of the existing image
+// when the policy is to reuse it.
+func resolveImageName(ctx context.Context, log *logger.Logger, policy, imageName string, lookup imageLookup) (name, existingID string, err error) {
+	existingID, err = lookup(ctx, imageName)
+	if err != nil || existingID == "" {
+		return imageName, "", err
+	}
+	switch policy {
+	case imageConflictReuse:
+		log.Infof("Reusing existing image '%s': %s", imageName, existingID)
+		return imageName, existingID, nil
+	case imageConflictFail:
+		return "", "", fmt.Errorf("an image named '%s' already exists: %s (set IMAGE_CONFLICT_POLICY to reuse or suffix)", imageName, existingID)
+	}
+	for version := 2; version <= maxImageVersion; version++ {
+		name := fmt.Sprintf("%s-v%d", imageName, version)
+		id, err := lookup(ctx, name)
+		if err != nil {
+			return "", "", err
+		}
+		if id == "" {
+			log.Infof("An image named '%s' already exists, importing as '%s'", imageName, name)
+			return name, "", nil
+		}
+	}
+	return "", "", fmt.Errorf("images '%s' to '%s-v%d' already exist", imageName, imageName, maxImageVersion)
+}
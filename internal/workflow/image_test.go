@@ -0,0 +1,62 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+func TestImageImportOptions(t *testing.T) {
+	cfg := &config.Config{OCIImageOS: "CentOS", OCIImageOSVersion: "6", OCIImageLaunchMode: "EMULATED"}
+	opts := imageImportOptions(cfg, "legacy-imported-image")
+	if opts.DisplayName != "legacy-imported-image" || opts.OperatingSystem != "CentOS" || opts.OperatingSystemVersion != "6" {
+		t.Errorf("Unexpected image import options: %+v", opts)
+	}
+	if opts.LaunchMode != core.CreateImageDetailsLaunchModeEmulated {
+		t.Errorf("LaunchMode = %s, want EMULATED", opts.LaunchMode)
+	}
+	if opts.FreeformTags["created-by"] != "kopru" {
+		t.Errorf("FreeformTags = %v, want created-by=kopru", opts.FreeformTags)
+	}
+}
+
+func TestResolveImageName(t *testing.T) {
+	existing := map[string]string{
+		"vm-imported-image":    "ocid1.image.oc1..v1",
+		"vm-imported-image-v2": "ocid1.image.oc1..v2",
+	}
+	lookup := func(ctx context.Context, name string) (string, error) { return existing[name], nil }
+
+	tests := []struct {
+		name, policy, imageName string
+		wantName, wantID        string
+		wantErr                 bool
+	}{
+		{"no conflict", imageConflictFail, "new-imported-image", "new-imported-image", "", false},
+		{"reuse", imageConflictReuse, "vm-imported-image", "vm-imported-image", "ocid1.image.oc1..v1", false},
+		{"fail", imageConflictFail, "vm-imported-image", "", "", true},
+		{"suffix", imageConflictSuffix, "vm-imported-image", "vm-imported-image-v3", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, id, err := resolveImageName(context.Background(), logger.New(false), tt.policy, tt.imageName, lookup)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveImageName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if name != tt.wantName || id != tt.wantID {
+				t.Errorf("resolveImageName() = %q, %q, want %q, %q", name, id, tt.wantName, tt.wantID)
+			}
+		})
+	}
+
+	_, _, err := resolveImageName(context.Background(), logger.New(false), imageConflictSuffix, "x", func(context.Context, string) (string, error) {
+		return "", errors.New("list failed")
+	})
+	if err == nil {
+		t.Error("Expected lookup error")
+	}
+}
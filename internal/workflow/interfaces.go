@@ -23,6 +23,16 @@ type Handler interface {
 	// Initialize prepares the workflow handler with configuration and logger
 	Initialize(cfg *config.Config, log *logger.Logger) error
 
+	// InitializeOffline prepares the workflow handler to list its steps only, without
+	// creating the cloud providers and so without credentials
+	InitializeOffline(cfg *config.Config, log *logger.Logger) error
+
+	// Steps returns the ordered steps of the workflow for the current configuration
+	Steps() []Step
+
 	// Execute runs the complete migration workflow
 	Execute(ctx context.Context) error
+
+	// Summarize adds source details and produced artifacts to the run summary
+	Summarize(s *RunSummary)
 }
@@ -0,0 +1,117 @@
+// Package workflow provides the OCI service limit preflight check shared by workflow handlers.
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/template"
+)
+
+// migrationFootprint describes the OCI resources a migration will create.
+// Disk sizes of zero are unknown and counted at the OCI minimum volume size.
+type migrationFootprint struct {
+	osDiskGB     int64
+	dataDisksGB  []int64
+	architecture string
+	shape        string // Shape of the instance; the default shape of architecture when empty
+	vcpus        int32
+	memoryGB     int32
+	instance     bool
+}
+
+// limitRequests returns the service limits the footprint consumes. Block volumes and
+// cores are counted against availabilityDomain; AD-scoped limits are left out when it
+// is empty.
+func (f migrationFootprint) limitRequests(availabilityDomain string) []oci.LimitRequest {
+	requests := []oci.LimitRequest{
+		{Service: oci.LimitServiceCompute, Limit: oci.LimitCustomImageCount, Required: 1, Description: "Custom images"},
+	}
+	if f.osDiskGB > 0 {
+		requests = append(requests, oci.LimitRequest{
+			Service: oci.LimitServiceObjectStorage, Limit: oci.LimitStorageBytes,
+			Required: f.osDiskGB << 30, Description: "Object Storage bytes for the OS image",
+		})
+	}
+	if availabilityDomain == "" {
+		return requests
+	}
+	var volumesGB int64
+	if f.instance {
+		volumesGB += max(f.osDiskGB, common.OCIMinVolumeSizeGB)
+	}
+	for _, sizeGB := range f.dataDisksGB {
+		volumesGB += max(sizeGB, common.OCIMinVolumeSizeGB)
+	}
+	if volumesGB > 0 {
+		requests = append(requests, oci.LimitRequest{
+			Service: oci.LimitServiceBlockStorage, Limit: oci.LimitTotalStorageGB, AvailabilityDomain: availabilityDomain,
+			Required: volumesGB, Description: "Block volume storage (GB)",
+		})
+	}
+	if f.instance {
+		shape := f.shape
+		if shape == "" {
+			shape = template.Shape(f.architecture)
+		}
+		ocpus, memoryGB := template.ShapeResources(shape, f.vcpus, f.memoryGB, f.architecture)
+		requests = append(requests, shapeLimitRequests(shape, availabilityDomain, ocpus, memoryGB)...)
+	}
+	return requests
+}
+
+// shapeLimitRequests returns the cores and memory limits an instance of shape with ocpus
+// and memoryGB consumes in availabilityDomain. The memory limit is left out for the
+// default memory of the shape, memoryGB 0.
+func shapeLimitRequests(shape, availabilityDomain string, ocpus, memoryGB int32) []oci.LimitRequest {
+	prefix := oci.ShapeLimitPrefix(shape)
+	requests := []oci.LimitRequest{{
+		Service: oci.LimitServiceCompute, Limit: prefix + "-core-count", AvailabilityDomain: availabilityDomain,
+		Required: int64(ocpus), Description: shape + " cores",
+	}}
+	if memoryGB > 0 {
+		requests = append(requests, oci.LimitRequest{
+			Service: oci.LimitServiceCompute, Limit: prefix + "-memory-count", AvailabilityDomain: availabilityDomain,
+			Required: int64(memoryGB), Description: shape + " memory (GB)",
+		})
+	}
+	return requests
+}
+
+// checkOCILimits verifies that the service limits and compartment quotas of the
+// target compartment leave room for the resources a migration will create, so that
+// it fails before exporting any disk rather than midway through the import.
+func checkOCILimits(ctx context.Context, log *logger.Logger, provider *oci.Provider, cfg *config.Config, footprint migrationFootprint) error {
+	availabilityDomain, err := instanceAvailabilityDomain(ctx, provider, cfg)
+	if err != nil {
+		log.Warningf("Skipping availability domain limits: %v", err)
+	}
+	shortfalls, err := provider.CheckLimits(ctx, cfg.OCICompartmentID, footprint.limitRequests(availabilityDomain))
+	if err != nil {
+		return err
+	}
+	if len(shortfalls) == 0 {
+		log.Success("✓ OCI service limits and quotas allow the migration")
+		return nil
+	}
+	log.Error("OCI service limits or compartment quotas are too low for this migration:")
+	for _, s := range shortfalls {
+		log.Errorf("  %s", s)
+	}
+	log.Error("Request a service limit increase or free up resources, then run Kopru again.")
+	return fmt.Errorf("%d OCI service limit(s) would be exceeded in compartment %s", len(shortfalls), cfg.OCICompartmentID)
+}
+
+// instanceAvailabilityDomain resolves the OCI_AVAILABILITY_DOMAIN number to the
+// name of the availability domain the instance is deployed to.
+func instanceAvailabilityDomain(ctx context.Context, provider *oci.Provider, cfg *config.Config) (string, error) {
+	number := cfg.OCIAvailabilityDomain
+	if number == "" {
+		number = template.DefaultAvailabilityDomain
+	}
+	return availabilityDomainName(ctx, provider, cfg, number)
+}
@@ -0,0 +1,63 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+)
+
+func TestMigrationFootprintLimitRequests(t *testing.T) {
+	f := migrationFootprint{
+		osDiskGB:     30,
+		dataDisksGB:  []int64{100, 0},
+		architecture: "x86_64",
+		vcpus:        4,
+		memoryGB:     16,
+		instance:     true,
+	}
+	got := map[string]oci.LimitRequest{}
+	for _, r := range f.limitRequests("AD-1") {
+		got[r.Service+"/"+r.Limit] = r
+	}
+	want := map[string]int64{
+		"compute/custom-image-count":       1,
+		"object-storage/storage-bytes":     30 << 30,
+		"block-storage/total-storage-gb":   50 + 100 + 50,
+		"compute/standard-e5-core-count":   2,
+		"compute/standard-e5-memory-count": 16,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("limitRequests() returned %d requests, want %d: %v", len(got), len(want), got)
+	}
+	for key, required := range want {
+		if r, ok := got[key]; !ok || r.Required != required {
+			t.Errorf("%s required = %d, want %d", key, r.Required, required)
+		}
+	}
+}
+
+func TestMigrationFootprintLimitRequestsWithoutInstance(t *testing.T) {
+	f := migrationFootprint{architecture: "ARM64"}
+	requests := f.limitRequests("AD-1")
+	if len(requests) != 1 || requests[0].Limit != oci.LimitCustomImageCount {
+		t.Errorf("limitRequests() = %v, want only the custom image count", requests)
+	}
+	f = migrationFootprint{dataDisksGB: []int64{200}, instance: true}
+	for _, r := range f.limitRequests("") {
+		if r.AvailabilityDomain != "" {
+			t.Errorf("limitRequests(\"\") included AD-scoped limit %s", r.Limit)
+		}
+	}
+}
+
+func TestMigrationFootprintLimitRequestsOfSelectedShape(t *testing.T) {
+	f := migrationFootprint{architecture: "x86_64", shape: "VM.Standard3.Flex", vcpus: 8, memoryGB: 32, instance: true}
+	var limits []string
+	for _, r := range f.limitRequests("AD-1") {
+		limits = append(limits, r.Limit)
+	}
+	if !slices.Contains(limits, "standard3-core-count") || slices.Contains(limits, "standard-e5-core-count") {
+		t.Errorf("limitRequests() = %v, want the limits of VM.Standard3.Flex", limits)
+	}
+}
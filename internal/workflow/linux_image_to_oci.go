@@ -13,6 +13,7 @@ import (
 	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
 	"github.com/codebypatrickleung/kopru-cli/internal/common"
 	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
 	"github.com/codebypatrickleung/kopru-cli/internal/logger"
 	"github.com/codebypatrickleung/kopru-cli/internal/template"
 	"github.com/oracle/oci-go-sdk/v65/core"
@@ -26,9 +27,17 @@ type LinuxImageToOCIHandler struct {
 	osImageURL        string
 	osDiskSizeGB      int64
 	osArchitecture    string
+	configureEngine   string
+	configurators     []common.Configurator
 	imageExportDir    string
 	templateOutputDir string
 	importedImageID   string
+	instanceID        string
+	privateIPs        []string
+	publicIPs         []string
+	checksums         checksumLog
+	bootBeacon        *BootBeaconResult
+	finishing         *FinishingResult
 }
 
 func NewLinuxImageToOCIHandler() *LinuxImageToOCIHandler { return &LinuxImageToOCIHandler{} }
@@ -37,11 +46,20 @@ func (h *LinuxImageToOCIHandler) SourcePlatform() string { return "linux_image"
 func (h *LinuxImageToOCIHandler) TargetPlatform() string { return "oci" }
 
 func (h *LinuxImageToOCIHandler) Initialize(cfg *config.Config, log *logger.Logger) error {
-	h.config, h.logger = cfg, log
+	if err := h.InitializeOffline(cfg, log); err != nil {
+		return err
+	}
 	var err error
 	if h.ociProvider, err = oci.NewProvider(cfg.OCIRegion, log); err != nil {
 		return fmt.Errorf("failed to initialize OCI provider: %w", err)
 	}
+	return nil
+}
+
+// InitializeOffline sets the configuration, the image URL and the directories of the
+// workflow, which are all that Steps needs.
+func (h *LinuxImageToOCIHandler) InitializeOffline(cfg *config.Config, log *logger.Logger) error {
+	h.config, h.logger = cfg, log
 
 	if cfg.OSImageURL != "" {
 		h.osImageURL = cfg.OSImageURL
@@ -60,70 +78,97 @@ func (h *LinuxImageToOCIHandler) Initialize(cfg *config.Config, log *logger.Logg
 
 func (h *LinuxImageToOCIHandler) Execute(ctx context.Context) error {
 	h.logger.Info("=========================================")
-	h.logger.Infof("Executing: %s", h.Name())
+	h.logger.Info(i18n.T("workflow.executing", h.Name()))
 	h.logger.Info("=========================================")
 
-	steps := []struct {
-		skip    bool
-		skipMsg string
-		errMsg  string
-		fn      func(context.Context) error
-	}{
-		{h.config.SkipExport, "Skipping OS image download (SKIP_OS_EXPORT=true)", "OS image download failed", h.downloadOSImage},
-	}
-
-	if err := h.runPrerequisites(ctx); err != nil {
-		return fmt.Errorf("prerequisite checks failed: %w", err)
-	}
-
-	for _, step := range steps {
-		if step.skip {
-			h.logger.Warning(step.skipMsg)
-			continue
-		}
-		if err := step.fn(ctx); err != nil {
-			return fmt.Errorf("%s: %w", step.errMsg, err)
-		}
-	}
-
-	if err := h.configureImage(ctx); err != nil {
-		return fmt.Errorf("image configuration failed: %w", err)
-	}
-	if err := h.uploadImage(ctx); err != nil {
-		return fmt.Errorf("image upload failed: %w", err)
-	}
-	if err := h.importOSImage(ctx); err != nil {
-		return fmt.Errorf("image import failed: %w", err)
-	}
-	if err := h.generateTemplate(ctx); err != nil {
-		return fmt.Errorf("template generation failed: %w", err)
-	}
-
-	if err := h.waitForImageImportCompletion(ctx); err != nil {
-		return fmt.Errorf("failed waiting for image import: %w", err)
+	defer h.ociProvider.MonitorSession(ctx)()
+	run := runSteps
+	if h.config.ParallelSteps {
+		run = runStepGraph
 	}
-
-	if !h.config.SkipTemplateDeploy {
-		if err := h.deployTemplate(ctx); err != nil {
-			return fmt.Errorf("template deployment failed: %w", err)
-		}
-	} else {
-		h.logger.Warning("Skipping template deployment (SKIP_TEMPLATE_DEPLOY=true)")
-		h.logger.Infof("To deploy manually, run: cd %s && tofu init && tofu apply", h.templateOutputDir)
-	}
-
-	if err := h.verifyWorkflow(ctx); err != nil {
-		return fmt.Errorf("workflow verification failed: %w", err)
+	if err := run(ctx, h.logger, h.Steps()); err != nil {
+		return err
 	}
 
 	h.logger.Success("=========================================")
-	h.logger.Success("Linux Image to OCI deployment completed successfully!")
+	h.logger.Success(i18n.T("workflow.linux_completed"))
 	h.logger.Success("=========================================")
 	return nil
 }
 
+// Steps returns the ordered list of steps that make up the Linux image to OCI workflow.
+func (h *LinuxImageToOCIHandler) Steps() []Step {
+	return configureSteps(h.config, []Step{
+		{ID: StepPrerequisites, Name: "Run prerequisite checks", ErrMsg: "prerequisite checks failed", Fn: h.runPrerequisites},
+		{
+			ID: StepExport, Name: "Download OS image", Skip: h.config.SkipExport,
+			SkipMsg: "Skipping OS image download (SKIP_OS_EXPORT=true)", ErrMsg: "OS image download failed",
+			Outputs: []string{ArtifactOSImageQCOW2}, Fn: h.downloadOSImage,
+		},
+		{
+			ID: StepConfigure, Name: "Configure image for OCI", ErrMsg: "image configuration failed",
+			Inputs: []string{ArtifactOSImageQCOW2}, Outputs: []string{ArtifactConfiguredImage}, Fn: h.configureImage,
+		},
+		{
+			ID: StepUpload, Name: "Upload image to OCI", ErrMsg: "image upload failed",
+			Inputs: []string{ArtifactConfiguredImage}, Outputs: []string{ArtifactUploadedObject}, Fn: h.uploadImage,
+		},
+		{
+			ID: StepImport, Name: "Import OS image", ErrMsg: "image import failed",
+			Inputs: []string{ArtifactUploadedObject}, Outputs: []string{ArtifactCustomImage}, Fn: h.importOSImage,
+		},
+		{
+			ID: StepTemplate, Name: "Generate template", ErrMsg: "template generation failed",
+			Inputs: []string{ArtifactCustomImage}, Outputs: []string{ArtifactTemplate}, Fn: h.generateTemplate,
+		},
+		{
+			ID: StepWaitImport, Name: "Wait for image import", ErrMsg: "failed waiting for image import",
+			Inputs: []string{ArtifactCustomImage}, Outputs: []string{ArtifactAvailableImage}, Fn: h.waitForImageImportCompletion,
+		},
+		{
+			ID: StepDeploy, Name: "Deploy template", Skip: h.config.SkipTemplateDeploy,
+			SkipMsg:  "Skipping template deployment (SKIP_TEMPLATE_DEPLOY=true)",
+			SkipHint: fmt.Sprintf("To deploy manually, run: cd %[1]s && %[2]s init && %[2]s apply", h.templateOutputDir, template.Engine(h.config.IaCEngine)),
+			ErrMsg:   "template deployment failed",
+			Inputs:   []string{ArtifactTemplate, ArtifactAvailableImage}, Outputs: []string{ArtifactInstance}, Fn: h.deployTemplate,
+		},
+		{
+			ID: StepFinishing, Name: "Run finishing script", Skip: h.config.SkipTemplateDeploy || h.config.FinishingScript == "" || h.config.StartsStopped(),
+			SkipMsg: "Skipping finishing script (FINISHING_SCRIPT not set, SKIP_TEMPLATE_DEPLOY=true or OCI_INSTANCE_STATE=STOPPED)",
+			ErrMsg:  "finishing script failed", Inputs: []string{ArtifactInstance}, Fn: h.runFinishingScript,
+		},
+		{ID: StepVerify, Name: "Verify workflow", ErrMsg: "workflow verification failed", Fn: h.verifyWorkflow},
+	})
+}
+
+// Summarize adds the source image details and produced OCI resources to the run summary.
+func (h *LinuxImageToOCIHandler) Summarize(s *RunSummary) {
+	s.Source = map[string]string{
+		"platform":     "linux_image",
+		"imageUrl":     h.osImageURL,
+		"architecture": h.osArchitecture,
+	}
+	if h.osDiskSizeGB > 0 {
+		s.Source["osDiskSizeGB"] = fmt.Sprint(h.osDiskSizeGB)
+	}
+	s.Artifacts = SummaryArtifacts{
+		ImageID:     h.importedImageID,
+		InstanceID:  h.instanceID,
+		PrivateIPs:  h.privateIPs,
+		PublicIPs:   h.publicIPs,
+		TemplateDir: h.templateOutputDir,
+		ExportDir:   h.imageExportDir,
+	}
+	if h.importedImageID != "" {
+		s.Artifacts.ImageLaunchMode = h.config.OCIImageLaunchMode
+	}
+	s.Checksums = h.checksums.list()
+	s.Finishing = h.finishing
+	s.BootBeacon = h.bootBeacon
+}
+
 func (h *LinuxImageToOCIHandler) runPrerequisites(ctx context.Context) error {
-	h.logger.Step(1, "Reviewing Deployment Configuration")
+	h.logger.Step(1, i18n.T("step.review_deployment"))
 	h.logger.Infof("OS Image URL: %s", h.osImageURL)
 	h.logger.Infof("OCI Compartment ID: %s", h.config.OCICompartmentID)
 	h.logger.Infof("OCI Subnet ID: %s", h.config.OCISubnetID)
@@ -132,10 +177,11 @@ func (h *LinuxImageToOCIHandler) runPrerequisites(ctx context.Context) error {
 	h.logger.Infof("OCI Image Name: %s", h.config.OCIImageName)
 	h.logger.Infof("OCI Image OS: %s", h.config.OCIImageOS)
 	h.logger.Infof("OCI Image OS Version: %s", h.config.OCIImageOSVersion)
+	h.logger.Infof("OCI Image Launch Mode: %s", h.config.OCIImageLaunchMode)
 	h.logger.Infof("Template Output Dir: %s", h.templateOutputDir)
 	h.logger.Infof("SSH Key File Path: %s", h.config.SSHKeyFilePath)
-	h.logger.Step(2, "Running Prerequisite Checks")
-	for _, tool := range []string{"qemu-img", "virt-customize", "curl"} {
+	h.logger.Step(2, i18n.T("step.prerequisites"))
+	for _, tool := range append([]string{"qemu-img", "virt-customize", "curl"}, optionalTools(h.config)...) {
 		if err := common.CheckCommand(tool); err != nil {
 			return fmt.Errorf("required tool missing: %w", err)
 		}
@@ -178,6 +224,21 @@ func (h *LinuxImageToOCIHandler) runPrerequisites(ctx context.Context) error {
 	}
 	h.logger.Successf("✓ OCI region configured: %s", h.config.OCIRegion)
 
+	h.configureEngine = resolveConfigureEngine(h.logger, h.config)
+	configurators, err := loadConfigurators(h.logger, h.config)
+	if err != nil {
+		return err
+	}
+	h.configurators = configurators
+	if err := checkFinishingScript(h.config); err != nil {
+		return err
+	}
+	if err := checkClockSkew(ctx, h.logger, h.config); err != nil {
+		return err
+	}
+	if err := checkOCISession(ctx, h.logger, h.ociProvider); err != nil {
+		return err
+	}
 	if err := h.ociProvider.CheckCompartmentExists(ctx, h.config.OCICompartmentID); err != nil {
 		return fmt.Errorf("OCI compartment check failed: %w", err)
 	}
@@ -191,6 +252,14 @@ func (h *LinuxImageToOCIHandler) runPrerequisites(ctx context.Context) error {
 		return fmt.Errorf("failed to get OCI namespace: %w", err)
 	}
 	h.logger.Successf("✓ OCI namespace retrieved: %s", namespace)
+	if err := checkOCIPolicies(ctx, h.logger, h.ociProvider, h.config.OCICompartmentID, namespace, ociPolicyResources(false, !h.config.SkipTemplateDeploy)); err != nil {
+		return err
+	}
+	if err := checkOCILimits(ctx, h.logger, h.ociProvider, h.config, migrationFootprint{
+		architecture: h.osArchitecture, instance: !h.config.SkipTemplateDeploy,
+	}); err != nil {
+		return err
+	}
 	bucketExists, err := h.ociProvider.CheckBucketExists(ctx, namespace, h.config.OCIBucketName)
 	if err != nil {
 		return fmt.Errorf("failed to check bucket: %w", err)
@@ -200,12 +269,12 @@ func (h *LinuxImageToOCIHandler) runPrerequisites(ctx context.Context) error {
 	} else {
 		h.logger.Successf("✓ Bucket '%s' exists", h.config.OCIBucketName)
 	}
-	h.logger.Success("Prerequisite checks passed")
+	h.logger.Success(i18n.T("workflow.prereq_passed"))
 	return nil
 }
 
 func (h *LinuxImageToOCIHandler) downloadOSImage(ctx context.Context) error {
-	h.logger.Step(3, "Downloading Linux Cloud Image")
+	h.logger.Step(3, i18n.T("step.download_image"))
 
 	if err := common.EnsureDir(h.imageExportDir); err != nil {
 		return fmt.Errorf("failed to create download directory: %w", err)
@@ -255,58 +324,73 @@ func (h *LinuxImageToOCIHandler) downloadOSImage(ctx context.Context) error {
 	}
 
 	h.logger.Successf("Linux cloud image downloaded to: %s", destPath)
+	if h.config.VerifyChecksums {
+		return h.checksums.hashFile(h.logger, ArtifactOSImageQCOW2, destPath)
+	}
 	return nil
 }
 
 func (h *LinuxImageToOCIHandler) configureImage(ctx context.Context) error {
-	h.logger.Step(4, "Configuring Image for OCI")
+	h.logger.Step(4, i18n.T("step.configure_image"))
 	qcow2File, err := common.FindDiskFile(h.imageExportDir, ".qcow2")
 	if err != nil {
 		return fmt.Errorf("failed to find QCOW2 file: %w", err)
 	}
 	h.logger.Infof("Configuring QCOW2 file: %s", qcow2File)
-
-	h.logger.Info("Applying OS configurations ...")
-	if err := common.ExecuteOSConfigScript(qcow2File, h.config.OCIImageOS, h.SourcePlatform(), h.logger); err != nil {
-		return fmt.Errorf("failed to execute OS configuration script: %w", err)
+	c := h.imageConfiguration()
+	if h.config.BootBeacon {
+		if c.beaconURL, c.beaconMethod, err = prepareBootBeacon(ctx, h.logger, h.ociProvider, h.config); err != nil {
+			return err
+		}
 	}
+	return c.apply(ctx, qcow2File, h.imageExportDir, true)
+}
 
-	h.logger.Success("Image configurations complete")
-	return nil
+func (h *LinuxImageToOCIHandler) imageConfiguration() imageConfiguration {
+	return imageConfiguration{
+		cfg:            h.config,
+		log:            h.logger,
+		sourcePlatform: h.SourcePlatform(),
+		engine:         h.configureEngine,
+		configurators:  h.configurators,
+	}
 }
 
 func (h *LinuxImageToOCIHandler) uploadImage(ctx context.Context) error {
-	h.logger.Step(5, "Uploading Image to OCI")
+	h.logger.Step(5, i18n.T("step.upload_image"))
 
 	qcow2File, err := common.FindDiskFile(h.imageExportDir, ".qcow2")
 	if err != nil {
 		return fmt.Errorf("failed to find QCOW2 file: %w", err)
 	}
-	namespace, err := h.ociProvider.GetNamespace(ctx)
+	namespace, err := ensureBucket(ctx, h.logger, h.ociProvider, h.config)
 	if err != nil {
-		return fmt.Errorf("failed to get namespace: %w", err)
+		return err
 	}
-	bucketExists, err := h.ociProvider.CheckBucketExists(ctx, namespace, h.config.OCIBucketName)
-	if err != nil {
-		return fmt.Errorf("failed to check bucket: %w", err)
+	objectName := filepath.Base(qcow2File)
+	if err := confirmUpload(h.config, h.logger, qcow2File, namespace, objectName); err != nil {
+		return err
 	}
-	if !bucketExists {
-		h.logger.Infof("Creating bucket '%s'...", h.config.OCIBucketName)
-		if err := h.ociProvider.CreateBucket(ctx, namespace, h.config.OCICompartmentID, h.config.OCIBucketName); err != nil {
-			return fmt.Errorf("failed to create bucket: %w", err)
+	var hashes common.FileHashes
+	if h.config.VerifyChecksums {
+		if hashes, err = common.HashFile(qcow2File, oci.UploadPartSize, h.logger); err != nil {
+			return err
 		}
 	}
-	objectName := filepath.Base(qcow2File)
 	h.logger.Infof("Uploading %s to bucket %s (this may take a while)...", objectName, h.config.OCIBucketName)
-	if err := h.ociProvider.UploadToObjectStorage(ctx, namespace, h.config.OCIBucketName, objectName, qcow2File); err != nil {
+	objectMD5, err := h.ociProvider.UploadToObjectStorage(ctx, namespace, h.config.OCIBucketName, objectName, qcow2File)
+	if err != nil {
 		return fmt.Errorf("failed to upload to Object Storage: %w", err)
 	}
 	h.logger.Success("Image uploaded to OCI")
+	if h.config.VerifyChecksums {
+		return h.checksums.verifyUpload(h.logger, qcow2File, hashes, objectMD5)
+	}
 	return nil
 }
 
 func (h *LinuxImageToOCIHandler) importOSImage(ctx context.Context) error {
-	h.logger.Step(6, "Importing OS Image in OCI")
+	h.logger.Step(6, i18n.T("step.import_image"))
 
 	namespace, objectName, err := h.getImageImportDetails(ctx)
 	if err != nil {
@@ -317,19 +401,20 @@ func (h *LinuxImageToOCIHandler) importOSImage(ctx context.Context) error {
 		common.SanitizeName(h.config.OCIImageOS),
 		common.SanitizeName(h.config.OCIImageOSVersion))
 
+	imageName, existingID, err := resolveImageName(ctx, h.logger, h.config.ImageConflictPolicy, imageName, func(ctx context.Context, name string) (string, error) {
+		return h.ociProvider.FindImageByName(ctx, h.config.OCICompartmentID, name)
+	})
+	if err != nil {
+		return err
+	}
+	if existingID != "" {
+		h.importedImageID = existingID
+		return nil
+	}
 	h.logger.Infof("Starting OS image import: %s", imageName)
 	h.logger.Info("Image import will run in the background (10-20 minutes)")
 
-	imageID, err := h.ociProvider.ImportImage(
-		ctx,
-		h.config.OCICompartmentID,
-		namespace,
-		h.config.OCIBucketName,
-		objectName,
-		imageName,
-		h.config.OCIImageOS,
-		h.config.OCIImageOSVersion,
-	)
+	imageID, err := h.ociProvider.ImportImage(ctx, h.config.OCICompartmentID, namespace, h.config.OCIBucketName, objectName, imageImportOptions(h.config, imageName))
 	if err != nil {
 		return fmt.Errorf("failed to start image import: %w", err)
 	}
@@ -355,7 +440,7 @@ func (h *LinuxImageToOCIHandler) getImageImportDetails(ctx context.Context) (nam
 }
 
 func (h *LinuxImageToOCIHandler) generateTemplate(ctx context.Context) error {
-	h.logger.Step(7, "Generating Template")
+	h.logger.Step(7, i18n.T("step.generate_template"))
 	if h.osDiskSizeGB == 0 {
 		h.logger.Info("Reading OS disk size from QCOW2 file...")
 		qcow2File, err := common.FindDiskFile(h.imageExportDir, ".qcow2")
@@ -379,6 +464,10 @@ func (h *LinuxImageToOCIHandler) generateTemplate(ctx context.Context) error {
 		h.osDiskSizeGB, 0, 0, h.osArchitecture,
 		h.templateOutputDir,
 	)
+	tfGen.SetSessionProfile(h.ociProvider.SessionProfile())
+	if err := setBackendNamespace(ctx, h.config, h.ociProvider, tfGen); err != nil {
+		return err
+	}
 	return tfGen.GenerateTemplate()
 }
 
@@ -399,7 +488,7 @@ func (h *LinuxImageToOCIHandler) waitForImageImportCompletion(ctx context.Contex
 }
 
 func (h *LinuxImageToOCIHandler) deployTemplate(ctx context.Context) error {
-	h.logger.Step(8, "Deploying the template")
+	h.logger.Step(8, i18n.T("step.deploy_template"))
 
 	tfGen := template.NewOCIGenerator(
 		h.config, h.logger, h.importedImageID,
@@ -407,11 +496,32 @@ func (h *LinuxImageToOCIHandler) deployTemplate(ctx context.Context) error {
 		h.osDiskSizeGB, 0, 0, h.osArchitecture,
 		h.templateOutputDir,
 	)
-	return tfGen.DeployTemplate()
+	tfGen.SetApplyConfirmation(func(planSummary string) error {
+		return confirmApply(h.config, h.logger, planSummary)
+	})
+	if err := tfGen.DeployTemplate(); err != nil {
+		return err
+	}
+	h.instanceID = tfGen.InstanceID()
+	h.privateIPs, h.publicIPs = lookupInstanceIPs(ctx, h.logger, h.ociProvider, h.config.OCICompartmentID, h.instanceID)
+	return nil
+}
+
+func (h *LinuxImageToOCIHandler) runFinishingScript(ctx context.Context) error {
+	h.logger.Step(9, i18n.T("step.finishing_script"))
+	result, err := runFinishingScript(ctx, h.logger, h.ociProvider, h.config, h.instanceID)
+	h.finishing = result
+	return err
 }
 
 func (h *LinuxImageToOCIHandler) verifyWorkflow(ctx context.Context) error {
-	h.logger.Step(9, "Verifying Workflow")
+	h.logger.Step(10, i18n.T("step.verify_workflow"))
+
+	beacon, err := verifyBootBeacon(ctx, h.logger, h.ociProvider, h.config, h.instanceID)
+	h.bootBeacon = beacon
+	if err != nil {
+		return err
+	}
 
 	if !h.config.SkipExport {
 		if qcow2File, err := common.FindDiskFile(h.imageExportDir, ".qcow2"); err == nil {
@@ -421,16 +531,19 @@ func (h *LinuxImageToOCIHandler) verifyWorkflow(ctx context.Context) error {
 	if _, err := os.Stat(h.templateOutputDir); err == nil {
 		h.logger.Successf("✓ Template files exist in: %s", h.templateOutputDir)
 	}
-	h.logger.Success("Workflow verification complete")
+	h.logger.Success(i18n.T("workflow.verify_complete"))
 	h.logger.Info("=========================================")
-	h.logger.Info("Next Steps:")
-	if !h.config.SkipTemplateDeploy {
-		h.logger.Info("1. Check the OCI console for the deployed instance")
-		h.logger.Info("2. Verify the instance is running as expected")
+	h.logger.Info(i18n.T("workflow.next_steps"))
+	if h.config.SkipTemplateDeploy {
+		h.logger.Info(i18n.T("workflow.next_navigate", 1, h.templateOutputDir))
+		h.logger.Info(i18n.T("workflow.next_run_iac", 2, template.Engine(h.config.IaCEngine)))
+		h.logger.Info(i18n.T("workflow.next_check_console", 3))
+	} else if h.config.StartsStopped() {
+		h.logger.Info(i18n.T("workflow.next_start", 1))
+		h.logger.Info(i18n.T("workflow.next_verify", 2))
 	} else {
-		h.logger.Infof("1. Navigate to: %s", h.templateOutputDir)
-		h.logger.Info("2. Run: tofu init && tofu apply")
-		h.logger.Info("3. Check the OCI console for the deployed instance")
+		h.logger.Info(i18n.T("workflow.next_check_console", 1))
+		h.logger.Info(i18n.T("workflow.next_verify", 2))
 	}
 	h.logger.Info("=========================================")
 	return nil
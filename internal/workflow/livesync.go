@@ -0,0 +1,178 @@
+// Package workflow provides the live sync of the disks of a running Azure VM through incremental snapshots.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// LiveSyncResult records the two passes of a live sync in the run summary.
+type LiveSyncResult struct {
+	Disks             []string `json:"disks"`
+	FirstPassSeconds  float64  `json:"firstPassSeconds"`
+	StopWindowSeconds float64  `json:"stopWindowSeconds"` // From the VM found stopped to the last change applied
+	DeltaBytes        int64    `json:"deltaBytes"`
+}
+
+// liveDisk is a disk exported by a live sync and the directory it is exported to.
+type liveDisk struct {
+	name string
+	dir  string
+}
+
+// liveSync exports the disks of a running VM once, before the first disk is exported,
+// whichever export step runs first. The first pass exports each disk whole while the
+// VM runs; once the VM is stopped, the second pass applies the pages changed since, so
+// the VM is only down for the time it takes to copy the changes.
+type liveSync struct {
+	once   sync.Once
+	err    error
+	files  map[string]string // Exported VHD of each disk, by disk name
+	result *LiveSyncResult
+}
+
+// run syncs the disks of the VM when LIVE_SYNC is set and the VM is running. The OS
+// disk is left out when it is not exported. Between the passes, power deallocates the
+// VM with AZURE_STOP_VM; otherwise the operator stops it.
+func (l *liveSync) run(ctx context.Context, log *logger.Logger, provider *azure.Provider, cfg *config.Config, power *sourcePower, osExportDir, dataExportDir string) error {
+	l.once.Do(func() {
+		if !cfg.LiveSync {
+			return
+		}
+		stopped, err := provider.CheckComputeIsStopped(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+		if err != nil {
+			l.err = fmt.Errorf("failed to check Compute instance state: %w", err)
+			return
+		}
+		if stopped {
+			log.Info(i18n.T("livesync.single_pass"))
+			return
+		}
+		disks, err := liveSyncDisks(ctx, provider, cfg, osExportDir, dataExportDir)
+		if err != nil {
+			l.err = err
+			return
+		}
+		if len(disks) == 0 {
+			return
+		}
+		l.err = l.sync(ctx, log, provider, cfg, power, disks)
+	})
+	return l.err
+}
+
+// liveSyncDisks returns the disks of the VM exported by the workflow, with their export
+// directories, which it creates.
+func liveSyncDisks(ctx context.Context, provider *azure.Provider, cfg *config.Config, osExportDir, dataExportDir string) ([]liveDisk, error) {
+	var disks []liveDisk
+	if !cfg.SkipExport {
+		osDiskName, err := provider.GetComputeOSDiskName(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get OS disk name: %w", err)
+		}
+		disks = append(disks, liveDisk{name: osDiskName, dir: osExportDir})
+	}
+	dataDiskNames, err := provider.GetComputeDataDiskNames(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data disk names: %w", err)
+	}
+	for _, name := range dataDiskNames {
+		disks = append(disks, liveDisk{name: name, dir: dataExportDir})
+	}
+	for _, d := range disks {
+		if err := common.EnsureDir(d.dir); err != nil {
+			return nil, fmt.Errorf("failed to create export directory: %w", err)
+		}
+	}
+	return disks, nil
+}
+
+// sync runs the two passes of the live sync over disks.
+func (l *liveSync) sync(ctx context.Context, log *logger.Logger, provider *azure.Provider, cfg *config.Config, power *sourcePower, disks []liveDisk) error {
+	l.result = &LiveSyncResult{}
+	for _, d := range disks {
+		l.result.Disks = append(l.result.Disks, d.name)
+	}
+	log.Info(i18n.T("livesync.first_pass", len(disks)))
+	start := time.Now()
+	files := make([]string, len(disks))
+	err := forEachDisk(disks, cfg.DataDiskParallelism, func(i int, d liveDisk) (err error) {
+		files[i], err = provider.ExportAzureDiskBase(ctx, d.name, cfg.AzureResourceGroup, d.dir)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("live sync first pass failed: %w", err)
+	}
+	l.result.FirstPassSeconds = time.Since(start).Seconds()
+	log.Success(i18n.T("livesync.first_pass_done", len(disks), time.Since(start).Round(time.Second)))
+
+	if cfg.StopsSourceVM() {
+		if err := power.deallocate(ctx, log, provider, cfg); err != nil {
+			return fmt.Errorf("live sync: %w", err)
+		}
+	} else {
+		timeout := time.Duration(cfg.LiveSyncStopTimeoutMinutes) * time.Minute
+		log.Warning(i18n.T("livesync.stop_vm", cfg.AzureComputeName, timeout))
+		if err := provider.WaitForComputeStopped(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName, timeout); err != nil {
+			return fmt.Errorf("live sync: %w", err)
+		}
+	}
+	log.Success(i18n.T("livesync.stopped"))
+	start = time.Now()
+	written := make([]int64, len(disks))
+	err = forEachDisk(disks, cfg.DataDiskParallelism, func(i int, d liveDisk) (err error) {
+		written[i], err = provider.SyncAzureDiskDelta(ctx, d.name, cfg.AzureResourceGroup, d.dir)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("live sync final pass failed: %w", err)
+	}
+	l.result.StopWindowSeconds = time.Since(start).Seconds()
+	for _, n := range written {
+		l.result.DeltaBytes += n
+	}
+	l.files = make(map[string]string, len(disks))
+	for i, d := range disks {
+		l.files[d.name] = files[i]
+	}
+	log.Success(i18n.T("livesync.final_pass_done", time.Since(start).Round(time.Second), l.result.DeltaBytes>>20))
+	return nil
+}
+
+// file returns the VHD a live sync exported a disk to, if it did.
+func (l *liveSync) file(diskName string) (string, bool) {
+	f, ok := l.files[diskName]
+	return f, ok
+}
+
+// forEachDisk runs fn for each disk, at most parallelism at a time, and returns the
+// errors of all disks.
+func forEachDisk(disks []liveDisk, parallelism int, fn func(i int, d liveDisk) error) error {
+	errs := make([]error, len(disks))
+	sem := make(chan struct{}, max(parallelism, 1))
+	var wg sync.WaitGroup
+	for i, d := range disks {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fn(i, d); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", d.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
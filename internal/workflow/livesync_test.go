@@ -0,0 +1,50 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func TestLiveSyncDisabled(t *testing.T) {
+	var l liveSync
+	if err := l.run(context.Background(), nil, nil, &config.Config{}, nil, "os", "data"); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if _, ok := l.file("osdisk"); ok {
+		t.Error("file() found a disk without live sync")
+	}
+	if l.result != nil {
+		t.Error("result set without live sync")
+	}
+}
+
+func TestForEachDisk(t *testing.T) {
+	disks := []liveDisk{{name: "os", dir: "a"}, {name: "data1", dir: "b"}, {name: "data2", dir: "b"}}
+	var running, peak atomic.Int32
+	seen := make([]string, len(disks))
+	err := forEachDisk(disks, 2, func(i int, d liveDisk) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		seen[i] = d.name
+		if d.name == "data2" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "data2: boom") {
+		t.Errorf("forEachDisk() error = %v, want data2: boom", err)
+	}
+	if strings.Join(seen, ",") != "os,data1,data2" {
+		t.Errorf("forEachDisk() visited %v", seen)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("forEachDisk() ran %d disks at once, want at most 2", peak.Load())
+	}
+}
@@ -0,0 +1,38 @@
+// Package workflow provides the LUKS key settings used to configure encrypted images.
+package workflow
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+// luksKeySelector returns the libguestfs key selector that opens the LUKS containers
+// of the image with LUKS_PASSPHRASE or LUKS_KEY_FILE, or "" when neither is set. The
+// passphrase is written to a temporary file so that it does not appear in process
+// listings; cleanup removes it.
+func luksKeySelector(cfg *config.Config) (selector string, cleanup func(), err error) {
+	cleanup = func() {}
+	switch {
+	case cfg.LUKSKeyFile != "":
+		return fmt.Sprintf("%s:file:%s", cfg.LUKSDevice, cfg.LUKSKeyFile), cleanup, nil
+	case cfg.LUKSPassphrase == "":
+		return "", cleanup, nil
+	}
+	f, err := os.CreateTemp("", "kopru-luks-")
+	if err != nil {
+		return "", cleanup, fmt.Errorf("failed to create LUKS key file: %w", err)
+	}
+	cleanup = func() { _ = os.Remove(f.Name()) }
+	// libguestfs uses the whole file content as the key, so no newline is written.
+	_, err = f.WriteString(cfg.LUKSPassphrase)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", func() {}, fmt.Errorf("failed to write LUKS key file: %w", err)
+	}
+	return fmt.Sprintf("%s:file:%s", cfg.LUKSDevice, f.Name()), cleanup, nil
+}
@@ -0,0 +1,39 @@
+package workflow
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func TestLUKSKeySelector(t *testing.T) {
+	selector, cleanup, err := luksKeySelector(&config.Config{LUKSDevice: "all"})
+	cleanup()
+	if err != nil || selector != "" {
+		t.Errorf("Expected no selector without key, got %q, %v", selector, err)
+	}
+
+	selector, cleanup, err = luksKeySelector(&config.Config{LUKSDevice: "/dev/sda2", LUKSKeyFile: "/etc/kopru/luks.key"})
+	cleanup()
+	if err != nil || selector != "/dev/sda2:file:/etc/kopru/luks.key" {
+		t.Errorf("luksKeySelector() = %q, %v", selector, err)
+	}
+
+	selector, cleanup, err = luksKeySelector(&config.Config{LUKSDevice: "all", LUKSPassphrase: "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile, found := strings.CutPrefix(selector, "all:file:")
+	if !found {
+		t.Fatalf("Unexpected selector %q", selector)
+	}
+	if data, err := os.ReadFile(keyFile); err != nil || string(data) != "s3cret" {
+		t.Errorf("Key file content = %q, %v", data, err)
+	}
+	cleanup()
+	if _, err := os.Stat(keyFile); !os.IsNotExist(err) {
+		t.Errorf("Expected key file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Package workflow provides the reconstruction of the network interfaces of Azure VMs as OCI VNICs and private IPs.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/template"
+)
+
+// sourceNetwork reads the network interfaces of the source VM and returns the VNICs and
+// private IPs that reconstruct them, when OCI_SUBNET_MAP_FILE or OCI_PRESERVE_PRIVATE_IPS
+// is set.
+func sourceNetwork(ctx context.Context, log *logger.Logger, provider *azure.Provider, cfg *config.Config) (template.Network, error) {
+	if cfg.OCISubnetMapFile == "" && !cfg.OCIPreservePrivateIPs {
+		return template.Network{}, nil
+	}
+	nics, err := provider.GetComputeNetworkInterfaces(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+	if err != nil {
+		return template.Network{}, fmt.Errorf("failed to read network interfaces: %w", err)
+	}
+	subnets, err := cfg.SubnetMap()
+	if err != nil {
+		return template.Network{}, err
+	}
+	network, err := networkFromInterfaces(log, nics, subnets, cfg.OCISubnetMapFile != "")
+	if err != nil {
+		return template.Network{}, err
+	}
+	log.Successf("✓ Network: %d network interface(s), %d secondary VNIC(s) and %d secondary private IP(s) reconstructed",
+		len(nics), len(network.VNICs), secondaryIPCount(network))
+	return network, nil
+}
+
+// networkFromInterfaces maps the network interfaces of the source VM, the primary one
+// first, to OCI. The primary IP configuration of the primary interface is the primary
+// VNIC in OCI_SUBNET_ID, and each secondary interface becomes a secondary VNIC in the
+// OCI subnet that subnets maps its Azure subnet to. The other IP configurations of an
+// interface become secondary private IPs of its VNIC when they are in the same subnet,
+// as OCI private IPs belong to the subnet of their VNIC. With requireMapped, secondary
+// interfaces whose subnet is not mapped are an error; otherwise they are left out.
+func networkFromInterfaces(log *logger.Logger, nics []azure.NetworkInterface, subnets map[string]string, requireMapped bool) (template.Network, error) {
+	var network template.Network
+	var unmapped []string
+	for i, nic := range nics {
+		if len(nic.IPConfigurations) == 0 {
+			continue
+		}
+		primary := nic.IPConfigurations[0]
+		secondaryIPs := secondaryPrivateIPs(log, nic)
+		if i == 0 {
+			network.PrimaryPrivateIP = primary.PrivateIP
+			network.SecondaryPrivateIPs = secondaryIPs
+			continue
+		}
+		subnetID, ok := config.MapSubnet(subnets, primary.SubnetID)
+		if !ok {
+			if requireMapped {
+				unmapped = append(unmapped, config.AzureSubnetName(primary.SubnetID))
+			} else {
+				log.Warningf("Network interface %s is not reconstructed, set OCI_SUBNET_MAP_FILE to map its subnet %s", nic.Name, config.AzureSubnetName(primary.SubnetID))
+			}
+			continue
+		}
+		network.VNICs = append(network.VNICs, template.VNIC{
+			Name: nic.Name, SubnetID: subnetID, PrivateIP: primary.PrivateIP, SecondaryPrivateIPs: secondaryIPs,
+		})
+	}
+	if len(unmapped) > 0 {
+		return template.Network{}, fmt.Errorf("OCI_SUBNET_MAP_FILE does not map the subnet(s) of the secondary network interfaces: %s", strings.Join(unmapped, ", "))
+	}
+	return network, nil
+}
+
+// secondaryPrivateIPs returns the private IPs of the secondary IP configurations of nic
+// that are in the subnet of its primary IP configuration.
+func secondaryPrivateIPs(log *logger.Logger, nic azure.NetworkInterface) []string {
+	var ips []string
+	subnet := strings.ToLower(nic.IPConfigurations[0].SubnetID)
+	for _, c := range nic.IPConfigurations[1:] {
+		if strings.ToLower(c.SubnetID) != subnet {
+			log.Warningf("IP configuration %s of network interface %s is in another subnet than the interface's primary IP configuration, which OCI does not support; it is not reconstructed", c.Name, nic.Name)
+			continue
+		}
+		ips = append(ips, c.PrivateIP)
+	}
+	return ips
+}
+
+// secondaryIPCount returns the number of secondary private IPs of network.
+func secondaryIPCount(network template.Network) int {
+	n := len(network.SecondaryPrivateIPs)
+	for _, vnic := range network.VNICs {
+		n += len(vnic.SecondaryPrivateIPs)
+	}
+	return n
+}
@@ -0,0 +1,47 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestNetworkFromInterfaces(t *testing.T) {
+	const prefix = "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/hub/subnets/"
+	nics := []azure.NetworkInterface{
+		{Name: "web-nic", Primary: true, IPConfigurations: []azure.IPConfiguration{
+			{Name: "ipconfig1", PrivateIP: "10.0.1.4", SubnetID: prefix + "web", Primary: true},
+			{Name: "ipconfig2", PrivateIP: "10.0.1.5", SubnetID: prefix + "web"},
+			{Name: "other-subnet", PrivateIP: "10.0.3.5", SubnetID: prefix + "mgmt"},
+		}},
+		{Name: "db-nic", IPConfigurations: []azure.IPConfiguration{
+			{Name: "ipconfig1", PrivateIP: "10.0.2.4", SubnetID: prefix + "db", Primary: true},
+			{Name: "ipconfig2", PrivateIP: "10.0.2.5", SubnetID: prefix + "db"},
+		}},
+	}
+	subnets := map[string]string{"hub/db": "ocid1.subnet.oc1.iad.db"}
+
+	network, err := networkFromInterfaces(logger.New(false), nics, subnets, true)
+	if err != nil {
+		t.Fatalf("networkFromInterfaces() error = %v", err)
+	}
+	if network.PrimaryPrivateIP != "10.0.1.4" || strings.Join(network.SecondaryPrivateIPs, ",") != "10.0.1.5" {
+		t.Errorf("Primary VNIC = %s with %v, want 10.0.1.4 with [10.0.1.5]", network.PrimaryPrivateIP, network.SecondaryPrivateIPs)
+	}
+	if len(network.VNICs) != 1 {
+		t.Fatalf("Expected 1 secondary VNIC, got %+v", network.VNICs)
+	}
+	if vnic := network.VNICs[0]; vnic.Name != "db-nic" || vnic.SubnetID != "ocid1.subnet.oc1.iad.db" || vnic.PrivateIP != "10.0.2.4" || strings.Join(vnic.SecondaryPrivateIPs, ",") != "10.0.2.5" {
+		t.Errorf("Secondary VNIC = %+v", vnic)
+	}
+
+	if _, err := networkFromInterfaces(logger.New(false), nics, nil, true); err == nil || !strings.Contains(err.Error(), "hub/db") {
+		t.Errorf("networkFromInterfaces() with an unmapped subnet error = %v", err)
+	}
+	network, err = networkFromInterfaces(logger.New(false), nics, nil, false)
+	if err != nil || len(network.VNICs) != 0 || network.PrimaryPrivateIP != "10.0.1.4" {
+		t.Errorf("networkFromInterfaces() without a subnet map = %+v, %v", network, err)
+	}
+}
@@ -0,0 +1,90 @@
+// Package workflow provides the webhook notifications of step completions and run outcomes.
+package workflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/notify"
+)
+
+// runNotifier posts the events of a run to the configured webhook.
+type runNotifier struct {
+	notifier     *notify.Notifier
+	log          *logger.Logger
+	runID        string
+	workflow     string
+	vm           string
+	failuresOnly bool
+}
+
+// newRunNotifier returns the notifier of a run, or nil when no webhook is configured.
+func newRunNotifier(cfg *config.Config, log *logger.Logger, workflowName, runID string) *runNotifier {
+	if cfg.NotifyWebhookURL == "" {
+		return nil
+	}
+	notifier, err := notify.New(cfg.NotifyWebhookURL, cfg.NotifyWebhookFormat)
+	if err != nil {
+		log.Warningf("%v", err)
+		return nil
+	}
+	vm := cfg.OCIInstanceName
+	if cfg.SourcePlatform == "azure" {
+		vm = cfg.AzureComputeName
+	}
+	return &runNotifier{
+		notifier:     notifier,
+		log:          log,
+		runID:        runID,
+		workflow:     workflowName,
+		vm:           vm,
+		failuresOnly: cfg.NotifyEvents == "failures",
+	}
+}
+
+// send posts e unless only failures are notified and e is not one. Notifications are
+// sent even when the run was interrupted; failures are logged as warnings and never
+// fail the migration.
+func (n *runNotifier) send(ctx context.Context, e notify.Event) {
+	if n == nil || (n.failuresOnly && !e.Failed()) {
+		return
+	}
+	e.RunID, e.Workflow, e.VM, e.Time = n.runID, n.workflow, n.vm, time.Now().UTC()
+	if err := n.notifier.Send(context.WithoutCancel(ctx), e); err != nil {
+		n.log.Warningf("%v", err)
+	}
+}
+
+// started notifies the start of the run.
+func (n *runNotifier) started(ctx context.Context) {
+	n.send(ctx, notify.Event{Type: notify.EventRunStarted})
+}
+
+// stepCompleted notifies the outcome of a step that was run.
+func (n *runNotifier) stepCompleted(ctx context.Context, step, status string, duration time.Duration, err error) {
+	e := notify.Event{Type: notify.EventStepDone, Step: step, Status: status, DurationSeconds: duration.Seconds()}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	n.send(ctx, e)
+}
+
+// finished notifies the outcome of the run.
+func (n *runNotifier) finished(ctx context.Context, s *RunSummary) {
+	n.send(ctx, notify.Event{Type: notify.EventRunFinished, Status: s.Status, Error: s.Error, DurationSeconds: s.DurationSeconds})
+}
+
+type notifierKey struct{}
+
+// withNotifier returns a context through which runSteps notifies step completions to n.
+func withNotifier(ctx context.Context, n *runNotifier) context.Context {
+	return context.WithValue(ctx, notifierKey{}, n)
+}
+
+// notifierFromContext returns the notifier of the run, or nil when there is none.
+func notifierFromContext(ctx context.Context) *runNotifier {
+	n, _ := ctx.Value(notifierKey{}).(*runNotifier)
+	return n
+}
@@ -0,0 +1,78 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/notify"
+)
+
+func TestRunNotifier(t *testing.T) {
+	var mu sync.Mutex
+	var events []notify.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e notify.Event
+		_ = json.NewDecoder(r.Body).Decode(&e)
+		mu.Lock()
+		events = append(events, e)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	steps := []Step{
+		{Name: "Export OS disk", Fn: func(context.Context) error { return nil }},
+		{Name: "Convert", ErrMsg: "conversion failed", Fn: func(context.Context) error { return errors.New("qemu-img failed") }},
+	}
+	tests := []struct {
+		name   string
+		events string
+		want   []string
+	}{
+		{"all", "all", []string{"run_started", "Export OS disk", "Convert"}},
+		{"failures", "failures", []string{"Convert"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events = nil
+			cfg := &config.Config{SourcePlatform: "azure", AzureComputeName: "web-01", NotifyWebhookURL: server.URL, NotifyWebhookFormat: "json", NotifyEvents: tt.events}
+			n := newRunNotifier(cfg, logger.New(false), "azure-to-oci", "a1b2c3")
+			n.started(context.Background())
+			if err := runSteps(withNotifier(context.Background(), n), logger.New(false), steps); err == nil {
+				t.Fatal("Expected the failing step to fail the run")
+			}
+			var got []string
+			for _, e := range events {
+				if e.RunID != "a1b2c3" || e.VM != "web-01" || e.Workflow != "azure-to-oci" {
+					t.Errorf("Unexpected event: %+v", e)
+				}
+				if e.Step != "" {
+					got = append(got, e.Step)
+				} else {
+					got = append(got, e.Type)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Notified %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Notified %q, want %q", got, tt.want)
+				}
+			}
+			if last := events[len(events)-1]; last.Status != StatusFailed || last.Error != "qemu-img failed" {
+				t.Errorf("Unexpected failure event: %+v", last)
+			}
+		})
+	}
+
+	if n := newRunNotifier(&config.Config{}, logger.New(false), "azure-to-oci", "a1b2c3"); n != nil {
+		t.Error("Expected no notifier without a webhook")
+	}
+}
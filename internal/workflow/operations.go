@@ -0,0 +1,29 @@
+// Package workflow provides the report of the cloud API operations a run invoked.
+package workflow
+
+import (
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
+)
+
+// apiOperationClouds names the clouds in the order they are reported.
+var apiOperationClouds = []struct{ key, name string }{
+	{telemetry.CloudAzure, "Azure"},
+	{telemetry.CloudOCI, "OCI"},
+}
+
+// reportAPIOperations logs the Azure RBAC operations and OCI API operations the run
+// invoked, so that custom roles and IAM policies granting no more than them can be
+// derived for the identity that runs Kopru. They are also recorded in the run summary.
+func reportAPIOperations(log *logger.Logger, operations map[string][]string) {
+	if len(operations) == 0 {
+		return
+	}
+	log.Info(i18n.T("summary.api_operations"))
+	for _, cloud := range apiOperationClouds {
+		for _, operation := range operations[cloud.key] {
+			log.Infof("  %s: %s", cloud.name, operation)
+		}
+	}
+}
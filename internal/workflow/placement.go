@@ -0,0 +1,83 @@
+// Package workflow provides the placement of the restored data volumes in an availability domain.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/template"
+)
+
+// availabilityDomainName resolves an availability domain number to the name of the
+// availability domain of the region.
+func availabilityDomainName(ctx context.Context, provider *oci.Provider, cfg *config.Config, number string) (string, error) {
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 {
+		return "", fmt.Errorf("invalid availability domain number '%s'", number)
+	}
+	domains, err := provider.ListAvailabilityDomains(ctx, cfg.OCICompartmentID)
+	if err != nil {
+		return "", err
+	}
+	if n > len(domains) || domains[n-1].Name == nil {
+		return "", fmt.Errorf("availability domain %d not found in region %s", n, cfg.OCIRegion)
+	}
+	return *domains[n-1].Name, nil
+}
+
+// dataVolumeAvailabilityDomain returns the availability domain the data volumes are
+// placed in: that of OCI_DATA_VOLUME_AVAILABILITY_DOMAIN, or localAvailabilityDomain, the
+// availability domain of the local instance that copies the data disks.
+func dataVolumeAvailabilityDomain(ctx context.Context, provider *oci.Provider, cfg *config.Config, localAvailabilityDomain string) (string, error) {
+	if cfg.OCIDataVolumeAD == "" {
+		return localAvailabilityDomain, nil
+	}
+	ad, err := availabilityDomainName(ctx, provider, cfg, cfg.OCIDataVolumeAD)
+	if err != nil {
+		return "", fmt.Errorf("OCI_DATA_VOLUME_AVAILABILITY_DOMAIN: %w", err)
+	}
+	return ad, nil
+}
+
+// dataVolumePlacementProblem returns why the data volumes of
+// OCI_DATA_VOLUME_AVAILABILITY_DOMAIN cannot be attached to the instance of the template,
+// or "". Volumes can only be attached to instances in their availability domain.
+func dataVolumePlacementProblem(cfg *config.Config) string {
+	if cfg.OCIDataVolumeAD == "" || cfg.AttachesToInstance() {
+		return ""
+	}
+	instanceAD := cfg.OCIAvailabilityDomain
+	if instanceAD == "" {
+		instanceAD = template.DefaultAvailabilityDomain
+	}
+	if cfg.OCIDataVolumeAD == instanceAD {
+		return ""
+	}
+	return fmt.Sprintf("The data volumes are placed in availability domain %s, but the instance is deployed to availability domain %s; the template cannot attach them unless OCI_AVAILABILITY_DOMAIN is %s", cfg.OCIDataVolumeAD, instanceAD, cfg.OCIDataVolumeAD)
+}
+
+// moveDataVolume moves the data volume volumeID of the data disk diskName to
+// availabilityDomain, by restoring a full backup of it there, and deletes the volume.
+// The backup is kept, as the new volume is hydrated from it in the background. It
+// returns the OCID of the new volume.
+func (h *AzureToOCIHandler) moveDataVolume(ctx context.Context, volumeID, volumeName, diskName, availabilityDomain string) (string, error) {
+	h.logger.Infof("[%s] Backing up volume '%s' to move it to %s...", diskName, volumeName, availabilityDomain)
+	backupID, err := h.ociProvider.CreateVolumeSnapshot(ctx, volumeID, volumeName+"-move")
+	if err != nil {
+		return "", err
+	}
+	h.logger.Infof("[%s] Restoring backup %s in %s...", diskName, backupID, availabilityDomain)
+	vpusPerGB := h.config.DataVolumeVPUsPerGB(diskName)
+	movedID, err := h.ociProvider.CreateBlockVolumeFromBackup(ctx, h.config.OCICompartmentID, availabilityDomain, volumeName, backupID, int64(vpusPerGB), int64(h.config.OCIVolumeAutotuneMaxVPUs), h.sourceTags.dataVolume(diskName))
+	if err != nil {
+		return "", err
+	}
+	if err := h.ociProvider.DeleteVolume(ctx, volumeID); err != nil {
+		h.logger.Warningf("[%s] Failed to delete volume %s, delete it manually: %v", diskName, volumeID, err)
+	}
+	h.logger.Successf("[%s] Volume moved to %s: %s (backup %s can be deleted once the volume is hydrated)", diskName, availabilityDomain, movedID, backupID)
+	return movedID, nil
+}
@@ -0,0 +1,33 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func TestDataVolumePlacementProblem(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr string
+	}{
+		{"default placement", config.Config{}, ""},
+		{"instance availability domain", config.Config{OCIDataVolumeAD: "2", OCIAvailabilityDomain: "2"}, ""},
+		{"default instance availability domain", config.Config{OCIDataVolumeAD: "1"}, ""},
+		{"other availability domain", config.Config{OCIDataVolumeAD: "3", OCIAvailabilityDomain: "2"}, "availability domain 2"},
+		{"attached to an instance", config.Config{OCIDataVolumeAD: "3", OCITargetInstanceID: "ocid1.instance.oc1.iad.app"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := dataVolumePlacementProblem(&tt.cfg)
+			if tt.wantErr == "" && problem != "" {
+				t.Errorf("dataVolumePlacementProblem() = %q, want none", problem)
+			}
+			if tt.wantErr != "" && !strings.Contains(problem, tt.wantErr) {
+				t.Errorf("dataVolumePlacementProblem() = %q, want %q", problem, tt.wantErr)
+			}
+		})
+	}
+}
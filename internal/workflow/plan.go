@@ -0,0 +1,146 @@
+// Package workflow provides rendering of workflow plans.
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Supported plan graph formats.
+const (
+	GraphFormatDOT     = "dot"
+	GraphFormatMermaid = "mermaid"
+)
+
+// RenderPlan writes a numbered, human readable list of the workflow steps.
+func RenderPlan(w io.Writer, name string, steps []Step) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Workflow: %s\n", name)
+	for i, step := range steps {
+		status := "run"
+		if step.Skip {
+			status = "skip"
+		}
+		fmt.Fprintf(&b, "%2d. [%s] %s\n", i+1, status, step.Name)
+		if len(step.Inputs) > 0 {
+			fmt.Fprintf(&b, "      inputs:  %s\n", strings.Join(step.Inputs, ", "))
+		}
+		if len(step.Outputs) > 0 {
+			fmt.Fprintf(&b, "      outputs: %s\n", strings.Join(step.Outputs, ", "))
+		}
+		if step.Timeout > 0 {
+			fmt.Fprintf(&b, "      timeout: %s\n", step.Timeout)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RenderGraph writes the workflow steps, skip states and artifact dependencies
+// as a graph in the given format (dot or mermaid).
+func RenderGraph(w io.Writer, name string, steps []Step, format string) error {
+	var content string
+	switch strings.ToLower(format) {
+	case GraphFormatDOT:
+		content = renderDOT(name, steps)
+	case GraphFormatMermaid:
+		content = renderMermaid(steps)
+	default:
+		return fmt.Errorf("unsupported graph format '%s' (supported: %s, %s)", format, GraphFormatDOT, GraphFormatMermaid)
+	}
+	_, err := io.WriteString(w, content)
+	return err
+}
+
+// planArtifacts returns the artifacts referenced by the steps in order of first appearance.
+func planArtifacts(steps []Step) []string {
+	seen := make(map[string]struct{})
+	var artifacts []string
+	for _, step := range steps {
+		for _, a := range append(append([]string{}, step.Outputs...), step.Inputs...) {
+			if _, ok := seen[a]; !ok {
+				seen[a] = struct{}{}
+				artifacts = append(artifacts, a)
+			}
+		}
+	}
+	return artifacts
+}
+
+// graphID converts an artifact name into an identifier usable in DOT and Mermaid.
+func graphID(prefix, name string) string {
+	return prefix + strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func stepLabel(i int, step Step) string {
+	label := fmt.Sprintf("%d. %s", i+1, step.Name)
+	if step.Skip {
+		label += " (skipped)"
+	}
+	return label
+}
+
+func renderDOT(name string, steps []Step) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", name)
+	b.WriteString("  rankdir=TB;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+	for i, step := range steps {
+		style := ""
+		if step.Skip {
+			style = ", style=\"rounded,dashed\", fontcolor=gray"
+		}
+		fmt.Fprintf(&b, "  step%d [label=%q%s];\n", i+1, stepLabel(i, step), style)
+	}
+	for _, a := range planArtifacts(steps) {
+		fmt.Fprintf(&b, "  %s [label=%q, shape=note];\n", graphID("artifact_", a), a)
+	}
+	for i := 1; i < len(steps); i++ {
+		fmt.Fprintf(&b, "  step%d -> step%d [style=dotted, arrowhead=none];\n", i, i+1)
+	}
+	for i, step := range steps {
+		for _, a := range step.Inputs {
+			fmt.Fprintf(&b, "  %s -> step%d;\n", graphID("artifact_", a), i+1)
+		}
+		for _, a := range step.Outputs {
+			fmt.Fprintf(&b, "  step%d -> %s;\n", i+1, graphID("artifact_", a))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func renderMermaid(steps []Step) string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for i, step := range steps {
+		fmt.Fprintf(&b, "  step%d[\"%s\"]\n", i+1, stepLabel(i, step))
+	}
+	for _, a := range planArtifacts(steps) {
+		fmt.Fprintf(&b, "  %s[(\"%s\")]\n", graphID("artifact_", a), a)
+	}
+	for i := 1; i < len(steps); i++ {
+		fmt.Fprintf(&b, "  step%d -.- step%d\n", i, i+1)
+	}
+	for i, step := range steps {
+		for _, a := range step.Inputs {
+			fmt.Fprintf(&b, "  %s --> step%d\n", graphID("artifact_", a), i+1)
+		}
+		for _, a := range step.Outputs {
+			fmt.Fprintf(&b, "  step%d --> %s\n", i+1, graphID("artifact_", a))
+		}
+	}
+	b.WriteString("  classDef skipped stroke-dasharray: 5 5,color:#888;\n")
+	for i, step := range steps {
+		if step.Skip {
+			fmt.Fprintf(&b, "  class step%d skipped\n", i+1)
+		}
+	}
+	return b.String()
+}
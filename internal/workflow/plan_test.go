@@ -0,0 +1,98 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func testPlanSteps() []Step {
+	return []Step{
+		{Name: "Export OS disk", Skip: true, Outputs: []string{ArtifactOSDiskVHD}},
+		{Name: "Convert VHD to QCOW2", Inputs: []string{ArtifactOSDiskVHD}, Outputs: []string{ArtifactOSImageQCOW2}},
+	}
+}
+
+func TestRenderGraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		expected []string
+	}{
+		{"dot", GraphFormatDOT, []string{
+			"digraph \"test\" {",
+			"step1 [label=\"1. Export OS disk (skipped)\", style=\"rounded,dashed\"",
+			"step1 -> artifact_os_disk_vhd;",
+			"artifact_os_disk_vhd -> step2;",
+		}},
+		{"mermaid", GraphFormatMermaid, []string{
+			"flowchart TD",
+			"step2[\"2. Convert VHD to QCOW2\"]",
+			"artifact_os_disk_vhd --> step2",
+			"class step1 skipped",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := RenderGraph(&buf, "test", testPlanSteps(), tt.format); err != nil {
+				t.Fatalf("RenderGraph failed: %v", err)
+			}
+			for _, want := range tt.expected {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("expected output to contain %q, got:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRenderGraphUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderGraph(&buf, "test", testPlanSteps(), "svg"); err == nil {
+		t.Error("Expected error for unsupported format but got nil")
+	}
+}
+
+func TestRenderPlan(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderPlan(&buf, "test", testPlanSteps()); err != nil {
+		t.Fatalf("RenderPlan failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "1. [skip] Export OS disk") || !strings.Contains(out, "2. [run] Convert VHD to QCOW2") {
+		t.Errorf("unexpected plan output:\n%s", out)
+	}
+}
+
+func TestNewManagerOfflineWithoutCredentials(t *testing.T) {
+	// No OCI config file and no Azure credentials are available.
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("OCI_CLI_CONFIG_FILE", "")
+	for _, name := range []string{"AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET", "AZURE_TENANT_ID"} {
+		t.Setenv(name, "")
+	}
+	cfg := &config.Config{SourcePlatform: "azure", TargetPlatform: "oci", AzureComputeName: "web-01", OCIRegion: "eu-frankfurt-1"}
+	mgr, err := NewManagerOffline(cfg, logger.New(false), "test")
+	if err != nil {
+		t.Fatalf("NewManagerOffline() error = %v", err)
+	}
+	if h := mgr.handler.(*AzureToOCIHandler); h.azureProvider != nil || h.ociProvider != nil {
+		t.Error("NewManagerOffline() created the cloud providers")
+	}
+	var buf bytes.Buffer
+	if err := RenderGraph(&buf, mgr.WorkflowName(), mgr.Steps(), GraphFormatMermaid); err != nil {
+		t.Fatalf("RenderGraph() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "Export OS disk") || !strings.Contains(buf.String(), "Deploy template") {
+		t.Errorf("plan without credentials = %s", buf.String())
+	}
+	if err := mgr.Run(context.Background()); err == nil {
+		t.Error("Run() of an offline manager succeeded")
+	}
+}
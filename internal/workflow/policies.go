@@ -0,0 +1,50 @@
+// Package workflow provides the OCI IAM policy preflight check shared by workflow handlers.
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// checkOCIPolicies verifies that the current OCI principal may manage the given IAM
+// resource types in the compartment. Read access is probed first; once it is confirmed,
+// the policy statements are evaluated for manage access, which a read-only probe cannot
+// prove. Missing permissions are reported together with the policy statements that
+// grant them, before any resource has been created.
+func checkOCIPolicies(ctx context.Context, log *logger.Logger, provider *oci.Provider, compartmentID, namespace string, resources []string) error {
+	missing, err := provider.CheckPolicies(ctx, compartmentID, namespace, resources)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		if missing, err = provider.SimulatePolicies(ctx, compartmentID, resources); err != nil {
+			log.Warningf("Could not evaluate OCI IAM policy statements, only read access was verified: %v", err)
+			missing = nil
+		}
+	}
+	if len(missing) == 0 {
+		log.Success("✓ OCI IAM policies allow managing the required resources")
+		return nil
+	}
+	log.Error("OCI IAM policies are missing for the target compartment. Ask your tenancy administrator to add:")
+	for _, m := range missing {
+		log.Errorf("  %s", m.Statement)
+		log.Debugf("%s check failed: %v", m.Resource, m.Err)
+	}
+	return fmt.Errorf("missing OCI IAM policies for %d resource type(s) in compartment %s", len(missing), compartmentID)
+}
+
+// ociPolicyResources returns the IAM resource types a workflow manages in OCI.
+func ociPolicyResources(withDataVolumes, withInstance bool) []string {
+	resources := []string{oci.PolicyObjectFamily, oci.PolicyInstanceImages}
+	if withDataVolumes {
+		resources = append(resources, oci.PolicyVolumeFamily)
+	}
+	if withInstance {
+		resources = append(resources, oci.PolicyInstanceFamily)
+	}
+	return resources
+}
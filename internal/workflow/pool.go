@@ -0,0 +1,58 @@
+// Package workflow provides the OCI instance pool of the instances migrated from the VMs of an Azure availability set or VM scale set.
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/template"
+)
+
+// instancePool returns the instance pool of the instances of the succeeded migrations of
+// a batch, named after the group of AZURE_COMPUTE_GROUP.
+func instancePool(cfg *config.Config, results []BatchResult) template.InstancePool {
+	_, groupName, _ := cfg.ComputeGroup()
+	pool := template.InstancePool{Name: common.SanitizeName(groupName) + "-pool"}
+	for _, r := range results {
+		if r.Status == StatusSucceeded && r.InstanceID != "" {
+			pool.InstanceIDs = append(pool.InstanceIDs, r.InstanceID)
+		}
+	}
+	return pool
+}
+
+// deployInstancePool generates the template of the instance pool of the instances
+// migrated by a batch, to ./<group>-pool-template-output, and deploys it.
+func deployInstancePool(ctx context.Context, cfg *config.Config, log *logger.Logger, results []BatchResult) error {
+	pool := instancePool(cfg, results)
+	if len(pool.InstanceIDs) == 0 {
+		log.Warning("No instance was migrated, the instance pool is not created")
+		return nil
+	}
+	if len(pool.InstanceIDs) < len(results) {
+		log.Warningf("The instance pool %s is created with the %d instance(s) of the %d VM(s) that were migrated", pool.Name, len(pool.InstanceIDs), len(results))
+	}
+	provider, err := oci.NewProvider(cfg.OCIRegion, log)
+	if err != nil {
+		return fmt.Errorf("failed to initialize OCI provider: %w", err)
+	}
+	poolCfg := *cfg
+	poolCfg.OCIInstanceName = pool.Name
+	tfGen := template.NewOCIGenerator(&poolCfg, log, "", nil, nil, 0, 0, 0, "", fmt.Sprintf("./%s-template-output", pool.Name))
+	tfGen.SetSessionProfile(provider.SessionProfile())
+	tfGen.SetInstancePool(pool)
+	tfGen.SetApplyConfirmation(func(planSummary string) error {
+		return confirmApply(&poolCfg, log, planSummary)
+	})
+	if err := setBackendNamespace(ctx, &poolCfg, provider, tfGen); err != nil {
+		return err
+	}
+	if err := tfGen.GenerateTemplate(); err != nil {
+		return err
+	}
+	return tfGen.DeployTemplate()
+}
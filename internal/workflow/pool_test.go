@@ -0,0 +1,25 @@
+package workflow
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func TestInstancePool(t *testing.T) {
+	cfg := &config.Config{AzureComputeGroup: "availabilitySets/Web_AvSet"}
+	results := []BatchResult{
+		{VM: "web-01", Status: StatusSucceeded, InstanceID: "ocid1.instance.oc1.test.web-01"},
+		{VM: "web-02", Status: StatusFailed, InstanceID: "ocid1.instance.oc1.test.web-02"},
+		{VM: "web-03", Status: StatusSucceeded},
+		{VM: "web-04", Status: StatusSucceeded, InstanceID: "ocid1.instance.oc1.test.web-04"},
+	}
+	pool := instancePool(cfg, results)
+	if pool.Name != "web_avset-pool" {
+		t.Errorf("Expected pool name web_avset-pool, got %q", pool.Name)
+	}
+	if want := []string{"ocid1.instance.oc1.test.web-01", "ocid1.instance.oc1.test.web-04"}; !slices.Equal(pool.InstanceIDs, want) {
+		t.Errorf("Expected instances %v, got %v", want, pool.InstanceIDs)
+	}
+}
@@ -0,0 +1,124 @@
+// Package workflow provides the deallocation of the source VM before its disks are exported, and its restart afterwards.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// sourcePower deallocates the running source VM once, before its disks are exported,
+// whichever export step runs first, and starts it again with AZURE_RESTART_VM once all
+// the export steps are done, or when the run fails first.
+type sourcePower struct {
+	once     sync.Once
+	err      error
+	mu       sync.Mutex // Guards exported and restart
+	exported int        // Export steps done
+	restart  func()     // Starts the VM again, if Kopru deallocated it
+}
+
+// stop deallocates the VM before the first disk is exported when AZURE_STOP_VM or
+// AZURE_FORCE_STOP is set. With LIVE_SYNC, the live sync deallocates it after its first
+// pass instead.
+func (s *sourcePower) stop(ctx context.Context, log *logger.Logger, provider *azure.Provider, cfg *config.Config) error {
+	if cfg.LiveSync {
+		return nil
+	}
+	return s.deallocate(ctx, log, provider, cfg)
+}
+
+// deallocate deallocates the VM, after a typed confirmation unless AZURE_FORCE_STOP or
+// --yes is set, and waits for it to be deallocated. A VM that is already stopped is left
+// as it is, and is not started again.
+func (s *sourcePower) deallocate(ctx context.Context, log *logger.Logger, provider *azure.Provider, cfg *config.Config) error {
+	s.once.Do(func() {
+		if !cfg.StopsSourceVM() {
+			return
+		}
+		state, err := provider.GetComputePowerState(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+		if err != nil {
+			s.err = fmt.Errorf("failed to check Compute instance state: %w", err)
+			return
+		}
+		if state == azure.PowerStateStopped || state == azure.PowerStateDeallocated {
+			log.Info(i18n.T("power.already_stopped", state))
+			return
+		}
+		title := i18n.T("guardrail.stop_vm_title", cfg.AzureComputeName, cfg.AzureResourceGroup)
+		if cfg.AzureForceStop {
+			log.Info(i18n.T("power.force_stop", title))
+		} else if s.err = confirmOperation(cfg, log, title, stopSummary(cfg, state), cfg.AzureComputeName); s.err != nil {
+			return
+		}
+		timeout := time.Duration(cfg.AzurePowerTimeoutMinutes) * time.Minute
+		if cfg.AzureRestartVM {
+			restart := cleanupFromContext(ctx).push("start Azure VM "+cfg.AzureComputeName, func(ctx context.Context) error {
+				log.Info(i18n.T("power.starting", cfg.AzureComputeName))
+				if err := provider.StartCompute(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName); err != nil {
+					return err
+				}
+				if err := provider.WaitForComputeRunning(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName, timeout); err != nil {
+					return err
+				}
+				log.Success(i18n.T("power.running_again", cfg.AzureComputeName))
+				return nil
+			})
+			s.mu.Lock()
+			s.restart = restart
+			s.mu.Unlock()
+		}
+		log.Info(i18n.T("power.deallocating", cfg.AzureComputeName))
+		if err := provider.DeallocateCompute(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName); err != nil {
+			s.err = err
+			return
+		}
+		if err := provider.WaitForComputeStopped(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName, timeout); err != nil {
+			s.err = err
+			return
+		}
+		log.Success(i18n.T("power.deallocated", cfg.AzureComputeName))
+	})
+	return s.err
+}
+
+// stopSummary returns the lines shown before the VM is deallocated.
+func stopSummary(cfg *config.Config, state string) []string {
+	summary := []string{
+		"VM: " + cfg.AzureComputeName,
+		"Resource group: " + cfg.AzureResourceGroup,
+		"Power state: " + state,
+	}
+	if cfg.AzureRestartVM {
+		return append(summary, "The VM is started again once its disks are exported")
+	}
+	return append(summary, "The VM stays deallocated; set AZURE_RESTART_VM to start it again once its disks are exported")
+}
+
+// exportStepsDone records that an export step is done, and starts the VM again once
+// all the export steps of the run are.
+func (s *sourcePower) exportStepsDone(cfg *config.Config) {
+	s.mu.Lock()
+	s.exported++
+	restart := s.restart
+	done := s.exported >= exportStepCount(cfg)
+	s.mu.Unlock()
+	if done && restart != nil {
+		restart()
+	}
+}
+
+// exportStepCount returns the number of steps of the run that export disks: the data
+// disk export, and the OS disk export unless it is skipped.
+func exportStepCount(cfg *config.Config) int {
+	if cfg.SkipExport || cfg.AttachesToInstance() {
+		return 1
+	}
+	return 2
+}
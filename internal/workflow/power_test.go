@@ -0,0 +1,59 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func TestSourcePowerDisabled(t *testing.T) {
+	var s sourcePower
+	if err := s.stop(context.Background(), nil, nil, &config.Config{}); err != nil {
+		t.Fatalf("stop() error = %v", err)
+	}
+	if s.restart != nil {
+		t.Error("restart registered without AZURE_STOP_VM")
+	}
+}
+
+func TestExportStepsDone(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   config.Config
+		steps int
+	}{
+		{"os and data disks", config.Config{}, 2},
+		{"os export skipped", config.Config{SkipExport: true}, 1},
+		{"attach to instance", config.Config{OCITargetInstanceID: "ocid1.instance.oc1..a"}, 1},
+	}
+	for _, tt := range tests {
+		restarts := 0
+		s := sourcePower{restart: func() { restarts++ }}
+		for i := 1; i < tt.steps; i++ {
+			s.exportStepsDone(&tt.cfg)
+		}
+		if restarts != 0 {
+			t.Errorf("%s: VM restarted before all %d export steps were done", tt.name, tt.steps)
+		}
+		s.exportStepsDone(&tt.cfg)
+		if restarts != 1 {
+			t.Errorf("%s: VM restarted %d times after all export steps, want 1", tt.name, restarts)
+		}
+	}
+}
+
+func TestStopSummary(t *testing.T) {
+	cfg := &config.Config{AzureComputeName: "vm1", AzureResourceGroup: "rg1"}
+	summary := strings.Join(stopSummary(cfg, "running"), "\n")
+	for _, want := range []string{"VM: vm1", "Resource group: rg1", "Power state: running", "stays deallocated"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("stopSummary() = %q, want %q", summary, want)
+		}
+	}
+	cfg.AzureRestartVM = true
+	if summary := strings.Join(stopSummary(cfg, "running"), "\n"); !strings.Contains(summary, "started again") {
+		t.Errorf("stopSummary() with AZURE_RESTART_VM = %q", summary)
+	}
+}
@@ -0,0 +1,45 @@
+// Package workflow provides host checks shared by the prerequisite steps of workflow handlers.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// clockSkewWarning is the clock offset above which a warning is logged even though
+// it is within the configured maximum.
+const clockSkewWarning = 5 * time.Second
+
+// Overridable in tests.
+var clockOffset = common.ClockOffset
+
+// checkClockSkew compares the local clock with the configured NTP server. Signed OCI
+// requests and Azure tokens are rejected when the clock is off by several minutes,
+// which otherwise surfaces as confusing authentication errors. An unreachable NTP
+// server only produces a warning.
+func checkClockSkew(ctx context.Context, log *logger.Logger, cfg *config.Config) error {
+	if cfg.MaxClockSkewSeconds <= 0 || cfg.NTPServer == "" {
+		return nil
+	}
+	offset, err := clockOffset(ctx, cfg.NTPServer)
+	if err != nil {
+		log.Warningf("Clock skew check skipped: %v", err)
+		return nil
+	}
+	skew := offset.Abs().Round(time.Millisecond)
+	if skew > time.Duration(cfg.MaxClockSkewSeconds)*time.Second {
+		return fmt.Errorf("local clock is off by %s compared to %s (maximum %ds); synchronize the clock (e.g. enable chronyd or systemd-timesyncd) and retry",
+			skew, cfg.NTPServer, cfg.MaxClockSkewSeconds)
+	}
+	if skew > clockSkewWarning {
+		log.Warningf("Local clock is off by %s compared to %s; consider synchronizing it", skew, cfg.NTPServer)
+		return nil
+	}
+	log.Successf("✓ Local clock is in sync with %s (offset %s)", cfg.NTPServer, skew)
+	return nil
+}
@@ -0,0 +1,44 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestCheckClockSkew(t *testing.T) {
+	tests := []struct {
+		name        string
+		offset      time.Duration
+		queryErr    error
+		maxSkew     int
+		expectError bool
+	}{
+		{"in sync", 200 * time.Millisecond, nil, 60, false},
+		{"warning only", -20 * time.Second, nil, 60, false},
+		{"local clock ahead", 5 * time.Minute, nil, 60, true},
+		{"local clock behind", -5 * time.Minute, nil, 60, true},
+		{"ntp unreachable", 0, errors.New("i/o timeout"), 60, false},
+		{"check disabled", time.Hour, nil, 0, false},
+	}
+
+	orig := clockOffset
+	t.Cleanup(func() { clockOffset = orig })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clockOffset = func(context.Context, string) (time.Duration, error) { return tt.offset, tt.queryErr }
+			cfg := &config.Config{NTPServer: "pool.ntp.org", MaxClockSkewSeconds: tt.maxSkew}
+			err := checkClockSkew(context.Background(), logger.New(false), cfg)
+			if tt.expectError && err == nil {
+				t.Error("Expected error but got nil")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
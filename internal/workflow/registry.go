@@ -47,4 +47,3 @@ func (r *Registry) Get(sourcePlatform, targetPlatform string) (Handler, error) {
 
 	return handler, nil
 }
-
@@ -0,0 +1,31 @@
+// Package workflow provides the optional scrubbing of configured images.
+package workflow
+
+import (
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// scrubImage removes host-specific data and secrets from the configured image when
+// SCRUB_IMAGE is enabled, so that migrated images can be used as golden templates.
+func scrubImage(log *logger.Logger, cfg *config.Config, imageFile, luksKey string) error {
+	if !cfg.ScrubImage {
+		return nil
+	}
+	opts := common.ScrubOptions{Exclude: splitList(cfg.ScrubExclude), Include: splitList(cfg.ScrubInclude), LUKSKey: luksKey}
+	return common.ScrubImage(imageFile, opts, log)
+}
+
+// splitList splits a comma-separated configuration value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
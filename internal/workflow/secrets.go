@@ -0,0 +1,50 @@
+// Package workflow provides the OCI Vault and Azure Key Vault backends of secret:// configuration values.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// RegisterSecretBackends registers the secret backends that read from the cloud providers,
+// in addition to the env and hashicorp-vault backends of the config package:
+//
+//	secret://oci-vault/<secret OCID>
+//	secret://azure-keyvault/<vault>/<secret>[/<version>]
+func RegisterSecretBackends() {
+	config.RegisterSecretResolver("oci-vault", config.SecretResolverFunc(resolveOCIVaultSecret))
+	config.RegisterSecretResolver("azure-keyvault", config.SecretResolverFunc(resolveAzureKeyVaultSecret))
+}
+
+// resolveOCIVaultSecret reads an OCI Vault secret with the OCI CLI configuration or
+// session token.
+func resolveOCIVaultSecret(ctx context.Context, cfg *config.Config, reference string) (string, error) {
+	if !strings.HasPrefix(reference, "ocid1.vaultsecret.") {
+		return "", fmt.Errorf("invalid OCI Vault reference '%s' (expected a secret OCID)", reference)
+	}
+	provider, err := oci.NewProvider(cfg.OCIRegion, logger.New(cfg.Debug))
+	if err != nil {
+		return "", fmt.Errorf("failed to create OCI provider: %w", err)
+	}
+	return provider.GetVaultSecret(ctx, reference)
+}
+
+// resolveAzureKeyVaultSecret reads an Azure Key Vault secret with the configured Azure
+// authentication method.
+func resolveAzureKeyVaultSecret(ctx context.Context, cfg *config.Config, reference string) (string, error) {
+	vault, name, ok := strings.Cut(reference, "/")
+	if !ok || vault == "" || name == "" {
+		return "", fmt.Errorf("invalid Azure Key Vault reference '%s' (expected <vault>/<secret>[/<version>])", reference)
+	}
+	provider, err := azure.NewProvider(cfg.AzureSubscriptionID, AzureAuth(cfg), logger.New(cfg.Debug))
+	if err != nil {
+		return "", fmt.Errorf("failed to create Azure provider: %w", err)
+	}
+	return provider.GetKeyVaultSecret(ctx, vault, name)
+}
@@ -0,0 +1,30 @@
+// Package workflow provides credential lifetime checks shared by workflow handlers.
+package workflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// checkOCISession verifies the OCI session token, if session authentication is used,
+// refreshing it when it is about to expire.
+func checkOCISession(ctx context.Context, log *logger.Logger, provider *oci.Provider) error {
+	if !provider.UsesSessionToken() {
+		return nil
+	}
+	expiry, err := provider.CheckSession(ctx)
+	if err != nil {
+		return err
+	}
+	if err := common.CheckCommand("oci"); err != nil {
+		log.Warningf("OCI session token valid until %s, but it cannot be refreshed during the run: %v", expiry.Format(time.RFC3339), err)
+		log.Warning("Install the OCI CLI, or make sure the migration finishes before the token expires")
+		return nil
+	}
+	log.Successf("✓ OCI session token valid until %s, it is refreshed automatically during the run", expiry.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,118 @@
+// Package workflow provides the selection of the OCI shape of the instance from the shapes the availability domain offers.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/template"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+// shapeCandidates returns the shapes of OCI_SHAPES that match architecture, or the
+// default shapes of architecture and the workload profile of the VM size, in order of
+// preference.
+func shapeCandidates(log *logger.Logger, cfg *config.Config, architecture, profile string) []string {
+	preferred := cfg.Shapes()
+	if len(preferred) == 0 {
+		return template.DefaultShapes(architecture, profile)
+	}
+	var shapes []string
+	for _, shape := range preferred {
+		if shapeArchitecture := template.ShapeArchitecture(shape); shapeArchitecture != architecture {
+			log.Warningf("Shape %s is skipped: it is %s, but the source VM is %s", shape, shapeArchitecture, architecture)
+			continue
+		}
+		shapes = append(shapes, shape)
+	}
+	if len(shapes) == 0 {
+		log.Warningf("OCI_SHAPES has no %s shape, using the default shapes", architecture)
+		return template.DefaultShapes(architecture, profile)
+	}
+	return shapes
+}
+
+// selectShape returns the first shape of shapeCandidates that the availability domain of
+// the instance offers, with the OCPUs and memory of the source VM, host capacity for it
+// and service limits that leave room for it, which DenseIO shapes count in blocks of 8
+// OCPUs. Shapes that cannot be checked are assumed to be available. When no shape
+// passes, the first one is returned with a warning, as capacity may change before the
+// template is deployed.
+func selectShape(ctx context.Context, log *logger.Logger, provider *oci.Provider, cfg *config.Config, architecture, profile string, vcpus, memoryGB int32) string {
+	candidates := shapeCandidates(log, cfg, architecture, profile)
+	availabilityDomain, err := instanceAvailabilityDomain(ctx, provider, cfg)
+	if err != nil {
+		log.Warningf("Skipping shape checks, using %s: %v", candidates[0], err)
+		return candidates[0]
+	}
+	offered, err := provider.ListShapes(ctx, cfg.OCICompartmentID, availabilityDomain)
+	if err != nil {
+		log.Warningf("Skipping shape checks, using %s: %v", candidates[0], err)
+		return candidates[0]
+	}
+	for _, shape := range candidates {
+		ocpus, memGB := template.ShapeResources(shape, vcpus, memoryGB, architecture)
+		if problem := shapeProblem(shape, offered, ocpus, memGB); problem != "" {
+			log.Infof("Shape %s is skipped: %s", shape, problem)
+			continue
+		}
+		status, err := provider.CheckShapeCapacity(ctx, cfg.OCICompartmentID, availabilityDomain, shape, float32(ocpus), float32(memGB))
+		if err != nil {
+			log.Warningf("Could not check the capacity of shape %s: %v", shape, err)
+		} else if status != core.CapacityReportShapeAvailabilityAvailabilityStatusAvailable {
+			log.Infof("Shape %s is skipped: %s in %s", shape, status, availabilityDomain)
+			continue
+		}
+		shortfalls, err := provider.CheckLimits(ctx, cfg.OCICompartmentID, shapeLimitRequests(shape, availabilityDomain, ocpus, memGB))
+		if err != nil {
+			log.Warningf("Could not check the limits of shape %s: %v", shape, err)
+		} else if len(shortfalls) > 0 {
+			log.Infof("Shape %s is skipped: %s", shape, shortfalls[0])
+			continue
+		}
+		log.Successf("✓ Shape %s is offered in %s with capacity and limits for %d OCPUs and %s", shape, availabilityDomain, ocpus, memoryDescription(memGB))
+		return shape
+	}
+	log.Warningf("None of the shapes %s has capacity and limits for the instance in %s, using %s; deployment may fail", strings.Join(candidates, ", "), availabilityDomain, candidates[0])
+	return candidates[0]
+}
+
+// shapeProblem returns why shape cannot host an instance with ocpus and memoryGB, given
+// the shapes offered in the availability domain, or "".
+func shapeProblem(shape string, offered []core.Shape, ocpus, memoryGB int32) string {
+	for _, s := range offered {
+		if s.Shape == nil || !strings.EqualFold(*s.Shape, shape) {
+			continue
+		}
+		if s.IsFlexible != nil && *s.IsFlexible {
+			if s.OcpuOptions != nil && s.OcpuOptions.Max != nil && float32(ocpus) > *s.OcpuOptions.Max {
+				return fmt.Sprintf("the instance needs %d OCPUs, the shape allows up to %g", ocpus, *s.OcpuOptions.Max)
+			}
+			if s.MemoryOptions != nil && s.MemoryOptions.MaxInGBs != nil && float32(memoryGB) > *s.MemoryOptions.MaxInGBs {
+				return fmt.Sprintf("the instance needs %d GB of memory, the shape allows up to %g GB", memoryGB, *s.MemoryOptions.MaxInGBs)
+			}
+			return ""
+		}
+		if s.Ocpus != nil && *s.Ocpus < float32(ocpus) {
+			return fmt.Sprintf("the instance needs %d OCPUs, the shape has %g", ocpus, *s.Ocpus)
+		}
+		if s.MemoryInGBs != nil && *s.MemoryInGBs < float32(memoryGB) {
+			return fmt.Sprintf("the instance needs %d GB of memory, the shape has %g GB", memoryGB, *s.MemoryInGBs)
+		}
+		return ""
+	}
+	return "not offered to the compartment in the availability domain"
+}
+
+// memoryDescription describes the memory of an instance, 0 being the default memory of
+// its shape.
+func memoryDescription(memoryGB int32) string {
+	if memoryGB == 0 {
+		return "the memory of the shape"
+	}
+	return fmt.Sprintf("%d GB", memoryGB)
+}
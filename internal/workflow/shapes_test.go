@@ -0,0 +1,75 @@
+package workflow
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/template"
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/core"
+)
+
+func TestShapeCandidates(t *testing.T) {
+	log := logger.New(false)
+	tests := []struct {
+		name         string
+		shapes       string
+		architecture string
+		profile      string
+		want         []string
+	}{
+		{"x86_64 defaults", "", "x86_64", "", []string{"VM.Standard.E5.Flex", "VM.Standard.E4.Flex", "VM.Standard3.Flex"}},
+		{"ARM64 defaults", "", "ARM64", "", []string{"VM.Standard.A1.Flex"}},
+		{"dense IO defaults", "", "x86_64", template.ProfileDenseIO, []string{"VM.DenseIO.E5.Flex", "VM.DenseIO.E4.Flex", "VM.Standard.E5.Flex", "VM.Standard.E4.Flex", "VM.Standard3.Flex"}},
+		{"preference", "VM.Standard3.Flex, VM.Standard.E4.Flex", "x86_64", template.ProfileDenseIO, []string{"VM.Standard3.Flex", "VM.Standard.E4.Flex"}},
+		{"other architecture skipped", "VM.Standard.A1.Flex, VM.Standard.E4.Flex", "x86_64", "", []string{"VM.Standard.E4.Flex"}},
+		{"no shape of the architecture", "VM.Standard.E4.Flex", "ARM64", "", []string{"VM.Standard.A1.Flex"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shapeCandidates(log, &config.Config{OCIShapes: tt.shapes}, tt.architecture, tt.profile)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("shapeCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShapeProblem(t *testing.T) {
+	offered := []core.Shape{
+		{
+			Shape: common.String("VM.Standard.E5.Flex"), IsFlexible: common.Bool(true),
+			OcpuOptions:   &core.ShapeOcpuOptions{Max: common.Float32(94)},
+			MemoryOptions: &core.ShapeMemoryOptions{MaxInGBs: common.Float32(1049)},
+		},
+		{
+			Shape: common.String("VM.Standard3.Flex"), IsFlexible: common.Bool(true),
+			OcpuOptions:   &core.ShapeOcpuOptions{Max: common.Float32(32)},
+			MemoryOptions: &core.ShapeMemoryOptions{MaxInGBs: common.Float32(512)},
+		},
+		{Shape: common.String("VM.Standard2.4"), Ocpus: common.Float32(4), MemoryInGBs: common.Float32(60)},
+	}
+	tests := []struct {
+		shape    string
+		ocpus    int32
+		memoryGB int32
+		want     string
+	}{
+		{"VM.Standard.E5.Flex", 48, 384, ""},
+		{"vm.standard.e5.flex", 4, 32, ""},
+		{"VM.Standard3.Flex", 48, 384, "up to 32"},
+		{"VM.Standard3.Flex", 16, 768, "up to 512 GB"},
+		{"VM.Standard2.4", 4, 32, ""},
+		{"VM.Standard2.4", 8, 32, "the shape has 4"},
+		{"VM.Standard.E4.Flex", 4, 32, "not offered"},
+	}
+	for _, tt := range tests {
+		got := shapeProblem(tt.shape, offered, tt.ocpus, tt.memoryGB)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("shapeProblem(%s, %d, %d) = %q, want %q", tt.shape, tt.ocpus, tt.memoryGB, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+// Package workflow provides the naming and tagging of the Azure snapshots used to export disks.
+package workflow
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"os/user"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+// newMigrationID returns a random identifier for a migration run.
+func newMigrationID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// snapshotCreator returns the local user and host that started the run, as user@host.
+func snapshotCreator() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		name = u.Username
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		name += "@" + host
+	}
+	return name
+}
+
+// snapshotOptions returns the names and tags of the snapshots created by a migration run.
+func snapshotOptions(cfg *config.Config, migrationID string) azure.SnapshotOptions {
+	return azure.SnapshotOptions{
+		NameTemplate: cfg.AzureSnapshotNameTemplate,
+		MigrationID:  migrationID,
+		Creator:      snapshotCreator(),
+	}
+}
@@ -0,0 +1,62 @@
+// Package workflow provides starting instances that were deployed stopped.
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// StartInstance starts an instance deployed with OCI_INSTANCE_STATE=STOPPED and waits
+// for it to run. Without instanceID, the instance recorded in the run summary of the
+// current directory is started.
+func StartInstance(ctx context.Context, cfg *config.Config, log *logger.Logger, instanceID string) error {
+	if instanceID == "" {
+		var err error
+		if instanceID, err = summaryInstanceID(SummaryFileName); err != nil {
+			return err
+		}
+	}
+	provider, err := oci.NewProvider(cfg.OCIRegion, log)
+	if err != nil {
+		return fmt.Errorf("failed to create OCI provider: %w", err)
+	}
+	if err := checkOCISession(ctx, log, provider); err != nil {
+		return err
+	}
+	log.Infof("Starting instance %s", instanceID)
+	if err := provider.StartInstance(ctx, instanceID); err != nil {
+		return err
+	}
+	log.Successf("✓ Instance %s is running", instanceID)
+	privateIPs, publicIPs := lookupInstanceIPs(ctx, log, provider, cfg.OCICompartmentID, instanceID)
+	if len(privateIPs) > 0 {
+		log.Infof("Private IPs: %s", strings.Join(privateIPs, ", "))
+	}
+	if len(publicIPs) > 0 {
+		log.Infof("Public IPs: %s", strings.Join(publicIPs, ", "))
+	}
+	return nil
+}
+
+// summaryInstanceID returns the instance recorded in the run summary at path.
+func summaryInstanceID(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("no instance ID given and the run summary could not be read: %w", err)
+	}
+	var summary RunSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return "", fmt.Errorf("failed to parse run summary %s: %w", path, err)
+	}
+	if summary.Artifacts.InstanceID == "" {
+		return "", fmt.Errorf("run summary %s records no instance; pass the instance OCID", path)
+	}
+	return summary.Artifacts.InstanceID, nil
+}
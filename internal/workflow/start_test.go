@@ -0,0 +1,30 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSummaryInstanceID(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	id, err := summaryInstanceID(write("deployed.json", `{"artifacts": {"instanceId": "ocid1.instance.oc1..abc"}}`))
+	if err != nil || id != "ocid1.instance.oc1..abc" {
+		t.Errorf("summaryInstanceID() = %q, %v, want the recorded instance", id, err)
+	}
+	if _, err := summaryInstanceID(write("template-only.json", `{"artifacts": {"templateDir": "./template-output"}}`)); err == nil || !strings.Contains(err.Error(), "records no instance") {
+		t.Errorf("summaryInstanceID() without instance error = %v", err)
+	}
+	if _, err := summaryInstanceID(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("summaryInstanceID() with a missing summary succeeded")
+	}
+}
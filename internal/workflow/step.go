@@ -0,0 +1,297 @@
+// Package workflow provides the step abstraction shared by workflow handlers.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
+)
+
+// Artifacts produced and consumed by workflow steps. They are used to describe
+// the dependencies between steps when rendering a workflow plan.
+const (
+	ArtifactOSDiskVHD       = "os-disk.vhd"
+	ArtifactOSImageQCOW2    = "os-image.qcow2"
+	ArtifactConfiguredImage = "configured-image.qcow2"
+	ArtifactUploadedObject  = "object-storage-object"
+	ArtifactCustomImage     = "custom-image"
+	ArtifactAvailableImage  = "available-image"
+	ArtifactDataDiskVHDs    = "data-disk.vhd"
+	ArtifactBlockVolumes    = "block-volumes"
+	ArtifactTemplate        = "template"
+	ArtifactInstance        = "instance"
+)
+
+// Step identifiers, shared by the workflows, by which steps are configured in
+// STEP_TIMEOUTS.
+const (
+	StepPrerequisites = "prerequisites"
+	StepExport        = "export"
+	StepConvert       = "convert"
+	StepConfigure     = "configure"
+	StepUpload        = "upload"
+	StepImport        = "import"
+	StepExportData    = "export-data"
+	StepImportData    = "import-data"
+	StepTemplate      = "template"
+	StepWaitImport    = "wait-import"
+	StepDeploy        = "deploy"
+	StepFinishing     = "finishing"
+	StepVerify        = "verify"
+)
+
+// stepTimeoutGrace is how long a step that timed out may take to return after its
+// context is cancelled before it is abandoned.
+var stepTimeoutGrace = 2 * time.Minute
+
+// StepTimeoutError reports a step that did not complete within its timeout.
+type StepTimeoutError struct {
+	Step    string
+	Timeout time.Duration
+	Err     error // Error returned by the step, or the context error if it was abandoned
+}
+
+func (e *StepTimeoutError) Error() string {
+	return fmt.Sprintf("step '%s' timed out after %s", e.Step, e.Timeout)
+}
+
+func (e *StepTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Step describes a single unit of work within a workflow.
+type Step struct {
+	ID        string        // Identifier of the step, one of the Step constants
+	Name      string        // Short human readable name of the step
+	Skip      bool          // Whether the step is skipped for the current configuration
+	SkipMsg   string        // Warning logged when the step is skipped
+	SkipHint  string        // Optional informational message logged after SkipMsg
+	ErrMsg    string        // Prefix for errors returned by the step
+	Inputs    []string      // Artifacts consumed by the step
+	Outputs   []string      // Artifacts produced by the step
+	Timeout   time.Duration // Hard limit on the duration of the step; none when 0
+	Heartbeat time.Duration // Interval between heartbeat log lines while the step runs; none when 0
+	Fn        func(context.Context) error
+}
+
+// configureSteps sets the timeouts configured in STEP_TIMEOUTS and the heartbeat
+// interval configured in HEARTBEAT_MINUTES on steps.
+func configureSteps(cfg *config.Config, steps []Step) []Step {
+	timeouts := cfg.StepTimeoutList()
+	for i := range steps {
+		steps[i].Timeout = timeouts[steps[i].ID]
+		if cfg.HeartbeatMinutes > 0 {
+			steps[i].Heartbeat = time.Duration(cfg.HeartbeatMinutes) * time.Minute
+		}
+	}
+	return steps
+}
+
+// startHeartbeat logs a heartbeat of step every step.Heartbeat until the returned
+// function is called, so that operators and watchdogs can tell a slow step from a hung
+// one. Each line carries the elapsed time and the last reported progress, which does
+// not change while a step hangs.
+func startHeartbeat(log *logger.Logger, step Step, start time.Time) (stop func()) {
+	if step.Heartbeat <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(step.Heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				elapsed := now.Sub(start).Round(time.Second)
+				fields := logger.Fields{
+					"heartbeat":       true,
+					"step":            step.Name,
+					"elapsed_seconds": int64(elapsed.Seconds()),
+				}
+				msg := fmt.Sprintf("Heartbeat: %s running for %s", step.Name, elapsed)
+				if last := progress.Snapshot(); last != "" {
+					fields["progress"] = last
+					msg += ", last progress: " + last
+				}
+				log.InfoFields(msg, fields)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// checkStepTimeouts reports STEP_TIMEOUTS entries that name none of steps.
+func checkStepTimeouts(cfg *config.Config, steps []Step) error {
+	var ids []string
+	for _, step := range steps {
+		ids = append(ids, step.ID)
+	}
+	for id := range cfg.StepTimeoutList() {
+		if !slices.Contains(ids, id) {
+			return fmt.Errorf("STEP_TIMEOUTS names unknown step '%s' (steps: %s)", id, strings.Join(ids, ", "))
+		}
+	}
+	return nil
+}
+
+// runStep runs the function of step within its timeout, if any. When the timeout
+// expires, the context of the step is cancelled; a step that does not return within
+// stepTimeoutGrace, e.g. because a command it waits for ignores the cancellation, is
+// abandoned so that the run can clean up and end rather than hang.
+func runStep(ctx context.Context, step Step) error {
+	if step.Timeout <= 0 {
+		return step.Fn(ctx)
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, step.Timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- step.Fn(stepCtx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-stepCtx.Done():
+		select {
+		case err = <-done:
+		case <-time.After(stepTimeoutGrace):
+			err = stepCtx.Err()
+		}
+	}
+	if err != nil && errors.Is(stepCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return &StepTimeoutError{Step: step.Name, Timeout: step.Timeout, Err: err}
+	}
+	return err
+}
+
+// runSteps executes the given steps in order, honouring their skip states.
+// Step results are recorded in the run summary and notified to the webhook carried by
+// ctx, if any, and each executed step is traced as a span and measured in the step
+// duration metric.
+func runSteps(ctx context.Context, log *logger.Logger, steps []Step) error {
+	summary := summaryFromContext(ctx)
+	for _, step := range steps {
+		if step.Skip {
+			log.Warning(step.SkipMsg)
+			if step.SkipHint != "" {
+				log.Info(step.SkipHint)
+			}
+			if summary != nil {
+				summary.recordStep(step.Name, StatusSkipped, 0, nil)
+			}
+			continue
+		}
+		start := time.Now()
+		stepCtx, span := telemetry.StartSpan(ctx, step.Name)
+		stopHeartbeat := startHeartbeat(log, step, start)
+		err := runStep(stepCtx, step)
+		stopHeartbeat()
+		telemetry.EndSpan(span, err)
+		status := StatusSucceeded
+		var timeoutErr *StepTimeoutError
+		switch {
+		case errors.As(err, &timeoutErr):
+			status = StatusTimedOut
+		case err != nil:
+			status = StatusFailed
+		}
+		duration := time.Since(start)
+		telemetry.RecordStep(ctx, step.Name, status, duration)
+		if summary != nil {
+			summary.recordStep(step.Name, status, duration, err)
+		}
+		notifierFromContext(ctx).stepCompleted(ctx, step.Name, status, duration, err)
+		if err != nil {
+			return fmt.Errorf("%s: %w", step.ErrMsg, err)
+		}
+	}
+	return nil
+}
+
+// stepDependencies returns, for each step, the indexes of the earlier steps it waits for:
+// the producers of its inputs and the latest barrier. Steps that declare neither inputs
+// nor outputs, e.g. prerequisite checks and verification, are barriers that wait for all
+// earlier steps.
+func stepDependencies(steps []Step) [][]int {
+	deps := make([][]int, len(steps))
+	producers := make(map[string]int)
+	barrier := -1
+	for i, step := range steps {
+		if len(step.Inputs) == 0 && len(step.Outputs) == 0 {
+			for j := range i {
+				deps[i] = append(deps[i], j)
+			}
+			barrier = i
+			continue
+		}
+		seen := make(map[int]bool)
+		if barrier >= 0 {
+			seen[barrier] = true
+			deps[i] = append(deps[i], barrier)
+		}
+		for _, input := range step.Inputs {
+			if j, ok := producers[input]; ok && !seen[j] {
+				seen[j] = true
+				deps[i] = append(deps[i], j)
+			}
+		}
+		slices.Sort(deps[i])
+		for _, output := range step.Outputs {
+			producers[output] = i
+		}
+	}
+	return deps
+}
+
+// runStepGraph executes steps like runSteps, except that each step starts as soon as the
+// steps it depends on have completed, so that independent steps (e.g. the OS disk and
+// data disk pipelines) run concurrently. A failing step does not cancel the others, so
+// that their cleanup (e.g. of Azure snapshots) completes, but the steps that depend on
+// it are not run; the errors of all failed steps are returned.
+//
+// Artifacts are handed over whole: a step starts once the files it consumes are complete,
+// never on chunks of them. The OS disk upload cannot start on the chunks of a conversion,
+// as the image is modified in place when it is configured and the QCOW2 metadata is only
+// final at the end of the conversion.
+func runStepGraph(ctx context.Context, log *logger.Logger, steps []Step) error {
+	deps := stepDependencies(steps)
+	done := make([]chan struct{}, len(steps))
+	ok := make([]bool, len(steps))
+	errs := make([]error, len(steps))
+	for i := range steps {
+		done[i] = make(chan struct{})
+	}
+	var wg sync.WaitGroup
+	for i, step := range steps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+			for _, j := range deps[i] {
+				<-done[j]
+				if !ok[j] {
+					return
+				}
+			}
+			errs[i] = runSteps(ctx, log, []Step{step})
+			ok[i] = errs[i] == nil
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
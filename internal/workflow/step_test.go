@@ -0,0 +1,179 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestStepDependencies(t *testing.T) {
+	h := &AzureToOCIHandler{config: &config.Config{}}
+	steps := h.Steps()
+	deps := stepDependencies(steps)
+	byName := make(map[string]int)
+	for i, step := range steps {
+		byName[step.Name] = i
+	}
+	names := func(idx []int) []string {
+		var out []string
+		for _, i := range idx {
+			out = append(out, steps[i].Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		step     string
+		expected []string
+	}{
+		{"Export data disks", []string{"Run prerequisite checks"}},
+		{"Upload image to OCI", []string{"Run prerequisite checks", "Configure image for OCI"}},
+		{"Wait for image import", []string{"Run prerequisite checks", "Import OS image"}},
+		{"Generate template", []string{"Run prerequisite checks", "Import OS image", "Import data disks"}},
+		{"Deploy template", []string{"Run prerequisite checks", "Generate template", "Wait for image import"}},
+	}
+	for _, tt := range tests {
+		if got := names(deps[byName[tt.step]]); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Dependencies of %q = %v, expected %v", tt.step, got, tt.expected)
+		}
+	}
+	if got := len(deps[byName["Verify workflow"]]); got != len(steps)-1 {
+		t.Errorf("Expected verification to depend on all %d earlier steps, got %d", len(steps)-1, got)
+	}
+}
+
+func TestRunStepGraph(t *testing.T) {
+	dataStarted := make(chan struct{})
+	var osFinished atomic.Bool
+	steps := []Step{
+		{Name: "Prerequisites", Fn: func(context.Context) error { return nil }},
+		{Name: "Export OS disk", Outputs: []string{ArtifactOSDiskVHD}, Fn: func(context.Context) error {
+			select {
+			case <-dataStarted:
+				return nil
+			case <-time.After(5 * time.Second):
+				return errors.New("data disk export did not run concurrently")
+			}
+		}},
+		{Name: "Export data disks", ErrMsg: "data disk export failed", Outputs: []string{ArtifactDataDiskVHDs}, Fn: func(context.Context) error {
+			close(dataStarted)
+			return errors.New("boom")
+		}},
+		{Name: "Import OS image", Inputs: []string{ArtifactOSDiskVHD}, Outputs: []string{ArtifactCustomImage}, Fn: func(context.Context) error {
+			osFinished.Store(true)
+			return nil
+		}},
+		{Name: "Import data disks", Inputs: []string{ArtifactDataDiskVHDs}, Outputs: []string{ArtifactBlockVolumes}, Fn: func(context.Context) error {
+			t.Error("step depending on a failed step should not run")
+			return nil
+		}},
+		{Name: "Verify workflow", Fn: func(context.Context) error { t.Error("barrier after a failed step should not run"); return nil }},
+	}
+
+	err := runStepGraph(context.Background(), logger.New(false), steps)
+	if err == nil || err.Error() != "data disk export failed: boom" {
+		t.Fatalf("Expected data disk export error, got %v", err)
+	}
+	if !osFinished.Load() {
+		t.Error("Expected independent OS disk steps to complete after the data disk export failed")
+	}
+}
+
+func TestRunStepsTimeout(t *testing.T) {
+	defer func(grace time.Duration) { stepTimeoutGrace = grace }(stepTimeoutGrace)
+	stepTimeoutGrace = 50 * time.Millisecond
+	release := make(chan struct{})
+	defer close(release)
+	tests := []struct {
+		name string
+		fn   func(context.Context) error
+	}{
+		{"step honours cancellation", func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }},
+		{"step ignores cancellation", func(context.Context) error { <-release; return nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := &RunSummary{Workflow: "test", StartedAt: time.Now().UTC()}
+			ctx := withSummary(context.Background(), summary)
+			steps := []Step{{ID: StepUpload, Name: "Upload", ErrMsg: "upload failed", Timeout: 20 * time.Millisecond, Fn: tt.fn}}
+			err := runSteps(ctx, logger.New(false), steps)
+			var timeoutErr *StepTimeoutError
+			if !errors.As(err, &timeoutErr) || timeoutErr.Step != "Upload" {
+				t.Fatalf("runSteps() error = %v, want a StepTimeoutError", err)
+			}
+			summary.finish(err)
+			if summary.Steps[0].Status != StatusTimedOut || summary.ErrorCategory != ErrorCategoryTimeout {
+				t.Errorf("Step status = %s, error category = %s", summary.Steps[0].Status, summary.ErrorCategory)
+			}
+		})
+	}
+}
+
+func TestRunStepsWithinTimeout(t *testing.T) {
+	steps := []Step{{Name: "Deploy", Timeout: time.Minute, Fn: func(context.Context) error { return nil }}}
+	if err := runSteps(context.Background(), logger.New(false), steps); err != nil {
+		t.Errorf("runSteps() error = %v", err)
+	}
+	failing := []Step{{Name: "Deploy", ErrMsg: "deploy failed", Timeout: time.Minute, Fn: func(context.Context) error { return errors.New("boom") }}}
+	var timeoutErr *StepTimeoutError
+	if err := runSteps(context.Background(), logger.New(false), failing); err == nil || errors.As(err, &timeoutErr) {
+		t.Errorf("runSteps() error = %v, want a failure that is not a timeout", err)
+	}
+}
+
+func TestStepTimeoutsConfiguration(t *testing.T) {
+	cfg := &config.Config{StepTimeouts: "export=4h, deploy=30m"}
+	h := &AzureToOCIHandler{config: cfg}
+	for _, step := range h.Steps() {
+		want := map[string]time.Duration{StepExport: 4 * time.Hour, StepDeploy: 30 * time.Minute}[step.ID]
+		if step.Timeout != want {
+			t.Errorf("Step %s timeout = %s, want %s", step.ID, step.Timeout, want)
+		}
+	}
+	if err := checkStepTimeouts(cfg, h.Steps()); err != nil {
+		t.Errorf("checkStepTimeouts() error = %v", err)
+	}
+	cfg.StepTimeouts = "export-data=1h"
+	if err := checkStepTimeouts(cfg, (&LinuxImageToOCIHandler{config: cfg}).Steps()); err == nil {
+		t.Error("Expected an error for a step the Linux image workflow does not have")
+	}
+}
+
+func TestRunStepsHeartbeat(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "kopru.log")
+	log, err := logger.NewWithFile(false, logFile)
+	if err != nil {
+		t.Fatalf("NewWithFile() error = %v", err)
+	}
+	defer log.Close()
+	steps := []Step{{Name: "Upload", Heartbeat: 10 * time.Millisecond, Fn: func(context.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}}}
+	if err := runSteps(context.Background(), log, steps); err != nil {
+		t.Fatalf("runSteps() error = %v", err)
+	}
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.Contains(string(data), "Heartbeat: Upload running for") {
+		t.Errorf("Expected a heartbeat in the log, got:\n%s", data)
+	}
+
+	cfg := &config.Config{HeartbeatMinutes: 5}
+	for _, step := range (&AzureToOCIHandler{config: cfg}).Steps() {
+		if step.Heartbeat != 5*time.Minute {
+			t.Errorf("Step %s heartbeat = %s, want 5m", step.ID, step.Heartbeat)
+		}
+	}
+}
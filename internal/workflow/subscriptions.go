@@ -0,0 +1,102 @@
+// Package workflow provides the checks of the OS licensing and subscriptions of the source, which do not carry over to OCI.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// licensingWarnings returns the warnings about the Azure licensing of a VM: Azure Hybrid
+// Benefit and the subscriptions of pay-as-you-go RHEL and SLES images are billed by
+// Azure and end with the migration.
+func licensingWarnings(l *azure.ComputeLicensing) []string {
+	var warnings []string
+	image := strings.Trim(strings.Join([]string{l.ImagePublisher, l.ImageOffer, l.ImageSKU}, ":"), ":")
+	switch license := strings.ToUpper(l.LicenseType); {
+	case strings.HasPrefix(license, "WINDOWS"):
+		warnings = append(warnings, fmt.Sprintf("The VM uses Azure Hybrid Benefit (%s), which does not carry over to OCI; review the Windows licensing of the instance in OCI", l.LicenseType))
+	case strings.HasPrefix(license, "RHEL"):
+		warnings = append(warnings, fmt.Sprintf("The VM uses Azure Hybrid Benefit for RHEL (%s); make the Red Hat subscriptions available to OCI with Red Hat Cloud Access and register the instance again with subscription-manager", l.LicenseType))
+	case strings.HasPrefix(license, "SLES"):
+		warnings = append(warnings, fmt.Sprintf("The VM uses Azure Hybrid Benefit for SLES (%s); register the instance again with SUSEConnect and a SUSE subscription valid in OCI", l.LicenseType))
+	case strings.EqualFold(l.ImagePublisher, "RedHat"):
+		warnings = append(warnings, fmt.Sprintf("The VM was created from the pay-as-you-go RHEL image %s, whose subscription is billed by Azure; the instance needs a Red Hat subscription in OCI (subscription-manager register)", image))
+	case strings.EqualFold(l.ImagePublisher, "SUSE"):
+		warnings = append(warnings, fmt.Sprintf("The VM was created from the pay-as-you-go SLES image %s, whose subscription is billed by Azure; the instance needs a SUSE subscription in OCI (SUSEConnect -r <code>)", image))
+	}
+	return warnings
+}
+
+// checkAzureLicensing warns about the Azure licensing of the VM during the prerequisite
+// checks. It does not fail the checks.
+func checkAzureLicensing(ctx context.Context, log *logger.Logger, provider *azure.Provider, cfg *config.Config) {
+	licensing, err := provider.GetComputeLicensing(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+	if err != nil {
+		log.Warningf("Could not read the licensing of the Azure VM: %v", err)
+		return
+	}
+	warnings := licensingWarnings(licensing)
+	for _, w := range warnings {
+		log.Warning(w)
+	}
+	if len(warnings) == 0 {
+		log.Success("✓ No Azure licensing that needs attention after migration")
+	}
+}
+
+// subscriptionWarnings returns the warnings about the guest subscriptions found in an
+// image, which are tied to the source VM or to the update infrastructure of its cloud.
+func subscriptionWarnings(s common.GuestSubscriptions) []string {
+	var warnings []string
+	if s.RHUI {
+		warnings = append(warnings, "The image is updated from the Red Hat Update Infrastructure of the source cloud, which is not reachable from OCI; register the instance with subscription-manager after migration")
+	}
+	if s.RHSM {
+		warnings = append(warnings, "The image is registered with Red Hat Subscription Management as the source VM; register the instance again after migration (subscription-manager register)")
+	}
+	if s.SUSECloud {
+		warnings = append(warnings, "The image is updated from the SUSE public cloud update infrastructure of the source cloud, which is not reachable from OCI; register the instance with SUSEConnect after migration")
+	}
+	if s.SUSEConnect && !s.SUSECloud {
+		warnings = append(warnings, "The image is registered with SUSEConnect as the source VM; register the instance again after migration (SUSEConnect -r <code>)")
+	}
+	return warnings
+}
+
+// checkGuestSubscriptions warns about the subscriptions of the guest in imageFile and,
+// with SUBSCRIPTION_REREGISTER, installs the service that registers the instance again
+// on its first boot. A failed detection is only logged.
+func checkGuestSubscriptions(log *logger.Logger, cfg *config.Config, imageFile, luksKey string) error {
+	subs, err := common.DetectGuestSubscriptions(imageFile, luksKey)
+	if err != nil {
+		log.Warningf("Could not check the subscriptions of the image: %v", err)
+		return nil
+	}
+	if !subs.Any() {
+		log.Info("No RHEL or SLES subscription found in the image")
+		return nil
+	}
+	for _, w := range subscriptionWarnings(subs) {
+		log.Warning(w)
+	}
+	if !cfg.SubscriptionReregister {
+		log.Info("Set SUBSCRIPTION_REREGISTER to register the instance again on its first boot in OCI")
+		return nil
+	}
+	if (subs.RHEL() && cfg.RHSMOrgID == "") || (subs.SLES() && cfg.SUSERegistrationCode == "") {
+		log.Warning("Skipping the re-registration service: no credentials are configured for the subscription service of the image")
+		return nil
+	}
+	return common.InstallReregistration(imageFile, common.ReregisterCredentials{
+		RHSMOrgID:            cfg.RHSMOrgID,
+		RHSMActivationKey:    cfg.RHSMActivationKey,
+		SUSERegistrationCode: cfg.SUSERegistrationCode,
+		SUSEEmail:            cfg.SUSERegistrationEmail,
+	}, luksKey, log)
+}
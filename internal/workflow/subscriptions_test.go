@@ -0,0 +1,55 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+)
+
+func TestLicensingWarnings(t *testing.T) {
+	tests := []struct {
+		name      string
+		licensing azure.ComputeLicensing
+		want      string
+	}{
+		{"windows hybrid benefit", azure.ComputeLicensing{LicenseType: "Windows_Server"}, "Azure Hybrid Benefit (Windows_Server)"},
+		{"rhel byos", azure.ComputeLicensing{LicenseType: "RHEL_BYOS", ImagePublisher: "RedHat"}, "Red Hat Cloud Access"},
+		{"sles byos", azure.ComputeLicensing{LicenseType: "SLES_BYOS"}, "SUSEConnect"},
+		{"rhel payg", azure.ComputeLicensing{ImagePublisher: "RedHat", ImageOffer: "RHEL", ImageSKU: "9-lvm-gen2"}, "pay-as-you-go RHEL image RedHat:RHEL:9-lvm-gen2"},
+		{"sles payg", azure.ComputeLicensing{ImagePublisher: "SUSE", ImageOffer: "sles-15-sp5", ImageSKU: "gen2"}, "pay-as-you-go SLES image"},
+		{"ubuntu", azure.ComputeLicensing{ImagePublisher: "Canonical"}, ""},
+	}
+	for _, tt := range tests {
+		got := strings.Join(licensingWarnings(&tt.licensing), "\n")
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("%s: licensingWarnings() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSubscriptionWarnings(t *testing.T) {
+	tests := []struct {
+		subs common.GuestSubscriptions
+		want []string
+	}{
+		{common.GuestSubscriptions{}, nil},
+		{common.GuestSubscriptions{RHUI: true}, []string{"Red Hat Update Infrastructure"}},
+		{common.GuestSubscriptions{RHSM: true}, []string{"Red Hat Subscription Management"}},
+		{common.GuestSubscriptions{SUSEConnect: true, SUSECloud: true}, []string{"SUSE public cloud update infrastructure"}},
+		{common.GuestSubscriptions{SUSEConnect: true}, []string{"registered with SUSEConnect"}},
+	}
+	for _, tt := range tests {
+		got := subscriptionWarnings(tt.subs)
+		if len(got) != len(tt.want) {
+			t.Errorf("subscriptionWarnings(%+v) = %q, want %q", tt.subs, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if !strings.Contains(got[i], tt.want[i]) {
+				t.Errorf("subscriptionWarnings(%+v) = %q, want %q", tt.subs, got, tt.want)
+			}
+		}
+	}
+}
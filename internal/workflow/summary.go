@@ -0,0 +1,161 @@
+// Package workflow provides the machine-readable run summary written after each workflow.
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// SummaryFileName is the name of the run summary written to the current directory.
+const SummaryFileName = "kopru-summary.json"
+
+// Run and step statuses recorded in the summary.
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+	StatusTimedOut  = "timed_out"
+)
+
+// ErrorCategoryTimeout is the error category of runs stopped by a step timeout.
+const ErrorCategoryTimeout = "timeout"
+
+// RunSummary describes the outcome of a workflow run for downstream automation.
+type RunSummary struct {
+	RunID           string              `json:"runId"`
+	Workflow        string              `json:"workflow"`
+	Version         string              `json:"version"`
+	Status          string              `json:"status"`
+	Error           string              `json:"error,omitempty"`
+	ErrorCategory   string              `json:"errorCategory,omitempty"`
+	StartedAt       time.Time           `json:"startedAt"`
+	FinishedAt      time.Time           `json:"finishedAt"`
+	DurationSeconds float64             `json:"durationSeconds"`
+	Source          map[string]string   `json:"source,omitempty"`
+	SourceTags      map[string]string   `json:"sourceTags,omitempty"`
+	NotCarriedOver  []SourceDependency  `json:"notCarriedOver,omitempty"`
+	LiveSync        *LiveSyncResult     `json:"liveSync,omitempty"`
+	Artifacts       SummaryArtifacts    `json:"artifacts"`
+	Steps           []StepResult        `json:"steps"`
+	Checksums       []ChecksumResult    `json:"checksums,omitempty"`
+	Finishing       *FinishingResult    `json:"finishing,omitempty"`
+	BootBeacon      *BootBeaconResult   `json:"bootBeacon,omitempty"`
+	APIOperations   map[string][]string `json:"apiOperations,omitempty"`
+	Throughput      *ThroughputReport   `json:"throughput,omitempty"`
+
+	mu sync.Mutex // Guards Steps while steps run concurrently
+}
+
+// SummaryArtifacts lists the resources and files produced by a workflow run.
+type SummaryArtifacts struct {
+	ImageID         string   `json:"imageId,omitempty"`
+	ImageLaunchMode string   `json:"imageLaunchMode,omitempty"`
+	DataVolumeIDs   []string `json:"dataVolumeIds,omitempty"`
+	InstanceID      string   `json:"instanceId,omitempty"`
+	PrivateIPs      []string `json:"privateIps,omitempty"`
+	PublicIPs       []string `json:"publicIps,omitempty"`
+	TemplateDir     string   `json:"templateDir,omitempty"`
+	ExportDir       string   `json:"exportDir,omitempty"`
+}
+
+// StepResult records the outcome and duration of a single workflow step.
+type StepResult struct {
+	Name            string  `json:"name"`
+	Status          string  `json:"status"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// ChecksumResult records the SHA-256 of an artifact and the copy it was verified against.
+type ChecksumResult struct {
+	Artifact        string `json:"artifact"`
+	File            string `json:"file"`
+	SHA256          string `json:"sha256,omitempty"`
+	ObjectMD5       string `json:"objectMd5,omitempty"`
+	VerifiedAgainst string `json:"verifiedAgainst,omitempty"`
+	Status          string `json:"status"`
+}
+
+// FinishingResult records the finishing script run on the instance after first boot.
+type FinishingResult struct {
+	Script    string `json:"script"`
+	CommandID string `json:"commandId,omitempty"`
+	State     string `json:"state"`
+	ExitCode  int    `json:"exitCode"`
+	Output    string `json:"output,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// BootBeaconResult records the boot beacon the instance sent on its first boot.
+type BootBeaconResult struct {
+	Object          string `json:"object"`
+	Status          string `json:"status"`
+	InstanceID      string `json:"instanceId,omitempty"`
+	BootTime        int64  `json:"bootTime,omitempty"`
+	CloudInitStatus string `json:"cloudInitStatus,omitempty"`
+	Hostname        string `json:"hostname,omitempty"`
+	Kernel          string `json:"kernel,omitempty"`
+}
+
+type summaryKey struct{}
+
+// withSummary returns a context through which runSteps records step results into s.
+func withSummary(ctx context.Context, s *RunSummary) context.Context {
+	return context.WithValue(ctx, summaryKey{}, s)
+}
+
+func summaryFromContext(ctx context.Context) *RunSummary {
+	s, _ := ctx.Value(summaryKey{}).(*RunSummary)
+	return s
+}
+
+func (s *RunSummary) recordStep(name, status string, duration time.Duration, err error) {
+	result := StepResult{Name: name, Status: status, DurationSeconds: duration.Seconds()}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Steps = append(s.Steps, result)
+}
+
+// finish sets the final status and duration of the run.
+func (s *RunSummary) finish(err error) {
+	s.FinishedAt = time.Now().UTC()
+	s.DurationSeconds = s.FinishedAt.Sub(s.StartedAt).Seconds()
+	s.Status = StatusSucceeded
+	if err != nil {
+		s.Status = StatusFailed
+		s.Error = err.Error()
+		var timeoutErr *StepTimeoutError
+		if errors.As(err, &timeoutErr) {
+			s.ErrorCategory = ErrorCategoryTimeout
+		}
+	}
+}
+
+// JSON returns the summary as indented JSON.
+func (s *RunSummary) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode run summary: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Write saves the summary as indented JSON.
+func (s *RunSummary) Write(path string) error {
+	data, err := s.JSON()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write run summary: %w", err)
+	}
+	return nil
+}
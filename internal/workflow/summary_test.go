@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+func TestRunStepsRecordsSummary(t *testing.T) {
+	summary := &RunSummary{Workflow: "test", StartedAt: time.Now().UTC()}
+	ctx := withSummary(context.Background(), summary)
+	steps := []Step{
+		{Name: "Export", Fn: func(context.Context) error { return nil }},
+		{Name: "Upload", Skip: true, SkipMsg: "skipped"},
+		{Name: "Import", ErrMsg: "import failed", Fn: func(context.Context) error { return errors.New("boom") }},
+		{Name: "Deploy", Fn: func(context.Context) error { t.Error("step after failure should not run"); return nil }},
+	}
+
+	err := runSteps(ctx, logger.New(false), steps)
+	if err == nil {
+		t.Fatal("Expected error from failing step")
+	}
+	summary.finish(err)
+
+	expected := []struct{ name, status string }{
+		{"Export", StatusSucceeded},
+		{"Upload", StatusSkipped},
+		{"Import", StatusFailed},
+	}
+	if len(summary.Steps) != len(expected) {
+		t.Fatalf("Expected %d step results, got %d", len(expected), len(summary.Steps))
+	}
+	for i, want := range expected {
+		if summary.Steps[i].Name != want.name || summary.Steps[i].Status != want.status {
+			t.Errorf("Step %d: expected %s/%s, got %s/%s", i, want.name, want.status, summary.Steps[i].Name, summary.Steps[i].Status)
+		}
+	}
+	if summary.Steps[2].Error != "boom" {
+		t.Errorf("Expected step error 'boom', got '%s'", summary.Steps[2].Error)
+	}
+	if summary.Status != StatusFailed || summary.Error == "" {
+		t.Errorf("Expected failed run with error, got status '%s' error '%s'", summary.Status, summary.Error)
+	}
+}
+
+func TestRunSummaryWrite(t *testing.T) {
+	summary := &RunSummary{Workflow: "test", Version: "1.0.0", StartedAt: time.Now().UTC()}
+	summary.Artifacts = SummaryArtifacts{ImageID: "ocid1.image.oc1.iad.aaaaaaaatest", TemplateDir: "./template-output"}
+	summary.finish(nil)
+
+	path := filepath.Join(t.TempDir(), SummaryFileName)
+	if err := summary.Write(path); err != nil {
+		t.Fatalf("Failed to write summary: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read summary: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Summary is not valid JSON: %v", err)
+	}
+	if decoded["status"] != StatusSucceeded {
+		t.Errorf("Expected status '%s', got %v", StatusSucceeded, decoded["status"])
+	}
+	artifacts, _ := decoded["artifacts"].(map[string]interface{})
+	if artifacts["imageId"] != "ocid1.image.oc1.iad.aaaaaaaatest" {
+		t.Errorf("Expected imageId artifact, got %v", artifacts)
+	}
+}
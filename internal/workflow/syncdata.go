@@ -0,0 +1,231 @@
+// Package workflow provides the catch-up sync of data disks onto a migrated instance.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// DataSyncOptions selects the disks and the instance synced by SyncData.
+type DataSyncOptions struct {
+	Disks        []string // Azure data disks to sync; all data disks of the VM when empty
+	Host         string   // Address of the migrated instance; its private IP from the run summary when empty
+	User         string   // SSH user with passwordless sudo; KOPRU_BREAKGLASS_USER when empty
+	IdentityFile string   // SSH private key; SSH_KEY_FILE without .pub when empty
+	DryRun       bool     // List the changes without transferring them
+}
+
+// selectSyncDisks returns the data disks of the VM to sync, in the order of the VM.
+func selectSyncDisks(vmDisks, selected []string) ([]string, error) {
+	if len(selected) == 0 {
+		return vmDisks, nil
+	}
+	for _, disk := range selected {
+		if !slices.Contains(vmDisks, disk) {
+			return nil, fmt.Errorf("%s is not a data disk of the VM (data disks: %s)", disk, strings.Join(vmDisks, ", "))
+		}
+	}
+	var disks []string
+	for _, disk := range vmDisks {
+		if slices.Contains(selected, disk) {
+			disks = append(disks, disk)
+		}
+	}
+	return disks, nil
+}
+
+// syncTarget resolves the SSH target of the migrated instance from opts and cfg.
+func syncTarget(ctx context.Context, log *logger.Logger, cfg *config.Config, opts DataSyncOptions) (common.SSHTarget, error) {
+	target := common.SSHTarget{Host: opts.Host, User: opts.User, IdentityFile: opts.IdentityFile}
+	if target.User == "" {
+		target.User = cfg.BreakglassUser
+	}
+	if target.User == "" {
+		return target, fmt.Errorf("no SSH user given; pass --user or set KOPRU_BREAKGLASS_USER")
+	}
+	if target.IdentityFile == "" && strings.HasSuffix(cfg.SSHKeyFilePath, ".pub") {
+		if key := strings.TrimSuffix(cfg.SSHKeyFilePath, ".pub"); fileExists(key) {
+			target.IdentityFile = key
+		}
+	}
+	if target.Host != "" {
+		return target, nil
+	}
+	instanceID, err := summaryInstanceID(SummaryFileName)
+	if err != nil {
+		return target, fmt.Errorf("%w; pass --host", err)
+	}
+	provider, err := oci.NewProvider(cfg.OCIRegion, log)
+	if err != nil {
+		return target, fmt.Errorf("failed to create OCI provider: %w", err)
+	}
+	if err := checkOCISession(ctx, log, provider); err != nil {
+		return target, err
+	}
+	privateIPs, _ := lookupInstanceIPs(ctx, log, provider, cfg.OCICompartmentID, instanceID)
+	if len(privateIPs) == 0 {
+		return target, fmt.Errorf("no private IP found for instance %s; pass --host", instanceID)
+	}
+	target.Host = privateIPs[0]
+	return target, nil
+}
+
+// fileExists reports whether path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// SyncData re-exports Azure data disks and transfers the changes made since the
+// migration onto the volumes attached to the migrated instance, for a catch-up pass
+// after the initial bulk copy. Each filesystem of a disk is mounted read-only and
+// rsynced over SSH to the directory where the instance mounts the filesystem with the
+// same UUID; the volumes are block copies of the disks, so the UUIDs match.
+func SyncData(ctx context.Context, cfg *config.Config, log *logger.Logger, opts DataSyncOptions) error {
+	if cfg.AzureComputeName == "" || cfg.AzureResourceGroup == "" || cfg.AzureSubscriptionID == "" {
+		return fmt.Errorf("AZURE_SUBSCRIPTION_ID, AZURE_RESOURCE_GROUP and AZURE_COMPUTE_NAME are required")
+	}
+	for _, tool := range []string{"rsync", "ssh", "guestmount", "virt-filesystems"} {
+		if err := common.CheckCommand(tool); err != nil {
+			return fmt.Errorf("%w; it is required to sync data disks", err)
+		}
+	}
+	target, err := syncTarget(ctx, log, cfg, opts)
+	if err != nil {
+		return err
+	}
+	log.Infof("Checking SSH access to %s@%s...", target.User, target.Host)
+	if _, err := target.Run("sudo -n rsync --version"); err != nil {
+		return fmt.Errorf("the instance must be reachable over SSH with passwordless sudo and rsync installed: %w", err)
+	}
+	log.Successf("✓ SSH access to %s verified", target.Host)
+
+	azureProvider, err := azure.NewProvider(cfg.AzureSubscriptionID, AzureAuth(cfg), log)
+	if err != nil {
+		return fmt.Errorf("failed to create Azure provider: %w", err)
+	}
+	azureProvider.ConfigureDownload(cfg.DownloadBlockSizeMB, cfg.DownloadWorkers, cfg.DownloadMBPerSecond)
+	azureProvider.ConfigureSnapshots(snapshotOptions(cfg, newMigrationID()))
+	vmDisks, err := azureProvider.GetComputeDataDiskNames(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+	if err != nil {
+		return fmt.Errorf("failed to get data disk names: %w", err)
+	}
+	disks, err := selectSyncDisks(vmDisks, opts.Disks)
+	if err != nil {
+		return err
+	}
+	if len(disks) == 0 {
+		log.Info("The VM has no data disks to sync")
+		return nil
+	}
+	if stopped, err := azureProvider.CheckComputeIsStopped(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName); err == nil && !stopped {
+		log.Warning("The source VM is running; files changed while its disks are snapshotted may be synced incompletely. Stop it for the final pass.")
+	}
+
+	syncDir := fmt.Sprintf("./%s-data-disk-sync", common.SanitizeName(cfg.AzureComputeName))
+	if err := common.EnsureDir(syncDir); err != nil {
+		return fmt.Errorf("failed to create sync directory: %w", err)
+	}
+	log.Infof("Sync directory: %s", syncDir)
+	var errs []error
+	for _, disk := range disks {
+		if err := syncDataDisk(ctx, log, azureProvider, cfg, target, disk, syncDir, opts.DryRun); err != nil {
+			log.Warningf("[%s] %v", disk, err)
+			errs = append(errs, fmt.Errorf("%s: %w", disk, err))
+		}
+	}
+	reportWatchdogIncidents(log, common.WatchdogIncidents())
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d data disk(s) failed to sync: %w", len(errs), len(disks), errors.Join(errs...))
+	}
+	log.Successf("✓ %d data disk(s) synced to %s", len(disks), target.Host)
+	return nil
+}
+
+// syncDataDisk exports disk, converts it to RAW and rsyncs each of its filesystems to
+// the directory where the instance mounts it. The exported files are removed afterwards.
+func syncDataDisk(ctx context.Context, log *logger.Logger, provider *azure.Provider, cfg *config.Config, target common.SSHTarget, disk, syncDir string, dryRun bool) error {
+	log.Infof("[%s] Exporting data disk...", disk)
+	vhdFile, err := provider.ExportAzureDisk(ctx, disk, cfg.AzureResourceGroup, syncDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(vhdFile) }()
+	rawFile := strings.TrimSuffix(vhdFile, ".vhd") + ".raw"
+	if err := common.ConvertVHDToRAW(vhdFile, rawFile, log); err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(rawFile) }()
+
+	luksKey, cleanup, err := luksKeySelector(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	filesystems, err := common.ListGuestFilesystems(rawFile, luksKey)
+	if err != nil {
+		return err
+	}
+	if len(filesystems) == 0 {
+		log.Warningf("[%s] No filesystems found that can be synced; raw block devices must be copied with a full migration", disk)
+		return nil
+	}
+	var errs []error
+	for _, fs := range filesystems {
+		remoteDir, err := target.RemoteMountPoint(fs.UUID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if remoteDir == "" {
+			log.Warningf("[%s] Filesystem %s (UUID %s) is not mounted on %s, skipping", disk, fs.Device, fs.UUID, target.Host)
+			continue
+		}
+		mountPoint := filepath.Join(syncDir, common.SanitizeName(disk+"-"+filepath.Base(fs.Device)))
+		if err := common.EnsureDir(mountPoint); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		mountPoint, err = common.MountGuestFilesystem(rawFile, fs.Device, mountPoint, luksKey, log)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		log.Infof("[%s] Syncing %s to %s:%s...", disk, fs.Device, target.Host, remoteDir)
+		err = common.RsyncToHost(mountPoint, remoteDir, target, dryRun, log)
+		if unmountErr := common.UnmountGuestFilesystem(mountPoint, log); unmountErr != nil {
+			log.Warningf("[%s] %v", disk, unmountErr)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		log.Successf("[%s] %s synced to %s", disk, fs.Device, remoteDir)
+	}
+	return errors.Join(errs...)
+}
+
+// reportWatchdogIncidents logs the stuck mount and unmount commands killed by the
+// watchdog, which may point at a wedged FUSE device or libguestfs appliance on the host.
+func reportWatchdogIncidents(log *logger.Logger, incidents []common.WatchdogIncident) {
+	if len(incidents) == 0 {
+		return
+	}
+	log.Warning(i18n.T("summary.watchdog_incidents", len(incidents)))
+	for _, incident := range incidents {
+		log.Warning(i18n.T("summary.watchdog_incident", incident.Time.Format(time.RFC3339), incident.Command, incident.Target, incident.Recovery))
+	}
+}
@@ -0,0 +1,28 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelectSyncDisks(t *testing.T) {
+	vmDisks := []string{"vm-data-0", "vm-data-1", "vm-data-2"}
+	tests := []struct {
+		name     string
+		selected []string
+		want     []string
+		wantErr  bool
+	}{
+		{"all disks", nil, vmDisks, false},
+		{"VM order", []string{"vm-data-2", "vm-data-0"}, []string{"vm-data-0", "vm-data-2"}, false},
+		{"unknown disk", []string{"vm-osdisk"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectSyncDisks(vmDisks, tt.selected)
+			if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectSyncDisks() = %v, %v, want %v (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Package workflow provides the Azure tags carried over to the OCI resources of a migration.
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/azure"
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// Length limits of OCI freeform tag keys and values.
+const (
+	maxTagKeyLength   = 100
+	maxTagValueLength = 256
+)
+
+// sourceTags are the tags of the source VM and its disks, carried over to the instance,
+// the image and the data volumes with PRESERVE_SOURCE_TAGS. The zero value carries no
+// tags.
+type sourceTags struct {
+	vm        map[string]string
+	osDisk    map[string]string
+	dataDisks map[string]map[string]string // By disk name
+	defined   map[string]string            // namespace.key of SOURCE_TAG_MAP_FILE by lowercase Azure tag key
+}
+
+// readSourceTags reads the tags of the disks of the source VM, whose own tags are
+// vmTags, when PRESERVE_SOURCE_TAGS is set.
+func readSourceTags(ctx context.Context, log *logger.Logger, provider *azure.Provider, cfg *config.Config, vmTags map[string]string) (sourceTags, error) {
+	if !cfg.PreserveSourceTags {
+		return sourceTags{}, nil
+	}
+	defined, err := cfg.SourceTagMap()
+	if err != nil {
+		return sourceTags{}, err
+	}
+	disks, err := provider.GetComputeDiskTags(ctx, cfg.AzureResourceGroup, cfg.AzureComputeName)
+	if err != nil {
+		return sourceTags{}, fmt.Errorf("failed to read disk tags: %w", err)
+	}
+	log.Successf("✓ %d tag(s) of the VM and the tags of %d disk(s) will be carried over to OCI", len(vmTags), len(disks.DataDisks)+1)
+	return sourceTags{vm: vmTags, osDisk: disks.OSDisk, dataDisks: disks.DataDisks, defined: defined}, nil
+}
+
+// instance returns the freeform tags and the defined tags by namespace.key of the
+// instance, from the tags of the VM.
+func (t sourceTags) instance() (freeform, defined map[string]string) {
+	return t.split(t.vm)
+}
+
+// image returns the tags of the image, from the tags of the VM and its OS disk.
+func (t sourceTags) image() oci.Tags {
+	return t.ociTags(t.osDisk)
+}
+
+// dataVolume returns the tags of the volume of the data disk diskName, from the tags of
+// the VM and the disk.
+func (t sourceTags) dataVolume(diskName string) oci.Tags {
+	return t.ociTags(t.dataDisks[diskName])
+}
+
+// ociTags returns the tags of the VM overridden by diskTags as OCI tags, with the
+// created-by tag with which Kopru finds its resources.
+func (t sourceTags) ociTags(diskTags map[string]string) oci.Tags {
+	tags := maps.Clone(t.vm)
+	if tags == nil {
+		tags = make(map[string]string)
+	}
+	maps.Copy(tags, diskTags)
+	freeform, defined := t.split(tags)
+	freeform[createdByTagKey] = createdByTagValue
+	return oci.Tags{Freeform: freeform, Defined: definedTagValues(defined)}
+}
+
+// split separates tags into the defined tags of SOURCE_TAG_MAP_FILE, by namespace.key,
+// and freeform tags, whose keys and values are fitted to the limits of OCI.
+func (t sourceTags) split(tags map[string]string) (freeform, defined map[string]string) {
+	freeform, defined = make(map[string]string), make(map[string]string)
+	for key, value := range tags {
+		if definedTag, ok := t.defined[strings.ToLower(key)]; ok {
+			defined[definedTag] = value
+			continue
+		}
+		freeform[freeformTagKey(key)] = truncate(value, maxTagValueLength)
+	}
+	return freeform, defined
+}
+
+// freeformTagKey returns key as an OCI freeform tag key, which cannot contain periods
+// or whitespace.
+func freeformTagKey(key string) string {
+	key = strings.Map(func(r rune) rune {
+		if r == '.' || r == ' ' || r == '\t' {
+			return '_'
+		}
+		return r
+	}, key)
+	return truncate(key, maxTagKeyLength)
+}
+
+// truncate shortens s to at most n runes.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// definedTagValues converts defined tags by namespace.key to the namespace and key
+// maps of the OCI SDK.
+func definedTagValues(tags map[string]string) map[string]map[string]interface{} {
+	if len(tags) == 0 {
+		return nil
+	}
+	values := make(map[string]map[string]interface{})
+	for definedTag, value := range tags {
+		namespace, key, _ := strings.Cut(definedTag, ".")
+		if values[namespace] == nil {
+			values[namespace] = make(map[string]interface{})
+		}
+		values[namespace][key] = value
+	}
+	return values
+}
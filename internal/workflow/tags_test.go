@@ -0,0 +1,61 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSourceTagsOCITags(t *testing.T) {
+	tags := sourceTags{
+		vm:        map[string]string{"env": "prod", "CostCenter": "42", "app.tier": "web"},
+		osDisk:    map[string]string{"env": "staging"},
+		dataDisks: map[string]map[string]string{"data-0": {"backup": "daily"}},
+		defined:   map[string]string{"costcenter": "Operations.CostCenter"},
+	}
+
+	image := tags.image()
+	if image.Freeform["env"] != "staging" || image.Freeform["app_tier"] != "web" || image.Freeform[createdByTagKey] != createdByTagValue {
+		t.Errorf("image() freeform tags = %v", image.Freeform)
+	}
+	if _, ok := image.Freeform["CostCenter"]; ok {
+		t.Error("Expected the mapped tag to be a defined tag only")
+	}
+	if got := image.Defined["Operations"]["CostCenter"]; got != "42" {
+		t.Errorf("image() defined tag Operations.CostCenter = %v, want 42", got)
+	}
+
+	volume := tags.dataVolume("data-0")
+	if volume.Freeform["env"] != "prod" || volume.Freeform["backup"] != "daily" {
+		t.Errorf("dataVolume() freeform tags = %v", volume.Freeform)
+	}
+
+	freeform, defined := tags.instance()
+	if freeform["env"] != "prod" || defined["Operations.CostCenter"] != "42" {
+		t.Errorf("instance() = %v, %v", freeform, defined)
+	}
+	if _, ok := freeform[createdByTagKey]; ok {
+		t.Error("Expected the template to set the created-by tag of the instance")
+	}
+}
+
+func TestSourceTagsZeroValue(t *testing.T) {
+	var tags sourceTags
+	image := tags.image()
+	if len(image.Freeform) != 1 || image.Defined != nil {
+		t.Errorf("image() = %+v, want only the created-by tag", image)
+	}
+}
+
+func TestFreeformTagKey(t *testing.T) {
+	tests := map[string]string{
+		"env":                    "env",
+		"app.tier":               "app_tier",
+		"cost center":            "cost_center",
+		strings.Repeat("k", 120): strings.Repeat("k", maxTagKeyLength),
+	}
+	for key, want := range tests {
+		if got := freeformTagKey(key); got != want {
+			t.Errorf("freeformTagKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package workflow provides the throughput statistics of the transfers made by a run.
+package workflow
+
+import (
+	"math"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
+)
+
+// throughputPhases are the transfer phases in the order they are reported.
+var throughputPhases = []string{progress.PhaseDownload, progress.PhaseConvert, progress.PhaseCopy, progress.PhaseUpload}
+
+// ThroughputReport records the throughput of the downloads, conversions, data disk
+// copies and uploads of a run, for capacity planning of later migrations.
+type ThroughputReport struct {
+	Transfers []TransferResult  `json:"transfers"`
+	Phases    []PhaseThroughput `json:"phases"`
+}
+
+// TransferResult records the throughput of a single transfer of a disk.
+type TransferResult struct {
+	Phase           string  `json:"phase"`
+	Item            string  `json:"item"`
+	Bytes           int64   `json:"bytes"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	MBPerSecond     float64 `json:"mbPerSecond"`
+}
+
+// PhaseThroughput records the minimum, average and maximum throughput of the transfers
+// of a phase.
+type PhaseThroughput struct {
+	Phase          string  `json:"phase"`
+	Transfers      int     `json:"transfers"`
+	Bytes          int64   `json:"bytes"`
+	MinMBPerSecond float64 `json:"minMbPerSecond"`
+	AvgMBPerSecond float64 `json:"avgMbPerSecond"`
+	MaxMBPerSecond float64 `json:"maxMbPerSecond"`
+}
+
+// throughputReport summarizes transfers per phase. Transfers that processed nothing,
+// e.g. downloads resumed after they had completed, are left out. It returns nil when
+// no transfer remains.
+func throughputReport(transfers []progress.Transfer) *ThroughputReport {
+	report := &ThroughputReport{}
+	for _, phase := range throughputPhases {
+		stats := PhaseThroughput{Phase: phase}
+		var total float64
+		for _, t := range transfers {
+			if t.Phase != phase || t.Bytes <= 0 || t.Elapsed <= 0 {
+				continue
+			}
+			rate := t.MBPerSecond()
+			report.Transfers = append(report.Transfers, TransferResult{
+				Phase:           t.Phase,
+				Item:            t.Item,
+				Bytes:           t.Bytes,
+				DurationSeconds: roundRate(t.Elapsed.Seconds()),
+				MBPerSecond:     roundRate(rate),
+			})
+			if stats.Transfers == 0 || rate < stats.MinMBPerSecond {
+				stats.MinMBPerSecond = rate
+			}
+			stats.MaxMBPerSecond = max(stats.MaxMBPerSecond, rate)
+			stats.Transfers++
+			stats.Bytes += t.Bytes
+			total += rate
+		}
+		if stats.Transfers == 0 {
+			continue
+		}
+		stats.AvgMBPerSecond = roundRate(total / float64(stats.Transfers))
+		stats.MinMBPerSecond = roundRate(stats.MinMBPerSecond)
+		stats.MaxMBPerSecond = roundRate(stats.MaxMBPerSecond)
+		report.Phases = append(report.Phases, stats)
+	}
+	if len(report.Transfers) == 0 {
+		return nil
+	}
+	return report
+}
+
+// roundRate rounds v to two decimals.
+func roundRate(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// reportThroughput logs the minimum, average and maximum throughput of each phase.
+// The throughput of every transfer is recorded in the run summary.
+func reportThroughput(log *logger.Logger, report *ThroughputReport) {
+	if report == nil {
+		return
+	}
+	log.Info(i18n.T("summary.throughput"))
+	for _, p := range report.Phases {
+		log.Info(i18n.T("summary.throughput_phase", p.Phase, p.Transfers, p.MinMBPerSecond, p.AvgMBPerSecond, p.MaxMBPerSecond))
+	}
+}
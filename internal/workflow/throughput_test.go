@@ -0,0 +1,36 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
+)
+
+func TestThroughputReport(t *testing.T) {
+	const mb = 1024 * 1024
+	transfers := []progress.Transfer{
+		{Phase: progress.PhaseUpload, Item: "web-01.qcow2", Bytes: 300 * mb, Elapsed: 10 * time.Second},
+		{Phase: progress.PhaseDownload, Item: "osdisk.vhd", Bytes: 100 * mb, Elapsed: 4 * time.Second},
+		{Phase: progress.PhaseDownload, Item: "datadisk-0.vhd", Bytes: 100 * mb, Elapsed: 2 * time.Second},
+		{Phase: progress.PhaseDownload, Item: "datadisk-1.vhd", Bytes: 0, Elapsed: time.Second},
+	}
+	report := throughputReport(transfers)
+	if report == nil || len(report.Transfers) != 3 {
+		t.Fatalf("throughputReport() = %+v, want 3 transfers", report)
+	}
+	want := []PhaseThroughput{
+		{Phase: progress.PhaseDownload, Transfers: 2, Bytes: 200 * mb, MinMBPerSecond: 25, AvgMBPerSecond: 37.5, MaxMBPerSecond: 50},
+		{Phase: progress.PhaseUpload, Transfers: 1, Bytes: 300 * mb, MinMBPerSecond: 30, AvgMBPerSecond: 30, MaxMBPerSecond: 30},
+	}
+	if !reflect.DeepEqual(report.Phases, want) {
+		t.Errorf("Phases = %+v, want %+v", report.Phases, want)
+	}
+	if report.Transfers[0].Item != "osdisk.vhd" || report.Transfers[0].MBPerSecond != 25 {
+		t.Errorf("Unexpected first transfer: %+v", report.Transfers[0])
+	}
+	if throughputReport(nil) != nil {
+		t.Error("Expected no report without transfers")
+	}
+}
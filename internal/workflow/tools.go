@@ -0,0 +1,26 @@
+// Package workflow provides the external tools required by optional workflow features.
+package workflow
+
+import (
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+// optionalTools returns the tools needed by the optional features enabled in cfg, so
+// that the prerequisite checks fail before any disk is exported when one is missing.
+func optionalTools(cfg *config.Config) []string {
+	var tools []string
+	if cfg.ScrubImage {
+		tools = append(tools, "virt-sysprep")
+	}
+	switch cfg.DataDiskCopyStrategy {
+	case common.DataDiskCopySparse:
+		tools = append(tools, "virt-sparsify")
+	case common.DataDiskCopyNBD:
+		tools = append(tools, "qemu-nbd")
+	}
+	if cfg.PrebootValidation {
+		tools = append(tools, common.QEMUSystemCommand())
+	}
+	return tools
+}
@@ -0,0 +1,286 @@
+// Package workflow provides the upload of the OS image to Object Storage, which can start while the image is converted.
+package workflow
+
+import (
+	"context"
+	"crypto/md5" // #nosec G501 -- parts are compared with the MD5 sent with them
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/cloud/oci"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
+)
+
+// stagedUploadLag is how far a part must end before the end of the image for it to be
+// uploaded while the image is written, since qemu-img is still writing near the end.
+var stagedUploadLag int64 = 1 << 30
+
+// stagedUploadPoll is how often the size of the image is checked while it is written.
+var stagedUploadPoll = 5 * time.Second
+
+// stagedUploadWorkers is the number of parts uploaded concurrently.
+const stagedUploadWorkers = 4
+
+// ensureBucket returns the Object Storage namespace and creates the bucket of the
+// image when it does not exist.
+func ensureBucket(ctx context.Context, log *logger.Logger, provider *oci.Provider, cfg *config.Config) (string, error) {
+	namespace, err := provider.GetNamespace(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get namespace: %w", err)
+	}
+	bucketExists, err := provider.CheckBucketExists(ctx, namespace, cfg.OCIBucketName)
+	if err != nil {
+		return "", fmt.Errorf("failed to check bucket: %w", err)
+	}
+	if !bucketExists {
+		log.Infof("Creating bucket '%s'...", cfg.OCIBucketName)
+		if err := provider.CreateBucket(ctx, namespace, cfg.OCICompartmentID, cfg.OCIBucketName); err != nil {
+			return "", fmt.Errorf("failed to create bucket: %w", err)
+		}
+	}
+	return namespace, nil
+}
+
+// partUpload is a multipart upload of an object, such as *oci.MultipartUpload.
+type partUpload interface {
+	UploadPart(ctx context.Context, number int, data []byte) (string, error)
+	Commit(ctx context.Context, etags []string, exclude []int) (string, error)
+	Abort(ctx context.Context) error
+}
+
+// stagedPart is a part uploaded by a staged upload.
+type stagedPart struct {
+	sum  [md5.Size]byte
+	etag string
+}
+
+// stagedUpload uploads an image to Object Storage while it is written. Parts are
+// uploaded as soon as the image has grown past them by stagedUploadLag, and the rest of
+// the full parts once the image is written. The image may still change afterwards, as
+// configuring it does: complete reads every part again, uploads the parts that changed
+// or are missing and commits the object, which is then identical to the image.
+type stagedUpload struct {
+	log      *logger.Logger
+	file     string
+	object   string
+	upload   partUpload
+	partSize int64
+
+	cancel    context.CancelFunc
+	finish    chan struct{} // Closed when the image is written
+	finished  sync.Once
+	stopped   chan struct{} // Closed when the upload of the growing image stops
+	committed atomic.Bool
+	abort     func() // Stops the upload and aborts it unless it is committed
+
+	mu    sync.Mutex
+	parts []stagedPart // Uploaded parts, by part number - 1
+}
+
+// startStagedUpload starts uploading file, which is being written, in parts of partSize
+// through upload. The upload is aborted when the run fails before it is committed.
+func startStagedUpload(ctx context.Context, log *logger.Logger, upload partUpload, file, object string, partSize int64) *stagedUpload {
+	s := &stagedUpload{
+		log: log, file: file, object: object, upload: upload, partSize: partSize,
+		finish: make(chan struct{}), stopped: make(chan struct{}),
+	}
+	tailCtx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.abort = cleanupFromContext(ctx).push("abort the multipart upload of "+object, func(ctx context.Context) error {
+		s.stop()
+		if s.committed.Load() {
+			return nil
+		}
+		return upload.Abort(ctx)
+	})
+	go s.tail(tailCtx)
+	return s
+}
+
+// written tells the upload that the image is written, so that its remaining full parts
+// are uploaded without waiting for it to grow.
+func (s *stagedUpload) written() {
+	s.finished.Do(func() { close(s.finish) })
+}
+
+// stop stops uploading the growing image and waits for the parts in flight.
+func (s *stagedUpload) stop() {
+	s.cancel()
+	<-s.stopped
+}
+
+// tail uploads the parts of the image as it grows, until it is written.
+func (s *stagedUpload) tail(ctx context.Context) {
+	defer close(s.stopped)
+	ticker := time.NewTicker(stagedUploadPoll)
+	defer ticker.Stop()
+	for {
+		lag := stagedUploadLag
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.finish:
+			lag = 0
+		case <-ticker.C:
+		}
+		if err := s.uploadFullParts(ctx, lag); err != nil {
+			if ctx.Err() == nil {
+				s.log.Warning(i18n.T("upload.staged_stopped", s.object, err))
+			}
+			return
+		}
+		if lag == 0 {
+			return
+		}
+	}
+}
+
+// uploadFullParts uploads the full parts not uploaded yet that end at least lag bytes
+// before the end of the image.
+func (s *stagedUpload) uploadFullParts(ctx context.Context, lag int64) error {
+	info, err := os.Stat(s.file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	first := len(s.parts) + 1
+	s.mu.Unlock()
+	var numbers []int
+	for n := first; int64(n)*s.partSize <= info.Size()-lag; n++ {
+		numbers = append(numbers, n)
+	}
+	return s.uploadParts(ctx, numbers, nil)
+}
+
+// complete uploads the parts of the written image that are missing or differ from the
+// uploaded ones, commits the object and returns its multipart MD5.
+func (s *stagedUpload) complete(ctx context.Context) (string, error) {
+	s.written()
+	<-s.stopped
+	info, err := os.Stat(s.file)
+	if err != nil {
+		return "", err
+	}
+	count := int((info.Size() + s.partSize - 1) / s.partSize)
+	numbers := make([]int, count)
+	for i := range numbers {
+		numbers[i] = i + 1
+	}
+	rep := progress.New(s.log, "Uploading "+s.object, info.Size()).Record(progress.PhaseUpload, s.object)
+	defer rep.Done()
+	if err := s.uploadParts(ctx, numbers, rep); err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	etags := make([]string, count)
+	for i := range etags {
+		etags[i] = s.parts[i].etag
+	}
+	var exclude []int
+	for n := count + 1; n <= len(s.parts); n++ {
+		if s.parts[n-1].etag != "" {
+			exclude = append(exclude, n)
+		}
+	}
+	s.mu.Unlock()
+	objectMD5, err := s.upload.Commit(ctx, etags, exclude)
+	if err != nil {
+		return "", err
+	}
+	s.committed.Store(true)
+	s.abort()
+	return objectMD5, nil
+}
+
+// uploadParts uploads the given parts of the image that differ from the uploaded ones.
+// Parts already uploaded with the same content are skipped. A non-nil rep is advanced
+// by the size of every part.
+func (s *stagedUpload) uploadParts(ctx context.Context, numbers []int, rep *progress.Reporter) error {
+	f, err := os.Open(s.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	work := make(chan int)
+	errs := make(chan error, stagedUploadWorkers)
+	var wg sync.WaitGroup
+	for range stagedUploadWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, s.partSize)
+			for n := range work {
+				if err := s.uploadPart(ctx, f, n, buf, rep); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	var sendErr error
+send:
+	for _, n := range numbers {
+		select {
+		case work <- n:
+		case sendErr = <-errs:
+			break send
+		case <-ctx.Done():
+			sendErr = ctx.Err()
+			break send
+		}
+	}
+	close(work)
+	wg.Wait()
+	close(errs)
+	if sendErr != nil {
+		return sendErr
+	}
+	return <-errs
+}
+
+// uploadPart uploads part n of the image read from f into buf, unless it is already
+// uploaded with the same content.
+func (s *stagedUpload) uploadPart(ctx context.Context, f *os.File, n int, buf []byte, rep *progress.Reporter) error {
+	size, err := f.ReadAt(buf, int64(n-1)*s.partSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	data := buf[:size]
+	sum := md5.Sum(data) // #nosec G401
+	s.mu.Lock()
+	uploaded := n <= len(s.parts) && s.parts[n-1].etag != "" && s.parts[n-1].sum == sum
+	s.mu.Unlock()
+	if uploaded {
+		if rep != nil {
+			rep.Skip(int64(size))
+		}
+		return nil
+	}
+	etag, err := s.upload.UploadPart(ctx, n, data)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	for len(s.parts) < n {
+		s.parts = append(s.parts, stagedPart{})
+	}
+	s.parts[n-1] = stagedPart{sum: sum, etag: etag}
+	s.mu.Unlock()
+	if rep != nil {
+		rep.Add(int64(size))
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+)
+
+// fakePartUpload records the parts uploaded to it and assembles the committed object.
+type fakePartUpload struct {
+	mu        sync.Mutex
+	parts     map[int][]byte
+	uploads   map[int]int
+	object    []byte
+	exclude   []int
+	committed bool
+	aborted   bool
+}
+
+func newFakePartUpload() *fakePartUpload {
+	return &fakePartUpload{parts: map[int][]byte{}, uploads: map[int]int{}}
+}
+
+func (f *fakePartUpload) UploadPart(_ context.Context, number int, data []byte) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.parts[number] = bytes.Clone(data)
+	f.uploads[number]++
+	return fmt.Sprintf("etag-%d-%d", number, f.uploads[number]), nil
+}
+
+func (f *fakePartUpload) Commit(_ context.Context, etags []string, exclude []int) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, etag := range etags {
+		if want := fmt.Sprintf("etag-%d-%d", i+1, f.uploads[i+1]); etag != want {
+			return "", fmt.Errorf("part %d committed with ETag %s, want %s", i+1, etag, want)
+		}
+		f.object = append(f.object, f.parts[i+1]...)
+	}
+	f.exclude = exclude
+	f.committed = true
+	return "md5", nil
+}
+
+func (f *fakePartUpload) Abort(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.aborted = true
+	return nil
+}
+
+func (f *fakePartUpload) uploaded() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.parts)
+}
+
+func withStagedUploadTiming(t *testing.T, lag int64) {
+	t.Helper()
+	oldLag, oldPoll := stagedUploadLag, stagedUploadPoll
+	stagedUploadLag, stagedUploadPoll = lag, time.Millisecond
+	t.Cleanup(func() { stagedUploadLag, stagedUploadPoll = oldLag, oldPoll })
+}
+
+func TestStagedUploadWhileWritten(t *testing.T) {
+	withStagedUploadTiming(t, 4)
+	file := filepath.Join(t.TempDir(), "os-disk.qcow2")
+	if err := os.WriteFile(file, []byte("aaaabbbbcccc"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	upload := newFakePartUpload()
+	s := startStagedUpload(context.Background(), logger.New(false), upload, file, "os-disk.qcow2", 4)
+
+	// Parts 1 and 2 end at least one part before the end of the image.
+	deadline := time.Now().Add(5 * time.Second)
+	for upload.uploaded() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("uploaded %d parts while the image is written, want 2", upload.uploaded())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.written()
+
+	// Configuring the image changes part 1 and grows it by a partial part.
+	if err := os.WriteFile(file, []byte("AAAAbbbbccccdd"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	objectMD5, err := s.complete(context.Background())
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if objectMD5 != "md5" {
+		t.Errorf("object MD5 = %q, want md5", objectMD5)
+	}
+	if got := string(upload.object); got != "AAAAbbbbccccdd" {
+		t.Errorf("committed object = %q, want the final image", got)
+	}
+	if upload.uploads[1] != 2 || upload.uploads[2] != 1 || upload.uploads[4] != 1 {
+		t.Errorf("uploads per part = %v, want part 1 twice and parts 2 and 4 once", upload.uploads)
+	}
+	if upload.aborted {
+		t.Error("committed upload was aborted")
+	}
+}
+
+func TestStagedUploadExcludesPartsPastTheEnd(t *testing.T) {
+	withStagedUploadTiming(t, 0)
+	file := filepath.Join(t.TempDir(), "os-disk.qcow2")
+	if err := os.WriteFile(file, []byte("aaaabbbbcccc"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	upload := newFakePartUpload()
+	s := startStagedUpload(context.Background(), logger.New(false), upload, file, "os-disk.qcow2", 4)
+	s.written()
+	<-s.stopped
+	if err := os.WriteFile(file, []byte("aaaabb"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.complete(context.Background()); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if got := string(upload.object); got != "aaaabb" {
+		t.Errorf("committed object = %q, want aaaabb", got)
+	}
+	if fmt.Sprint(upload.exclude) != "[3]" {
+		t.Errorf("excluded parts = %v, want [3]", upload.exclude)
+	}
+}
+
+func TestStagedUploadAbortedWhenRunFails(t *testing.T) {
+	withStagedUploadTiming(t, 0)
+	file := filepath.Join(t.TempDir(), "os-disk.qcow2")
+	if err := os.WriteFile(file, []byte("aaaa"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stack := newCleanupStack(logger.New(false))
+	upload := newFakePartUpload()
+	startStagedUpload(withCleanup(context.Background(), stack), logger.New(false), upload, file, "os-disk.qcow2", 4)
+	if err := stack.unwind(); err != nil {
+		t.Fatalf("unwind: %v", err)
+	}
+	if !upload.aborted || upload.committed {
+		t.Errorf("aborted = %t, committed = %t, want the upload aborted", upload.aborted, upload.committed)
+	}
+}
@@ -0,0 +1,47 @@
+// Package workflow provides the offline validation of a configuration.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+// ValidateConfig checks cfg without contacting a cloud: the schema constraints (OCID
+// formats, regions, allowed values and mutually exclusive options), the files it
+// references, and the external configurators and configuration chain with their
+// scripts. All problems are reported together.
+func ValidateConfig(cfg *config.Config) error {
+	errs := []error{cfg.Validate()}
+	if _, err := cfg.SSHPublicKey(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.LUKSKeyFile != "" {
+		if _, err := os.Stat(cfg.LUKSKeyFile); err != nil {
+			errs = append(errs, fmt.Errorf("failed to read LUKS key file %s: %w", cfg.LUKSKeyFile, err))
+		}
+	}
+	if err := checkFinishingScript(cfg); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkPackageCacheDir(cfg); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.SourcePlatform == "azure" {
+		if _, err := snapshotQuiesce(cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	configurators, err := common.LoadConfigurators(configuratorsDir(cfg))
+	if err == nil {
+		var chain []configureLink
+		if chain, err = resolveConfigureChain(cfg, configurators); err == nil {
+			err = checkCustomScripts(cfg, chain)
+		}
+	}
+	errs = append(errs, err)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,46 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codebypatrickleung/kopru-cli/internal/config"
+)
+
+func validateTestConfig(t *testing.T) *config.Config {
+	return &config.Config{
+		SourcePlatform:     "azure",
+		TargetPlatform:     "oci",
+		AzureComputeName:   "test-vm",
+		AzureResourceGroup: "test-rg",
+		OCICompartmentID:   "ocid1.compartment.oc1..aaaaaaaatest",
+		OCISubnetID:        "ocid1.subnet.oc1.iad.aaaaaaaatest",
+		OCIRegion:          "us-ashburn-1",
+		ConfiguratorsDir:   t.TempDir(),
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	if err := ValidateConfig(validateTestConfig(t)); err != nil {
+		t.Fatalf("Expected valid configuration, got %v", err)
+	}
+
+	cfg := validateTestConfig(t)
+	cfg.OCIRegion = "us-nowhere-1"
+	cfg.SSHKeyFilePath = filepath.Join(t.TempDir(), "missing.pub")
+	cfg.LUKSKeyFile = filepath.Join(t.TempDir(), "missing.key")
+	if err := os.WriteFile(filepath.Join(cfg.ConfiguratorsDir, "hook.yaml"), []byte("name: hook\nhooks:\n  - script: missing.sh\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err := ValidateConfig(cfg)
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+	for _, expected := range []string{"us-nowhere-1", "missing.pub", "missing.key", "missing.sh"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to mention %s, got %v", expected, err)
+		}
+	}
+}
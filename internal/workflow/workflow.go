@@ -4,21 +4,40 @@ package workflow
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/codebypatrickleung/kopru-cli/internal/common"
 	"github.com/codebypatrickleung/kopru-cli/internal/config"
+	"github.com/codebypatrickleung/kopru-cli/internal/i18n"
 	"github.com/codebypatrickleung/kopru-cli/internal/logger"
+	"github.com/codebypatrickleung/kopru-cli/internal/progress"
+	"github.com/codebypatrickleung/kopru-cli/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Manager orchestrates the migration workflow by delegating to registered workflow handlers.
 type Manager struct {
-	config  *config.Config
-	logger  *logger.Logger
-	handler Handler
-	version string
+	config      *config.Config
+	logger      *logger.Logger
+	handler     Handler
+	version     string
+	summaryFile string // Where the run summary is written, SummaryFileName by default
+	offline     bool   // Created by NewManagerOffline, to list the steps only
 }
 
 // NewManager creates a new workflow manager.
 func NewManager(cfg *config.Config, log *logger.Logger, version string) (*Manager, error) {
+	return newManager(cfg, log, version, false)
+}
+
+// NewManagerOffline creates a workflow manager that lists the steps of the workflow
+// without connecting to the clouds, so that no credentials are needed, e.g. for plan.
+// It cannot run the workflow.
+func NewManagerOffline(cfg *config.Config, log *logger.Logger, version string) (*Manager, error) {
+	return newManager(cfg, log, version, true)
+}
+
+func newManager(cfg *config.Config, log *logger.Logger, version string, offline bool) (*Manager, error) {
 	// Create registry and register all workflow handlers
 	registry := NewRegistry()
 
@@ -39,30 +58,115 @@ func NewManager(cfg *config.Config, log *logger.Logger, version string) (*Manage
 	}
 
 	// Initialize the handler
-	if err := handler.Initialize(cfg, log); err != nil {
+	initialize := handler.Initialize
+	if offline {
+		initialize = handler.InitializeOffline
+	}
+	if err := initialize(cfg, log); err != nil {
 		return nil, fmt.Errorf("failed to initialize workflow handler: %w", err)
 	}
 
+	if err := checkStepTimeouts(cfg, handler.Steps()); err != nil {
+		return nil, err
+	}
+
 	return &Manager{
-		config:  cfg,
-		logger:  log,
-		handler: handler,
-		version: version,
+		config:      cfg,
+		logger:      log,
+		handler:     handler,
+		version:     version,
+		summaryFile: SummaryFileName,
+		offline:     offline,
 	}, nil
 }
 
+// runIdentifier is implemented by handlers that identify their runs themselves, e.g.
+// in the tags of the resources they create.
+type runIdentifier interface {
+	RunID() string
+}
+
+// WorkflowName returns the name of the selected workflow handler.
+func (m *Manager) WorkflowName() string {
+	return m.handler.Name()
+}
+
+// Steps returns the steps of the selected workflow without executing them.
+func (m *Manager) Steps() []Step {
+	return m.handler.Steps()
+}
+
 // Run executes the complete migration workflow by delegating to the registered handler.
 func (m *Manager) Run(ctx context.Context) error {
+	if m.offline {
+		return fmt.Errorf("workflow manager was created offline and cannot run the workflow")
+	}
+	m.logger.SetWorkflow(m.WorkflowName())
 	m.logger.Info("=========================================")
-	m.logger.Infof("Kopru - Compute Migration Tool v%s", m.version)
+	m.logger.Info(i18n.T("workflow.header", m.version))
 	m.logger.Info("=========================================")
-	m.logger.Infof("Source Platform: %s", m.config.SourcePlatform)
-	m.logger.Infof("Target Platform: %s", m.config.TargetPlatform)
+	m.logger.Info(i18n.T("workflow.source_platform", m.config.SourcePlatform))
+	m.logger.Info(i18n.T("workflow.target_platform", m.config.TargetPlatform))
 	m.logger.Info("=========================================")
 
+	runID := newMigrationID()
+	if h, ok := m.handler.(runIdentifier); ok {
+		runID = h.RunID()
+	}
+	summary := &RunSummary{
+		RunID:     runID,
+		Workflow:  m.WorkflowName(),
+		Version:   m.version,
+		StartedAt: time.Now().UTC(),
+	}
+
+	if err := checkPreviousMigration(m.config, m.logger); err != nil {
+		m.logger.Error(i18n.T("workflow.failed", err))
+		return err
+	}
+
+	ticket := startChangeTicket(ctx, m.config, m.logger, m.WorkflowName(), m.version)
+	notifier := newRunNotifier(m.config, m.logger, m.WorkflowName(), runID)
+	notifier.started(ctx)
+
 	// Execute the workflow handler
-	if err := m.handler.Execute(ctx); err != nil {
-		m.logger.Errorf("Workflow failed: %v", err)
+	ctx, span := telemetry.StartSpan(ctx, "kopru.run",
+		attribute.String("workflow", m.WorkflowName()),
+		attribute.String("source_platform", m.config.SourcePlatform),
+		attribute.String("target_platform", m.config.TargetPlatform),
+	)
+	incidentsBefore := len(common.WatchdogIncidents())
+	cleanups := newCleanupStack(m.logger)
+	err := m.handler.Execute(withNotifier(withCleanup(withSummary(ctx, summary), cleanups), notifier))
+	if n := cleanups.pending(); n > 0 {
+		m.logger.Info(i18n.T("run.cleaning_up", n))
+		if cleanupErr := cleanups.unwind(); cleanupErr != nil {
+			m.logger.Warning(i18n.T("run.cleanup_incomplete"))
+		}
+	}
+	// Earlier runs of a batch recorded the incidents before the first one of this run.
+	reportWatchdogIncidents(m.logger, common.WatchdogIncidents()[incidentsBefore:])
+	telemetry.EndSpan(span, err)
+	m.handler.Summarize(summary)
+	summary.APIOperations = telemetry.Operations()
+	reportAPIOperations(m.logger, summary.APIOperations)
+	summary.Throughput = throughputReport(progress.Transfers())
+	reportThroughput(m.logger, summary.Throughput)
+	summary.finish(err)
+	if writeErr := summary.Write(m.summaryFile); writeErr != nil {
+		m.logger.Warningf("%v", writeErr)
+	} else {
+		m.logger.Info(i18n.T("run.summary_written", m.summaryFile))
+	}
+	if recordErr := recordMigration(m.config, summary); recordErr != nil {
+		m.logger.Warning(i18n.T("run.history_failed", recordErr))
+	}
+	exportCMDBRecord(ctx, m.config, m.logger, summary)
+	ticket.finish(ctx, m.logger, summary)
+	notifier.finished(ctx, summary)
+
+	if err != nil {
+		m.logger.Error(i18n.T("workflow.failed", err))
 		return err
 	}
 
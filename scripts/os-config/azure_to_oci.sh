@@ -48,8 +48,22 @@ main() {
     add_oci_chrony_config "$IMAGE_FILE" "$os_family" "$os_id"
     add_oci_cloud_init "$IMAGE_FILE" "$os_family" "$os_id" 
     fix_ssh_host_keys "$IMAGE_FILE" "$os_family"
+    configure_oci_network "$IMAGE_FILE" "$os_family"
+    remediate_fstab "$IMAGE_FILE"
+    inject_ssh_access "$IMAGE_FILE"
     cloud_init_clean "$IMAGE_FILE" "$os_family"
 
+    if is_rhel_compatible "$os_id"; then
+        log_info "Phase 3: Applying RHEL-compatible configurations..."
+        configure_rhel_dracut "$IMAGE_FILE"
+        configure_rhel_network "$IMAGE_FILE"
+        if [[ "$os_id" == "ol" ]]; then
+            install_oci_utilities "$IMAGE_FILE"
+        fi
+        # Relabel last so that all files written above get the right SELinux context
+        selinux_relabel "$IMAGE_FILE"
+    fi
+
     log_info "=== OS configurations complete ==="
 }
 
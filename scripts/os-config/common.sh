@@ -6,6 +6,38 @@ log_success() { echo -e "\033[1;32m[SUCCESS]\033[0m $1"; }
 log_warning() { echo -e "\033[1;33m[WARN]\033[0m $1"; }
 log_error()   { echo -e "\033[1;31m[ERROR]\033[0m $1"; }
 
+# LUKS containers in the image are opened by the virt-* tools with the libguestfs key
+# selector passed by Kopru in KOPRU_LUKS_KEY (e.g. all:file:/path/to/keyfile).
+guestfs_key_args=()
+if [[ -n "${KOPRU_LUKS_KEY:-}" ]]; then
+    guestfs_key_args=(--key "$KOPRU_LUKS_KEY")
+fi
+virt-cat()         { command virt-cat ${guestfs_key_args[@]+"${guestfs_key_args[@]}"} "$@"; }
+virt-filesystems() { command virt-filesystems ${guestfs_key_args[@]+"${guestfs_key_args[@]}"} "$@"; }
+virt-inspector()   { command virt-inspector ${guestfs_key_args[@]+"${guestfs_key_args[@]}"} "$@"; }
+virt-ls()          { command virt-ls ${guestfs_key_args[@]+"${guestfs_key_args[@]}"} "$@"; }
+
+# When the image architecture differs from the migration host, commands cannot run
+# inside the image. Kopru then sets KOPRU_DEFER_GUEST_COMMANDS and virt-customize
+# operations that execute guest binaries are deferred to the first boot.
+virt-customize() {
+    if [[ "${KOPRU_DEFER_GUEST_COMMANDS:-}" == "true" ]]; then
+        local args=()
+        while [[ $# -gt 0 ]]; do
+            case "$1" in
+                --run-command) args+=(--firstboot-command "$2"); shift ;;
+                --run) args+=(--firstboot "$2"); shift ;;
+                --install) args+=(--firstboot-install "$2"); shift ;;
+                --selinux-relabel) args+=(--touch /.autorelabel) ;;
+                *) args+=("$1") ;;
+            esac
+            shift
+        done
+        set -- "${args[@]}"
+    fi
+    command virt-customize ${guestfs_key_args[@]+"${guestfs_key_args[@]}"} "$@"
+}
+
 detect_os_info_from_image() {
     local output os_id os_version os_family
     output=$(virt-cat -a "$IMAGE_FILE" /etc/os-release 2>/dev/null || echo "")
@@ -22,7 +54,7 @@ detect_os_info_from_image() {
 detect_guest_architecture() {
     local image_file=$1
     local arch
-    arch=$(virt-inspector -a "$image_file" | virt-inspector --xpath "string(//arch)")
+    arch=$(virt-inspector -a "$image_file" | command virt-inspector --xpath "string(//arch)")
     case "$arch" in
         x86_64|x86-64|amd64) echo "x86_64" ;;
         aarch64|arm64) echo "aarch64" ;;
@@ -158,6 +190,313 @@ ssh_genkeytypes:
     virt-customize -a "$image_file" --write "/etc/cloud/cloud.cfg.d/99_ssh_host_keys_fix.cfg:$ssh_config" &>/dev/null || log_warning "Failed to write SSH host keys fix configuration"
 }
 
+configure_oci_network() {
+    local image_file=$1 os_family=$2
+    log_info "Switching guest network configuration to DHCP on the primary NIC..."
+    # Azure images bind interfaces to the Hyper-V MAC address and netvsc driver, mark
+    # accelerated networking (Mellanox SR-IOV) interfaces as unmanaged and may carry static
+    # addresses and routes of the Azure VNet. None of these match the OCI VNIC, which makes
+    # the first boot wait for a network that never comes up.
+    virt-customize -a "$image_file" --run-command "
+        rm -f /etc/udev/rules.d/68-azure-sriov-nm-unmanaged.rules /etc/udev/rules.d/10-azure-unmanaged-sriov.rules /etc/udev/rules.d/70-persistent-net.rules
+        rm -f /etc/systemd/network/*azure*.network /etc/systemd/network/*azure*.link
+        for f in /etc/sysconfig/network-scripts/ifcfg-eth* /etc/sysconfig/network/ifcfg-eth*; do
+            [ -f \"\$f\" ] || continue
+            sed -i -e '/^BOOTPROTO=/d' -e '/^IPADDR[0-9_]*=/d' -e '/^NETMASK[0-9_]*=/d' -e '/^PREFIX[0-9_]*=/d' \
+                -e '/^GATEWAY=/d' -e '/^HWADDR=/d' -e '/^MACADDR=/d' -e '/^CLOUD_NETCONFIG_MANAGE=/d' \"\$f\"
+            echo 'BOOTPROTO=dhcp' >> \"\$f\"
+        done
+        rm -f /etc/sysconfig/network-scripts/route-eth* /etc/sysconfig/network-scripts/route6-eth* /etc/sysconfig/network/ifroute-eth*
+        for f in /etc/NetworkManager/system-connections/*.nmconnection; do
+            [ -f \"\$f\" ] && sed -i -e 's/^method=manual/method=auto/' -e '/^address[0-9]*=/d' -e '/^route[0-9]*=/d' \
+                -e '/^gateway=/d' -e '/^mac-address=/d' -e '/^interface-name=/d' \"\$f\"
+        done
+        true
+    " &>/dev/null || log_warning "Failed to remove Azure network configuration"
+
+    if [[ "$os_family" == "debian" ]] && virt-ls -a "$image_file" /etc/netplan &>/dev/null; then
+        # Keep the Azure netplan files for reference; netplan only reads *.yaml.
+        virt-customize -a "$image_file" --run-command "
+            for f in /etc/netplan/*.yaml; do [ -f \"\$f\" ] && mv \"\$f\" \"\$f.azure\"; done; true
+        " &>/dev/null || log_warning "Failed to disable Azure netplan configuration"
+        local netplan_conf='network:
+  version: 2
+  ethernets:
+    primary:
+      match:
+        name: "e*"
+      dhcp4: true
+'
+        if virt-customize -a "$image_file" --write "/etc/netplan/90-oci-dhcp.yaml:$netplan_conf" --chmod "0600:/etc/netplan/90-oci-dhcp.yaml" &>/dev/null; then
+            log_success "Netplan configured for DHCP on the primary NIC"
+        else
+            log_warning "Failed to write netplan DHCP configuration"
+        fi
+    fi
+    log_success "Guest network configuration switched to DHCP"
+}
+
+# Prints the subvolume among the space-separated subvolumes that conventionally holds
+# mount_point: @, @root or root for /, and @home, @/home or home for /home.
+btrfs_subvolume_for() {
+    local mount_point=$1 subvolumes=$2 candidate rel
+    local candidates=(@ @root root)
+    if [[ "$mount_point" != "/" ]]; then
+        rel=${mount_point#/}
+        candidates=("@${rel//\//_}" "@/$rel" "$rel")
+    fi
+    for candidate in "${candidates[@]}"; do
+        if [[ " $subvolumes " == *" $candidate "* ]]; then
+            echo "$candidate"
+            return 0
+        fi
+    done
+    return 1
+}
+
+remediate_fstab() {
+    local image_file=$1
+    log_info "Rewriting Azure device paths in /etc/fstab..."
+    local fstab
+    if ! fstab=$(virt-cat -a "$image_file" /etc/fstab 2>/dev/null); then
+        log_warning "Could not read /etc/fstab, skipping fstab remediation"
+        return 0
+    fi
+
+    # UUIDs and types of the filesystems on the OS disk, keyed by partition (/dev/sda1,
+    # ...), and the partition each UUID= and LABEL= reference resolves to. The OS disk is
+    # /dev/sda both on Azure and in the libguestfs appliance. Btrfs subvolumes
+    # (btrfsvol:/dev/sda2/@home) share the UUID of their filesystem and are collected per
+    # partition instead.
+    local -A uuids=() vfs_types=() ref_devices=() subvolumes=() uuid_counts=()
+    local name vfs label uuid
+    while IFS=, read -r name vfs label uuid; do
+        if [[ "$name" == btrfsvol:* ]]; then
+            if [[ "${name#btrfsvol:}" =~ ^(/dev/[a-z]+[0-9]+|/dev/mapper/[^/]+)/(.+)$ ]]; then
+                subvolumes[${BASH_REMATCH[1]}]+=" ${BASH_REMATCH[2]}"
+            fi
+            continue
+        fi
+        vfs_types[$name]=$vfs
+        if [[ -n "$uuid" && "$uuid" != "-" ]]; then
+            uuids[$name]=$uuid
+            ref_devices["UUID=$uuid"]=$name
+            uuid_counts[$uuid]=$(( ${uuid_counts[$uuid]:-0} + 1 ))
+        fi
+        if [[ -n "$label" && "$label" != "-" ]]; then
+            ref_devices["LABEL=$label"]=$name
+        fi
+    done < <(virt-filesystems -a "$image_file" --filesystems --long --uuid --csv 2>/dev/null |
+        awk -F, 'NR == 1 { for (i = 1; i <= NF; i++) col[$i] = i; next } { print $col["Name"] "," $col["VFS"] "," $col["Label"] "," $col["UUID"] }')
+
+    local output="" changed=0 line device mount_point partition fs_device subvol modified
+    local fields=()
+    while IFS= read -r line || [[ -n "$line" ]]; do
+        if [[ "$line" =~ ^[[:space:]]*(#|$) ]]; then
+            output+="$line"$'\n'
+            continue
+        fi
+        read -r -a fields <<< "$line"
+        device=${fields[0]} mount_point=${fields[1]:-}
+        partition="" fs_device="" modified=0
+        case "$device" in
+            /dev/disk/azure/resource*|/dev/disk/cloud/azure_resource*)
+                # The Azure temporary disk does not exist on OCI.
+                output+="# Commented out by Kopru (Azure resource disk): $line"$'\n'
+                changed=1
+                log_warning "Commented out Azure resource disk mount: $mount_point"
+                continue
+                ;;
+            /dev/disk/azure/root-part*) partition="/dev/sda${device##*-part}" ;;
+            /dev/sda[0-9]*) partition="$device" ;;
+        esac
+        if [[ -n "$partition" && -n "${uuids[$partition]:-}" ]]; then
+            fields[0]="UUID=${uuids[$partition]}"
+            fs_device=$partition
+            modified=1
+            log_info "Rewrote $device to UUID=${uuids[$partition]} for $mount_point"
+        elif [[ "$device" == /dev/sd* || "$device" == /dev/disk/azure/* ]]; then
+            # Device names of data disks differ on OCI and may point to another volume.
+            output+="# Commented out by Kopru (Azure device path, use UUID= or LABEL=): $line"$'\n'
+            changed=1
+            log_warning "Commented out mount of $device on $mount_point; re-add it by UUID or LABEL"
+            continue
+        else
+            fs_device=${ref_devices[$device]:-}
+        fi
+
+        if [[ -n "$fs_device" ]]; then
+            # Without subvol=, btrfs mounts the default subvolume, which is the top-level
+            # volume unless it was changed, not the @ or @home subvolume of the mount point.
+            # Snapper sets the default subvolume to the booted snapshot, so it is kept.
+            if [[ "${vfs_types[$fs_device]:-}" == "btrfs" && ",${fields[3]:-}," != *,subvol=* && ",${fields[3]:-}," != *,subvolid=* &&
+                " ${subvolumes[$fs_device]:-} " != *" @/.snapshots "* && " ${subvolumes[$fs_device]:-} " != *" .snapshots "* ]] &&
+                subvol=$(btrfs_subvolume_for "$mount_point" "${subvolumes[$fs_device]:-}"); then
+                fields[3]="${fields[3]:-defaults},subvol=$subvol"
+                modified=1
+                log_info "Mounting btrfs subvolume $subvol on $mount_point"
+            fi
+            # XFS refuses to mount a filesystem with the UUID of a mounted one, which
+            # happens when a disk was cloned.
+            uuid=${uuids[$fs_device]:-}
+            if [[ "${vfs_types[$fs_device]:-}" == "xfs" && -n "$uuid" && "${uuid_counts[$uuid]}" -gt 1 &&
+                ",${fields[3]:-}," != *,nouuid,* ]]; then
+                fields[3]="${fields[3]:-defaults},nouuid"
+                modified=1
+                log_warning "Added nouuid to the mount of $mount_point: XFS UUID $uuid is used by several filesystems"
+            fi
+        elif [[ "$mount_point" != "/" && "$mount_point" != "/boot" && "$mount_point" != "/boot/efi" &&
+            ${#fields[@]} -ge 4 && ",${fields[3]}," != *,nofail,* ]]; then
+            # Mounts of data disks should not send the instance to emergency mode when a
+            # volume is not attached yet.
+            fields[3]="${fields[3]},nofail"
+            modified=1
+            log_info "Added nofail to the mount of $mount_point"
+        fi
+        if [[ $modified -eq 1 ]]; then
+            # A missing type is needed before the options that were added.
+            [[ -z "${fields[2]:-}" ]] && fields[2]=auto
+            output+="${fields[*]}"$'\n'
+            changed=1
+            continue
+        fi
+        output+="$line"$'\n'
+    done <<< "$fstab"
+
+    if [[ $changed -eq 0 ]]; then
+        log_info "No Azure device paths found in /etc/fstab"
+        return 0
+    fi
+    local tmp_fstab
+    tmp_fstab=$(mktemp)
+    printf '%s' "$output" > "$tmp_fstab"
+    if virt-customize -a "$image_file" --run-command "cp -p /etc/fstab /etc/fstab.azure" \
+        --upload "$tmp_fstab:/etc/fstab" --chmod "0644:/etc/fstab" &>/dev/null; then
+        log_success "fstab updated (original saved as /etc/fstab.azure)"
+    else
+        log_warning "Failed to update /etc/fstab"
+    fi
+    rm -f "$tmp_fstab"
+}
+
+inject_ssh_access() {
+    local image_file=$1
+    local public_key=${KOPRU_SSH_PUBLIC_KEY:-} user=${KOPRU_BREAKGLASS_USER:-} expiry_days=${KOPRU_BREAKGLASS_EXPIRY_DAYS:-0}
+    [[ -z "$public_key" ]] && return 0
+    log_info "Injecting SSH public key for emergency access..."
+    local ops=(--mkdir /var/lib/kopru --write "/var/lib/kopru/ssh_key:$public_key")
+    if [[ -n "$user" ]]; then
+        local useradd_opts="-m -s /bin/bash -c 'Kopru break-glass access'"
+        if [[ "$expiry_days" -gt 0 ]]; then
+            useradd_opts+=" -e $(date -u -d "+${expiry_days} days" +%F)"
+        fi
+        ops+=(--run-command "id -u $user >/dev/null 2>&1 || useradd $useradd_opts $user"
+              --write "/etc/sudoers.d/90-kopru-breakglass:$user ALL=(ALL) NOPASSWD:ALL"
+              --chmod "0440:/etc/sudoers.d/90-kopru-breakglass")
+    fi
+    # Add the key to every login user (the Azure admin user and the break-glass user), so
+    # that access does not depend on cloud-init processing the instance metadata.
+    ops+=(--run-command '
+        key=$(cat /var/lib/kopru/ssh_key)
+        getent passwd | while IFS=: read -r name _ uid gid _ home shell; do
+            [ "$uid" -ge 1000 ] && [ "$uid" -lt 60000 ] || continue
+            case "$shell" in */nologin|*/false|"") continue ;; esac
+            [ -d "$home" ] || continue
+            mkdir -p "$home/.ssh"
+            grep -qxF "$key" "$home/.ssh/authorized_keys" 2>/dev/null || echo "$key" >> "$home/.ssh/authorized_keys"
+            chown -R "$uid:$gid" "$home/.ssh"
+            chmod 700 "$home/.ssh"
+            chmod 600 "$home/.ssh/authorized_keys"
+        done
+        rm -rf /var/lib/kopru
+    ')
+    if virt-customize -a "$image_file" "${ops[@]}" &>/dev/null; then
+        log_success "SSH public key injected${user:+, break-glass user '$user' created}"
+    else
+        log_warning "Failed to inject SSH access into the image"
+    fi
+}
+
+is_rhel_compatible() {
+    local os_id=$1
+    case "$os_id" in
+        rhel|centos|almalinux|rocky|ol) return 0 ;;
+        *) return 1 ;;
+    esac
+}
+
+configure_rhel_dracut() {
+    local image_file=$1
+    log_info "Adding virtio drivers to the initramfs..."
+    local dracut_conf='hostonly="no"
+add_drivers+=" virtio_blk virtio_scsi virtio_net virtio_pci virtio_console "
+omit_drivers+=" hv_netvsc hv_storvsc hv_vmbus "'
+    if ! virt-customize -a "$image_file" --mkdir /etc/dracut.conf.d --write "/etc/dracut.conf.d/90-oci-virtio.conf:$dracut_conf" &>/dev/null; then
+        log_warning "Failed to write dracut configuration for OCI"
+        return 0
+    fi
+    virt-customize -a "$image_file" --run-command "rm -f /etc/dracut.conf.d/*azure* /etc/dracut.conf.d/*hyperv*" &>/dev/null || log_warning "Failed to remove Azure dracut configuration"
+    if virt-customize -a "$image_file" --run-command "dracut -f --regenerate-all || dracut -f" &>/dev/null; then
+        log_success "Initramfs rebuilt with virtio drivers"
+    else
+        log_warning "Failed to rebuild initramfs, scheduling at first boot"
+        virt-customize -a "$image_file" --firstboot-command "dracut -f --regenerate-all" &>/dev/null || log_warning "Failed to schedule initramfs rebuild"
+    fi
+}
+
+configure_rhel_network() {
+    local image_file=$1
+    log_info "Adjusting network configuration for OCI..."
+    # MAC bindings and static addresses are removed by configure_oci_network; make sure
+    # NetworkManager manages the interfaces and creates DHCP profiles for new NICs.
+    virt-customize -a "$image_file" --run-command "
+        for f in /etc/sysconfig/network-scripts/ifcfg-eth*; do
+            [ -f \"\$f\" ] && sed -i -e 's/^NM_CONTROLLED=.*/NM_CONTROLLED=yes/' \"\$f\"
+        done
+        true
+    " &>/dev/null || log_warning "Failed to adjust network configuration"
+    local nm_conf='[main]
+no-auto-default=
+'
+    virt-customize -a "$image_file" --mkdir /etc/NetworkManager/conf.d --write "/etc/NetworkManager/conf.d/90-oci-dhcp.conf:$nm_conf" &>/dev/null || log_warning "Failed to enable NetworkManager DHCP fallback"
+    log_success "Network configuration adjusted for OCI"
+}
+
+selinux_relabel() {
+    local image_file=$1
+    local selinux_config
+    selinux_config=$(virt-cat -a "$image_file" /etc/selinux/config 2>/dev/null || echo "")
+    if ! echo "$selinux_config" | grep -qE "^SELINUX=(enforcing|permissive)"; then
+        log_info "SELinux disabled - skipping relabel"
+        return 0
+    fi
+    log_info "Relabelling SELinux contexts of modified files..."
+    if virt-customize -a "$image_file" --selinux-relabel &>/dev/null; then
+        log_success "SELinux contexts relabelled"
+    else
+        log_warning "Failed to relabel SELinux contexts, scheduling a relabel at first boot"
+        virt-customize -a "$image_file" --touch /.autorelabel &>/dev/null || log_warning "Failed to schedule SELinux relabel"
+    fi
+}
+
+install_oci_utilities() {
+    local image_file=$1
+    # virt-customize --install takes a comma-separated list; dnf and yum a space-separated one.
+    local packages="oci-utils,oracle-cloud-agent"
+    local services="ocid.service oracle-cloud-agent.service"
+    log_info "Installing OCI utilities ($packages)..."
+    if virt-customize -a "$image_file" --install "$packages" &>/dev/null; then
+        virt-customize -a "$image_file" --run-command "systemctl enable $services || true" &>/dev/null || log_warning "Failed to enable OCI utilities services"
+        log_success "OCI utilities installed and enabled"
+        return 0
+    fi
+    # The packages are served from the OCI yum mirrors, which may not be reachable from
+    # the migration host; install them when the instance first boots on OCI instead.
+    log_warning "Failed to install OCI utilities into the image, scheduling installation at first boot"
+    local cmd="(dnf install -y ${packages//,/ } || yum install -y ${packages//,/ }) && systemctl enable --now $services"
+    virt-customize -a "$image_file" --firstboot-command "$cmd" &>/dev/null || log_warning "Failed to schedule OCI utilities installation"
+}
+
 install_iscsi_initiator() {
     local image_file=$1
     log_info "Installing iSCSI initiator..."
@@ -45,6 +45,10 @@ main() {
     fi
     
     cloud_init_clean "$IMAGE_FILE" "$OS_FAMILY"
+    if [[ -n "${KOPRU_SSH_PUBLIC_KEY:-}" ]]; then
+        inject_ssh_access "$IMAGE_FILE"
+        selinux_relabel "$IMAGE_FILE"
+    fi
     log_info "=== Linux Image to OCI configuration complete ==="
 }
 